@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// ================= ALERT RULES =================
+
+const (
+	// maxAlertRules bounds the rules in alerts.rules
+	maxAlertRules = 50
+	// maxAlertRuleSteps bounds the Starlark steps of one evaluation
+	maxAlertRuleSteps = 100_000
+)
+
+// AlertRule raises a rule_matched event when its condition becomes true for a server (alerts.rules in config.json)
+// When is a Starlark expression over server, total_players, hour, minute and weekday, e.g.
+// server.category == "Track" and server.players == 0 and hour >= 18 (&&, || and ! are accepted too)
+type AlertRule struct {
+	Name     string `json:"name"`
+	When     string `json:"when"`
+	Severity string `json:"severity,omitempty"` // default warning
+	Message  string `json:"message,omitempty"`  // {rule}, {server}, {category} and {players} are replaced
+	Timezone string `json:"timezone,omitempty"` // IANA name for hour/minute/weekday, default the bot's time zone
+}
+
+// severity returns the event severity (config already validated)
+func (r AlertRule) severity() alertSeverity {
+	s, _ := parseSeverity(r.Severity, severityWarning)
+	return s
+}
+
+// ruleKey identifies one rule on one server; the condition is part of it so an edited rule starts over
+type ruleKey struct {
+	rule   string
+	when   string
+	server serverKey
+}
+
+// alertRuleParams are the names a condition can use
+var alertRuleParams = []string{"server", "total_players", "hour", "minute", "weekday"}
+
+// ruleOperators maps the C-style operators accepted in conditions to Starlark
+var ruleOperators = map[string]string{"&&": " and ", "||": " or ", "!": " not "}
+
+// translateRuleOperators rewrites &&, || and ! (but not !=) outside string literals to Starlark
+func translateRuleOperators(expr string) string {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		ch := expr[i]
+		switch {
+		case quote != 0:
+			b.WriteByte(ch)
+			if ch == '\\' && i+1 < len(expr) {
+				i++
+				b.WriteByte(expr[i])
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+			b.WriteByte(ch)
+		case (ch == '&' || ch == '|') && i+1 < len(expr) && expr[i+1] == ch:
+			b.WriteString(ruleOperators[expr[i:i+2]])
+			i++
+		case ch == '!' && (i+1 == len(expr) || expr[i+1] != '='):
+			b.WriteString(ruleOperators["!"])
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+// compileAlertRule compiles a condition into a function taking alertRuleParams
+func compileAlertRule(name, when string) (*starlark.Function, error) {
+	expr := translateRuleOperators(when)
+	if _, err := syntax.ParseExpr(name, expr, 0); err != nil {
+		return nil, err
+	}
+	src := "lambda " + strings.Join(alertRuleParams, ", ") + ": (" + expr + "\n)"
+	thread := &starlark.Thread{Name: "compile rule " + name}
+	f, err := starlark.ExprFunc(name, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	v, err := starlark.Call(thread, f, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*starlark.Function), nil
+}
+
+// validateAlertRules checks names, severities and time zones, and that every condition compiles and
+// gives a bool for a sample server
+func validateAlertRules(rules []AlertRule) error {
+	if len(rules) > maxAlertRules {
+		return fmt.Errorf("alerts rules: at most %d rules allowed (got %d)", maxAlertRules, len(rules))
+	}
+	seen := make(map[string]bool, len(rules))
+	sample := ServerInfo{Name: "sample", Category: "sample", NumPlayers: 1, MaxPlayers: 20}
+	for i, r := range rules {
+		if strings.TrimSpace(r.Name) == "" {
+			return fmt.Errorf("alerts rule #%d: name cannot be empty", i+1)
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("alerts rule %q: duplicate name", r.Name)
+		}
+		seen[r.Name] = true
+		if strings.TrimSpace(r.When) == "" {
+			return fmt.Errorf("alerts rule %q: when cannot be empty", r.Name)
+		}
+		if _, err := parseSeverity(r.Severity, severityWarning); err != nil {
+			return fmt.Errorf("alerts rule %q: severity: %w", r.Name, err)
+		}
+		loc, err := time.LoadLocation(r.Timezone)
+		if err != nil {
+			return fmt.Errorf("alerts rule %q: timezone: %w", r.Name, err)
+		}
+		fn, err := compileAlertRule(r.Name, r.When)
+		if err != nil {
+			return fmt.Errorf("alerts rule %q: %v", r.Name, err)
+		}
+		if _, err := evalAlertRule(fn, r.Name, sample, 0, time.Now().In(loc)); err != nil {
+			return fmt.Errorf("alerts rule %q: %v", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// ruleServer converts a poll result into the server value of a condition
+func ruleServer(info ServerInfo) *starlarkstruct.Struct {
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"name":        starlark.String(info.Name),
+		"category":    starlark.String(info.Category),
+		"map":         starlark.String(info.Map),
+		"players":     starlark.MakeInt(max(info.NumPlayers, 0)),
+		"max_players": starlark.MakeInt(info.MaxPlayers),
+		"online":      starlark.Bool(!serverDown(info)),
+		"stale":       starlark.Bool(info.Stale),
+		"maintenance": starlark.Bool(info.Maintenance != nil),
+	})
+}
+
+// evalAlertRule evaluates a compiled condition for one server; now must be in the rule's time zone
+func evalAlertRule(fn *starlark.Function, name string, info ServerInfo, totalPlayers int, now time.Time) (bool, error) {
+	thread := &starlark.Thread{Name: "rule " + name}
+	thread.SetMaxExecutionSteps(maxAlertRuleSteps)
+	v, err := starlark.Call(thread, fn, starlark.Tuple{
+		ruleServer(info),
+		starlark.MakeInt(totalPlayers),
+		starlark.MakeInt(now.Hour()),
+		starlark.MakeInt(now.Minute()),
+		starlark.String(strings.ToLower(now.Weekday().String()[:3])),
+	}, nil)
+	if err != nil {
+		return false, err
+	}
+	matched, ok := v.(starlark.Bool)
+	if !ok {
+		return false, fmt.Errorf("condition must give True or False, got %s", v.Type())
+	}
+	return bool(matched), nil
+}
+
+// ruleMessage renders the rule's message for a server
+func ruleMessage(r AlertRule, info ServerInfo) string {
+	msg := r.Message
+	if msg == "" {
+		msg = "⚠️ **{server}** ({category}): {rule}"
+	}
+	return strings.NewReplacer(
+		"{rule}", r.Name,
+		"{server}", info.Name,
+		"{category}", info.Category,
+		"{players}", strconv.Itoa(max(info.NumPlayers, 0)),
+	).Replace(msg)
+}
+
+// evaluateRules evaluates the rules for every server of a poll result and returns a rule_matched event
+// for each condition that became true. A condition is a baseline at its first evaluation, so one already
+// true at startup or when the rule or server is added is not reported; it fires again once it was false
+func (r *alertRouter) evaluateRules(infos []ServerInfo, rules []AlertRule, now time.Time) []alertEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ruleFuncs == nil || len(r.ruleFuncs) > 2*maxAlertRules {
+		r.ruleFuncs = make(map[string]*starlark.Function) // also drops conditions of old configs
+	}
+	if r.ruleErrors == nil {
+		r.ruleErrors = make(map[string]string)
+	}
+
+	total := 0
+	for _, info := range infos {
+		total += max(info.NumPlayers, 0)
+	}
+	matched := make(map[ruleKey]bool, len(rules)*len(infos))
+	var events []alertEvent
+	for _, rule := range rules {
+		fn, ok := r.ruleFuncs[rule.When]
+		if !ok {
+			var err error
+			if fn, err = compileAlertRule(rule.Name, rule.When); err != nil {
+				continue // validated with the config
+			}
+			r.ruleFuncs[rule.When] = fn
+		}
+		local := now
+		if loc, err := time.LoadLocation(rule.Timezone); err == nil {
+			local = now.In(loc)
+		}
+		for _, info := range infos {
+			key := ruleKey{rule.Name, rule.When, serverKey{info.Name, info.Port}}
+			ok, err := evalAlertRule(fn, rule.Name, info, total, local)
+			if err != nil {
+				// Log a failing condition once per distinct error, not every poll
+				if r.ruleErrors[rule.Name] != err.Error() {
+					r.ruleErrors[rule.Name] = err.Error()
+					log.Printf("Error evaluating alert rule %q for %s: %v", rule.Name, info.Name, err)
+				}
+				ok = false
+			}
+			if was, known := r.ruleMatches[key]; known && !was && ok {
+				ev := serverAlert(alertRuleMatched, info, now, ruleMessage(rule, info))
+				ev.Severity = rule.severity()
+				events = append(events, ev)
+			}
+			matched[key] = ok
+		}
+	}
+	// Replacing the map forgets removed rules and servers
+	r.ruleMatches = matched
+	return events
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTranslateRuleOperators tests C-style operators outside and inside string literals
+func TestTranslateRuleOperators(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`a && b || !c`, `a  and  b  or   not c`},
+		{`a != b`, `a != b`},
+		{`server.name == "A && B!"`, `server.name == "A && B!"`},
+		{`server.name == 'it\'s || ok'`, `server.name == 'it\'s || ok'`},
+	}
+	for _, tt := range tests {
+		if got := translateRuleOperators(tt.in); got != tt.want {
+			t.Errorf("translateRuleOperators(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestValidateAlertRules tests names, severities, time zones and conditions
+func TestValidateAlertRules(t *testing.T) {
+	valid := []AlertRule{
+		{Name: "empty track", When: `server.category == "Track" && server.players == 0 && hour >= 18`},
+		{Name: "weekend", When: `weekday in ("sat", "sun") and total_players > 40`, Severity: "info", Timezone: "Europe/Oslo"},
+	}
+	if err := validateAlertRules(valid); err != nil {
+		t.Fatalf("Expected valid rules, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		rule    AlertRule
+		wantErr string
+	}{
+		{"no name", AlertRule{When: "True"}, "name cannot be empty"},
+		{"no condition", AlertRule{Name: "a"}, "when cannot be empty"},
+		{"bad severity", AlertRule{Name: "a", When: "True", Severity: "panic"}, "unknown severity"},
+		{"bad timezone", AlertRule{Name: "a", When: "True", Timezone: "Mars/Base"}, "timezone"},
+		{"syntax error", AlertRule{Name: "a", When: "server.players >"}, `rule "a"`},
+		{"unknown name", AlertRule{Name: "a", When: "players == 0"}, "undefined: players"},
+		{"unknown field", AlertRule{Name: "a", When: "server.ping > 100"}, "no .ping attribute"},
+		{"not a bool", AlertRule{Name: "a", When: "server.players"}, "must give True or False"},
+		{"statement", AlertRule{Name: "a", When: "True)\nx = (1"}, `rule "a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAlertRules([]AlertRule{tt.rule})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+	if err := validateAlertRules([]AlertRule{valid[0], valid[0]}); err == nil || !strings.Contains(err.Error(), "duplicate name") {
+		t.Errorf("Expected duplicate name error, got %v", err)
+	}
+}
+
+// TestEvaluateRules tests the startup baseline, one event per match, re-arming and an edited rule starting over
+func TestEvaluateRules(t *testing.T) {
+	var r alertRouter
+	rules := []AlertRule{{Name: "empty track", When: `server.category == "Track" && server.players == 0 && hour >= 18`,
+		Severity: "error", Message: "{server} is empty ({players} players, {rule})", Timezone: "UTC"}}
+	busy := ServerInfo{Name: "Track 1", Category: "Track", Port: 8081, NumPlayers: 5}
+	empty := busy
+	empty.NumPlayers = 0
+	drift := ServerInfo{Name: "Drift 1", Category: "Drift", Port: 8082, NumPlayers: 0}
+	evening := time.Date(2026, 1, 2, 19, 0, 0, 0, time.UTC)
+
+	// Already true at startup: baseline only
+	if events := r.evaluateRules([]ServerInfo{empty, drift}, rules, evening); len(events) != 0 {
+		t.Fatalf("Expected no events at startup, got %v", events)
+	}
+	r.evaluateRules([]ServerInfo{busy, drift}, rules, evening)
+	events := r.evaluateRules([]ServerInfo{empty, drift}, rules, evening)
+	if len(events) != 1 {
+		t.Fatalf("Expected one rule_matched event, got %v", events)
+	}
+	ev := events[0]
+	if ev.Type != alertRuleMatched || ev.Severity != severityError || ev.Server != "Track 1" || ev.Category != "Track" ||
+		ev.Message != "Track 1 is empty (0 players, empty track)" {
+		t.Errorf("Unexpected event %+v", ev)
+	}
+	if events := r.evaluateRules([]ServerInfo{empty, drift}, rules, evening); len(events) != 0 {
+		t.Errorf("Expected no repeat while the condition holds, got %v", events)
+	}
+
+	// Before 18:00 the condition is false, so it fires again in the evening
+	r.evaluateRules([]ServerInfo{empty, drift}, rules, evening.Add(-2*time.Hour))
+	if events := r.evaluateRules([]ServerInfo{empty, drift}, rules, evening); len(events) != 1 {
+		t.Errorf("Expected the rule to fire again, got %v", events)
+	}
+
+	// An edited condition is a new baseline
+	r.evaluateRules([]ServerInfo{busy, drift}, rules, evening)
+	edited := []AlertRule{rules[0]}
+	edited[0].When = `server.players == 0`
+	if events := r.evaluateRules([]ServerInfo{empty, drift}, edited, evening); len(events) != 0 {
+		t.Errorf("Expected no events for an edited rule, got %v", events)
+	}
+}
+
+// TestEvaluateRules_RuntimeError tests that a failing condition counts as false
+func TestEvaluateRules_RuntimeError(t *testing.T) {
+	var r alertRouter
+	rules := []AlertRule{{Name: "ratio", When: `server.max_players // server.players > 2`}}
+	s := ServerInfo{Name: "A", Category: "Track", NumPlayers: 5, MaxPlayers: 20}
+	r.evaluateRules([]ServerInfo{s}, rules, time.Now())
+	s.NumPlayers = 0 // division by zero
+	if events := r.evaluateRules([]ServerInfo{s}, rules, time.Now()); len(events) != 0 {
+		t.Errorf("Expected no events for a failing condition, got %v", events)
+	}
+	if r.ruleErrors["ratio"] == "" {
+		t.Error("Expected the error to be recorded")
+	}
+}
@@ -0,0 +1,576 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/supervisor"
+	"go.starlark.net/starlark"
+)
+
+// ================= ALERT ROUTING =================
+
+// alertWebhookTimeout bounds one POST to an alert webhook
+const alertWebhookTimeout = 10 * time.Second
+
+// AlertsConfig is the optional alerts section of config.json: a routing table from events to destinations,
+// an escalation policy for long outages and custom rule conditions. Without routes, only the missing-permission
+// alert is posted through the status publishers (previous behavior); an empty routes list turns routed alerts off
+type AlertsConfig struct {
+	Routes     []AlertRoute      `json:"routes"`
+	Escalation *EscalationConfig `json:"escalation,omitempty"`
+	Rules      []AlertRule       `json:"rules,omitempty"`
+}
+
+// AlertRoute sends matching events to one or more destinations
+// Empty Events matches every event type; empty Categories matches every server category (and events without one)
+type AlertRoute struct {
+	Name        string      `json:"name,omitempty"`
+	Events      []string    `json:"events,omitempty"`
+	Categories  []string    `json:"categories,omitempty"`
+	MinSeverity string      `json:"min_severity,omitempty"` // default info (everything)
+	ChannelID   string      `json:"channel_id,omitempty"`   // Discord channel, bot mode only
+	WebhookURL  string      `json:"webhook_url,omitempty"`  // Discord, Slack or generic JSON webhook
+	Status      bool        `json:"status,omitempty"`       // post next to the status message through every publisher
+	QuietHours  *QuietHours `json:"quiet_hours,omitempty"`
+}
+
+// QuietHours mutes a route between Start and End (HH:MM, may wrap past midnight) except for severe events
+type QuietHours struct {
+	Start       string `json:"start"`
+	End         string `json:"end"`
+	Timezone    string `json:"timezone,omitempty"`     // IANA name, default the bot's local time zone
+	MinSeverity string `json:"min_severity,omitempty"` // still delivered during quiet hours, default critical
+}
+
+// Alert event types
+const (
+	alertServerDown         = "server_down"
+	alertServerUp           = "server_up"
+	alertConfigChanged      = "config_changed"
+	alertConfigReloadFailed = "config_reload_failed"
+	alertDiscordPermission  = "discord_permission"
+	alertRuleMatched        = "rule_matched"
+)
+
+// alertEventTypes lists the valid event types with their severity
+var alertEventTypes = map[string]alertSeverity{
+	alertServerDown:         severityWarning,
+	alertServerUp:           severityInfo,
+	alertConfigChanged:      severityInfo,
+	alertConfigReloadFailed: severityError,
+	alertDiscordPermission:  severityCritical,
+	alertRuleMatched:        severityWarning, // the rule's severity replaces it
+}
+
+// alertSeverity orders events for min_severity filters
+type alertSeverity int
+
+const (
+	severityInfo alertSeverity = iota
+	severityWarning
+	severityError
+	severityCritical
+)
+
+var severityNames = []string{"info", "warning", "error", "critical"}
+
+func (s alertSeverity) String() string {
+	return severityNames[s]
+}
+
+// parseSeverity converts a severity name; empty returns def
+func parseSeverity(name string, def alertSeverity) (alertSeverity, error) {
+	if name == "" {
+		return def, nil
+	}
+	i := slices.Index(severityNames, strings.ToLower(name))
+	if i < 0 {
+		return 0, fmt.Errorf("unknown severity %q (valid: %s)", name, strings.Join(severityNames, ", "))
+	}
+	return alertSeverity(i), nil
+}
+
+// defaultAlertRoutes applies when config.json has no alerts section
+var defaultAlertRoutes = []AlertRoute{{Name: "default", Events: []string{alertDiscordPermission}, Status: true}}
+
+// alertEvent is one routed alert
+type alertEvent struct {
+	Type     string
+	Severity alertSeverity
+	Message  string
+	Server   string // empty for bot-wide events
+	Category string // empty for bot-wide events
+	At       time.Time
+}
+
+// newAlertEvent creates an event of a known type with its default severity
+func newAlertEvent(typ, msg string) alertEvent {
+	return alertEvent{Type: typ, Severity: alertEventTypes[typ], Message: msg, At: time.Now()}
+}
+
+// snowflakePattern matches Discord IDs
+var snowflakePattern = regexp.MustCompile(`^\d{17,20}$`)
+
+// validateAlertsConfig checks the routing table and escalation policy (nil is valid: the default route applies)
+func validateAlertsConfig(a *AlertsConfig, categories map[string]bool) error {
+	if a == nil {
+		return nil
+	}
+	if err := validateEscalationConfig(a.Escalation, categories); err != nil {
+		return err
+	}
+	if err := validateAlertRules(a.Rules); err != nil {
+		return err
+	}
+	for i, r := range a.Routes {
+		label := r.label(i)
+		for _, ev := range r.Events {
+			if _, ok := alertEventTypes[ev]; !ok {
+				return fmt.Errorf("alerts route %s: unknown event %q (valid: %s)", label, ev, strings.Join(alertEventNames(), ", "))
+			}
+		}
+		for _, cat := range r.Categories {
+			if !categories[cat] {
+				return fmt.Errorf("alerts route %s: category '%s' is not defined in category_order", label, cat)
+			}
+		}
+		if _, err := parseSeverity(r.MinSeverity, severityInfo); err != nil {
+			return fmt.Errorf("alerts route %s: min_severity: %w", label, err)
+		}
+		if r.ChannelID == "" && r.WebhookURL == "" && !r.Status {
+			return fmt.Errorf("alerts route %s: needs a destination (channel_id, webhook_url or status)", label)
+		}
+		if r.ChannelID != "" && !snowflakePattern.MatchString(r.ChannelID) {
+			return fmt.Errorf("alerts route %s: channel_id %q is not a Discord channel ID", label, r.ChannelID)
+		}
+		if r.WebhookURL != "" && !validAlertWebhookURL(r.WebhookURL) {
+			return fmt.Errorf("alerts route %s: webhook_url must be an http:// or https:// URL", label)
+		}
+		if q := r.QuietHours; q != nil {
+			start, err1 := parseClock(q.Start)
+			end, err2 := parseClock(q.End)
+			if err1 != nil || err2 != nil {
+				return fmt.Errorf("alerts route %s: quiet_hours start and end must be HH:MM", label)
+			}
+			if start == end {
+				return fmt.Errorf("alerts route %s: quiet_hours start and end must differ", label)
+			}
+			if _, err := time.LoadLocation(q.Timezone); err != nil {
+				return fmt.Errorf("alerts route %s: quiet_hours timezone: %w", label, err)
+			}
+			if _, err := parseSeverity(q.MinSeverity, severityCritical); err != nil {
+				return fmt.Errorf("alerts route %s: quiet_hours min_severity: %w", label, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validAlertWebhookURL reports whether rawURL is an absolute http(s) URL
+func validAlertWebhookURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// alertEventNames returns the valid event types, sorted
+func alertEventNames() []string {
+	names := make([]string, 0, len(alertEventTypes))
+	for name := range alertEventTypes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// label names a route in errors and logs: its name, or its position
+func (r *AlertRoute) label(i int) string {
+	if r.Name != "" {
+		return fmt.Sprintf("%q", r.Name)
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// matches reports whether ev should be delivered through r (config already validated)
+func (r *AlertRoute) matches(ev alertEvent) bool {
+	if len(r.Events) > 0 && !slices.Contains(r.Events, ev.Type) {
+		return false
+	}
+	if len(r.Categories) > 0 && !slices.Contains(r.Categories, ev.Category) {
+		return false
+	}
+	if floor, _ := parseSeverity(r.MinSeverity, severityInfo); ev.Severity < floor {
+		return false
+	}
+	if q := r.QuietHours; q != nil && q.active(ev.At) {
+		if floor, _ := parseSeverity(q.MinSeverity, severityCritical); ev.Severity < floor {
+			return false
+		}
+	}
+	return true
+}
+
+// parseClock converts HH:MM to minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// active reports whether now falls within the quiet hours (config already validated)
+func (q *QuietHours) active(now time.Time) bool {
+	if loc, err := time.LoadLocation(q.Timezone); err == nil {
+		now = now.In(loc)
+	}
+	start, _ := parseClock(q.Start)
+	end, _ := parseClock(q.End)
+	m := now.Hour()*60 + now.Minute()
+	if start < end {
+		return m >= start && m < end
+	}
+	return m >= start || m < end // wraps past midnight
+}
+
+// alertRouter holds routing state; the zero value is ready to use
+type alertRouter struct {
+	client *http.Client // nil = default client with alertWebhookTimeout
+
+	mu sync.Mutex
+	// outages tracks servers seen offline (only touched by performUpdate, which never overlaps)
+	outages map[serverKey]outage
+	// known marks servers polled at least once; their first result is a baseline, not a transition
+	known map[serverKey]bool
+	// lastReloadError is the last config reload failure alerted, so a broken file alerts once
+	lastReloadError string
+	// ruleMatches is the last result of every rule per server (see evaluateRules)
+	ruleMatches map[ruleKey]bool
+	// ruleFuncs caches compiled rule conditions by source
+	ruleFuncs map[string]*starlark.Function
+	// ruleErrors is the last evaluation error logged per rule
+	ruleErrors map[string]string
+}
+
+// outage is a server's current offline period
+type outage struct {
+	since time.Time
+	// alerted is false for outages already in progress when the server was first seen
+	alerted bool
+	// escalated is the number of escalation steps sent
+	escalated int
+}
+
+// alert routes ev to every matching destination (skipped on a standby replica)
+// Delivery is synchronous; use alertAsync from code that must not wait on Discord or webhooks
+func (b *Bot) alert(ev alertEvent) {
+	if !b.isLeader() || !b.features.enabled(featureAlerts) {
+		return
+	}
+	routes := defaultAlertRoutes
+	if cfg := b.configManager.GetConfig(); cfg != nil && cfg.Alerts != nil && cfg.Alerts.Routes != nil {
+		routes = cfg.Alerts.Routes
+	}
+
+	// Deliver once per destination even if several routes match
+	var status bool
+	channels := make(map[string]bool)
+	webhooks := make(map[string]bool)
+	for i, r := range routes {
+		if !r.matches(ev) {
+			continue
+		}
+		if r.Status && !status {
+			status = true
+			b.SendAlert(ev.Message)
+		}
+		if r.ChannelID != "" && !channels[r.ChannelID] {
+			channels[r.ChannelID] = true
+			if err := b.sendAlertToChannel(r.ChannelID, ev); err != nil {
+				log.Printf("Error sending %s alert (route %s) to channel %s: %v", ev.Type, r.label(i), r.ChannelID, err)
+			}
+		}
+		if r.WebhookURL != "" && !webhooks[r.WebhookURL] {
+			webhooks[r.WebhookURL] = true
+			if err := b.alerts.postWebhook(r.WebhookURL, ev); err != nil {
+				log.Printf("Error sending %s alert (route %s) to webhook: %v", ev.Type, r.label(i), err)
+			}
+		}
+	}
+}
+
+// alertAsync routes ev in the background
+func (b *Bot) alertAsync(ev alertEvent) {
+	go func() {
+		defer supervisor.Recover("alert delivery", log.Default())
+		b.alert(ev)
+	}()
+}
+
+// sendAlertToChannel posts ev to a Discord channel through the bot session
+func (b *Bot) sendAlertToChannel(channelID string, ev alertEvent) error {
+	if b.discord == nil {
+		return fmt.Errorf("channel_id routes require bot mode (DISCORD_TOKEN), not a webhook")
+	}
+	_, err := b.discord.session.ChannelMessageSend(channelID, ev.Message)
+	return err
+}
+
+// postWebhook posts ev to an alert webhook: Discord and Slack webhooks get a plain message,
+// any other URL the event as JSON (event, severity, message, server, category, time)
+func (r *alertRouter) postWebhook(rawURL string, ev alertEvent) error {
+	var payload any
+	switch {
+	case isDiscordWebhookURL(rawURL):
+		payload = map[string]string{"content": ev.Message}
+	case isSlackWebhookURL(rawURL):
+		payload = map[string]string{"text": ev.Message}
+	default:
+		payload = map[string]string{
+			"event":    ev.Type,
+			"severity": ev.Severity.String(),
+			"message":  ev.Message,
+			"server":   ev.Server,
+			"category": ev.Category,
+			"time":     ev.At.UTC().Format(time.RFC3339),
+		}
+	}
+	return r.postJSON(rawURL, payload)
+}
+
+// postJSON posts payload to an alert webhook, expecting a 2xx response
+func (r *alertRouter) postJSON(rawURL string, payload any) error {
+	return postAlertJSON(r.client, rawURL, nil, payload)
+}
+
+// postAlertJSON posts payload with extra headers through client (nil = default client with alertWebhookTimeout)
+func postAlertJSON(client *http.Client, rawURL string, header http.Header, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), alertWebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: alertWebhookTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// The URL may carry a token: report the host only
+		return fmt.Errorf("request to %s failed: %w", req.URL.Host, unwrapURLError(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned HTTP %d", req.URL.Host, resp.StatusCode)
+	}
+	return nil
+}
+
+// unwrapURLError drops the *url.Error wrapper, whose message repeats the full URL
+func unwrapURLError(err error) error {
+	if ue, ok := err.(*url.Error); ok {
+		return ue.Err
+	}
+	return err
+}
+
+// isDiscordWebhookURL reports whether rawURL is a Discord webhook
+func isDiscordWebhookURL(rawURL string) bool {
+	_, _, err := parseWebhookURL(rawURL)
+	return err == nil
+}
+
+// isSlackWebhookURL reports whether rawURL is a Slack incoming webhook
+func isSlackWebhookURL(rawURL string) bool {
+	return strings.HasPrefix(rawURL, "https://hooks.slack.com/")
+}
+
+// trackOutages compares a poll result with the previous ones and returns server_down and server_up events,
+// plus the escalation steps that came due and the recoveries of escalated outages (esc may be nil)
+// A server is down while it is offline or only shown with last known (stale) data. The first result of a
+// server is a baseline: a server already down at startup or when added is neither reported nor escalated
+func (r *alertRouter) trackOutages(infos []ServerInfo, esc *EscalationConfig, now time.Time) ([]alertEvent, []escalationNotice) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.outages == nil {
+		r.outages = make(map[serverKey]outage)
+		r.known = make(map[serverKey]bool)
+	}
+
+	var events []alertEvent
+	var notices []escalationNotice
+	current := make(map[serverKey]bool, len(infos))
+	for _, info := range infos {
+		key := serverKey{info.Name, info.Port}
+		current[key] = true
+		if info.Maintenance != nil || info.CloudPlanned {
+			// Planned work or a parked cloud server: no alerts or escalation; an outage from before resumes when it ends
+			r.known[key] = true
+			continue
+		}
+		down := serverDown(info)
+		o, wasDown := r.outages[key]
+
+		switch {
+		case down && !wasDown:
+			o = outage{since: now, alerted: r.known[key]}
+			if o.alerted {
+				events = append(events, serverAlert(alertServerDown, info, now,
+					fmt.Sprintf("🔴 **%s** (%s) is offline", info.Name, info.Category)))
+			}
+		case !down && wasDown:
+			delete(r.outages, key)
+			if o.alerted {
+				downtime := now.Sub(o.since)
+				events = append(events, serverAlert(alertServerUp, info, now,
+					fmt.Sprintf("🟢 **%s** (%s) is back online after %s", info.Name, info.Category, formatDataAge(downtime))))
+				if o.escalated > 0 {
+					notices = append(notices, escalationNotice{info: info, steps: esc.firedSteps(o.escalated), level: o.escalated, downtime: downtime, resolved: true})
+				}
+			}
+		}
+		if down && o.alerted && esc.applies(info.Category) {
+			// One poll may pass several thresholds (long interval, config change): only the highest is sent
+			if due := esc.dueSteps(now.Sub(o.since)); due > o.escalated {
+				o.escalated = due
+				notices = append(notices, escalationNotice{info: info, steps: esc.Steps[due-1 : due], level: due, downtime: now.Sub(o.since)})
+			}
+		}
+		if down {
+			r.outages[key] = o
+		}
+		r.known[key] = true
+	}
+
+	// Forget servers removed from the config
+	for key := range r.known {
+		if !current[key] {
+			delete(r.known, key)
+			delete(r.outages, key)
+		}
+	}
+	return events, notices
+}
+
+// serverDown reports whether a poll result counts as an outage: offline, or only last known (stale) data
+func serverDown(info ServerInfo) bool {
+	return info.NumPlayers < 0 || !info.LastSeen.IsZero()
+}
+
+// serverAlert creates a server event
+func serverAlert(typ string, info ServerInfo, now time.Time, msg string) alertEvent {
+	ev := newAlertEvent(typ, msg)
+	ev.Server = info.Name
+	ev.Category = info.Category
+	ev.At = now
+	return ev
+}
+
+// alertOutages routes server_down and server_up events, escalations and matched alert rules for a poll
+// result in the background
+func (b *Bot) alertOutages(infos []ServerInfo, cfg *Config) {
+	if !b.features.enabled(featureAlerts) {
+		return
+	}
+	var esc *EscalationConfig
+	var rules []AlertRule
+	if cfg.Alerts != nil {
+		esc = cfg.Alerts.Escalation
+		rules = cfg.Alerts.Rules
+	}
+	now := time.Now()
+	events, notices := b.alerts.trackOutages(infos, esc, now)
+	events = append(events, b.alerts.evaluateRules(infos, rules, now)...)
+	for _, ev := range events {
+		b.alertAsync(ev)
+	}
+	for _, n := range notices {
+		b.escalateAsync(n)
+	}
+}
+
+// alertReloadFailure routes a config reload failure once per distinct error; nil resets it
+func (b *Bot) alertReloadFailure(err error) {
+	b.alerts.mu.Lock()
+	defer b.alerts.mu.Unlock()
+	if err == nil {
+		b.alerts.lastReloadError = ""
+		return
+	}
+	if err.Error() == b.alerts.lastReloadError {
+		return
+	}
+	b.alerts.lastReloadError = err.Error()
+	b.alertAsync(newAlertEvent(alertConfigReloadFailed, fmt.Sprintf("🛑 Config reload failed, still running the previous config: %v", err)))
+}
+
+// describeConfigChange summarizes what changed between two configs for the config_changed alert
+func describeConfigChange(old, new *Config) string {
+	if old == nil {
+		return fmt.Sprintf("📝 Config loaded: %d servers across %d categories", len(new.Servers), len(new.CategoryOrder))
+	}
+
+	var changes []string
+	oldServers := make(map[serverKey]bool, len(old.Servers))
+	for _, s := range old.Servers {
+		oldServers[serverKey{s.Name, s.Port}] = true
+	}
+	newServers := make(map[serverKey]bool, len(new.Servers))
+	for _, s := range new.Servers {
+		key := serverKey{s.Name, s.Port}
+		newServers[key] = true
+		if !oldServers[key] {
+			changes = append(changes, "added "+s.Name)
+		}
+	}
+	for _, s := range old.Servers {
+		if !newServers[serverKey{s.Name, s.Port}] {
+			changes = append(changes, "removed "+s.Name)
+		}
+	}
+	if old.UpdateInterval != new.UpdateInterval {
+		changes = append(changes, fmt.Sprintf("update interval %ds → %ds", old.UpdateInterval, new.UpdateInterval))
+	}
+	if categoryLayoutChanged(old, new) {
+		changes = append(changes, "categories changed")
+	}
+	if old.ServerIP != new.ServerIP {
+		changes = append(changes, "server_ip changed")
+	}
+	if len(changes) == 0 {
+		return "📝 Config changed"
+	}
+	return "📝 Config changed: " + strings.Join(changes, ", ")
+}
+
+// redactAlertURL hides the path and query of a webhook URL, where tokens live
+func redactAlertURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "[REDACTED]"
+	}
+	if u.Path == "" && u.RawQuery == "" {
+		return u.Redacted()
+	}
+	return u.Scheme + "://" + u.Host + "/[REDACTED]"
+}
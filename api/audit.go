@@ -0,0 +1,436 @@
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditPath lists API config changes; AuditPath/{id} shows one with its snapshots and
+// POST AuditPath/{id}/revert undoes it
+const AuditPath = "/api/v1/audit"
+
+// AuditIDHeader carries the audit entry ID of a successful config write, so clients can offer an undo
+const AuditIDHeader = "X-Audit-ID"
+
+// DefaultAuditSize is the number of config changes the audit log keeps in memory
+const DefaultAuditSize = 100
+
+// AuditEntry is one config change made through the API
+type AuditEntry struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+	// Action is the request that made the change (e.g. "PUT /api/config")
+	Action string `json:"action"`
+	// Actor is the client IP of the request
+	Actor string `json:"actor"`
+	// ChangedKeys are the top-level config keys the change touched
+	ChangedKeys []string `json:"changed_keys"`
+	// RevertOf is set on the entry of a revert, RevertedBy on the entry it reverted
+	RevertOf   string `json:"revert_of,omitempty"`
+	RevertedBy string `json:"reverted_by,omitempty"`
+	// Before and After are the configs around the change (only in GET AuditPath/{id})
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// auditList is the body of GET AuditPath
+type auditList struct {
+	Entries []AuditEntry `json:"entries"`
+}
+
+// configAudit keeps the last size API config changes with the configs they replaced, oldest first
+// mu also serializes audited writes, so Before is exactly the config the write replaced
+// (hand edits of config.json in between are not API changes and are not recorded)
+type configAudit struct {
+	mu      sync.Mutex
+	size    int
+	entries []*auditEntry
+	// store keeps the entries across restarts (nil = memory only)
+	store AuditStore
+}
+
+// AuditStore persists the audit log, so changes can be listed and reverted after a restart
+// Failed writes are logged; the in-memory log stays authoritative
+type AuditStore interface {
+	// LoadAuditEntries returns the newest limit entries with Before and After set, oldest first
+	LoadAuditEntries(limit int) ([]AuditEntry, error)
+	// SaveAuditEntries inserts new entries and updates the RevertedBy of known ones
+	SaveAuditEntries(entries []AuditEntry) error
+	DeleteAuditEntries(ids []string) error
+}
+
+// auditEntry is an AuditEntry with its snapshots split into top-level keys for diffing
+type auditEntry struct {
+	AuditEntry
+	before, after map[string]json.RawMessage
+}
+
+// EnableConfigAudit records config writes (PUT, PATCH, upload, CSV import) in an in-memory audit log of
+// the last size changes and enables the /api/v1/audit endpoints to list and revert them
+// Must be called before Start
+func (s *Server) EnableConfigAudit(size int) {
+	s.audit = &configAudit{size: max(size, 1)}
+}
+
+// SetAuditStore loads the saved audit log from st and keeps it saved there
+// Must be called after EnableConfigAudit and before Start; a no-op when the audit log is disabled
+func (s *Server) SetAuditStore(st AuditStore) error {
+	if s.audit == nil {
+		return nil
+	}
+	saved, err := st.LoadAuditEntries(s.audit.size)
+	if err != nil {
+		return fmt.Errorf("failed to load the audit log: %w", err)
+	}
+	entries := make([]*auditEntry, 0, len(saved))
+	for _, e := range saved {
+		entry := &auditEntry{AuditEntry: e}
+		if err := json.Unmarshal(e.Before, &entry.before); err != nil {
+			return fmt.Errorf("failed to load audit entry %s: %w", e.ID, err)
+		}
+		if err := json.Unmarshal(e.After, &entry.after); err != nil {
+			return fmt.Errorf("failed to load audit entry %s: %w", e.ID, err)
+		}
+		entry.Before, entry.After = nil, nil
+		entries = append(entries, entry)
+	}
+	s.audit.mu.Lock()
+	defer s.audit.mu.Unlock()
+	s.audit.entries = entries
+	s.audit.store = st
+	return nil
+}
+
+// persist saves changed entries and deletes dropped ones when a store is set (caller holds a.mu)
+func (a *configAudit) persist(changed []*auditEntry, dropped []string) {
+	if a.store == nil {
+		return
+	}
+	if len(changed) > 0 {
+		records := make([]AuditEntry, 0, len(changed))
+		for _, e := range changed {
+			record := e.AuditEntry
+			record.Before, record.After = snapshotJSON(e.before), snapshotJSON(e.after)
+			records = append(records, record)
+		}
+		if err := a.store.SaveAuditEntries(records); err != nil {
+			log.Printf("Warning: failed to save the audit log: %v", err)
+		}
+	}
+	if len(dropped) > 0 {
+		if err := a.store.DeleteAuditEntries(dropped); err != nil {
+			log.Printf("Warning: failed to delete from the audit log: %v", err)
+		}
+	}
+}
+
+// dropWhere removes the entries matching drop and deletes them from the store (caller holds a.mu)
+func (a *configAudit) dropWhere(drop func(*auditEntry) bool) int {
+	var dropped []string
+	a.entries = slices.DeleteFunc(a.entries, func(e *auditEntry) bool {
+		if drop(e) {
+			dropped = append(dropped, e.ID)
+			return true
+		}
+		return false
+	})
+	a.persist(nil, dropped)
+	return len(dropped)
+}
+
+// auditedWrite runs write and records the change with the config it replaced when the audit log is enabled
+// The entry ID is sent in the X-Audit-ID header, so it must be called before the response body is written
+func (s *Server) auditedWrite(w http.ResponseWriter, r *http.Request, write func() error) error {
+	if s.audit == nil {
+		return write()
+	}
+	s.audit.mu.Lock()
+	defer s.audit.mu.Unlock()
+	return s.writeAndRecord(w, r, nil, write)
+}
+
+// writeAndRecord runs write between two config snapshots and records the change (caller holds s.audit.mu)
+// revertOf is the entry being reverted, nil for a regular write. A write that changes nothing is not recorded
+func (s *Server) writeAndRecord(w http.ResponseWriter, r *http.Request, revertOf *auditEntry, write func() error) error {
+	before, err := configSnapshot(s.cm.GetConfigAny())
+	if err != nil {
+		return err
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	after, err := configSnapshot(s.cm.GetConfigAny())
+	if err != nil {
+		log.Printf("Warning: config change not audited: %v", err)
+		return nil
+	}
+	changed := changedKeys(before, after)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	e := &auditEntry{
+		AuditEntry: AuditEntry{
+			ID:          newAuditID(),
+			Time:        time.Now().UTC(),
+			Action:      r.Method + " " + r.URL.Path,
+			Actor:       extractClientIP(r, s.trustedProxies),
+			ChangedKeys: changed,
+		},
+		before: before,
+		after:  after,
+	}
+	a := s.audit
+	changedEntries := []*auditEntry{e}
+	if revertOf != nil {
+		e.RevertOf = revertOf.ID
+		revertOf.RevertedBy = e.ID
+		changedEntries = append(changedEntries, revertOf)
+		// Reverting a revert redoes the original change, which can then be reverted again
+		if orig := a.find(revertOf.RevertOf); orig != nil {
+			orig.RevertedBy = ""
+			changedEntries = append(changedEntries, orig)
+		}
+	}
+	a.entries = append(a.entries, e)
+	var evicted []string
+	if len(a.entries) > a.size {
+		for _, old := range a.entries[:len(a.entries)-a.size] {
+			evicted = append(evicted, old.ID)
+		}
+		a.entries = slices.Delete(a.entries, 0, len(a.entries)-a.size)
+	}
+	a.persist(changedEntries, evicted)
+	w.Header().Set(AuditIDHeader, e.ID)
+	return nil
+}
+
+// newAuditID returns a random ID; IDs are never reused across restarts, so a stale undo cannot hit another change
+func newAuditID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// configSnapshot splits a config (as returned by GetConfigAny) into its top-level JSON keys
+func configSnapshot(cfg any) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot config: %w", err)
+	}
+	var snap map[string]json.RawMessage
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to snapshot config: %w", err)
+	}
+	return snap, nil
+}
+
+// changedKeys returns the sorted top-level keys whose values differ between a and b (missing counts as different)
+func changedKeys(a, b map[string]json.RawMessage) []string {
+	keys := slices.Collect(maps.Keys(a))
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var changed []string
+	for _, k := range keys {
+		va, inA := a[k]
+		vb, inB := b[k]
+		if inA != inB || !bytes.Equal(va, vb) {
+			changed = append(changed, k)
+		}
+	}
+	return changed
+}
+
+// find returns the entry with id, nil if it is unknown or was evicted (caller holds a.mu)
+func (a *configAudit) find(id string) *auditEntry {
+	for _, e := range a.entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// mentions reports whether a snapshot of e holds value as a JSON string
+func (e *auditEntry) mentions(value string) bool {
+	quoted, _ := json.Marshal(value)
+	for _, snap := range []map[string]json.RawMessage{e.before, e.after} {
+		for _, v := range snap {
+			if bytes.Contains(v, quoted) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// purge drops the entries whose snapshots hold one of values and returns how many it dropped
+func (a *configAudit) purge(values []string) int {
+	if len(values) == 0 {
+		return 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dropWhere(func(e *auditEntry) bool { return slices.ContainsFunc(values, e.mentions) })
+}
+
+// ExpireAuditEntries drops the recorded config changes made before cutoff and returns how many it dropped
+// (0 when the audit log is disabled); the data retention job calls it
+func (s *Server) ExpireAuditEntries(cutoff time.Time) int {
+	if s.audit == nil {
+		return 0
+	}
+	s.audit.mu.Lock()
+	defer s.audit.mu.Unlock()
+	return s.audit.dropWhere(func(e *auditEntry) bool { return e.Time.Before(cutoff) })
+}
+
+// snapshotJSON joins snapshot keys back into one JSON object
+func snapshotJSON(snap map[string]json.RawMessage) json.RawMessage {
+	data, _ := json.Marshal(snap)
+	return data
+}
+
+// ListAuditEntries returns the recorded config changes, newest first, without their snapshots
+// Requires Bearer token authentication
+func (s *Server) ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("ListAuditEntries cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	s.audit.mu.Lock()
+	list := auditList{Entries: make([]AuditEntry, 0, len(s.audit.entries))}
+	for _, e := range slices.Backward(s.audit.entries) {
+		list.Entries = append(list.Entries, e.AuditEntry)
+	}
+	s.audit.mu.Unlock()
+
+	w.Header().Set("Cache-Control", "private, no-cache")
+	WriteJSON(w, http.StatusOK, list)
+}
+
+// GetAuditEntry returns one config change with the configs before and after it
+// Requires Bearer token authentication
+func (s *Server) GetAuditEntry(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("GetAuditEntry cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	s.audit.mu.Lock()
+	var entry *AuditEntry
+	if e := s.audit.find(r.PathValue("id")); e != nil {
+		detail := e.AuditEntry
+		entry = &detail
+		entry.Before, entry.After = snapshotJSON(e.before), snapshotJSON(e.after)
+	}
+	s.audit.mu.Unlock()
+
+	if entry == nil {
+		WriteError(w, http.StatusNotFound, "Audit entry not found", "Unknown or expired audit ID: "+r.PathValue("id"))
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-cache")
+	WriteJSON(w, http.StatusOK, entry)
+}
+
+// RevertAuditEntry undoes a config change by restoring the keys it changed to their previous values;
+// later changes to other keys are kept. The revert is itself recorded, so reverting it redoes the change
+// Answers 409 when one of the keys was changed again since, unless ?force=true
+// Requires Bearer token authentication and CSRF token
+func (s *Server) RevertAuditEntry(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("RevertAuditEntry cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	force := false
+	if v := r.URL.Query().Get("force"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid force parameter", "Use force=true or force=false")
+			return
+		}
+		force = parsed
+	}
+
+	s.audit.mu.Lock()
+	defer s.audit.mu.Unlock()
+
+	id := r.PathValue("id")
+	e := s.audit.find(id)
+	if e == nil {
+		WriteError(w, http.StatusNotFound, "Audit entry not found", "Unknown or expired audit ID: "+id)
+		return
+	}
+	if e.RevertedBy != "" && !force {
+		WriteError(w, http.StatusConflict, "Change already reverted",
+			fmt.Sprintf("Reverted by %s; revert that entry to redo the change", e.RevertedBy))
+		return
+	}
+
+	current, err := configSnapshot(s.cm.GetConfigAny())
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to read config", err.Error())
+		return
+	}
+	if conflicts := changedKeys(pick(current, e.ChangedKeys), pick(e.after, e.ChangedKeys)); len(conflicts) > 0 && !force {
+		WriteError(w, http.StatusConflict, "Config changed since",
+			fmt.Sprintf("%s changed again after this change; use force=true to overwrite", strings.Join(conflicts, ", ")))
+		return
+	}
+
+	// Inverse of the change: the touched keys get their previous value back (or are removed if they were absent)
+	restored := maps.Clone(current)
+	for _, k := range e.ChangedKeys {
+		if v, ok := e.before[k]; ok {
+			restored[k] = v
+		} else {
+			delete(restored, k)
+		}
+	}
+	var cfg map[string]interface{}
+	if err := json.Unmarshal(snapshotJSON(restored), &cfg); err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to build config", err.Error())
+		return
+	}
+
+	if err := s.writeAndRecord(w, r, e, func() error { return s.cm.WriteConfigAny(cfg) }); err != nil {
+		writeConfigWriteError(w, "Revert failed", err)
+		return
+	}
+
+	// Return reverted config with its lint warnings
+	s.writeConfigWithWarnings(w, r)
+}
+
+// pick returns the entries of snap for keys (absent keys stay absent)
+func pick(snap map[string]json.RawMessage, keys []string) map[string]json.RawMessage {
+	out := make(map[string]json.RawMessage, len(keys))
+	for _, k := range keys {
+		if v, ok := snap[k]; ok {
+			out[k] = v
+		}
+	}
+	return out
+}
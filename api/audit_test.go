@@ -0,0 +1,254 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newAuditTestServer returns an audited server over a writable mock config and its handler
+func newAuditTestServer(t *testing.T, size int) (*mockConfigManagerWithWrites, http.Handler) {
+	t.Helper()
+	cm := &mockConfigManagerWithWrites{config: map[string]interface{}{
+		"server_ip":       "203.0.113.10",
+		"update_interval": float64(30),
+	}}
+	s := NewServer(cm, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.EnableConfigAudit(size)
+	return cm, newVersionedTestHandler(t, s)
+}
+
+// auditDo sends an authenticated request with an optional JSON body
+func auditDo(t *testing.T, handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := authedRequest(method, path)
+	if body != "" {
+		req = httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// auditEntries returns the listed entries, newest first
+func auditEntries(t *testing.T, handler http.Handler) []AuditEntry {
+	t.Helper()
+	rec := auditDo(t, handler, "GET", AuditPath, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("List status = %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	var list auditList
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode list: %v", err)
+	}
+	return list.Entries
+}
+
+func TestAudit_RecordsWrites(t *testing.T) {
+	_, handler := newAuditTestServer(t, DefaultAuditSize)
+
+	rec := auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 60}`)
+	id := rec.Header().Get(AuditIDHeader)
+	if rec.Code != http.StatusOK || id == "" {
+		t.Fatalf("Expected 200 with %s, got %d %q", AuditIDHeader, rec.Code, id)
+	}
+
+	// A write that changes nothing is not recorded
+	rec = auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 60}`)
+	if rec.Header().Get(AuditIDHeader) != "" {
+		t.Error("Expected no audit entry for a no-op write")
+	}
+
+	entries := auditEntries(t, handler)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %+v", entries)
+	}
+	e := entries[0]
+	if e.ID != id || e.Action != "PATCH /api/config" || e.Actor == "" || !slices.Equal(e.ChangedKeys, []string{"update_interval"}) || e.Before != nil {
+		t.Errorf("Unexpected entry %+v", e)
+	}
+
+	rec = auditDo(t, handler, "GET", AuditPath+"/"+id, "")
+	var detail AuditEntry
+	json.NewDecoder(rec.Body).Decode(&detail)
+	if rec.Code != http.StatusOK || !strings.Contains(string(detail.Before), `"update_interval":30`) || !strings.Contains(string(detail.After), `"update_interval":60`) {
+		t.Errorf("Expected snapshots in the detail, got %d %+v", rec.Code, detail)
+	}
+
+	if rec := auditDo(t, handler, "GET", AuditPath+"/unknown", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown ID, got %d", rec.Code)
+	}
+}
+
+func TestAudit_RevertKeepsLaterChanges(t *testing.T) {
+	cm, handler := newAuditTestServer(t, DefaultAuditSize)
+
+	first := auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 60, "http_client": {"timeout_seconds": 3}}`).Header().Get(AuditIDHeader)
+	auditDo(t, handler, "PATCH", "/api/config", `{"server_ip": "198.51.100.7"}`)
+
+	rec := auditDo(t, handler, "POST", AuditPath+"/"+first+"/revert", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Revert status = %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	cfg := cm.config.(map[string]interface{})
+	if cfg["update_interval"] != float64(30) || cfg["server_ip"] != "198.51.100.7" {
+		t.Errorf("Expected update_interval restored and the later server_ip kept, got %v", cfg)
+	}
+	if _, ok := cfg["http_client"]; ok {
+		t.Errorf("Expected the added key removed, got %v", cfg)
+	}
+
+	revert := rec.Header().Get(AuditIDHeader)
+	entries := auditEntries(t, handler)
+	if len(entries) != 3 || entries[0].ID != revert || entries[0].RevertOf != first || entries[2].RevertedBy != revert {
+		t.Errorf("Expected the revert recorded and linked, got %+v", entries)
+	}
+
+	// A second undo is refused; reverting the revert redoes the change
+	if rec := auditDo(t, handler, "POST", AuditPath+"/"+first+"/revert", ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for an already reverted change, got %d", rec.Code)
+	}
+	if rec := auditDo(t, handler, "POST", AuditPath+"/"+revert+"/revert", ""); rec.Code != http.StatusOK {
+		t.Fatalf("Redo status = %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if cfg := cm.config.(map[string]interface{}); cfg["update_interval"] != float64(60) {
+		t.Errorf("Expected the change redone, got %v", cfg)
+	}
+	if entries := auditEntries(t, handler); entries[3].RevertedBy != "" {
+		t.Errorf("Expected the redone change to be revertable again, got %+v", entries[3])
+	}
+}
+
+func TestAudit_RevertConflict(t *testing.T) {
+	cm, handler := newAuditTestServer(t, DefaultAuditSize)
+
+	first := auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 60}`).Header().Get(AuditIDHeader)
+	auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 90}`)
+
+	rec := auditDo(t, handler, "POST", AuditPath+"/"+first+"/revert", "")
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "update_interval") {
+		t.Fatalf("Expected 409 naming the key, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if cm.config.(map[string]interface{})["update_interval"] != float64(90) {
+		t.Error("Expected the config unchanged after a conflict")
+	}
+
+	if rec := auditDo(t, handler, "POST", AuditPath+"/"+first+"/revert?force=maybe", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid force parameter, got %d", rec.Code)
+	}
+	if rec := auditDo(t, handler, "POST", AuditPath+"/"+first+"/revert?force=true", ""); rec.Code != http.StatusOK {
+		t.Fatalf("Forced revert status = %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if cm.config.(map[string]interface{})["update_interval"] != float64(30) {
+		t.Error("Expected the forced revert to restore 30")
+	}
+}
+
+func TestAudit_EvictsOldest(t *testing.T) {
+	_, handler := newAuditTestServer(t, 2)
+
+	first := auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 40}`).Header().Get(AuditIDHeader)
+	auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 50}`)
+	auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 60}`)
+
+	if entries := auditEntries(t, handler); len(entries) != 2 {
+		t.Errorf("Expected 2 entries, got %d", len(entries))
+	}
+	if rec := auditDo(t, handler, "POST", AuditPath+"/"+first+"/revert", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an evicted entry, got %d", rec.Code)
+	}
+}
+
+func TestAudit_V2Meta(t *testing.T) {
+	_, handler := newAuditTestServer(t, DefaultAuditSize)
+
+	rec := auditDo(t, handler, "PATCH", "/api/v2/config", `{"update_interval": 60}`)
+	var body struct {
+		Meta map[string]any `json:"meta"`
+	}
+	json.NewDecoder(rec.Body).Decode(&body)
+	if id := rec.Header().Get(AuditIDHeader); id == "" || body.Meta["audit_id"] != id {
+		t.Errorf("Expected audit_id %q in v2 meta, got %v", id, body.Meta)
+	}
+}
+
+func TestAudit_Disabled(t *testing.T) {
+	cm := &mockConfigManagerWithWrites{config: map[string]interface{}{"update_interval": float64(30)}}
+	s := NewServer(cm, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newVersionedTestHandler(t, s)
+
+	rec := auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 60}`)
+	if rec.Code != http.StatusOK || rec.Header().Get(AuditIDHeader) != "" {
+		t.Errorf("Expected a plain write without audit, got %d %q", rec.Code, rec.Header().Get(AuditIDHeader))
+	}
+	if rec := auditDo(t, handler, "GET", AuditPath, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected no audit route when disabled, got %d", rec.Code)
+	}
+}
+
+// memoryAuditStore is an AuditStore in memory
+type memoryAuditStore struct {
+	entries []AuditEntry
+}
+
+func (m *memoryAuditStore) LoadAuditEntries(limit int) ([]AuditEntry, error) {
+	return slices.Clone(m.entries[max(len(m.entries)-limit, 0):]), nil
+}
+
+func (m *memoryAuditStore) SaveAuditEntries(entries []AuditEntry) error {
+	for _, e := range entries {
+		if i := slices.IndexFunc(m.entries, func(s AuditEntry) bool { return s.ID == e.ID }); i >= 0 {
+			m.entries[i] = e
+		} else {
+			m.entries = append(m.entries, e)
+		}
+	}
+	return nil
+}
+
+func (m *memoryAuditStore) DeleteAuditEntries(ids []string) error {
+	m.entries = slices.DeleteFunc(m.entries, func(e AuditEntry) bool { return slices.Contains(ids, e.ID) })
+	return nil
+}
+
+func TestAudit_Store(t *testing.T) {
+	st := &memoryAuditStore{}
+	cm := &mockConfigManagerWithWrites{config: map[string]interface{}{"update_interval": float64(30)}}
+	newHandler := func() http.Handler {
+		s := NewServer(cm, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+		s.EnableConfigAudit(2)
+		if err := s.SetAuditStore(st); err != nil {
+			t.Fatalf("SetAuditStore failed: %v", err)
+		}
+		return newVersionedTestHandler(t, s)
+	}
+	handler := newHandler()
+	auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 40}`)
+	auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 50}`)
+	last := auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 60}`).Header().Get(AuditIDHeader)
+	if len(st.entries) != 2 {
+		t.Fatalf("Expected the evicted entry to be deleted from the store, got %d entries", len(st.entries))
+	}
+
+	// After a restart the saved entries can be listed and reverted
+	handler = newHandler()
+	if entries := auditEntries(t, handler); len(entries) != 2 || entries[0].ID != last {
+		t.Fatalf("Expected the 2 saved entries, newest first, got %+v", entries)
+	}
+	if rec := auditDo(t, handler, "POST", AuditPath+"/"+last+"/revert", ""); rec.Code != http.StatusOK {
+		t.Fatalf("Revert status = %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if cm.config.(map[string]interface{})["update_interval"] != float64(50) {
+		t.Errorf("Expected the revert to restore 50, got %v", cm.config.(map[string]interface{})["update_interval"])
+	}
+	if i := slices.IndexFunc(st.entries, func(e AuditEntry) bool { return e.ID == last }); i < 0 || st.entries[i].RevertedBy == "" {
+		t.Errorf("Expected the store to mark the entry reverted, got %+v", st.entries)
+	}
+}
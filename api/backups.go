@@ -0,0 +1,57 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// ConfigBackupsPath lists the config backups kept by config writes
+const ConfigBackupsPath = "/api/config/backups"
+
+// ConfigBackup is one backup file of the config
+type ConfigBackup struct {
+	Name      string    `json:"name"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ConfigBackupList is the backup policy and the backups on disk, newest first
+type ConfigBackupList struct {
+	// Mode is "rotate" (config.json.backup, .backup.1, ...) or "timestamp" (config.json.<time>.bak)
+	Mode  string `json:"mode"`
+	Count int    `json:"count"`
+	// MaxAge and MaxSizeBytes are the timestamp mode pruning limits (omitted when unlimited)
+	MaxAge       string         `json:"max_age,omitempty"`
+	MaxSizeBytes int64          `json:"max_size_bytes,omitempty"`
+	Backups      []ConfigBackup `json:"backups"`
+}
+
+// ConfigBackups lists the backups written before each config change
+type ConfigBackups interface {
+	ListConfigBackups() (*ConfigBackupList, error)
+}
+
+// SetConfigBackups enables GET /api/config/backups
+// Must be called before Start
+func (s *Server) SetConfigBackups(b ConfigBackups) {
+	s.configBackups = b
+}
+
+// ListConfigBackups returns the backup policy and the backup files, newest first
+// Requires Bearer token authentication
+func (s *Server) ListConfigBackups(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("ListConfigBackups cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	list, err := s.configBackups.ListConfigBackups()
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to list backups", err.Error())
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-cache")
+	WriteJSON(w, http.StatusOK, list)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+type mockConfigBackups struct {
+	list *ConfigBackupList
+	err  error
+}
+
+func (m *mockConfigBackups) ListConfigBackups() (*ConfigBackupList, error) {
+	return m.list, m.err
+}
+
+func TestListConfigBackups(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.SetConfigBackups(&mockConfigBackups{list: &ConfigBackupList{
+		Mode:    "timestamp",
+		Count:   20,
+		MaxAge:  "720h0m0s",
+		Backups: []ConfigBackup{{Name: "config.json.2024-05-01T12-00-00.bak", SizeBytes: 512, CreatedAt: created}},
+	}})
+	handler := newVersionedTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", ConfigBackupsPath))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	var list ConfigBackupList
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if list.Mode != "timestamp" || len(list.Backups) != 1 || !list.Backups[0].CreatedAt.Equal(created) || list.MaxSizeBytes != 0 {
+		t.Errorf("Unexpected list %+v", list)
+	}
+
+	// Unauthenticated requests are rejected
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", ConfigBackupsPath, nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", rec.Code)
+	}
+}
+
+func TestListConfigBackups_Error(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetConfigBackups(&mockConfigBackups{err: errors.New("permission denied")})
+	handler := newVersionedTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", ConfigBackupsPath))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+}
+
+func TestListConfigBackups_Disabled(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newVersionedTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", ConfigBackupsPath))
+	if rec.Code != http.StatusNotFound && rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected no route without a provider, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventsCalendarPath serves the race events and maintenance windows as an iCalendar feed without authentication
+// Only registered when an EventCalendar is set (API_EVENTS_CALENDAR_ENABLED=true)
+const EventsCalendarPath = "/api/v1/events.ics"
+
+// icalTimeFormat is the UTC DATE-TIME form of RFC 5545 3.3.5
+const icalTimeFormat = "20060102T150405Z"
+
+// CalendarEvent is one VEVENT of the feed
+type CalendarEvent struct {
+	UID         string // stable across requests, so subscribed calendars update the event instead of duplicating it
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+}
+
+// Calendar is the feed served at EventsCalendarPath
+type Calendar struct {
+	Name   string
+	Events []CalendarEvent
+}
+
+// EventCalendar supplies the events of the iCalendar feed
+type EventCalendar interface {
+	EventsCalendar() Calendar
+}
+
+// SetEventCalendar enables the unauthenticated iCalendar feed
+// Must be called before Start
+func (s *Server) SetEventCalendar(c EventCalendar) {
+	s.calendar = c
+}
+
+// EventsCalendar returns the configured race events and maintenance windows as text/calendar
+// No authentication required (calendar apps cannot send a Bearer token); rate limited like every other endpoint
+func (s *Server) EventsCalendar(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("EventsCalendar cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	body := renderICalendar(s.calendar.EventsCalendar(), time.Now())
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="events.ics"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("EventsCalendar write failed: %v", err)
+	}
+}
+
+// renderICalendar writes c as an RFC 5545 VCALENDAR stamped with now
+func renderICalendar(c Calendar, now time.Time) []byte {
+	var sb strings.Builder
+	line := func(name, value string) {
+		writeICalLine(&sb, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//absa-ac//Server Events//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escapeICalText(c.Name))
+	}
+	stamp := now.UTC().Format(icalTimeFormat)
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", stamp)
+		line("DTSTART", e.Start.UTC().Format(icalTimeFormat))
+		line("DTEND", e.End.UTC().Format(icalTimeFormat))
+		line("SUMMARY", escapeICalText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeICalText(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escapeICalText(e.Location))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return []byte(sb.String())
+}
+
+// icalTextEscaper escapes backslashes, separators and line breaks in TEXT values
+var icalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escapeICalText escapes a TEXT value (RFC 5545 3.3.11)
+func escapeICalText(s string) string {
+	return icalTextEscaper.Replace(s)
+}
+
+// writeICalLine writes a content line folded at 75 octets without splitting UTF-8 characters (RFC 5545 3.1)
+func writeICalLine(sb *strings.Builder, s string) {
+	const maxOctets = 75
+	width := maxOctets
+	for len(s) > width {
+		cut := width
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		sb.WriteString(s[:cut])
+		sb.WriteString("\r\n ")
+		s = s[cut:]
+		width = maxOctets - 1 // continuation lines start with a space
+	}
+	sb.WriteString(s)
+	sb.WriteString("\r\n")
+}
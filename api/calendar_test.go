@@ -0,0 +1,100 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockEventCalendar is a test double for EventCalendar
+type mockEventCalendar struct {
+	calendar Calendar
+}
+
+func (m *mockEventCalendar) EventsCalendar() Calendar {
+	return m.calendar
+}
+
+func TestEventsCalendar_NoAuthRequired(t *testing.T) {
+	start := time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC)
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetEventCalendar(&mockEventCalendar{calendar: Calendar{Name: "League", Events: []CalendarEvent{{
+		UID: "event-1@absa-ac", Summary: "Sunday Cup, round 3", Description: "GT3 at Spa\nServer: Track #1",
+		Location: "Track #1", Start: start, End: start.Add(2 * time.Hour),
+	}}}})
+	handler := newPublicTestHandler(t, s)
+
+	req := httptest.NewRequest("GET", EventsCalendarPath, nil)
+	req.Header.Set("Origin", "https://league.example.org")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/calendar; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want text/calendar", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:League\r\n",
+		"UID:event-1@absa-ac\r\n",
+		"DTSTART:20261018T180000Z\r\n",
+		"DTEND:20261018T200000Z\r\n",
+		`SUMMARY:Sunday Cup\, round 3` + "\r\n",
+		`DESCRIPTION:GT3 at Spa\nServer: Track #1` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Body missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestEventsCalendar_DisabledByDefault(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newPublicTestHandler(t, s)
+
+	req := httptest.NewRequest("GET", EventsCalendarPath, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestWriteICalLine_Folds(t *testing.T) {
+	var sb strings.Builder
+	writeICalLine(&sb, "SUMMARY:"+strings.Repeat("é", 100))
+
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\r\n"), "\r\n")
+	if len(lines) < 3 {
+		t.Fatalf("Expected the line to be folded, got %q", sb.String())
+	}
+	var unfolded strings.Builder
+	for i, l := range lines {
+		if len(l) > 75 {
+			t.Errorf("Line %d is %d octets, want at most 75", i, len(l))
+		}
+		if i > 0 {
+			if !strings.HasPrefix(l, " ") {
+				t.Errorf("Continuation line %d must start with a space: %q", i, l)
+			}
+			l = l[1:]
+		}
+		unfolded.WriteString(l)
+	}
+	if unfolded.String() != "SUMMARY:"+strings.Repeat("é", 100) {
+		t.Errorf("Unfolded line differs (UTF-8 split?): %q", unfolded.String())
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"log"
 	"net/http"
 	"strings"
+
+	"github.com/bombom/absa-ac/pkg/metrics"
+	"github.com/bombom/absa-ac/pkg/requestid"
 )
 
 // CSRF validates CSRF tokens for state-changing requests
@@ -40,6 +43,7 @@ func CSRF(next http.Handler) http.Handler {
 		// Extract CSRF token from header
 		csrfTokenFromRequest := r.Header.Get("X-CSRF-Token")
 		if csrfTokenFromRequest == "" {
+			csrfRejections.Inc(metrics.ComponentAPI, "missing")
 			WriteError(w, http.StatusForbidden, "CSRF token missing",
 				"State-changing requests require X-CSRF-Token header. Fetch token from GET /api/csrf-token")
 			return
@@ -48,7 +52,8 @@ func CSRF(next http.Handler) http.Handler {
 		// Validate token using timing-safe comparison
 		expectedToken := GetCSRFToken()
 		if !compareTokens(csrfTokenFromRequest, expectedToken) {
-			log.Printf("CSRF validation failed for %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+			log.Printf("CSRF validation failed for %s %s from %s (request_id=%s)", r.Method, r.URL.Path, r.RemoteAddr, requestid.From(r))
+			csrfRejections.Inc(metrics.ComponentAPI, "invalid")
 			WriteError(w, http.StatusForbidden, "CSRF token invalid",
 				"The provided CSRF token is invalid or expired. Fetch a new token from GET /api/csrf-token")
 			return
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// DeploymentsPath/{server} lists the config file deployments of a server (GET) or deploys the rendered
+// files (POST); /preview diffs them against the last deployment and /rollback redeploys an earlier one
+const DeploymentsPath = "/api/v1/deployments"
+
+// ErrUnknownDeployment is returned (wrapped) by ConfigDeployer.Rollback for a deployment not in the history;
+// handlers answer 404 Not Found
+var ErrUnknownDeployment = errors.New("unknown deployment")
+
+// ErrDeployFailed is returned (wrapped) by ConfigDeployer when the target refused or could not be reached;
+// handlers answer 502 Bad Gateway
+var ErrDeployFailed = errors.New("deployment failed")
+
+// Deployment is one upload of a server's config files
+type Deployment struct {
+	ID         int       `json:"id"`
+	Server     string    `json:"server"`
+	Target     string    `json:"target"`
+	DeployedAt time.Time `json:"deployed_at"`
+	By         string    `json:"by,omitempty"`
+	Files      []string  `json:"files"`
+	RollbackOf int       `json:"rollback_of,omitempty"` // ID of the deployment that was redeployed
+}
+
+// FileDiff is the change of one file against the last deployment
+type FileDiff struct {
+	Name    string `json:"name"`
+	Changed bool   `json:"changed"`
+	Diff    string `json:"diff,omitempty"` // unified diff, empty when unchanged
+}
+
+// DeploymentPreview is what a deployment would upload
+type DeploymentPreview struct {
+	Server string     `json:"server"`
+	Target string     `json:"target"`
+	Base   int        `json:"base,omitempty"` // deployment the diff is against (0 = never deployed)
+	Files  []FileDiff `json:"files"`
+}
+
+// RollbackRequest is the optional body of POST DeploymentsPath/{server}/rollback
+type RollbackRequest struct {
+	ID int `json:"id,omitempty"` // deployment to redeploy (0 = the one before the latest)
+}
+
+// ConfigDeployer renders server config files from the templates in the config and uploads them
+// Unknown servers (or servers without files) are ErrUnknownServer; render errors are other errors
+type ConfigDeployer interface {
+	Deployments(server string) ([]Deployment, error)
+	PreviewDeployment(server string) (DeploymentPreview, error)
+	Deploy(ctx context.Context, server, by string) (Deployment, error)
+	Rollback(ctx context.Context, server string, id int, by string) (Deployment, error)
+}
+
+// SetConfigDeployer enables the deployment endpoints
+// Must be called before Start
+func (s *Server) SetConfigDeployer(d ConfigDeployer) {
+	s.deployer = d
+}
+
+// ListDeployments returns the deployment history of a server, newest first
+// Requires Bearer token authentication
+func (s *Server) ListDeployments(w http.ResponseWriter, r *http.Request) {
+	deployments, err := s.deployer.Deployments(r.PathValue("server"))
+	if err != nil {
+		writeDeployError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string][]Deployment{"deployments": deployments})
+}
+
+// PreviewDeployment renders the files of a server and diffs them against the last deployment
+// Requires Bearer token authentication
+func (s *Server) PreviewDeployment(w http.ResponseWriter, r *http.Request) {
+	preview, err := s.deployer.PreviewDeployment(r.PathValue("server"))
+	if err != nil {
+		writeDeployError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, preview)
+}
+
+// Deploy renders the files of a server and uploads them to its target
+// Requires Bearer token authentication and CSRF token
+func (s *Server) Deploy(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("Deploy cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	server := r.PathValue("server")
+	d, err := s.deployer.Deploy(r.Context(), server, "api:"+extractClientIP(r, s.trustedProxies))
+	if err != nil {
+		writeDeployError(w, err)
+		return
+	}
+	log.Printf("Audit: config files of '%s' deployed (#%d) via the API from %s", server, d.ID, extractClientIP(r, s.trustedProxies))
+	WriteJSON(w, http.StatusOK, d)
+}
+
+// RollbackDeployment redeploys the files of an earlier deployment (body {"id": n}, default the one before the latest)
+// Requires Bearer token authentication and CSRF token
+func (s *Server) RollbackDeployment(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("RollbackDeployment cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	var req RollbackRequest
+	if r.Body != nil {
+		defer r.Body.Close()
+		r.Body = http.MaxBytesReader(w, r.Body, 4<<10)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			WriteError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+			return
+		}
+	}
+	if req.ID < 0 {
+		WriteError(w, http.StatusBadRequest, "Invalid deployment", "id must be a positive deployment ID")
+		return
+	}
+
+	server := r.PathValue("server")
+	d, err := s.deployer.Rollback(r.Context(), server, req.ID, "api:"+extractClientIP(r, s.trustedProxies))
+	if err != nil {
+		writeDeployError(w, err)
+		return
+	}
+	log.Printf("Audit: config files of '%s' rolled back to #%d (#%d) via the API from %s", server, d.RollbackOf, d.ID, extractClientIP(r, s.trustedProxies))
+	WriteJSON(w, http.StatusOK, d)
+}
+
+// writeDeployError maps ConfigDeployer errors to responses
+func writeDeployError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownServer):
+		WriteError(w, http.StatusNotFound, "Server not found", err.Error())
+	case errors.Is(err, ErrUnknownDeployment):
+		WriteError(w, http.StatusNotFound, "Deployment not found", err.Error())
+	case errors.Is(err, ErrDeployFailed):
+		log.Printf("Deployment failed: %v", err)
+		WriteError(w, http.StatusBadGateway, "Deployment failed", err.Error())
+	default:
+		WriteError(w, http.StatusBadRequest, "Cannot render config files", err.Error())
+	}
+}
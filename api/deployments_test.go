@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// mockDeployer deploys "Track #1" only; its target fails while down is set
+type mockDeployer struct {
+	deployments []Deployment
+	down        bool
+}
+
+func (m *mockDeployer) check(server string) error {
+	if server != "Track #1" {
+		return fmt.Errorf("%w '%s'", ErrUnknownServer, server)
+	}
+	return nil
+}
+
+func (m *mockDeployer) Deployments(server string) ([]Deployment, error) {
+	return m.deployments, m.check(server)
+}
+
+func (m *mockDeployer) PreviewDeployment(server string) (DeploymentPreview, error) {
+	if err := m.check(server); err != nil {
+		return DeploymentPreview{}, err
+	}
+	return DeploymentPreview{Server: server, Files: []FileDiff{{Name: "server_cfg.ini", Changed: true, Diff: "+NAME=Track\n"}}}, nil
+}
+
+func (m *mockDeployer) Deploy(ctx context.Context, server, by string) (Deployment, error) {
+	if err := m.check(server); err != nil {
+		return Deployment{}, err
+	}
+	if m.down {
+		return Deployment{}, fmt.Errorf("%w: connection refused", ErrDeployFailed)
+	}
+	d := Deployment{ID: len(m.deployments) + 1, Server: server, By: by, Files: []string{"server_cfg.ini"}}
+	m.deployments = append([]Deployment{d}, m.deployments...)
+	return d, nil
+}
+
+func (m *mockDeployer) Rollback(ctx context.Context, server string, id int, by string) (Deployment, error) {
+	if err := m.check(server); err != nil {
+		return Deployment{}, err
+	}
+	if id > len(m.deployments) || len(m.deployments) < 2 {
+		return Deployment{}, fmt.Errorf("%w: #%d", ErrUnknownDeployment, id)
+	}
+	if id == 0 {
+		id = len(m.deployments) - 1
+	}
+	d := Deployment{ID: len(m.deployments) + 1, Server: server, By: by, RollbackOf: id}
+	m.deployments = append([]Deployment{d}, m.deployments...)
+	return d, nil
+}
+
+func TestDeploymentEndpoints(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	m := &mockDeployer{}
+	s.SetConfigDeployer(m)
+	handler := newVersionedTestHandler(t, s)
+
+	rec := auditDo(t, handler, "GET", DeploymentsPath+"/Track%20%231/preview", "")
+	var preview DeploymentPreview
+	json.NewDecoder(rec.Body).Decode(&preview)
+	if rec.Code != http.StatusOK || len(preview.Files) != 1 || !preview.Files[0].Changed {
+		t.Fatalf("Preview = %d %+v", rec.Code, preview)
+	}
+
+	for range 2 {
+		if rec := auditDo(t, handler, "POST", DeploymentsPath+"/Track%20%231", ""); rec.Code != http.StatusOK {
+			t.Fatalf("Deploy status = %d (body: %s)", rec.Code, rec.Body.String())
+		}
+	}
+	rec = auditDo(t, handler, "POST", DeploymentsPath+"/Track%20%231/rollback", "")
+	var d Deployment
+	json.NewDecoder(rec.Body).Decode(&d)
+	if rec.Code != http.StatusOK || d.ID != 3 || d.RollbackOf != 1 {
+		t.Fatalf("Rollback = %d %+v", rec.Code, d)
+	}
+
+	rec = auditDo(t, handler, "GET", DeploymentsPath+"/Track%20%231", "")
+	var list map[string][]Deployment
+	json.NewDecoder(rec.Body).Decode(&list)
+	if rec.Code != http.StatusOK || len(list["deployments"]) != 3 || list["deployments"][0].ID != 3 {
+		t.Errorf("History = %d %+v", rec.Code, list)
+	}
+
+	tests := []struct {
+		name, method, path, body string
+		want                     int
+	}{
+		{"unknown server", "GET", "/Rally%20%231/preview", "", http.StatusNotFound},
+		{"unknown deployment", "POST", "/Track%20%231/rollback", `{"id":9}`, http.StatusNotFound},
+		{"negative id", "POST", "/Track%20%231/rollback", `{"id":-1}`, http.StatusBadRequest},
+		{"invalid JSON", "POST", "/Track%20%231/rollback", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := auditDo(t, handler, tt.method, DeploymentsPath+tt.path, tt.body); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+
+	m.down = true
+	if rec := auditDo(t, handler, "POST", DeploymentsPath+"/Track%20%231", ""); rec.Code != http.StatusBadGateway {
+		t.Errorf("Failed deploy status = %d, want 502", rec.Code)
+	}
+}
+
+func TestWriteDeployError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeDeployError(rec, errors.New(`server_cfg.ini: map has no entry for key "track"`))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Render error status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// DiagnosticsPath serves the self-diagnostics report to attach to bug reports
+const DiagnosticsPath = "/api/v1/diagnostics"
+
+// DiagnosticsReport summarizes the state of the running bot without secrets or server addresses
+type DiagnosticsReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	// Source is "running bot", or "offline" with the reason when the CLI could not reach the bot
+	Source    string     `json:"source"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	Uptime    string     `json:"uptime,omitempty"`
+
+	Config DiagnosticsConfig `json:"config"`
+	// LastReload is the last reload of a changed config file (nil until the file changed)
+	LastReload *DiagnosticsReload  `json:"last_reload,omitempty"`
+	Updates    *DiagnosticsUpdates `json:"updates,omitempty"`
+	// Discord is the gateway state (nil in webhook mode)
+	Discord *DiagnosticsDiscord `json:"discord,omitempty"`
+	Runtime DiagnosticsRuntime  `json:"runtime"`
+	// Components are the long-lived components and health checks (channel permissions, watchdog)
+	Components []HealthCheckResult `json:"components"`
+}
+
+// DiagnosticsConfig describes the loaded config by counts only
+type DiagnosticsConfig struct {
+	// Source is the config file path or the environment variable the config came from
+	Source                string   `json:"source"`
+	Loaded                bool     `json:"loaded"`
+	Error                 string   `json:"error,omitempty"`
+	Servers               int      `json:"servers"`
+	Categories            int      `json:"categories"`
+	UpdateIntervalSeconds int      `json:"update_interval_seconds,omitempty"`
+	Mode                  string   `json:"mode,omitempty"`
+	Publishers            []string `json:"publishers,omitempty"`
+}
+
+// DiagnosticsReload is the result of a config file reload
+type DiagnosticsReload struct {
+	At    time.Time `json:"at"`
+	OK    bool      `json:"ok"`
+	Error string    `json:"error,omitempty"`
+}
+
+// DiagnosticsUpdates are the recent status updates (poll and publish), newest first
+type DiagnosticsUpdates struct {
+	Recent       []DiagnosticsUpdate `json:"recent"`
+	SkippedTicks uint64              `json:"skipped_ticks"`
+	SlowUpdates  uint64              `json:"slow_updates"`
+}
+
+// DiagnosticsUpdate is one completed status update
+type DiagnosticsUpdate struct {
+	At         time.Time `json:"at"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// DiagnosticsDiscord is the Discord gateway state
+type DiagnosticsDiscord struct {
+	Connected          bool   `json:"connected"`
+	HeartbeatLatencyMs int64  `json:"heartbeat_latency_ms"`
+	Reconnects         uint64 `json:"reconnects"`
+
+	// Privileges is the intent and permission audit of the last ready (nil before it)
+	Privileges *DiagnosticsPrivileges `json:"privileges,omitempty"`
+}
+
+// DiagnosticsPrivileges compares the gateway intents and status channel permissions in use with those granted
+type DiagnosticsPrivileges struct {
+	Intents []DiagnosticsIntent `json:"intents"` // requested on connect
+	// PrivilegedIntentsGranted are enabled in the Developer Portal (absent when the lookup failed)
+	PrivilegedIntentsGranted []string `json:"privileged_intents_granted,omitempty"`
+	Channel                  string   `json:"channel"`
+	PermissionsUsed          []string `json:"permissions_used"`
+	PermissionsGranted       []string `json:"permissions_granted,omitempty"` // "Administrator" alone stands for all
+	PermissionsUnused        []string `json:"permissions_unused,omitempty"`  // granted but not needed by any enabled feature
+	Error                    string   `json:"error,omitempty"`
+}
+
+// DiagnosticsIntent is a requested gateway intent
+type DiagnosticsIntent struct {
+	Name       string `json:"name"`
+	Privileged bool   `json:"privileged,omitempty"`
+	Purpose    string `json:"purpose"`
+}
+
+// DiagnosticsRuntime is the Go runtime state of the process
+type DiagnosticsRuntime struct {
+	GoVersion      string `json:"go_version"`
+	OS             string `json:"os"`
+	Arch           string `json:"arch"`
+	Goroutines     int    `json:"goroutines"`
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes"`
+	HeapSysBytes   uint64 `json:"heap_sys_bytes"`
+	SysBytes       uint64 `json:"sys_bytes"`
+	NumGC          uint32 `json:"num_gc"`
+}
+
+// DiagnosticsProvider builds the diagnostics report of the running bot
+type DiagnosticsProvider interface {
+	Diagnostics() *DiagnosticsReport
+}
+
+// SetDiagnostics enables GET /api/v1/diagnostics
+// Must be called before Start
+func (s *Server) SetDiagnostics(d DiagnosticsProvider) {
+	s.diagnostics = d
+}
+
+// GetDiagnostics returns the diagnostics report
+// Requires Bearer token authentication
+func (s *Server) GetDiagnostics(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("GetDiagnostics cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-cache")
+	WriteJSON(w, http.StatusOK, s.diagnostics.Diagnostics())
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+type mockDiagnostics struct{}
+
+func (mockDiagnostics) Diagnostics() *DiagnosticsReport {
+	return &DiagnosticsReport{Source: "running bot", Config: DiagnosticsConfig{Source: "/data/config.json", Loaded: true, Servers: 3}}
+}
+
+func TestGetDiagnostics(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetDiagnostics(mockDiagnostics{})
+	handler := newVersionedTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", DiagnosticsPath))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	var report DiagnosticsReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if report.Source != "running bot" || report.Config.Servers != 3 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", DiagnosticsPath, nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", rec.Code)
+	}
+}
+
+func TestGetDiagnostics_Disabled(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newVersionedTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", DiagnosticsPath))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a provider, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// DriversPath lists Steam links; DriversPath/{steam_id} links (PUT) or unlinks (DELETE) one
+const DriversPath = "/api/v1/drivers"
+
+// ErrDriverLinked is returned (wrapped) by DriverDirectory when the Steam ID belongs to another member;
+// handlers answer 409 Conflict
+var ErrDriverLinked = errors.New("steam ID already linked")
+
+// DriverLink ties a Steam ID (the GUID in AC results) to a Discord member
+type DriverLink struct {
+	SteamID         string    `json:"steam_id"`
+	DiscordUserID   string    `json:"discord_user_id"`
+	DiscordUsername string    `json:"discord_username,omitempty"` // empty when linked via the API
+	LinkedAt        time.Time `json:"linked_at"`
+}
+
+// DriverLinkRequest is the body of PUT DriversPath/{steam_id}
+type DriverLinkRequest struct {
+	DiscordUserID string `json:"discord_user_id"`
+}
+
+// DriverDirectory manages the Steam links members create with /driver link
+type DriverDirectory interface {
+	Drivers() []DriverLink
+	// LinkDriver replaces the member's previous link; errors other than ErrDriverLinked are bad input
+	LinkDriver(steamID, userID string) (DriverLink, error)
+	// UnlinkDriver reports whether the Steam ID was linked
+	UnlinkDriver(steamID string) bool
+}
+
+// SetDriverDirectory enables the driver link endpoints
+// Must be called before Start
+func (s *Server) SetDriverDirectory(d DriverDirectory) {
+	s.drivers = d
+}
+
+// ListDrivers returns every Steam link
+// Requires Bearer token authentication
+func (s *Server) ListDrivers(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string][]DriverLink{"drivers": s.drivers.Drivers()})
+}
+
+// LinkDriver links a Steam ID to a Discord member on their behalf
+// Requires Bearer token authentication and CSRF token
+func (s *Server) LinkDriver(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("LinkDriver cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	var req DriverLinkRequest
+	defer r.Body.Close()
+	r.Body = http.MaxBytesReader(w, r.Body, 4<<10)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	link, err := s.drivers.LinkDriver(r.PathValue("steam_id"), req.DiscordUserID)
+	if errors.Is(err, ErrDriverLinked) {
+		WriteError(w, http.StatusConflict, "Steam ID already linked", err.Error())
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid driver link", err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, link)
+}
+
+// UnlinkDriver removes the link of a Steam ID
+// Requires Bearer token authentication and CSRF token
+func (s *Server) UnlinkDriver(w http.ResponseWriter, r *http.Request) {
+	steamID := r.PathValue("steam_id")
+	if !s.drivers.UnlinkDriver(steamID) {
+		WriteError(w, http.StatusNotFound, "Not linked", "steam ID '"+steamID+"' is not linked")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeDriverDirectory struct {
+	links map[string]string // Steam ID -> Discord user ID
+}
+
+func (f *fakeDriverDirectory) Drivers() []DriverLink {
+	out := []DriverLink{}
+	for steamID, userID := range f.links {
+		out = append(out, DriverLink{SteamID: steamID, DiscordUserID: userID})
+	}
+	return out
+}
+
+func (f *fakeDriverDirectory) LinkDriver(steamID, userID string) (DriverLink, error) {
+	if userID == "" {
+		return DriverLink{}, fmt.Errorf("a Discord user ID is required")
+	}
+	if owner, ok := f.links[steamID]; ok && owner != userID {
+		return DriverLink{}, fmt.Errorf("%w: %s", ErrDriverLinked, steamID)
+	}
+	f.links[steamID] = userID
+	return DriverLink{SteamID: steamID, DiscordUserID: userID}, nil
+}
+
+func (f *fakeDriverDirectory) UnlinkDriver(steamID string) bool {
+	_, ok := f.links[steamID]
+	delete(f.links, steamID)
+	return ok
+}
+
+// TestDriverEndpoints tests link, conflict, list, unlink and registration
+func TestDriverEndpoints(t *testing.T) {
+	s := &Server{}
+	mux := http.NewServeMux()
+	RegisterRoutes(mux, s)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DriversPath, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a directory, got %d", rec.Code)
+	}
+
+	s.SetDriverDirectory(&fakeDriverDirectory{links: map[string]string{}})
+	mux = http.NewServeMux()
+	RegisterRoutes(mux, s)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return rec
+	}
+
+	if rec := do(http.MethodPut, DriversPath+"/76561198000000001", `{"discord_user_id": "42"}`); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodPut, DriversPath+"/76561198000000001", `{"discord_user_id": "43"}`); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a linked Steam ID, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, DriversPath+"/76561198000000002", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a user ID, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, DriversPath+"/76561198000000002", `{`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid JSON, got %d", rec.Code)
+	}
+
+	rec = do(http.MethodGet, DriversPath, "")
+	var list map[string][]DriverLink
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list["drivers"]) != 1 || list["drivers"][0].DiscordUserID != "42" {
+		t.Errorf("Unexpected list %s", rec.Body)
+	}
+
+	if rec := do(http.MethodDelete, DriversPath+"/76561198000000001", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, DriversPath+"/76561198000000001", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unlinked Steam ID, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// EntryListPath returns the latest entry list of one server (GET, {server} is the server name)
+const EntryListPath = "/api/v1/servers/{server}/entries"
+
+// EntryListCar is one car slot of a server's entry list
+type EntryListCar struct {
+	Model     string `json:"model"`
+	Class     string `json:"class"`
+	Skin      string `json:"skin,omitempty"`
+	Driver    string `json:"driver,omitempty"` // empty for a free slot
+	Team      string `json:"team,omitempty"`
+	Nation    string `json:"nation,omitempty"`
+	Connected bool   `json:"connected"`
+}
+
+// CarClassCount is the number of entry list slots of one car class
+type CarClassCount struct {
+	Class string `json:"class"`
+	Count int    `json:"count"`
+}
+
+// EntryList is the response of EntryListPath
+type EntryList struct {
+	Server    string          `json:"server"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Cars      []EntryListCar  `json:"cars"`
+	Classes   []CarClassCount `json:"classes"` // most common first
+}
+
+// EntryListProvider supplies the entry lists fetched with the last polls
+// Reports false for unknown or offline servers and servers whose protocol has no entry list
+type EntryListProvider interface {
+	EntryList(server string) (EntryList, bool)
+}
+
+// SetEntryListProvider enables the entry list endpoint
+// Must be called before Start
+func (s *Server) SetEntryListProvider(p EntryListProvider) {
+	s.entryLists = p
+}
+
+// GetEntryList returns the entry list and car class breakdown of one server
+// Requires Bearer token authentication
+func (s *Server) GetEntryList(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("GetEntryList cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	server := r.PathValue("server")
+	list, ok := s.entryLists.EntryList(server)
+	if !ok {
+		WriteError(w, http.StatusNotFound, "No entry list",
+			"server '"+server+"' is unknown, offline, not polled yet or has no entry list")
+		return
+	}
+	WriteJSON(w, http.StatusOK, list)
+}
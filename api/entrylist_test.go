@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// mockEntryLists has an entry list for "Track #1" only
+type mockEntryLists struct{}
+
+func (mockEntryLists) EntryList(server string) (EntryList, bool) {
+	if server != "Track #1" {
+		return EntryList{}, false
+	}
+	return EntryList{
+		Server:    server,
+		UpdatedAt: time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC),
+		Cars:      []EntryListCar{{Model: "ks_ferrari_488_gt3", Class: "GT3", Driver: "Ola", Connected: true}},
+		Classes:   []CarClassCount{{Class: "GT3", Count: 1}},
+	}, true
+}
+
+func TestGetEntryList(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetEntryListProvider(mockEntryLists{})
+	handler := newVersionedTestHandler(t, s)
+
+	rec := auditDo(t, handler, "GET", "/api/v1/servers/Track%20%231/entries", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	var list EntryList
+	json.NewDecoder(rec.Body).Decode(&list)
+	if list.Server != "Track #1" || len(list.Cars) != 1 || list.Cars[0].Class != "GT3" || list.Classes[0].Count != 1 {
+		t.Errorf("Unexpected entry list %+v", list)
+	}
+
+	if rec := auditDo(t, handler, "GET", "/api/v1/servers/Drift%20%231/entries", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a server without an entry list, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/servers/Track%20%231/entries", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", rec.Code)
+	}
+}
+
+func TestGetEntryList_Disabled(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newVersionedTestHandler(t, s)
+	if rec := auditDo(t, handler, "GET", "/api/v1/servers/Track%20%231/entries", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a provider, got %d", rec.Code)
+	}
+}
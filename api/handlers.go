@@ -2,12 +2,24 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
+// writeConfigWriteError reports a failed config write: 409 for a read-only config source, 400 otherwise
+func writeConfigWriteError(w http.ResponseWriter, msg string, err error) {
+	if errors.Is(err, ErrConfigReadOnly) {
+		WriteError(w, http.StatusConflict, "Config is read-only", err.Error())
+		return
+	}
+	WriteError(w, http.StatusBadRequest, msg, err.Error())
+}
+
 // HealthCheck returns 200 OK if the API server is running
 // No authentication required (used for health checks)
 func HealthCheck(w http.ResponseWriter, r *http.Request) {
@@ -27,10 +39,14 @@ func (s *Server) GetConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	cfg := s.cm.GetConfigAny()
-	WriteJSON(w, http.StatusOK, cfg)
+	// Private to the authenticated user, but always revalidated so edits show up at once
+	w.Header().Set("Cache-Control", "private, no-cache")
+	WriteJSONWithETag(w, r, cfg)
 }
 
 // GetServers returns only the servers list from current configuration
+// Supports ?category=, ?sort=name|port|category, ?order=, ?limit= and ?offset=; X-Total-Count is the
+// number of matches before limit/offset
 // Requires Bearer token authentication
 func (s *Server) GetServers(w http.ResponseWriter, r *http.Request) {
 	if err := r.Context().Err(); err != nil {
@@ -38,6 +54,14 @@ func (s *Server) GetServers(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
 		return
 	}
+	lq, err := parseListQuery(r, []string{"name", "port", "category"})
+	if err == nil && lq.online != nil {
+		err = fmt.Errorf("online is only supported on status lists (%s)", PublicStatusServersPath)
+	}
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid query", err.Error())
+		return
+	}
 	cfg := s.cm.GetConfigAny()
 
 	// Serialize and deserialize to extract servers field
@@ -61,7 +85,10 @@ func (s *Server) GetServers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	WriteJSON(w, http.StatusOK, servers)
+	page, total := lq.apply(jsonObjects(servers))
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	w.Header().Set("Cache-Control", "private, no-cache")
+	WriteJSONWithETag(w, r, page)
 }
 
 // PatchConfig applies a partial configuration update
@@ -93,14 +120,13 @@ func (s *Server) PatchConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.cm.UpdateConfig(partial); err != nil {
-		WriteError(w, http.StatusBadRequest, "Config update failed", err.Error())
+	if err := s.auditedWrite(w, r, func() error { return s.cm.UpdateConfig(partial) }); err != nil {
+		writeConfigWriteError(w, "Config update failed", err)
 		return
 	}
 
-	// Return updated config
-	cfg := s.cm.GetConfigAny()
-	WriteJSON(w, http.StatusOK, cfg)
+	// Return updated config with its lint warnings
+	s.writeConfigWithWarnings(w, r)
 }
 
 // PutConfig replaces the entire configuration
@@ -132,14 +158,13 @@ func (s *Server) PutConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.cm.WriteConfigAny(newConfig); err != nil {
-		WriteError(w, http.StatusBadRequest, "Config write failed", err.Error())
+	if err := s.auditedWrite(w, r, func() error { return s.cm.WriteConfigAny(newConfig) }); err != nil {
+		writeConfigWriteError(w, "Config write failed", err)
 		return
 	}
 
-	// Return updated config
-	cfg := s.cm.GetConfigAny()
-	WriteJSON(w, http.StatusOK, cfg)
+	// Return updated config with its lint warnings
+	s.writeConfigWithWarnings(w, r)
 }
 
 // ValidateConfig validates a configuration without applying it
@@ -259,13 +284,12 @@ func (s *Server) UploadConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Write config (triggers backup rotation via WriteConfigAny)
-	if err := s.cm.WriteConfigAny(newConfig); err != nil {
-		WriteError(w, http.StatusBadRequest, "Config write failed", err.Error())
+	// Write config (triggers backup rotation via WriteConfigAny, audited when enabled)
+	if err := s.auditedWrite(w, r, func() error { return s.cm.WriteConfigAny(newConfig) }); err != nil {
+		writeConfigWriteError(w, "Config write failed", err)
 		return
 	}
 
-	// Return updated config
-	cfg := s.cm.GetConfigAny()
-	WriteJSON(w, http.StatusOK, cfg)
+	// Return updated config with its lint warnings
+	s.writeConfigWithWarnings(w, r)
 }
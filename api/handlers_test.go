@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"log"
 	"mime/multipart"
 	"net/http"
@@ -99,6 +100,42 @@ func TestHandlers_GetConfig(t *testing.T) {
 	}
 }
 
+// TestHandlers_GetConfigETag tests conditional GETs: 304 while unchanged, new ETag after a write
+func TestHandlers_GetConfigETag(t *testing.T) {
+	cm := &mockConfigManagerWithWrites{config: map[string]interface{}{"server_ip": "192.168.1.1"}}
+	s := NewServer(cm, "18080", "test-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/config", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		s.GetConfig(rec, req)
+		return rec
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Header().Get("Cache-Control") != "private, no-cache" {
+		t.Fatalf("Expected 200 with ETag, got %d %q", first.Code, etag)
+	}
+
+	for _, inm := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		if rec := get(inm); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 || rec.Header().Get("ETag") != etag {
+			t.Errorf("If-None-Match %s: expected empty 304, got %d (%d bytes)", inm, rec.Code, rec.Body.Len())
+		}
+	}
+	if rec := get(`"stale"`); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for a non-matching ETag, got %d", rec.Code)
+	}
+
+	cm.config = map[string]interface{}{"server_ip": "10.0.0.1"}
+	if rec := get(etag); rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("Expected 200 with a new ETag after a config change, got %d", rec.Code)
+	}
+}
+
 func TestHandlers_PatchConfig(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -222,6 +259,39 @@ func TestHandlers_PutConfig(t *testing.T) {
 	}
 }
 
+func TestHandlers_ReadOnlyConfig(t *testing.T) {
+	readOnly := fmt.Errorf("%w: loaded from CONFIG_JSON", ErrConfigReadOnly)
+	cm := &mockConfigManagerWithWrites{
+		config:    map[string]interface{}{"server_ip": "192.168.1.1"},
+		writeErr:  readOnly,
+		updateErr: readOnly,
+	}
+	s := NewServer(cm, "18080", "test-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+	}{
+		{"PATCH", s.PatchConfig, "PATCH"},
+		{"PUT", s.PutConfig, "PUT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/config", strings.NewReader(`{"update_interval":60}`))
+			rec := httptest.NewRecorder()
+			tt.handler(rec, req)
+
+			if rec.Code != http.StatusConflict {
+				t.Errorf("Status = %d, want %d", rec.Code, http.StatusConflict)
+			}
+			if !strings.Contains(rec.Body.String(), "CONFIG_JSON") {
+				t.Errorf("Expected the config source in the error, got %s", rec.Body.String())
+			}
+		})
+	}
+}
+
 func TestHandlers_ValidateConfig(t *testing.T) {
 	tests := []struct {
 		name       string
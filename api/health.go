@@ -0,0 +1,85 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// HealthChecksPath serves the component checks with their details (auth required)
+const HealthChecksPath = "/api/v1/health"
+
+// HealthCheckResult is one component check reported by /health
+type HealthCheckResult struct {
+	Name    string   `json:"name"`
+	OK      bool     `json:"ok"`
+	Detail  string   `json:"detail,omitempty"`
+	Missing []string `json:"missing,omitempty"`
+	// LastSuccess is when a recurring job (e.g. the offsite backup) last succeeded
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+}
+
+// HealthReporter supplies component checks for /health (e.g. Discord channel permissions)
+type HealthReporter interface {
+	HealthChecks() []HealthCheckResult
+}
+
+// SetHealthReporter adds component checks to /health
+// Must be called before Start
+func (s *Server) SetHealthReporter(h HealthReporter) {
+	s.health = h
+}
+
+// Health serves /health: "ok" when every check passes, "degraded" (still 200) otherwise
+// Degraded is not a liveness failure; restarting the bot does not fix a missing permission
+// /health needs no auth, so checks are listed by name and result only: details such as
+// channel IDs and Discord errors are served by GetHealthChecks
+// Without a HealthReporter it answers like HealthCheck
+func (s *Server) Health(w http.ResponseWriter, r *http.Request) {
+	if s.health == nil {
+		HealthCheck(w, r)
+		return
+	}
+	if err := r.Context().Err(); err != nil {
+		log.Printf("Health cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	checks := s.health.HealthChecks()
+	public := make([]HealthCheckResult, len(checks))
+	for i, c := range checks {
+		public[i] = HealthCheckResult{Name: c.Name, OK: c.OK}
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"status":  healthStatus(checks),
+		"service": "ac-bot-api",
+		"checks":  public,
+	})
+}
+
+// GetHealthChecks handles GET /api/v1/health: the /health checks with detail, missing
+// permissions and last success
+func (s *Server) GetHealthChecks(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("GetHealthChecks cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	checks := s.health.HealthChecks()
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"status": healthStatus(checks),
+		"checks": checks,
+	})
+}
+
+// healthStatus is "degraded" when any check fails, "ok" otherwise
+func healthStatus(checks []HealthCheckResult) string {
+	for _, c := range checks {
+		if !c.OK {
+			return "degraded"
+		}
+	}
+	return "ok"
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// mockHealthReporter is a test double for HealthReporter
+type mockHealthReporter struct {
+	checks []HealthCheckResult
+}
+
+func (m *mockHealthReporter) HealthChecks() []HealthCheckResult {
+	return m.checks
+}
+
+func TestHealth_WithoutReporter(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newPublicTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+
+	var body map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if rec.Code != http.StatusOK || body["status"] != "ok" || body["checks"] != nil {
+		t.Errorf("Unexpected response %d %v", rec.Code, body)
+	}
+}
+
+func TestHealth_Degraded(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetHealthReporter(&mockHealthReporter{checks: []HealthCheckResult{
+		{Name: "discord_permissions", OK: false, Detail: "missing in channel 123: Embed Links", Missing: []string{"Embed Links"}},
+	}})
+	handler := newPublicTestHandler(t, s)
+
+	// No auth header: /health stays public
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var body struct {
+		Status string              `json:"status"`
+		Checks []HealthCheckResult `json:"checks"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Status != "degraded" || len(body.Checks) != 1 || body.Checks[0].Name != "discord_permissions" || body.Checks[0].OK {
+		t.Errorf("Unexpected body %+v", body)
+	}
+	// Channel IDs and Discord errors are not public
+	if body.Checks[0].Detail != "" || body.Checks[0].Missing != nil {
+		t.Errorf("Expected /health without check details, got %+v", body.Checks[0])
+	}
+}
+
+func TestHealthChecks_Details(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetHealthReporter(&mockHealthReporter{checks: []HealthCheckResult{
+		{Name: "discord_permissions", OK: false, Detail: "missing in channel 123: Embed Links", Missing: []string{"Embed Links"}},
+	}})
+	handler := newPublicTestHandler(t, s)
+
+	// Auth required
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", HealthChecksPath, nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Status without token = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", HealthChecksPath))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var body struct {
+		Status string              `json:"status"`
+		Checks []HealthCheckResult `json:"checks"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Status != "degraded" || len(body.Checks) != 1 || body.Checks[0].Missing[0] != "Embed Links" || body.Checks[0].Detail == "" {
+		t.Errorf("Unexpected body %+v", body)
+	}
+}
+
+func TestHealthChecks_NotRegisteredWithoutReporter(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newPublicTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", HealthChecksPath))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHealth_AllChecksPass(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetHealthReporter(&mockHealthReporter{checks: []HealthCheckResult{{Name: "discord_permissions", OK: true}}})
+	handler := newPublicTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+
+	var body map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body["status"] != "ok" {
+		t.Errorf("status = %v, want ok", body["status"])
+	}
+}
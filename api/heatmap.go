@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HeatmapPath serves the average players per weekday and hour from the server history
+// Only registered when a HeatmapProvider is set (HISTORY_ENABLED=true)
+const HeatmapPath = "/api/v1/heatmap"
+
+const (
+	// defaultHeatmapWeeks is the history covered without ?weeks=
+	defaultHeatmapWeeks = 4
+	// maxHeatmapWeeks is the most ?weeks= may ask for (the history keeps 93 days by default)
+	maxHeatmapWeeks = 13
+)
+
+// Heatmap is the occupancy by hour of the week, for "best time to race" charts
+type Heatmap struct {
+	Server   string    `json:"server,omitempty"` // empty = all servers together
+	Timezone string    `json:"timezone"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	// Players[d][h] is the average players on weekday d (0 = Monday) during hour h; null without history
+	Players [7][24]*float64 `json:"players"`
+}
+
+// HeatmapProvider computes the heatmap of server ("" = all) over [from, to) in loc
+// Returns ErrUnknownServer (wrapped) for a server that is neither configured nor in the history
+type HeatmapProvider interface {
+	Heatmap(server string, from, to time.Time, loc *time.Location) (*Heatmap, error)
+}
+
+// SetHeatmapProvider enables the heatmap endpoint
+// Must be called before Start
+func (s *Server) SetHeatmapProvider(p HeatmapProvider) {
+	s.heatmap = p
+}
+
+// GetHeatmap returns the heatmap of ?server= (default: all servers) over the last ?weeks= (default 4),
+// with hours in the ?tz= IANA time zone (default UTC)
+// Requires Bearer token authentication
+func (s *Server) GetHeatmap(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("GetHeatmap cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	q := r.URL.Query()
+	weeks := defaultHeatmapWeeks
+	if v := q.Get("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxHeatmapWeeks {
+			WriteError(w, http.StatusBadRequest, "Invalid weeks", "weeks must be 1-"+strconv.Itoa(maxHeatmapWeeks))
+			return
+		}
+		weeks = n
+	}
+	loc := time.UTC
+	if v := q.Get("tz"); v != "" {
+		l, err := time.LoadLocation(v)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid tz", "tz must be an IANA time zone, e.g. Europe/Oslo")
+			return
+		}
+		loc = l
+	}
+
+	to := time.Now().UTC().Truncate(time.Hour)
+	heatmap, err := s.heatmap.Heatmap(q.Get("server"), to.AddDate(0, 0, -7*weeks), to, loc)
+	if errors.Is(err, ErrUnknownServer) {
+		WriteError(w, http.StatusNotFound, "Server not found", err.Error())
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Heatmap failed", err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, heatmap)
+}
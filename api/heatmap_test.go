@@ -0,0 +1,53 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+type mockHeatmapProvider struct {
+	server   string
+	from, to time.Time
+	loc      *time.Location
+}
+
+func (m *mockHeatmapProvider) Heatmap(server string, from, to time.Time, loc *time.Location) (*Heatmap, error) {
+	if server == "Nope" {
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownServer, server)
+	}
+	m.server, m.from, m.to, m.loc = server, from, to, loc
+	return &Heatmap{Server: server, Timezone: loc.String(), From: from, To: to}, nil
+}
+
+func TestGetHeatmap(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	provider := &mockHeatmapProvider{}
+	s.SetHeatmapProvider(provider)
+	handler := newVersionedTestHandler(t, s)
+
+	rec := auditDo(t, handler, "GET", HeatmapPath, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if provider.server != "" || provider.loc != time.UTC || provider.to.Sub(provider.from) != defaultHeatmapWeeks*7*24*time.Hour {
+		t.Errorf("Expected all servers over 4 weeks in UTC, got %q %v - %v in %v", provider.server, provider.from, provider.to, provider.loc)
+	}
+
+	rec = auditDo(t, handler, "GET", HeatmapPath+"?server=Track%20%231&weeks=2&tz=Europe/Oslo", "")
+	if rec.Code != http.StatusOK || provider.server != "Track #1" || provider.loc.String() != "Europe/Oslo" || provider.to.Sub(provider.from) != 14*24*time.Hour {
+		t.Errorf("Unexpected request %d: %q %v - %v in %v", rec.Code, provider.server, provider.from, provider.to, provider.loc)
+	}
+
+	if rec := auditDo(t, handler, "GET", HeatmapPath+"?server=Nope", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Unknown server: status = %d, want 404", rec.Code)
+	}
+	for _, query := range []string{"?weeks=0", "?weeks=14", "?weeks=two", "?tz=Mars/Olympus"} {
+		if rec := auditDo(t, handler, "GET", HeatmapPath+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// ImagesPath serves the proxied embed images without authentication ("/api/v1/images/{name}")
+// Only registered when an ImageProxy is set (IMAGE_PROXY=true)
+const ImagesPath = "/api/v1/images"
+
+// ErrUnknownImage is returned (wrapped) by ImageProxy for a name that is not proxied
+var ErrUnknownImage = errors.New("unknown image")
+
+// ImageProxy fetches and caches the images shown in the status embed, so Discord can load images the bot
+// reaches but Discord cannot (a logo on a LAN-only server_ip)
+type ImageProxy interface {
+	// Image returns the image called name and its content type; ErrUnknownImage for names that are not
+	// proxied, other errors when the source could not be fetched and nothing is cached
+	Image(ctx context.Context, name string) (data []byte, contentType string, err error)
+}
+
+// SetImageProxy enables the unauthenticated image proxy endpoint
+// Must be called before Start
+func (s *Server) SetImageProxy(p ImageProxy) {
+	s.images = p
+}
+
+// ProxiedImage returns one proxied embed image
+// No authentication required (Discord fetches it); rate limited like every other endpoint
+func (s *Server) ProxiedImage(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("ProxiedImage cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	data, contentType, err := s.images.Image(r.Context(), r.PathValue("name"))
+	switch {
+	case errors.Is(err, ErrUnknownImage):
+		WriteError(w, http.StatusNotFound, "Image not found", err.Error())
+		return
+	case err != nil:
+		log.Printf("ProxiedImage %s failed: %v", r.PathValue("name"), err)
+		WriteError(w, http.StatusBadGateway, "Image not available", "The image source could not be fetched")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(data); err != nil {
+		log.Printf("ProxiedImage write failed: %v", err)
+	}
+}
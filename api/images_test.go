@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+type mockImageProxy struct {
+	err error
+}
+
+func (m *mockImageProxy) Image(ctx context.Context, name string) ([]byte, string, error) {
+	if name != "image" {
+		return nil, "", ErrUnknownImage
+	}
+	if m.err != nil {
+		return nil, "", m.err
+	}
+	return []byte("\x89PNG"), "image/png", nil
+}
+
+func TestProxiedImage(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	proxy := &mockImageProxy{}
+	s.SetImageProxy(proxy)
+	handler := newPublicTestHandler(t, s)
+
+	tests := []struct {
+		name string
+		path string
+		err  error
+		want int
+	}{
+		{"served without auth", ImagesPath + "/image?v=1a2b3c4d", nil, http.StatusOK},
+		{"unknown name", ImagesPath + "/track", nil, http.StatusNotFound},
+		{"source down", ImagesPath + "/image", errors.New("connection refused"), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy.err = tt.err
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+			if rec.Code != tt.want {
+				t.Fatalf("Status = %d, want %d (body: %s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusOK && (rec.Header().Get("Content-Type") != "image/png" || rec.Body.String() != "\x89PNG") {
+				t.Errorf("Unexpected response %q (%s)", rec.Body.String(), rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestProxiedImage_DisabledByDefault(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newPublicTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", ImagesPath+"/image", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// ConfigWarningsHeader carries the number of lint warnings on successful config writes
+// The warnings themselves are in the "warnings" array of the response body
+const ConfigWarningsHeader = "X-Config-Warnings"
+
+// LintWarning is a non-fatal config issue: the config is valid and was saved, but probably not intended
+type LintWarning struct {
+	// Field is the JSON path of the offending setting (e.g. "servers[2].port")
+	Field string `json:"field"`
+	// Message explains the issue for display in the GUI
+	Message string `json:"message"`
+}
+
+// ConfigLinter reports non-fatal issues in the current config (may probe servers, so it can be slow)
+// Probes stop when ctx is done
+type ConfigLinter interface {
+	LintConfig(ctx context.Context) []LintWarning
+}
+
+// SetConfigLinter enables GET /api/config/lint and the warnings on config writes
+// Must be called before Start
+func (s *Server) SetConfigLinter(l ConfigLinter) {
+	s.linter = l
+}
+
+// lintResponse is the body of GET /api/config/lint
+type lintResponse struct {
+	Warnings []LintWarning `json:"warnings"`
+}
+
+// writeConfigWithWarnings responds to a successful config write with the current config
+// With a linter, the config that was just written is linted: the body gets a "warnings" array
+// next to the config keys (ignored when the config is sent back) and the header the warning count
+func (s *Server) writeConfigWithWarnings(w http.ResponseWriter, r *http.Request) {
+	cfg := s.cm.GetConfigAny()
+	if s.linter == nil {
+		WriteJSON(w, http.StatusOK, cfg)
+		return
+	}
+	warnings := s.linter.LintConfig(r.Context())
+	if warnings == nil {
+		warnings = []LintWarning{}
+	}
+	w.Header().Set(ConfigWarningsHeader, strconv.Itoa(len(warnings)))
+
+	// Splice the array into the encoded config so its key order is kept
+	data, err := json.Marshal(cfg)
+	if err != nil || len(data) < 2 || data[0] != '{' {
+		WriteJSON(w, http.StatusOK, cfg)
+		return
+	}
+	list, _ := json.Marshal(warnings)
+	body := bytes.NewBuffer(data[:len(data)-1])
+	if len(data) > 2 {
+		body.WriteByte(',')
+	}
+	body.WriteString(`"warnings":`)
+	body.Write(list)
+	body.WriteByte('}')
+	WriteJSON(w, http.StatusOK, json.RawMessage(body.Bytes()))
+}
+
+// LintConfig returns non-fatal warnings for the current config
+// Requires Bearer token authentication
+func (s *Server) LintConfig(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("LintConfig cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	warnings := s.linter.LintConfig(r.Context())
+	if warnings == nil {
+		warnings = []LintWarning{}
+	}
+	WriteJSON(w, http.StatusOK, lintResponse{Warnings: warnings})
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// mockConfigLinter is a test double for ConfigLinter
+type mockConfigLinter struct {
+	warnings []LintWarning
+	calls    int
+	ctx      context.Context
+}
+
+func (m *mockConfigLinter) LintConfig(ctx context.Context) []LintWarning {
+	m.calls++
+	m.ctx = ctx
+	return m.warnings
+}
+
+// TestPutConfig_WarningsHeader tests that successful writes return the lint warnings and their count
+func TestPutConfig_WarningsHeader(t *testing.T) {
+	cm := &mockConfigManagerWithWrites{config: map[string]interface{}{"server_ip": "192.168.1.1"}}
+	s := NewServer(cm, "18080", "test-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	linter := &mockConfigLinter{warnings: []LintWarning{
+		{Field: "update_interval", Message: "too low"},
+		{Field: "servers[0].port", Message: "unusual port"},
+	}}
+	s.SetConfigLinter(linter)
+
+	req := httptest.NewRequest("PUT", "/api/config", strings.NewReader(`{"server_ip":"10.0.0.1"}`))
+	rec := httptest.NewRecorder()
+	s.PutConfig(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get(ConfigWarningsHeader); got != "2" {
+		t.Errorf("%s = %q, want \"2\"", ConfigWarningsHeader, got)
+	}
+
+	// Body is still the config so existing clients are unaffected, plus the warnings
+	var body struct {
+		ServerIP string        `json:"server_ip"`
+		Warnings []LintWarning `json:"warnings"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.ServerIP != "10.0.0.1" {
+		t.Errorf("server_ip = %v, want 10.0.0.1", body.ServerIP)
+	}
+	if len(body.Warnings) != 2 || body.Warnings[1].Field != "servers[0].port" {
+		t.Errorf("warnings = %+v, want the two lint warnings", body.Warnings)
+	}
+	if linter.ctx != req.Context() {
+		t.Error("Expected the linter to get the request context")
+	}
+}
+
+// TestPutConfig_NoLintOnFailedWrite tests that rejected configs are not linted
+func TestPutConfig_NoLintOnFailedWrite(t *testing.T) {
+	cm := &mockConfigManagerWithWrites{writeErr: errors.New("server_ip cannot be empty")}
+	s := NewServer(cm, "18080", "test-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	linter := &mockConfigLinter{}
+	s.SetConfigLinter(linter)
+
+	req := httptest.NewRequest("PUT", "/api/config", strings.NewReader(`{"server_ip":""}`))
+	rec := httptest.NewRecorder()
+	s.PutConfig(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if linter.calls != 0 {
+		t.Errorf("Expected no lint on failed write, got %d calls", linter.calls)
+	}
+	if got := rec.Header().Get(ConfigWarningsHeader); got != "" {
+		t.Errorf("Expected no %s header on failed write, got %q", ConfigWarningsHeader, got)
+	}
+}
+
+// TestLintConfig_Endpoint tests GET /api/config/lint through the router and auth chain
+func TestLintConfig_Endpoint(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetConfigLinter(&mockConfigLinter{warnings: []LintWarning{{Field: "category_order", Message: "category 'GT3' has no servers"}}})
+	handler := newPublicTestHandler(t, s)
+
+	// Requires auth like every other config endpoint
+	req := httptest.NewRequest("GET", "/api/config/lint", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Unauthenticated status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	req = httptest.NewRequest("GET", "/api/config/lint", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var body lintResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Warnings) != 1 || body.Warnings[0].Field != "category_order" {
+		t.Errorf("Unexpected warnings: %+v", body.Warnings)
+	}
+}
+
+// TestLintConfig_EmptyListNotNull tests that a clean config returns an empty array, not null
+func TestLintConfig_EmptyListNotNull(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetConfigLinter(&mockConfigLinter{})
+
+	rec := httptest.NewRecorder()
+	s.LintConfig(rec, httptest.NewRequest("GET", "/api/config/lint", nil))
+
+	if got := strings.TrimSpace(rec.Body.String()); got != `{"warnings":[]}` {
+		t.Errorf("Body = %s, want {\"warnings\":[]}", got)
+	}
+}
+
+// TestLintConfig_NotRegisteredWithoutLinter tests that the endpoint is absent when no linter is set
+func TestLintConfig_NotRegisteredWithoutLinter(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newPublicTestHandler(t, s)
+
+	req := httptest.NewRequest("GET", "/api/config/lint", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PublicStatusServersPath serves the public status as one flat, filterable server list
+const PublicStatusServersPath = "/api/public/status/servers"
+
+// maxListLimit caps ?limit= on list endpoints
+const maxListLimit = 500
+
+// listQuery holds the filter, sort and page parameters of a list request
+// ?category= matches case-insensitively; ?online= only applies to lists with an "online" field;
+// ?sort= names a field from the endpoint's sortable list, ?order=asc|desc; ?limit=0 means no limit
+type listQuery struct {
+	category string
+	online   *bool
+	sort     string
+	desc     bool
+	limit    int
+	offset   int
+}
+
+// parseListQuery reads list parameters from r; sort must be one of sortable
+func parseListQuery(r *http.Request, sortable []string) (listQuery, error) {
+	q := r.URL.Query()
+	lq := listQuery{category: q.Get("category"), sort: q.Get("sort")}
+
+	if v := q.Get("online"); v != "" {
+		online, err := strconv.ParseBool(v)
+		if err != nil {
+			return lq, fmt.Errorf("online must be true or false, got %q", v)
+		}
+		lq.online = &online
+	}
+	if lq.sort != "" && !slices.Contains(sortable, lq.sort) {
+		return lq, fmt.Errorf("sort must be one of %s, got %q", strings.Join(sortable, ", "), lq.sort)
+	}
+	switch order := q.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		lq.desc = true
+	default:
+		return lq, fmt.Errorf("order must be asc or desc, got %q", order)
+	}
+
+	var err error
+	if lq.limit, err = listInt(q.Get("limit"), "limit"); err != nil {
+		return lq, err
+	}
+	if lq.limit > maxListLimit {
+		return lq, fmt.Errorf("limit must be at most %d", maxListLimit)
+	}
+	if lq.offset, err = listInt(q.Get("offset"), "offset"); err != nil {
+		return lq, err
+	}
+	return lq, nil
+}
+
+// listInt parses a non-negative integer parameter ("" = 0)
+func listInt(v, name string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", name, v)
+	}
+	return n, nil
+}
+
+// apply filters, sorts and pages items and returns the page plus the number of matches before paging
+// Sorting is stable and ties are broken by name so pages do not shuffle between requests
+func (lq listQuery) apply(items []map[string]any) ([]map[string]any, int) {
+	matched := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		if lq.category != "" && !strings.EqualFold(fmt.Sprint(item["category"]), lq.category) {
+			continue
+		}
+		if lq.online != nil && item["online"] != *lq.online {
+			continue
+		}
+		matched = append(matched, item)
+	}
+
+	if lq.sort != "" {
+		slices.SortStableFunc(matched, func(a, b map[string]any) int {
+			c := compareListValues(a[lq.sort], b[lq.sort])
+			if lq.desc {
+				c = -c
+			}
+			if c == 0 {
+				c = compareListValues(a["name"], b["name"])
+			}
+			return c
+		})
+	}
+
+	total := len(matched)
+	start := min(lq.offset, total)
+	end := total
+	if lq.limit > 0 {
+		end = min(start+lq.limit, total)
+	}
+	return matched[start:end], total
+}
+
+// compareListValues orders JSON numbers numerically and everything else as case-insensitive text
+// Missing values sort first
+func compareListValues(a, b any) int {
+	af, aNum := a.(float64)
+	bf, bNum := b.(float64)
+	switch {
+	case aNum && bNum:
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return strings.Compare(strings.ToLower(fmt.Sprint(a)), strings.ToLower(fmt.Sprint(b)))
+}
+
+// toJSONMap converts a value from main (which api cannot import) to generic JSON
+func toJSONMap(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// jsonObjects returns the objects of a decoded JSON array (non-objects are skipped)
+func jsonObjects(v any) []map[string]any {
+	arr, _ := v.([]any)
+	out := make([]map[string]any, 0, len(arr))
+	for _, item := range arr {
+		if m, ok := item.(map[string]any); ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// PublicStatusServers returns every server of the public status as a flat list with its category
+// Supports ?category=, ?online=, ?sort=name|players|max_players|category, ?order=, ?limit= and ?offset=;
+// "total" is the number of matches before limit/offset
+func (s *Server) PublicStatusServers(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("PublicStatusServers cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	lq, err := parseListQuery(r, []string{"name", "players", "max_players", "category"})
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid query", err.Error())
+		return
+	}
+
+	status := s.status.PublicStatus()
+	if status == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Status not available yet", "No poll has completed since startup")
+		return
+	}
+	doc, err := toJSONMap(status)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to serialize status", err.Error())
+		return
+	}
+
+	var servers []map[string]any
+	for _, cat := range jsonObjects(doc["categories"]) {
+		for _, srv := range jsonObjects(cat["servers"]) {
+			srv["category"] = cat["name"]
+			servers = append(servers, srv)
+		}
+	}
+	page, total := lq.apply(servers)
+
+	w.Header().Set("Cache-Control", "public, max-age=5")
+	WriteJSONWithETag(w, r, map[string]any{
+		"updated_at": doc["updated_at"],
+		"total":      total,
+		"servers":    page,
+	})
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testPublicStatus is a status document with three servers in two categories
+func testPublicStatus() map[string]any {
+	return map[string]any{
+		"updated_at":    "2026-01-01T12:00:00Z",
+		"total_players": 9,
+		"categories": []map[string]any{
+			{"name": "Drift", "servers": []map[string]any{
+				{"name": "Drift 1", "online": true, "players": 2, "max_players": 16},
+				{"name": "Drift 2", "online": false, "players": 0, "max_players": 16},
+			}},
+			{"name": "Track", "servers": []map[string]any{
+				{"name": "Track 1", "online": true, "players": 7, "max_players": 24},
+			}},
+		},
+	}
+}
+
+// serverNames lists the "name" fields of decoded servers
+func serverNames(servers []any) string {
+	var names []string
+	for _, s := range servers {
+		names = append(names, s.(map[string]any)["name"].(string))
+	}
+	return strings.Join(names, ",")
+}
+
+func TestPublicStatusServers(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetStatusProvider(&mockStatusProvider{status: testPublicStatus()})
+	handler := newPublicTestHandler(t, s)
+
+	tests := []struct {
+		query     string
+		wantNames string
+		wantTotal float64
+	}{
+		{"", "Drift 1,Drift 2,Track 1", 3},
+		{"?category=drift", "Drift 1,Drift 2", 2},
+		{"?online=true", "Drift 1,Track 1", 2},
+		{"?sort=players&order=desc", "Track 1,Drift 1,Drift 2", 3},
+		{"?sort=max_players&order=desc", "Track 1,Drift 1,Drift 2", 3}, // ties by name
+		{"?sort=players&order=desc&limit=1&offset=1", "Drift 1", 3},
+		{"?offset=10", "", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", PublicStatusServersPath+tt.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Status = %d (body: %s)", rec.Code, rec.Body.String())
+			}
+			var body struct {
+				Total   float64 `json:"total"`
+				Servers []any   `json:"servers"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if got := serverNames(body.Servers); got != tt.wantNames || body.Total != tt.wantTotal {
+				t.Errorf("Got %q (total %v), want %q (total %v)", got, body.Total, tt.wantNames, tt.wantTotal)
+			}
+		})
+	}
+
+	// Each server carries its category
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", PublicStatusServersPath+"?category=Track", nil))
+	if !strings.Contains(rec.Body.String(), `"category":"Track"`) {
+		t.Errorf("Expected category on servers, got %s", rec.Body.String())
+	}
+}
+
+func TestPublicStatusServers_InvalidQuery(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetStatusProvider(&mockStatusProvider{status: testPublicStatus()})
+	handler := newPublicTestHandler(t, s)
+
+	for _, query := range []string{"?sort=port", "?order=up", "?limit=-1", "?limit=501", "?offset=x", "?online=maybe"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", PublicStatusServersPath+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: Status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestHandlers_GetServersQuery(t *testing.T) {
+	cm := &mockConfigManagerWithWrites{config: map[string]interface{}{
+		"servers": []map[string]interface{}{
+			{"name": "b", "port": 8082, "category": "Drift"},
+			{"name": "a", "port": 8083, "category": "Track"},
+			{"name": "c", "port": 8081, "category": "Drift"},
+		},
+	}}
+	s := NewServer(cm, "18080", "test-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.GetServers(rec, httptest.NewRequest("GET", "/api/config/servers"+query, nil))
+		return rec
+	}
+
+	rec := get("?category=Drift&sort=port&limit=1")
+	var servers []any
+	if err := json.NewDecoder(rec.Body).Decode(&servers); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if serverNames(servers) != "c" || rec.Header().Get("X-Total-Count") != "2" {
+		t.Errorf("Got %q (total %s), want c (total 2)", serverNames(servers), rec.Header().Get("X-Total-Count"))
+	}
+
+	// Without parameters the full list is returned in config order
+	rec = get("")
+	servers = nil
+	json.NewDecoder(rec.Body).Decode(&servers)
+	if serverNames(servers) != "b,a,c" {
+		t.Errorf("Expected config order, got %q", serverNames(servers))
+	}
+
+	for _, query := range []string{"?online=true", "?sort=players"} {
+		if rec := get(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: Status = %d, want 400", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogsPath serves the most recent log lines (?tail=N, default DefaultLogTail)
+const LogsPath = "/api/v1/logs"
+
+// LogsStreamPath streams new log lines as server-sent events
+const LogsStreamPath = "/api/v1/logs/stream"
+
+// DefaultLogTail is the number of lines returned by LogsPath without a tail parameter
+const DefaultLogTail = 200
+
+// logStreamKeepAlive is how often an idle stream sends an SSE comment, so proxies keep the connection open
+var logStreamKeepAlive = 30 * time.Second
+
+// LogLine is one log entry. Seq increases by one per entry, so gaps show dropped lines
+type LogLine struct {
+	Seq  uint64 `json:"seq"`
+	Line string `json:"line"`
+}
+
+// LogsResponse is the body of LogsPath
+type LogsResponse struct {
+	Lines []LogLine `json:"lines"`
+	// Capacity is the number of lines the buffer keeps (the largest useful tail)
+	Capacity int `json:"capacity"`
+}
+
+// LogSource is a buffer of recent, already redacted log lines
+type LogSource interface {
+	// Tail returns up to n of the newest lines, oldest first
+	Tail(n int) []LogLine
+	// Since returns the buffered lines after seq, oldest first
+	Since(seq uint64) []LogLine
+	// Subscribe returns a channel receiving new lines and a function ending the subscription
+	// Lines are dropped for a subscriber that does not keep up
+	Subscribe() (<-chan LogLine, func())
+	Capacity() int
+}
+
+// SetLogSource enables GET /api/v1/logs and the SSE tail at /api/v1/logs/stream
+// Must be called before Start
+func (s *Server) SetLogSource(l LogSource) {
+	s.logs = l
+}
+
+// DisableLogStream leaves out the SSE tail at /api/v1/logs/stream (log_stream feature flag)
+// Must be called before Start
+func (s *Server) DisableLogStream() {
+	s.noLogStream = true
+}
+
+// GetLogs returns the newest log lines
+// Requires Bearer token authentication
+func (s *Server) GetLogs(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("GetLogs cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	tail := DefaultLogTail
+	if v := r.URL.Query().Get("tail"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			WriteError(w, http.StatusBadRequest, "Invalid tail", "tail must be a positive number of lines")
+			return
+		}
+		tail = n
+	}
+	if c := s.logs.Capacity(); tail > c {
+		tail = c
+	}
+
+	lines := s.logs.Tail(tail)
+	if lines == nil {
+		lines = []LogLine{}
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	WriteJSON(w, http.StatusOK, LogsResponse{Lines: lines, Capacity: s.logs.Capacity()})
+}
+
+// StreamLogs sends new log lines as server-sent events until the client disconnects or the server stops
+// Each event carries the line's Seq as its ID; a reconnect with Last-Event-ID first replays the buffered lines
+// after that ID, and ?tail=N starts a fresh stream with the newest N lines
+// Requires Bearer token authentication
+func (s *Server) StreamLogs(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	lines, unsubscribe := s.logs.Subscribe()
+	defer unsubscribe()
+
+	// Subscribed first, so no line falls between the backlog and the live lines
+	var backlog []LogLine
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		seq, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid Last-Event-ID", "Last-Event-ID must be a log line sequence number")
+			return
+		}
+		backlog = s.logs.Since(seq)
+	} else if v := r.URL.Query().Get("tail"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			WriteError(w, http.StatusBadRequest, "Invalid tail", "tail must be a non-negative number of lines")
+			return
+		}
+		if n > 0 {
+			backlog = s.logs.Tail(n)
+		}
+	}
+
+	// The stream outlives the server's write timeout
+	rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx: do not buffer the stream
+	w.WriteHeader(http.StatusOK)
+
+	var last uint64
+	for _, l := range backlog {
+		writeLogEvent(w, l)
+		last = l.Seq
+	}
+	if err := rc.Flush(); err != nil {
+		log.Printf("StreamLogs: streaming not supported: %v", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(logStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.stopping:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case l := <-lines:
+			if l.Seq <= last { // already sent with the backlog
+				continue
+			}
+			writeLogEvent(w, l)
+			last = l.Seq
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeLogEvent writes l as one SSE event (multi-line entries become several data fields)
+func writeLogEvent(w http.ResponseWriter, l LogLine) {
+	fmt.Fprintf(w, "id: %d\n", l.Seq)
+	for _, line := range strings.Split(l.Line, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
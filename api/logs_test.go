@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockLogSource holds fixed lines; Subscribe hands out a new channel the test feeds via send
+type mockLogSource struct {
+	lines []LogLine
+	mu    sync.Mutex
+	live  chan LogLine
+}
+
+func (m *mockLogSource) Tail(n int) []LogLine {
+	if n < len(m.lines) {
+		return m.lines[len(m.lines)-n:]
+	}
+	return m.lines
+}
+
+func (m *mockLogSource) Since(seq uint64) []LogLine {
+	for i, l := range m.lines {
+		if l.Seq > seq {
+			return m.lines[i:]
+		}
+	}
+	return nil
+}
+
+func (m *mockLogSource) Subscribe() (<-chan LogLine, func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.live = make(chan LogLine, 4)
+	return m.live, func() {}
+}
+
+// send delivers l to the latest subscriber
+func (m *mockLogSource) send(l LogLine) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.live <- l
+}
+
+func (m *mockLogSource) Capacity() int { return 3 }
+
+func newMockLogSource() *mockLogSource {
+	return &mockLogSource{
+		lines: []LogLine{{Seq: 1, Line: "one"}, {Seq: 2, Line: "two"}, {Seq: 3, Line: "three"}},
+	}
+}
+
+func TestGetLogs(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetLogSource(newMockLogSource())
+	handler := newVersionedTestHandler(t, s)
+
+	tests := []struct {
+		query  string
+		status int
+		lines  int
+	}{
+		{"", http.StatusOK, 3},
+		{"?tail=2", http.StatusOK, 2},
+		{"?tail=500", http.StatusOK, 3}, // capped at the capacity
+		{"?tail=0", http.StatusBadRequest, 0},
+		{"?tail=abc", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, authedRequest("GET", LogsPath+tt.query))
+		if rec.Code != tt.status {
+			t.Errorf("%q: status = %d, want %d (body: %s)", tt.query, rec.Code, tt.status, rec.Body.String())
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var resp LogsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(resp.Lines) != tt.lines || resp.Capacity != 3 || resp.Lines[len(resp.Lines)-1].Line != "three" {
+			t.Errorf("%q: unexpected response %+v", tt.query, resp)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", LogsPath, nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", rec.Code)
+	}
+}
+
+func TestGetLogs_Disabled(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newVersionedTestHandler(t, s)
+
+	for _, path := range []string{LogsPath, LogsStreamPath} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, authedRequest("GET", path))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404 without a log source, got %d", path, rec.Code)
+		}
+	}
+}
+
+// TestStreamLogs_Disabled tests that DisableLogStream removes only the stream
+func TestStreamLogs_Disabled(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetLogSource(newMockLogSource())
+	s.DisableLogStream()
+	handler := newVersionedTestHandler(t, s)
+
+	for path, want := range map[string]int{LogsPath: http.StatusOK, LogsStreamPath: http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, authedRequest("GET", path))
+		if rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+// readEvents reads n SSE events (id and joined data lines)
+func readEvents(t *testing.T, r *bufio.Reader, n int) []LogLine {
+	t.Helper()
+	var events []LogLine
+	var cur LogLine
+	var data []string
+	for len(events) < n {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Stream ended after %d events: %v", len(events), err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			json.Unmarshal([]byte(strings.TrimPrefix(line, "id: ")), &cur.Seq)
+		case strings.HasPrefix(line, "data: "):
+			data = append(data, strings.TrimPrefix(line, "data: "))
+		case line == "" && data != nil:
+			cur.Line = strings.Join(data, "\n")
+			events = append(events, cur)
+			cur, data = LogLine{}, nil
+		}
+	}
+	return events
+}
+
+func TestStreamLogs(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	source := newMockLogSource()
+	s.SetLogSource(source)
+	stopping := make(chan struct{})
+	s.stopping = stopping
+	// Logger wraps the writer: flushing must reach the connection through it
+	srv := httptest.NewServer(Logger(s.logger)(newVersionedTestHandler(t, s)))
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		name   string
+		path   string
+		header string
+		want   []string
+	}{
+		{"tail", LogsStreamPath + "?tail=2", "", []string{"two", "three"}},
+		{"resume", LogsStreamPath, "1", []string{"two", "three"}},
+		{"v2", "/api/v2/logs/stream?tail=1", "", []string{"three"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+tt.path, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+			if tt.header != "" {
+				req.Header.Set("Last-Event-ID", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+				t.Fatalf("Unexpected response %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+			}
+
+			// A line already sent with the backlog is skipped, a multi-line entry stays one event
+			source.send(LogLine{Seq: 3, Line: "three"})
+			source.send(LogLine{Seq: 4, Line: "panic: boom\ngoroutine 1"})
+			events := readEvents(t, bufio.NewReader(resp.Body), len(tt.want)+1)
+			for i, want := range tt.want {
+				if events[i].Line != want {
+					t.Errorf("Event %d = %+v, want %q", i, events[i], want)
+				}
+			}
+			if last := events[len(tt.want)]; last.Seq != 4 || last.Line != "panic: boom\ngoroutine 1" {
+				t.Errorf("Unexpected live event %+v", last)
+			}
+		})
+	}
+
+	// Shutdown ends open streams instead of waiting for the drain timeout
+	req, _ := http.NewRequest("GET", srv.URL+LogsStreamPath, nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	close(stopping)
+	done := make(chan struct{})
+	go func() {
+		bufio.NewReader(resp.Body).ReadString(0)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the stream to end on shutdown")
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// MaintenancePath lists servers in maintenance mode; MaintenancePath/{server} sets (PUT) or clears (DELETE) it
+const MaintenancePath = "/api/v1/maintenance"
+
+// ErrUnknownServer is returned (wrapped) by MaintenanceController when no configured server has the name;
+// handlers answer 404 Not Found
+var ErrUnknownServer = errors.New("unknown server")
+
+// MaintenanceEntry is a server in maintenance mode
+type MaintenanceEntry struct {
+	Server string    `json:"server"`
+	Reason string    `json:"reason,omitempty"`
+	SetBy  string    `json:"set_by,omitempty"`
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until"`
+}
+
+// MaintenanceRequest is the body of PUT MaintenancePath/{server}; both fields are optional
+type MaintenanceRequest struct {
+	TTL    string `json:"ttl,omitempty"` // Go duration like "90m", empty = the default TTL
+	Reason string `json:"reason,omitempty"`
+}
+
+// MaintenanceController manages the runtime maintenance flags (not saved in the config)
+type MaintenanceController interface {
+	ListMaintenance() []MaintenanceEntry
+	// SetMaintenance flags server for ttl (0 = default); errors other than ErrUnknownServer are bad input
+	SetMaintenance(server string, ttl time.Duration, reason, setBy string) (MaintenanceEntry, error)
+	// ClearMaintenance reports whether the server was in maintenance mode
+	ClearMaintenance(server string) bool
+}
+
+// SetMaintenanceController enables the maintenance endpoints
+// Must be called before Start
+func (s *Server) SetMaintenanceController(m MaintenanceController) {
+	s.maintenance = m
+}
+
+// ListMaintenance returns the servers in maintenance mode
+// Requires Bearer token authentication
+func (s *Server) ListMaintenance(w http.ResponseWriter, r *http.Request) {
+	entries := s.maintenance.ListMaintenance()
+	if entries == nil {
+		entries = []MaintenanceEntry{}
+	}
+	WriteJSON(w, http.StatusOK, map[string][]MaintenanceEntry{"servers": entries})
+}
+
+// SetMaintenance puts a server in maintenance mode until the TTL passes or it is cleared
+// Requires Bearer token authentication and CSRF token; an empty body uses the default TTL
+func (s *Server) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("SetMaintenance cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	var req MaintenanceRequest
+	if r.Body != nil {
+		defer r.Body.Close()
+		r.Body = http.MaxBytesReader(w, r.Body, 4<<10)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			WriteError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+			return
+		}
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil || d <= 0 {
+			WriteError(w, http.StatusBadRequest, "Invalid ttl", "ttl must be a positive duration like 90m")
+			return
+		}
+		ttl = d
+	}
+
+	entry, err := s.maintenance.SetMaintenance(r.PathValue("server"), ttl, req.Reason, "api")
+	if errors.Is(err, ErrUnknownServer) {
+		WriteError(w, http.StatusNotFound, "Server not found", err.Error())
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid maintenance request", err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, entry)
+}
+
+// ClearMaintenance ends maintenance mode for a server
+// Requires Bearer token authentication and CSRF token
+func (s *Server) ClearMaintenance(w http.ResponseWriter, r *http.Request) {
+	server := r.PathValue("server")
+	if !s.maintenance.ClearMaintenance(server) {
+		WriteError(w, http.StatusNotFound, "Not in maintenance", "server '"+server+"' is not in maintenance mode")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+// mockMaintenance keeps flags in a map; only "Drift #1" and "Track #1" exist
+type mockMaintenance struct {
+	entries map[string]MaintenanceEntry
+	lastTTL time.Duration
+}
+
+func (m *mockMaintenance) ListMaintenance() []MaintenanceEntry {
+	var out []MaintenanceEntry
+	for _, e := range m.entries {
+		out = append(out, e)
+	}
+	return out
+}
+
+func (m *mockMaintenance) SetMaintenance(server string, ttl time.Duration, reason, setBy string) (MaintenanceEntry, error) {
+	if server != "Drift #1" && server != "Track #1" {
+		return MaintenanceEntry{}, fmt.Errorf("%w '%s'", ErrUnknownServer, server)
+	}
+	if ttl > 24*time.Hour {
+		return MaintenanceEntry{}, errors.New("ttl too long")
+	}
+	m.lastTTL = ttl
+	e := MaintenanceEntry{Server: server, Reason: reason, SetBy: setBy, Until: time.Now().Add(time.Hour)}
+	m.entries[server] = e
+	return e, nil
+}
+
+func (m *mockMaintenance) ClearMaintenance(server string) bool {
+	_, ok := m.entries[server]
+	delete(m.entries, server)
+	return ok
+}
+
+func TestMaintenanceEndpoints(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	m := &mockMaintenance{entries: make(map[string]MaintenanceEntry)}
+	s.SetMaintenanceController(m)
+	handler := newVersionedTestHandler(t, s)
+
+	rec := auditDo(t, handler, "PUT", MaintenancePath+"/Drift%20%231", `{"ttl":"90m","reason":"track update"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Set status = %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	var entry MaintenanceEntry
+	json.NewDecoder(rec.Body).Decode(&entry)
+	if entry.Server != "Drift #1" || entry.Reason != "track update" || entry.SetBy != "api" || m.lastTTL != 90*time.Minute {
+		t.Errorf("Unexpected entry %+v (ttl %v)", entry, m.lastTTL)
+	}
+
+	// An empty body uses the default TTL
+	if rec := auditDo(t, handler, "PUT", MaintenancePath+"/Track%20%231", ""); rec.Code != http.StatusOK || m.lastTTL != 0 {
+		t.Errorf("Empty body: status %d, ttl %v", rec.Code, m.lastTTL)
+	}
+
+	rec = auditDo(t, handler, "GET", MaintenancePath, "")
+	var list map[string][]MaintenanceEntry
+	json.NewDecoder(rec.Body).Decode(&list)
+	if rec.Code != http.StatusOK || len(list["servers"]) != 2 {
+		t.Errorf("List: status %d, %+v", rec.Code, list)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"unknown server", "PUT", MaintenancePath + "/Nope", `{}`, http.StatusNotFound},
+		{"bad ttl", "PUT", MaintenancePath + "/Track%20%231", `{"ttl":"soon"}`, http.StatusBadRequest},
+		{"rejected ttl", "PUT", MaintenancePath + "/Track%20%231", `{"ttl":"48h"}`, http.StatusBadRequest},
+		{"bad json", "PUT", MaintenancePath + "/Track%20%231", `{`, http.StatusBadRequest},
+		{"clear", "DELETE", MaintenancePath + "/Drift%20%231", "", http.StatusNoContent},
+		{"clear again", "DELETE", MaintenancePath + "/Drift%20%231", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := auditDo(t, handler, tt.method, tt.path, tt.body); rec.Code != tt.status {
+				t.Errorf("Status = %d, want %d (body: %s)", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestMaintenanceEndpoints_Disabled(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newVersionedTestHandler(t, s)
+	if rec := auditDo(t, handler, "GET", MaintenancePath, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a controller, got %d", rec.Code)
+	}
+}
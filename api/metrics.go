@@ -0,0 +1,30 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/bombom/absa-ac/pkg/metrics"
+)
+
+// MetricsPath serves the metrics of the whole binary (API, proxy, Discord) in the Prometheus text format
+const MetricsPath = "/metrics"
+
+// API metrics besides the shared request and auth failure counters (see pkg/metrics)
+var (
+	csrfRejections = metrics.NewCounter("absa_csrf_rejections_total",
+		"State-changing requests rejected by the CSRF check, by component and reason (missing, invalid)", "component", "reason")
+	rateLimited = metrics.NewCounter("absa_rate_limited_total",
+		"Requests rejected by rate limiting, by component", "component")
+)
+
+// Metrics serves the process metrics for Prometheus scrapes
+// Requires Bearer token authentication
+func (s *Server) Metrics(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("Metrics cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	metrics.Handler().ServeHTTP(w, r)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bombom/absa-ac/pkg/metrics"
+)
+
+func TestMetrics_RequiresAuth(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newVersionedTestHandler(t, s)
+	missing := metrics.AuthFailures.Value(metrics.ComponentAPI, metrics.AuthMissing)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", MetricsPath, nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", rec.Code)
+	}
+	if metrics.AuthFailures.Value(metrics.ComponentAPI, metrics.AuthMissing) != missing+1 {
+		t.Error("Expected the missing token counted as an auth failure")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", MetricsPath))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != metrics.ContentType {
+		t.Fatalf("Expected metrics, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), `absa_auth_failures_total{component="api",reason="missing"}`) {
+		t.Errorf("Expected the auth failure in the exposition, got:\n%s", rec.Body.String())
+	}
+}
+
+func TestMetrics_CountsRequestsAndCSRF(t *testing.T) {
+	var logged strings.Builder
+	handler := Logger(log.New(&logged, "", 0))(CSRF(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	requests := metrics.HTTPRequests.Value(metrics.ComponentAPI, "PATCH", "403")
+	missing := csrfRejections.Value(metrics.ComponentAPI, "missing")
+	invalid := csrfRejections.Value(metrics.ComponentAPI, "invalid")
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PATCH", "/api/config", nil))
+	req := httptest.NewRequest("PATCH", "/api/config", nil)
+	req.Header.Set("X-CSRF-Token", "wrong")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := metrics.HTTPRequests.Value(metrics.ComponentAPI, "PATCH", "403"); got != requests+2 {
+		t.Errorf("Expected 2 more PATCH 403 requests, got %v", got-requests)
+	}
+	if csrfRejections.Value(metrics.ComponentAPI, "missing") != missing+1 || csrfRejections.Value(metrics.ComponentAPI, "invalid") != invalid+1 {
+		t.Error("Expected one missing and one invalid CSRF rejection")
+	}
+}
@@ -13,6 +13,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/bombom/absa-ac/pkg/metrics"
+	"github.com/bombom/absa-ac/pkg/requestid"
+	"github.com/bombom/absa-ac/pkg/supervisor"
 	"golang.org/x/time/rate"
 )
 
@@ -115,6 +118,7 @@ func extractClientIP(r *http.Request, trustedProxies []string) string {
 			"reason", "xff_from_untrusted_source",
 			"xff_header", forwardedFor,
 			"remote_addr", r.RemoteAddr,
+			"request_id", requestid.From(r),
 			"remote_ip", normalizedRemoteIP,
 			"trusted_proxies", trustedProxies,
 		)
@@ -129,6 +133,7 @@ func extractClientIP(r *http.Request, trustedProxies []string) string {
 			"xff_count", len(parts),
 			"xff_header", forwardedFor,
 			"remote_addr", r.RemoteAddr,
+			"request_id", requestid.From(r),
 			"max_allowed", maxForwardedIps,
 		)
 		return r.RemoteAddr
@@ -149,6 +154,7 @@ func extractClientIP(r *http.Request, trustedProxies []string) string {
 				"reason", "invalid_or_non_routable_ip",
 				"xff_header", forwardedFor,
 				"remote_addr", r.RemoteAddr,
+				"request_id", requestid.From(r),
 				"invalid_ip", ipStr,
 				"normalized_ip", normalizedIP,
 			)
@@ -169,6 +175,7 @@ func extractClientIP(r *http.Request, trustedProxies []string) string {
 		"reason", "all_ips_are_trusted_proxies",
 		"xff_header", forwardedFor,
 		"remote_addr", r.RemoteAddr,
+		"request_id", requestid.From(r),
 		"trusted_proxies", trustedProxies,
 	)
 	return r.RemoteAddr
@@ -180,14 +187,15 @@ func extractClientIP(r *http.Request, trustedProxies []string) string {
 func BearerAuth(token string, trustedProxies []string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Health check bypasses auth
-			if r.URL.Path == "/health" {
+			// Health check and public status bypass auth
+			if r.URL.Path == "/health" || isPublicPath(r.URL.Path) {
 				next.ServeHTTP(w, r)
 				return
 			}
 
 			auth := r.Header.Get("Authorization")
 			if auth == "" {
+				metrics.AuthFailures.Inc(metrics.ComponentAPI, metrics.AuthMissing)
 				WriteError(w, http.StatusUnauthorized, "Missing Authorization header",
 					"Request requires Bearer token authentication")
 				return
@@ -196,6 +204,7 @@ func BearerAuth(token string, trustedProxies []string) func(http.Handler) http.H
 			// Validate "Bearer <token>" format
 			const prefix = "Bearer "
 			if len(auth) < len(prefix) || auth[:len(prefix)] != prefix {
+				metrics.AuthFailures.Inc(metrics.ComponentAPI, metrics.AuthMalformed)
 				WriteError(w, http.StatusUnauthorized, "Invalid Authorization header format",
 					"Expected format: Authorization: Bearer <token>")
 				return
@@ -213,8 +222,10 @@ func BearerAuth(token string, trustedProxies []string) func(http.Handler) http.H
 					"reason", "invalid_token",
 					"ip", clientIP,
 					"token", "<redacted>",
+					"request_id", requestid.From(r),
 				)
 
+				metrics.AuthFailures.Inc(metrics.ComponentAPI, metrics.AuthInvalid)
 				WriteError(w, http.StatusUnauthorized, "Invalid Bearer token",
 					"The provided token is not valid")
 				return
@@ -227,6 +238,7 @@ func BearerAuth(token string, trustedProxies []string) func(http.Handler) http.H
 			slog.Info("auth_attempt",
 				"success", true,
 				"ip", clientIP,
+				"request_id", requestid.From(r),
 			)
 
 			next.ServeHTTP(w, r)
@@ -293,21 +305,21 @@ func (rm *rateLimiterManager) cleanupStaleLimiters() {
 }
 
 // startCleanupGoroutine launches background cleanup for stale rate limiters
-// Stops gracefully when context is cancelled
+// Stops gracefully when context is cancelled; supervised so a panic in the loop restarts it
 func (rm *rateLimiterManager) startCleanupGoroutine() {
-	go func() {
+	go supervisor.Run(rm.ctx, "rate limit cleanup", func(ctx context.Context) error {
 		ticker := time.NewTicker(cleanupInterval)
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
 				rm.cleanupStaleLimiters()
-			case <-rm.ctx.Done():
+			case <-ctx.Done():
 				slog.Info("rate_limit_cleanup_shutdown")
-				return
+				return nil
 			}
 		}
-	}()
+	}, supervisor.Options{InitialBackoff: cleanupRestartDelay})
 }
 
 // RateLimit implements token bucket rate limiting per client IP
@@ -362,6 +374,7 @@ func RateLimit(requestsPerSecond int, burstSize int, trustedProxies []string, ct
 
 			// Check rate limit
 			if !rl.limiter.Allow() {
+				rateLimited.Inc(metrics.ComponentAPI)
 				WriteError(w, http.StatusTooManyRequests, "Rate limit exceeded",
 					fmt.Sprintf("Maximum of %d requests per second allowed", requestsPerSecond))
 				return
@@ -384,13 +397,15 @@ func Logger(logger *log.Logger) func(http.Handler) http.Handler {
 
 			next.ServeHTTP(wrapped, r)
 
-			// Log request (method, path, status, duration - no headers logged)
+			// Log request (method, path, status, duration, request ID - no headers logged)
 			duration := time.Since(start)
-			logger.Printf("%s %s - %d (%v)",
+			metrics.ObserveRequest(metrics.ComponentAPI, r.Method, wrapped.status, duration)
+			logger.Printf("%s %s - %d (%v) request_id=%s",
 				r.Method,
 				r.URL.Path,
 				wrapped.status,
 				duration,
+				requestid.From(r),
 			)
 		})
 	}
@@ -407,6 +422,11 @@ func (rw *responseWriter) WriteHeader(status int) {
 	rw.ResponseWriter.WriteHeader(status)
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController (flushing streamed responses)
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // CORS implements Cross-Origin Resource Sharing middleware
 // allowedOrigins is a list of allowed origin URLs (e.g., "https://example.com")
 // Empty list means no CORS headers are set (same-origin only)
@@ -422,6 +442,18 @@ func CORS(allowedOrigins []string) func(http.Handler) http.Handler {
 				return
 			}
 
+			// Public read-only endpoints may be embedded by any site (no credentials allowed)
+			if isPublicPath(r.URL.Path) {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+				if r.Method == "OPTIONS" {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Check if origin is allowed
 			// Validate allowlist: reject "*" mixed with other origins (security risk)
 			hasWildcard := false
@@ -457,6 +489,7 @@ func CORS(allowedOrigins []string) func(http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, PUT, DELETE, OPTIONS")
 			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Expose-Headers", ConfigWarningsHeader+", "+AuditIDHeader+", "+requestid.Header)
 
 			// Handle preflight requests
 			if r.Method == "OPTIONS" {
@@ -2,7 +2,9 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
@@ -14,6 +16,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bombom/absa-ac/pkg/requestid"
 	"golang.org/x/time/rate"
 )
 
@@ -1205,3 +1208,36 @@ func TestRateLimiterRecreation(t *testing.T) {
 		t.Errorf("Follow-up request failed: %d", rec.Code)
 	}
 }
+
+// TestRequestID_ErrorResponses tests that v1 and v2 error bodies name the request ID of the response header
+func TestRequestID_ErrorResponses(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(io.Discard, "", 0))
+	handler := requestid.Middleware(newVersionedTestHandler(t, s))
+
+	// v1: unauthenticated, with a client-supplied ID
+	req := httptest.NewRequest("GET", "/api/config", nil)
+	req.Header.Set(requestid.Header, "trace-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	var v1 ErrorResponse
+	json.NewDecoder(rec.Body).Decode(&v1)
+	if rec.Code != http.StatusUnauthorized || v1.RequestID != "trace-1" || rec.Header().Get(requestid.Header) != "trace-1" {
+		t.Errorf("Expected 401 naming trace-1, got %d %+v", rec.Code, v1)
+	}
+
+	// v2: an invalid incoming ID is replaced by a generated one
+	req = authedRequest("GET", "/api/v2/config/servers?sort=bogus")
+	req.Header.Set(requestid.Header, "bad id\n")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	var v2 struct {
+		Error struct {
+			RequestID string `json:"request_id"`
+		} `json:"error"`
+	}
+	json.NewDecoder(rec.Body).Decode(&v2)
+	id := rec.Header().Get(requestid.Header)
+	if rec.Code != http.StatusBadRequest || !requestid.Valid(id) || v2.Error.RequestID != id {
+		t.Errorf("Expected 400 naming the generated ID %q, got %d %+v", id, rec.Code, v2)
+	}
+}
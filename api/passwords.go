@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+// PasswordsPath lists the join passwords of password-protected servers; POST PasswordsPath/{server}/rotate
+// replaces one with a generated password
+const PasswordsPath = "/api/v1/passwords"
+
+// ServerPassword is the join password of a server and the Discord roles allowed to receive it
+type ServerPassword struct {
+	Server    string   `json:"server"`
+	Password  string   `json:"password"`
+	Roles     []string `json:"roles"`
+	RotatedAt string   `json:"rotated_at,omitempty"` // RFC 3339
+}
+
+// PasswordManager reads and rotates the server passwords saved in the config
+type PasswordManager interface {
+	Passwords() []ServerPassword
+	// RotatePassword saves a new generated password for server; ErrUnknownServer for a server without a
+	// password, other errors are write failures (ErrConfigReadOnly for a read-only config)
+	RotatePassword(server string) (ServerPassword, error)
+}
+
+// SetPasswordManager enables the password endpoints
+// Must be called before Start
+func (s *Server) SetPasswordManager(m PasswordManager) {
+	s.passwords = m
+}
+
+// ListPasswords returns the passwords of all password-protected servers
+// Requires Bearer token authentication
+func (s *Server) ListPasswords(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string][]ServerPassword{"passwords": s.passwords.Passwords()})
+}
+
+// RotatePassword replaces the password of one server with a generated one and saves the config
+// The game server keeps the old password until its config is deployed again
+// Requires Bearer token authentication and CSRF token; the change is recorded in the config audit log
+func (s *Server) RotatePassword(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("RotatePassword cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	server := r.PathValue("server")
+	var p ServerPassword
+	err := s.auditedWrite(w, r, func() error {
+		var err error
+		p, err = s.passwords.RotatePassword(server)
+		return err
+	})
+	if errors.Is(err, ErrUnknownServer) {
+		WriteError(w, http.StatusNotFound, "Server not found", err.Error())
+		return
+	}
+	if err != nil {
+		writeConfigWriteError(w, "Rotation failed", err)
+		return
+	}
+	log.Printf("Audit: password of '%s' rotated via the API from %s", server, extractClientIP(r, s.trustedProxies))
+	WriteJSON(w, http.StatusOK, p)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+)
+
+// mockPasswords keeps one password for "Track #1"; rotation appends "-new"
+type mockPasswords struct {
+	passwords map[string]ServerPassword
+}
+
+func (m *mockPasswords) Passwords() []ServerPassword {
+	out := []ServerPassword{}
+	for _, p := range m.passwords {
+		out = append(out, p)
+	}
+	return out
+}
+
+func (m *mockPasswords) RotatePassword(server string) (ServerPassword, error) {
+	p, ok := m.passwords[server]
+	if !ok {
+		return ServerPassword{}, fmt.Errorf("%w '%s'", ErrUnknownServer, server)
+	}
+	p.Password += "-new"
+	p.RotatedAt = "2026-10-16T20:00:00Z"
+	m.passwords[server] = p
+	return p, nil
+}
+
+func TestPasswordEndpoints(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetPasswordManager(&mockPasswords{passwords: map[string]ServerPassword{
+		"Track #1": {Server: "Track #1", Password: "hunter2", Roles: []string{"123456789012345678"}},
+	}})
+	handler := newVersionedTestHandler(t, s)
+
+	rec := auditDo(t, handler, "GET", PasswordsPath, "")
+	var list map[string][]ServerPassword
+	json.NewDecoder(rec.Body).Decode(&list)
+	if rec.Code != http.StatusOK || len(list["passwords"]) != 1 || list["passwords"][0].Password != "hunter2" {
+		t.Fatalf("Unexpected list %d %+v", rec.Code, list)
+	}
+
+	rec = auditDo(t, handler, "POST", PasswordsPath+"/Track%20%231/rotate", "")
+	var p ServerPassword
+	json.NewDecoder(rec.Body).Decode(&p)
+	if rec.Code != http.StatusOK || p.Password != "hunter2-new" || p.RotatedAt == "" {
+		t.Errorf("Unexpected rotation %d %+v", rec.Code, p)
+	}
+
+	if rec := auditDo(t, handler, "POST", PasswordsPath+"/Rally%20%231/rotate", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Unknown server: status = %d, want 404", rec.Code)
+	}
+}
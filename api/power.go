@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// PowerPath lists the process state of managed servers; POST PowerPath/{server} starts, stops or restarts one
+const PowerPath = "/api/v1/power"
+
+// ErrPowerBusy is returned (wrapped) by PowerController.PowerAction while another action on the server runs;
+// handlers answer 409 Conflict
+var ErrPowerBusy = errors.New("power action in progress")
+
+// PowerStatus is the last known process state of a server
+type PowerStatus struct {
+	Server    string    `json:"server"`
+	Backend   string    `json:"backend"` // docker, pterodactyl
+	Target    string    `json:"target"`  // container name or panel server identifier
+	State     string    `json:"state"`   // running, starting, stopping, stopped, restarting, paused, unhealthy, suspended, unknown
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+	Pending   string    `json:"pending,omitempty"` // action in progress
+}
+
+// PowerRequest is the body of POST PowerPath/{server}
+type PowerRequest struct {
+	Action string `json:"action"` // start, stop or restart
+}
+
+// PowerController reads and changes the process state of game servers
+type PowerController interface {
+	PowerStates() []PowerStatus
+	// PowerAction starts action in the background and returns the state before it; ErrUnknownServer for a
+	// server without a backend, ErrPowerBusy while another action runs, other errors for invalid actions
+	PowerAction(server, action, by string) (PowerStatus, error)
+}
+
+// SetPowerController enables the power endpoints
+// Must be called before Start
+func (s *Server) SetPowerController(c PowerController) {
+	s.power = c
+}
+
+// ListPower returns the process state of every managed server
+// Requires Bearer token authentication
+func (s *Server) ListPower(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string][]PowerStatus{"servers": s.power.PowerStates()})
+}
+
+// PowerAction starts, stops or restarts a server; the action runs in the background (202 Accepted)
+// Requires Bearer token authentication and CSRF token
+func (s *Server) PowerAction(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("PowerAction cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	if r.Body == nil {
+		WriteError(w, http.StatusBadRequest, "Empty request body", "POST requires a JSON body")
+		return
+	}
+	defer r.Body.Close()
+
+	var req PowerRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 4<<10)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	st, err := s.power.PowerAction(r.PathValue("server"), req.Action, "api:"+extractClientIP(r, s.trustedProxies))
+	switch {
+	case errors.Is(err, ErrUnknownServer):
+		WriteError(w, http.StatusNotFound, "Server not found", err.Error())
+	case errors.Is(err, ErrPowerBusy):
+		WriteError(w, http.StatusConflict, "Action in progress", err.Error())
+	case err != nil:
+		WriteError(w, http.StatusBadRequest, "Invalid action", err.Error())
+	default:
+		WriteJSON(w, http.StatusAccepted, st)
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+)
+
+// mockPower manages "Track #1" only; an action stays pending until the test clears it
+type mockPower struct {
+	states map[string]PowerStatus
+}
+
+func (m *mockPower) PowerStates() []PowerStatus {
+	var out []PowerStatus
+	for _, st := range m.states {
+		out = append(out, st)
+	}
+	return out
+}
+
+func (m *mockPower) PowerAction(server, action, by string) (PowerStatus, error) {
+	st, ok := m.states[server]
+	if !ok {
+		return PowerStatus{}, fmt.Errorf("%w '%s'", ErrUnknownServer, server)
+	}
+	if action != "start" && action != "stop" && action != "restart" {
+		return PowerStatus{}, fmt.Errorf("unknown action '%s'", action)
+	}
+	if st.Pending != "" {
+		return PowerStatus{}, fmt.Errorf("%w: %s", ErrPowerBusy, st.Pending)
+	}
+	st.Pending = action
+	m.states[server] = st
+	return st, nil
+}
+
+func TestPowerEndpoints(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	m := &mockPower{states: map[string]PowerStatus{"Track #1": {Server: "Track #1", Backend: "docker", Target: "ac-track", State: "stopped"}}}
+	s.SetPowerController(m)
+	handler := newVersionedTestHandler(t, s)
+
+	rec := auditDo(t, handler, "POST", PowerPath+"/Track%20%231", `{"action":"start"}`)
+	var st PowerStatus
+	json.NewDecoder(rec.Body).Decode(&st)
+	if rec.Code != http.StatusAccepted || st.Pending != "start" || st.State != "stopped" {
+		t.Fatalf("Action = %d %+v", rec.Code, st)
+	}
+
+	rec = auditDo(t, handler, "GET", PowerPath, "")
+	var list map[string][]PowerStatus
+	json.NewDecoder(rec.Body).Decode(&list)
+	if rec.Code != http.StatusOK || len(list["servers"]) != 1 || list["servers"][0].Target != "ac-track" {
+		t.Errorf("List = %d %+v", rec.Code, list)
+	}
+
+	tests := []struct {
+		name, path, body string
+		want             int
+	}{
+		{"busy", "/Track%20%231", `{"action":"stop"}`, http.StatusConflict},
+		{"unknown server", "/Drift%20%231", `{"action":"start"}`, http.StatusNotFound},
+		{"unknown action", "/Track%20%231", `{"action":"explode"}`, http.StatusBadRequest},
+		{"invalid JSON", "/Track%20%231", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := auditDo(t, handler, "POST", PowerPath+tt.path, tt.body); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
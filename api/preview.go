@@ -0,0 +1,50 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// EmbedPreviewPath returns the Discord embed the bot would currently send
+const EmbedPreviewPath = "/api/v1/preview/embed"
+
+// EmbedPreview is the rendered Discord status for review in the admin GUI
+type EmbedPreview struct {
+	// Embeds are the Discord embed objects, one per message when the status is split into pages
+	// Using any keeps the API package free of discordgo types
+	Embeds any `json:"embeds"`
+	// Markdown approximates how Discord displays the embeds
+	Markdown string `json:"markdown"`
+	// PolledAt is when the server data in the preview was polled
+	PolledAt time.Time `json:"polled_at"`
+}
+
+// EmbedPreviewer renders the status embed from the latest poll results and the current config
+// Returns nil when no poll has completed yet
+type EmbedPreviewer interface {
+	EmbedPreview() *EmbedPreview
+}
+
+// SetEmbedPreviewer enables GET /api/v1/preview/embed
+// Must be called before Start
+func (s *Server) SetEmbedPreviewer(p EmbedPreviewer) {
+	s.embedPreview = p
+}
+
+// PreviewEmbed returns the embed JSON and a markdown approximation
+// Requires Bearer token authentication; nothing is sent to Discord
+func (s *Server) PreviewEmbed(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("PreviewEmbed cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	preview := s.embedPreview.EmbedPreview()
+	if preview == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Preview not available yet", "No poll has completed since startup")
+		return
+	}
+	WriteJSON(w, http.StatusOK, preview)
+}
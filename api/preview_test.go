@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// mockEmbedPreviewer is a test double for EmbedPreviewer
+type mockEmbedPreviewer struct {
+	preview *EmbedPreview
+}
+
+func (m *mockEmbedPreviewer) EmbedPreview() *EmbedPreview {
+	return m.preview
+}
+
+func newPreviewTestServer(p EmbedPreviewer) *Server {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	if p != nil {
+		s.SetEmbedPreviewer(p)
+	}
+	return s
+}
+
+// TestPreviewEmbed_ReturnsPreview tests that embeds, markdown and poll time are returned
+func TestPreviewEmbed_ReturnsPreview(t *testing.T) {
+	polled := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := newPreviewTestServer(&mockEmbedPreviewer{preview: &EmbedPreview{
+		Embeds:   []map[string]any{{"title": "ABSA Official Servers"}},
+		Markdown: "## ABSA Official Servers\n",
+		PolledAt: polled,
+	}})
+	handler := newPublicTestHandler(t, s)
+
+	req := httptest.NewRequest("GET", EmbedPreviewPath, nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var body struct {
+		Embeds   []map[string]any `json:"embeds"`
+		Markdown string           `json:"markdown"`
+		PolledAt time.Time        `json:"polled_at"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Embeds) != 1 || body.Embeds[0]["title"] != "ABSA Official Servers" || body.Markdown == "" || !body.PolledAt.Equal(polled) {
+		t.Errorf("Unexpected preview: %+v", body)
+	}
+}
+
+// TestPreviewEmbed_RequiresAuth tests that the preview is not public
+func TestPreviewEmbed_RequiresAuth(t *testing.T) {
+	handler := newPublicTestHandler(t, newPreviewTestServer(&mockEmbedPreviewer{preview: &EmbedPreview{}}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", EmbedPreviewPath, nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+// TestPreviewEmbed_NotReady tests 503 before the first poll
+func TestPreviewEmbed_NotReady(t *testing.T) {
+	s := newPreviewTestServer(&mockEmbedPreviewer{})
+
+	rec := httptest.NewRecorder()
+	s.PreviewEmbed(rec, httptest.NewRequest("GET", EmbedPreviewPath, nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+// TestPreviewEmbed_NotRegisteredWithoutPreviewer tests that the endpoint is absent when no previewer is set
+func TestPreviewEmbed_NotRegisteredWithoutPreviewer(t *testing.T) {
+	handler := newPublicTestHandler(t, newPreviewTestServer(nil))
+
+	req := httptest.NewRequest("GET", EmbedPreviewPath, nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PublicStatusPath serves the sanitized live status without authentication
+// Only registered when a StatusProvider is set (API_PUBLIC_STATUS_ENABLED=true)
+const PublicStatusPath = "/api/public/status"
+
+// StatusImagePath serves the latest PNG status banner without authentication
+// Only registered when a StatusImageProvider is set (STATUS_BANNER_ENABLED=true)
+const StatusImagePath = "/api/v1/status.png"
+
+// StatusProvider supplies the sanitized public status document
+// Returns nil when no poll has completed yet
+// Using any keeps the API package free of main.StatusSnapshot (avoids circular imports)
+type StatusProvider interface {
+	PublicStatus() any
+}
+
+// SetStatusProvider enables the unauthenticated public status endpoint
+// Must be called before Start
+func (s *Server) SetStatusProvider(p StatusProvider) {
+	s.status = p
+}
+
+// StatusImageProvider supplies the latest rendered PNG banner
+// Returns nil when no poll has completed yet
+type StatusImageProvider interface {
+	StatusImage() []byte
+}
+
+// SetStatusImageProvider enables the unauthenticated PNG banner endpoint
+// Must be called before Start
+func (s *Server) SetStatusImageProvider(p StatusImageProvider) {
+	s.statusImage = p
+}
+
+// isPublicPath reports whether path is served without Bearer auth and with open CORS
+// v2 routes of public endpoints are public as well
+func isPublicPath(path string) bool {
+	if path == APIVersionsPath {
+		return true
+	}
+	if strings.HasPrefix(path, apiV2Prefix+"/") {
+		for _, v1 := range v1Paths(path) {
+			if isPublicPath(v1) {
+				return true
+			}
+		}
+		return false
+	}
+	return path == PublicStatusPath || path == PublicStatusServersPath || path == StatusImagePath ||
+		path == EventsCalendarPath || strings.HasPrefix(path, ImagesPath+"/")
+}
+
+// PublicStatus returns the sanitized status snapshot for community websites
+// No authentication required; rate limited like every other endpoint
+// Cross-origin reads are allowed from any origin (no credentials)
+func (s *Server) PublicStatus(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("PublicStatus cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	status := s.status.PublicStatus()
+	if status == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Status not available yet", "No poll has completed since startup")
+		return
+	}
+
+	// Short shared cache: status changes at most once per update interval
+	w.Header().Set("Cache-Control", "public, max-age=5")
+	WriteJSONWithETag(w, r, status)
+}
+
+// StatusImage returns the PNG status banner for forums and sites that can only embed images
+// No authentication required; rate limited like every other endpoint
+func (s *Server) StatusImage(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("StatusImage cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	img := s.statusImage.StatusImage()
+	if img == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Status not available yet", "No poll has completed since startup")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(img)))
+	w.Header().Set("Cache-Control", "public, max-age=5")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(img); err != nil {
+		log.Printf("StatusImage write failed: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// mockStatusProvider is a test double for StatusProvider
+type mockStatusProvider struct {
+	status any
+}
+
+func (m *mockStatusProvider) PublicStatus() any {
+	return m.status
+}
+
+// newPublicTestHandler builds the auth/CORS chain around a mux with routes registered for s
+func newPublicTestHandler(t *testing.T, s *Server) http.Handler {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	mux := http.NewServeMux()
+	RegisterRoutes(mux, s)
+
+	var handler http.Handler = mux
+	handler = BearerAuth("valid-token", []string{})(handler)
+	handler = RateLimit(10, 20, []string{}, ctx)(handler)
+	handler = CORS([]string{"https://admin.example.com"})(handler)
+	return handler
+}
+
+func TestPublicStatus_NoAuthRequired(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetStatusProvider(&mockStatusProvider{status: map[string]any{"total_players": 7}})
+	handler := newPublicTestHandler(t, s)
+
+	req := httptest.NewRequest("GET", PublicStatusPath, nil)
+	req.Header.Set("Origin", "https://community.example.org")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Access-Control-Allow-Credentials must not be set on public endpoint, got %q", got)
+	}
+
+	var body map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body["total_players"] != float64(7) {
+		t.Errorf("total_players = %v, want 7", body["total_players"])
+	}
+}
+
+// TestPublicStatus_ETag tests that pollers get 304 through the full middleware chain while the status is unchanged
+func TestPublicStatus_ETag(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	provider := &mockStatusProvider{status: map[string]any{"total_players": 7}}
+	s.SetStatusProvider(provider)
+	handler := newPublicTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", PublicStatusPath, nil))
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+
+	req := httptest.NewRequest("GET", PublicStatusPath, nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("Expected empty 304, got %d (%s)", rec.Code, rec.Body.String())
+	}
+
+	provider.status = map[string]any{"total_players": 8}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 after the status changed, got %d", rec.Code)
+	}
+}
+
+func TestPublicStatus_NotAvailableYet(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetStatusProvider(&mockStatusProvider{})
+	handler := newPublicTestHandler(t, s)
+
+	req := httptest.NewRequest("GET", PublicStatusPath, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestPublicStatus_DisabledByDefault(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newPublicTestHandler(t, s)
+
+	req := httptest.NewRequest("GET", PublicStatusPath, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestPublicStatus_OtherEndpointsStillRequireAuth(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetStatusProvider(&mockStatusProvider{status: map[string]any{}})
+	handler := newPublicTestHandler(t, s)
+
+	req := httptest.NewRequest("GET", "/api/config", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+// mockStatusImageProvider is a test double for StatusImageProvider
+type mockStatusImageProvider struct {
+	image []byte
+}
+
+func (m *mockStatusImageProvider) StatusImage() []byte {
+	return m.image
+}
+
+func TestStatusImage_NoAuthRequired(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetStatusImageProvider(&mockStatusImageProvider{image: []byte("\x89PNG")})
+	handler := newPublicTestHandler(t, s)
+
+	req := httptest.NewRequest("GET", StatusImagePath, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "image/png" {
+		t.Errorf("Content-Type = %q, want image/png", got)
+	}
+	if got := rec.Body.String(); got != "\x89PNG" {
+		t.Errorf("Body = %q, want PNG bytes", got)
+	}
+}
+
+func TestStatusImage_NotAvailableYet(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetStatusImageProvider(&mockStatusImageProvider{})
+	handler := newPublicTestHandler(t, s)
+
+	req := httptest.NewRequest("GET", StatusImagePath, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestStatusImage_DisabledByDefault(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newPublicTestHandler(t, s)
+
+	req := httptest.NewRequest("GET", StatusImagePath, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/drain"
+	"github.com/bombom/absa-ac/pkg/listen"
+	"github.com/bombom/absa-ac/pkg/requestid"
+)
+
+// PublicListener is an optional second listener serving only read-only endpoints without auth:
+// the public status, banner, calendar and image proxy, plus the history reports (SLA, usage, heatmap)
+// Config writes, /health and the admin UI stay on the API listener, which can then bind an internal interface
+type PublicListener struct {
+	Listeners []net.Listener
+	// CORSOrigins may read the endpoints from a browser (empty or "*" = any origin, no credentials)
+	CORSOrigins []string
+	// RequestsPerSecond and Burst rate limit each client IP, separately from the API listener
+	RequestsPerSecond int
+	Burst             int
+}
+
+// SetPublicListener serves the read-only endpoints on a second listener as well
+// The server closes its listeners on shutdown. Must be called before Start
+func (s *Server) SetPublicListener(p PublicListener) {
+	s.public = &p
+}
+
+// registerPublicListenerRoutes registers the endpoints of the public listener
+// The history reports need auth on the API listener but are read-only aggregates, so they are public here
+func registerPublicListenerRoutes(mux *http.ServeMux, s *Server) {
+	registerPublicRoutes(mux, s)
+	if s.sla != nil {
+		mux.HandleFunc("GET "+SLAPath, s.GetSLA)
+	}
+	if s.usage != nil {
+		mux.HandleFunc("GET "+UsagePath, s.GetUsage)
+	}
+	if s.heatmap != nil {
+		mux.HandleFunc("GET "+HeatmapPath, s.GetHeatmap)
+	}
+}
+
+// PublicCORS allows cross-origin reads from allowedOrigins (empty or "*" = any origin)
+// Only GET is allowed and credentials never are; a disallowed origin gets no CORS headers, so browsers block the read
+func PublicCORS(allowedOrigins []string) func(http.Handler) http.Handler {
+	anyOrigin := len(allowedOrigins) == 0 || slices.Contains(allowedOrigins, "*")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			switch {
+			case anyOrigin:
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			case slices.Contains(allowedOrigins, origin):
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Add("Vary", "Origin")
+			default:
+				if r.Method == http.MethodOptions {
+					WriteError(w, http.StatusForbidden, "Origin not allowed", "Origin '"+origin+"' is not in the allowed origins list")
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+			w.Header().Set("Access-Control-Expose-Headers", requestid.Header)
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// startPublicListener serves the public endpoints on the public listeners until shutdown
+// Execution order (outer to inner): RequestID → SecurityHeaders → PublicCORS → Logger → RateLimit
+func (s *Server) startPublicListener(ctx context.Context) {
+	mux := http.NewServeMux()
+	registerPublicListenerRoutes(mux, s)
+
+	var handler http.Handler = mux
+	handler = RateLimit(s.public.RequestsPerSecond, s.public.Burst, s.trustedProxies, ctx)(handler)
+	handler = Logger(s.logger)(handler)
+	handler = PublicCORS(s.public.CORSOrigins)(handler)
+	handler = SecurityHeaders()(handler)
+	handler = requestid.Middleware(handler)
+	handler = s.publicInFlight.Wrap(handler)
+
+	s.publicServer = &http.Server{
+		Handler:      handler,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+	for _, l := range s.public.Listeners {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.logger.Printf("Public API listening on %s", listen.Name(l))
+			if err := s.publicServer.Serve(l); err != nil && err != http.ErrServerClosed {
+				s.logger.Printf("Public API error on %s: %v", listen.Name(l), err)
+			}
+		}()
+	}
+}
+
+// stopPublicListener drains the public listener (no-op without one)
+func (s *Server) stopPublicListener() error {
+	if s.publicServer == nil {
+		return nil
+	}
+	return drain.Shutdown(s.publicServer, &s.publicInFlight, s.drainTimeout, s.logger, "public API")
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPublicListener tests that the public listener serves only the read-only endpoints without auth
+// and that the API listener keeps every route
+func TestPublicListener(t *testing.T) {
+	s := NewServer(&mockConfigManager{config: map[string]any{"test": "data"}}, "0", "valid-token", []string{}, []string{}, log.New(io.Discard, "", 0))
+	s.SetStatusProvider(&mockStatusProvider{status: map[string]any{"total_players": 3}})
+	s.SetSLAProvider(&mockSLAProvider{})
+
+	apiListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetListeners([]net.Listener{apiListener})
+	s.SetPublicListener(PublicListener{
+		Listeners:         []net.Listener{publicListener},
+		CORSOrigins:       []string{"https://league.example.com"},
+		RequestsPerSecond: 100,
+		Burst:             100,
+	})
+	startErr := make(chan error, 1)
+	go func() { startErr <- s.Start(context.Background()) }()
+	defer func() {
+		s.Stop()
+		if err := <-startErr; err != nil {
+			t.Errorf("Start returned %v", err)
+		}
+	}()
+
+	publicURL := "http://" + publicListener.Addr().String()
+	apiURL := "http://" + apiListener.Addr().String()
+	do := func(method, url, origin string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(method, url, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		var resp *http.Response
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+			if resp, err = http.DefaultClient.Do(req); err == nil {
+				resp.Body.Close()
+				return resp
+			}
+		}
+		t.Fatalf("%s %s failed: %v", method, url, err)
+		return nil
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		want   int
+	}{
+		{"public status", "GET", publicURL + PublicStatusPath, http.StatusOK},
+		{"history without auth", "GET", publicURL + SLAPath, http.StatusOK},
+		{"no config", "GET", publicURL + "/api/config", http.StatusNotFound},
+		{"no health", "GET", publicURL + "/health", http.StatusNotFound},
+		{"no admin UI", "GET", publicURL + "/admin/", http.StatusNotFound},
+		{"no writes", "POST", publicURL + PublicStatusPath, http.StatusMethodNotAllowed},
+		{"API keeps public status", "GET", apiURL + PublicStatusPath, http.StatusOK},
+		{"API history needs auth", "GET", apiURL + SLAPath, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := do(tt.method, tt.url, ""); resp.StatusCode != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.url, resp.StatusCode, tt.want)
+			}
+		})
+	}
+
+	// Its own CORS allowlist
+	resp := do("GET", publicURL+PublicStatusPath, "https://league.example.com")
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://league.example.com" {
+		t.Errorf("Allow-Origin for an allowed origin = %q", got)
+	}
+	resp = do("GET", publicURL+PublicStatusPath, "https://other.example.com")
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Expected no Allow-Origin for another origin, got %q", got)
+	}
+	if resp := do("OPTIONS", publicURL+PublicStatusPath, "https://other.example.com"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Preflight from another origin = %d, want 403", resp.StatusCode)
+	}
+}
+
+// TestPublicListener_RateLimit tests that the public listener has its own rate limit
+func TestPublicListener_RateLimit(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "0", "valid-token", []string{}, []string{}, log.New(io.Discard, "", 0))
+	s.SetStatusProvider(&mockStatusProvider{status: map[string]any{}})
+	s.public = &PublicListener{RequestsPerSecond: 1, Burst: 2}
+	s.startPublicListener(t.Context())
+
+	codes := make([]int, 3)
+	for i := range codes {
+		req, _ := http.NewRequest("GET", PublicStatusPath, nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		rec := httptest.NewRecorder()
+		s.publicServer.Handler.ServeHTTP(rec, req)
+		codes[i] = rec.Code
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("Expected two requests then 429, got %v", codes)
+	}
+}
+
+// TestPublicCORS tests the wildcard default
+func TestPublicCORS(t *testing.T) {
+	handler := PublicCORS(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req, _ := http.NewRequest("OPTIONS", PublicStatusPath, nil)
+	req.Header.Set("Origin", "https://any.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "*" ||
+		strings.Contains(rec.Header().Get("Access-Control-Allow-Methods"), "POST") {
+		t.Errorf("Unexpected preflight %d %v", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// PurgePath removes the data tied to a Discord user or a Steam ID (POST, on request of the person)
+const PurgePath = "/api/v1/purge"
+
+var (
+	// discordIDPattern matches a Discord user ID (snowflake)
+	discordIDPattern = regexp.MustCompile(`^[0-9]{17,20}$`)
+	// steamIDPattern matches a SteamID64, the GUID in AC results
+	steamIDPattern = regexp.MustCompile(`^7656119[0-9]{10}$`)
+)
+
+// PurgeRequest names the person whose data is purged; at least one field is required
+type PurgeRequest struct {
+	DiscordUserID string `json:"discord_user_id,omitempty"`
+	SteamID       string `json:"steam_id,omitempty"`
+}
+
+// PurgeResult counts what a purge removed
+// A driver link ties both identifiers together, so the linked Steam ID or user is purged as well
+type PurgeResult struct {
+	DiscordUserIDs []string `json:"discord_user_ids"`
+	SteamIDs       []string `json:"steam_ids"`
+	DriverLinks    int      `json:"driver_links"`
+	// ResultsFiles is the race results files the driver was anonymized in
+	ResultsFiles     int `json:"results_files"`
+	WhitelistEntries int `json:"whitelist_entries"`
+	SlotSignups      int `json:"slot_signups"`
+	// LogLines is the buffered log lines the identifiers were blanked in
+	LogLines int `json:"log_lines"`
+	// AuditEntries is the config changes dropped from the audit log because their snapshots held an identifier
+	AuditEntries int `json:"audit_entries"`
+}
+
+// DataPurger removes the data the bot keeps about a person
+type DataPurger interface {
+	// Purge returns what was removed so far along with any error, since a failure leaves earlier steps done
+	Purge(req PurgeRequest) (PurgeResult, error)
+}
+
+// SetDataPurger enables the purge endpoint
+// Must be called before Start
+func (s *Server) SetDataPurger(p DataPurger) {
+	s.purger = p
+}
+
+// Purge removes the data tied to a Discord user ID and/or a Steam ID, then drops the audit entries that
+// mention them. The identifiers are not logged
+// Requires Bearer token authentication and CSRF token
+func (s *Server) Purge(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("Purge cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	var req PurgeRequest
+	defer r.Body.Close()
+	r.Body = http.MaxBytesReader(w, r.Body, 4<<10)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+	req.DiscordUserID, req.SteamID = strings.TrimSpace(req.DiscordUserID), strings.TrimSpace(req.SteamID)
+	if req.DiscordUserID == "" && req.SteamID == "" {
+		WriteError(w, http.StatusBadRequest, "Nothing to purge", "Set discord_user_id, steam_id or both")
+		return
+	}
+	if req.DiscordUserID != "" && !discordIDPattern.MatchString(req.DiscordUserID) {
+		WriteError(w, http.StatusBadRequest, "Invalid discord_user_id", "discord_user_id must be a Discord user ID")
+		return
+	}
+	if req.SteamID != "" && !steamIDPattern.MatchString(req.SteamID) {
+		WriteError(w, http.StatusBadRequest, "Invalid steam_id", "steam_id must be a SteamID64 (17 digits starting with 7656119)")
+		return
+	}
+
+	result, err := s.purger.Purge(req)
+	if s.audit != nil {
+		result.AuditEntries = s.audit.purge(append(result.DiscordUserIDs, result.SteamIDs...))
+	}
+	if err != nil {
+		log.Printf("Purge incomplete: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Purge incomplete", err.Error())
+		return
+	}
+	log.Printf("Audit: purged personal data on request (driver links: %d, results files: %d, whitelist entries: %d, audit entries: %d)",
+		result.DriverLinks, result.ResultsFiles, result.WhitelistEntries, result.AuditEntries)
+	WriteJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+type mockDataPurger struct {
+	req PurgeRequest
+}
+
+func (m *mockDataPurger) Purge(req PurgeRequest) (PurgeResult, error) {
+	m.req = req
+	return PurgeResult{DiscordUserIDs: []string{req.DiscordUserID}, SteamIDs: []string{"76561198000000001"}, DriverLinks: 1}, nil
+}
+
+func TestPurge(t *testing.T) {
+	cm := &mockConfigManagerWithWrites{config: map[string]interface{}{"update_interval": float64(30)}}
+	s := NewServer(cm, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.EnableConfigAudit(DefaultAuditSize)
+	purger := &mockDataPurger{}
+	s.SetDataPurger(purger)
+	handler := newVersionedTestHandler(t, s)
+
+	auditDo(t, handler, "PATCH", "/api/config", `{"whitelists": {"Track #1": {"drivers": [{"guid": "76561198000000001"}]}}}`)
+	auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 60}`)
+
+	rec := auditDo(t, handler, "POST", PurgePath, `{"discord_user_id": "111111111111111111"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	var res PurgeResult
+	if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if purger.req.DiscordUserID != "111111111111111111" || res.DriverLinks != 1 {
+		t.Errorf("Unexpected purge %+v -> %+v", purger.req, res)
+	}
+	// Both entries hold the GUID: the first one after the change, the second one before it
+	if res.AuditEntries != 2 || len(auditEntries(t, handler)) != 0 {
+		t.Errorf("Expected the audit entries mentioning the GUID to be dropped, got %d", res.AuditEntries)
+	}
+
+	for _, body := range []string{`{}`, `{"discord_user_id": "alice"}`, `{"steam_id": "12345"}`, `{`} {
+		if rec := auditDo(t, handler, "POST", PurgePath, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, rec.Code)
+		}
+	}
+}
+
+func TestExpireAuditEntries(t *testing.T) {
+	s := NewServer(&mockConfigManagerWithWrites{config: map[string]interface{}{}}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	if n := s.ExpireAuditEntries(time.Now()); n != 0 {
+		t.Errorf("Expected nothing to expire without an audit log, got %d", n)
+	}
+	s.EnableConfigAudit(DefaultAuditSize)
+	handler := newVersionedTestHandler(t, s)
+	auditDo(t, handler, "PATCH", "/api/config", `{"update_interval": 60}`)
+
+	if n := s.ExpireAuditEntries(time.Now().Add(-time.Hour)); n != 0 {
+		t.Errorf("Expected a recent entry to be kept, got %d expired", n)
+	}
+	if n := s.ExpireAuditEntries(time.Now().Add(time.Minute)); n != 1 || len(auditEntries(t, handler)) != 0 {
+		t.Errorf("Expected the entry to expire, got %d", n)
+	}
+}
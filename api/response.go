@@ -1,16 +1,24 @@
 package api
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"strings"
+
+	"github.com/bombom/absa-ac/pkg/requestid"
 )
 
 // ErrorResponse represents an error response
 // Error: short error message
 // Details: optional detailed explanation
+// RequestID: the X-Request-ID of the request, to find it in the logs
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Details string `json:"details,omitempty"`
+	Error     string `json:"error"`
+	Details   string `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // SuccessResponse represents a success response with data
@@ -29,10 +37,48 @@ func WriteJSON(w http.ResponseWriter, status int, data interface{}) error {
 
 // WriteError writes an error response with status code and error details
 // Details is optional - pass empty string to omit
+// The request ID is taken from the X-Request-ID response header set by requestid.Middleware
 func WriteError(w http.ResponseWriter, status int, err string, details string) error {
 	resp := ErrorResponse{
-		Error:   err,
-		Details: details,
+		Error:     err,
+		Details:   details,
+		RequestID: w.Header().Get(requestid.Header),
 	}
 	return WriteJSON(w, status, resp)
 }
+
+// WriteJSONWithETag writes data as a 200 JSON response with an ETag of its content
+// A request whose If-None-Match matches gets 304 Not Modified without a body, so clients
+// polling unchanged data (the admin UI, status dashboards) only pay for the headers
+func WriteJSONWithETag(w http.ResponseWriter, r *http.Request, data interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(data); err != nil {
+		return WriteError(w, http.StatusInternalServerError, "Failed to encode response", err.Error())
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// etagMatches reports whether an If-None-Match header matches etag (weak comparison, RFC 9110 13.1.2)
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+)
+
+// ResultsPath accepts an AC race results file (POST, the JSON body as written to the server's results folder)
+const ResultsPath = "/api/v1/results"
+
+// maxResultsSize bounds an uploaded results file (lap and event lists of long races are large)
+const maxResultsSize = 8 << 20
+
+// ErrInvalidResults is returned (wrapped) by ResultsIngester for a body that is not an AC results file;
+// handlers answer 400 Bad Request
+var ErrInvalidResults = errors.New("invalid results file")
+
+// sessionIDPattern is what a session ID may look like: the results file name without .json
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,100}$`)
+
+// ResultsUpload describes an ingested results file
+type ResultsUpload struct {
+	SessionID string `json:"session_id"`
+	Track     string `json:"track"`
+	Type      string `json:"type"` // RACE, QUALIFY, PRACTICE
+	Drivers   int    `json:"drivers"`
+	// Duplicate is set when the session was ingested before; nothing was stored or posted again
+	Duplicate bool `json:"duplicate"`
+}
+
+// ResultsIngester stores uploaded results files and updates the standings
+type ResultsIngester interface {
+	// IngestResults stores data under sessionID (empty = derived from the content)
+	IngestResults(sessionID string, data []byte) (ResultsUpload, error)
+}
+
+// SetResultsIngester enables the results upload endpoint
+// Must be called before Start
+func (s *Server) SetResultsIngester(i ResultsIngester) {
+	s.results = i
+}
+
+// UploadResults ingests an AC results file; the optional ?session= names it (e.g. 2026_10_18_18_00_RACE)
+// Requires Bearer token authentication and CSRF token
+// Answers 201 for a new session and 200 for one that was already ingested
+func (s *Server) UploadResults(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("UploadResults cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	sessionID := r.URL.Query().Get("session")
+	if sessionID != "" && !sessionIDPattern.MatchString(sessionID) {
+		WriteError(w, http.StatusBadRequest, "Invalid session",
+			"session must be 1-100 letters, digits, underscores or dashes, like the results file name without .json")
+		return
+	}
+
+	defer r.Body.Close()
+	r.Body = http.MaxBytesReader(w, r.Body, maxResultsSize)
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			WriteError(w, http.StatusRequestEntityTooLarge, "Results file too large", "Results files are limited to 8 MB")
+			return
+		}
+		WriteError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	upload, err := s.results.IngestResults(sessionID, data)
+	if errors.Is(err, ErrInvalidResults) {
+		WriteError(w, http.StatusBadRequest, "Invalid results file", err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to store results: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to store results", "See the bot log for details")
+		return
+	}
+	if upload.Duplicate {
+		WriteJSON(w, http.StatusOK, upload)
+		return
+	}
+	WriteJSON(w, http.StatusCreated, upload)
+}
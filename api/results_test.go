@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeResultsIngester struct {
+	stored map[string]bool
+}
+
+func (f *fakeResultsIngester) IngestResults(sessionID string, data []byte) (ResultsUpload, error) {
+	if !json.Valid(data) {
+		return ResultsUpload{}, fmt.Errorf("%w: invalid JSON", ErrInvalidResults)
+	}
+	if sessionID == "" {
+		sessionID = "upload_1"
+	}
+	upload := ResultsUpload{SessionID: sessionID, Track: "ks_spa", Type: "RACE", Drivers: 2, Duplicate: f.stored[sessionID]}
+	f.stored[sessionID] = true
+	return upload, nil
+}
+
+// TestUploadResults tests storing, duplicates, invalid files and session IDs, the size limit and registration
+func TestUploadResults(t *testing.T) {
+	s := &Server{}
+	mux := http.NewServeMux()
+	RegisterRoutes(mux, s)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, ResultsPath, bytes.NewBufferString("{}")))
+	if rec.Code != http.StatusNotFound && rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected no route without an ingester, got %d", rec.Code)
+	}
+
+	s.SetResultsIngester(&fakeResultsIngester{stored: map[string]bool{}})
+	mux = http.NewServeMux()
+	RegisterRoutes(mux, s)
+	post := func(query, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, ResultsPath+query, bytes.NewBufferString(body)))
+		return rec
+	}
+
+	rec = post("?session=2026_10_18_18_00_RACE", `{}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var upload ResultsUpload
+	json.Unmarshal(rec.Body.Bytes(), &upload)
+	if upload.SessionID != "2026_10_18_18_00_RACE" || upload.Duplicate {
+		t.Errorf("Unexpected upload %+v", upload)
+	}
+
+	rec = post("?session=2026_10_18_18_00_RACE", `{}`)
+	json.Unmarshal(rec.Body.Bytes(), &upload)
+	if rec.Code != http.StatusOK || !upload.Duplicate {
+		t.Errorf("Expected 200 with duplicate for a known session, got %d: %s", rec.Code, rec.Body)
+	}
+
+	if rec := post("", `{`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid file, got %d", rec.Code)
+	}
+	if rec := post("?session=../config", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid session, got %d", rec.Code)
+	}
+	if rec := post("", string(bytes.Repeat([]byte(" "), maxResultsSize+1))); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for an oversized file, got %d", rec.Code)
+	}
+}
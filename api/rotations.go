@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// RotationsPath lists the track rotations of all servers; RotationsPath/{server} reads (GET) or replaces (PUT) one
+const RotationsPath = "/api/v1/rotations"
+
+// RotationEntry is one upcoming track of a server
+type RotationEntry struct {
+	Track string `json:"track"`
+	Start string `json:"start"` // RFC 3339
+}
+
+// RotationRequest is the body of PUT RotationsPath/{server}; an empty list removes the rotation
+type RotationRequest struct {
+	Rotation []RotationEntry `json:"rotation"`
+}
+
+// RotationEditor reads and replaces the per-server track rotations saved in the config
+type RotationEditor interface {
+	Rotations() map[string][]RotationEntry
+	// SetRotation saves the rotation of server; ErrUnknownServer for an unknown server, other errors are
+	// validation or write failures (ErrConfigReadOnly for a read-only config)
+	SetRotation(server string, rotation []RotationEntry) error
+}
+
+// SetRotationEditor enables the rotation endpoints
+// Must be called before Start
+func (s *Server) SetRotationEditor(e RotationEditor) {
+	s.rotations = e
+}
+
+// ListRotations returns the track rotations of all servers
+// Requires Bearer token authentication
+func (s *Server) ListRotations(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string]map[string][]RotationEntry{"rotations": s.rotations.Rotations()})
+}
+
+// GetRotation returns the track rotation of one server (empty when none is configured)
+// Requires Bearer token authentication
+func (s *Server) GetRotation(w http.ResponseWriter, r *http.Request) {
+	server := r.PathValue("server")
+	rotation := s.rotations.Rotations()[server]
+	if rotation == nil {
+		rotation = []RotationEntry{}
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"server": server, "rotation": rotation})
+}
+
+// SetRotation replaces the track rotation of one server and saves the config
+// Requires Bearer token authentication and CSRF token
+func (s *Server) SetRotation(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("SetRotation cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	if r.Body == nil {
+		WriteError(w, http.StatusBadRequest, "Empty request body", "PUT requires a JSON body")
+		return
+	}
+	defer r.Body.Close()
+
+	var req RotationRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	server := r.PathValue("server")
+	err := s.auditedWrite(w, r, func() error { return s.rotations.SetRotation(server, req.Rotation) })
+	if errors.Is(err, ErrUnknownServer) {
+		WriteError(w, http.StatusNotFound, "Server not found", err.Error())
+		return
+	}
+	if err != nil {
+		writeConfigWriteError(w, "Invalid rotation", err)
+		return
+	}
+	rotation := req.Rotation
+	if rotation == nil {
+		rotation = []RotationEntry{}
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"server": server, "rotation": rotation})
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+)
+
+// mockRotations keeps rotations in a map; only "Drift #1" and "Track #1" exist
+type mockRotations struct {
+	rotations map[string][]RotationEntry
+}
+
+func (m *mockRotations) Rotations() map[string][]RotationEntry {
+	return m.rotations
+}
+
+func (m *mockRotations) SetRotation(server string, rotation []RotationEntry) error {
+	if server != "Drift #1" && server != "Track #1" {
+		return fmt.Errorf("%w '%s'", ErrUnknownServer, server)
+	}
+	for _, r := range rotation {
+		if r.Start == "" {
+			return errors.New("start cannot be empty")
+		}
+	}
+	if len(rotation) == 0 {
+		delete(m.rotations, server)
+		return nil
+	}
+	m.rotations[server] = rotation
+	return nil
+}
+
+func TestRotationEndpoints(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	m := &mockRotations{rotations: make(map[string][]RotationEntry)}
+	s.SetRotationEditor(m)
+	handler := newVersionedTestHandler(t, s)
+
+	rec := auditDo(t, handler, "PUT", RotationsPath+"/Track%20%231", `{"rotation":[{"track":"Spa","start":"2026-10-18T18:00:00Z"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Set status = %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if got := m.rotations["Track #1"]; len(got) != 1 || got[0].Track != "Spa" {
+		t.Errorf("Expected the rotation saved, got %+v", got)
+	}
+
+	rec = auditDo(t, handler, "GET", RotationsPath+"/Track%20%231", "")
+	var one struct {
+		Server   string          `json:"server"`
+		Rotation []RotationEntry `json:"rotation"`
+	}
+	json.NewDecoder(rec.Body).Decode(&one)
+	if rec.Code != http.StatusOK || one.Server != "Track #1" || len(one.Rotation) != 1 {
+		t.Errorf("Get: status %d, %+v", rec.Code, one)
+	}
+
+	rec = auditDo(t, handler, "GET", RotationsPath, "")
+	var list map[string]map[string][]RotationEntry
+	json.NewDecoder(rec.Body).Decode(&list)
+	if rec.Code != http.StatusOK || len(list["rotations"]["Track #1"]) != 1 {
+		t.Errorf("List: status %d, %+v", rec.Code, list)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"unknown server", "PUT", RotationsPath + "/Nope", `{"rotation":[]}`, http.StatusNotFound},
+		{"invalid entry", "PUT", RotationsPath + "/Track%20%231", `{"rotation":[{"track":"Spa"}]}`, http.StatusBadRequest},
+		{"bad json", "PUT", RotationsPath + "/Track%20%231", `{`, http.StatusBadRequest},
+		{"no body", "PUT", RotationsPath + "/Track%20%231", "", http.StatusBadRequest},
+		{"remove", "PUT", RotationsPath + "/Track%20%231", `{"rotation":[]}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := auditDo(t, handler, tt.method, tt.path, tt.body); rec.Code != tt.status {
+				t.Errorf("Status = %d, want %d (body: %s)", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+	if _, ok := m.rotations["Track #1"]; ok {
+		t.Error("Expected the rotation removed")
+	}
+}
+
+func TestRotationEndpoints_Disabled(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newVersionedTestHandler(t, s)
+	if rec := auditDo(t, handler, "GET", RotationsPath, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without an editor, got %d", rec.Code)
+	}
+}
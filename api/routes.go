@@ -8,7 +8,14 @@ import (
 // Middleware is applied externally (auth, rate limit, logger, CSRF)
 func RegisterRoutes(mux *http.ServeMux, s *Server) {
 	// Health check (no auth required, but rate limited)
-	mux.HandleFunc("GET /health", HealthCheck)
+	mux.HandleFunc("GET /health", s.Health)
+
+	// Version negotiation document (no auth) and v2 routes converted from the v1 handlers
+	mux.HandleFunc("GET "+APIVersionsPath, s.Versions)
+	mux.Handle(apiV2Prefix+"/", s.v2Handler(mux))
+
+	// Prometheus metrics of the API, proxy and Discord gateway (auth required)
+	mux.HandleFunc("GET "+MetricsPath, s.Metrics)
 
 	// CSRF token endpoint (auth required, returns token for frontend)
 	mux.HandleFunc("GET /api/csrf-token", s.GetCSRFTokenHandler)
@@ -21,4 +28,181 @@ func RegisterRoutes(mux *http.ServeMux, s *Server) {
 	mux.HandleFunc("POST /api/config/validate", s.ValidateConfig)
 	mux.HandleFunc("GET /api/config/download", s.DownloadConfig)
 	mux.HandleFunc("POST /api/config/upload", s.UploadConfig)
+
+	// Non-fatal config warnings - only when a linter is configured
+	if s.linter != nil {
+		mux.HandleFunc("GET /api/config/lint", s.LintConfig)
+	}
+
+	// Live poll of an unsaved server definition - only when a tester is configured
+	if s.serverTester != nil {
+		mux.HandleFunc("POST "+ServerTestPath, s.TestServer)
+	}
+
+	// Entry list and car classes from the last poll - only when entry lists are fetched
+	if s.entryLists != nil {
+		mux.HandleFunc("GET "+EntryListPath, s.GetEntryList)
+	}
+
+	// Championship standings from the results folder - only when standings are enabled
+	if s.standings != nil {
+		mux.HandleFunc("GET "+StandingsPath, s.GetStandings)
+	}
+
+	// Results uploads from a game server hook - only when standings are enabled
+	if s.results != nil {
+		mux.HandleFunc("POST "+ResultsPath, s.UploadResults)
+	}
+
+	// Steam links of members - only when driver registration is enabled
+	if s.drivers != nil {
+		mux.HandleFunc("GET "+DriversPath, s.ListDrivers)
+		mux.HandleFunc("PUT "+DriversPath+"/{steam_id}", s.LinkDriver)
+		mux.HandleFunc("DELETE "+DriversPath+"/{steam_id}", s.UnlinkDriver)
+	}
+
+	// Rendered Discord embed for the admin GUI - only when a previewer is configured
+	if s.embedPreview != nil {
+		mux.HandleFunc("GET "+EmbedPreviewPath, s.PreviewEmbed)
+	}
+
+	// Servers as CSV for spreadsheet workflows - only when a converter is configured
+	if s.serversCSV != nil {
+		mux.HandleFunc("GET "+ServersCSVPath, s.ExportServersCSV)
+		mux.HandleFunc("POST "+ServersCSVPath, s.ImportServersCSV)
+	}
+
+	// Backups written before each config change - only when backups are listed
+	if s.configBackups != nil {
+		mux.HandleFunc("GET "+ConfigBackupsPath, s.ListConfigBackups)
+	}
+
+	// Health check details (auth required) - only when a reporter is set
+	if s.health != nil {
+		mux.HandleFunc("GET "+HealthChecksPath, s.GetHealthChecks)
+	}
+
+	// Self-diagnostics report for bug reports - only when a provider is set
+	if s.diagnostics != nil {
+		mux.HandleFunc("GET "+DiagnosticsPath, s.GetDiagnostics)
+	}
+
+	// Recent log lines and live tail for the admin GUI - only when a log buffer is set
+	// The stream is registered under v2 as well: the v2 envelope buffers responses and cannot stream
+	if s.logs != nil {
+		mux.HandleFunc("GET "+LogsPath, s.GetLogs)
+	}
+	if s.logs != nil && !s.noLogStream {
+		mux.HandleFunc("GET "+LogsStreamPath, s.StreamLogs)
+		mux.HandleFunc("GET "+v2Path(LogsStreamPath), s.StreamLogs)
+	}
+
+	// Runtime maintenance flags per server - only when a controller is set
+	if s.maintenance != nil {
+		mux.HandleFunc("GET "+MaintenancePath, s.ListMaintenance)
+		mux.HandleFunc("PUT "+MaintenancePath+"/{server}", s.SetMaintenance)
+		mux.HandleFunc("DELETE "+MaintenancePath+"/{server}", s.ClearMaintenance)
+	}
+
+	// Per-server track rotations saved in the config - only when an editor is set
+	if s.rotations != nil {
+		mux.HandleFunc("GET "+RotationsPath, s.ListRotations)
+		mux.HandleFunc("GET "+RotationsPath+"/{server}", s.GetRotation)
+		mux.HandleFunc("PUT "+RotationsPath+"/{server}", s.SetRotation)
+	}
+
+	// Reserved slots per server - only when an editor is set
+	if s.whitelists != nil {
+		mux.HandleFunc("GET "+WhitelistsPath, s.ListWhitelists)
+		mux.HandleFunc("GET "+WhitelistsPath+"/{server}", s.GetWhitelist)
+		mux.HandleFunc("PUT "+WhitelistsPath+"/{server}", s.SetWhitelist)
+	}
+
+	// Templated server config files with diff preview and rollback - only when a deployer is set
+	if s.deployer != nil {
+		mux.HandleFunc("GET "+DeploymentsPath+"/{server}", s.ListDeployments)
+		mux.HandleFunc("GET "+DeploymentsPath+"/{server}/preview", s.PreviewDeployment)
+		mux.HandleFunc("POST "+DeploymentsPath+"/{server}", s.Deploy)
+		mux.HandleFunc("POST "+DeploymentsPath+"/{server}/rollback", s.RollbackDeployment)
+	}
+
+	// Start/stop/restart of game server processes - only when a power backend is enabled
+	if s.power != nil {
+		mux.HandleFunc("GET "+PowerPath, s.ListPower)
+		mux.HandleFunc("POST "+PowerPath+"/{server}", s.PowerAction)
+	}
+
+	// Monthly uptime report from the server history - only when a provider is set
+	if s.sla != nil {
+		mux.HandleFunc("GET "+SLAPath, s.GetSLA)
+	}
+
+	// Player-hours and sessions from the server history - only when a provider is set
+	if s.usage != nil {
+		mux.HandleFunc("GET "+UsagePath, s.GetUsage)
+	}
+
+	// Occupancy by hour of the week from the server history - only when a provider is set
+	if s.heatmap != nil {
+		mux.HandleFunc("GET "+HeatmapPath, s.GetHeatmap)
+	}
+
+	// Purge of the data tied to a Discord user or Steam ID - only when a purger is set
+	if s.purger != nil {
+		mux.HandleFunc("POST "+PurgePath, s.Purge)
+	}
+
+	// Backup archive of the bot state - only when an archiver is set
+	if s.archiver != nil {
+		mux.HandleFunc("GET "+StateBackupPath, s.GetStateBackup)
+	}
+
+	// Join passwords of event servers - only when a manager is set
+	if s.passwords != nil {
+		mux.HandleFunc("GET "+PasswordsPath, s.ListPasswords)
+		mux.HandleFunc("POST "+PasswordsPath+"/{server}/rotate", s.RotatePassword)
+	}
+
+	// Config change audit log with undo - only when enabled
+	if s.audit != nil {
+		mux.HandleFunc("GET "+AuditPath, s.ListAuditEntries)
+		mux.HandleFunc("GET "+AuditPath+"/{id}", s.GetAuditEntry)
+		mux.HandleFunc("POST "+AuditPath+"/{id}/revert", s.RevertAuditEntry)
+	}
+
+	// First-run bootstrap (auth + CSRF) - only when started without a config
+	if s.setup != nil {
+		mux.HandleFunc("GET "+SetupPath, s.GetSetup)
+		mux.HandleFunc("PUT "+SetupPath+"/server-ip", s.SetupServerIP)
+		mux.HandleFunc("PUT "+SetupPath+"/categories", s.SetupCategories)
+		mux.HandleFunc("POST "+SetupPath+"/servers", s.SetupAddServer)
+		mux.HandleFunc("POST "+SetupPath+"/complete", s.SetupComplete)
+	}
+
+	registerPublicRoutes(mux, s)
+}
+
+// registerPublicRoutes registers the unauthenticated read-only endpoints, served on the API listener
+// and on the public listener
+func registerPublicRoutes(mux *http.ServeMux, s *Server) {
+	// Public status (no auth, open CORS) - only when a status provider is configured
+	if s.status != nil {
+		mux.HandleFunc("GET "+PublicStatusPath, s.PublicStatus)
+		mux.HandleFunc("GET "+PublicStatusServersPath, s.PublicStatusServers)
+	}
+
+	// Public status banner (no auth, open CORS) - only when an image provider is configured
+	if s.statusImage != nil {
+		mux.HandleFunc("GET "+StatusImagePath, s.StatusImage)
+	}
+
+	// Public iCalendar feed of events and maintenance windows (no auth, open CORS) - only when a calendar is set
+	if s.calendar != nil {
+		mux.HandleFunc("GET "+EventsCalendarPath, s.EventsCalendar)
+	}
+
+	// Public proxy of embed images (no auth, open CORS) - only when an image proxy is set
+	if s.images != nil {
+		mux.HandleFunc("GET "+ImagesPath+"/{name}", s.ProxiedImage)
+	}
 }
@@ -3,12 +3,19 @@ package api
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
+	"net"
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/bombom/absa-ac/pkg/drain"
+	"github.com/bombom/absa-ac/pkg/listen"
+	"github.com/bombom/absa-ac/pkg/requestid"
+	"github.com/bombom/absa-ac/pkg/supervisor"
 )
 
 // adminFS embeds the web/admin directory for single-binary deployment.
@@ -27,9 +34,119 @@ type Server struct {
 	corsOrigins    []string
 	trustedProxies []string
 
+	// status backs the optional public status endpoint (nil = disabled)
+	status StatusProvider
+
+	// statusImage backs the optional PNG status banner endpoint (nil = disabled)
+	statusImage StatusImageProvider
+
+	// calendar backs the optional iCalendar feed of events (nil = disabled)
+	calendar EventCalendar
+
+	// images backs the optional proxy of embed images (nil = disabled)
+	images ImageProxy
+
+	// sla backs the monthly uptime report endpoint (nil = disabled)
+	sla SLAProvider
+
+	// usage backs the player-hours and sessions endpoint (nil = disabled)
+	usage UsageProvider
+
+	// heatmap backs the occupancy heatmap endpoint (nil = disabled)
+	heatmap HeatmapProvider
+
+	// purger backs the personal data purge endpoint (nil = disabled)
+	purger DataPurger
+
+	// archiver backs the state backup download (nil = disabled)
+	archiver StateArchiver
+
+	// linter backs config lint warnings (nil = disabled)
+	linter ConfigLinter
+
+	// serverTester backs the server test endpoint (nil = disabled)
+	serverTester ServerTester
+
+	// embedPreview backs the embed preview endpoint (nil = disabled)
+	embedPreview EmbedPreviewer
+
+	// serversCSV backs CSV export/import of the servers array (nil = disabled)
+	serversCSV ServersCSV
+
+	// configBackups backs the config backup listing (nil = disabled)
+	configBackups ConfigBackups
+
+	// audit records API config changes for listing and undo (nil = disabled)
+	audit *configAudit
+
+	// diagnostics builds the self-diagnostics report (nil = disabled)
+	diagnostics DiagnosticsProvider
+
+	// logs backs the recent log endpoint and live tail (nil = disabled)
+	logs LogSource
+	// noLogStream leaves out the live tail while keeping the recent log endpoint
+	noLogStream bool
+
+	// maintenance backs the runtime maintenance flags (nil = disabled)
+	maintenance MaintenanceController
+
+	// rotations backs the track rotation endpoints (nil = disabled)
+	rotations RotationEditor
+
+	// entryLists backs the entry list endpoint (nil = disabled)
+	entryLists EntryListProvider
+
+	// standings backs the championship standings endpoint (nil = disabled)
+	standings StandingsProvider
+
+	// results backs the results upload endpoint (nil = disabled)
+	results ResultsIngester
+
+	// drivers backs the driver link endpoints (nil = disabled)
+	drivers DriverDirectory
+
+	// whitelists backs the reserved slot endpoints (nil = disabled)
+	whitelists WhitelistEditor
+
+	// deployer backs the config file deployment endpoints (nil = disabled)
+	deployer ConfigDeployer
+
+	// power backs the server power endpoints (nil = disabled)
+	power PowerController
+
+	// passwords backs the server password endpoints (nil = disabled)
+	passwords PasswordManager
+
+	// setup backs the first-run bootstrap endpoints (nil = started with a config)
+	setup SetupWizard
+
+	// health adds component checks to /health (nil = plain "ok")
+	health HealthReporter
+
+	// v1Sunset is the announced removal date of v1 routes (zero = none scheduled)
+	v1Sunset time.Time
+
+	// listeners replace the default TCP listener on the port (nil = listen on ":"+port)
+	listeners []net.Listener
+
+	// public serves the read-only endpoints on a second listener (nil = disabled)
+	public *PublicListener
+	// publicServer and publicInFlight are the public listener's server and drain counter (set by Start)
+	publicServer   *http.Server
+	publicInFlight drain.Tracker
+
+	// drainTimeout bounds how long shutdown waits for in-flight requests (0 = drain.DefaultTimeout)
+	drainTimeout time.Duration
+
+	// inFlight counts active requests so shutdown can report how many it drained
+	inFlight drain.Tracker
+
 	// wg tracks graceful shutdown completion
 	wg sync.WaitGroup
 
+	// stopping is closed when shutdown begins, ending long-lived streams that would hold up the drain
+	stopping <-chan struct{}
+
 	// cancel is stored to allow Stop() to cancel the Start() context
 	cancel context.CancelFunc
 	cancelMu sync.Mutex
@@ -43,6 +160,10 @@ type ConfigManager interface {
 	UpdateConfig(map[string]interface{}) error
 }
 
+// ErrConfigReadOnly is returned (wrapped) by ConfigManager writes when the config comes
+// from a read-only source such as environment variables; handlers answer 409 Conflict
+var ErrConfigReadOnly = errors.New("config is read-only")
+
 // NewServer creates a new API server with the given config manager and configuration
 // Port is the listen address (e.g., "3001" for :3001)
 // Bearer token is required for all authenticated endpoints
@@ -64,9 +185,22 @@ func NewServer(cm ConfigManager, port string, bearerToken string, corsOrigins []
 	}
 }
 
+// SetDrainTimeout sets how long shutdown waits for in-flight requests before closing them
+// Must be called before Start
+func (s *Server) SetDrainTimeout(d time.Duration) {
+	s.drainTimeout = d
+}
+
+// SetListeners serves on already opened listeners (TCP, Unix sockets, systemd sockets) instead of the port
+// The server closes them on shutdown. Must be called before Start
+func (s *Server) SetListeners(listeners []net.Listener) {
+	s.listeners = listeners
+}
+
 // Start begins the HTTP server in a background goroutine
 // Blocks until Stop() is called, then performs graceful shutdown
-// Returns error if graceful shutdown fails
+// Returns error if graceful shutdown fails, or a supervisor.Fatal error if the server cannot start
+// (port in use, missing admin files), which a restart would not fix
 func (s *Server) Start(ctx context.Context) error {
 	// Create a cancellable context for this server
 	// This allows Stop() to cancel it without needing access to the caller's context
@@ -75,12 +209,13 @@ func (s *Server) Start(ctx context.Context) error {
 	s.cancelMu.Lock()
 	s.cancel = serverCancel
 	s.cancelMu.Unlock()
+	s.stopping = serverCtx.Done()
 
 	// Set up router with middleware
 	mux := http.NewServeMux()
 
 	// Apply middleware chain (order matters: each middleware wraps the previous one)
-	// Execution order (outer to inner): SecurityHeaders → CORS → Logger → RateLimit → BearerAuth
+	// Execution order (outer to inner): RequestID → SecurityHeaders → CORS → Logger → RateLimit → BearerAuth
 	securityHeadersMiddleware := SecurityHeaders()
 	// CORS: second layer (cross-origin checks before auth)
 	corsMiddleware := CORS(s.corsOrigins)
@@ -90,12 +225,15 @@ func (s *Server) Start(ctx context.Context) error {
 	// CSRF defense-in-depth: validates state-changing requests following auth
 
 	var handler http.Handler = mux
+	handler = DeprecateV1(s.v1Sunset)(handler)           // Deprecation/Sunset headers on v1 routes
 	handler = CSRF(handler)                              // CSRF validation for state-changing requests
 	handler = authMiddleware(handler)                    // Innermost: check auth last
 	handler = rateLimitMiddleware(handler)               // Apply rate limiting before expensive auth
 	handler = loggerMiddleware(handler)                  // Log all requests including rate limited ones
 	handler = corsMiddleware(handler)                    // Handle CORS preflight before rate limiting
-	handler = securityHeadersMiddleware(handler)         // Outermost: security headers applied to all responses
+	handler = securityHeadersMiddleware(handler)         // Security headers applied to all responses
+	handler = requestid.Middleware(handler)              // Request ID for logs and error responses, before anything can fail
+	handler = s.inFlight.Wrap(handler)                   // Outermost: count in-flight requests for shutdown drain
 
 	s.httpServer.Handler = handler
 
@@ -107,7 +245,8 @@ func (s *Server) Start(ctx context.Context) error {
 	// /admin route provides clean separation from public /health endpoint
 	adminSubFS, err := fs.Sub(adminFS, "web/admin")
 	if err != nil {
-		return fmt.Errorf("failed to load embedded admin files: %w", err)
+		serverCancel()
+		return supervisor.Fatal(fmt.Errorf("failed to load embedded admin files: %w", err))
 	}
 	fileServer := http.FileServer(http.FS(adminSubFS))
 
@@ -116,28 +255,47 @@ func (s *Server) Start(ctx context.Context) error {
 	mux.Handle("GET /admin/", http.StripPrefix("/admin", adminHandler))
 	mux.Handle("GET /admin", http.RedirectHandler("/admin/", http.StatusMovedPermanently))
 
-	// Start server in background
-	s.wg.Add(1)
-	go func() {
-		defer s.wg.Done()
-		s.logger.Printf("API server listening on %s", s.httpServer.Addr)
-
-		// ListenAndServe blocks until server shutdown
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			s.logger.Printf("API server error: %v", err)
+	// Bind before serving so a taken port fails Start instead of only being logged
+	listeners := s.listeners
+	if len(listeners) == 0 {
+		l, err := net.Listen("tcp", s.httpServer.Addr)
+		if err != nil {
+			serverCancel()
+			return supervisor.Fatal(fmt.Errorf("API server: %w", err))
 		}
-	}()
+		listeners = []net.Listener{l}
+	}
+
+	// Start server in background
+	for _, l := range listeners {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.logger.Printf("API server listening on %s", listen.Name(l))
+
+			// Serve blocks until server shutdown, which also closes l
+			if err := s.httpServer.Serve(l); err != nil && err != http.ErrServerClosed {
+				s.logger.Printf("API server error on %s: %v", listen.Name(l), err)
+			}
+		}()
+	}
+
+	if s.public != nil {
+		s.startPublicListener(serverCtx)
+	}
 
 	// Wait for context cancellation
 	<-serverCtx.Done()
 	s.logger.Println("Shutting down API server...")
 
-	// Initiate graceful shutdown
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
-		return fmt.Errorf("API server shutdown failed: %w", err)
+	// Stop accepting, close idle connections, let in-flight requests finish within the drain timeout
+	// Both listeners drain at the same time
+	publicErr := make(chan error, 1)
+	go func() { publicErr <- s.stopPublicListener() }()
+	err = drain.Shutdown(s.httpServer, &s.inFlight, s.drainTimeout, s.logger, "API server")
+	if err := errors.Join(err, <-publicErr); err != nil {
+		s.wg.Wait()
+		return err
 	}
 
 	// Wait for server goroutine to finish
@@ -148,7 +306,7 @@ func (s *Server) Start(ctx context.Context) error {
 }
 
 // Stop gracefully shuts down the HTTP server
-// Allows in-flight requests up to 30 seconds to complete
+// Allows in-flight requests up to the drain timeout (default 30 seconds) to complete
 // Called by main bot during shutdown sequence
 func (s *Server) Stop() error {
 	s.cancelMu.Lock()
@@ -2,11 +2,15 @@ package api
 
 import (
 	"context"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"testing"
 	"time"
+
+	"github.com/bombom/absa-ac/pkg/supervisor"
 )
 
 // mockConfigManager is a test double for ConfigManager
@@ -145,3 +149,26 @@ func TestServer_InFlightRequestsComplete(t *testing.T) {
 		t.Error("In-flight request did not complete before shutdown timeout")
 	}
 }
+
+// TestServer_StartPortInUse tests that a taken port fails Start with an error the supervisor does not retry
+func TestServer_StartPortInUse(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+	_, port, _ := net.SplitHostPort(taken.Addr().String())
+
+	s := NewServer(&mockConfigManager{}, port, "valid-token", []string{}, []string{}, log.New(io.Discard, "", 0))
+	done := make(chan error, 1)
+	go func() { done <- s.Start(context.Background()) }()
+	select {
+	case err := <-done:
+		if !supervisor.IsFatal(err) {
+			t.Errorf("Expected a fatal bind error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		s.Stop()
+		t.Fatal("Start did not fail on a port in use")
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// ServersCSVPath exports (GET) and imports (POST) the servers array as CSV
+const ServersCSVPath = "/api/config/servers/csv"
+
+// CSVRowError is a problem with one CSV row (row 1 is the header, 0 = the file as a whole)
+type CSVRowError struct {
+	Row     int    `json:"row,omitempty"`
+	Message string `json:"message"`
+}
+
+// CSVImportReport describes what an import changed, or would change in dry-run mode
+// Servers are matched by name against the current config
+type CSVImportReport struct {
+	DryRun   bool          `json:"dry_run"`
+	Applied  bool          `json:"applied"`
+	Servers  int           `json:"servers"`
+	Added    []string      `json:"added"`
+	Updated  []string      `json:"updated"`
+	Removed  []string      `json:"removed"`
+	Errors   []CSVRowError `json:"errors"`
+	Warnings []LintWarning `json:"warnings"`
+}
+
+// ServersCSV converts the servers array to and from CSV
+// ImportServersCSV replaces all servers; it returns a report with Errors set (and saves nothing)
+// when any row is invalid, and an error only when the import could not be processed at all
+type ServersCSV interface {
+	ExportServersCSV(w io.Writer) error
+	ImportServersCSV(r io.Reader, dryRun bool) (*CSVImportReport, error)
+}
+
+// SetServersCSV enables CSV export/import of the servers array
+// Must be called before Start
+func (s *Server) SetServersCSV(c ServersCSV) {
+	s.serversCSV = c
+}
+
+// ExportServersCSV returns the servers array as a downloadable CSV file
+// Requires Bearer token authentication
+func (s *Server) ExportServersCSV(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("ExportServersCSV cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	// Render fully before writing headers so an error can still become a JSON error response
+	var buf bytes.Buffer
+	if err := s.serversCSV.ExportServersCSV(&buf); err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to export servers", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"servers.csv\"")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("ExportServersCSV write failed: %v", err)
+	}
+}
+
+// ImportServersCSV replaces the servers array from a CSV request body
+// ?dry_run=true validates and reports the changes without saving
+// Requires Bearer token authentication and CSRF token
+func (s *Server) ImportServersCSV(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("ImportServersCSV cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	if r.Body == nil {
+		WriteError(w, http.StatusBadRequest, "Empty request body", "POST requires a CSV body")
+		return
+	}
+	defer r.Body.Close()
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid dry_run parameter", "Use dry_run=true or dry_run=false")
+			return
+		}
+		dryRun = parsed
+	}
+
+	// Limit request body size to 1MB (prevent memory exhaustion)
+	const maxBodySize = 1 << 20 // 1MB
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	var report *CSVImportReport
+	err := s.auditedWrite(w, r, func() error {
+		var err error
+		report, err = s.serversCSV.ImportServersCSV(r.Body, dryRun)
+		return err
+	})
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteError(w, http.StatusRequestEntityTooLarge, "Request body too large",
+				"Maximum size is 1MB")
+			return
+		}
+		writeConfigWriteError(w, "Servers import failed", err)
+		return
+	}
+
+	if len(report.Errors) > 0 {
+		WriteJSON(w, http.StatusUnprocessableEntity, report)
+		return
+	}
+	WriteJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// mockServersCSV is a test double for ServersCSV
+type mockServersCSV struct {
+	export    string
+	exportErr error
+	report    *CSVImportReport
+	importErr error
+	gotBody   string
+	gotDryRun bool
+}
+
+func (m *mockServersCSV) ExportServersCSV(w io.Writer) error {
+	if m.exportErr != nil {
+		return m.exportErr
+	}
+	_, err := io.WriteString(w, m.export)
+	return err
+}
+
+func (m *mockServersCSV) ImportServersCSV(r io.Reader, dryRun bool) (*CSVImportReport, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	m.gotBody = string(data)
+	m.gotDryRun = dryRun
+	return m.report, m.importErr
+}
+
+func newServersCSVServer(m *mockServersCSV) *Server {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetServersCSV(m)
+	return s
+}
+
+func TestExportServersCSV(t *testing.T) {
+	s := newServersCSVServer(&mockServersCSV{export: "name,port,category\nA,8081,GT3\n"})
+
+	rec := httptest.NewRecorder()
+	s.ExportServersCSV(rec, httptest.NewRequest("GET", ServersCSVPath, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, "servers.csv") {
+		t.Errorf("Content-Disposition = %q, want servers.csv attachment", cd)
+	}
+	if rec.Body.String() != "name,port,category\nA,8081,GT3\n" {
+		t.Errorf("Body = %q", rec.Body.String())
+	}
+}
+
+func TestExportServersCSV_Error(t *testing.T) {
+	s := newServersCSVServer(&mockServersCSV{exportErr: errors.New("no config loaded")})
+
+	rec := httptest.NewRecorder()
+	s.ExportServersCSV(rec, httptest.NewRequest("GET", ServersCSVPath, nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json error", ct)
+	}
+}
+
+func TestImportServersCSV(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		report     *CSVImportReport
+		importErr  error
+		wantStatus int
+		wantDryRun bool
+	}{
+		{"applied", "", &CSVImportReport{Applied: true, Servers: 1}, nil, http.StatusOK, false},
+		{"dry run", "?dry_run=true", &CSVImportReport{DryRun: true, Servers: 1}, nil, http.StatusOK, true},
+		{"row errors", "?dry_run=1", &CSVImportReport{DryRun: true, Errors: []CSVRowError{{Row: 2, Message: "name is empty"}}}, nil, http.StatusUnprocessableEntity, true},
+		{"unreadable file", "", nil, errors.New("CSV header is missing required column \"port\""), http.StatusBadRequest, false},
+		{"bad dry_run", "?dry_run=maybe", nil, nil, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockServersCSV{report: tt.report, importErr: tt.importErr}
+			s := newServersCSVServer(m)
+
+			req := httptest.NewRequest("POST", ServersCSVPath+tt.query, strings.NewReader("name,port,category\n"))
+			req.Header.Set("Content-Type", "text/csv")
+			rec := httptest.NewRecorder()
+			s.ImportServersCSV(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if m.gotDryRun != tt.wantDryRun {
+				t.Errorf("dryRun = %v, want %v", m.gotDryRun, tt.wantDryRun)
+			}
+			if tt.report != nil {
+				var got CSVImportReport
+				if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+					t.Fatalf("Failed to decode report: %v", err)
+				}
+				if got.Servers != tt.report.Servers || len(got.Errors) != len(tt.report.Errors) {
+					t.Errorf("Report = %+v, want %+v", got, tt.report)
+				}
+			}
+		})
+	}
+}
+
+func TestImportServersCSV_TooLarge(t *testing.T) {
+	s := newServersCSVServer(&mockServersCSV{})
+
+	req := httptest.NewRequest("POST", ServersCSVPath, strings.NewReader(strings.Repeat("a", 1<<20+1)))
+	rec := httptest.NewRecorder()
+	s.ImportServersCSV(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestServersCSV_NotRegisteredWithoutConverter(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newPublicTestHandler(t, s)
+
+	req := httptest.NewRequest("GET", ServersCSVPath, nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
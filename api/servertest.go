@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// ServerTestPath polls a single server definition without saving it
+const ServerTestPath = "/api/v1/servers/test"
+
+// ServerTestResult is the live poll result for a tested server definition
+// An unreachable server is a successful test with Reachable=false and Error set
+type ServerTestResult struct {
+	Reachable  bool   `json:"reachable"`
+	Map        string `json:"map,omitempty"`
+	Players    int    `json:"players"`
+	MaxPlayers int    `json:"max_players"`
+	LatencyMS  int64  `json:"latency_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ServerTester polls a server entry (same fields as config.json servers[]) on demand
+// Returns an error only when the definition itself is invalid
+type ServerTester interface {
+	TestServer(ctx context.Context, server map[string]interface{}) (*ServerTestResult, error)
+}
+
+// SetServerTester enables POST /api/v1/servers/test
+// Must be called before Start
+func (s *Server) SetServerTester(t ServerTester) {
+	s.serverTester = t
+}
+
+// TestServer polls the posted server definition and returns reachability, players, map and latency
+// Requires Bearer token authentication and CSRF token; nothing is saved
+func (s *Server) TestServer(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("TestServer cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	if r.Body == nil {
+		WriteError(w, http.StatusBadRequest, "Empty request body", "POST requires JSON body with a server definition")
+		return
+	}
+	defer r.Body.Close()
+
+	// A single server entry is tiny; 64KB is generous
+	const maxBodySize = 64 << 10
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	var server map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&server); err != nil {
+		if err.Error() == "http: request body too large" {
+			WriteError(w, http.StatusRequestEntityTooLarge, "Request body too large",
+				"Maximum size is 64KB")
+			return
+		}
+		WriteError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	result, err := s.serverTester.TestServer(r.Context(), server)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid server definition", err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// mockServerTester is a test double for ServerTester
+type mockServerTester struct {
+	result *ServerTestResult
+	err    error
+	got    map[string]interface{}
+}
+
+func (m *mockServerTester) TestServer(ctx context.Context, server map[string]interface{}) (*ServerTestResult, error) {
+	m.got = server
+	return m.result, m.err
+}
+
+func newServerTestServer(tester ServerTester) *Server {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetServerTester(tester)
+	return s
+}
+
+// TestTestServer_ReturnsPollResult tests that the posted definition is passed through and the result returned
+func TestTestServer_ReturnsPollResult(t *testing.T) {
+	tester := &mockServerTester{result: &ServerTestResult{Reachable: true, Map: "spa", Players: 3, MaxPlayers: 24, LatencyMS: 12}}
+	s := newServerTestServer(tester)
+
+	req := httptest.NewRequest("POST", ServerTestPath, strings.NewReader(`{"name":"GT3 #1","port":8081,"category":"GT3"}`))
+	rec := httptest.NewRecorder()
+	s.TestServer(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if tester.got["name"] != "GT3 #1" {
+		t.Errorf("Tester received %v, want name GT3 #1", tester.got)
+	}
+
+	var body ServerTestResult
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !body.Reachable || body.Map != "spa" || body.Players != 3 || body.MaxPlayers != 24 || body.LatencyMS != 12 {
+		t.Errorf("Unexpected result: %+v", body)
+	}
+}
+
+// TestTestServer_UnreachableIsOK tests that an unreachable server is reported in the body, not as an HTTP error
+func TestTestServer_UnreachableIsOK(t *testing.T) {
+	s := newServerTestServer(&mockServerTester{result: &ServerTestResult{Error: "connection refused", LatencyMS: 1}})
+
+	rec := httptest.NewRecorder()
+	s.TestServer(rec, httptest.NewRequest("POST", ServerTestPath, strings.NewReader(`{"port":8081}`)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), `"reachable":false`) {
+		t.Errorf("Expected reachable=false in body, got %s", rec.Body.String())
+	}
+}
+
+// TestTestServer_BadRequests tests malformed JSON and definitions rejected by the tester
+func TestTestServer_BadRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  error
+	}{
+		{"invalid JSON", `{port:`, nil},
+		{"invalid definition", `{"port":0}`, errors.New("invalid port: 0")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServerTestServer(&mockServerTester{err: tt.err})
+			rec := httptest.NewRecorder()
+			s.TestServer(rec, httptest.NewRequest("POST", ServerTestPath, strings.NewReader(tt.body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+// TestTestServer_Routing tests that the endpoint requires auth and is absent without a tester
+func TestTestServer_Routing(t *testing.T) {
+	s := newServerTestServer(&mockServerTester{result: &ServerTestResult{}})
+	handler := newPublicTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", ServerTestPath, strings.NewReader(`{"port":8081}`)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Unauthenticated status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	disabled := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	req := httptest.NewRequest("POST", ServerTestPath, strings.NewReader(`{"port":8081}`))
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rec = httptest.NewRecorder()
+	newPublicTestHandler(t, disabled).ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Disabled status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// SetupPath is the base of the first-run bootstrap endpoints
+// Only registered when a SetupWizard is set (the bot started without a config file)
+const SetupPath = "/api/setup"
+
+// ErrSetupComplete is returned by SetupWizard methods once a config exists
+var ErrSetupComplete = errors.New("setup already completed: a config is loaded, use /api/config instead")
+
+// SetupStatus is the bootstrap progress returned by GET /api/setup
+type SetupStatus struct {
+	// Required is true until a config has been written
+	Required bool `json:"setup_required"`
+	// Draft is the config assembled so far
+	Draft any `json:"draft"`
+	// Missing lists the steps still needed before complete succeeds
+	Missing []string `json:"missing"`
+}
+
+// SetupWizard builds the initial config step by step while no config exists
+// Every method returns ErrSetupComplete after the config has been written; other errors are invalid input
+type SetupWizard interface {
+	SetupStatus() SetupStatus
+	SetServerIP(ip string) error
+	SetCategories(order []string, emojis map[string]string) error
+	AddServer(server map[string]interface{}) error
+	Complete() error
+}
+
+// SetSetupWizard enables the /api/setup bootstrap endpoints
+// Must be called before Start
+func (s *Server) SetSetupWizard(w SetupWizard) {
+	s.setup = w
+}
+
+// decodeSetupBody decodes a small JSON body, writing the error response on failure
+func decodeSetupBody(w http.ResponseWriter, r *http.Request, dst any) bool {
+	if r.Body == nil {
+		WriteError(w, http.StatusBadRequest, "Empty request body", "JSON body required")
+		return false
+	}
+	defer r.Body.Close()
+
+	// Setup payloads are tiny; 64KB is generous
+	const maxBodySize = 64 << 10
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteError(w, http.StatusRequestEntityTooLarge, "Request body too large", "Maximum size is 64KB")
+			return false
+		}
+		WriteError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return false
+	}
+	return true
+}
+
+// writeSetupResult reports the outcome of a setup step: the updated status, 409 after completion, 400 otherwise
+func (s *Server) writeSetupResult(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrSetupComplete) {
+		WriteError(w, http.StatusConflict, "Setup already completed", err.Error())
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Setup step failed", err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, s.setup.SetupStatus())
+}
+
+// GetSetup returns whether setup is required, the draft config and the remaining steps
+// Requires Bearer token authentication
+func (s *Server) GetSetup(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("GetSetup cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	WriteJSON(w, http.StatusOK, s.setup.SetupStatus())
+}
+
+// SetupServerIP sets the public server address used by all servers ({"server_ip": "..."})
+// Requires Bearer token authentication and CSRF token
+func (s *Server) SetupServerIP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ServerIP string `json:"server_ip"`
+	}
+	if !decodeSetupBody(w, r, &body) {
+		return
+	}
+	s.writeSetupResult(w, s.setup.SetServerIP(body.ServerIP))
+}
+
+// SetupCategories sets category order and emojis ({"category_order": [...], "category_emojis": {...}})
+// Requires Bearer token authentication and CSRF token
+func (s *Server) SetupCategories(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		CategoryOrder  []string          `json:"category_order"`
+		CategoryEmojis map[string]string `json:"category_emojis"`
+	}
+	if !decodeSetupBody(w, r, &body) {
+		return
+	}
+	s.writeSetupResult(w, s.setup.SetCategories(body.CategoryOrder, body.CategoryEmojis))
+}
+
+// SetupAddServer adds a server entry to the draft (same fields as config.json servers[])
+// Requires Bearer token authentication and CSRF token
+func (s *Server) SetupAddServer(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if !decodeSetupBody(w, r, &body) {
+		return
+	}
+	s.writeSetupResult(w, s.setup.AddServer(body))
+}
+
+// SetupComplete validates the draft, writes the initial config and switches to normal operation
+// Requires Bearer token authentication and CSRF token
+func (s *Server) SetupComplete(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("SetupComplete cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	s.writeSetupResult(w, s.setup.Complete())
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// mockSetupWizard is a test double for SetupWizard that records the last step
+type mockSetupWizard struct {
+	err    error
+	called string
+	ip     string
+	order  []string
+	server map[string]interface{}
+}
+
+func (m *mockSetupWizard) SetupStatus() SetupStatus {
+	return SetupStatus{Required: m.err == nil, Draft: map[string]any{"server_ip": m.ip}, Missing: []string{"servers"}}
+}
+
+func (m *mockSetupWizard) SetServerIP(ip string) error {
+	m.called, m.ip = "server-ip", ip
+	return m.err
+}
+
+func (m *mockSetupWizard) SetCategories(order []string, emojis map[string]string) error {
+	m.called, m.order = "categories", order
+	return m.err
+}
+
+func (m *mockSetupWizard) AddServer(server map[string]interface{}) error {
+	m.called, m.server = "servers", server
+	return m.err
+}
+
+func (m *mockSetupWizard) Complete() error {
+	m.called = "complete"
+	return m.err
+}
+
+// newSetupTestHandler routes requests to a server with the given wizard (no auth/CSRF layers)
+func newSetupTestHandler(w SetupWizard) http.Handler {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	if w != nil {
+		s.SetSetupWizard(w)
+	}
+	mux := http.NewServeMux()
+	RegisterRoutes(mux, s)
+	return mux
+}
+
+func TestSetupEndpoints(t *testing.T) {
+	tests := []struct {
+		method, path, body, wantCall string
+	}{
+		{"PUT", "/api/setup/server-ip", `{"server_ip":"203.0.113.10"}`, "server-ip"},
+		{"PUT", "/api/setup/categories", `{"category_order":["GT3"],"category_emojis":{"GT3":"🏎️"}}`, "categories"},
+		{"POST", "/api/setup/servers", `{"name":"GT3 #1","port":8081,"category":"GT3"}`, "servers"},
+		{"POST", "/api/setup/complete", ``, "complete"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantCall, func(t *testing.T) {
+			m := &mockSetupWizard{}
+			rec := httptest.NewRecorder()
+			newSetupTestHandler(m).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			if m.called != tt.wantCall {
+				t.Errorf("Called %q, want %q", m.called, tt.wantCall)
+			}
+
+			var status SetupStatus
+			if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+				t.Fatalf("Failed to decode status: %v", err)
+			}
+			if !status.Required {
+				t.Error("Expected the updated setup status in the response")
+			}
+		})
+	}
+}
+
+func TestSetupEndpoints_PassesFields(t *testing.T) {
+	m := &mockSetupWizard{}
+	h := newSetupTestHandler(m)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/api/setup/server-ip", strings.NewReader(`{"server_ip":"203.0.113.10"}`)))
+	if m.ip != "203.0.113.10" {
+		t.Errorf("server_ip = %q", m.ip)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/api/setup/categories", strings.NewReader(`{"category_order":["GT3","Drift"],"category_emojis":{}}`)))
+	if strings.Join(m.order, ",") != "GT3,Drift" {
+		t.Errorf("category_order = %v", m.order)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/setup/servers", strings.NewReader(`{"name":"GT3 #1","port":8081}`)))
+	if m.server["name"] != "GT3 #1" {
+		t.Errorf("server = %v", m.server)
+	}
+}
+
+func TestSetupEndpoints_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		body       string
+		wantStatus int
+	}{
+		{"invalid step", errors.New("server_ip cannot be empty"), `{"server_ip":""}`, http.StatusBadRequest},
+		{"already complete", ErrSetupComplete, `{"server_ip":"203.0.113.10"}`, http.StatusConflict},
+		{"invalid JSON", nil, `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newSetupTestHandler(&mockSetupWizard{err: tt.err}).ServeHTTP(rec, httptest.NewRequest("PUT", "/api/setup/server-ip", strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSetupEndpoints_NotRegisteredWithConfig(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newSetupTestHandler(nil).ServeHTTP(rec, httptest.NewRequest("GET", SetupPath, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// SLAPath serves the monthly uptime report computed from the server history
+// Only registered when an SLAProvider is set (HISTORY_ENABLED=true)
+const SLAPath = "/api/v1/sla"
+
+// slaMonthFormat is the ?month= parameter and SLAReport.Month ("2026-09")
+const slaMonthFormat = "2006-01"
+
+// SLAServer is the uptime and usage of one server during the month
+type SLAServer struct {
+	Server   string `json:"server"`
+	Category string `json:"category"`
+	// UptimePercent is online polls over all polls outside maintenance (nil = no such polls)
+	UptimePercent    *float64 `json:"uptime_percent"`
+	Polls            int      `json:"polls"`
+	MaintenancePolls int      `json:"maintenance_polls"`
+	PlayerHours      float64  `json:"player_hours"`
+	Sessions         int      `json:"sessions"`
+}
+
+// SLATrack is the player-hours spent on one track during the month
+type SLATrack struct {
+	Track       string  `json:"track"`
+	PlayerHours float64 `json:"player_hours"`
+}
+
+// SLAReport is the uptime report of one calendar month (UTC)
+type SLAReport struct {
+	Month         string      `json:"month"`
+	From          time.Time   `json:"from"`
+	To            time.Time   `json:"to"`
+	UptimePercent *float64    `json:"uptime_percent"` // over all servers
+	PlayerHours   float64     `json:"player_hours"`
+	Sessions      int         `json:"sessions"`
+	Servers       []SLAServer `json:"servers"`
+	TopTracks     []SLATrack  `json:"top_tracks"`
+}
+
+// SLAProvider computes the report of the month starting at month (first day, 00:00 UTC)
+type SLAProvider interface {
+	SLAReport(month time.Time) *SLAReport
+}
+
+// SetSLAProvider enables the uptime report endpoint
+// Must be called before Start
+func (s *Server) SetSLAProvider(p SLAProvider) {
+	s.sla = p
+}
+
+// GetSLA returns the uptime report of ?month=YYYY-MM (default: the current month, so far)
+// Requires Bearer token authentication
+func (s *Server) GetSLA(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("GetSLA cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	month := time.Now().UTC()
+	if v := r.URL.Query().Get("month"); v != "" {
+		m, err := time.Parse(slaMonthFormat, v)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid month", "month must be YYYY-MM, e.g. 2026-09")
+			return
+		}
+		month = m
+	}
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	WriteJSON(w, http.StatusOK, s.sla.SLAReport(month))
+}
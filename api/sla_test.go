@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+type mockSLAProvider struct {
+	month time.Time
+}
+
+func (m *mockSLAProvider) SLAReport(month time.Time) *SLAReport {
+	m.month = month
+	return &SLAReport{Month: month.Format(slaMonthFormat), From: month, To: month.AddDate(0, 1, 0), Servers: []SLAServer{}, TopTracks: []SLATrack{}}
+}
+
+func TestGetSLA(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	provider := &mockSLAProvider{}
+	s.SetSLAProvider(provider)
+	handler := newVersionedTestHandler(t, s)
+
+	rec := auditDo(t, handler, "GET", SLAPath+"?month=2026-09", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	var report SLAReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil || report.Month != "2026-09" {
+		t.Errorf("Unexpected report %s (%v)", rec.Body.String(), err)
+	}
+	if !provider.month.Equal(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the first of September, got %v", provider.month)
+	}
+
+	if rec := auditDo(t, handler, "GET", SLAPath, ""); rec.Code != http.StatusOK || provider.month.Day() != 1 || provider.month.Month() != time.Now().UTC().Month() {
+		t.Errorf("Expected the current month by default, got %d for %v", rec.Code, provider.month)
+	}
+	if rec := auditDo(t, handler, "GET", SLAPath+"?month=September", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Status = %d, want 400 for an invalid month", rec.Code)
+	}
+}
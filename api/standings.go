@@ -0,0 +1,55 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// StandingsPath returns the championship standings computed from the results folder (GET)
+const StandingsPath = "/api/v1/standings"
+
+// DriverStanding is one driver of the championship
+// Drivers are identified by name only; Steam GUIDs from the results files are not published
+type DriverStanding struct {
+	Position int    `json:"position"`
+	Driver   string `json:"driver"`
+	// DiscordUserID is set for drivers who linked their Steam account
+	DiscordUserID string `json:"discord_user_id,omitempty"`
+	Team          string `json:"team,omitempty"`
+	Points        int    `json:"points"`
+	Races         int    `json:"races"`
+	Wins          int    `json:"wins"`
+	Podiums       int    `json:"podiums"`
+	BestFinish    int    `json:"best_finish,omitempty"` // 0 = never finished
+}
+
+// Standings is the response of StandingsPath
+type Standings struct {
+	Title     string           `json:"title"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Races     int              `json:"races"`
+	Drivers   []DriverStanding `json:"drivers"` // leader first
+}
+
+// StandingsProvider supplies the standings of the last results folder scan
+type StandingsProvider interface {
+	Standings() Standings
+}
+
+// SetStandingsProvider enables the standings endpoint
+// Must be called before Start
+func (s *Server) SetStandingsProvider(p StandingsProvider) {
+	s.standings = p
+}
+
+// GetStandings returns the championship standings
+// Requires Bearer token authentication
+func (s *Server) GetStandings(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("GetStandings cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	WriteJSON(w, http.StatusOK, s.standings.Standings())
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeStandings struct {
+	standings Standings
+}
+
+func (f *fakeStandings) Standings() Standings { return f.standings }
+
+// TestStandingsEndpoint tests the standings response and that the route only exists when enabled
+func TestStandingsEndpoint(t *testing.T) {
+	s := &Server{}
+	mux := http.NewServeMux()
+	RegisterRoutes(mux, s)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, StandingsPath, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a provider, got %d", rec.Code)
+	}
+
+	s.SetStandingsProvider(&fakeStandings{standings: Standings{
+		Title:     "Autumn Cup",
+		UpdatedAt: time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC),
+		Races:     2,
+		Drivers: []DriverStanding{
+			{Position: 1, Driver: "Bobby", Points: 43, Races: 2, Wins: 1, Podiums: 2, BestFinish: 1},
+			{Position: 2, Driver: "Alice", Team: "Red Team", Points: 40, Races: 2, Wins: 1, Podiums: 2, BestFinish: 1},
+		},
+	}})
+	mux = http.NewServeMux()
+	RegisterRoutes(mux, s)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, StandingsPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var got Standings
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if got.Races != 2 || len(got.Drivers) != 2 || got.Drivers[1].Team != "Red Team" || got.Drivers[0].Points != 43 {
+		t.Errorf("Unexpected standings %+v", got)
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// StateBackupPath downloads a zip of the bot state: config.json and its backups, the state files next to it
+// (message IDs, driver links, deployments) and the database tables (history, audit log)
+const StateBackupPath = "/api/v1/backup"
+
+// StateArchiver writes a backup archive of the bot state
+type StateArchiver interface {
+	WriteStateBackup(w io.Writer, now time.Time) error
+}
+
+// SetStateArchiver enables the backup endpoint
+// Must be called before Start
+func (s *Server) SetStateArchiver(a StateArchiver) {
+	s.archiver = a
+}
+
+// StateBackupName is the file name of a backup taken at now
+func StateBackupName(now time.Time) string {
+	return "absa-backup-" + now.UTC().Format("2006-01-02T15-04-05") + ".zip"
+}
+
+// GetStateBackup sends the backup archive as a download. The archive is built in memory first, so a
+// failure is reported as JSON instead of a truncated zip. It holds config secrets (proxy password, join
+// passwords, webhook URLs) but not the .env
+// Requires Bearer token authentication
+func (s *Server) GetStateBackup(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("GetStateBackup cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	now := time.Now()
+	var buf bytes.Buffer
+	if err := s.archiver.WriteStateBackup(&buf, now); err != nil {
+		log.Printf("Error writing state backup: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Backup failed", err.Error())
+		return
+	}
+	log.Printf("Audit: state backup downloaded by %s (%d bytes)", extractClientIP(r, s.trustedProxies), buf.Len())
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+StateBackupName(now)+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
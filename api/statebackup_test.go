@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockStateArchiver struct {
+	err error
+}
+
+func (m *mockStateArchiver) WriteStateBackup(w io.Writer, now time.Time) error {
+	if m.err != nil {
+		w.Write([]byte("partial"))
+		return m.err
+	}
+	_, err := w.Write([]byte("PK archive"))
+	return err
+}
+
+func TestGetStateBackup(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	archiver := &mockStateArchiver{}
+	s.SetStateArchiver(archiver)
+	handler := newVersionedTestHandler(t, s)
+
+	rec := auditDo(t, handler, "GET", StateBackupPath, "")
+	if rec.Code != http.StatusOK || rec.Body.String() != "PK archive" {
+		t.Fatalf("Expected the archive, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != "application/zip" || !strings.Contains(rec.Header().Get("Content-Disposition"), `filename="absa-backup-`) {
+		t.Errorf("Unexpected headers %v", rec.Header())
+	}
+
+	archiver.err = errors.New("database is locked")
+	rec = auditDo(t, handler, "GET", StateBackupPath, "")
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "partial") {
+		t.Errorf("Expected a JSON error without the partial archive, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetStateBackup_Disabled(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	if rec := auditDo(t, newVersionedTestHandler(t, s), "GET", StateBackupPath, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected no backup route without an archiver, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// UsagePath serves player-hours and sessions per server and category from the server history
+// Only registered when a UsageProvider is set (HISTORY_ENABLED=true)
+const UsagePath = "/api/v1/usage"
+
+const (
+	// usageDateFormat is the ?from= and ?to= parameters
+	usageDateFormat = "2006-01-02"
+	// defaultUsageDays is the range without ?from=
+	defaultUsageDays = 30
+)
+
+// UsageTotal is the usage of one server or category
+type UsageTotal struct {
+	Name        string  `json:"name"`
+	PlayerHours float64 `json:"player_hours"`
+	Sessions    int     `json:"sessions"`
+}
+
+// Usage sums the player-hours and sessions in [From, To)
+type Usage struct {
+	From        time.Time    `json:"from"`
+	To          time.Time    `json:"to"`
+	PlayerHours float64      `json:"player_hours"`
+	Sessions    int          `json:"sessions"`
+	Servers     []UsageTotal `json:"servers"`
+	Categories  []UsageTotal `json:"categories"`
+}
+
+// UsageProvider sums the server history in [from, to)
+type UsageProvider interface {
+	Usage(from, to time.Time) *Usage
+}
+
+// SetUsageProvider enables the usage endpoint
+// Must be called before Start
+func (s *Server) SetUsageProvider(p UsageProvider) {
+	s.usage = p
+}
+
+// GetUsage returns the usage from ?from= to ?to= (YYYY-MM-DD, UTC, to exclusive)
+// Without parameters it covers the last 30 days including today
+// Requires Bearer token authentication
+func (s *Server) GetUsage(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("GetUsage cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.Parse(usageDateFormat, v)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid to", "to must be YYYY-MM-DD")
+			return
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -defaultUsageDays)
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse(usageDateFormat, v)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid from", "from must be YYYY-MM-DD")
+			return
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		WriteError(w, http.StatusBadRequest, "Invalid range", "from must be before to")
+		return
+	}
+	WriteJSON(w, http.StatusOK, s.usage.Usage(from, to))
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+type mockUsageProvider struct {
+	from, to time.Time
+}
+
+func (m *mockUsageProvider) Usage(from, to time.Time) *Usage {
+	m.from, m.to = from, to
+	return &Usage{From: from, To: to, Servers: []UsageTotal{}, Categories: []UsageTotal{}}
+}
+
+func TestGetUsage(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	provider := &mockUsageProvider{}
+	s.SetUsageProvider(provider)
+	handler := newVersionedTestHandler(t, s)
+
+	rec := auditDo(t, handler, "GET", UsagePath+"?from=2026-09-01&to=2026-10-01", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if !provider.from.Equal(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)) || !provider.to.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected range %v - %v", provider.from, provider.to)
+	}
+
+	if rec := auditDo(t, handler, "GET", UsagePath, ""); rec.Code != http.StatusOK || provider.to.Sub(provider.from) != defaultUsageDays*24*time.Hour || provider.to.Before(time.Now()) {
+		t.Errorf("Expected the last 30 days by default, got %d for %v - %v", rec.Code, provider.from, provider.to)
+	}
+
+	for _, query := range []string{"?from=yesterday", "?to=2026-13-01", "?from=2026-10-01&to=2026-10-01"} {
+		if rec := auditDo(t, handler, "GET", UsagePath+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
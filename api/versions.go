@@ -0,0 +1,207 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/requestid"
+)
+
+// APIVersionsPath serves the version negotiation document (no auth)
+const APIVersionsPath = "/api/versions"
+
+// apiV2Prefix is the base path of API v2
+const apiV2Prefix = "/api/v2"
+
+// v1DeprecatedAt is when v2 was introduced and v1 (/api/... and /api/v1/...) became deprecated
+var v1DeprecatedAt = time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+// SetV1Sunset announces the date v1 routes will be removed (Sunset header and /api/versions)
+// Zero (the default) means no removal is scheduled. Must be called before Start
+func (s *Server) SetV1Sunset(t time.Time) {
+	s.v1Sunset = t
+}
+
+// APIVersion describes one API version in the negotiation document
+type APIVersion struct {
+	Version      string     `json:"version"`
+	Status       string     `json:"status"` // "current" or "deprecated"
+	Paths        []string   `json:"paths"`
+	DeprecatedAt *time.Time `json:"deprecated_at,omitempty"`
+	Sunset       *time.Time `json:"sunset,omitempty"`
+	Schema       string     `json:"schema"`
+}
+
+// APIVersions is the version negotiation document served at APIVersionsPath
+type APIVersions struct {
+	Current  string       `json:"current"`
+	Versions []APIVersion `json:"versions"`
+}
+
+// Versions returns the version negotiation document
+// Clients pick the newest version they understand; GUI builds pinned to v1 keep working until the sunset
+func (s *Server) Versions(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("Versions cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	deprecated := v1DeprecatedAt
+	v1 := APIVersion{
+		Version:      "v1",
+		Status:       "deprecated",
+		Paths:        []string{"/api/", "/api/v1/"},
+		DeprecatedAt: &deprecated,
+		Schema:       "Bare JSON bodies; errors as {\"error\", \"details\"}",
+	}
+	if !s.v1Sunset.IsZero() {
+		sunset := s.v1Sunset
+		v1.Sunset = &sunset
+	}
+	WriteJSON(w, http.StatusOK, APIVersions{
+		Current: "v2",
+		Versions: []APIVersion{v1, {
+			Version: "v2",
+			Status:  "current",
+			Paths:   []string{apiV2Prefix + "/"},
+			Schema:  "Bodies wrapped as {\"data\", \"meta\"}; errors as {\"error\": {\"status\", \"message\", \"details\"}}",
+		}},
+	})
+}
+
+// isV1Path reports whether path is a v1 API route (everything under /api/ except v2 and the versions document)
+func isV1Path(path string) bool {
+	return strings.HasPrefix(path, "/api/") && path != APIVersionsPath && path != "/api/csrf-token" &&
+		!strings.HasPrefix(path, apiV2Prefix+"/")
+}
+
+// DeprecateV1 adds Deprecation (RFC 9745), Sunset (RFC 8594, when scheduled) and Link headers to v1 responses
+// The successor-version link points at the same route under /api/v2
+func DeprecateV1(sunset time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isV1Path(r.URL.Path) {
+				h := w.Header()
+				h.Set("Deprecation", "@"+strconv.FormatInt(v1DeprecatedAt.Unix(), 10))
+				if !sunset.IsZero() {
+					h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+				}
+				h.Add("Link", `<`+v2Path(r.URL.Path)+`>; rel="successor-version"`)
+				h.Add("Link", `<`+APIVersionsPath+`>; rel="deprecation"; type="application/json"`)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// v2Path returns the v2 path of a v1 route: /api/config and /api/v1/config both become /api/v2/config
+func v2Path(path string) string {
+	suffix := strings.TrimPrefix(path, "/api")
+	suffix = strings.TrimPrefix(suffix, "/v1")
+	return apiV2Prefix + suffix
+}
+
+// v1Paths returns the candidate v1 routes for a v2 path, versioned first (/api/v2/x -> /api/v1/x, /api/x)
+func v1Paths(path string) []string {
+	suffix := strings.TrimPrefix(path, apiV2Prefix)
+	return []string{"/api/v1" + suffix, "/api" + suffix}
+}
+
+// v2Handler serves /api/v2/... by running the matching v1 route and converting its response to the v2 schema
+// JSON success bodies become {"data": body, "meta": {...}}, JSON errors become {"error": {status, message, details}};
+// other content (CSV, PNG) and 304 responses pass through unchanged
+func (s *Server) v2Handler(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var v1 *http.Request
+		for _, candidate := range v1Paths(r.URL.Path) {
+			req := r.Clone(r.Context())
+			req.URL.Path, req.URL.RawPath = candidate, ""
+			if _, pattern := mux.Handler(req); pattern != "" && !strings.HasPrefix(pattern, apiV2Prefix+"/") {
+				v1 = req
+				break
+			}
+		}
+		if v1 == nil {
+			writeV2Error(w, http.StatusNotFound, "Not found", "no such v2 route: "+r.URL.Path)
+			return
+		}
+
+		rec := &v2Recorder{header: http.Header{}, status: http.StatusOK}
+		mux.ServeHTTP(rec, v1)
+
+		for key, values := range rec.header {
+			if key != "Content-Length" {
+				w.Header()[key] = values
+			}
+		}
+		isJSON := strings.HasPrefix(rec.header.Get("Content-Type"), "application/json")
+		if !isJSON || rec.body.Len() == 0 {
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+			return
+		}
+
+		if rec.status >= 400 {
+			var e ErrorResponse
+			json.Unmarshal(rec.body.Bytes(), &e)
+			writeV2Error(w, rec.status, e.Error, e.Details)
+			return
+		}
+		meta := map[string]any{"api_version": "v2"}
+		if total := rec.header.Get("X-Total-Count"); total != "" {
+			if n, err := strconv.Atoi(total); err == nil {
+				meta["total"] = n
+			}
+		}
+		if n := rec.header.Get("X-Config-Warnings"); n != "" {
+			if warnings, err := strconv.Atoi(n); err == nil {
+				meta["warnings"] = warnings
+			}
+		}
+		if id := rec.header.Get(AuditIDHeader); id != "" {
+			meta["audit_id"] = id
+		}
+		WriteJSON(w, rec.status, map[string]any{
+			"data": json.RawMessage(bytes.TrimSpace(rec.body.Bytes())),
+			"meta": meta,
+		})
+	})
+}
+
+// writeV2Error writes an error in the v2 schema
+func writeV2Error(w http.ResponseWriter, status int, message, details string) {
+	body := map[string]any{"status": status, "message": message}
+	if details != "" {
+		body["details"] = details
+	}
+	if id := w.Header().Get(requestid.Header); id != "" {
+		body["request_id"] = id
+	}
+	WriteJSON(w, status, map[string]any{"error": body})
+}
+
+// v2Recorder buffers a v1 response so v2Handler can convert it
+type v2Recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func (r *v2Recorder) Header() http.Header { return r.header }
+
+func (r *v2Recorder) WriteHeader(status int) {
+	if !r.wrote {
+		r.status, r.wrote = status, true
+	}
+}
+
+func (r *v2Recorder) Write(p []byte) (int, error) {
+	r.wrote = true
+	return r.body.Write(p)
+}
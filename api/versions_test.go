@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newVersionedTestHandler builds the public test chain plus DeprecateV1, as Start does
+func newVersionedTestHandler(t *testing.T, s *Server) http.Handler {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	mux := http.NewServeMux()
+	RegisterRoutes(mux, s)
+
+	var handler http.Handler = mux
+	handler = DeprecateV1(s.v1Sunset)(handler)
+	handler = BearerAuth("valid-token", []string{})(handler)
+	handler = RateLimit(100, 100, []string{}, ctx)(handler)
+	return handler
+}
+
+func authedRequest(method, path string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	return req
+}
+
+func TestVersions_Document(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetV1Sunset(time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC))
+	handler := newVersionedTestHandler(t, s)
+
+	// No auth required
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", APIVersionsPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	var doc APIVersions
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if doc.Current != "v2" || len(doc.Versions) != 2 || doc.Versions[0].Status != "deprecated" || doc.Versions[0].Sunset == nil {
+		t.Errorf("Unexpected document %+v", doc)
+	}
+	if rec.Header().Get("Deprecation") != "" {
+		t.Error("The versions document must not be marked deprecated")
+	}
+}
+
+func TestDeprecateV1_Headers(t *testing.T) {
+	s := NewServer(&mockConfigManager{config: map[string]any{"server_ip": "1.2.3.4"}}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newVersionedTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", "/api/config"))
+	if !strings.HasPrefix(rec.Header().Get("Deprecation"), "@") {
+		t.Errorf("Expected Deprecation header, got %q", rec.Header().Get("Deprecation"))
+	}
+	if rec.Header().Get("Sunset") != "" {
+		t.Error("Expected no Sunset header without a scheduled removal")
+	}
+	if links := strings.Join(rec.Header().Values("Link"), ", "); !strings.Contains(links, `</api/v2/config>; rel="successor-version"`) {
+		t.Errorf("Expected successor-version link, got %q", links)
+	}
+
+	s.SetV1Sunset(time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC))
+	handler = newVersionedTestHandler(t, s)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", "/api/config"))
+	if got := rec.Header().Get("Sunset"); got != "Wed, 30 Jun 2027 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", "/api/v2/config"))
+	if rec.Header().Get("Deprecation") != "" || rec.Header().Get("Sunset") != "" {
+		t.Error("v2 responses must not carry deprecation headers")
+	}
+}
+
+func TestV2_Envelope(t *testing.T) {
+	cm := &mockConfigManagerWithWrites{config: map[string]interface{}{
+		"server_ip": "1.2.3.4",
+		"servers":   []map[string]interface{}{{"name": "a", "category": "Drift"}, {"name": "b", "category": "Track"}},
+	}}
+	s := NewServer(cm, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	handler := newVersionedTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", "/api/v2/config"))
+	var body struct {
+		Data map[string]any `json:"data"`
+		Meta map[string]any `json:"meta"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Status %d, decode error %v", rec.Code, err)
+	}
+	if body.Data["server_ip"] != "1.2.3.4" || body.Meta["api_version"] != "v2" {
+		t.Errorf("Unexpected v2 body %+v", body)
+	}
+	if rec.Header().Get("ETag") == "" {
+		t.Error("Expected v1 headers such as ETag to carry over")
+	}
+
+	// List totals move into meta
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", "/api/v2/config/servers?limit=1"))
+	var list struct {
+		Data []any          `json:"data"`
+		Meta map[string]any `json:"meta"`
+	}
+	json.NewDecoder(rec.Body).Decode(&list)
+	if len(list.Data) != 1 || list.Meta["total"] != float64(2) {
+		t.Errorf("Unexpected v2 list %+v", list)
+	}
+
+	// Errors use the v2 error object
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", "/api/v2/config/servers?sort=bogus"))
+	var errBody struct {
+		Error struct {
+			Status  int    `json:"status"`
+			Message string `json:"message"`
+			Details string `json:"details"`
+		} `json:"error"`
+	}
+	json.NewDecoder(rec.Body).Decode(&errBody)
+	if rec.Code != http.StatusBadRequest || errBody.Error.Status != 400 || errBody.Error.Message != "Invalid query" || errBody.Error.Details == "" {
+		t.Errorf("Unexpected v2 error %d %+v", rec.Code, errBody)
+	}
+
+	// Unknown v2 routes
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, authedRequest("GET", "/api/v2/nope"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want 404", rec.Code)
+	}
+}
+
+func TestV2_PublicRoutes(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	s.SetStatusProvider(&mockStatusProvider{status: map[string]any{"total_players": 7}})
+	handler := newVersionedTestHandler(t, s)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v2/public/status", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"data":{"total_players":7}`) {
+		t.Errorf("Expected public v2 status without auth, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	// Versioned v1 routes map too (/api/v1/servers/test -> /api/v2/servers/test)
+	if paths := v1Paths("/api/v2/servers/test"); paths[0] != "/api/v1/servers/test" || paths[1] != "/api/servers/test" {
+		t.Errorf("Unexpected candidates %v", paths)
+	}
+	if got := v2Path("/api/v1/preview/embed"); got != "/api/v2/preview/embed" {
+		t.Errorf("v2Path = %q", got)
+	}
+}
@@ -34,6 +34,16 @@ const APIClient = {
         return headers;
     },
 
+    // Non-fatal lint warnings returned in the body of a config write ([] for other responses)
+    lintWarnings(data) {
+        return Array.isArray(data?.warnings) ? data.warnings : [];
+    },
+
+    // Audit entry ID of a config write, for undo via POST /v1/audit/{id}/revert (null when auditing is off)
+    auditId(response) {
+        return response.headers.get('X-Audit-ID');
+    },
+
     // Parse API error responses
     async parseError(response) {
         try {
@@ -78,12 +88,21 @@ const APIClient = {
             return { ok: false, status: 429, error: 'Rate limit exceeded. Please wait.' };
         }
 
+        // Handle 503 from the proxy while the bot API is down (reason: upstream_unavailable)
+        if (response.status === 503) {
+            const data = await response.clone().json().catch(() => ({}));
+            if (data.reason === 'upstream_unavailable') {
+                const retry = data.retry_after_seconds || 5;
+                return { ok: false, status: 503, reason: data.reason, error: `Bot API unavailable since ${new Date(data.since).toLocaleTimeString()} (${data.details}). Retry in ${retry} seconds.` };
+            }
+        }
+
         // Parse successful responses
         if (response.ok) {
             const text = await response.text();
             try {
                 const data = text ? JSON.parse(text) : null;
-                return { ok: true, status: response.status, data };
+                return { ok: true, status: response.status, data, warnings: this.lintWarnings(data), auditId: this.auditId(response) };
             } catch {
                 return { ok: false, status: response.status, error: 'Invalid JSON response from server' };
             }
@@ -101,12 +120,22 @@ const APIClient = {
     delete(path) { return this.request('DELETE', path); },
 
     // Download config as file
-    async downloadConfig() {
+    downloadConfig() {
+        return this.downloadFile('/config/download', 'config.json');
+    },
+
+    // Download servers as CSV for editing in a spreadsheet
+    downloadServersCSV() {
+        return this.downloadFile('/config/servers/csv', 'servers.csv');
+    },
+
+    // Download a file from an authenticated endpoint and save it in the browser
+    async downloadFile(path, defaultFilename) {
         const headers = this.buildHeaders(false);
 
         let response;
         try {
-            response = await fetch(`${this.baseURL}/config/download`, { headers });
+            response = await fetch(`${this.baseURL}${path}`, { headers });
         } catch (networkError) {
             return { ok: false, status: 0, error: 'Network error: unable to reach server' };
         }
@@ -117,7 +146,7 @@ const APIClient = {
 
         // Get filename from Content-Disposition header
         const disposition = response.headers.get('Content-Disposition');
-        let filename = 'config.json';
+        let filename = defaultFilename;
         if (disposition) {
             const match = disposition.match(/filename="(.+)"/);
             if (match) filename = match[1];
@@ -137,6 +166,83 @@ const APIClient = {
         return { ok: true, status: response.status };
     },
 
+    // Import servers from a CSV file (dryRun validates and reports without saving)
+    // A 422 response carries the validation report, so it is returned as data
+    async importServersCSV(file, dryRun) {
+        const headers = this.buildHeaders(true);
+        headers['Content-Type'] = 'text/csv';
+
+        let response;
+        try {
+            response = await fetch(`${this.baseURL}/config/servers/csv?dry_run=${dryRun}`, {
+                method: 'POST',
+                headers,
+                body: file
+            });
+        } catch (networkError) {
+            return { ok: false, status: 0, error: 'Network error: unable to reach server' };
+        }
+
+        if (response.status === 401) {
+            window.Auth?.logout();
+            return { ok: false, status: 401, error: 'Authentication required' };
+        }
+
+        if (response.ok || response.status === 422) {
+            const data = await response.json();
+            return { ok: response.ok, status: response.status, data, auditId: this.auditId(response) };
+        }
+
+        return { ok: false, status: response.status, error: await this.parseError(response) };
+    },
+
+    // Follow the live log tail (server-sent events read via fetch, which can send the Authorization header)
+    // Calls onLine with each {seq, line}; resolves when the stream ends or signal aborts it
+    async streamLogs(onLine, signal) {
+        let response;
+        try {
+            response = await fetch(`${this.baseURL}/v1/logs/stream`, { headers: this.buildHeaders(false), signal });
+        } catch (networkError) {
+            if (signal?.aborted) return { ok: true, status: 0 };
+            return { ok: false, status: 0, error: 'Network error: unable to reach server' };
+        }
+
+        if (response.status === 401) {
+            window.Auth?.logout();
+            return { ok: false, status: 401, error: 'Authentication required' };
+        }
+        if (!response.ok) {
+            return { ok: false, status: response.status, error: await this.parseError(response) };
+        }
+
+        const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+        let buffer = '';
+        try {
+            for (;;) {
+                const { value, done } = await reader.read();
+                if (done) break;
+                buffer += value;
+                // Events are separated by a blank line; keep a partial event for the next chunk
+                const events = buffer.split('\n\n');
+                buffer = events.pop();
+                for (const event of events) {
+                    let seq = null;
+                    const data = [];
+                    for (const line of event.split('\n')) {
+                        if (line.startsWith('id: ')) seq = parseInt(line.slice(4), 10);
+                        else if (line.startsWith('data: ')) data.push(line.slice(6));
+                    }
+                    if (data.length > 0) onLine({ seq, line: data.join('\n') });
+                }
+            }
+        } catch (streamError) {
+            if (!signal?.aborted) {
+                return { ok: false, status: response.status, error: 'Log stream interrupted' };
+            }
+        }
+        return { ok: true, status: response.status };
+    },
+
     // Upload config file
     async uploadConfig(file) {
         const formData = new FormData();
@@ -170,7 +276,7 @@ const APIClient = {
 
         if (response.ok) {
             const data = await response.json();
-            return { ok: true, status: response.status, data };
+            return { ok: true, status: response.status, data, warnings: this.lintWarnings(data), auditId: this.auditId(response) };
         }
 
         return { ok: false, status: response.status, error: await this.parseError(response) };
@@ -7,6 +7,14 @@
 const App = {
     config: null,
     servers: [],
+    // Audit ID of the last change made in this session (undo target)
+    lastAuditId: null,
+    // Aborts the live log tail (null when not following)
+    logStream: null,
+    // Sequence number of the last log line shown (lines up to it are skipped when following)
+    lastLogSeq: 0,
+    // Maximum number of lines kept in the log view while following
+    maxLogLines: 1000,
 
     // Initialize app on page load
     init() {
@@ -52,6 +60,11 @@ const App = {
             this.saveConfig();
         });
 
+        // Undo button (shown after a change when the API audit log is enabled)
+        document.getElementById('undo-btn').addEventListener('click', () => {
+            this.undoLastChange();
+        });
+
         // Download button
         document.getElementById('download-btn').addEventListener('click', () => {
             this.handleDownload();
@@ -66,6 +79,30 @@ const App = {
         document.getElementById('file-input').addEventListener('change', (e) => {
             this.handleFileSelect(e);
         });
+
+        // Servers CSV export/import
+        document.getElementById('export-csv-btn').addEventListener('click', () => {
+            this.handleExportCSV();
+        });
+        document.getElementById('import-csv-btn').addEventListener('click', () => {
+            document.getElementById('csv-file-input').click();
+        });
+        document.getElementById('csv-file-input').addEventListener('change', (e) => {
+            this.handleImportCSV(e);
+        });
+
+        // Embed preview (saved config + latest poll)
+        document.getElementById('preview-embed-btn').addEventListener('click', () => {
+            this.previewEmbed();
+        });
+
+        // Recent logs and live tail
+        document.getElementById('logs-btn').addEventListener('click', () => {
+            this.showLogs();
+        });
+        document.getElementById('logs-follow-btn').addEventListener('click', () => {
+            this.toggleLogFollow();
+        });
     },
 
     // Check auth state and show appropriate screen
@@ -109,6 +146,7 @@ const App = {
 
     // Handle logout
     handleLogout() {
+        this.stopLogFollow();
         window.Auth.logout();
         this.showLoggedOutScreen();
     },
@@ -143,11 +181,112 @@ const App = {
             this.config = response.data;
             this.servers = response.data.servers || [];
             this.renderConfig();
+            await this.loadPasswords();
+            await this.loadHeatmap();
         } else {
             this.showMessage('Failed to load config: ' + response.error, 'error');
         }
     },
 
+    // Show the server passwords with a Rotate button each; the section stays hidden when none are configured
+    async loadPasswords() {
+        const response = await window.APIClient.get('/v1/passwords');
+        const passwords = response.ok ? response.data.passwords : [];
+        const list = document.getElementById('passwords-list');
+        list.innerHTML = '';
+        passwords.forEach(p => list.appendChild(this.createPasswordElement(p)));
+        document.getElementById('passwords-section').classList.toggle('hidden', passwords.length === 0);
+    },
+
+    // Create one password row: server, masked password (click to reveal), roles, last rotation
+    createPasswordElement(p) {
+        const row = document.createElement('div');
+        row.className = 'form-group';
+
+        // textContent keeps server names and passwords from being interpreted as HTML
+        const label = document.createElement('label');
+        label.textContent = p.server;
+
+        const value = document.createElement('code');
+        value.textContent = '••••••••';
+        value.title = 'Click to reveal';
+        value.addEventListener('click', () => {
+            value.textContent = value.textContent === p.password ? '••••••••' : p.password;
+        });
+
+        const info = document.createElement('span');
+        info.textContent = ` roles ${p.roles.join(', ')}` +
+            (p.rotated_at ? `, rotated ${new Date(p.rotated_at).toLocaleString()}` : '');
+
+        const rotateBtn = document.createElement('button');
+        rotateBtn.type = 'button';
+        rotateBtn.textContent = 'Rotate';
+        rotateBtn.addEventListener('click', () => this.rotatePassword(p.server));
+
+        row.append(label, value, info, rotateBtn);
+        return row;
+    },
+
+    // Replace a server password with a generated one; the game server needs its config deployed to pick it up
+    async rotatePassword(server) {
+        if (!confirm(`Generate a new password for ${server}? Members have to request it again with /password.`)) return;
+        const response = await window.APIClient.post('/v1/passwords/' + encodeURIComponent(server) + '/rotate');
+        if (!response.ok) {
+            this.showMessage('Rotation failed: ' + response.error, 'error');
+            return;
+        }
+        this.setUndo(response.auditId);
+        this.showMessage(`Password of ${server} rotated; deploy its server config (or update server_cfg.ini) to apply it`, 'success');
+        await this.loadPasswords();
+    },
+
+    // Show the average players per weekday and hour in the browser's time zone; the section stays hidden
+    // when the history is disabled (no heatmap endpoint)
+    async loadHeatmap() {
+        const select = document.getElementById('heatmap-server');
+        const selected = select.value;
+        select.innerHTML = '';
+        select.appendChild(new Option('All servers', ''));
+        this.servers.forEach(server => select.appendChild(new Option(server.name, server.name)));
+        select.value = this.servers.some(server => server.name === selected) ? selected : '';
+        select.onchange = () => this.loadHeatmap();
+
+        const tz = Intl.DateTimeFormat().resolvedOptions().timeZone;
+        const response = await window.APIClient.get('/v1/heatmap?server=' + encodeURIComponent(select.value) +
+            '&tz=' + encodeURIComponent(tz));
+        document.getElementById('heatmap-section').classList.toggle('hidden', !response.ok);
+        if (response.ok) {
+            this.renderHeatmap(response.data);
+        }
+    },
+
+    // Render the heatmap as a weekday × hour table, shading each cell by its share of the busiest hour
+    renderHeatmap(heatmap) {
+        const table = document.getElementById('heatmap');
+        table.innerHTML = '';
+        const max = Math.max(1, ...heatmap.players.flat().filter(p => p !== null));
+
+        const header = table.insertRow();
+        header.insertCell();
+        for (let hour = 0; hour < 24; hour++) {
+            header.insertCell().textContent = String(hour).padStart(2, '0');
+        }
+        ['Mon', 'Tue', 'Wed', 'Thu', 'Fri', 'Sat', 'Sun'].forEach((day, d) => {
+            const row = table.insertRow();
+            row.insertCell().textContent = day;
+            heatmap.players[d].forEach((players, hour) => {
+                const cell = row.insertCell();
+                if (players === null) {
+                    cell.title = 'No data';
+                    return;
+                }
+                cell.textContent = players >= 1 ? Math.round(players) : '';
+                cell.title = `${day} ${String(hour).padStart(2, '0')}:00 — ${players} players on average`;
+                cell.style.backgroundColor = `rgba(88, 101, 242, ${(players / max).toFixed(2)})`;
+            });
+        });
+    },
+
     // Render config to UI
     // Populates server list and settings fields: server_ip, update_interval,
     // category_order, category_emojis (ref: DL-002).
@@ -215,6 +354,11 @@ const App = {
         emojiInput.placeholder = 'Emoji';
         emojiInput.value = emoji;
 
+        const entriesBtn = document.createElement('button');
+        entriesBtn.type = 'button';
+        entriesBtn.className = 'test-server-btn';
+        entriesBtn.textContent = 'Cars';
+
         const deleteBtn = document.createElement('button');
         deleteBtn.type = 'button';
         deleteBtn.className = 'delete-emoji-btn';
@@ -269,6 +413,11 @@ const App = {
         categoryGroup.appendChild(categoryLabel);
         categoryGroup.appendChild(categorySelect);
 
+        const testBtn = document.createElement('button');
+        testBtn.type = 'button';
+        testBtn.className = 'test-server-btn';
+        testBtn.textContent = 'Test';
+
         const deleteBtn = document.createElement('button');
         deleteBtn.type = 'button';
         deleteBtn.className = 'delete-server-btn';
@@ -277,8 +426,20 @@ const App = {
         div.appendChild(nameGroup);
         div.appendChild(portGroup);
         div.appendChild(categoryGroup);
+        div.appendChild(testBtn);
+        div.appendChild(entriesBtn);
         div.appendChild(deleteBtn);
 
+        // Bind test handler
+        testBtn.addEventListener('click', () => {
+            this.testServer(index, testBtn);
+        });
+
+        // Bind entry list handler
+        entriesBtn.addEventListener('click', () => {
+            this.showEntryList(index);
+        });
+
         // Bind delete handler
         deleteBtn.addEventListener('click', () => {
             this.deleteServer(index);
@@ -320,6 +481,30 @@ const App = {
         this.renderConfig();
     },
 
+    // Poll a server entry live without saving, using the server IP currently in the form
+    async testServer(index, button) {
+        const server = this.servers[index];
+        if (!server) return;
+        const payload = {
+            ...server,
+            ip: document.getElementById('server-ip-input').value.trim()
+        };
+
+        button.disabled = true;
+        const response = await window.APIClient.post('/v1/servers/test', payload);
+        button.disabled = false;
+
+        const name = server.name || `Server ${index + 1}`;
+        if (!response.ok) {
+            this.showMessage(`Test failed for ${name}: ${response.error}`, 'error');
+        } else if (response.data.reachable) {
+            const r = response.data;
+            this.showMessage(`${name} is online: ${r.map}, ${r.players}/${r.max_players} players (${r.latency_ms} ms)`, 'success');
+        } else {
+            this.showMessage(`${name} is not reachable: ${response.data.error}`, 'error');
+        }
+    },
+
     // Delete server
     deleteServer(index) {
         this.servers.splice(index, 1);
@@ -352,12 +537,39 @@ const App = {
         const response = await window.APIClient.put('/config', this.buildConfigPayload());
         if (response.ok) {
             this.showMessage('Configuration saved', 'success');
+            this.setUndo(response.auditId);
             await this.loadConfig(); // Refresh from server
+            await this.showLintWarnings(response.warnings);
         } else {
             this.showMessage('Failed to save: ' + response.error, 'error');
         }
     },
 
+    // Remember the change to undo; the button stays hidden without an audit ID (audit log disabled)
+    setUndo(auditId) {
+        this.lastAuditId = auditId || null;
+        document.getElementById('undo-btn').classList.toggle('hidden', !this.lastAuditId);
+    },
+
+    // Revert the last change; if the same settings were changed again since, ask before overwriting
+    async undoLastChange() {
+        if (!this.lastAuditId) return;
+        const path = `/v1/audit/${encodeURIComponent(this.lastAuditId)}/revert`;
+        let response = await window.APIClient.post(path);
+        if (response.status === 409 && response.error.startsWith('Config changed since')
+            && confirm(`${response.error}\n\nUndo anyway?`)) {
+            response = await window.APIClient.post(`${path}?force=true`);
+        }
+        if (!response.ok) {
+            this.showMessage('Undo failed: ' + response.error, 'error');
+            return;
+        }
+        this.showMessage('Last change undone', 'success');
+        this.setUndo(null);
+        await this.loadConfig(); // Refresh from server
+        await this.showLintWarnings(response.warnings);
+    },
+
     // Handle download button click
     async handleDownload() {
         const response = await window.APIClient.downloadConfig();
@@ -383,7 +595,9 @@ const App = {
         const response = await window.APIClient.uploadConfig(file);
         if (response.ok) {
             this.showMessage('Config uploaded successfully', 'success');
+            this.setUndo(response.auditId);
             await this.loadConfig(); // Refresh from server
+            await this.showLintWarnings(response.warnings);
         } else {
             this.showMessage('Upload failed: ' + response.error, 'error');
         }
@@ -391,6 +605,135 @@ const App = {
         e.target.value = ''; // Reset file input
     },
 
+    // Show the embed the bot would currently send; reflects the saved config, so save first
+    async previewEmbed() {
+        const response = await window.APIClient.get('/v1/preview/embed');
+        if (!response.ok) {
+            this.showMessage('Preview failed: ' + response.error, 'error');
+            return;
+        }
+        // textContent keeps server names and maps from being interpreted as HTML
+        document.getElementById('embed-preview').textContent = response.data.markdown;
+        document.getElementById('embed-preview-section').classList.remove('hidden');
+    },
+
+    // Show the entry list and car classes of a saved server from the last poll (ENTRY_LIST_ENABLED)
+    async showEntryList(index) {
+        const server = this.servers[index];
+        if (!server || !server.name) return;
+        const response = await window.APIClient.get('/v1/servers/' + encodeURIComponent(server.name) + '/entries');
+        if (!response.ok) {
+            this.showMessage(`No entry list for ${server.name}: ${response.error}`, 'error');
+            return;
+        }
+        const list = response.data;
+        const lines = [
+            list.classes.map(c => `${c.class} x${c.count}`).join(', '),
+            '',
+            ...list.cars.map(c => `${c.connected ? '●' : '○'} ${c.class.padEnd(8)} ${c.model}${c.driver ? ' — ' + c.driver : ''}${c.team ? ' (' + c.team + ')' : ''}`)
+        ];
+        // textContent keeps driver and team names from being interpreted as HTML
+        document.getElementById('entry-list-title').textContent = `Entry List: ${list.server}`;
+        document.getElementById('entry-list').textContent = lines.join('\n');
+        document.getElementById('entry-list-section').classList.remove('hidden');
+    },
+
+    // Show the most recent log lines
+    async showLogs() {
+        const response = await window.APIClient.get('/v1/logs?tail=200');
+        if (!response.ok) {
+            this.showMessage('Loading logs failed: ' + response.error, 'error');
+            return;
+        }
+        // textContent: log lines contain server names and error messages
+        const view = document.getElementById('log-view');
+        view.textContent = response.data.lines.map(l => l.line).join('\n');
+        this.lastLogSeq = response.data.lines.length > 0 ? response.data.lines[response.data.lines.length - 1].seq : 0;
+        document.getElementById('logs-section').classList.remove('hidden');
+        view.scrollTop = view.scrollHeight;
+    },
+
+    // Start or stop following new log lines
+    async toggleLogFollow() {
+        if (this.logStream) {
+            this.stopLogFollow();
+            return;
+        }
+        const button = document.getElementById('logs-follow-btn');
+        const view = document.getElementById('log-view');
+        this.logStream = new AbortController();
+        button.textContent = 'Stop Following';
+
+        const result = await window.APIClient.streamLogs(({ seq, line }) => {
+            if (seq !== null && seq <= this.lastLogSeq) return; // already shown
+            this.lastLogSeq = seq ?? this.lastLogSeq;
+            view.textContent += (view.textContent ? '\n' : '') + line;
+            const lines = view.textContent.split('\n');
+            if (lines.length > this.maxLogLines) {
+                view.textContent = lines.slice(-this.maxLogLines).join('\n');
+            }
+            view.scrollTop = view.scrollHeight;
+        }, this.logStream.signal);
+
+        this.logStream = null;
+        button.textContent = 'Follow';
+        if (!result.ok) {
+            this.showMessage('Log stream ended: ' + result.error, 'error');
+        }
+    },
+
+    // Stop following the log (no-op when not following)
+    stopLogFollow() {
+        if (this.logStream) {
+            this.logStream.abort();
+        }
+    },
+
+    // Handle servers CSV export
+    async handleExportCSV() {
+        const response = await window.APIClient.downloadServersCSV();
+        if (response.ok) {
+            this.showMessage('Servers exported', 'success');
+        } else {
+            this.showMessage('Export failed: ' + response.error, 'error');
+        }
+    },
+
+    // Handle servers CSV import: dry run first, then apply after confirmation
+    async handleImportCSV(e) {
+        const file = e.target.files[0];
+        e.target.value = ''; // Reset file input
+        if (!file) return;
+
+        const preview = await window.APIClient.importServersCSV(file, true);
+        if (preview.status === 422) {
+            const lines = preview.data.errors.map(err => err.row ? `• Row ${err.row}: ${err.message}` : `• ${err.message}`);
+            this.showMessage('Import rejected, nothing was saved:\n' + lines.join('\n'), 'error');
+            return;
+        }
+        if (!preview.ok) {
+            this.showMessage('Import failed: ' + preview.error, 'error');
+            return;
+        }
+
+        const r = preview.data;
+        const summary = `Replace servers with ${r.servers} from ${file.name}?\n\n` +
+            `Added: ${r.added.length ? r.added.join(', ') : 'none'}\n` +
+            `Updated: ${r.updated.length ? r.updated.join(', ') : 'none'}\n` +
+            `Removed: ${r.removed.length ? r.removed.join(', ') : 'none'}`;
+        if (!confirm(summary)) return;
+
+        const response = await window.APIClient.importServersCSV(file, false);
+        if (!response.ok) {
+            this.showMessage('Import failed: ' + (response.error || 'validation failed'), 'error');
+            return;
+        }
+        this.showMessage(`Imported ${response.data.servers} servers`, 'success');
+        this.setUndo(response.auditId);
+        await this.loadConfig(); // Refresh from server
+        await this.showLintWarnings(response.data.warnings);
+    },
+
     // Collect form changes into config object
     // Gathers all config fields: server_ip, update_interval, category_order,
     // category_emojis, and servers array (ref: DL-002).
@@ -433,6 +776,13 @@ const App = {
     },
 
     // Show status message
+    // Display non-fatal lint warnings returned by a successful save (config is already applied)
+    async showLintWarnings(warnings) {
+        if (!warnings?.length) return;
+        const lines = warnings.map(w => `• ${w.field}: ${w.message}`);
+        this.showMessage('Saved with warnings:\n' + lines.join('\n'), 'warning');
+    },
+
     showMessage(text, type) {
         const el = document.getElementById('status-message');
         el.textContent = text;
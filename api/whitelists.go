@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// WhitelistsPath lists the reserved slots of all servers; WhitelistsPath/{server} reads (GET) or replaces (PUT) one
+const WhitelistsPath = "/api/v1/whitelists"
+
+// WhitelistEntry is one reserved slot
+type WhitelistEntry struct {
+	GUID string `json:"guid"` // SteamID64
+	Name string `json:"name,omitempty"`
+}
+
+// Whitelist is the reserved slots of one server
+type Whitelist struct {
+	Server    string           `json:"server"`
+	EntryList string           `json:"entry_list,omitempty"` // entry_list.ini written on this host (set in config.json)
+	Drivers   []WhitelistEntry `json:"drivers"`
+}
+
+// WhitelistRequest is the body of PUT WhitelistsPath/{server}
+type WhitelistRequest struct {
+	Drivers []WhitelistEntry `json:"drivers"`
+}
+
+// WhitelistEditor reads and replaces the per-server reserved slots saved in the config
+type WhitelistEditor interface {
+	Whitelists() map[string]Whitelist
+	// SetWhitelist saves the reserved drivers of server and writes its entry list; ErrUnknownServer for an
+	// unknown server, other errors are validation or write failures (ErrConfigReadOnly for a read-only config)
+	SetWhitelist(server string, drivers []WhitelistEntry) (Whitelist, error)
+}
+
+// SetWhitelistEditor enables the whitelist endpoints
+// Must be called before Start
+func (s *Server) SetWhitelistEditor(e WhitelistEditor) {
+	s.whitelists = e
+}
+
+// ListWhitelists returns the reserved slots of all servers
+// Requires Bearer token authentication
+func (s *Server) ListWhitelists(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string]map[string]Whitelist{"whitelists": s.whitelists.Whitelists()})
+}
+
+// GetWhitelist returns the reserved slots of one server (empty when none are configured)
+// Requires Bearer token authentication
+func (s *Server) GetWhitelist(w http.ResponseWriter, r *http.Request) {
+	server := r.PathValue("server")
+	wl, ok := s.whitelists.Whitelists()[server]
+	if !ok {
+		wl = Whitelist{Server: server, Drivers: []WhitelistEntry{}}
+	}
+	WriteJSON(w, http.StatusOK, wl)
+}
+
+// SetWhitelist replaces the reserved slots of one server, saves the config and writes the entry list
+// Requires Bearer token authentication and CSRF token; the change is recorded in the config audit log
+func (s *Server) SetWhitelist(w http.ResponseWriter, r *http.Request) {
+	if err := r.Context().Err(); err != nil {
+		log.Printf("SetWhitelist cancelled: %v", err)
+		WriteError(w, http.StatusServiceUnavailable, "Service unavailable", "Request cancelled")
+		return
+	}
+	if r.Body == nil {
+		WriteError(w, http.StatusBadRequest, "Empty request body", "PUT requires a JSON body")
+		return
+	}
+	defer r.Body.Close()
+
+	var req WhitelistRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	server := r.PathValue("server")
+	var wl Whitelist
+	err := s.auditedWrite(w, r, func() error {
+		var err error
+		wl, err = s.whitelists.SetWhitelist(server, req.Drivers)
+		return err
+	})
+	if errors.Is(err, ErrUnknownServer) {
+		WriteError(w, http.StatusNotFound, "Server not found", err.Error())
+		return
+	}
+	if err != nil {
+		writeConfigWriteError(w, "Invalid whitelist", err)
+		return
+	}
+	log.Printf("Audit: whitelist of '%s' set to %d driver(s) via the API from %s", server, len(wl.Drivers), extractClientIP(r, s.trustedProxies))
+	WriteJSON(w, http.StatusOK, wl)
+}
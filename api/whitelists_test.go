@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+)
+
+// mockWhitelists keeps whitelists in a map; only "Track #1" exists and it has two car slots
+type mockWhitelists struct {
+	whitelists map[string]Whitelist
+}
+
+func (m *mockWhitelists) Whitelists() map[string]Whitelist {
+	return m.whitelists
+}
+
+func (m *mockWhitelists) SetWhitelist(server string, drivers []WhitelistEntry) (Whitelist, error) {
+	if server != "Track #1" {
+		return Whitelist{}, fmt.Errorf("%w '%s'", ErrUnknownServer, server)
+	}
+	if len(drivers) > 2 {
+		return Whitelist{}, errors.New("the entry list has only 2 car slots")
+	}
+	wl := Whitelist{Server: server, EntryList: "/srv/ac/cfg/entry_list.ini", Drivers: drivers}
+	m.whitelists[server] = wl
+	return wl, nil
+}
+
+func TestWhitelistEndpoints(t *testing.T) {
+	s := NewServer(&mockConfigManager{}, "18081", "valid-token", []string{}, []string{}, log.New(os.Stdout, "TEST: ", log.LstdFlags))
+	m := &mockWhitelists{whitelists: make(map[string]Whitelist)}
+	s.SetWhitelistEditor(m)
+	handler := newVersionedTestHandler(t, s)
+
+	rec := auditDo(t, handler, "PUT", WhitelistsPath+"/Track%20%231", `{"drivers":[{"guid":"76561198000000001","name":"Alice"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Set status = %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	var wl Whitelist
+	json.NewDecoder(rec.Body).Decode(&wl)
+	if wl.Server != "Track #1" || wl.EntryList == "" || len(wl.Drivers) != 1 || wl.Drivers[0].Name != "Alice" {
+		t.Errorf("Unexpected whitelist %+v", wl)
+	}
+
+	rec = auditDo(t, handler, "GET", WhitelistsPath+"/Drift%20%231", "")
+	wl = Whitelist{}
+	json.NewDecoder(rec.Body).Decode(&wl)
+	if rec.Code != http.StatusOK || wl.Server != "Drift #1" || wl.Drivers == nil || len(wl.Drivers) != 0 {
+		t.Errorf("Expected an empty whitelist, got %d %+v", rec.Code, wl)
+	}
+
+	rec = auditDo(t, handler, "GET", WhitelistsPath, "")
+	var all map[string]map[string]Whitelist
+	json.NewDecoder(rec.Body).Decode(&all)
+	if len(all["whitelists"]["Track #1"].Drivers) != 1 {
+		t.Errorf("Expected the whitelist listed, got %+v", all)
+	}
+
+	tests := []struct {
+		name, path, body string
+		want             int
+	}{
+		{"unknown server", "/Rally%20%231", `{"drivers":[]}`, http.StatusNotFound},
+		{"too many drivers", "/Track%20%231", `{"drivers":[{"guid":"1"},{"guid":"2"},{"guid":"3"}]}`, http.StatusBadRequest},
+		{"invalid JSON", "/Track%20%231", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := auditDo(t, handler, "PUT", WhitelistsPath+tt.path, tt.body); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// ================= CONFIG BACKUPS =================
+
+// Backup modes: rotate keeps numbered slots (config.json.backup, .backup.1, ...), timestamp keeps one
+// file per write (config.json.2024-05-01T12-00-00.bak) pruned by count, age and total size
+const (
+	backupModeRotate    = "rotate"
+	backupModeTimestamp = "timestamp"
+)
+
+// Default number of backups: the rotate default matches the original fixed .backup/.backup.1-.3 slots
+const (
+	defaultRotateBackupCount    = 4
+	defaultTimestampBackupCount = 20
+)
+
+// backupTimeLayout names timestamped backups (UTC, no colons so the names are valid on every filesystem)
+const backupTimeLayout = "2006-01-02T15-04-05"
+
+// backupPolicy decides how config writes back up the file they replace
+type backupPolicy struct {
+	mode  string
+	count int
+	// maxAge and maxSize (total bytes) prune timestamped backups; 0 = no limit
+	maxAge  time.Duration
+	maxSize int64
+}
+
+// defaultBackupPolicy is the historical behavior: four rotated slots
+func defaultBackupPolicy() backupPolicy {
+	return backupPolicy{mode: backupModeRotate, count: defaultRotateBackupCount}
+}
+
+// backupPolicyFromEnv reads CONFIG_BACKUP_MODE (rotate or timestamp), CONFIG_BACKUP_COUNT and, for
+// timestamp mode, CONFIG_BACKUP_MAX_AGE (duration) and CONFIG_BACKUP_MAX_SIZE (bytes, or with KB/MB suffix)
+func backupPolicyFromEnv() (backupPolicy, error) {
+	p := defaultBackupPolicy()
+	switch mode := os.Getenv("CONFIG_BACKUP_MODE"); mode {
+	case "", backupModeRotate:
+	case backupModeTimestamp:
+		p = backupPolicy{mode: backupModeTimestamp, count: defaultTimestampBackupCount}
+	default:
+		return p, fmt.Errorf("invalid CONFIG_BACKUP_MODE %q: must be rotate or timestamp", mode)
+	}
+
+	if v := os.Getenv("CONFIG_BACKUP_COUNT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("invalid CONFIG_BACKUP_COUNT %q: must be at least 1", v)
+		}
+		p.count = n
+	}
+	if v := os.Getenv("CONFIG_BACKUP_MAX_AGE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return p, fmt.Errorf("invalid CONFIG_BACKUP_MAX_AGE %q: must be a positive duration like 720h", v)
+		}
+		p.maxAge = d
+	}
+	if v := os.Getenv("CONFIG_BACKUP_MAX_SIZE"); v != "" {
+		n, err := parseByteSize(v)
+		if err != nil {
+			return p, fmt.Errorf("invalid CONFIG_BACKUP_MAX_SIZE %q: %w", v, err)
+		}
+		p.maxSize = n
+	}
+	if p.mode == backupModeRotate && (p.maxAge > 0 || p.maxSize > 0) {
+		return p, fmt.Errorf("CONFIG_BACKUP_MAX_AGE and CONFIG_BACKUP_MAX_SIZE need CONFIG_BACKUP_MODE=timestamp")
+	}
+
+	if p != defaultBackupPolicy() {
+		log.Printf("Config backups: %s", p)
+	}
+	return p, nil
+}
+
+// String describes the policy for logs
+func (p backupPolicy) String() string {
+	s := fmt.Sprintf("%s mode, keeping %d", p.mode, p.count)
+	if p.maxAge > 0 {
+		s += fmt.Sprintf(", at most %v old", p.maxAge)
+	}
+	if p.maxSize > 0 {
+		s += fmt.Sprintf(", at most %d bytes in total", p.maxSize)
+	}
+	return s
+}
+
+// parseByteSize reads "1048576", "512KB" or "10MB" (binary units)
+func parseByteSize(s string) (int64, error) {
+	mult := int64(1)
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	switch {
+	case strings.HasSuffix(upper, "MB"):
+		mult, upper = 1<<20, strings.TrimSuffix(upper, "MB")
+	case strings.HasSuffix(upper, "KB"):
+		mult, upper = 1<<10, strings.TrimSuffix(upper, "KB")
+	case strings.HasSuffix(upper, "B"):
+		upper = strings.TrimSuffix(upper, "B")
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(upper), 10, 64)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("must be a positive size like 512KB or 10MB")
+	}
+	return n * mult, nil
+}
+
+// SetBackupPolicy sets how later writes back up the config file (the default is defaultBackupPolicy)
+func (cm *ConfigManager) SetBackupPolicy(p backupPolicy) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.backups = p
+}
+
+// backupPolicy returns the configured policy (caller holds cm.mu)
+func (cm *ConfigManager) backupPolicy() backupPolicy {
+	if cm.backups.mode == "" {
+		return defaultBackupPolicy()
+	}
+	return cm.backups
+}
+
+// createBackup saves the current config file before it is replaced, following the backup policy
+// Returns nil if config file doesn't exist yet (first-time write)
+func (cm *ConfigManager) createBackup() error {
+	data, err := os.ReadFile(cm.configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// No existing config to backup (first write)
+			return nil
+		}
+		return err
+	}
+
+	p := cm.backupPolicy()
+	if p.mode == backupModeTimestamp {
+		return cm.writeTimestampBackup(data, p, time.Now())
+	}
+	return cm.rotateBackups(data, p.count)
+}
+
+// rotateSlot is the path of rotated backup i: config.json.backup (latest), then .backup.1, .backup.2, ...
+func (cm *ConfigManager) rotateSlot(i int) string {
+	if i == 0 {
+		return cm.configPath + ".backup"
+	}
+	return fmt.Sprintf("%s.backup.%d", cm.configPath, i)
+}
+
+// rotateBackups shifts every slot one up (dropping the oldest) and writes data to config.json.backup
+// Slots beyond count, left over from a larger count, are removed
+func (cm *ConfigManager) rotateBackups(data []byte, count int) error {
+	for _, b := range cm.listBackups() {
+		if b.slot >= count-1 {
+			if err := os.Remove(b.path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", b.path, err)
+			}
+		}
+	}
+	for i := count - 2; i >= 0; i-- {
+		from, to := cm.rotateSlot(i), cm.rotateSlot(i+1)
+		if _, err := os.Stat(from); err == nil {
+			if err := os.Rename(from, to); err != nil {
+				return fmt.Errorf("failed to rename %s -> %s: %w", from, to, err)
+			}
+		}
+	}
+
+	latest := cm.rotateSlot(0)
+	if err := os.WriteFile(latest, data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	log.Printf("Config backup rotated: %s (latest of %d versions)", latest, count)
+	return nil
+}
+
+// writeTimestampBackup writes data to config.json.<UTC time>.bak and prunes older timestamped backups
+// A second write within the same second gets a -2, -3, ... suffix instead of overwriting
+func (cm *ConfigManager) writeTimestampBackup(data []byte, p backupPolicy, now time.Time) error {
+	stamp := now.UTC().Format(backupTimeLayout)
+	path := fmt.Sprintf("%s.%s.bak", cm.configPath, stamp)
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = fmt.Sprintf("%s.%s-%d.bak", cm.configPath, stamp, n)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	log.Printf("Config backup written: %s", path)
+
+	cm.pruneTimestampBackups(p, now)
+	return nil
+}
+
+// pruneTimestampBackups deletes timestamped backups beyond the count, older than maxAge or past the total
+// maxSize (newest first). The newest backup is always kept. Failures are logged: the write already succeeded
+func (cm *ConfigManager) pruneTimestampBackups(p backupPolicy, now time.Time) {
+	var total int64
+	kept, removed := 0, 0
+	for _, b := range cm.listBackups() {
+		if b.slot >= 0 {
+			continue // rotated slots are not pruned by age or size
+		}
+		total += b.size
+		expired := kept >= p.count ||
+			(p.maxAge > 0 && now.Sub(b.created) > p.maxAge) ||
+			(p.maxSize > 0 && total > p.maxSize)
+		if kept == 0 || !expired {
+			kept++
+			continue
+		}
+		if err := os.Remove(b.path); err != nil {
+			log.Printf("Warning: failed to prune config backup %s: %v", b.path, err)
+			continue
+		}
+		total -= b.size
+		removed++
+	}
+	if removed > 0 {
+		log.Printf("Pruned %d old config backups (%d kept)", removed, kept)
+	}
+}
+
+// configBackupFile is one backup on disk
+type configBackupFile struct {
+	path    string
+	size    int64
+	created time.Time
+	// slot is the rotation index of config.json.backup[.N], -1 for timestamped backups
+	slot int
+	// seq orders timestamped backups written within the same second
+	seq int
+}
+
+// listBackups returns the backups of the config file in both naming schemes, newest first
+// Timestamped backups are dated by their name, rotated slots by their modification time
+func (cm *ConfigManager) listBackups() []configBackupFile {
+	dir, base := filepath.Split(cm.configPath)
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil
+	}
+
+	var backups []configBackupFile
+	for _, e := range entries {
+		name := e.Name()
+		rest, ok := strings.CutPrefix(name, base+".")
+		if !ok || e.IsDir() {
+			continue
+		}
+		b := configBackupFile{path: filepath.Join(dir, name), slot: -1}
+		switch {
+		case rest == "backup":
+			b.slot = 0
+		case strings.HasPrefix(rest, "backup."):
+			n, err := strconv.Atoi(strings.TrimPrefix(rest, "backup."))
+			if err != nil || n < 1 {
+				continue
+			}
+			b.slot = n
+		case strings.HasSuffix(rest, ".bak") && len(rest) >= len(backupTimeLayout)+len(".bak"):
+			stamp, suffix := rest[:len(backupTimeLayout)], strings.TrimSuffix(rest[len(backupTimeLayout):], ".bak")
+			t, err := time.Parse(backupTimeLayout, stamp)
+			if err != nil {
+				continue
+			}
+			b.created, b.seq = t, 1
+			if suffix != "" {
+				n, err := strconv.Atoi(strings.TrimPrefix(suffix, "-"))
+				if err != nil || !strings.HasPrefix(suffix, "-") || n < 2 {
+					continue
+				}
+				b.seq = n
+			}
+		default:
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		b.size = info.Size()
+		if b.slot >= 0 {
+			b.created = info.ModTime()
+		}
+		backups = append(backups, b)
+	}
+
+	slices.SortFunc(backups, func(a, b configBackupFile) int {
+		if c := b.created.Compare(a.created); c != 0 {
+			return c
+		}
+		if a.slot != b.slot {
+			return a.slot - b.slot // same mtime: lower slot is newer
+		}
+		return b.seq - a.seq
+	})
+	return backups
+}
+
+// configBackups adapts ConfigManager to api.ConfigBackups
+type configBackups struct {
+	cm *ConfigManager
+}
+
+// ListConfigBackups implements api.ConfigBackups
+func (c *configBackups) ListConfigBackups() (*api.ConfigBackupList, error) {
+	c.cm.mu.RLock()
+	defer c.cm.mu.RUnlock()
+
+	p := c.cm.backupPolicy()
+	list := &api.ConfigBackupList{Mode: p.mode, Count: p.count, MaxSizeBytes: p.maxSize, Backups: []api.ConfigBackup{}}
+	if p.maxAge > 0 {
+		list.MaxAge = p.maxAge.String()
+	}
+	for _, b := range c.cm.listBackups() {
+		list.Backups = append(list.Backups, api.ConfigBackup{Name: filepath.Base(b.path), SizeBytes: b.size, CreatedAt: b.created.UTC()})
+	}
+	return list, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// backupNames returns the base names of the config's backups, newest first
+func backupNames(cm *ConfigManager) []string {
+	var names []string
+	for _, b := range cm.listBackups() {
+		names = append(names, filepath.Base(b.path))
+	}
+	return names
+}
+
+// TestBackupPolicyFromEnv tests defaults, timestamp mode limits and rejected settings
+func TestBackupPolicyFromEnv(t *testing.T) {
+	if p, err := backupPolicyFromEnv(); err != nil || p != defaultBackupPolicy() {
+		t.Errorf("Expected the default policy, got %v (%v)", p, err)
+	}
+
+	t.Setenv("CONFIG_BACKUP_MODE", "timestamp")
+	t.Setenv("CONFIG_BACKUP_MAX_AGE", "720h")
+	t.Setenv("CONFIG_BACKUP_MAX_SIZE", "512KB")
+	p, err := backupPolicyFromEnv()
+	if err != nil {
+		t.Fatalf("backupPolicyFromEnv failed: %v", err)
+	}
+	if p.mode != backupModeTimestamp || p.count != defaultTimestampBackupCount || p.maxAge != 720*time.Hour || p.maxSize != 512<<10 {
+		t.Errorf("Unexpected policy %v", p)
+	}
+
+	for name, bad := range map[string]string{
+		"CONFIG_BACKUP_MODE":     "daily",
+		"CONFIG_BACKUP_COUNT":    "0",
+		"CONFIG_BACKUP_MAX_AGE":  "30d",
+		"CONFIG_BACKUP_MAX_SIZE": "lots",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, bad)
+			if _, err := backupPolicyFromEnv(); err == nil {
+				t.Errorf("Expected error for %s=%s", name, bad)
+			}
+		})
+	}
+
+	t.Setenv("CONFIG_BACKUP_MODE", "rotate")
+	if _, err := backupPolicyFromEnv(); err == nil {
+		t.Error("Expected error for age and size limits in rotate mode")
+	}
+}
+
+// TestParseByteSize tests plain bytes and binary suffixes
+func TestParseByteSize(t *testing.T) {
+	for in, want := range map[string]int64{"2048": 2048, "100B": 100, "512kb": 512 << 10, "10MB": 10 << 20} {
+		if got, err := parseByteSize(in); err != nil || got != want {
+			t.Errorf("parseByteSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "MB", "-1KB", "1GB"} {
+		if _, err := parseByteSize(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+// TestRotateBackups_Count tests a smaller count shifting slots and removing leftovers from a larger one
+func TestRotateBackups_Count(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	cm := NewConfigManager(configPath, nil)
+	cm.SetBackupPolicy(backupPolicy{mode: backupModeRotate, count: 2})
+	os.WriteFile(configPath+".backup.3", []byte("stale"), 0644)
+
+	for _, version := range []string{"v1", "v2", "v3"} {
+		os.WriteFile(configPath, []byte(version), 0644)
+		if err := cm.createBackup(); err != nil {
+			t.Fatalf("createBackup failed: %v", err)
+		}
+	}
+
+	if got := backupNames(cm); !slices.Equal(slices.Sorted(slices.Values(got)), []string{"config.json.backup", "config.json.backup.1"}) {
+		t.Errorf("Expected two slots, got %v", got)
+	}
+	latest, _ := os.ReadFile(configPath + ".backup")
+	previous, _ := os.ReadFile(configPath + ".backup.1")
+	if string(latest) != "v3" || string(previous) != "v2" {
+		t.Errorf("Expected v3 then v2, got %q and %q", latest, previous)
+	}
+}
+
+// TestTimestampBackups_Pruning tests same-second names and pruning by count, age and size
+func TestTimestampBackups_Pruning(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	cm := NewConfigManager(configPath, nil)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	write := func(p backupPolicy, at time.Time, data string) {
+		t.Helper()
+		if err := cm.writeTimestampBackup([]byte(data), p, at); err != nil {
+			t.Fatalf("writeTimestampBackup failed: %v", err)
+		}
+	}
+
+	unlimited := backupPolicy{mode: backupModeTimestamp, count: 10}
+	write(unlimited, start, "a")
+	write(unlimited, start, "b")
+	write(unlimited, start.Add(time.Hour), "c")
+	want := []string{"config.json.2024-05-01T13-00-00.bak", "config.json.2024-05-01T12-00-00-2.bak", "config.json.2024-05-01T12-00-00.bak"}
+	if got := backupNames(cm); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	write(backupPolicy{mode: backupModeTimestamp, count: 2}, start.Add(2*time.Hour), "d")
+	if got := backupNames(cm); len(got) != 2 || got[0] != "config.json.2024-05-01T14-00-00.bak" {
+		t.Errorf("Expected the newest two after count pruning, got %v", got)
+	}
+
+	write(backupPolicy{mode: backupModeTimestamp, count: 10, maxAge: 90 * time.Minute}, start.Add(3*time.Hour), "e")
+	if got := backupNames(cm); len(got) != 2 || got[1] != "config.json.2024-05-01T14-00-00.bak" {
+		t.Errorf("Expected backups older than 90m pruned, got %v", got)
+	}
+
+	// Size pruning never removes the newest backup, even when it alone is over the limit
+	write(backupPolicy{mode: backupModeTimestamp, count: 10, maxSize: 1}, start.Add(4*time.Hour), "ff")
+	if got := backupNames(cm); !slices.Equal(got, []string{"config.json.2024-05-01T16-00-00.bak"}) {
+		t.Errorf("Expected only the newest backup, got %v", got)
+	}
+}
+
+// TestConfigBackups_List tests the API listing through a timestamp-mode write
+func TestConfigBackups_List(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	cm := NewConfigManager(configPath, nil)
+	cm.SetBackupPolicy(backupPolicy{mode: backupModeTimestamp, count: 5, maxAge: 24 * time.Hour})
+	if err := cm.WriteConfig(testStatusConfig()); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+	if err := cm.UpdateConfig(map[string]interface{}{"update_interval": 60}); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+
+	list, err := (&configBackups{cm: cm}).ListConfigBackups()
+	if err != nil {
+		t.Fatalf("ListConfigBackups failed: %v", err)
+	}
+	if list.Mode != backupModeTimestamp || list.Count != 5 || list.MaxAge != "24h0m0s" || len(list.Backups) != 1 {
+		t.Fatalf("Unexpected list %+v", list)
+	}
+	if b := list.Backups[0]; b.SizeBytes == 0 || time.Since(b.CreatedAt) > time.Minute || filepath.Ext(b.Name) != ".bak" {
+		t.Errorf("Unexpected backup %+v", b)
+	}
+}
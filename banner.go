@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ================= STATUS BANNER (PNG) =================
+
+// Banner layout (pixels). Glyphs are 5x7 bitmaps drawn at bannerScale, one column of spacing
+const (
+	bannerScale      = 2
+	bannerGlyphW     = 5
+	bannerGlyphH     = 7
+	bannerCellW      = (bannerGlyphW + 1) * bannerScale
+	bannerLineH      = (bannerGlyphH + 3) * bannerScale
+	bannerPadding    = 16
+	bannerWidth      = 640
+	bannerNameChars  = 24
+	bannerMapChars   = 16
+	bannerStatusSize = 5 * bannerScale
+)
+
+var (
+	bannerBackground = color.RGBA{0x1e, 0x1f, 0x22, 0xff}
+	bannerText       = color.RGBA{0xdb, 0xde, 0xe1, 0xff}
+	bannerHeading    = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	bannerMuted      = color.RGBA{0x94, 0x9b, 0xa4, 0xff}
+	bannerOnline     = color.RGBA{0x23, 0xa5, 0x5a, 0xff}
+	bannerOffline    = color.RGBA{0xf2, 0x3f, 0x43, 0xff}
+)
+
+// bannerFont is a 5x7 bitmap font (rows top to bottom, bit 4 = leftmost column)
+// Lowercase is drawn as uppercase; unknown runes are drawn as '?'
+// Embedded so the banner needs no font files or external image libraries
+var bannerFont = map[rune][bannerGlyphH]uint8{
+	' ':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	'A':  {0x0E, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11},
+	'B':  {0x1E, 0x11, 0x11, 0x1E, 0x11, 0x11, 0x1E},
+	'C':  {0x0E, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0E},
+	'D':  {0x1C, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1C},
+	'E':  {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x1F},
+	'F':  {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x10},
+	'G':  {0x0E, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0F},
+	'H':  {0x11, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11},
+	'I':  {0x0E, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'J':  {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0C},
+	'K':  {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L':  {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1F},
+	'M':  {0x11, 0x1B, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N':  {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O':  {0x0E, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'P':  {0x1E, 0x11, 0x11, 0x1E, 0x10, 0x10, 0x10},
+	'Q':  {0x0E, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0D},
+	'R':  {0x1E, 0x11, 0x11, 0x1E, 0x14, 0x12, 0x11},
+	'S':  {0x0F, 0x10, 0x10, 0x0E, 0x01, 0x01, 0x1E},
+	'T':  {0x1F, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'U':  {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'V':  {0x11, 0x11, 0x11, 0x11, 0x11, 0x0A, 0x04},
+	'W':  {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0A},
+	'X':  {0x11, 0x11, 0x0A, 0x04, 0x0A, 0x11, 0x11},
+	'Y':  {0x11, 0x11, 0x0A, 0x04, 0x04, 0x04, 0x04},
+	'Z':  {0x1F, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1F},
+	'0':  {0x0E, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0E},
+	'1':  {0x04, 0x0C, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'2':  {0x0E, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1F},
+	'3':  {0x1F, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0E},
+	'4':  {0x02, 0x06, 0x0A, 0x12, 0x1F, 0x02, 0x02},
+	'5':  {0x1F, 0x10, 0x1E, 0x01, 0x01, 0x11, 0x0E},
+	'6':  {0x06, 0x08, 0x10, 0x1E, 0x11, 0x11, 0x0E},
+	'7':  {0x1F, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8':  {0x0E, 0x11, 0x11, 0x0E, 0x11, 0x11, 0x0E},
+	'9':  {0x0E, 0x11, 0x11, 0x0F, 0x01, 0x02, 0x0C},
+	'.':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x0C, 0x0C},
+	',':  {0x00, 0x00, 0x00, 0x00, 0x0C, 0x04, 0x08},
+	':':  {0x00, 0x0C, 0x0C, 0x00, 0x0C, 0x0C, 0x00},
+	';':  {0x00, 0x0C, 0x0C, 0x00, 0x0C, 0x04, 0x08},
+	'-':  {0x00, 0x00, 0x00, 0x1F, 0x00, 0x00, 0x00},
+	'_':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F},
+	'/':  {0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00},
+	'(':  {0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02},
+	')':  {0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08},
+	'[':  {0x0E, 0x08, 0x08, 0x08, 0x08, 0x08, 0x0E},
+	']':  {0x0E, 0x02, 0x02, 0x02, 0x02, 0x02, 0x0E},
+	'<':  {0x02, 0x04, 0x08, 0x10, 0x08, 0x04, 0x02},
+	'>':  {0x08, 0x04, 0x02, 0x01, 0x02, 0x04, 0x08},
+	'!':  {0x04, 0x04, 0x04, 0x04, 0x04, 0x00, 0x04},
+	'?':  {0x0E, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04},
+	'\'': {0x04, 0x04, 0x08, 0x00, 0x00, 0x00, 0x00},
+	'"':  {0x0A, 0x0A, 0x00, 0x00, 0x00, 0x00, 0x00},
+	'#':  {0x0A, 0x0A, 0x1F, 0x0A, 0x1F, 0x0A, 0x0A},
+	'%':  {0x18, 0x19, 0x02, 0x04, 0x08, 0x13, 0x03},
+	'&':  {0x0C, 0x12, 0x14, 0x08, 0x15, 0x12, 0x0D},
+	'*':  {0x00, 0x04, 0x15, 0x0E, 0x15, 0x04, 0x00},
+	'+':  {0x00, 0x04, 0x04, 0x1F, 0x04, 0x04, 0x00},
+	'=':  {0x00, 0x00, 0x1F, 0x00, 0x1F, 0x00, 0x00},
+	'|':  {0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'@':  {0x0E, 0x11, 0x01, 0x0D, 0x15, 0x15, 0x0E},
+}
+
+// bannerGlyph returns the bitmap for r, folding case and common typographic variants
+func bannerGlyph(r rune) [bannerGlyphH]uint8 {
+	switch r {
+	case '—', '–':
+		r = '-'
+	case '’', '‘':
+		r = '\''
+	}
+	if g, ok := bannerFont[unicode.ToUpper(r)]; ok {
+		return g
+	}
+	return bannerFont['?']
+}
+
+// bannerDrawText draws s with its top-left corner at (x, y)
+func bannerDrawText(img *image.RGBA, x, y int, s string, c color.Color) {
+	src := image.NewUniform(c)
+	for _, r := range s {
+		glyph := bannerGlyph(r)
+		for row := 0; row < bannerGlyphH; row++ {
+			for col := 0; col < bannerGlyphW; col++ {
+				if glyph[row]&(1<<(bannerGlyphW-1-col)) == 0 {
+					continue
+				}
+				px := x + col*bannerScale
+				py := y + row*bannerScale
+				draw.Draw(img, image.Rect(px, py, px+bannerScale, py+bannerScale), src, image.Point{}, draw.Src)
+			}
+		}
+		x += bannerCellW
+	}
+}
+
+// bannerTruncate shortens s to max runes, marking truncation with '.'
+func bannerTruncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimRight(string(runes[:max-1]), " ") + "."
+}
+
+// renderStatusBanner draws the snapshot as a PNG: title, total players, then one row per server
+// (status dot, name, map, players) grouped under category headings
+func renderStatusBanner(snap *StatusSnapshot, title string) ([]byte, error) {
+	lines := 2 // title + total
+	for _, cat := range snap.Categories {
+		lines += 1 + len(cat.Servers)
+	}
+	height := 2*bannerPadding + lines*bannerLineH + len(snap.Categories)*bannerLineH/2
+
+	img := image.NewRGBA(image.Rect(0, 0, bannerWidth, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bannerBackground), image.Point{}, draw.Src)
+
+	nameX := bannerPadding + bannerStatusSize + bannerCellW
+	mapX := nameX + (bannerNameChars+1)*bannerCellW
+	playersX := bannerWidth - bannerPadding - 7*bannerCellW
+
+	y := bannerPadding
+	bannerDrawText(img, bannerPadding, y, bannerTruncate(title, (bannerWidth-2*bannerPadding)/bannerCellW), bannerHeading)
+	y += bannerLineH
+	bannerDrawText(img, bannerPadding, y, fmt.Sprintf("Total players: %d", snap.TotalPlayers), bannerMuted)
+	y += bannerLineH
+
+	for _, cat := range snap.Categories {
+		y += bannerLineH / 2
+		bannerDrawText(img, bannerPadding, y, fmt.Sprintf("%s - %d players", cat.Name, cat.Players), bannerHeading)
+		y += bannerLineH
+
+		for _, srv := range cat.Servers {
+			dot := bannerOffline
+			players := "-"
+			if srv.Online {
+				dot = bannerOnline
+				players = fmt.Sprintf("%d/%d", srv.Players, srv.MaxPlayers)
+			}
+			if srv.Stale {
+				dot = bannerMuted
+			}
+			dotY := y + (bannerGlyphH*bannerScale-bannerStatusSize)/2
+			draw.Draw(img, image.Rect(bannerPadding, dotY, bannerPadding+bannerStatusSize, dotY+bannerStatusSize),
+				image.NewUniform(dot), image.Point{}, draw.Src)
+
+			bannerDrawText(img, nameX, y, bannerTruncate(srv.Name, bannerNameChars), bannerText)
+			bannerDrawText(img, mapX, y, bannerTruncate(srv.Map, bannerMapChars), bannerMuted)
+			bannerDrawText(img, playersX, y, players, bannerText)
+			y += bannerLineH
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("PNG encoding failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// statusBannerProvider adapts the bot's latest banner to api.StatusImageProvider
+type statusBannerProvider struct {
+	bot *Bot
+}
+
+// StatusImage returns the latest rendered PNG, or nil before the first poll completes
+func (p *statusBannerProvider) StatusImage() []byte {
+	banner := p.bot.lastBanner.Load()
+	if banner == nil {
+		return nil
+	}
+	return *banner
+}
+
+// bannerAttachmentName is the filename used when the banner is attached to the Discord embed
+const bannerAttachmentName = "status.png"
+
+// StatusBanner renders the PNG banner after each poll
+// attach additionally uploads it with the Discord status message as the embed image
+type StatusBanner struct {
+	title  string
+	attach bool
+}
+
+// NewStatusBanner creates a banner renderer
+func NewStatusBanner(title string, attach bool) *StatusBanner {
+	if title == "" {
+		title = defaultStatusTitle
+	}
+	return &StatusBanner{title: title, attach: attach}
+}
+
+// Render draws the sanitized snapshot (server addresses are never drawn)
+func (sb *StatusBanner) Render(snap *StatusSnapshot) ([]byte, error) {
+	return renderStatusBanner(snap.Sanitized(false), sb.title)
+}
+
+// statusBannerFromEnv returns a banner renderer if STATUS_BANNER_ENABLED or DISCORD_ATTACH_BANNER is true, nil otherwise
+func statusBannerFromEnv() *StatusBanner {
+	attach := os.Getenv("DISCORD_ATTACH_BANNER") == "true"
+	if os.Getenv("STATUS_BANNER_ENABLED") != "true" && !attach {
+		return nil
+	}
+	log.Printf("Status banner enabled (attached to Discord embed: %v)", attach)
+	return NewStatusBanner(os.Getenv("STATUS_BANNER_TITLE"), attach)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+)
+
+// TestRenderStatusBanner tests that the banner is a valid PNG sized to the server list
+func TestRenderStatusBanner(t *testing.T) {
+	infos := []ServerInfo{
+		{Name: "Drift 1", Category: "Drift", Map: "ebisu", NumPlayers: 2, MaxPlayers: 16, IP: "203.0.113.10", Port: 8081},
+		{Name: "Drift 2", Category: "Drift", Map: "Offline", NumPlayers: -1, IP: "203.0.113.10", Port: 8083},
+		{Name: "Track 1 — a very long server name that must be truncated", Category: "Track", Map: "spa", NumPlayers: 4, MaxPlayers: 20, IP: "203.0.113.10", Port: 8082},
+	}
+	snap := buildStatusSnapshot(infos, testStatusConfig(), time.Now())
+
+	data, err := NewStatusBanner("", false).Render(snap)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Banner is not a valid PNG: %v", err)
+	}
+	if got := img.Bounds().Dx(); got != bannerWidth {
+		t.Errorf("Expected width %d, got %d", bannerWidth, got)
+	}
+	// title + total + 2 category headings + 3 servers, plus half-line category gaps
+	wantHeight := 2*bannerPadding + 7*bannerLineH + 2*bannerLineH/2
+	if got := img.Bounds().Dy(); got != wantHeight {
+		t.Errorf("Expected height %d, got %d", wantHeight, got)
+	}
+}
+
+// TestRenderStatusBanner_Empty tests rendering before any category is configured
+func TestRenderStatusBanner_Empty(t *testing.T) {
+	data, err := renderStatusBanner(&StatusSnapshot{}, "Empty")
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("Banner is not a valid PNG: %v", err)
+	}
+}
+
+// TestBannerGlyph tests case folding and fallback for unsupported runes
+func TestBannerGlyph(t *testing.T) {
+	if bannerGlyph('a') != bannerFont['A'] {
+		t.Error("Expected lowercase to use the uppercase glyph")
+	}
+	if bannerGlyph('—') != bannerFont['-'] {
+		t.Error("Expected em dash to use the hyphen glyph")
+	}
+	if bannerGlyph('🟣') != bannerFont['?'] {
+		t.Error("Expected unsupported rune to use the '?' glyph")
+	}
+}
+
+// TestBannerTruncate tests rune-safe truncation
+func TestBannerTruncate(t *testing.T) {
+	if got := bannerTruncate("short", 10); got != "short" {
+		t.Errorf("Expected unchanged string, got %q", got)
+	}
+	if got := bannerTruncate("Nürburgring", 5); got != "Nürb." {
+		t.Errorf("Expected 'Nürb.', got %q", got)
+	}
+}
+
+// TestStatusBannerProvider tests that no image is served before the first poll
+func TestStatusBannerProvider(t *testing.T) {
+	bot := newTestBot(testStatusConfig())
+	p := &statusBannerProvider{bot: bot}
+
+	if p.StatusImage() != nil {
+		t.Error("Expected nil image before first poll")
+	}
+
+	data := []byte{0x89, 'P', 'N', 'G'}
+	bot.lastBanner.Store(&data)
+	if !bytes.Equal(p.StatusImage(), data) {
+		t.Error("Expected latest banner to be returned")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// ================= EVENTS CALENDAR =================
+
+// eventCalendar adapts the configured race events and active maintenance windows to api.EventCalendar
+type eventCalendar struct {
+	bot           *Bot
+	title         string
+	showAddresses bool
+}
+
+// eventCalendarFromEnv returns the iCalendar feed if API_EVENTS_CALENDAR_ENABLED is true, nil otherwise
+func eventCalendarFromEnv(bot *Bot) *eventCalendar {
+	if os.Getenv("API_EVENTS_CALENDAR_ENABLED") != "true" {
+		return nil
+	}
+	title := os.Getenv("API_EVENTS_CALENDAR_TITLE")
+	if title == "" {
+		title = defaultStatusTitle
+	}
+	return &eventCalendar{
+		bot:           bot,
+		title:         title,
+		showAddresses: os.Getenv("API_EVENTS_CALENDAR_SHOW_ADDRESSES") == "true",
+	}
+}
+
+// EventsCalendar implements api.EventCalendar
+func (c *eventCalendar) EventsCalendar() api.Calendar {
+	return buildEventsCalendar(c.title, c.bot.configManager.GetConfig(), c.bot.maintenance.list(time.Now()), c.showAddresses)
+}
+
+// buildEventsCalendar lists every configured race event and the maintenance windows of entries
+// Who set a maintenance flag is not published; join links only with showAddresses
+func buildEventsCalendar(title string, cfg *Config, entries []maintenanceEntry, showAddresses bool) api.Calendar {
+	cal := api.Calendar{Name: title, Events: []api.CalendarEvent{}}
+	if cfg == nil {
+		return cal
+	}
+	servers := make(map[string]Server, len(cfg.Servers))
+	for _, srv := range cfg.Servers {
+		if _, ok := servers[srv.Name]; !ok {
+			servers[srv.Name] = srv
+		}
+	}
+
+	for _, e := range cfg.Events {
+		start, end := e.window()
+		description := "Server: " + e.Server
+		location := e.Server
+		if srv, ok := servers[e.Server]; ok && showAddresses {
+			join := joinURL(srv.IP, srv.Port)
+			description += "\nJoin: " + join
+			location = join
+		}
+		if e.Description != "" {
+			description = e.Description + "\n\n" + description
+		}
+		cal.Events = append(cal.Events, api.CalendarEvent{
+			UID:         calendarUID("event", e.Name),
+			Summary:     e.Name,
+			Description: description,
+			Location:    location,
+			Start:       start,
+			End:         end,
+		})
+	}
+
+	for _, m := range entries {
+		cal.Events = append(cal.Events, api.CalendarEvent{
+			UID:         calendarUID("maintenance", m.server+"\x00"+strconv.FormatInt(m.since.Unix(), 10)),
+			Summary:     "🔧 Maintenance: " + m.server,
+			Description: m.reason,
+			Location:    m.server,
+			Start:       m.since,
+			End:         m.until,
+		})
+	}
+	return cal
+}
+
+// calendarUID derives a stable UID from kind and key; hashing keeps arbitrary names out of the UID syntax
+func calendarUID(kind, key string) string {
+	sum := sha256.Sum256([]byte(kind + "\x00" + key))
+	return kind + "-" + hex.EncodeToString(sum[:8]) + "@absa-ac"
+}
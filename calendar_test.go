@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBuildEventsCalendar tests the feed contents: race events, maintenance windows and address hiding
+func TestBuildEventsCalendar(t *testing.T) {
+	cfg := testRaceEventConfig()
+	since := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	entries := []maintenanceEntry{{server: "Drift #1", reason: "Track update", setBy: "alice", since: since, until: since.Add(time.Hour)}}
+
+	cal := buildEventsCalendar("League", cfg, entries, false)
+	if cal.Name != "League" || len(cal.Events) != 2 {
+		t.Fatalf("Expected 'League' with 2 events, got %q with %d", cal.Name, len(cal.Events))
+	}
+	race := cal.Events[0]
+	if race.Summary != "Sunday Cup" || race.Location != "Track #1" {
+		t.Errorf("Race event = %+v, want Sunday Cup at Track #1", race)
+	}
+	if want := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC); !race.End.Equal(want) {
+		t.Errorf("Race event ends %v, want %v (default duration)", race.End, want)
+	}
+	if !strings.HasPrefix(race.Description, "GT3 at Spa\n\n") || strings.Contains(race.Description, "Join: ") {
+		t.Errorf("Race description %q should keep the event text and hide the join link", race.Description)
+	}
+	maint := cal.Events[1]
+	if maint.Summary != "🔧 Maintenance: Drift #1" || maint.Description != "Track update" || !maint.Start.Equal(since) {
+		t.Errorf("Maintenance event = %+v", maint)
+	}
+	if strings.Contains(maint.Description, "alice") {
+		t.Errorf("Maintenance event must not publish who set it: %+v", maint)
+	}
+
+	again := buildEventsCalendar("League", cfg, entries, false)
+	if again.Events[0].UID != race.UID || again.Events[1].UID != maint.UID || race.UID == maint.UID {
+		t.Errorf("UIDs must be stable and distinct: %q, %q", race.UID, maint.UID)
+	}
+
+	shown := buildEventsCalendar("League", cfg, nil, true)
+	if len(shown.Events) != 1 || !strings.Contains(shown.Events[0].Description, "Join: ") || shown.Events[0].Location == "Track #1" {
+		t.Errorf("Expected the join link with addresses shown, got %+v", shown.Events)
+	}
+
+	if empty := buildEventsCalendar("League", nil, nil, false); empty.Events == nil || len(empty.Events) != 0 {
+		t.Errorf("Expected an empty event list without a config, got %+v", empty.Events)
+	}
+}
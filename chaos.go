@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= CHAOS INJECTION =================
+
+// defaultChaosPollDelay is how long a slowed poll waits before it is sent (above the default 2s poll timeout)
+const defaultChaosPollDelay = 3 * time.Second
+
+// errChaosPoll is returned for an injected poll failure; it looks like a network error to the poller
+var errChaosPoll = errors.New("chaos: injected poll failure")
+
+// chaosInjector makes polls and Discord status edits fail or stall at configured probabilities
+// Test-only: lets retries, stale data and alert debouncing be exercised before a release
+// Never enable in production; every injection is logged with a CHAOS prefix
+type chaosInjector struct {
+	pollFailureRate  float64
+	pollSlowRate     float64
+	pollDelay        time.Duration
+	discordErrorRate float64
+	discordStatus    int
+
+	mu  sync.Mutex
+	rng *rand.Rand
+
+	// injected counts faults per kind for logs and tests
+	pollFailures  atomic.Int64
+	pollDelays    atomic.Int64
+	discordErrors atomic.Int64
+}
+
+// chaosFromEnv reads CHAOS_* settings (nil unless CHAOS_ENABLED=true)
+// CHAOS_POLL_FAILURE_RATE, CHAOS_POLL_SLOW_RATE and CHAOS_DISCORD_ERROR_RATE are probabilities (0-1),
+// CHAOS_POLL_DELAY is a duration, CHAOS_DISCORD_ERROR_STATUS the HTTP status of injected Discord errors
+// (500 retried, 429 rate limited, 403 missing permission) and CHAOS_SEED makes runs repeatable
+func chaosFromEnv() (*chaosInjector, error) {
+	if os.Getenv("CHAOS_ENABLED") != "true" {
+		return nil, nil
+	}
+
+	c := &chaosInjector{pollDelay: defaultChaosPollDelay, discordStatus: http.StatusInternalServerError}
+	for _, rate := range []struct {
+		name string
+		dst  *float64
+	}{
+		{"CHAOS_POLL_FAILURE_RATE", &c.pollFailureRate},
+		{"CHAOS_POLL_SLOW_RATE", &c.pollSlowRate},
+		{"CHAOS_DISCORD_ERROR_RATE", &c.discordErrorRate},
+	} {
+		v := os.Getenv(rate.name)
+		if v == "" {
+			continue
+		}
+		p, err := strconv.ParseFloat(v, 64)
+		if err != nil || p < 0 || p > 1 {
+			return nil, fmt.Errorf("invalid %s %q: must be a probability between 0 and 1", rate.name, v)
+		}
+		*rate.dst = p
+	}
+	if v := os.Getenv("CHAOS_POLL_DELAY"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid CHAOS_POLL_DELAY %q: must be a positive duration like 3s", v)
+		}
+		c.pollDelay = d
+	}
+	if v := os.Getenv("CHAOS_DISCORD_ERROR_STATUS"); v != "" {
+		status, err := strconv.Atoi(v)
+		if err != nil || status < 400 || status > 599 {
+			return nil, fmt.Errorf("invalid CHAOS_DISCORD_ERROR_STATUS %q: must be an HTTP error status like 500, 429 or 403", v)
+		}
+		c.discordStatus = status
+	}
+	seed := uint64(time.Now().UnixNano())
+	if v := os.Getenv("CHAOS_SEED"); v != "" {
+		s, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CHAOS_SEED %q: must be a non-negative integer", v)
+		}
+		seed = s
+	}
+	c.rng = rand.New(rand.NewPCG(seed, seed))
+
+	log.Printf("[WARNING] CHAOS mode enabled (test only): poll failures %.0f%%, slow polls %.0f%% (+%v), Discord edit errors %.0f%% (HTTP %d), seed %d",
+		c.pollFailureRate*100, c.pollSlowRate*100, c.pollDelay, c.discordErrorRate*100, c.discordStatus, seed)
+	return c, nil
+}
+
+// roll reports whether an event with probability p happens (false on a nil injector)
+func (c *chaosInjector) roll(p float64) bool {
+	if c == nil || p <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rng.Float64() < p
+}
+
+// wrapTransport injects poll faults into rt (rt unchanged on a nil injector)
+func (c *chaosInjector) wrapTransport(rt http.RoundTripper) http.RoundTripper {
+	if c == nil {
+		return rt
+	}
+	return &chaosTransport{next: rt, chaos: c}
+}
+
+// chaosTransport fails or delays game server polls before they reach the network
+type chaosTransport struct {
+	next  http.RoundTripper
+	chaos *chaosInjector
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *chaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.chaos.roll(t.chaos.pollFailureRate) {
+		t.chaos.pollFailures.Add(1)
+		log.Printf("CHAOS: failing poll of %s", req.URL.Host)
+		return nil, errChaosPoll
+	}
+	if t.chaos.roll(t.chaos.pollSlowRate) {
+		t.chaos.pollDelays.Add(1)
+		log.Printf("CHAOS: delaying poll of %s by %v", req.URL.Host, t.chaos.pollDelay)
+		if err := sleepContext(req.Context(), t.chaos.pollDelay); err != nil {
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// CloseIdleConnections forwards to the wrapped transport so client rebuilds release sockets
+func (t *chaosTransport) CloseIdleConnections() {
+	if ci, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		ci.CloseIdleConnections()
+	}
+}
+
+// sleepContext waits d or until ctx is done (returns ctx.Err() then)
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// discordError builds the REST error an injected Discord failure returns
+func (c *chaosInjector) discordError() error {
+	c.discordErrors.Add(1)
+	log.Printf("CHAOS: failing Discord status edit with HTTP %d", c.discordStatus)
+	restErr := &discordgo.RESTError{
+		Response:     &http.Response{StatusCode: c.discordStatus, Status: fmt.Sprintf("%d %s", c.discordStatus, http.StatusText(c.discordStatus))},
+		ResponseBody: []byte(`{"message": "chaos: injected error", "code": 0}`),
+	}
+	if c.discordStatus == http.StatusForbidden {
+		restErr.Message = &discordgo.APIErrorMessage{Code: discordgo.ErrCodeMissingPermissions, Message: "chaos: injected error"}
+	}
+	return restErr
+}
+
+// wrapSession injects status edit errors into a bot session (s unchanged on a nil injector)
+func (c *chaosInjector) wrapSession(s DiscordSession) DiscordSession {
+	if c == nil || c.discordErrorRate <= 0 {
+		return s
+	}
+	return &chaosSession{DiscordSession: s, chaos: c}
+}
+
+// chaosSession fails status message edits; every other call goes to the real session
+type chaosSession struct {
+	DiscordSession
+	chaos *chaosInjector
+}
+
+// ChannelMessageEditComplex implements DiscordSession
+func (s *chaosSession) ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if s.chaos.roll(s.chaos.discordErrorRate) {
+		return nil, s.chaos.discordError()
+	}
+	return s.DiscordSession.ChannelMessageEditComplex(m, options...)
+}
+
+// wrapWebhookAPI injects status edit errors into webhook mode (api unchanged on a nil injector)
+func (c *chaosInjector) wrapWebhookAPI(api webhookAPI) webhookAPI {
+	if c == nil || c.discordErrorRate <= 0 {
+		return api
+	}
+	return &chaosWebhookAPI{webhookAPI: api, chaos: c}
+}
+
+// chaosWebhookAPI fails webhook message edits; posts and deletes go to the real API
+type chaosWebhookAPI struct {
+	webhookAPI
+	chaos *chaosInjector
+}
+
+// WebhookMessageEdit implements webhookAPI
+func (w *chaosWebhookAPI) WebhookMessageEdit(webhookID, token, messageID string, data *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if w.chaos.roll(w.chaos.discordErrorRate) {
+		return nil, w.chaos.discordError()
+	}
+	return w.webhookAPI.WebhookMessageEdit(webhookID, token, messageID, data, options...)
+}
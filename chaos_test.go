@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// newTestChaos returns an injector with a fixed seed
+func newTestChaos(c *chaosInjector) *chaosInjector {
+	c.rng = rand.New(rand.NewPCG(1, 1))
+	if c.discordStatus == 0 {
+		c.discordStatus = http.StatusInternalServerError
+	}
+	return c
+}
+
+// TestChaosFromEnv tests the master switch, defaults and rejected settings
+func TestChaosFromEnv(t *testing.T) {
+	t.Setenv("CHAOS_POLL_FAILURE_RATE", "0.5")
+	if c, err := chaosFromEnv(); c != nil || err != nil {
+		t.Errorf("Expected chaos off without CHAOS_ENABLED, got %v (%v)", c, err)
+	}
+
+	t.Setenv("CHAOS_ENABLED", "true")
+	t.Setenv("CHAOS_DISCORD_ERROR_RATE", "1")
+	t.Setenv("CHAOS_SEED", "42")
+	c, err := chaosFromEnv()
+	if err != nil {
+		t.Fatalf("chaosFromEnv failed: %v", err)
+	}
+	if c.pollFailureRate != 0.5 || c.discordErrorRate != 1 || c.pollDelay != defaultChaosPollDelay || c.discordStatus != http.StatusInternalServerError {
+		t.Errorf("Unexpected settings %+v", c)
+	}
+
+	for name, bad := range map[string]string{
+		"CHAOS_POLL_SLOW_RATE":       "1.5",
+		"CHAOS_POLL_FAILURE_RATE":    "often",
+		"CHAOS_POLL_DELAY":           "0s",
+		"CHAOS_DISCORD_ERROR_STATUS": "200",
+		"CHAOS_SEED":                 "-1",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, bad)
+			if _, err := chaosFromEnv(); err == nil {
+				t.Errorf("Expected error for %s=%s", name, bad)
+			}
+		})
+	}
+}
+
+// TestChaosTransport tests injected poll failures and delays, and pass-through when off
+func TestChaosTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	get := func(c *chaosInjector, ctx context.Context) error {
+		client := &http.Client{Transport: c.wrapTransport(http.DefaultTransport)}
+		req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	failing := newTestChaos(&chaosInjector{pollFailureRate: 1})
+	if err := get(failing, context.Background()); !errors.Is(err, errChaosPoll) {
+		t.Errorf("Expected an injected failure, got %v", err)
+	}
+	if failing.pollFailures.Load() != 1 {
+		t.Errorf("Expected 1 counted failure, got %d", failing.pollFailures.Load())
+	}
+
+	slow := newTestChaos(&chaosInjector{pollSlowRate: 1, pollDelay: 20 * time.Millisecond})
+	start := time.Now()
+	if err := get(slow, context.Background()); err != nil || time.Since(start) < 20*time.Millisecond {
+		t.Errorf("Expected a delayed successful poll, got %v after %v", err, time.Since(start))
+	}
+	slow.pollDelay = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := get(slow, ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the poll timeout to cut the delay short, got %v", err)
+	}
+
+	if err := get(newTestChaos(&chaosInjector{}), context.Background()); err != nil {
+		t.Errorf("Expected pass-through at rate 0, got %v", err)
+	}
+	var off *chaosInjector
+	if off.wrapTransport(http.DefaultTransport) != http.DefaultTransport {
+		t.Error("Expected a nil injector to leave the transport unchanged")
+	}
+}
+
+// TestChaosSession_EditErrors tests that injected edit errors classify like real Discord errors
+func TestChaosSession_EditErrors(t *testing.T) {
+	f := newFakeDiscord()
+	msg := f.seed(f.BotUser(), "status")
+	edit := discordgo.NewMessageEdit(fakeChannelID, msg.ID).SetContent("edited")
+
+	for status, want := range map[int]discordErrorKind{
+		http.StatusInternalServerError: discordErrServer,
+		http.StatusTooManyRequests:     discordErrRateLimited,
+		http.StatusForbidden:           discordErrPermission,
+	} {
+		session := newTestChaos(&chaosInjector{discordErrorRate: 1, discordStatus: status}).wrapSession(f)
+		_, err := session.ChannelMessageEditComplex(edit)
+		if kind := classifyDiscordError(err); kind != want {
+			t.Errorf("HTTP %d: expected %v, got %v (%v)", status, want, kind, err)
+		}
+	}
+	if f.count("ChannelMessageEditComplex") != 0 {
+		t.Error("Expected injected errors not to reach Discord")
+	}
+
+	if newTestChaos(&chaosInjector{}).wrapSession(f) != DiscordSession(f) {
+		t.Error("Expected the session unchanged without a Discord error rate")
+	}
+}
+
+// TestChaosSession_PermissionAlertDebounced tests the alert path end to end with injected 403s
+func TestChaosSession_PermissionAlertDebounced(t *testing.T) {
+	f := newFakeDiscord()
+	b := newFakeDiscordBot(t, f, testStatusConfig())
+	b.tryPerformUpdate()
+
+	chaos := newTestChaos(&chaosInjector{discordErrorRate: 1, discordStatus: http.StatusForbidden})
+	b.discord.session = chaos.wrapSession(f)
+	for i := 0; i < 3; i++ {
+		b.tryPerformUpdate()
+	}
+	if got := chaos.discordErrors.Load(); got != 3 {
+		t.Errorf("Expected 3 injected errors, got %d", got)
+	}
+	if got := f.count("ChannelMessageSend"); got != 1 {
+		t.Errorf("Expected one alert for repeated injected 403s, got %d", got)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= STATUS MESSAGE CLEANUP =================
+
+// statusEmbedMarker is appended to the footer of every status embed posted through the bot session
+// It is invisible (U+2063) and tells status messages apart from alerts and announcements
+const statusEmbedMarker = "\u2063"
+
+// cleanupMaxPages bounds the history scanned at startup (100 messages per page)
+const cleanupMaxPages = 10
+
+// markStatusEmbed returns a copy of embed whose footer carries statusEmbedMarker
+func markStatusEmbed(embed *discordgo.MessageEmbed) *discordgo.MessageEmbed {
+	marked := *embed
+	footer := discordgo.MessageEmbedFooter{}
+	if embed.Footer != nil {
+		footer = *embed.Footer
+	}
+	footer.Text += statusEmbedMarker
+	marked.Footer = &footer
+	return &marked
+}
+
+// isStatusMessage reports whether msg is an unpinned status message posted by the bot
+// Messages from versions without the marker are recognized by title and footer
+func isStatusMessage(msg *discordgo.Message, botUserID string) bool {
+	if msg.Author == nil || msg.Author.ID != botUserID || msg.Pinned {
+		return false
+	}
+	for _, e := range msg.Embeds {
+		if e.Footer == nil {
+			continue
+		}
+		if strings.HasSuffix(e.Footer.Text, statusEmbedMarker) {
+			return true
+		}
+		if e.Title == defaultStatusTitle && strings.HasPrefix(e.Footer.Text, "Updates every") {
+			return true
+		}
+	}
+	return false
+}
+
+// messagePage fetches up to 100 messages older than before ("" = newest), newest first
+type messagePage func(before string) ([]*discordgo.Message, error)
+
+// findStatusMessages scans up to cleanupMaxPages pages of history for status messages, newest first
+func findStatusMessages(fetch messagePage, botUserID string) ([]*discordgo.Message, error) {
+	var found []*discordgo.Message
+	before := ""
+	for page := 0; page < cleanupMaxPages; page++ {
+		messages, err := fetch(before)
+		if err != nil {
+			return found, fmt.Errorf("failed to fetch messages: %w", err)
+		}
+		for _, msg := range messages {
+			if isStatusMessage(msg, botUserID) {
+				found = append(found, msg)
+			}
+		}
+		if len(messages) < 100 {
+			return found, nil
+		}
+		before = messages[len(messages)-1].ID
+	}
+	log.Printf("Status message scan stopped after %d messages of history", cleanupMaxPages*100)
+	return found, nil
+}
+
+// cleanupEnabled reports whether old status messages may be deleted in the current channel
+func (d *DiscordPublisher) cleanupEnabled() bool {
+	return d.cleanupChannels["*"] || d.cleanupChannels[d.channel()]
+}
+
+// cleanupOldMessages prepares the channel at startup (gateway only: needs the bot user ID)
+// With cleanup enabled for the channel, every previous status message is deleted and a new one is posted;
+// otherwise the newest status message (all its pages) is adopted and edited. Pinned messages and alerts are never touched
+func (d *DiscordPublisher) cleanupOldMessages() error {
+	channelID := d.channel()
+	fetch := func(before string) ([]*discordgo.Message, error) {
+		return d.session.ChannelMessages(channelID, 100, before, "", "")
+	}
+	user := d.session.BotUser()
+	if user == nil {
+		return fmt.Errorf("bot user unknown before Ready")
+	}
+	messages, err := findStatusMessages(fetch, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if !d.cleanupEnabled() {
+		if group := latestStatusGroup(messages); len(group) > 0 {
+			ids := make([]string, len(group))
+			for i, msg := range group {
+				ids[i] = msg.ID
+			}
+			d.setStatusMessages(ids)
+			log.Printf("Reusing status message %s (%d pages, cleanup not enabled for channel %s)", ids[0], len(ids), channelID)
+		}
+		return nil
+	}
+
+	deletedCount := 0
+	for _, msg := range messages {
+		if err := d.session.ChannelMessageDelete(channelID, msg.ID); err != nil {
+			log.Printf("Failed to delete message %s: %v", msg.ID, err)
+		} else {
+			deletedCount++
+		}
+	}
+	d.setStatusMessages(nil)
+	log.Printf("Cleaned up %d old status messages", deletedCount)
+	return nil
+}
+
+// cleanupChannelsFromEnv reads CLEANUP_CHANNEL_IDS: channel IDs (comma-separated, or "*") where old status
+// messages are deleted at startup. Unset = no deletion; the newest status message is reused instead
+func cleanupChannelsFromEnv() map[string]bool {
+	channels := map[string]bool{}
+	for _, id := range strings.Split(os.Getenv("CLEANUP_CHANNEL_IDS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			channels[id] = true
+		}
+	}
+	return channels
+}
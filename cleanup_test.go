@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// statusMsg builds a bot message carrying a marked status embed
+func statusMsg(id string) *discordgo.Message {
+	embed := markStatusEmbed(&discordgo.MessageEmbed{Title: "Custom title", Footer: &discordgo.MessageEmbedFooter{Text: "Updates every 30 seconds"}})
+	return &discordgo.Message{ID: id, Author: &discordgo.User{ID: "bot"}, Embeds: []*discordgo.MessageEmbed{embed}}
+}
+
+// TestMarkStatusEmbed tests that the marker is added to a copy
+func TestMarkStatusEmbed(t *testing.T) {
+	orig := &discordgo.MessageEmbed{Footer: &discordgo.MessageEmbedFooter{Text: "Updates every 30 seconds"}}
+	marked := markStatusEmbed(orig)
+	if marked.Footer.Text != "Updates every 30 seconds"+statusEmbedMarker {
+		t.Errorf("Unexpected footer %q", marked.Footer.Text)
+	}
+	if orig.Footer.Text != "Updates every 30 seconds" {
+		t.Error("Original embed was modified")
+	}
+	if markStatusEmbed(&discordgo.MessageEmbed{}).Footer.Text != statusEmbedMarker {
+		t.Error("Expected a footer to be added to embeds without one")
+	}
+}
+
+// TestIsStatusMessage tests that only unpinned bot status messages qualify
+func TestIsStatusMessage(t *testing.T) {
+	pinned := statusMsg("2")
+	pinned.Pinned = true
+	foreign := statusMsg("3")
+	foreign.Author = &discordgo.User{ID: "someone"}
+	legacy := &discordgo.Message{ID: "4", Author: &discordgo.User{ID: "bot"}, Embeds: []*discordgo.MessageEmbed{
+		{Title: defaultStatusTitle, Footer: &discordgo.MessageEmbedFooter{Text: "Updates every 30 seconds"}},
+	}}
+	alert := &discordgo.Message{ID: "5", Author: &discordgo.User{ID: "bot"}, Content: "Server down"}
+	announcement := &discordgo.Message{ID: "6", Author: &discordgo.User{ID: "bot"}, Embeds: []*discordgo.MessageEmbed{
+		{Title: "Season 3 starts Friday", Footer: &discordgo.MessageEmbedFooter{Text: "League"}},
+	}}
+
+	tests := []struct {
+		name string
+		msg  *discordgo.Message
+		want bool
+	}{
+		{"Marked status", statusMsg("1"), true},
+		{"Pinned status", pinned, false},
+		{"Other author", foreign, false},
+		{"Legacy status without marker", legacy, true},
+		{"Alert", alert, false},
+		{"Announcement embed", announcement, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStatusMessage(tt.msg, "bot"); got != tt.want {
+				t.Errorf("Got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFindStatusMessages_Paginates tests scanning across pages until a short page
+func TestFindStatusMessages_Paginates(t *testing.T) {
+	// 250 messages, newest first; every 50th is a status message
+	var history []*discordgo.Message
+	for i := 250; i > 0; i-- {
+		id := fmt.Sprintf("%d", i)
+		if i%50 == 0 {
+			history = append(history, statusMsg(id))
+		} else {
+			history = append(history, &discordgo.Message{ID: id, Author: &discordgo.User{ID: "user"}})
+		}
+	}
+	var befores []string
+	fetch := func(before string) ([]*discordgo.Message, error) {
+		befores = append(befores, before)
+		start := 0
+		if before != "" {
+			for i, m := range history {
+				if m.ID == before {
+					start = i + 1
+				}
+			}
+		}
+		end := start + 100
+		if end > len(history) {
+			end = len(history)
+		}
+		return history[start:end], nil
+	}
+
+	found, err := findStatusMessages(fetch, "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(found) != 5 || found[0].ID != "250" || found[4].ID != "50" {
+		t.Errorf("Expected 5 status messages newest first, got %d", len(found))
+	}
+	if len(befores) != 3 || befores[0] != "" || befores[1] != "151" || befores[2] != "51" {
+		t.Errorf("Unexpected page cursors %v", befores)
+	}
+}
+
+// TestFindStatusMessages_PageLimit tests that the scan stops after cleanupMaxPages
+func TestFindStatusMessages_PageLimit(t *testing.T) {
+	pages := 0
+	fetch := func(before string) ([]*discordgo.Message, error) {
+		pages++
+		page := make([]*discordgo.Message, 100)
+		for i := range page {
+			page[i] = &discordgo.Message{ID: fmt.Sprintf("%d-%d", pages, i), Author: &discordgo.User{ID: "user"}}
+		}
+		return page, nil
+	}
+	if _, err := findStatusMessages(fetch, "bot"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pages != cleanupMaxPages {
+		t.Errorf("Expected %d pages, got %d", cleanupMaxPages, pages)
+	}
+}
+
+// TestFindStatusMessages_Error tests that fetch errors are returned
+func TestFindStatusMessages_Error(t *testing.T) {
+	fetch := func(before string) ([]*discordgo.Message, error) { return nil, errors.New("403") }
+	if _, err := findStatusMessages(fetch, "bot"); err == nil {
+		t.Error("Expected fetch error")
+	}
+}
+
+// TestCleanupChannels tests opt-in per channel and the wildcard
+func TestCleanupChannels(t *testing.T) {
+	t.Setenv("CLEANUP_CHANNEL_IDS", "")
+	d := &DiscordPublisher{channelID: "42", cleanupChannels: cleanupChannelsFromEnv()}
+	if d.cleanupEnabled() {
+		t.Error("Expected cleanup disabled by default")
+	}
+
+	t.Setenv("CLEANUP_CHANNEL_IDS", " 41, 42 ")
+	d.cleanupChannels = cleanupChannelsFromEnv()
+	if !d.cleanupEnabled() {
+		t.Error("Expected cleanup enabled for listed channel")
+	}
+
+	t.Setenv("CLEANUP_CHANNEL_IDS", "*")
+	d.cleanupChannels = cleanupChannelsFromEnv()
+	d.channelID = "99"
+	if !d.cleanupEnabled() {
+		t.Error("Expected wildcard to enable cleanup everywhere")
+	}
+}
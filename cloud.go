@@ -0,0 +1,397 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/supervisor"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= CLOUD INSTANCES =================
+
+// Cloud instance states
+const (
+	cloudStopped  = "stopped"
+	cloudStarting = "starting"
+	cloudRunning  = "running"
+	cloudStopping = "stopping"
+)
+
+const (
+	// defaultCloudIdleMinutes is how long a server may stay empty before its instance is stopped
+	defaultCloudIdleMinutes = 30
+	// defaultCloudBootMinutes is how long a started instance may take to answer polls (and a stopped one to stop)
+	defaultCloudBootMinutes = 10
+	// cloudHookTimeout bounds one start or stop hook
+	cloudHookTimeout = 30 * time.Second
+	// cloudButtonPrefix starts the custom ID of "Request server" buttons ("cloud_start:<server>")
+	cloudButtonPrefix = "cloud_start"
+)
+
+// CloudConfig starts servers on demand and stops them when empty (cloud_instances in config.json)
+type CloudConfig struct {
+	IdleMinutes int                      `json:"idle_minutes,omitempty"` // stop after the server was empty this long (default 30)
+	BootMinutes int                      `json:"boot_minutes,omitempty"` // give up waiting for a start or stop after this long (default 10)
+	Servers     map[string]CloudInstance `json:"servers"`                // keyed by server name
+}
+
+// CloudInstance is the hooks of one server's VM
+// Both receive POST {"server", "action", "requested_by"}; anything that answers 2xx counts as accepted
+type CloudInstance struct {
+	StartURL string `json:"start_url"`
+	StopURL  string `json:"stop_url"`
+}
+
+// cloneCloud deep-copies the cloud section (nil for nil)
+func cloneCloud(c *CloudConfig) *CloudConfig {
+	if c == nil {
+		return nil
+	}
+	out := *c
+	out.Servers = maps.Clone(c.Servers)
+	return &out
+}
+
+// idle returns how long a server may stay empty
+func (c *CloudConfig) idle() time.Duration {
+	if c.IdleMinutes > 0 {
+		return time.Duration(c.IdleMinutes) * time.Minute
+	}
+	return defaultCloudIdleMinutes * time.Minute
+}
+
+// boot returns how long a start or stop may take
+func (c *CloudConfig) boot() time.Duration {
+	if c.BootMinutes > 0 {
+		return time.Duration(c.BootMinutes) * time.Minute
+	}
+	return defaultCloudBootMinutes * time.Minute
+}
+
+// validateCloud checks the cloud section: servers exist, hooks are https (http on loopback only), limits are sane
+func validateCloud(c *CloudConfig, servers []Server) error {
+	if c == nil {
+		return nil
+	}
+	if c.IdleMinutes < 0 || c.BootMinutes < 0 {
+		return fmt.Errorf("cloud idle_minutes and boot_minutes must not be negative")
+	}
+	if len(c.Servers) > maxStatusButtons {
+		return fmt.Errorf("cloud_instances has %d servers (max %d, one button each)", len(c.Servers), maxStatusButtons)
+	}
+	serverNames := make(map[string]bool, len(servers))
+	for _, s := range servers {
+		serverNames[s.Name] = true
+	}
+	for _, server := range slices.Sorted(maps.Keys(c.Servers)) {
+		if !serverNames[server] {
+			return fmt.Errorf("cloud for server '%s' which is not defined in servers", server)
+		}
+		if len(cloudButtonPrefix)+1+len(server) > 100 {
+			return fmt.Errorf("cloud for server '%s': the name is too long for a button (max %d bytes)", server, 100-len(cloudButtonPrefix)-1)
+		}
+		inst := c.Servers[server]
+		for field, hook := range map[string]string{"start_url": inst.StartURL, "stop_url": inst.StopURL} {
+			u, err := url.Parse(hook)
+			if err != nil || u.Host == "" || (u.Scheme != "https" && !(u.Scheme == "http" && isLoopbackHost(u.Hostname()))) {
+				return fmt.Errorf("cloud for server '%s': %s must be an https:// URL (http is allowed on localhost only)", server, field)
+			}
+		}
+	}
+	return nil
+}
+
+// cloudInstanceState is where the state machine of one server stands
+type cloudInstanceState struct {
+	state string
+	since time.Time // when the state was entered
+	// emptySince is when a running server was first seen without players (zero = players online)
+	emptySince time.Time
+	by         string // who requested the start
+	// planned is set while the server is down on purpose (idle stop, failed start): no outage alerts
+	planned bool
+	err     string // last hook failure, shown until the next transition
+}
+
+// cloudControl runs the instance state machine after every poll and calls the start and stop hooks
+// stopped --button--> starting --answers polls--> running --empty for idle--> stopping --stops answering--> stopped
+// Hooks run in the background; the state is only in memory and rebuilt from the first poll after a restart
+type cloudControl struct {
+	client *http.Client
+	token  string // Bearer token sent to the hooks ("" = none)
+
+	mu        sync.Mutex
+	instances map[string]*cloudInstanceState // by server name
+
+	// running tracks background hooks (tests wait on it)
+	running sync.WaitGroup
+}
+
+// cloudControlFromEnv returns the control if CLOUD_ENABLED is true, nil otherwise
+// CLOUD_HOOK_TOKEN is sent as Bearer token to the start and stop hooks
+func cloudControlFromEnv() *cloudControl {
+	if os.Getenv("CLOUD_ENABLED") != "true" {
+		return nil
+	}
+	log.Printf("Cloud instance hooks enabled")
+	return newCloudControl(os.Getenv("CLOUD_HOOK_TOKEN"))
+}
+
+func newCloudControl(token string) *cloudControl {
+	return &cloudControl{
+		client:    &http.Client{Timeout: cloudHookTimeout},
+		token:     token,
+		instances: make(map[string]*cloudInstanceState),
+	}
+}
+
+// observe advances the state machine of every cloud server with a poll result and forgets removed servers
+func (c *cloudControl) observe(cfg *Config, infos []ServerInfo, now time.Time) {
+	var stops []string
+	c.mu.Lock()
+	managed := make(map[string]bool)
+	for _, info := range infos {
+		if cfg.CloudInstances == nil {
+			break
+		}
+		if _, ok := cfg.CloudInstances.Servers[info.Name]; !ok || managed[info.Name] {
+			continue
+		}
+		managed[info.Name] = true
+		if c.step(cfg.CloudInstances, info, now) {
+			stops = append(stops, info.Name)
+		}
+	}
+	for server := range c.instances {
+		if !managed[server] {
+			delete(c.instances, server)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, server := range stops {
+		c.hook(cfg, server, powerStop, "idle timer")
+	}
+}
+
+// step moves one server's state with its poll result; it reports whether the instance should be stopped now
+// Must be called with c.mu held
+func (c *cloudControl) step(cc *CloudConfig, info ServerInfo, now time.Time) bool {
+	up := !serverDown(info)
+	inst := c.instances[info.Name]
+	if inst == nil {
+		// First poll (or a restart of the bot): take the server as it is
+		inst = &cloudInstanceState{state: cloudStopped, since: now, planned: true}
+		if up {
+			inst.state, inst.planned = cloudRunning, false
+		}
+		c.instances[info.Name] = inst
+	}
+
+	switch inst.state {
+	case cloudStopped:
+		if up {
+			log.Printf("Cloud: '%s' is online", info.Name)
+			*inst = cloudInstanceState{state: cloudRunning, since: now}
+		}
+	case cloudStarting:
+		if up {
+			log.Printf("Cloud: '%s' came online after %s", info.Name, now.Sub(inst.since).Round(time.Second))
+			*inst = cloudInstanceState{state: cloudRunning, since: now}
+		} else if now.Sub(inst.since) > cc.boot() {
+			log.Printf("Cloud: '%s' did not come online within %s of its start", info.Name, cc.boot())
+			*inst = cloudInstanceState{state: cloudStopped, since: now, planned: true, err: "did not come online"}
+		}
+	case cloudStopping:
+		if !up {
+			log.Printf("Cloud: '%s' stopped", info.Name)
+			*inst = cloudInstanceState{state: cloudStopped, since: now, planned: true}
+		} else if now.Sub(inst.since) > cc.boot() {
+			log.Printf("Cloud: '%s' still answers %s after its stop", info.Name, cc.boot())
+			*inst = cloudInstanceState{state: cloudRunning, since: now, emptySince: now, err: "did not stop"}
+		}
+	case cloudRunning:
+		if !up {
+			// Not stopped by the bot: an outage, alerted as usual
+			*inst = cloudInstanceState{state: cloudStopped, since: now}
+			return false
+		}
+		if info.NumPlayers > 0 || info.Maintenance != nil {
+			inst.emptySince = time.Time{}
+			return false
+		}
+		if inst.emptySince.IsZero() {
+			inst.emptySince = now
+		}
+		if now.Sub(inst.emptySince) >= cc.idle() {
+			log.Printf("Cloud: stopping '%s' after %s without players", info.Name, now.Sub(inst.emptySince).Round(time.Minute))
+			*inst = cloudInstanceState{state: cloudStopping, since: now, planned: true}
+			return true
+		}
+	}
+	return false
+}
+
+// request starts the instance of a stopped server for a member
+func (c *cloudControl) request(cfg *Config, server, by string, now time.Time) error {
+	if cfg == nil || cfg.CloudInstances == nil {
+		return fmt.Errorf("**%s** is not a cloud server", server)
+	}
+	if _, ok := cfg.CloudInstances.Servers[server]; !ok {
+		return fmt.Errorf("**%s** is not a cloud server", server)
+	}
+	c.mu.Lock()
+	inst := c.instances[server]
+	if inst == nil || inst.state != cloudStopped {
+		c.mu.Unlock()
+		state := "not polled yet"
+		if inst != nil {
+			state = inst.state
+		}
+		return fmt.Errorf("**%s** is %s", server, state)
+	}
+	*inst = cloudInstanceState{state: cloudStarting, since: now, by: by, planned: true}
+	c.mu.Unlock()
+
+	log.Printf("Audit: %s requested a start of cloud server '%s'", by, server)
+	c.hook(cfg, server, powerStart, by)
+	return nil
+}
+
+// hook calls the start or stop hook of server in the background
+// A failed start returns the server to stopped, a failed stop to running (the idle timer starts over)
+func (c *cloudControl) hook(cfg *Config, server, action, by string) {
+	inst := cfg.CloudInstances.Servers[server]
+	hookURL := inst.StartURL
+	if action == powerStop {
+		hookURL = inst.StopURL
+	}
+	c.running.Add(1)
+	go func() {
+		defer c.running.Done()
+		defer supervisor.Recover(fmt.Sprintf("cloud %s '%s'", action, server), log.Default())
+		err := c.post(hookURL, server, action, by)
+		if err == nil {
+			return
+		}
+		log.Printf("Error calling the cloud %s hook of '%s': %v", action, server, err)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		st := c.instances[server]
+		if st == nil {
+			return
+		}
+		now := time.Now()
+		switch {
+		case action == powerStart && st.state == cloudStarting:
+			*st = cloudInstanceState{state: cloudStopped, since: now, planned: true, err: "start failed"}
+		case action == powerStop && st.state == cloudStopping:
+			*st = cloudInstanceState{state: cloudRunning, since: now, emptySince: now, err: "stop failed"}
+		}
+	}()
+}
+
+// post sends one hook request
+func (c *cloudControl) post(hookURL, server, action, by string) error {
+	body, _ := json.Marshal(map[string]string{"server": server, "action": action, "requested_by": by})
+	ctx, cancel := context.WithTimeout(context.Background(), cloudHookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("hook request failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("hook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// apply marks cloud servers that are down on purpose and shows states other than running in the embed
+func (c *cloudControl) apply(infos []ServerInfo) []ServerInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range infos {
+		info := &infos[i]
+		inst := c.instances[info.Name]
+		if inst == nil {
+			continue
+		}
+		info.CloudPlanned = inst.planned
+		if info.Power != nil || (inst.state == cloudRunning && inst.err == "") {
+			continue
+		}
+		state := inst.state
+		switch {
+		case inst.err != "":
+			state += " (" + inst.err + ")"
+		case inst.state == cloudStarting:
+			state += " (requested by " + inst.by + ")"
+		case inst.state == cloudStopped:
+			state += " (press Request to start it)"
+		}
+		info.Power = &serverPower{label: "Instance", state: state}
+	}
+	return infos
+}
+
+// buttons returns a "Request" button for every stopped cloud server, in config order
+func (c *cloudControl) buttons(cfg *Config) []discordgo.Button {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var names []string
+	for _, s := range cfg.Servers {
+		if inst := c.instances[s.Name]; inst != nil && inst.state == cloudStopped && !slices.Contains(names, s.Name) {
+			names = append(names, s.Name)
+		}
+	}
+	buttons := make([]discordgo.Button, 0, len(names))
+	for _, name := range names {
+		buttons = append(buttons, discordgo.Button{
+			Label:    truncateRunes("Request "+name, maxButtonLabel),
+			Style:    discordgo.SecondaryButton,
+			CustomID: cloudButtonPrefix + ":" + name,
+			Emoji:    &discordgo.ComponentEmoji{Name: "▶️"},
+		})
+	}
+	return buttons
+}
+
+// trackCloud advances the cloud state machine after a poll and shows it in the embed (no-op when disabled)
+func (b *Bot) trackCloud(infos []ServerInfo, cfg *Config) []ServerInfo {
+	if b.cloud == nil {
+		return infos
+	}
+	b.cloud.observe(cfg, infos, time.Now())
+	return b.cloud.apply(infos)
+}
+
+// runCloudStartButton handles a click on "Request <server>"
+func runCloudStartButton(b *Bot, user invoker, server string) string {
+	if b.cloud == nil {
+		return "❌ Cloud servers are disabled"
+	}
+	if err := b.cloud.request(b.configManager.GetConfig(), server, user.name, time.Now()); err != nil {
+		return "❌ " + err.Error()
+	}
+	return fmt.Sprintf("⏳ Starting **%s**; it shows online once it answers (usually a few minutes)", server)
+}
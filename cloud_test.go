@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeCloudHooks records hook calls ("start Track #1 by alice") and answers with status
+type fakeCloudHooks struct {
+	mu     sync.Mutex
+	status int
+	calls  []string
+	auth   []string
+}
+
+func (f *fakeCloudHooks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]string
+	json.NewDecoder(r.Body).Decode(&body)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, r.URL.Path+" "+body["action"]+" "+body["server"]+" by "+body["requested_by"])
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+	}
+}
+
+func (f *fakeCloudHooks) called() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return strings.Join(f.calls, "\n")
+}
+
+// testCloudBot makes "Track #1" a cloud server with hooks on url, idle after 30 and boot within 10 minutes
+func testCloudBot(url string) *Bot {
+	cfg := testMaintenanceConfig()
+	cfg.CloudInstances = &CloudConfig{Servers: map[string]CloudInstance{"Track #1": {StartURL: url + "/start", StopURL: url + "/stop"}}}
+	b := newTestBot(cfg)
+	b.cloud = newCloudControl("hook-token")
+	return b
+}
+
+// cloudPoll is one poll result: Drift #1 online, Track #1 with players (-1 = offline)
+func cloudPoll(players int) []ServerInfo {
+	return []ServerInfo{
+		{Name: "Drift #1", Category: "Drift", NumPlayers: 3},
+		{Name: "Track #1", Category: "Track", NumPlayers: players},
+	}
+}
+
+// TestCloudStateMachine tests the request button, boot timeout, idle stop and the hooks along the way
+func TestCloudStateMachine(t *testing.T) {
+	hooks := &fakeCloudHooks{}
+	srv := httptest.NewServer(hooks)
+	defer srv.Close()
+	b := testCloudBot(srv.URL)
+	cfg := b.configManager.GetConfig()
+	c := b.cloud
+	start := time.Now()
+
+	// Offline at startup: stopped on purpose, with a button
+	c.observe(cfg, cloudPoll(-1), start)
+	infos := c.apply(cloudPoll(-1))
+	if !infos[1].CloudPlanned || infos[1].Power == nil || !strings.HasPrefix(infos[1].Power.state, "stopped") || infos[0].Power != nil {
+		t.Fatalf("Expected Track #1 parked, got %+v (power %+v)", infos[1], infos[1].Power)
+	}
+	if buttons := c.buttons(cfg); len(buttons) != 1 || buttons[0].CustomID != "cloud_start:Track #1" {
+		t.Fatalf("Expected one Request button, got %+v", buttons)
+	}
+
+	// Request: starting until it answers, and only once
+	if reply := runCloudStartButton(b, invoker{name: "alice"}, "Track #1"); !strings.Contains(reply, "Starting") {
+		t.Fatalf("Unexpected reply %q", reply)
+	}
+	c.running.Wait()
+	if reply := runCloudStartButton(b, invoker{name: "bob"}, "Track #1"); !strings.Contains(reply, "is starting") {
+		t.Errorf("Expected a second request to be refused, got %q", reply)
+	}
+	if reply := runCloudStartButton(b, invoker{name: "bob"}, "Drift #1"); !strings.Contains(reply, "not a cloud server") {
+		t.Errorf("Expected a request for a non-cloud server to be refused, got %q", reply)
+	}
+	if got := hooks.called(); got != "/start start Track #1 by alice" || hooks.auth[0] != "Bearer hook-token" {
+		t.Errorf("Hooks = %q (auth %v)", got, hooks.auth)
+	}
+	if buttons := c.buttons(cfg); len(buttons) != 0 {
+		t.Errorf("Expected no button while starting, got %+v", buttons)
+	}
+
+	// Not online within boot_minutes: back to stopped
+	c.observe(cfg, cloudPoll(-1), start.Add(5*time.Minute))
+	if st := c.instances["Track #1"].state; st != cloudStarting {
+		t.Errorf("Expected starting after 5 minutes, got %s", st)
+	}
+	c.observe(cfg, cloudPoll(-1), start.Add(11*time.Minute))
+	if infos := c.apply(cloudPoll(-1)); infos[1].Power.state != "stopped (did not come online)" {
+		t.Errorf("Expected the boot timeout in the embed, got %+v", infos[1].Power)
+	}
+
+	// Started again, online and empty: stopped after idle_minutes, then parked once it stops answering
+	if err := c.request(cfg, "Track #1", "alice", start.Add(12*time.Minute)); err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	c.running.Wait()
+	online := start.Add(15 * time.Minute)
+	c.observe(cfg, cloudPoll(0), online)
+	if infos := c.apply(cloudPoll(0)); infos[1].CloudPlanned || infos[1].Power != nil {
+		t.Errorf("Expected a running server to show normally, got %+v", infos[1])
+	}
+	c.observe(cfg, cloudPoll(2), online.Add(20*time.Minute))
+	c.observe(cfg, cloudPoll(0), online.Add(25*time.Minute))
+	c.observe(cfg, cloudPoll(0), online.Add(54*time.Minute))
+	if st := c.instances["Track #1"].state; st != cloudRunning {
+		t.Fatalf("Expected players to reset the idle timer, got %s", st)
+	}
+	c.observe(cfg, cloudPoll(0), online.Add(55*time.Minute))
+	c.running.Wait()
+	if st := c.instances["Track #1"].state; st != cloudStopping {
+		t.Fatalf("Expected stopping after 30 idle minutes, got %s", st)
+	}
+	if got := hooks.called(); !strings.HasSuffix(got, "/stop stop Track #1 by idle timer") {
+		t.Errorf("Hooks = %q", got)
+	}
+	c.observe(cfg, cloudPoll(-1), online.Add(57*time.Minute))
+	if infos := c.apply(cloudPoll(-1)); !infos[1].CloudPlanned || c.instances["Track #1"].state != cloudStopped {
+		t.Errorf("Expected a parked server after the stop, got %+v", c.instances["Track #1"])
+	}
+}
+
+// TestCloudUnplannedStop tests that a running server that goes down is not parked (alerts fire as usual)
+func TestCloudUnplannedStop(t *testing.T) {
+	b := testCloudBot("https://hooks.example.com")
+	cfg := b.configManager.GetConfig()
+	now := time.Now()
+	b.cloud.observe(cfg, cloudPoll(4), now)
+	b.cloud.observe(cfg, cloudPoll(-1), now.Add(time.Minute))
+	if infos := b.cloud.apply(cloudPoll(-1)); infos[1].CloudPlanned {
+		t.Error("Expected a crashed server not to be planned")
+	}
+	if st := b.cloud.instances["Track #1"].state; st != cloudStopped {
+		t.Errorf("Expected stopped, got %s", st)
+	}
+}
+
+// TestCloudHookFailure tests that a failed start hook returns the server to stopped
+func TestCloudHookFailure(t *testing.T) {
+	hooks := &fakeCloudHooks{status: http.StatusBadGateway}
+	srv := httptest.NewServer(hooks)
+	defer srv.Close()
+	b := testCloudBot(srv.URL)
+	cfg := b.configManager.GetConfig()
+	now := time.Now()
+
+	b.cloud.observe(cfg, cloudPoll(-1), now)
+	if err := b.cloud.request(cfg, "Track #1", "alice", now); err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	b.cloud.running.Wait()
+	if st := b.cloud.instances["Track #1"]; st.state != cloudStopped || st.err != "start failed" {
+		t.Errorf("Expected stopped after a failed hook, got %+v", st)
+	}
+}
+
+// TestCloudPlannedNoAlerts tests that parked cloud servers raise no outage alerts
+func TestCloudPlannedNoAlerts(t *testing.T) {
+	var r alertRouter
+	now := time.Now()
+	up := ServerInfo{Name: "Track #1", Port: 8082, NumPlayers: 0}
+	parked := ServerInfo{Name: "Track #1", Port: 8082, NumPlayers: -1, CloudPlanned: true}
+	r.trackOutages([]ServerInfo{up}, nil, now)
+	if events, _ := r.trackOutages([]ServerInfo{parked}, nil, now.Add(time.Minute)); len(events) != 0 {
+		t.Errorf("Expected no alert for a parked server, got %+v", events)
+	}
+}
+
+// TestCloudButtonsOnStatusMessage tests that buttons go on the last page and are removed with an empty list
+func TestCloudButtonsOnStatusMessage(t *testing.T) {
+	f := newFakeDiscord()
+	d := NewDiscordPublisher(f, fakeChannelID)
+	button := discordgo.ActionsRow{Components: []discordgo.MessageComponent{discordgo.Button{Label: "Request Track #1", CustomID: "cloud_start:Track #1"}}}
+	embed := &discordgo.MessageEmbed{Title: "Status"}
+
+	if err := d.UpdateStatus(&StatusUpdate{Embed: embed, Components: []discordgo.MessageComponent{button}}); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	msgs := f.channelMessages(fakeChannelID)
+	if len(msgs) != 1 || len(msgs[0].Components) != 1 {
+		t.Fatalf("Expected the button on the status message, got %+v", msgs)
+	}
+	if err := d.UpdateStatus(&StatusUpdate{Embed: embed}); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if msgs := f.channelMessages(fakeChannelID); len(msgs[0].Components) != 1 {
+		t.Error("Expected nil components to leave the button")
+	}
+	if err := d.UpdateStatus(&StatusUpdate{Embed: embed, Components: []discordgo.MessageComponent{}}); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if msgs := f.channelMessages(fakeChannelID); len(msgs[0].Components) != 0 {
+		t.Errorf("Expected the button removed, got %+v", msgs[0].Components)
+	}
+}
+
+// TestValidateCloud tests server names, hook URLs and limits
+func TestValidateCloud(t *testing.T) {
+	servers := testMaintenanceConfig().Servers
+	valid := CloudInstance{StartURL: "https://hooks.example.com/start", StopURL: "http://localhost:9000/stop"}
+	if err := validateCloud(&CloudConfig{IdleMinutes: 15, Servers: map[string]CloudInstance{"Track #1": valid}}, servers); err != nil {
+		t.Errorf("Expected valid cloud config, got %v", err)
+	}
+	invalid := map[string]*CloudConfig{
+		"unknown server": {Servers: map[string]CloudInstance{"Rally #1": valid}},
+		"http hook":      {Servers: map[string]CloudInstance{"Track #1": {StartURL: "http://hooks.example.com/start", StopURL: valid.StopURL}}},
+		"no stop hook":   {Servers: map[string]CloudInstance{"Track #1": {StartURL: valid.StartURL}}},
+		"negative idle":  {IdleMinutes: -1},
+	}
+	for name, c := range invalid {
+		if err := validateCloud(c, servers); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
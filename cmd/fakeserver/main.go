@@ -0,0 +1,166 @@
+// Command fakeserver simulates Assetto Corsa servers answering GET /info, so the bot can be demoed and
+// tested locally without real game servers. Each simulated server listens on its own port.
+//
+// Quick start (three servers on 8081-8083 cycling through player counts):
+//
+//	go run ./cmd/fakeserver -ports 8081-8083 -players 0,4,9,16 -max 16
+//
+// Per-server settings (tracks, flapping, latency, offline behavior) come from a JSON file:
+//
+//	go run ./cmd/fakeserver -config fakeservers.json
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/fakeserver"
+)
+
+func main() {
+	var (
+		configPath = flag.String("config", "", "JSON file with per-server settings (overrides the other server flags)")
+		listen     = flag.String("listen", "127.0.0.1", "address to listen on")
+		ports      = flag.String("ports", "8081", "ports to serve, comma-separated or ranges (8081-8083)")
+		players    = flag.String("players", "0,3,8", "player counts cycled per poll, comma-separated")
+		maxPlayers = flag.Int("max", 24, "max players")
+		track      = flag.String("track", "content/tracks/ks_vallelunga", "track content path")
+		latency    = flag.Duration("latency", 0, "fixed response delay")
+		jitter     = flag.Duration("jitter", 0, "random extra delay up to this value")
+		flapUp     = flag.Duration("flap-up", 0, "online part of the flap cycle")
+		flapDown   = flag.Duration("flap-down", 0, "offline part of the flap cycle (0 = never offline)")
+		offline    = flag.String("offline", fakeserver.OfflineError, "offline behavior: error, timeout or garbage")
+		seed       = flag.Uint64("seed", 1, "seed for latency jitter")
+	)
+	flag.Parse()
+
+	cfg, err := loadSpecs(*configPath, *ports, *players, fakeserver.Spec{
+		MaxPlayers: *maxPlayers,
+		Track:      *track,
+		Latency:    fakeserver.Duration(*latency),
+		Jitter:     fakeserver.Duration(*jitter),
+		FlapUp:     fakeserver.Duration(*flapUp),
+		FlapDown:   fakeserver.Duration(*flapDown),
+		Offline:    *offline,
+	}, *seed)
+	if err != nil {
+		log.Fatalf("fakeserver configuration error: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var wg sync.WaitGroup
+	servers := make([]*http.Server, 0, len(cfg.Servers))
+	for _, spec := range cfg.Servers {
+		srv := &http.Server{
+			Addr:              net.JoinHostPort(*listen, strconv.Itoa(spec.Port)),
+			Handler:           fakeserver.New(spec, cfg.Seed),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		servers = append(servers, srv)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Printf("%s: serving http://%s/info (players %v/%d, track %s)", spec.Name, srv.Addr, spec.Players, spec.MaxPlayers, spec.Track)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("%s: %v", spec.Name, err)
+				stop()
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	log.Println("Shutting down fake servers...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, srv := range servers {
+		srv.Shutdown(shutdownCtx)
+	}
+	wg.Wait()
+}
+
+// loadSpecs reads the config file, or builds one spec per port from the flag template
+func loadSpecs(configPath, ports, players string, template fakeserver.Spec, seed uint64) (*fakeserver.File, error) {
+	if configPath != "" {
+		return fakeserver.LoadFile(configPath)
+	}
+
+	portList, err := parsePorts(ports)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := parseInts(players)
+	if err != nil {
+		return nil, fmt.Errorf("-players: %w", err)
+	}
+
+	cfg := &fakeserver.File{Seed: seed}
+	for _, port := range portList {
+		spec := template
+		spec.Port = port
+		spec.Players = counts
+		if err := spec.Validate(); err != nil {
+			return nil, fmt.Errorf("port %d: %w", port, err)
+		}
+		cfg.Servers = append(cfg.Servers, spec)
+	}
+	return cfg, nil
+}
+
+// parsePorts reads "8081,8085" and "8081-8083" style port lists
+func parsePorts(s string) ([]int, error) {
+	var ports []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", part)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(hi); err != nil || last < first {
+				return nil, fmt.Errorf("invalid port range %q", part)
+			}
+		}
+		for p := first; p <= last; p++ {
+			ports = append(ports, p)
+		}
+	}
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("no ports given")
+	}
+	return ports, nil
+}
+
+// parseInts reads a comma-separated list of integers
+func parseInts(s string) ([]int, error) {
+	var out []int
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", part)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
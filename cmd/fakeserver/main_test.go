@@ -0,0 +1,35 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bombom/absa-ac/pkg/fakeserver"
+)
+
+// TestParsePorts tests single ports, lists and ranges
+func TestParsePorts(t *testing.T) {
+	got, err := parsePorts("8081, 8085-8087")
+	if err != nil || !slices.Equal(got, []int{8081, 8085, 8086, 8087}) {
+		t.Errorf("Got %v (%v)", got, err)
+	}
+	for _, bad := range []string{"", "x", "8083-8081", "8081-y"} {
+		if _, err := parsePorts(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+// TestLoadSpecs_Flags tests building one validated spec per port from the flag template
+func TestLoadSpecs_Flags(t *testing.T) {
+	cfg, err := loadSpecs("", "9001-9002", "1,2", fakeserver.Spec{MaxPlayers: 8}, 5)
+	if err != nil {
+		t.Fatalf("loadSpecs failed: %v", err)
+	}
+	if len(cfg.Servers) != 2 || cfg.Seed != 5 || cfg.Servers[1].Port != 9002 || cfg.Servers[1].Name != "Fake Server 9002" {
+		t.Errorf("Unexpected specs %+v", cfg)
+	}
+	if _, err := loadSpecs("", "9001", "9", fakeserver.Spec{MaxPlayers: 8}, 1); err == nil {
+		t.Error("Expected error for players above max")
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/bombom/absa-ac/pkg/supervisor"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= SLASH COMMANDS =================
+
+// slashCommand is an application command registered in the status channel's guild when the gateway is ready
+// Commands need no gateway intent; the bot must be invited with the applications.commands scope
+type slashCommand struct {
+	def *discordgo.ApplicationCommand
+	// run handles an invocation and returns the reply, shown only to the invoking user
+	run func(b *Bot, user invoker, opts []*discordgo.ApplicationCommandInteractionDataOption) string
+	// complete returns choices for the focused option (nil = no autocomplete)
+	complete func(b *Bot, focused *discordgo.ApplicationCommandInteractionDataOption) []*discordgo.ApplicationCommandOptionChoice
+	// enabled reports whether the command is registered (nil = always)
+	enabled func(b *Bot) bool
+}
+
+// invoker is the member who ran a slash command
+type invoker struct {
+	id    string   // Discord user ID
+	name  string   // username, for logs and "set by"
+	roles []string // role IDs in the guild
+}
+
+const (
+	// maxStatusButtons is what fits under one message (5 rows of 5 buttons)
+	maxStatusButtons = 25
+	// maxButtonLabel is Discord's limit for a button label
+	maxButtonLabel = 80
+)
+
+// buttonHandler answers a click on a button of the status message; arg is the part of the custom ID after ':'
+type buttonHandler func(b *Bot, user invoker, arg string) string
+
+// buttonHandlers returns the button handlers by custom ID prefix ("<prefix>:<arg>")
+func buttonHandlers() map[string]buttonHandler {
+	return map[string]buttonHandler{cloudButtonPrefix: runCloudStartButton, slotNotifyButtonPrefix: runSlotNotifyButton}
+}
+
+// statusComponents packs the buttons of the enabled features into rows under the status message
+// nil when no feature uses buttons (the message is left as is), empty when none is shown right now
+func (b *Bot) statusComponents(cfg *Config, infos []ServerInfo) []discordgo.MessageComponent {
+	if b.cloud == nil && b.slots == nil {
+		return nil
+	}
+	var buttons []discordgo.Button
+	if b.cloud != nil {
+		buttons = append(buttons, b.cloud.buttons(cfg)...)
+	}
+	if b.slots != nil {
+		buttons = append(buttons, b.slots.buttons(infos)...)
+	}
+	if len(buttons) > maxStatusButtons {
+		log.Printf("Warning: %d status buttons, only the first %d are shown", len(buttons), maxStatusButtons)
+		buttons = buttons[:maxStatusButtons]
+	}
+	rows := []discordgo.MessageComponent{}
+	for start := 0; start < len(buttons); start += 5 {
+		var row discordgo.ActionsRow
+		for _, button := range buttons[start:min(start+5, len(buttons))] {
+			row.Components = append(row.Components, button)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// slashCommands returns the bot's commands by name
+func slashCommands() map[string]*slashCommand {
+	commands := make(map[string]*slashCommand)
+	for _, c := range []*slashCommand{maintenanceCommand(), driverCommand(), whitelistCommand(), powerCommand(), passwordCommand()} {
+		commands[c.def.Name] = c
+	}
+	return commands
+}
+
+// registerSlashCommands replaces the bot's commands in the status channel's guild (globally if the guild is unknown)
+// Guild commands show up immediately; global ones can take up to an hour
+func (b *Bot) registerSlashCommands(s *discordgo.Session) {
+	if s == nil || s.State == nil || s.State.User == nil {
+		return
+	}
+	var defs []*discordgo.ApplicationCommand
+	for _, c := range slashCommands() {
+		if c.enabled == nil || c.enabled(b) {
+			defs = append(defs, c.def)
+		}
+	}
+	b.commandLocales.localize(defs)
+	guildID := b.discord.guild()
+	if _, err := s.ApplicationCommandBulkOverwrite(s.State.User.ID, guildID, defs); err != nil {
+		log.Printf("Warning: failed to register slash commands (invite the bot with the applications.commands scope): %v", err)
+		return
+	}
+	log.Printf("Registered %d slash command(s)", len(defs))
+}
+
+// onInteraction answers slash commands, their autocomplete requests and button clicks
+// Runtime state such as maintenance flags lives in the leader, so a standby replica leaves the interaction to it
+func (b *Bot) onInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer supervisor.Recover("slash command", log.Default())
+	if !b.isLeader() {
+		return
+	}
+	if i.Type == discordgo.InteractionMessageComponent {
+		b.onButton(s, i)
+		return
+	}
+	if i.Type != discordgo.InteractionApplicationCommand && i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		return
+	}
+	data := i.ApplicationCommandData()
+	cmd := slashCommands()[data.Name]
+	if cmd == nil || (cmd.enabled != nil && !cmd.enabled(b)) {
+		return
+	}
+
+	var resp *discordgo.InteractionResponse
+	if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		focused := focusedOption(data.Options)
+		if cmd.complete == nil || focused == nil {
+			return
+		}
+		resp = &discordgo.InteractionResponse{
+			Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+			Data: &discordgo.InteractionResponseData{Choices: cmd.complete(b, focused)},
+		}
+	} else {
+		reply := cmd.run(b, interactionUser(i), data.Options)
+		resp = &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: reply, Flags: discordgo.MessageFlagsEphemeral},
+		}
+	}
+	if err := s.InteractionRespond(i.Interaction, resp); err != nil {
+		log.Printf("Error answering /%s: %v", data.Name, err)
+	}
+}
+
+// onButton answers a button click with an ephemeral reply
+func (b *Bot) onButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.MessageComponentData()
+	prefix, arg, _ := strings.Cut(data.CustomID, ":")
+	handler := buttonHandlers()[prefix]
+	if handler == nil {
+		return
+	}
+	resp := &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: handler(b, interactionUser(i), arg), Flags: discordgo.MessageFlagsEphemeral},
+	}
+	if err := s.InteractionRespond(i.Interaction, resp); err != nil {
+		log.Printf("Error answering button %s: %v", prefix, err)
+	}
+}
+
+// interactionUser returns the invoking user
+func interactionUser(i *discordgo.InteractionCreate) invoker {
+	switch {
+	case i.Member != nil && i.Member.User != nil:
+		return invoker{id: i.Member.User.ID, name: i.Member.User.Username, roles: i.Member.Roles}
+	case i.User != nil:
+		return invoker{id: i.User.ID, name: i.User.Username}
+	}
+	return invoker{name: "unknown"}
+}
+
+// focusedOption returns the option being typed, searching subcommands (nil if none)
+func focusedOption(opts []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, o := range opts {
+		if o.Focused {
+			return o
+		}
+		if f := focusedOption(o.Options); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// optionString returns the string option name, "" if absent
+func optionString(opts []*discordgo.ApplicationCommandInteractionDataOption, name string) string {
+	for _, o := range opts {
+		if o.Name == name && o.Type == discordgo.ApplicationCommandOptionString {
+			return o.StringValue()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// TestSlashCommands tests that every command definition is named after its registry key and can run
+func TestSlashCommands(t *testing.T) {
+	for name, c := range slashCommands() {
+		if c.def.Name != name || c.def.Description == "" || c.run == nil {
+			t.Errorf("Incomplete command %q: %+v", name, c.def)
+		}
+		if c.def.DefaultMemberPermissions == nil {
+			t.Errorf("Command %q is usable by everyone; set DefaultMemberPermissions", name)
+		}
+	}
+}
+
+// TestCommandOptions tests option lookup in nested subcommands
+func TestCommandOptions(t *testing.T) {
+	opts := []*discordgo.ApplicationCommandInteractionDataOption{{
+		Name: "on", Type: discordgo.ApplicationCommandOptionSubCommand,
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "reason", Type: discordgo.ApplicationCommandOptionString, Value: "kerbs"},
+			{Name: "server", Type: discordgo.ApplicationCommandOptionString, Value: "Dri", Focused: true},
+		},
+	}}
+	if f := focusedOption(opts); f == nil || f.Name != "server" {
+		t.Errorf("Expected the focused server option, got %+v", f)
+	}
+	if got := optionString(opts[0].Options, "reason"); got != "kerbs" {
+		t.Errorf("Expected reason 'kerbs', got %q", got)
+	}
+	if got := optionString(opts[0].Options, "duration"); got != "" {
+		t.Errorf("Expected missing option to be empty, got %q", got)
+	}
+	if focusedOption(nil) != nil {
+		t.Error("Expected no focused option")
+	}
+}
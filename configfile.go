@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ================= CONFIG FILE FORMAT =================
+
+// API writes rewrite config.json. To keep diffs between backups readable, a write keeps the layout of
+// the file it replaces: keys stay in their order (nested objects too), top-level keys the bot does not
+// know ("_comment" keys, settings of newer versions loaded with -lenient) are carried over, and a leading block of "//"
+// comment lines is kept. Keys that are new to the file follow in struct order; map keys in sorted order.
+// Loading is strict: unknown keys are reported with the closest known key unless -lenient is set.
+
+// configFileKeys returns the top-level JSON keys Config defines (omitempty ones included)
+var configFileKeys = sync.OnceValue(func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+})
+
+// splitConfigHeader separates a leading block of "//" comment lines (blank lines included) from the JSON body
+func splitConfigHeader(data []byte) (header, body []byte) {
+	rest := data
+	end := 0
+	for len(rest) > 0 {
+		line, next, found := bytes.Cut(rest, []byte("\n"))
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) > 0 && !bytes.HasPrefix(trimmed, []byte("//")) {
+			break
+		}
+		if !found {
+			// A file that is only comments has no body
+			return data, nil
+		}
+		end += len(line) + 1
+		rest = next
+	}
+	if bytes.Count(data[:end], []byte("//")) == 0 {
+		return nil, data // blank lines alone are not a header
+	}
+	return data[:end], data[end:]
+}
+
+// parseConfigFile decodes the config file content into cfg, skipping a leading comment block
+func parseConfigFile(data []byte, cfg *Config) error {
+	_, body := splitConfigHeader(data)
+	return decodeConfigJSON(body, cfg)
+}
+
+// lenientConfig accepts unknown config keys with a warning instead of failing (-lenient flag)
+// Meant for rolling back to an older version whose config has keys it does not know yet
+var lenientConfig bool
+
+// decodeConfigJSON decodes a JSON config strictly: unknown keys (typos like "updat_interval") fail with
+// an error listing every unknown key and the closest known one, unless lenientConfig is set
+// Keys starting with "_" are comments (see init) and always accepted
+func decodeConfigJSON(data []byte, cfg *Config) error {
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return err
+	}
+	unknown := unknownConfigKeys(data)
+	if len(unknown) == 0 {
+		return nil
+	}
+	if lenientConfig {
+		log.Printf("Warning: ignoring unknown config keys (-lenient): %s", formatUnknownKeys(unknown))
+		return nil
+	}
+	return fmt.Errorf("unknown config keys: %s (fix them, or start with -lenient to ignore unknown keys)", formatUnknownKeys(unknown))
+}
+
+// unknownConfigKey is a key the Config types do not define, with the closest known key ("" if none is close)
+type unknownConfigKey struct {
+	path       string
+	suggestion string
+}
+
+// formatUnknownKeys renders keys as `"servers[1].prot" (did you mean "port"?), ...`
+func formatUnknownKeys(keys []unknownConfigKey) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = strconv.Quote(k.path)
+		if k.suggestion != "" {
+			parts[i] += fmt.Sprintf(" (did you mean %q?)", k.suggestion)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// unknownConfigKeys lists every key in data that Config does not define, nested ones included
+// Matching follows encoding/json: json tag names, case-insensitive. Comment keys ("_...") are skipped
+func unknownConfigKeys(data []byte) []unknownConfigKey {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+	var unknown []unknownConfigKey
+	collectUnknownKeys(raw, reflect.TypeOf(Config{}), "", &unknown)
+	return unknown
+}
+
+// collectUnknownKeys walks v (decoded JSON) alongside the Go type t and appends unknown keys to out
+func collectUnknownKeys(v any, t reflect.Type, path string, out *[]unknownConfigKey) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := make(map[string]reflect.Type)
+		var names []string
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			fields[strings.ToLower(name)] = t.Field(i).Type
+			names = append(names, name)
+		}
+		for _, key := range slices.Sorted(maps.Keys(obj)) {
+			if strings.HasPrefix(key, "_") {
+				continue // comment key
+			}
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			if ft, ok := fields[strings.ToLower(key)]; ok {
+				collectUnknownKeys(obj[key], ft, keyPath, out)
+				continue
+			}
+			*out = append(*out, unknownConfigKey{path: keyPath, suggestion: closestKey(key, names)})
+		}
+	case reflect.Slice, reflect.Array:
+		if arr, ok := v.([]any); ok {
+			for i, elem := range arr {
+				collectUnknownKeys(elem, t.Elem(), fmt.Sprintf("%s[%d]", path, i), out)
+			}
+		}
+	case reflect.Map:
+		if obj, ok := v.(map[string]any); ok {
+			for _, key := range slices.Sorted(maps.Keys(obj)) {
+				collectUnknownKeys(obj[key], t.Elem(), path+"."+key, out)
+			}
+		}
+	}
+}
+
+// closestKey returns the known key nearest to key by edit distance, or "" when none is close enough to be a typo
+func closestKey(key string, known []string) string {
+	best, bestDist := "", 0
+	for _, name := range known {
+		d := editDistance(strings.ToLower(key), name)
+		if d <= 2 && d*2 < len(name) && (best == "" || d < bestDist) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the optimal string alignment distance: insertions, deletions, substitutions and
+// swaps of adjacent characters each cost 1, so "prot" is one edit from "port"
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+// jsonMember is one key/value pair of a JSON object, in file order
+type jsonMember struct {
+	key   string
+	value json.RawMessage
+}
+
+// decodeObject returns the members of a JSON object in order (false if raw is not an object)
+func decodeObject(raw []byte) ([]jsonMember, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, false
+	}
+	var members []jsonMember
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, false
+		}
+		members = append(members, jsonMember{key: tok.(string), value: value})
+	}
+	return members, true
+}
+
+// mergeLayout returns updated with the key order of prev: keys in both keep prev's position (nested objects
+// recursively) and keys only in updated follow in their own order. Keys only in prev are dropped unless
+// keepUnknown reports them as unknown. Non-objects are returned as updated
+func mergeLayout(prev, updated json.RawMessage, keepUnknown func(key string) bool) json.RawMessage {
+	prevMembers, ok := decodeObject(prev)
+	if !ok {
+		return updated
+	}
+	newMembers, ok := decodeObject(updated)
+	if !ok {
+		return updated
+	}
+	newIndex := make(map[string]int, len(newMembers))
+	for i, m := range newMembers {
+		if _, dup := newIndex[m.key]; !dup {
+			newIndex[m.key] = i
+		}
+	}
+
+	var buf bytes.Buffer
+	written := make(map[string]bool, len(newMembers))
+	write := func(key string, value json.RawMessage) {
+		if written[key] {
+			return
+		}
+		written[key] = true
+		if buf.Len() > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	for _, m := range prevMembers {
+		if i, ok := newIndex[m.key]; ok {
+			write(m.key, mergeLayout(m.value, newMembers[i].value, nil))
+		} else if keepUnknown != nil && keepUnknown(m.key) {
+			write(m.key, m.value)
+		}
+	}
+	for _, m := range newMembers {
+		write(m.key, m.value)
+	}
+	return json.RawMessage("{" + buf.String() + "}")
+}
+
+// marshalConfigFile encodes cfg as config.json content, keeping the layout of prev (the file content
+// being replaced; nil for a new file). The output is indented with two spaces and ends with a newline
+func marshalConfigFile(cfg *Config, prev []byte) ([]byte, error) {
+	body, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	header, prevBody := splitConfigHeader(prev)
+	if len(bytes.TrimSpace(prevBody)) > 0 {
+		if !json.Valid(prevBody) {
+			log.Printf("Warning: current config file is not valid JSON, writing without its layout")
+		} else {
+			body = mergeLayout(prevBody, body, func(key string) bool { return !configFileKeys()[key] })
+		}
+	}
+
+	var out bytes.Buffer
+	out.Write(header)
+	if err := json.Indent(&out, body, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to format config: %w", err)
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// encodeConfigFile serializes cfg in the layout of the current config file (caller holds cm.mu)
+func (cm *ConfigManager) encodeConfigFile(cfg *Config) ([]byte, error) {
+	prev, err := os.ReadFile(cm.configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return marshalConfigFile(cfg, prev)
+}
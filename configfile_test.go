@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// layoutConfigFile is a hand-edited config.json: comment header, custom key order and a "_" comment key
+const layoutConfigFile = `// ABSA production servers
+// Owner: #server-admins
+
+{
+  "servers": [
+    {"name": "Drift 1", "ip": "203.0.113.10", "port": 8081, "category": "Drift"}
+  ],
+  "_notes": {"maintenance": "Sundays"},
+  "category_emojis": {"Track": "🔵", "Drift": "🟣"},
+  "category_order": ["Drift", "Track"],
+  "http_client": {"timeout_seconds": 3},
+  "server_ip": "203.0.113.10",
+  "update_interval": 30
+}
+`
+
+// TestSplitConfigHeader tests which leading lines count as the comment header
+func TestSplitConfigHeader(t *testing.T) {
+	tests := []struct {
+		name, data, header string
+	}{
+		{"no header", "{\n}", ""},
+		{"comments", "// a\n  // b\n{}", "// a\n  // b\n"},
+		{"blank lines kept with comments", "// a\n\n{}", "// a\n\n"},
+		{"blank lines alone", "\n\n{}", ""},
+		{"comments only", "// a\n// b", "// a\n// b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, body := splitConfigHeader([]byte(tt.data))
+			if string(header) != tt.header || string(header)+string(body) != tt.data {
+				t.Errorf("Got header %q body %q", header, body)
+			}
+		})
+	}
+}
+
+// TestMarshalConfigFile_KeepsLayout tests that a write keeps the header, key order and unknown keys
+func TestMarshalConfigFile_KeepsLayout(t *testing.T) {
+	var cfg Config
+	if err := parseConfigFile([]byte(layoutConfigFile), &cfg); err != nil {
+		t.Fatalf("parseConfigFile failed: %v", err)
+	}
+	cfg.UpdateInterval = 60
+	cfg.HTTPClient = nil
+	cfg.CategoryEmojis["Sprint"] = "🟢"
+
+	out, err := marshalConfigFile(&cfg, []byte(layoutConfigFile))
+	if err != nil {
+		t.Fatalf("marshalConfigFile failed: %v", err)
+	}
+	text := string(out)
+	if !strings.HasPrefix(text, "// ABSA production servers\n// Owner: #server-admins\n\n{\n") || !strings.HasSuffix(text, "}\n") {
+		t.Errorf("Expected the comment header and a trailing newline, got:\n%s", text)
+	}
+	if !strings.Contains(text, `"maintenance": "Sundays"`) {
+		t.Errorf("Expected the unknown key to be kept, got:\n%s", text)
+	}
+	if strings.Contains(text, "http_client") {
+		t.Errorf("Expected the removed http_client to be dropped, got:\n%s", text)
+	}
+	order := []string{`"servers"`, `"_notes"`, `"category_emojis"`, `"Track"`, `"Drift": "🟣"`, `"Sprint"`, `"category_order"`, `"server_ip"`, `"update_interval": 60`}
+	last := -1
+	for _, key := range order {
+		i := strings.Index(text, key)
+		if i < last {
+			t.Errorf("Expected %s after the previous keys, got:\n%s", key, text)
+		}
+		last = i
+	}
+
+	var back Config
+	if err := parseConfigFile(out, &back); err != nil || !reflect.DeepEqual(back, cfg) {
+		t.Errorf("Expected the written file to parse back to the config, got %+v (%v)", back, err)
+	}
+	again, _ := marshalConfigFile(&cfg, out)
+	if !bytes.Equal(again, out) {
+		t.Errorf("Expected rewriting unchanged config to be byte-identical, got:\n%s", again)
+	}
+}
+
+// TestMarshalConfigFile_NewFile tests struct order without a previous file and with an unparsable one
+func TestMarshalConfigFile_NewFile(t *testing.T) {
+	cfg := testStatusConfig()
+	for _, prev := range [][]byte{nil, []byte("{broken")} {
+		out, err := marshalConfigFile(cfg, prev)
+		if err != nil {
+			t.Fatalf("marshalConfigFile failed: %v", err)
+		}
+		if !strings.HasPrefix(string(out), "{\n  \"server_ip\": ") || !strings.HasSuffix(string(out), "}\n") {
+			t.Errorf("Expected struct order, got:\n%s", out)
+		}
+	}
+}
+
+// TestConfigManager_WriteKeepsLayout tests the layout surviving an API write and the header surviving a reload
+func TestConfigManager_WriteKeepsLayout(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(configPath, []byte(layoutConfigFile), 0644)
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatalf("loadConfig failed with a comment header: %v", err)
+	}
+	cm := NewConfigManager(configPath, cfg)
+
+	if err := cm.UpdateConfig(map[string]interface{}{"update_interval": 45}); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+	data, _ := os.ReadFile(configPath)
+	if !strings.HasPrefix(string(data), "// ABSA production servers") || !strings.Contains(string(data), `"_notes"`) {
+		t.Errorf("Expected header and unknown key kept, got:\n%s", data)
+	}
+
+	// A hand edit of the commented file is reloaded
+	os.WriteFile(configPath, bytes.Replace(data, []byte(`"update_interval": 45`), []byte(`"update_interval": 50`), 1), 0644)
+	if err := cm.checkAndReloadIfNeeded(); err != nil {
+		t.Fatalf("checkAndReloadIfNeeded failed: %v", err)
+	}
+	if got := cm.GetConfig().UpdateInterval; got != 50 {
+		t.Errorf("Expected the reloaded interval 50, got %d", got)
+	}
+}
+
+// TestDecodeConfigJSON_UnknownKeys tests that typos fail with every unknown key and a suggestion
+func TestDecodeConfigJSON_UnknownKeys(t *testing.T) {
+	data := []byte(`{
+  "_comment": "comment keys are fine",
+  "server_ip": "203.0.113.10",
+  "updat_interval": 30,
+  "category_order": ["Drift"],
+  "category_emojis": {"Drift": "🟣"},
+  "servers": [
+    {"name": "Drift 1", "port": 8081, "category": "Drift"},
+    {"name": "Drift 2", "prot": 8082, "category": "Drift", "_note": "new"}
+  ],
+  "http_client": {"timeout": 3},
+  "colour": "red"
+}`)
+	var cfg Config
+	err := decodeConfigJSON(data, &cfg)
+	if err == nil {
+		t.Fatal("Expected an error for unknown keys")
+	}
+	for _, want := range []string{
+		`"colour"`,
+		`"http_client.timeout"`,
+		`"servers[1].prot" (did you mean "port"?)`,
+		`"updat_interval" (did you mean "update_interval"?)`,
+		"-lenient",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %s in %q", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "colour\" (did you mean") || strings.Contains(err.Error(), "_comment") {
+		t.Errorf("Expected no suggestion for unrelated keys and no comment keys, got %q", err)
+	}
+
+	// Case-insensitive matches are accepted like encoding/json does
+	if err := decodeConfigJSON([]byte(`{"Server_IP": "203.0.113.10"}`), &cfg); err != nil {
+		t.Errorf("Expected case-insensitive keys to be accepted, got %v", err)
+	}
+
+	lenientConfig = true
+	t.Cleanup(func() { lenientConfig = false })
+	cfg = Config{}
+	if err := decodeConfigJSON(data, &cfg); err != nil || cfg.ServerIP != "203.0.113.10" {
+		t.Errorf("Expected -lenient to load the known keys, got %+v (%v)", cfg, err)
+	}
+}
+
+// TestEditDistance tests adjacent swaps counting as one edit
+func TestEditDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"port", "port", 0},
+		{"prot", "port", 1},
+		{"updat_interval", "update_interval", 1},
+		{"servers", "server_ip", 3},
+		{"", "abc", 3},
+	} {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
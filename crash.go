@@ -0,0 +1,342 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/atomicfile"
+	"github.com/bombom/absa-ac/pkg/supervisor"
+)
+
+// ================= CRASH REPORTS =================
+
+// Crash bundles are zip files in CRASH_REPORT_DIR named crash-<UTC time>.zip, holding:
+//
+//	info.json       reason, time, uptime, build and runtime versions
+//	stack.txt       panic value and stack (if any), then all goroutines
+//	logs.txt        the buffered log lines (LOG_BUFFER_LINES, already redacted by the logger)
+//	config.json     the loaded config with secrets stripped
+//
+// runtimeCrashFile receives the runtime's own crash output (unrecovered panics in any goroutine, fatal
+// runtime errors), which cannot be handled in-process; it becomes a bundle at the next start
+const (
+	defaultCrashReportKeep = 10
+	runtimeCrashFile       = "runtime-crash.txt"
+	crashTimeLayout        = "20060102T150405.000000Z"
+)
+
+// crashReporter writes crash bundles. A nil *crashReporter (CRASH_REPORT_DIR unset) ignores every call
+type crashReporter struct {
+	dir  string
+	keep int
+	logs *logRing
+	// config returns the loaded config (nil until set or in setup mode)
+	config func() *Config
+
+	mu sync.Mutex // serializes bundle writes and pruning
+}
+
+// crashReporterFromEnv returns a reporter if CRASH_REPORT_DIR is set, nil otherwise
+// CRASH_REPORT_KEEP bounds the number of bundles kept (oldest are deleted). Creates the directory, turns a
+// runtime crash of the previous run into a bundle, then routes the runtime crash output to it
+// Bundles include the lines of logs, the installed log ring
+func crashReporterFromEnv(logs *logRing) (*crashReporter, error) {
+	dir := os.Getenv("CRASH_REPORT_DIR")
+	if dir == "" {
+		return nil, nil
+	}
+	keep := defaultCrashReportKeep
+	if v := os.Getenv("CRASH_REPORT_KEEP"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid CRASH_REPORT_KEEP %q: must be at least 1", v)
+		}
+		keep = n
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create CRASH_REPORT_DIR %s: %w", dir, err)
+	}
+
+	c := &crashReporter{dir: dir, keep: keep, logs: logs}
+	c.collectRuntimeCrash()
+	if err := c.captureRuntimeCrashes(); err != nil {
+		return nil, err
+	}
+	log.Printf("Crash reports enabled: %s (keeping %d)", dir, keep)
+	return c, nil
+}
+
+// setConfig makes bundles include the config returned by fn
+func (c *crashReporter) setConfig(fn func() *Config) {
+	if c != nil {
+		c.config = fn
+	}
+}
+
+// captureRuntimeCrashes sends the runtime's crash output to runtimeCrashFile (truncated at each start)
+func (c *crashReporter) captureRuntimeCrashes() error {
+	f, err := os.OpenFile(filepath.Join(c.dir, runtimeCrashFile), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open runtime crash file: %w", err)
+	}
+	defer f.Close() // SetCrashOutput keeps its own duplicate of the descriptor
+	if err := debug.SetCrashOutput(f, debug.CrashOptions{}); err != nil {
+		return fmt.Errorf("failed to set runtime crash output: %w", err)
+	}
+	return nil
+}
+
+// collectRuntimeCrash turns the runtime crash output of the previous run, if any, into a bundle
+// The logs and config of that run are gone; the bundle holds the runtime's stack dump
+func (c *crashReporter) collectRuntimeCrash() {
+	path := filepath.Join(c.dir, runtimeCrashFile)
+	data, err := os.ReadFile(path)
+	if err != nil || len(bytes.TrimSpace(data)) == 0 {
+		return
+	}
+	at := time.Now()
+	if fi, err := os.Stat(path); err == nil {
+		at = fi.ModTime()
+	}
+	bundle, err := c.write(crashBundle{
+		at:          at,
+		previousRun: true,
+		reason:      "runtime crash of the previous run (unrecovered panic or fatal runtime error)",
+		stack:       RedactSecrets(string(data)),
+	})
+	if err != nil {
+		log.Printf("Warning: failed to write crash report for the previous run: %v", err)
+		return
+	}
+	log.Printf("WARNING: the previous run crashed, crash report written to %s", bundle)
+}
+
+// recoverPanic writes a bundle for a panic of the calling goroutine, then re-panics
+// Must be called directly via defer: `defer crashes.recoverPanic()`
+func (c *crashReporter) recoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if c != nil {
+		c.report(fmt.Sprintf("panic: %v", r), debug.Stack())
+		// The runtime would write the re-panic to runtimeCrashFile and report it again at the next start
+		debug.SetCrashOutput(nil, debug.CrashOptions{})
+	}
+	panic(r)
+}
+
+// componentPanic writes a bundle for a panic of a supervised component (it is restarted afterwards)
+func (c *crashReporter) componentPanic(name string, pe *supervisor.PanicError) {
+	c.report(fmt.Sprintf("%s panicked: %v", name, pe.Value), pe.Stack)
+}
+
+// report writes a bundle for reason and logs where it went
+// stack is the stack of the failing goroutine (nil for errors)
+func (c *crashReporter) report(reason string, stack []byte) {
+	if c == nil {
+		return
+	}
+	b := crashBundle{at: time.Now(), reason: reason, logs: c.logs.String()}
+	if stack != nil {
+		b.stack = RedactSecrets(fmt.Sprintf("%s\n\n%s", reason, stack)) + "\n"
+	}
+	b.stack += RedactSecrets(allGoroutines())
+	if c.config != nil {
+		b.config = c.config()
+	}
+	path, err := c.write(b)
+	if err != nil {
+		log.Printf("ERROR: failed to write crash report: %v", err)
+		return
+	}
+	log.Printf("Crash report written to %s", path)
+}
+
+// crashBundle is the content of one bundle
+type crashBundle struct {
+	at time.Time
+	// previousRun marks the runtime crash of an earlier process: uptime and PID are unknown
+	previousRun bool
+	reason      string
+	stack       string
+	logs        string
+	config      *Config
+}
+
+// crashInfo is info.json of a bundle
+type crashInfo struct {
+	Reason    string    `json:"reason"`
+	Time      time.Time `json:"time"`
+	Uptime    string    `json:"uptime,omitempty"`
+	Version   string    `json:"version"`
+	Revision  string    `json:"revision,omitempty"`
+	Modified  bool      `json:"modified,omitempty"`
+	GoVersion string    `json:"go_version"`
+	OS        string    `json:"os"`
+	Arch      string    `json:"arch"`
+	PID       int       `json:"pid,omitempty"`
+}
+
+// write writes b as a zip into the report directory and prunes old bundles
+func (c *crashReporter) write(b crashBundle) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	build := readBuildInfo()
+	info := crashInfo{
+		Reason:    RedactSecrets(b.reason),
+		Time:      b.at.UTC(),
+		Version:   build.Version,
+		Revision:  build.Commit,
+		Modified:  build.Modified,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if !b.previousRun {
+		info.Uptime = b.at.Sub(processStart).Round(time.Second).String()
+		info.PID = os.Getpid()
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	var zipErr error
+	add := func(name string, data []byte) {
+		if zipErr != nil {
+			return
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: b.at})
+		if err == nil {
+			_, err = w.Write(data)
+		}
+		zipErr = err
+	}
+	infoJSON, _ := json.MarshalIndent(info, "", "  ")
+	add("info.json", infoJSON)
+	add("stack.txt", []byte(b.stack))
+	if b.logs != "" {
+		add("logs.txt", []byte(b.logs))
+	}
+	if b.config != nil {
+		cfgJSON, _ := json.MarshalIndent(redactedConfig(b.config), "", "  ")
+		add("config.json", cfgJSON)
+	}
+	if zipErr == nil {
+		zipErr = zw.Close()
+	}
+	if zipErr != nil {
+		return "", fmt.Errorf("failed to build crash bundle: %w", zipErr)
+	}
+
+	name := "crash-" + b.at.UTC().Format(crashTimeLayout)
+	path := filepath.Join(c.dir, name+".zip")
+	for i := 2; ; i++ { // coarse clocks (Windows) can give two failures the same time
+		if _, err := os.Stat(path); err != nil {
+			break
+		}
+		path = filepath.Join(c.dir, fmt.Sprintf("%s-%d.zip", name, i))
+	}
+	tmp, err := os.CreateTemp(c.dir, ".crash-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file in %s: %w", c.dir, err)
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to close %s: %w", tmp.Name(), err)
+	}
+	if err := atomicfile.Replace(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	c.prune()
+	return path, nil
+}
+
+// prune deletes the oldest bundles beyond c.keep (names sort by time)
+func (c *crashReporter) prune() {
+	bundles, err := filepath.Glob(filepath.Join(c.dir, "crash-*.zip"))
+	if err != nil || len(bundles) <= c.keep {
+		return
+	}
+	slices.Sort(bundles)
+	for _, old := range bundles[:len(bundles)-c.keep] {
+		if err := os.Remove(old); err != nil {
+			log.Printf("Warning: failed to delete old crash report %s: %v", old, err)
+		}
+	}
+}
+
+// redactedConfig returns a copy of cfg that is safe to share: the proxy password, alert webhook tokens,
+// PagerDuty routing keys, Pterodactyl API keys, cloud hook URLs and server join passwords are stripped
+// The rest of the config (server addresses, categories) holds no secrets and is needed to reproduce problems
+func redactedConfig(cfg *Config) *Config {
+	out := cfg.Clone()
+	if out.HTTPClient != nil && out.HTTPClient.ProxyURL != "" {
+		if u, err := url.Parse(out.HTTPClient.ProxyURL); err == nil {
+			out.HTTPClient.ProxyURL = u.Redacted()
+		} else {
+			out.HTTPClient.ProxyURL = "[REDACTED]"
+		}
+	}
+	if out.CloudInstances != nil {
+		for server, inst := range out.CloudInstances.Servers {
+			out.CloudInstances.Servers[server] = CloudInstance{StartURL: redactAlertURL(inst.StartURL), StopURL: redactAlertURL(inst.StopURL)}
+		}
+	}
+	for server, p := range out.Pterodactyl {
+		p.APIKey = "[REDACTED]"
+		out.Pterodactyl[server] = p
+	}
+	for server, p := range out.Passwords {
+		p.Password = "[REDACTED]"
+		out.Passwords[server] = p
+	}
+	if out.Alerts != nil {
+		for i, r := range out.Alerts.Routes {
+			if r.WebhookURL != "" {
+				out.Alerts.Routes[i].WebhookURL = redactAlertURL(r.WebhookURL)
+			}
+		}
+		if esc := out.Alerts.Escalation; esc != nil {
+			for i, step := range esc.Steps {
+				if step.WebhookURL != "" {
+					esc.Steps[i].WebhookURL = redactAlertURL(step.WebhookURL)
+				}
+				if step.RoutingKey != "" {
+					esc.Steps[i].RoutingKey = "[REDACTED]"
+				}
+			}
+		}
+	}
+	return out
+}
+
+// allGoroutines returns the stacks of all goroutines
+func allGoroutines() string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= 16<<20 {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
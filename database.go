@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bombom/absa-ac/pkg/store"
+)
+
+// ================= DATABASE =================
+
+const (
+	// databaseFile is the SQLite database next to config.json unless DATABASE_URL is set
+	databaseFile = "bot.db"
+	// databaseTimeout bounds one read or write of the history or audit log
+	databaseTimeout = 30 * time.Second
+)
+
+// databaseMigrations is the schema, oldest first; never edit a released migration, append a new one
+// The SQL must run on both SQLite and PostgreSQL; times are Unix seconds (nanoseconds for audit entries)
+var databaseMigrations = []store.Migration{
+	{Version: 1, Name: "server history", Statements: []string{
+		`CREATE TABLE history_buckets (
+			hour BIGINT NOT NULL,
+			server TEXT NOT NULL,
+			category TEXT NOT NULL,
+			polls INTEGER NOT NULL,
+			online_polls INTEGER NOT NULL,
+			maintenance_polls INTEGER NOT NULL,
+			player_seconds BIGINT NOT NULL,
+			sessions INTEGER NOT NULL,
+			tracks TEXT NOT NULL,
+			PRIMARY KEY (hour, server)
+		)`,
+		`CREATE TABLE history_meta (name TEXT PRIMARY KEY, value TEXT NOT NULL)`,
+	}},
+	{Version: 2, Name: "config audit log", Statements: []string{
+		`CREATE TABLE audit_entries (
+			id TEXT PRIMARY KEY,
+			time BIGINT NOT NULL,
+			action TEXT NOT NULL,
+			actor TEXT NOT NULL,
+			changed_keys TEXT NOT NULL,
+			revert_of TEXT NOT NULL,
+			reverted_by TEXT NOT NULL,
+			before_config TEXT NOT NULL,
+			after_config TEXT NOT NULL
+		)`,
+		`CREATE INDEX audit_entries_time ON audit_entries (time)`,
+	}},
+}
+
+// databaseFromEnv opens and migrates DATABASE_URL, or bot.db next to config.json when needed (a feature
+// stores data) and DATABASE_URL is unset. Returns nil when neither
+func databaseFromEnv(configPath string, needed bool) (*store.DB, error) {
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		if !needed {
+			return nil, nil
+		}
+		dsn = "sqlite:" + filepath.Join(filepath.Dir(configPath), databaseFile)
+	}
+	db, err := store.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := db.Migrate(ctx, databaseMigrations); err != nil {
+		db.Close()
+		return nil, err
+	}
+	log.Printf("Database %s ready (schema version %d)", db, databaseMigrations[len(databaseMigrations)-1].Version)
+	return db, nil
+}
+
+// auditStore adapts the database to api.AuditStore
+type auditStore struct {
+	db *store.DB
+}
+
+// LoadAuditEntries implements api.AuditStore
+func (s *auditStore) LoadAuditEntries(limit int) ([]api.AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), databaseTimeout)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, s.db.Rebind(`SELECT id, time, action, actor, changed_keys, revert_of, reverted_by,
+		before_config, after_config FROM audit_entries ORDER BY time DESC LIMIT ?`), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []api.AuditEntry
+	for rows.Next() {
+		var e api.AuditEntry
+		var at int64
+		var keys, before, after string
+		if err := rows.Scan(&e.ID, &at, &e.Action, &e.Actor, &keys, &e.RevertOf, &e.RevertedBy, &before, &after); err != nil {
+			return nil, err
+		}
+		e.Time = time.Unix(0, at).UTC()
+		if err := json.Unmarshal([]byte(keys), &e.ChangedKeys); err != nil {
+			return nil, fmt.Errorf("invalid changed keys of audit entry %s: %w", e.ID, err)
+		}
+		e.Before, e.After = json.RawMessage(before), json.RawMessage(after)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Oldest first, like the in-memory log
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// SaveAuditEntries implements api.AuditStore
+func (s *auditStore) SaveAuditEntries(entries []api.AuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), databaseTimeout)
+	defer cancel()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, e := range entries {
+		keys, _ := json.Marshal(e.ChangedKeys)
+		_, err := tx.ExecContext(ctx, s.db.Rebind(`INSERT INTO audit_entries (id, time, action, actor, changed_keys, revert_of,
+			reverted_by, before_config, after_config) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET reverted_by = excluded.reverted_by`),
+			e.ID, e.Time.UnixNano(), e.Action, e.Actor, string(keys), e.RevertOf, e.RevertedBy,
+			string(e.Before), string(e.After))
+		if err != nil {
+			return fmt.Errorf("failed to save audit entry %s: %w", e.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteAuditEntries implements api.AuditStore
+func (s *auditStore) DeleteAuditEntries(ids []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), databaseTimeout)
+	defer cancel()
+	for _, id := range ids {
+		if _, err := s.db.ExecContext(ctx, s.db.Rebind("DELETE FROM audit_entries WHERE id = ?"), id); err != nil {
+			return fmt.Errorf("failed to delete audit entry %s: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bombom/absa-ac/pkg/store"
+)
+
+// testDatabase opens a migrated SQLite database in a temp dir
+func testDatabase(t *testing.T) *store.DB {
+	t.Helper()
+	return testDatabaseAt(t, filepath.Join(t.TempDir(), "config.json"))
+}
+
+// testDatabaseAt opens the migrated SQLite database next to configPath
+func testDatabaseAt(t *testing.T, configPath string) *store.DB {
+	t.Helper()
+	db, err := databaseFromEnv(configPath, true)
+	if err != nil {
+		t.Fatalf("databaseFromEnv failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// TestDatabaseFromEnv tests the default SQLite file, opening only when needed and DATABASE_URL
+func TestDatabaseFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	if db, err := databaseFromEnv(configPath, false); db != nil || err != nil {
+		t.Errorf("Expected no database when nothing needs one, got %v %v", db, err)
+	}
+	db, err := databaseFromEnv(configPath, true)
+	if err != nil {
+		t.Fatalf("databaseFromEnv failed: %v", err)
+	}
+	db.Close()
+	if _, err := os.Stat(filepath.Join(dir, databaseFile)); err != nil {
+		t.Errorf("Expected %s next to config.json: %v", databaseFile, err)
+	}
+
+	custom := filepath.Join(dir, "custom.db")
+	t.Setenv("DATABASE_URL", "sqlite:"+custom)
+	db, err = databaseFromEnv(configPath, false)
+	if err != nil || db == nil || db.String() != "sqlite:"+custom {
+		t.Fatalf("Expected DATABASE_URL to be opened, got %v %v", db, err)
+	}
+	db.Close()
+}
+
+// TestAuditStore tests saving, updating, loading the newest entries in order and deleting audit entries
+func TestAuditStore(t *testing.T) {
+	s := &auditStore{db: testDatabase(t)}
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	var entries []api.AuditEntry
+	for i, id := range []string{"a", "b", "c"} {
+		entries = append(entries, api.AuditEntry{
+			ID: id, Time: start.Add(time.Duration(i) * time.Minute), Action: "PATCH /api/config", Actor: "203.0.113.5",
+			ChangedKeys: []string{"update_interval"}, Before: json.RawMessage(`{"update_interval":30}`), After: json.RawMessage(`{"update_interval":60}`),
+		})
+	}
+	if err := s.SaveAuditEntries(entries); err != nil {
+		t.Fatalf("SaveAuditEntries failed: %v", err)
+	}
+	entries[1].RevertedBy = "c"
+	if err := s.SaveAuditEntries(entries[1:2]); err != nil {
+		t.Fatalf("SaveAuditEntries update failed: %v", err)
+	}
+
+	got, err := s.LoadAuditEntries(2)
+	if err != nil {
+		t.Fatalf("LoadAuditEntries failed: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "b" || got[1].ID != "c" || got[0].RevertedBy != "c" || !got[0].Time.Equal(entries[1].Time) {
+		t.Fatalf("Expected b and c, oldest first, got %+v", got)
+	}
+	if !slices.Equal(got[1].ChangedKeys, []string{"update_interval"}) || string(got[1].After) != `{"update_interval":60}` {
+		t.Errorf("Unexpected entry %+v", got[1])
+	}
+
+	if err := s.DeleteAuditEntries([]string{"b", "c"}); err != nil {
+		t.Fatalf("DeleteAuditEntries failed: %v", err)
+	}
+	if got, _ := s.LoadAuditEntries(10); len(got) != 1 || got[0].ID != "a" {
+		t.Errorf("Expected only a left, got %+v", got)
+	}
+}
+
+// TestHistoryImport tests moving history.json of an earlier release into an empty database
+func TestHistoryImport(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, historyStateFile)
+	hour := time.Now().UTC().Truncate(time.Hour)
+	data, _ := json.Marshal(historyState{
+		Buckets:    []historyBucket{{Hour: hour, Server: "Track #1", Category: "Track", Polls: 10, OnlinePolls: 9, PlayerSeconds: 600, Tracks: map[string]int64{"spa": 600}}},
+		LastReport: "2026-09",
+	})
+	os.WriteFile(path, data, 0644)
+
+	db := testDatabase(t)
+	t.Setenv("HISTORY_ENABLED", "true")
+	h, err := historyStoreFromEnv(filepath.Join(dir, "config.json"), db)
+	if err != nil {
+		t.Fatalf("historyStoreFromEnv failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".imported"); err != nil {
+		t.Errorf("Expected history.json to be renamed: %v", err)
+	}
+
+	reloaded := newHistoryStore(db)
+	if err := reloaded.load(time.Now()); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	got := reloaded.buckets(hour, hour.Add(time.Hour))
+	if len(got) != 1 || got[0].OnlinePolls != 9 || got[0].Tracks["spa"] != 600 || reloaded.state.LastReport != "2026-09" {
+		t.Errorf("Expected the imported history in the database, got %+v %q", got, reloaded.state.LastReport)
+	}
+	if len(h.state.Buckets) != 1 {
+		t.Errorf("Expected the store to hold the imported buckets, got %d", len(h.state.Buckets))
+	}
+}
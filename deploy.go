@@ -0,0 +1,707 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// ================= SERVER CONFIG FILE DEPLOYMENT =================
+
+const (
+	// deploymentsStateFile keeps the deployment history next to config.json
+	deploymentsStateFile = "deployments.json"
+	// deployHistoryMax bounds the deployments kept per server (the rollback window)
+	deployHistoryMax = 10
+	// maxFileTemplateSize bounds one template in the config
+	maxFileTemplateSize = 64 << 10
+	// deployTimeout bounds one upload of all files of a server
+	deployTimeout = 60 * time.Second
+)
+
+// deployFileNamePattern is what a deployed file may be called: a plain .ini name, never a path
+var deployFileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+\.ini$`)
+
+// ServerFilesConfig renders AC server config files from templates (server_files in config.json)
+type ServerFilesConfig struct {
+	Templates map[string]string        `json:"templates"` // text/template sources by name
+	Servers   map[string]ServerFileSet `json:"servers"`   // keyed by server name
+}
+
+// ServerFileSet is the files deployed to one server
+// Target is sftp://user@host[:port]/path/to/cfg (files are uploaded into that directory) or an
+// https:// hook that receives {"server", "files": {name: content}} as JSON
+type ServerFileSet struct {
+	Target string            `json:"target"`
+	Files  map[string]string `json:"files"`          // file name (server_cfg.ini) -> template name
+	Vars   map[string]string `json:"vars,omitempty"` // .Vars in the templates
+}
+
+// fileTemplateData is what templates render: the server, its vars, its reserved slots (whitelists) and its
+// join password (passwords, empty when none is set)
+type fileTemplateData struct {
+	Server   Server
+	Vars     map[string]string
+	Reserved []WhitelistEntry
+	Password string
+}
+
+// cloneServerFiles deep-copies the server_files section (nil for nil)
+func cloneServerFiles(sf *ServerFilesConfig) *ServerFilesConfig {
+	if sf == nil {
+		return nil
+	}
+	out := &ServerFilesConfig{Templates: maps.Clone(sf.Templates)}
+	if sf.Servers != nil {
+		out.Servers = make(map[string]ServerFileSet, len(sf.Servers))
+		for server, set := range sf.Servers {
+			set.Files = maps.Clone(set.Files)
+			set.Vars = maps.Clone(set.Vars)
+			out.Servers[server] = set
+		}
+	}
+	return out
+}
+
+// parseFileTemplate parses a template source; unknown vars are errors instead of "<no value>"
+func parseFileTemplate(name, src string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(src)
+}
+
+// parseDeployTarget checks a target URL: sftp://user@host[:port]/dir, or an https hook (http only on loopback)
+func parseDeployTarget(target string) (*url.URL, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("target %q has no host", target)
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		return nil, fmt.Errorf("target %q must not contain a password: set DEPLOY_SSH_PASSWORD or DEPLOY_SSH_KEY_FILE", target)
+	}
+	switch u.Scheme {
+	case "sftp":
+		if u.User.Username() == "" {
+			return nil, fmt.Errorf("target %q needs a user (sftp://user@host/dir)", target)
+		}
+		if !path.IsAbs(u.Path) {
+			return nil, fmt.Errorf("target %q needs an absolute directory (sftp://user@host/path/to/cfg)", target)
+		}
+	case "https":
+	case "http":
+		if !isLoopbackHost(u.Hostname()) {
+			return nil, fmt.Errorf("target %q: hooks must use https (http is allowed on localhost only)", target)
+		}
+	default:
+		return nil, fmt.Errorf("target %q must start with sftp:// or https://", target)
+	}
+	return u, nil
+}
+
+// validateServerFiles checks the server_files section: templates parse, servers exist, targets and file names are valid
+func validateServerFiles(sf *ServerFilesConfig, servers []Server) error {
+	if sf == nil {
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(sf.Templates)) {
+		src := sf.Templates[name]
+		if len(src) > maxFileTemplateSize {
+			return fmt.Errorf("server_files template '%s' is %d bytes (max %d)", name, len(src), maxFileTemplateSize)
+		}
+		if _, err := parseFileTemplate(name, src); err != nil {
+			return fmt.Errorf("server_files template '%s': %w", name, err)
+		}
+	}
+	serverNames := make(map[string]bool, len(servers))
+	for _, s := range servers {
+		serverNames[s.Name] = true
+	}
+	for _, server := range slices.Sorted(maps.Keys(sf.Servers)) {
+		set := sf.Servers[server]
+		if !serverNames[server] {
+			return fmt.Errorf("server_files for server '%s' which is not defined in servers", server)
+		}
+		if _, err := parseDeployTarget(set.Target); err != nil {
+			return fmt.Errorf("server_files for server '%s': %w", server, err)
+		}
+		if len(set.Files) == 0 {
+			return fmt.Errorf("server_files for server '%s' has no files", server)
+		}
+		for _, file := range slices.Sorted(maps.Keys(set.Files)) {
+			if !deployFileNamePattern.MatchString(file) {
+				return fmt.Errorf("server_files for server '%s': '%s' must be a plain .ini file name", server, file)
+			}
+			if _, ok := sf.Templates[set.Files[file]]; !ok {
+				return fmt.Errorf("server_files for server '%s': %s uses unknown template '%s'", server, file, set.Files[file])
+			}
+		}
+	}
+	return nil
+}
+
+// renderedFile is one rendered config file
+type renderedFile struct {
+	name string
+	data []byte
+}
+
+// renderServerFiles renders the files of server, sorted by name, and returns them with the parsed target
+func renderServerFiles(cfg *Config, server string) (*url.URL, []renderedFile, error) {
+	if cfg == nil {
+		return nil, nil, fmt.Errorf("no config loaded")
+	}
+	idx := slices.IndexFunc(cfg.Servers, func(s Server) bool { return s.Name == server })
+	var set ServerFileSet
+	ok := false
+	if cfg.ServerFiles != nil {
+		set, ok = cfg.ServerFiles.Servers[server]
+	}
+	if idx < 0 || !ok {
+		return nil, nil, fmt.Errorf("%w '%s' (no server_files configured)", api.ErrUnknownServer, server)
+	}
+	target, err := parseDeployTarget(set.Target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := fileTemplateData{Server: cfg.Servers[idx], Vars: set.Vars, Reserved: cfg.Whitelists[server].Drivers, Password: cfg.Passwords[server].Password}
+	if data.Vars == nil {
+		data.Vars = map[string]string{}
+	}
+	files := make([]renderedFile, 0, len(set.Files))
+	for _, name := range slices.Sorted(maps.Keys(set.Files)) {
+		tmpl, err := parseFileTemplate(set.Files[name], cfg.ServerFiles.Templates[set.Files[name]])
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		files = append(files, renderedFile{name: name, data: buf.Bytes()})
+	}
+	return target, files, nil
+}
+
+// ================= DIFF =================
+
+// diffContext is the number of unchanged lines shown around a change
+const diffContext = 3
+
+// maxDiffCells bounds the line comparison table; larger changes show as a full replacement
+const maxDiffCells = 4 << 20
+
+// diffOp is one line of a diff: ' ' kept, '-' removed, '+' added
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// splitLines splits text into lines without their newlines
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes a shortest line diff of a and b (longest common subsequence)
+func diffLines(a, b []string) []diffOp {
+	var ops []diffOp
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		ops = append(ops, diffOp{' ', a[prefix]})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	if len(ma)*len(mb) > maxDiffCells {
+		for _, l := range ma {
+			ops = append(ops, diffOp{'-', l})
+		}
+		for _, l := range mb {
+			ops = append(ops, diffOp{'+', l})
+		}
+	} else {
+		// lcs[i][j] is the common subsequence length of ma[i:] and mb[j:]
+		lcs := make([][]int, len(ma)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(mb)+1)
+		}
+		for i := len(ma) - 1; i >= 0; i-- {
+			for j := len(mb) - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(ma) || j < len(mb) {
+			switch {
+			case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+				ops = append(ops, diffOp{' ', ma[i]})
+				i++
+				j++
+			case j < len(mb) && (i == len(ma) || lcs[i][j+1] > lcs[i+1][j]):
+				ops = append(ops, diffOp{'+', mb[j]})
+				j++
+			default:
+				ops = append(ops, diffOp{'-', ma[i]})
+				i++
+			}
+		}
+	}
+
+	for _, l := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	return ops
+}
+
+// unifiedDiff renders the change from old to new as a unified diff ("" when equal)
+func unifiedDiff(name, old, new string) string {
+	if old == new {
+		return ""
+	}
+	ops := diffLines(splitLines(old), splitLines(new))
+	// aLine[k] and bLine[k] count the old and new lines before ops[k]
+	aLine, bLine := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for k, op := range ops {
+		aLine[k+1], bLine[k+1] = aLine[k], bLine[k]
+		if op.kind != '+' {
+			aLine[k+1]++
+		}
+		if op.kind != '-' {
+			bLine[k+1]++
+		}
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- a/%s\n+++ b/%s\n", name, name)
+	for i := 0; i < len(ops); {
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		start, end := max(0, i-diffContext), i
+		for {
+			for end < len(ops) && ops[end].kind != ' ' {
+				end++
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				end = min(len(ops), end+diffContext)
+				break
+			}
+			end = next
+		}
+
+		aCount, bCount := aLine[end]-aLine[start], bLine[end]-bLine[start]
+		aStart, bStart := aLine[start], bLine[start]
+		if aCount > 0 {
+			aStart++
+		}
+		if bCount > 0 {
+			bStart++
+		}
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, op := range ops[start:end] {
+			buf.WriteByte(op.kind)
+			buf.WriteString(op.line)
+			buf.WriteByte('\n')
+		}
+		i = end
+	}
+	return buf.String()
+}
+
+// ================= TRANSPORTS =================
+
+// deployTransport uploads rendered files to a target
+type deployTransport interface {
+	upload(ctx context.Context, target *url.URL, server string, files []renderedFile) error
+}
+
+// fileUploader uploads over SFTP (SSH with a verified host key) or POSTs to an HTTP hook
+type fileUploader struct {
+	// sshAuth and hostKeys authenticate SFTP targets (nil hostKeys = SFTP disabled)
+	sshAuth  []ssh.AuthMethod
+	hostKeys ssh.HostKeyCallback
+	// hookToken is sent as Bearer token to hooks (empty = none)
+	hookToken string
+	client    *http.Client
+}
+
+// upload implements deployTransport
+func (u *fileUploader) upload(ctx context.Context, target *url.URL, server string, files []renderedFile) error {
+	if target.Scheme == "sftp" {
+		return u.uploadSFTP(ctx, target, files)
+	}
+	return u.postHook(ctx, target, server, files)
+}
+
+// uploadSFTP writes the files into the target directory, each replaced atomically where the server allows
+func (u *fileUploader) uploadSFTP(ctx context.Context, target *url.URL, files []renderedFile) error {
+	if u.hostKeys == nil {
+		return fmt.Errorf("SFTP targets need DEPLOY_SSH_KNOWN_HOSTS to verify the host key")
+	}
+	addr := target.Host
+	if target.Port() == "" {
+		addr = net.JoinHostPort(target.Hostname(), "22")
+	}
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
+		User:            target.User.Username(),
+		Auth:            u.sshAuth,
+		HostKeyCallback: u.hostKeys,
+	})
+	if err != nil {
+		return fmt.Errorf("ssh %s: %w", addr, err)
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+
+	sftpClient, err := sftp.NewClient(client)
+	if err != nil {
+		return fmt.Errorf("sftp subsystem: %w", err)
+	}
+	defer sftpClient.Close()
+	for _, f := range files {
+		if err := writeFileSFTP(sftpClient, path.Join(target.Path, f.name), f.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFileSFTP replaces the file at name with data
+// With the posix-rename extension (OpenSSH) the data is written to a temp file next to it and renamed over it,
+// so the game server never reads half a file; otherwise the file is truncated and written in place
+func writeFileSFTP(c *sftp.Client, name string, data []byte) error {
+	if _, ok := c.HasExtension("posix-rename@openssh.com"); !ok {
+		return writeSFTP(c, name, data)
+	}
+	tmp := path.Join(path.Dir(name), "."+path.Base(name)+".tmp")
+	if err := writeSFTP(c, tmp, data); err != nil {
+		return err
+	}
+	if err := c.PosixRename(tmp, name); err != nil {
+		c.Remove(tmp)
+		return fmt.Errorf("rename %s: %w", tmp, err)
+	}
+	return nil
+}
+
+// writeSFTP creates or truncates name and writes data to it
+func writeSFTP(c *sftp.Client, name string, data []byte) error {
+	f, err := c.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", name, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		return fmt.Errorf("chmod %s: %w", name, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", name, err)
+	}
+	return nil
+}
+
+// postHook sends the files to an HTTP hook, which writes them on the game server host
+func (u *fileUploader) postHook(ctx context.Context, target *url.URL, server string, files []renderedFile) error {
+	payload := struct {
+		Server string            `json:"server"`
+		Files  map[string]string `json:"files"`
+	}{Server: server, Files: make(map[string]string, len(files))}
+	for _, f := range files {
+		payload.Files[f.name] = string(f.data)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if u.hookToken != "" {
+		req.Header.Set("Authorization", "Bearer "+u.hookToken)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("(%s) request failed: %w", target.Redacted(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("(%s) returned status %d", target.Redacted(), resp.StatusCode)
+	}
+	return nil
+}
+
+// ================= DEPLOYER =================
+
+// deploymentRecord is one deployment in deployments.json, with the content needed to roll back to it
+type deploymentRecord struct {
+	ID         int               `json:"id"`
+	Target     string            `json:"target"`
+	DeployedAt time.Time         `json:"deployed_at"`
+	By         string            `json:"by,omitempty"`
+	Files      map[string]string `json:"files"` // file name -> content
+	RollbackOf int               `json:"rollback_of,omitempty"`
+}
+
+// configDeployer renders server config files from the templates in the config, uploads them and keeps
+// the last deployHistoryMax deployments per server for diffs and rollbacks (deployments.json)
+type configDeployer struct {
+	config    func() *Config
+	transport deployTransport
+	path      string
+
+	// mu serializes deployments and guards the history
+	mu      sync.Mutex
+	loaded  bool
+	history map[string][]deploymentRecord // by server, oldest first
+}
+
+func newConfigDeployer(config func() *Config, transport deployTransport, path string) *configDeployer {
+	return &configDeployer{config: config, transport: transport, path: path, history: make(map[string][]deploymentRecord)}
+}
+
+// configDeployerFromEnv returns the deployer if DEPLOY_ENABLED is true, nil otherwise
+// SFTP targets authenticate with DEPLOY_SSH_KEY_FILE and/or DEPLOY_SSH_PASSWORD and verify host keys
+// against DEPLOY_SSH_KNOWN_HOSTS; hooks receive DEPLOY_HOOK_TOKEN as Bearer token
+func configDeployerFromEnv(cm *ConfigManager) (*configDeployer, error) {
+	if os.Getenv("DEPLOY_ENABLED") != "true" {
+		return nil, nil
+	}
+	up := &fileUploader{hookToken: os.Getenv("DEPLOY_HOOK_TOKEN"), client: &http.Client{Timeout: deployTimeout}}
+	if file := os.Getenv("DEPLOY_SSH_KNOWN_HOSTS"); file != "" {
+		hostKeys, err := knownhosts.New(file)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEPLOY_SSH_KNOWN_HOSTS: %w", err)
+		}
+		up.hostKeys = hostKeys
+	}
+	if file := os.Getenv("DEPLOY_SSH_KEY_FILE"); file != "" {
+		key, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read DEPLOY_SSH_KEY_FILE: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEPLOY_SSH_KEY_FILE (passphrase-protected keys are not supported): %w", err)
+		}
+		up.sshAuth = append(up.sshAuth, ssh.PublicKeys(signer))
+	}
+	if password := os.Getenv("DEPLOY_SSH_PASSWORD"); password != "" {
+		up.sshAuth = append(up.sshAuth, ssh.Password(password))
+	}
+	path := filepath.Join(filepath.Dir(cm.configPath), deploymentsStateFile)
+	log.Printf("Config file deployment enabled (SFTP: %v, history in %s)", up.hostKeys != nil, path)
+	return newConfigDeployer(cm.GetConfig, up, path), nil
+}
+
+// load reads the history once (caller holds d.mu)
+func (d *configDeployer) load() {
+	if d.loaded || d.path == "" {
+		return
+	}
+	d.loaded = true
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Warning: failed to read deployment history %s: %v", d.path, err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &d.history); err != nil {
+		log.Printf("Warning: ignoring invalid deployment history %s: %v", d.path, err)
+		d.history = make(map[string][]deploymentRecord)
+	}
+}
+
+// save writes the history (caller holds d.mu); it holds file contents, so it is private to the bot
+func (d *configDeployer) save() {
+	if d.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(d.history, "", "  ")
+	if err == nil {
+		err = os.WriteFile(d.path, append(data, '\n'), 0600)
+	}
+	if err != nil {
+		log.Printf("Warning: failed to write deployment history %s: %v", d.path, err)
+	}
+}
+
+// Deployments implements api.ConfigDeployer
+func (d *configDeployer) Deployments(server string) ([]api.Deployment, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.load()
+	history := d.history[server]
+	if len(history) == 0 {
+		if _, _, err := renderServerFiles(d.config(), server); err != nil {
+			return nil, err
+		}
+	}
+	out := make([]api.Deployment, 0, len(history))
+	for _, rec := range slices.Backward(history) {
+		out = append(out, rec.apiDeployment(server))
+	}
+	return out, nil
+}
+
+// PreviewDeployment implements api.ConfigDeployer
+func (d *configDeployer) PreviewDeployment(server string) (api.DeploymentPreview, error) {
+	target, files, err := renderServerFiles(d.config(), server)
+	if err != nil {
+		return api.DeploymentPreview{}, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.load()
+	var base deploymentRecord
+	if history := d.history[server]; len(history) > 0 {
+		base = history[len(history)-1]
+	}
+	preview := api.DeploymentPreview{Server: server, Target: target.Redacted(), Base: base.ID, Files: make([]api.FileDiff, 0, len(files))}
+	for _, f := range files {
+		diff := unifiedDiff(f.name, base.Files[f.name], string(f.data))
+		preview.Files = append(preview.Files, api.FileDiff{Name: f.name, Changed: diff != "", Diff: diff})
+	}
+	return preview, nil
+}
+
+// Deploy implements api.ConfigDeployer
+func (d *configDeployer) Deploy(ctx context.Context, server, by string) (api.Deployment, error) {
+	target, files, err := renderServerFiles(d.config(), server)
+	if err != nil {
+		return api.Deployment{}, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.load()
+	rec, err := d.upload(ctx, server, target, files, by, 0)
+	if err != nil {
+		return api.Deployment{}, err
+	}
+	return rec.apiDeployment(server), nil
+}
+
+// Rollback implements api.ConfigDeployer: uploads the files of deployment id (0 = the one before the
+// latest) to the server's current target and records that as a new deployment
+func (d *configDeployer) Rollback(ctx context.Context, server string, id int, by string) (api.Deployment, error) {
+	target, _, err := renderServerFiles(d.config(), server)
+	if err != nil {
+		return api.Deployment{}, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.load()
+	history := d.history[server]
+	var from deploymentRecord
+	switch {
+	case id == 0 && len(history) >= 2:
+		from = history[len(history)-2]
+	case id != 0:
+		i := slices.IndexFunc(history, func(r deploymentRecord) bool { return r.ID == id })
+		if i < 0 {
+			return api.Deployment{}, fmt.Errorf("%w: #%d is not in the last %d deployments of '%s'", api.ErrUnknownDeployment, id, deployHistoryMax, server)
+		}
+		from = history[i]
+	default:
+		return api.Deployment{}, fmt.Errorf("%w: '%s' has no earlier deployment to roll back to", api.ErrUnknownDeployment, server)
+	}
+
+	files := make([]renderedFile, 0, len(from.Files))
+	for _, name := range slices.Sorted(maps.Keys(from.Files)) {
+		files = append(files, renderedFile{name: name, data: []byte(from.Files[name])})
+	}
+	rec, err := d.upload(ctx, server, target, files, by, from.ID)
+	if err != nil {
+		return api.Deployment{}, err
+	}
+	return rec.apiDeployment(server), nil
+}
+
+// upload sends files to target and records the deployment (caller holds d.mu)
+func (d *configDeployer) upload(ctx context.Context, server string, target *url.URL, files []renderedFile, by string, rollbackOf int) (deploymentRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, deployTimeout)
+	defer cancel()
+	if err := d.transport.upload(ctx, target, server, files); err != nil {
+		return deploymentRecord{}, fmt.Errorf("%w: '%s' to %s: %v", api.ErrDeployFailed, server, target.Redacted(), err)
+	}
+
+	history := d.history[server]
+	rec := deploymentRecord{
+		ID: 1, Target: target.Redacted(), DeployedAt: time.Now().UTC(), By: by,
+		Files: make(map[string]string, len(files)), RollbackOf: rollbackOf,
+	}
+	if len(history) > 0 {
+		rec.ID = history[len(history)-1].ID + 1
+	}
+	for _, f := range files {
+		rec.Files[f.name] = string(f.data)
+	}
+	history = append(history, rec)
+	if len(history) > deployHistoryMax {
+		history = slices.Clone(history[len(history)-deployHistoryMax:])
+	}
+	d.history[server] = history
+	d.save()
+	log.Printf("Deployed %d config file(s) of '%s' to %s (#%d)", len(files), server, target.Redacted(), rec.ID)
+	return rec, nil
+}
+
+func (r deploymentRecord) apiDeployment(server string) api.Deployment {
+	return api.Deployment{
+		ID: r.ID, Server: server, Target: r.Target, DeployedAt: r.DeployedAt, By: r.By,
+		Files: slices.Sorted(maps.Keys(r.Files)), RollbackOf: r.RollbackOf,
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bombom/absa-ac/pkg/listen"
+)
+
+// ================= DIAGNOSTICS =================
+
+// processStart is when the process started, for the uptime in diagnostics
+var processStart = time.Now()
+
+// diagnosticsTimeout bounds how long the -diagnostics CLI waits for the running bot
+const diagnosticsTimeout = 5 * time.Second
+
+// diagnosticsProvider adapts Bot to api.DiagnosticsProvider
+type diagnosticsProvider struct {
+	bot *Bot
+}
+
+// Diagnostics implements api.DiagnosticsProvider
+func (p *diagnosticsProvider) Diagnostics() *api.DiagnosticsReport {
+	return p.bot.diagnostics(time.Now())
+}
+
+// diagnostics builds the report of the running bot: config summary, last reload, recent updates, gateway,
+// runtime and components. It holds counts and states only, no tokens or server addresses
+func (b *Bot) diagnostics(now time.Time) *api.DiagnosticsReport {
+	cm := b.configManager
+	cm.mu.RLock()
+	source := cm.configPath
+	if cm.readOnlySource != "" {
+		source = cm.readOnlySource
+	}
+	var reload *api.DiagnosticsReload
+	if cm.lastReload != nil {
+		last := *cm.lastReload
+		reload = &last
+	}
+	cm.mu.RUnlock()
+
+	started := processStart.UTC()
+	r := &api.DiagnosticsReport{
+		GeneratedAt: now.UTC(),
+		Source:      "running bot",
+		StartedAt:   &started,
+		Uptime:      now.Sub(processStart).Round(time.Second).String(),
+		Config:      configDiagnostics(source, cm.GetConfig()),
+		LastReload:  reload,
+		Updates: &api.DiagnosticsUpdates{
+			Recent:       b.updates.Recent(),
+			SkippedTicks: b.updates.SkippedTicks(),
+			SlowUpdates:  b.updates.SlowUpdates(),
+		},
+		Runtime:    runtimeDiagnostics(),
+		Components: b.componentChecks(now),
+	}
+
+	r.Config.Mode = "webhook"
+	if b.session != nil {
+		r.Config.Mode = "bot"
+		r.Discord = &api.DiagnosticsDiscord{Connected: b.gatewayUp.Load()}
+		if connects := b.gatewayConnects.Load(); connects > 1 {
+			r.Discord.Reconnects = connects - 1
+		}
+		if r.Discord.Connected {
+			r.Discord.HeartbeatLatencyMs = b.session.HeartbeatLatency().Milliseconds()
+		}
+		if p := b.privileges.Load(); p != nil {
+			r.Discord.Privileges = p.diagnostics()
+		}
+	}
+	for _, p := range b.publishers {
+		r.Config.Publishers = append(r.Config.Publishers, p.Name())
+	}
+	return r
+}
+
+// componentChecks reports whether each enabled long-lived component is running, whether the update loop
+// keeps up (the watchdog stall check) and the Discord permission self-check
+func (b *Bot) componentChecks(now time.Time) []api.HealthCheckResult {
+	checks := []api.HealthCheckResult{}
+	for _, c := range []struct {
+		name    string
+		enabled bool
+	}{
+		{componentUpdateLoop, true},
+		{componentAPIServer, b.apiServer != nil},
+		{componentProxyServer, b.proxyServer != nil},
+		{componentLeader, b.leader != nil},
+	} {
+		if !c.enabled {
+			continue
+		}
+		check := api.HealthCheckResult{Name: c.name, OK: b.lifecycle.Running(c.name)}
+		if !check.OK {
+			check.Detail = "not running"
+		}
+		checks = append(checks, check)
+	}
+
+	progress := api.HealthCheckResult{Name: "update progress", OK: b.watchdog.Healthy(now)}
+	if !progress.OK {
+		progress.Detail = fmt.Sprintf("no completed update in %d update intervals", b.watchdog.stallIntervals)
+	}
+	checks = append(checks, progress)
+
+	if report := b.permissions.Load(); report != nil {
+		checks = append(checks, report.healthCheck())
+	}
+	return checks
+}
+
+// configDiagnostics summarizes cfg loaded from source (nil = no config loaded)
+func configDiagnostics(source string, cfg *Config) api.DiagnosticsConfig {
+	d := api.DiagnosticsConfig{Source: source, Loaded: cfg != nil}
+	if cfg != nil {
+		d.Servers = len(cfg.Servers)
+		d.Categories = len(cfg.CategoryOrder)
+		d.UpdateIntervalSeconds = cfg.UpdateInterval
+	}
+	return d
+}
+
+// runtimeDiagnostics returns the Go runtime state of this process
+func runtimeDiagnostics() api.DiagnosticsRuntime {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return api.DiagnosticsRuntime{
+		GoVersion:      runtime.Version(),
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapSysBytes:   mem.HeapSys,
+		SysBytes:       mem.Sys,
+		NumGC:          mem.NumGC,
+	}
+}
+
+// runDiagnostics implements the -diagnostics flag: prints the running bot's report (fetched from its API)
+// as JSON to out. When the bot cannot be reached it prints an offline report of the config it would load
+func runDiagnostics(out io.Writer, configPath string) error {
+	report, err := fetchDiagnostics()
+	if err != nil {
+		report = offlineDiagnostics(configPath, err)
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// fetchDiagnostics asks the running bot's API for its report
+func fetchDiagnostics() (*api.DiagnosticsReport, error) {
+	if os.Getenv("API_ENABLED") != "true" {
+		return nil, fmt.Errorf("API_ENABLED is not true")
+	}
+	client, baseURL, err := diagnosticsClient()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", baseURL+api.DiagnosticsPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+os.Getenv("API_BEARER_TOKEN"))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered HTTP %d", baseURL+api.DiagnosticsPath, resp.StatusCode)
+	}
+	var report api.DiagnosticsReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("invalid diagnostics response: %w", err)
+	}
+	return &report, nil
+}
+
+// diagnosticsClient returns a client and base URL for the local API: the first TCP or Unix socket address of
+// API_LISTEN (systemd sockets cannot be dialed by name), else localhost on API_PORT
+func diagnosticsClient() (*http.Client, string, error) {
+	client := &http.Client{Timeout: diagnosticsTimeout}
+	if spec := os.Getenv("API_LISTEN"); spec != "" {
+		addrs, err := listen.Parse(spec)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid API_LISTEN: %w", err)
+		}
+		for _, a := range addrs {
+			switch a.Network {
+			case "unix":
+				client.Transport = &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", a.Address)
+				}}
+				return client, "http://localhost", nil
+			case "tcp":
+				host, port, _ := net.SplitHostPort(a.Address)
+				if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+					host = "localhost"
+				}
+				return client, "http://" + net.JoinHostPort(host, port), nil
+			}
+		}
+	}
+	port := os.Getenv("API_PORT")
+	if port == "" {
+		port = "3001"
+	}
+	return client, "http://localhost:" + port, nil
+}
+
+// offlineDiagnostics describes the config this binary would load and its runtime, for when the bot is not
+// running or its API is unreachable (reason)
+func offlineDiagnostics(configPath string, reason error) *api.DiagnosticsReport {
+	r := &api.DiagnosticsReport{
+		GeneratedAt: time.Now().UTC(),
+		Source:      fmt.Sprintf("offline (running bot not reachable: %v)", reason),
+		Runtime:     runtimeDiagnostics(),
+		Components:  []api.HealthCheckResult{},
+	}
+
+	cfg, source, err := configFromEnv()
+	if cfg == nil && err == nil {
+		source = getConfigPath(configPath)
+		cfg, err = loadConfig(configPath)
+	}
+	if err == nil && cfg != nil {
+		err = validateConfigStructSafeRuntime(cfg)
+	}
+	r.Config = configDiagnostics(source, cfg)
+	if err != nil {
+		r.Config.Loaded = false
+		r.Config.Error = err.Error()
+	}
+	return r
+}
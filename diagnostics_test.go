@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// TestDiagnostics_Report tests the config summary, update history cap and order, and component checks
+func TestDiagnostics_Report(t *testing.T) {
+	b := newTestBot(testStatusConfig())
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := range updateHistorySize + 2 {
+		b.updates.record(start.Add(time.Duration(i)*time.Minute), time.Duration(i)*time.Second)
+	}
+
+	r := b.diagnostics(time.Now())
+	if r.Source != "running bot" || !r.Config.Loaded || r.Config.Servers != len(testStatusConfig().Servers) || r.Config.Mode != "webhook" {
+		t.Errorf("Unexpected report %+v", r)
+	}
+	if recent := r.Updates.Recent; len(recent) != updateHistorySize || recent[0].DurationMs != 11000 || !recent[0].At.After(recent[1].At) {
+		t.Errorf("Expected the last %d updates newest first, got %+v", updateHistorySize, recent)
+	}
+	if len(r.Components) == 0 || r.Components[0].Name != componentUpdateLoop || r.Components[0].OK {
+		t.Errorf("Expected the stopped update loop reported, got %+v", r.Components)
+	}
+	if r.Discord != nil || r.Runtime.Goroutines == 0 || r.Runtime.GoVersion == "" {
+		t.Errorf("Expected runtime stats and no gateway in webhook mode, got %+v", r)
+	}
+}
+
+// TestConfigManager_RecordsReload tests that failed and successful reloads are kept for diagnostics
+func TestConfigManager_RecordsReload(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	cfg := testStatusConfig()
+	data, _ := json.Marshal(cfg)
+	os.WriteFile(configPath, data, 0644)
+	cm := NewConfigManager(configPath, cfg)
+
+	os.WriteFile(configPath, []byte(`{"server_ip": `), 0644)
+	if err := cm.checkAndReloadIfNeeded(); err == nil {
+		t.Fatal("Expected a parse error")
+	}
+	if cm.lastReload == nil || cm.lastReload.OK || !strings.Contains(cm.lastReload.Error, "parse") {
+		t.Errorf("Expected the failed reload recorded, got %+v", cm.lastReload)
+	}
+
+	os.WriteFile(configPath, append(data, '\n'), 0644)
+	if err := cm.checkAndReloadIfNeeded(); err != nil {
+		t.Fatalf("checkAndReloadIfNeeded failed: %v", err)
+	}
+	if !cm.lastReload.OK || cm.lastReload.Error != "" {
+		t.Errorf("Expected the successful reload recorded, got %+v", cm.lastReload)
+	}
+}
+
+// TestRunDiagnostics tests fetching the running bot's report and the offline fallback
+func TestRunDiagnostics(t *testing.T) {
+	bot := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != api.DiagnosticsPath || r.Header.Get("Authorization") != "Bearer diag-token" {
+			http.Error(w, "unexpected request", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(api.DiagnosticsReport{Source: "running bot", Uptime: "1h0m0s"})
+	}))
+	defer bot.Close()
+	t.Setenv("CONFIG_JSON", "")
+	t.Setenv("ABSA_SERVERS", "")
+	t.Setenv("API_ENABLED", "true")
+	t.Setenv("API_BEARER_TOKEN", "diag-token")
+	t.Setenv("API_LISTEN", strings.TrimPrefix(bot.URL, "http://"))
+
+	var out strings.Builder
+	if err := runDiagnostics(&out, ""); err != nil {
+		t.Fatalf("runDiagnostics failed: %v", err)
+	}
+	if !strings.Contains(out.String(), `"uptime": "1h0m0s"`) {
+		t.Errorf("Expected the running bot's report, got:\n%s", out.String())
+	}
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	data, _ := json.Marshal(testStatusConfig())
+	os.WriteFile(configPath, data, 0644)
+	t.Setenv("API_ENABLED", "")
+	out.Reset()
+	if err := runDiagnostics(&out, configPath); err != nil {
+		t.Fatalf("runDiagnostics failed: %v", err)
+	}
+	var report api.DiagnosticsReport
+	if err := json.Unmarshal([]byte(out.String()), &report); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if !strings.HasPrefix(report.Source, "offline") || !report.Config.Loaded || report.Config.Source != configPath || report.Config.Categories != 2 {
+		t.Errorf("Expected an offline report of the config file, got %+v", report)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= DISCORD ERROR HANDLING =================
+
+// discordErrorKind classifies a failed Discord request by how the bot should react
+type discordErrorKind int
+
+const (
+	// discordErrOther is any other client error (bad request, unknown message, ...): not retried
+	discordErrOther discordErrorKind = iota
+	// discordErrAuth means the token or webhook is no longer valid: the bot cannot recover
+	discordErrAuth
+	// discordErrPermission means the bot lacks access or permissions in the channel
+	discordErrPermission
+	// discordErrUnknownChannel means the channel was deleted
+	discordErrUnknownChannel
+	// discordErrRateLimited is a 429 that outlasted discordgo's own rate limit handling
+	discordErrRateLimited
+	// discordErrServer is a 5xx response
+	discordErrServer
+	// discordErrNetwork is a failure without an HTTP response (DNS, reset, timeout)
+	discordErrNetwork
+)
+
+// String implements fmt.Stringer for logs
+func (k discordErrorKind) String() string {
+	switch k {
+	case discordErrAuth:
+		return "auth revoked"
+	case discordErrPermission:
+		return "permission missing"
+	case discordErrUnknownChannel:
+		return "channel deleted"
+	case discordErrRateLimited:
+		return "rate limited"
+	case discordErrServer:
+		return "server error"
+	case discordErrNetwork:
+		return "network error"
+	default:
+		return "request rejected"
+	}
+}
+
+// transient reports whether the request may succeed when repeated
+func (k discordErrorKind) transient() bool {
+	return k == discordErrRateLimited || k == discordErrServer || k == discordErrNetwork
+}
+
+// classifyDiscordError maps an error returned by discordgo (possibly wrapped) to a discordErrorKind
+// The JSON error code is preferred over the HTTP status where Discord provides one
+func classifyDiscordError(err error) discordErrorKind {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response == nil {
+		return discordErrNetwork
+	}
+	if restErr.Message != nil {
+		switch restErr.Message.Code {
+		case discordgo.ErrCodeUnauthorized, discordgo.ErrCodeUnknownWebhook:
+			return discordErrAuth
+		case discordgo.ErrCodeMissingAccess, discordgo.ErrCodeMissingPermissions:
+			return discordErrPermission
+		case discordgo.ErrCodeUnknownChannel:
+			return discordErrUnknownChannel
+		}
+	}
+	switch code := restErr.Response.StatusCode; {
+	case code == http.StatusUnauthorized:
+		return discordErrAuth
+	case code == http.StatusForbidden:
+		return discordErrPermission
+	case code == http.StatusTooManyRequests:
+		return discordErrRateLimited
+	case code >= 500:
+		return discordErrServer
+	}
+	return discordErrOther
+}
+
+// Circuit breaker tuning: after breakerThreshold consecutive server errors, Discord requests pause for breakerCooldown
+const (
+	breakerThreshold = 5
+	breakerCooldown  = time.Minute
+)
+
+// errCircuitOpen is returned instead of calling Discord while the breaker is open
+var errCircuitOpen = errors.New("Discord circuit breaker open after repeated server errors")
+
+// circuitBreaker stops hammering Discord during an outage
+// Open after breakerThreshold consecutive 5xx/network errors; after the cooldown one trial request
+// is let through (half-open) and its result closes or reopens the breaker
+type circuitBreaker struct {
+	mu          sync.Mutex
+	consecutive int
+	openUntil   time.Time
+	trips       int
+}
+
+// allow reports whether a request may be sent now, and when to try again if not
+func (c *circuitBreaker) allow(now time.Time) (bool, time.Time) {
+	if c == nil {
+		return true, time.Time{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now.Before(c.openUntil) {
+		return false, c.openUntil
+	}
+	return true, time.Time{}
+}
+
+// record updates the breaker with the result of a request
+func (c *circuitBreaker) record(err error, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		if !c.openUntil.IsZero() {
+			log.Println("Discord circuit breaker closed: requests succeed again")
+		}
+		c.consecutive = 0
+		c.openUntil = time.Time{}
+		return
+	}
+	kind := classifyDiscordError(err)
+	if kind != discordErrServer && kind != discordErrNetwork {
+		return
+	}
+	c.consecutive++
+	if c.consecutive >= breakerThreshold {
+		c.openUntil = now.Add(breakerCooldown)
+		c.trips++
+		log.Printf("Discord circuit breaker open for %v after %d consecutive server errors (tripped %d times)", breakerCooldown, c.consecutive, c.trips)
+	}
+}
+
+// discordErrorHandler reacts to non-transient Discord errors reported by a retryPublisher
+type discordErrorHandler interface {
+	discordFailed(kind discordErrorKind, err error)
+	// discordUnreachable reports that the circuit breaker is open: Discord keeps failing with server or network errors
+	discordUnreachable(err error)
+	discordRecovered()
+}
+
+// discordFailed implements discordErrorHandler for the bot:
+//   - auth revoked: shut down and exit non-zero (nothing works until the token/webhook is replaced)
+//   - permission missing: alert once until an update succeeds again
+//   - channel deleted: look for a recreated channel with the same name (bot mode)
+func (b *Bot) discordFailed(kind discordErrorKind, err error) {
+	switch kind {
+	case discordErrAuth:
+		b.openDiscordIncident(err)
+		b.fail(fmt.Errorf("Discord rejected the credentials (%v): replace DISCORD_TOKEN or DISCORD_WEBHOOK_URL", err))
+	case discordErrPermission:
+		b.openDiscordIncident(err)
+		if b.permissionAlerted.CompareAndSwap(false, true) {
+			log.Printf("Discord permission missing: %v (check View Channel, Send Messages, Embed Links, Attach Files and Read Message History)", err)
+			b.alert(newAlertEvent(alertDiscordPermission, "⚠️ Status bot is missing permissions in the status channel and cannot update the status message."))
+		}
+	case discordErrUnknownChannel:
+		if b.discord == nil {
+			b.fail(fmt.Errorf("Discord channel was deleted (%v): create a new webhook", err))
+			return
+		}
+		if err := b.discord.reresolveChannel(); err != nil {
+			log.Printf("Error: status channel was deleted and could not be re-resolved: %v", err)
+			return
+		}
+		b.requestRefresh()
+	}
+}
+
+// discordRecovered implements discordErrorHandler: re-arms the permission alert
+func (b *Bot) discordRecovered() {
+	b.permissionAlerted.Store(false)
+	if b.incidents != nil {
+		b.incidents.resolve(incidentDiscordUnreachable)
+	}
+}
+
+// fail requests a shutdown with a non-zero exit code (first error wins, never blocks)
+func (b *Bot) fail(err error) {
+	log.Printf("Fatal: %v", err)
+	select {
+	case b.fatal <- err:
+	default:
+	}
+}
+
+// rememberChannel records the status channel's name and location so it can be found again if recreated
+func (d *DiscordPublisher) rememberChannel() error {
+	ch, err := d.session.Channel(d.channel())
+	if err != nil {
+		return fmt.Errorf("failed to look up status channel: %w", err)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.channelName, d.guildID, d.parentID = ch.Name, ch.GuildID, ch.ParentID
+	return nil
+}
+
+// findRecreatedChannel picks the text channel that replaces a deleted one: same name, preferring the same category
+func findRecreatedChannel(channels []*discordgo.Channel, name, parentID, deletedID string) *discordgo.Channel {
+	var match *discordgo.Channel
+	for _, ch := range channels {
+		if ch.ID == deletedID || ch.Name != name || ch.Type != discordgo.ChannelTypeGuildText {
+			continue
+		}
+		if ch.ParentID == parentID {
+			return ch
+		}
+		if match == nil {
+			match = ch
+		}
+	}
+	return match
+}
+
+// reresolveChannel switches to a recreated status channel after the old one was deleted
+// The status message is forgotten so the next update posts a new one in the new channel
+func (d *DiscordPublisher) reresolveChannel() error {
+	d.mu.RLock()
+	oldID, name, guildID, parentID := d.channelID, d.channelName, d.guildID, d.parentID
+	d.mu.RUnlock()
+	if name == "" || guildID == "" {
+		return fmt.Errorf("channel %s was never looked up, cannot find a replacement", oldID)
+	}
+
+	channels, err := d.session.GuildChannels(guildID)
+	if err != nil {
+		return fmt.Errorf("failed to list guild channels: %w", err)
+	}
+	ch := findRecreatedChannel(channels, name, parentID, oldID)
+	if ch == nil {
+		return fmt.Errorf("no text channel named #%s found, set CHANNEL_ID to the new channel", name)
+	}
+
+	d.mu.Lock()
+	d.channelID = ch.ID
+	d.parentID = ch.ParentID
+	d.statusIDs = nil
+	d.mu.Unlock()
+	log.Printf("Status channel #%s was recreated, switched from %s to %s (update CHANNEL_ID to keep it after a restart)", name, oldID, ch.ID)
+	return nil
+}
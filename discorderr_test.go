@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// apiError builds a discordgo REST error with an HTTP status and a Discord JSON error code
+func apiError(status, code int) error {
+	return &discordgo.RESTError{
+		Response: &http.Response{StatusCode: status},
+		Message:  &discordgo.APIErrorMessage{Code: code},
+	}
+}
+
+// TestClassifyDiscordError tests the mapping of statuses and JSON error codes
+func TestClassifyDiscordError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want discordErrorKind
+	}{
+		{"Revoked token", restError(http.StatusUnauthorized), discordErrAuth},
+		{"Deleted webhook", apiError(http.StatusNotFound, discordgo.ErrCodeUnknownWebhook), discordErrAuth},
+		{"Missing permissions", apiError(http.StatusForbidden, discordgo.ErrCodeMissingPermissions), discordErrPermission},
+		{"Missing access", apiError(http.StatusForbidden, discordgo.ErrCodeMissingAccess), discordErrPermission},
+		{"Deleted channel", apiError(http.StatusNotFound, discordgo.ErrCodeUnknownChannel), discordErrUnknownChannel},
+		{"Unknown message", apiError(http.StatusNotFound, discordgo.ErrCodeUnknownMessage), discordErrOther},
+		{"Rate limited", restError(http.StatusTooManyRequests), discordErrRateLimited},
+		{"Server error", restError(http.StatusBadGateway), discordErrServer},
+		{"Wrapped server error", fmt.Errorf("failed to edit message: %w", restError(http.StatusInternalServerError)), discordErrServer},
+		{"Network error", errors.New("connection reset by peer"), discordErrNetwork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyDiscordError(tt.err); got != tt.want {
+				t.Errorf("Got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, k := range []discordErrorKind{discordErrRateLimited, discordErrServer, discordErrNetwork} {
+		if !k.transient() {
+			t.Errorf("Expected %v to be transient", k)
+		}
+	}
+	for _, k := range []discordErrorKind{discordErrAuth, discordErrPermission, discordErrUnknownChannel, discordErrOther} {
+		if k.transient() {
+			t.Errorf("Expected %v not to be transient", k)
+		}
+	}
+}
+
+// TestCircuitBreaker tests opening after repeated server errors, the cooldown and closing on success
+func TestCircuitBreaker(t *testing.T) {
+	c := &circuitBreaker{}
+	now := time.Now()
+
+	for i := 0; i < breakerThreshold-1; i++ {
+		c.record(restError(http.StatusBadGateway), now)
+	}
+	c.record(restError(http.StatusForbidden), now) // client errors neither count nor reset
+	if ok, _ := c.allow(now); !ok {
+		t.Fatal("Breaker opened before the threshold")
+	}
+
+	c.record(restError(http.StatusBadGateway), now)
+	ok, until := c.allow(now)
+	if ok || !until.Equal(now.Add(breakerCooldown)) {
+		t.Fatalf("Expected breaker open until %v, got ok=%v until=%v", now.Add(breakerCooldown), ok, until)
+	}
+
+	// Half-open after the cooldown; a success closes it
+	later := now.Add(breakerCooldown)
+	if ok, _ := c.allow(later); !ok {
+		t.Fatal("Expected a trial request after the cooldown")
+	}
+	c.record(nil, later)
+	c.record(restError(http.StatusBadGateway), later)
+	if ok, _ := c.allow(later); !ok {
+		t.Error("Expected breaker closed after a success")
+	}
+}
+
+// TestRetryPublisher_CircuitOpenSkipsRequests tests that an open breaker queues updates without calling Discord
+func TestRetryPublisher_CircuitOpenSkipsRequests(t *testing.T) {
+	inner := &scriptedPublisher{}
+	r := newTestRetryPublisher(inner)
+	h := &recordingHandler{}
+	r.handler = h
+	for i := 0; i < breakerThreshold; i++ {
+		r.breaker.record(restError(http.StatusServiceUnavailable), time.Now())
+	}
+
+	u := &StatusUpdate{}
+	if err := r.UpdateStatus(u); !errors.Is(err, errCircuitOpen) {
+		t.Fatalf("Expected errCircuitOpen, got %v", err)
+	}
+	if calls, _ := inner.snapshot(); calls != 0 {
+		t.Errorf("Expected no Discord call while open, got %d", calls)
+	}
+	if r.current() != u {
+		t.Error("Expected update queued until the breaker closes")
+	}
+	if h.unreachable != 1 {
+		t.Errorf("Expected the handler told Discord is unreachable, got %d", h.unreachable)
+	}
+	r.mu.Lock()
+	scheduled := r.timer != nil
+	if scheduled {
+		r.timer.Stop()
+	}
+	r.mu.Unlock()
+	if !scheduled {
+		t.Error("Expected a retry scheduled for the end of the cooldown")
+	}
+}
+
+// recordingHandler records discordErrorHandler calls
+type recordingHandler struct {
+	failed      []discordErrorKind
+	unreachable int
+	recovered   int
+}
+
+func (h *recordingHandler) discordFailed(kind discordErrorKind, err error) {
+	h.failed = append(h.failed, kind)
+}
+
+func (h *recordingHandler) discordUnreachable(err error) { h.unreachable++ }
+
+func (h *recordingHandler) discordRecovered() { h.recovered++ }
+
+// TestRetryPublisher_ReportsToHandler tests that permanent errors and recoveries reach the handler
+func TestRetryPublisher_ReportsToHandler(t *testing.T) {
+	h := &recordingHandler{}
+	inner := &scriptedPublisher{errs: []error{apiError(http.StatusForbidden, discordgo.ErrCodeMissingPermissions)}}
+	r := newRetryPublisher(inner, h)
+	r.sleep = func(time.Duration) {}
+
+	r.UpdateStatus(&StatusUpdate{})
+	if len(h.failed) != 1 || h.failed[0] != discordErrPermission {
+		t.Fatalf("Expected one permission failure, got %v", h.failed)
+	}
+	r.UpdateStatus(&StatusUpdate{})
+	if h.recovered != 1 {
+		t.Errorf("Expected recovery after a successful update, got %d", h.recovered)
+	}
+}
+
+// TestBotDiscordFailed tests the bot's reaction per error kind
+func TestBotDiscordFailed(t *testing.T) {
+	b := newTestBot(testStatusConfig())
+	b.fatal = make(chan error, 1)
+	pub := &fakePublisher{name: "fake"}
+	b.publishers = []Publisher{pub}
+
+	// Missing permissions alert once until recovered
+	b.discordFailed(discordErrPermission, errors.New("403"))
+	b.discordFailed(discordErrPermission, errors.New("403"))
+	if len(pub.alerts) != 1 {
+		t.Errorf("Expected one permission alert, got %d", len(pub.alerts))
+	}
+	b.discordRecovered()
+	b.discordFailed(discordErrPermission, errors.New("403"))
+	if len(pub.alerts) != 2 {
+		t.Errorf("Expected a new alert after recovery, got %d", len(pub.alerts))
+	}
+
+	// Revoked credentials request a fatal shutdown
+	b.discordFailed(discordErrAuth, errors.New("401"))
+	select {
+	case err := <-b.fatal:
+		if err == nil {
+			t.Error("Expected fatal error")
+		}
+	default:
+		t.Error("Expected fatal shutdown request for revoked credentials")
+	}
+
+	// Deleted channel without a bot session (webhook mode) is fatal too
+	b.discordFailed(discordErrUnknownChannel, errors.New("404"))
+	if len(b.fatal) != 1 {
+		t.Error("Expected fatal shutdown request for a deleted webhook channel")
+	}
+}
+
+// TestFindRecreatedChannel tests picking the replacement channel by name, preferring the same category
+func TestFindRecreatedChannel(t *testing.T) {
+	channels := []*discordgo.Channel{
+		{ID: "1", Name: "status", Type: discordgo.ChannelTypeGuildText, ParentID: "old"},
+		{ID: "2", Name: "status", Type: discordgo.ChannelTypeGuildVoice, ParentID: "cat"},
+		{ID: "3", Name: "status", Type: discordgo.ChannelTypeGuildText, ParentID: "other"},
+		{ID: "4", Name: "status", Type: discordgo.ChannelTypeGuildText, ParentID: "cat"},
+		{ID: "5", Name: "chat", Type: discordgo.ChannelTypeGuildText, ParentID: "cat"},
+	}
+	if ch := findRecreatedChannel(channels, "status", "cat", "9"); ch == nil || ch.ID != "4" {
+		t.Errorf("Expected channel 4 (same category), got %+v", ch)
+	}
+	if ch := findRecreatedChannel(channels, "status", "gone", "1"); ch == nil || ch.ID != "3" {
+		t.Errorf("Expected channel 3 (first text match, deleted ID skipped), got %+v", ch)
+	}
+	if ch := findRecreatedChannel(channels, "missing", "cat", "9"); ch != nil {
+		t.Errorf("Expected no match, got %+v", ch)
+	}
+}
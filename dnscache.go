@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ================= DNS CACHE =================
+
+// defaultDNSCacheTTL is how long resolved server_ip hostnames are reused before re-resolving
+const defaultDNSCacheTTL = 60 * time.Second
+
+// hostResolver is the subset of *net.Resolver used by dnsCache (replaced in tests)
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// dnsCache caches hostname lookups for the polling transport
+// When server_ip is a hostname every poll would otherwise re-resolve it (once per server per cycle)
+// On resolution failure the last known addresses are served (stale) so a flaky resolver
+// does not mark every server offline
+type dnsCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	entries  map[string]dnsEntry
+	resolver hostResolver
+	now      func() time.Time
+
+	failures atomic.Uint64
+}
+
+func newDNSCache(ttl time.Duration) *dnsCache {
+	return &dnsCache{
+		ttl:      ttl,
+		entries:  make(map[string]dnsEntry),
+		resolver: net.DefaultResolver,
+		now:      time.Now,
+	}
+}
+
+// SetTTL changes the cache TTL; existing entries keep their expiry
+func (c *dnsCache) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+}
+
+// ResolutionFailures returns the number of failed lookups (including ones served from stale cache)
+func (c *dnsCache) ResolutionFailures() uint64 {
+	return c.failures.Load()
+}
+
+// Lookup resolves host, returning cached addresses while fresh
+// IP literals are returned as-is without touching the resolver
+func (c *dnsCache) Lookup(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+
+	c.mu.Lock()
+	entry, found := c.entries[host]
+	ttl := c.ttl
+	c.mu.Unlock()
+
+	if found && c.now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		c.failures.Add(1)
+		if err == nil {
+			err = fmt.Errorf("no addresses found for %s", host)
+		}
+		if found {
+			log.Printf("Warning: DNS lookup for %s failed, using last known address %v: %v", host, entry.addrs, err)
+			return entry.addrs, nil
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[host] = dnsEntry{addrs: addrs, expires: c.now().Add(ttl)}
+	c.mu.Unlock()
+
+	return addrs, nil
+}
+
+// DialContext wraps dialer, resolving the host through the cache and trying each address in turn
+func (c *dnsCache) DialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		ips, err := c.Lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var lastErr error
+		for _, ip := range ips {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+}
+
+// pollDNS is shared by every polling transport so cache entries survive transport rebuilds
+var pollDNS = newDNSCache(defaultDNSCacheTTL)
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeResolver returns canned answers and counts lookups
+type fakeResolver struct {
+	addrs []string
+	err   error
+	calls int
+}
+
+func (f *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	f.calls++
+	return f.addrs, f.err
+}
+
+func newTestDNSCache(r *fakeResolver, ttl time.Duration, now *time.Time) *dnsCache {
+	c := newDNSCache(ttl)
+	c.resolver = r
+	c.now = func() time.Time { return *now }
+	return c
+}
+
+// TestDNSCache_CachesWithinTTL tests that a hostname is resolved once per TTL
+func TestDNSCache_CachesWithinTTL(t *testing.T) {
+	now := time.Now()
+	r := &fakeResolver{addrs: []string{"10.0.0.1"}}
+	c := newTestDNSCache(r, time.Minute, &now)
+
+	for i := 0; i < 5; i++ {
+		addrs, err := c.Lookup(context.Background(), "ac.example.com")
+		if err != nil {
+			t.Fatalf("Lookup failed: %v", err)
+		}
+		if addrs[0] != "10.0.0.1" {
+			t.Errorf("Expected 10.0.0.1, got %v", addrs)
+		}
+	}
+	if r.calls != 1 {
+		t.Errorf("Expected 1 resolver call within TTL, got %d", r.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	c.Lookup(context.Background(), "ac.example.com")
+	if r.calls != 2 {
+		t.Errorf("Expected re-resolve after TTL, got %d calls", r.calls)
+	}
+}
+
+// TestDNSCache_IPLiteralBypassesResolver tests that IP addresses are never looked up
+func TestDNSCache_IPLiteralBypassesResolver(t *testing.T) {
+	now := time.Now()
+	r := &fakeResolver{}
+	c := newTestDNSCache(r, time.Minute, &now)
+
+	addrs, err := c.Lookup(context.Background(), "192.168.1.10")
+	if err != nil || len(addrs) != 1 || addrs[0] != "192.168.1.10" {
+		t.Errorf("Expected IP literal returned as-is, got %v (%v)", addrs, err)
+	}
+	if r.calls != 0 {
+		t.Errorf("Expected no resolver calls for IP literal, got %d", r.calls)
+	}
+}
+
+// TestDNSCache_StaleFallbackOnFailure tests that the last known address is used when resolution fails
+func TestDNSCache_StaleFallbackOnFailure(t *testing.T) {
+	now := time.Now()
+	r := &fakeResolver{addrs: []string{"10.0.0.1"}}
+	c := newTestDNSCache(r, time.Minute, &now)
+
+	if _, err := c.Lookup(context.Background(), "ac.example.com"); err != nil {
+		t.Fatalf("Initial lookup failed: %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	r.addrs, r.err = nil, errors.New("resolver unavailable")
+
+	addrs, err := c.Lookup(context.Background(), "ac.example.com")
+	if err != nil {
+		t.Fatalf("Expected stale fallback, got error: %v", err)
+	}
+	if addrs[0] != "10.0.0.1" {
+		t.Errorf("Expected stale address 10.0.0.1, got %v", addrs)
+	}
+	if got := c.ResolutionFailures(); got != 1 {
+		t.Errorf("Expected 1 resolution failure, got %d", got)
+	}
+}
+
+// TestDNSCache_FailureWithoutCache tests that an unknown host failure is returned
+func TestDNSCache_FailureWithoutCache(t *testing.T) {
+	now := time.Now()
+	r := &fakeResolver{err: errors.New("no such host")}
+	c := newTestDNSCache(r, time.Minute, &now)
+
+	if _, err := c.Lookup(context.Background(), "missing.example.com"); err == nil {
+		t.Error("Expected error for unresolvable host without cache entry")
+	}
+	if got := c.ResolutionFailures(); got != 1 {
+		t.Errorf("Expected 1 resolution failure, got %d", got)
+	}
+}
+
+// TestDNSCache_DialContext tests dialing through the cache
+func TestDNSCache_DialContext(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+
+	now := time.Now()
+	r := &fakeResolver{addrs: []string{"127.0.0.1"}}
+	c := newTestDNSCache(r, time.Minute, &now)
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	dial := c.DialContext(&net.Dialer{Timeout: time.Second})
+	conn, err := dial(context.Background(), "tcp", net.JoinHostPort("ac.example.com", port))
+	if err != nil {
+		t.Fatalf("Dial through cache failed: %v", err)
+	}
+	conn.Close()
+
+	if r.calls != 1 {
+		t.Errorf("Expected 1 resolver call, got %d", r.calls)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ================= DOCKER =================
+
+const (
+	// defaultDockerHost is the Docker Engine socket of a standard install
+	defaultDockerHost = "unix:///var/run/docker.sock"
+	// dockerAPIVersion is the Engine API version requested (Docker 20.10 and later)
+	dockerAPIVersion = "v1.41"
+	// dockerStopSeconds is how long stop and restart wait for the AC server to exit before killing it
+	dockerStopSeconds = 10
+)
+
+// containerNamePattern matches a Docker container name or ID
+var containerNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,127}$`)
+
+// validateContainers checks the containers section (server name -> container name)
+func validateContainers(containers map[string]string, servers []Server) error {
+	serverNames := make(map[string]bool, len(servers))
+	for _, s := range servers {
+		serverNames[s.Name] = true
+	}
+	for _, server := range slices.Sorted(maps.Keys(containers)) {
+		if !serverNames[server] {
+			return fmt.Errorf("container for server '%s' which is not defined in servers", server)
+		}
+		if !containerNamePattern.MatchString(containers[server]) {
+			return fmt.Errorf("container of server '%s': '%s' is not a valid container name", server, containers[server])
+		}
+	}
+	return nil
+}
+
+// dockerBackend starts, stops and inspects the containers mapped to servers in the containers section
+// It talks to the Docker Engine API over its Unix socket
+type dockerBackend struct {
+	client *http.Client
+}
+
+// dockerBackendFromEnv returns the backend if DOCKER_ENABLED is true, nil otherwise
+// DOCKER_HOST selects the socket (unix:// only, default unix:///var/run/docker.sock)
+func dockerBackendFromEnv() (*dockerBackend, error) {
+	if os.Getenv("DOCKER_ENABLED") != "true" {
+		return nil, nil
+	}
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = defaultDockerHost
+	}
+	socket, ok := strings.CutPrefix(host, "unix://")
+	if !ok || socket == "" {
+		return nil, fmt.Errorf("invalid DOCKER_HOST %q: only unix:// sockets are supported", host)
+	}
+	log.Printf("Docker integration enabled (socket %s)", socket)
+	return newDockerBackend(socket), nil
+}
+
+// newDockerBackend returns a backend talking to the Engine API on socket
+func newDockerBackend(socket string) *dockerBackend {
+	return &dockerBackend{client: &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+	}}
+}
+
+// name implements powerBackend
+func (d *dockerBackend) name() string {
+	return "docker"
+}
+
+// label implements powerBackend
+func (d *dockerBackend) label() string {
+	return "Container"
+}
+
+// target implements powerBackend: the container mapped to server
+func (d *dockerBackend) target(cfg *Config, server string) string {
+	return cfg.Containers[server]
+}
+
+// state implements powerBackend
+func (d *dockerBackend) state(ctx context.Context, cfg *Config, server string) (string, error) {
+	return d.containerState(ctx, cfg.Containers[server])
+}
+
+// act implements powerBackend
+func (d *dockerBackend) act(ctx context.Context, cfg *Config, server, action string) error {
+	return d.containerAction(ctx, cfg.Containers[server], action)
+}
+
+// containerState inspects a container and maps its state
+func (d *dockerBackend) containerState(ctx context.Context, container string) (string, error) {
+	var info struct {
+		State struct {
+			Status string `json:"Status"` // created, running, paused, restarting, removing, exited, dead
+			Health *struct {
+				Status string `json:"Status"` // starting, healthy, unhealthy
+			} `json:"Health"`
+		} `json:"State"`
+	}
+	resp, err := d.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(container)+"/json")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("failed to decode container state: %w", err)
+	}
+	switch info.State.Status {
+	case "running":
+		if h := info.State.Health; h != nil && h.Status == "unhealthy" {
+			return powerUnhealthy, nil
+		}
+		if h := info.State.Health; h != nil && h.Status == "starting" {
+			return powerStarting, nil
+		}
+		return powerRunning, nil
+	case "restarting":
+		return powerRestarting, nil
+	case "paused":
+		return powerPaused, nil
+	case "removing":
+		return powerStopping, nil
+	case "created", "exited", "dead":
+		return powerStopped, nil
+	}
+	return info.State.Status, nil
+}
+
+// containerAction starts, stops (waiting dockerStopSeconds) or restarts a container
+func (d *dockerBackend) containerAction(ctx context.Context, container, action string) error {
+	path := "/containers/" + url.PathEscape(container) + "/" + action
+	if action != powerStart {
+		path += fmt.Sprintf("?t=%d", dockerStopSeconds)
+	}
+	resp, err := d.do(ctx, http.MethodPost, path)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a request to the Engine API; 304 Not Modified (already started/stopped) counts as success
+func (d *dockerBackend) do(ctx context.Context, method, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, "http://docker/"+dockerAPIVersion+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("docker request failed: %w", err)
+	}
+	if resp.StatusCode < 300 || resp.StatusCode == http.StatusNotModified {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Message string `json:"message"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+	if body.Message == "" {
+		body.Message = http.StatusText(resp.StatusCode)
+	}
+	return nil, fmt.Errorf("docker returned status %d: %s", resp.StatusCode, body.Message)
+}
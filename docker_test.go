@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDockerEngine serves the Engine API endpoints the backend uses on a Unix socket
+type fakeDockerEngine struct {
+	mu       sync.Mutex
+	statuses map[string]string // container -> State.Status
+	health   map[string]string // container -> State.Health.Status
+	requests []string
+}
+
+func (f *fakeDockerEngine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.RequestURI())
+	path, ok := strings.CutPrefix(r.URL.Path, "/"+dockerAPIVersion+"/containers/")
+	name, op, _ := strings.Cut(path, "/")
+	status, exists := f.statuses[name]
+	if !ok || !exists {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"No such container: ` + name + `"}`))
+		return
+	}
+	switch op {
+	case "json":
+		health := ""
+		if h := f.health[name]; h != "" {
+			health = `,"Health":{"Status":"` + h + `"}`
+		}
+		w.Write([]byte(`{"Id":"abc","State":{"Status":"` + status + `","Running":true` + health + `}}`))
+	case "start":
+		if status == "running" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		f.statuses[name] = "running"
+		w.WriteHeader(http.StatusNoContent)
+	case "stop":
+		f.statuses[name] = "exited"
+		w.WriteHeader(http.StatusNoContent)
+	case "restart":
+		f.statuses[name] = "running"
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// startFakeDocker serves engine on a socket in a temp dir and returns a backend talking to it
+func startFakeDocker(t *testing.T, engine *fakeDockerEngine) *dockerBackend {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(engine)
+	srv.Listener = ln
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return newDockerBackend(socket)
+}
+
+// TestDockerBackendState tests the mapping of container and health states, and missing containers
+func TestDockerBackendState(t *testing.T) {
+	engine := &fakeDockerEngine{
+		statuses: map[string]string{"ac-track": "running", "ac-drift": "exited", "ac-gt3": "running", "ac-rally": "restarting"},
+		health:   map[string]string{"ac-gt3": "unhealthy"},
+	}
+	d := startFakeDocker(t, engine)
+	ctx := context.Background()
+
+	want := map[string]string{"ac-track": powerRunning, "ac-drift": powerStopped, "ac-gt3": powerUnhealthy, "ac-rally": powerRestarting}
+	for container, state := range want {
+		if got, err := d.containerState(ctx, container); err != nil || got != state {
+			t.Errorf("containerState(%s) = %q, %v; want %q", container, got, err, state)
+		}
+	}
+	if _, err := d.containerState(ctx, "ac-missing"); err == nil || !strings.Contains(err.Error(), "No such container") {
+		t.Errorf("Expected the engine's error message, got %v", err)
+	}
+}
+
+// TestDockerBackendAct tests start (including already running), stop and restart requests
+func TestDockerBackendAct(t *testing.T) {
+	engine := &fakeDockerEngine{statuses: map[string]string{"ac-track": "running"}}
+	d := startFakeDocker(t, engine)
+	ctx := context.Background()
+
+	for _, action := range []string{powerStart, powerStop, powerStart, powerRestart} {
+		if err := d.containerAction(ctx, "ac-track", action); err != nil {
+			t.Fatalf("containerAction(%s): %v", action, err)
+		}
+	}
+	want := []string{
+		"POST /v1.41/containers/ac-track/start",
+		"POST /v1.41/containers/ac-track/stop?t=10",
+		"POST /v1.41/containers/ac-track/start",
+		"POST /v1.41/containers/ac-track/restart?t=10",
+	}
+	if strings.Join(engine.requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Requests =\n%s\nwant\n%s", strings.Join(engine.requests, "\n"), strings.Join(want, "\n"))
+	}
+	if err := d.containerAction(ctx, "ac-missing", powerStart); err == nil {
+		t.Error("Expected an error for a missing container")
+	}
+}
+
+// TestValidateContainers tests server names and container names
+func TestValidateContainers(t *testing.T) {
+	servers := testMaintenanceConfig().Servers
+	if err := validateContainers(map[string]string{"Track #1": "ac-track_1.main"}, servers); err != nil {
+		t.Errorf("Expected valid containers, got %v", err)
+	}
+	if err := validateContainers(map[string]string{"Rally #1": "ac-rally"}, servers); err == nil {
+		t.Error("Expected an error for an unknown server")
+	}
+	if err := validateContainers(map[string]string{"Track #1": "../containers"}, servers); err == nil {
+		t.Error("Expected an error for an invalid container name")
+	}
+}
+
+// TestDockerBackendFromEnv tests enabling and the DOCKER_HOST scheme check
+func TestDockerBackendFromEnv(t *testing.T) {
+	t.Setenv("DOCKER_ENABLED", "")
+	if d, err := dockerBackendFromEnv(); d != nil || err != nil {
+		t.Errorf("Expected disabled, got %v, %v", d, err)
+	}
+	t.Setenv("DOCKER_ENABLED", "true")
+	t.Setenv("DOCKER_HOST", "tcp://docker.example.com:2375")
+	if _, err := dockerBackendFromEnv(); err == nil {
+		t.Error("Expected an error for a TCP DOCKER_HOST")
+	}
+	t.Setenv("DOCKER_HOST", "")
+	if d, err := dockerBackendFromEnv(); d == nil || err != nil {
+		t.Errorf("Expected the default socket, got %v, %v", d, err)
+	}
+}
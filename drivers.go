@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= DRIVER REGISTRATION =================
+
+// driversStateFile links Steam GUIDs to Discord members, next to config.json
+const driversStateFile = "drivers.json"
+
+// steamIDPattern matches a SteamID64, the GUID AC writes to results files
+var steamIDPattern = regexp.MustCompile(`^7656119[0-9]{10}$`)
+
+// driverLink is the Discord member a Steam GUID belongs to
+type driverLink struct {
+	UserID   string    `json:"user_id"`
+	Username string    `json:"username,omitempty"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+// driverRegistry links Steam GUIDs to Discord members (one GUID per member), saved in drivers.json
+// Results summaries and the standings show linked drivers as mentions instead of their in-game name
+type driverRegistry struct {
+	path string
+
+	mu     sync.Mutex
+	loaded bool
+	links  map[string]driverLink // by Steam GUID
+}
+
+func newDriverRegistry(path string) *driverRegistry {
+	return &driverRegistry{path: path, links: make(map[string]driverLink)}
+}
+
+// driverRegistryFromEnv returns the registry if DRIVER_REGISTRATION_ENABLED is true, nil otherwise
+func driverRegistryFromEnv(configPath string) *driverRegistry {
+	if os.Getenv("DRIVER_REGISTRATION_ENABLED") != "true" {
+		return nil
+	}
+	path := filepath.Join(filepath.Dir(configPath), driversStateFile)
+	log.Printf("Driver registration enabled (links saved in %s)", path)
+	return newDriverRegistry(path)
+}
+
+// load reads the links once (caller holds r.mu)
+func (r *driverRegistry) load() {
+	if r.loaded || r.path == "" {
+		return
+	}
+	r.loaded = true
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Warning: failed to read driver links %s: %v", r.path, err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &r.links); err != nil {
+		log.Printf("Warning: ignoring invalid driver links %s: %v", r.path, err)
+		r.links = make(map[string]driverLink)
+	}
+}
+
+// save writes the links (caller holds r.mu)
+func (r *driverRegistry) save() {
+	if r.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(r.links, "", "  ")
+	if err == nil {
+		err = os.WriteFile(r.path, append(data, '\n'), 0600)
+	}
+	if err != nil {
+		log.Printf("Warning: failed to write driver links %s: %v", r.path, err)
+	}
+}
+
+// link ties guid to a Discord member, replacing the member's previous GUID
+// A GUID already linked to another member is refused (api.ErrDriverLinked); an admin unlinks it first
+func (r *driverRegistry) link(guid, userID, username string, now time.Time) (driverLink, error) {
+	if !steamIDPattern.MatchString(guid) {
+		return driverLink{}, fmt.Errorf("'%s' is not a SteamID64 (17 digits starting with 7656119)", guid)
+	}
+	if userID == "" {
+		return driverLink{}, fmt.Errorf("a Discord user ID is required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.load()
+	if l, ok := r.links[guid]; ok && l.UserID != userID {
+		return driverLink{}, fmt.Errorf("%w: %s belongs to another member", api.ErrDriverLinked, guid)
+	}
+	for g, l := range r.links {
+		if l.UserID == userID {
+			delete(r.links, g)
+		}
+	}
+	l := driverLink{UserID: userID, Username: username, LinkedAt: now.UTC()}
+	r.links[guid] = l
+	r.save()
+	return l, nil
+}
+
+// unlinkUser removes the member's link and returns the GUID it had
+func (r *driverRegistry) unlinkUser(userID string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.load()
+	for g, l := range r.links {
+		if l.UserID == userID {
+			delete(r.links, g)
+			r.save()
+			return g, true
+		}
+	}
+	return "", false
+}
+
+// unlinkGUID removes the link of guid
+func (r *driverRegistry) unlinkGUID(guid string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.load()
+	if _, ok := r.links[guid]; !ok {
+		return false
+	}
+	delete(r.links, guid)
+	r.save()
+	return true
+}
+
+// prune removes the links made before cutoff whose GUID is not in raced and returns how many it removed
+func (r *driverRegistry) prune(cutoff time.Time, raced map[string]bool) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.load()
+	n := len(r.links)
+	maps.DeleteFunc(r.links, func(guid string, l driverLink) bool { return l.LinkedAt.Before(cutoff) && !raced[guid] })
+	if len(r.links) != n {
+		r.save()
+	}
+	return n - len(r.links)
+}
+
+// guidOf returns the GUID linked to a member
+func (r *driverRegistry) guidOf(userID string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.load()
+	for g, l := range r.links {
+		if l.UserID == userID {
+			return g, true
+		}
+	}
+	return "", false
+}
+
+// userOf returns the Discord user ID linked to guid ("" if none; safe on a nil registry)
+func (r *driverRegistry) userOf(guid string) string {
+	if r == nil || guid == "" {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.load()
+	return r.links[guid].UserID
+}
+
+// mention renders a linked driver as a Discord mention and everyone else by name
+// Mentions in embeds do not ping
+func (r *driverRegistry) mention(guid, name string) string {
+	if id := r.userOf(guid); id != "" {
+		return "<@" + id + ">"
+	}
+	return name
+}
+
+// driverCommand is /driver link|unlink|show, usable by every member who can post messages
+func driverCommand() *slashCommand {
+	sendMessages := int64(discordgo.PermissionSendMessages)
+	return &slashCommand{
+		def: &discordgo.ApplicationCommand{
+			Name:                     "driver",
+			Description:              "Link your Steam account so race results mention you",
+			DefaultMemberPermissions: &sendMessages,
+			Contexts:                 &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild},
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type: discordgo.ApplicationCommandOptionSubCommand, Name: "link", Description: "Link your SteamID64 (the GUID in AC results)",
+					Options: []*discordgo.ApplicationCommandOption{{
+						Type: discordgo.ApplicationCommandOptionString, Name: "steam_id", Description: "17 digits, e.g. 76561198000000001",
+						Required: true, MinLength: intPtr(17), MaxLength: 17,
+					}},
+				},
+				{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "unlink", Description: "Remove your Steam link"},
+				{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "show", Description: "Show your Steam link"},
+			},
+		},
+		run:     runDriverCommand,
+		enabled: func(b *Bot) bool { return b.drivers != nil },
+	}
+}
+
+// intPtr returns a pointer to n (command option minimums are pointers)
+func intPtr(n int) *int {
+	return &n
+}
+
+// runDriverCommand handles /driver
+func runDriverCommand(b *Bot, user invoker, opts []*discordgo.ApplicationCommandInteractionDataOption) string {
+	if b.drivers == nil || len(opts) != 1 {
+		return "Unknown subcommand"
+	}
+	sub := opts[0]
+	switch sub.Name {
+	case "link":
+		guid := optionString(sub.Options, "steam_id")
+		if _, err := b.drivers.link(guid, user.id, user.name, time.Now()); err != nil {
+			return "❌ " + err.Error()
+		}
+		log.Printf("Driver %s linked Steam ID %s", user.name, guid)
+		return fmt.Sprintf("✅ Linked Steam ID %s to your account", guid)
+	case "unlink":
+		guid, ok := b.drivers.unlinkUser(user.id)
+		if !ok {
+			return "Your account is not linked"
+		}
+		log.Printf("Driver %s unlinked Steam ID %s", user.name, guid)
+		return fmt.Sprintf("✅ Unlinked Steam ID %s", guid)
+	case "show":
+		guid, ok := b.drivers.guidOf(user.id)
+		if !ok {
+			return "Your account is not linked; use `/driver link`"
+		}
+		return fmt.Sprintf("Your account is linked to Steam ID %s", guid)
+	}
+	return "Unknown subcommand"
+}
+
+// driverDirectory adapts the registry to api.DriverDirectory
+type driverDirectory struct {
+	registry *driverRegistry
+}
+
+// Drivers implements api.DriverDirectory
+func (d *driverDirectory) Drivers() []api.DriverLink {
+	r := d.registry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.load()
+	out := make([]api.DriverLink, 0, len(r.links))
+	for _, guid := range slices.Sorted(maps.Keys(r.links)) {
+		out = append(out, r.links[guid].apiLink(guid))
+	}
+	return out
+}
+
+// LinkDriver implements api.DriverDirectory
+func (d *driverDirectory) LinkDriver(steamID, userID string) (api.DriverLink, error) {
+	l, err := d.registry.link(steamID, userID, "", time.Now())
+	if err != nil {
+		return api.DriverLink{}, err
+	}
+	log.Printf("Steam ID %s linked to Discord user %s via the API", steamID, userID)
+	return l.apiLink(steamID), nil
+}
+
+// UnlinkDriver implements api.DriverDirectory
+func (d *driverDirectory) UnlinkDriver(steamID string) bool {
+	if !d.registry.unlinkGUID(steamID) {
+		return false
+	}
+	log.Printf("Steam ID %s unlinked via the API", steamID)
+	return true
+}
+
+func (l driverLink) apiLink(guid string) api.DriverLink {
+	return api.DriverLink{SteamID: guid, DiscordUserID: l.UserID, DiscordUsername: l.Username, LinkedAt: l.LinkedAt}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	aliceGUID = "76561198000000001"
+	bobGUID   = "76561198000000002"
+)
+
+// TestDriverRegistry tests linking, one GUID per member, conflicts, unlinking and persistence
+func TestDriverRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), driversStateFile)
+	r := newDriverRegistry(path)
+	now := time.Now()
+
+	if _, err := r.link("12345", "1", "alice", now); err == nil {
+		t.Error("Expected an invalid Steam ID to be refused")
+	}
+	if _, err := r.link(aliceGUID, "1", "alice", now); err != nil {
+		t.Fatalf("link failed: %v", err)
+	}
+	if _, err := r.link(aliceGUID, "2", "mallory", now); !errors.Is(err, api.ErrDriverLinked) {
+		t.Errorf("Expected ErrDriverLinked for another member, got %v", err)
+	}
+
+	// Linking a new GUID replaces the member's previous one
+	if _, err := r.link(bobGUID, "1", "alice", now); err != nil {
+		t.Fatalf("relink failed: %v", err)
+	}
+	if r.userOf(aliceGUID) != "" || r.userOf(bobGUID) != "1" {
+		t.Errorf("Expected only the new GUID linked, got %q %q", r.userOf(aliceGUID), r.userOf(bobGUID))
+	}
+	if got := r.mention(bobGUID, "Bob"); got != "<@1>" {
+		t.Errorf("Expected a mention, got %q", got)
+	}
+	if got := r.mention(aliceGUID, "Alice"); got != "Alice" {
+		t.Errorf("Expected the name for an unlinked driver, got %q", got)
+	}
+	var none *driverRegistry
+	if got := none.mention(bobGUID, "Bob"); got != "Bob" {
+		t.Errorf("Expected the name without a registry, got %q", got)
+	}
+
+	reloaded := newDriverRegistry(path)
+	if guid, ok := reloaded.guidOf("1"); !ok || guid != bobGUID {
+		t.Errorf("Expected the link to be saved, got %q %v", guid, ok)
+	}
+	if guid, ok := reloaded.unlinkUser("1"); !ok || guid != bobGUID {
+		t.Errorf("unlinkUser = %q %v", guid, ok)
+	}
+	if _, ok := reloaded.unlinkUser("1"); ok {
+		t.Error("Expected a second unlink to report no link")
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), bobGUID) {
+		t.Errorf("Expected the unlink to be saved, got %s", data)
+	}
+}
+
+// TestDriverCommand tests /driver link, show and unlink
+func TestDriverCommand(t *testing.T) {
+	b := newTestBot(nil)
+	b.drivers = newDriverRegistry("")
+	user := invoker{id: "42", name: "alice"}
+	sub := func(name string, opts ...*discordgo.ApplicationCommandInteractionDataOption) []*discordgo.ApplicationCommandInteractionDataOption {
+		return []*discordgo.ApplicationCommandInteractionDataOption{{Name: name, Type: discordgo.ApplicationCommandOptionSubCommand, Options: opts}}
+	}
+	steamID := func(v string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: "steam_id", Type: discordgo.ApplicationCommandOptionString, Value: v}
+	}
+
+	if reply := runDriverCommand(b, user, sub("show")); !strings.Contains(reply, "not linked") {
+		t.Errorf("Unexpected reply %q", reply)
+	}
+	if reply := runDriverCommand(b, user, sub("link", steamID("steam"))); !strings.HasPrefix(reply, "❌") {
+		t.Errorf("Expected an invalid Steam ID reply, got %q", reply)
+	}
+	if reply := runDriverCommand(b, user, sub("link", steamID(aliceGUID))); !strings.HasPrefix(reply, "✅") {
+		t.Errorf("Unexpected reply %q", reply)
+	}
+	if reply := runDriverCommand(b, invoker{id: "43", name: "mallory"}, sub("link", steamID(aliceGUID))); !strings.Contains(reply, "another member") {
+		t.Errorf("Expected a conflict reply, got %q", reply)
+	}
+	if reply := runDriverCommand(b, user, sub("show")); !strings.Contains(reply, aliceGUID) {
+		t.Errorf("Unexpected reply %q", reply)
+	}
+	if reply := runDriverCommand(b, user, sub("unlink")); !strings.HasPrefix(reply, "✅") {
+		t.Errorf("Unexpected reply %q", reply)
+	}
+
+	if driverCommand().enabled(newTestBot(nil)) {
+		t.Error("Expected /driver to be registered only with driver registration enabled")
+	}
+}
+
+// TestResultsMentionLinkedDrivers tests mentions in the results summary and the standings
+func TestResultsMentionLinkedDrivers(t *testing.T) {
+	drivers := newDriverRegistry("")
+	drivers.link(aliceGUID, "42", "alice", time.Now())
+
+	dir := t.TempDir()
+	copyResults(t, dir, "2026_10_11_18_00_RACE.json")
+	m := newStandingsModule(dir, "", "", nil, "")
+	m.drivers = drivers
+	m.scan(time.Now())
+
+	m.mu.Lock()
+	embed := m.embed()
+	m.mu.Unlock()
+	if !strings.Contains(embed.Description, "**1.** <@42> — **25** pts") {
+		t.Errorf("Expected the leader mentioned, got %q", embed.Description)
+	}
+	got := (&standingsProvider{module: m}).Standings()
+	if got.Drivers[0].DiscordUserID != "42" || got.Drivers[0].Driver != "Alice" || got.Drivers[1].DiscordUserID != "" {
+		t.Errorf("Unexpected standings %+v", got.Drivers)
+	}
+
+	data, _ := os.ReadFile(filepath.Join("testdata", "results", "2026_10_11_18_00_RACE.json"))
+	r, _ := parseRaceResult(data)
+	summary := resultsSummaryEmbed(r, defaultStandingsPoints, drivers)
+	if !strings.Contains(summary.Description, "**1.** <@42> —") || !strings.Contains(summary.Description, "Fastest lap: <@42>") {
+		t.Errorf("Expected the winner mentioned, got %q", summary.Description)
+	}
+}
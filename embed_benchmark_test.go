@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// benchmarkStatus returns servers spread over categories, with offline, stale and trend entries mixed in
+func benchmarkStatus(categories, perCategory int) ([]ServerInfo, *Config) {
+	cfg := testStatusConfig()
+	cfg.CategoryOrder = nil
+	var infos []ServerInfo
+	for c := 0; c < categories; c++ {
+		cat := fmt.Sprintf("Cat%d", c)
+		cfg.CategoryOrder = append(cfg.CategoryOrder, cat)
+		cfg.CategoryEmojis[cat] = "🏁"
+		for s := 0; s < perCategory; s++ {
+			info := ServerInfo{Name: fmt.Sprintf("%s server %d", cat, s), Category: cat, Map: "spa", Players: "7/24", NumPlayers: 7, MaxPlayers: 24, IP: cfg.ServerIP, Port: 8000 + c*100 + s}
+			switch s % 5 {
+			case 1:
+				info = offlineServerInfo(Server{Name: info.Name, Category: cat, IP: info.IP, Port: info.Port})
+			case 2:
+				info.Stale, info.LastSeen = true, time.Now().Add(-5*time.Minute)
+			case 3:
+				info.Trend = "▁▃▅▇"
+			}
+			infos = append(infos, info)
+		}
+	}
+	return infos, cfg
+}
+
+// BenchmarkBuildEmbed measures rendering the status embed for 120 servers in 6 categories
+func BenchmarkBuildEmbed(b *testing.B) {
+	infos, cfg := benchmarkStatus(6, 20)
+	b.ReportAllocs()
+	for b.Loop() {
+		buildEmbed(infos, cfg)
+	}
+}
+
+// BenchmarkBuildEmbed_Small measures the common case of a few servers in two categories
+func BenchmarkBuildEmbed_Small(b *testing.B) {
+	infos, cfg := benchmarkStatus(2, 4)
+	b.ReportAllocs()
+	for b.Loop() {
+		buildEmbed(infos, cfg)
+	}
+}
+
+// BenchmarkStatusPages measures a full update's embed work for 120 servers: render and split into pages
+func BenchmarkStatusPages(b *testing.B) {
+	infos, cfg := benchmarkStatus(6, 20)
+	b.ReportAllocs()
+	for b.Loop() {
+		paginateEmbed(statusEmbed(infos, cfg, false), nil)
+	}
+}
+
+// TestBuildEmbed_Allocations guards the allocation budget: about two strings per server, plus a fixed overhead
+func TestBuildEmbed_Allocations(t *testing.T) {
+	infos, cfg := benchmarkStatus(6, 20)
+	allocs := testing.AllocsPerRun(20, func() { buildEmbed(infos, cfg) })
+	if limit := float64(3 * len(infos)); allocs > limit {
+		t.Errorf("buildEmbed made %.0f allocations for %d servers, expected at most %.0f", allocs, len(infos), limit)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= EMBED COLORS =================
+
+// Default status embed colors by state
+const (
+	defaultOnlineColor  = 0x00FF00 // every server online
+	defaultPartialColor = 0xFFBF00 // some servers offline or in maintenance
+	defaultOfflineColor = 0xFF0000 // no server online
+)
+
+// EmbedColors overrides the status embed colors (embed_colors in config.json); every color is "#RRGGBB"
+// The state colors apply to the whole status; a category color applies to the continuation pages that start in it
+type EmbedColors struct {
+	Online     string            `json:"online,omitempty"`
+	Partial    string            `json:"partial,omitempty"`
+	Offline    string            `json:"offline,omitempty"`
+	Categories map[string]string `json:"categories,omitempty"` // category name -> color
+}
+
+// cloneEmbedColors deep-copies the embed_colors section (nil for nil)
+func cloneEmbedColors(c *EmbedColors) *EmbedColors {
+	if c == nil {
+		return nil
+	}
+	out := *c
+	out.Categories = maps.Clone(c.Categories)
+	return &out
+}
+
+// parseHexColor parses "#RRGGBB" into an embed color
+func parseHexColor(s string) (int, error) {
+	if len(s) != 7 || s[0] != '#' {
+		return 0, fmt.Errorf("color %q must be #RRGGBB", s)
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("color %q must be #RRGGBB", s)
+	}
+	return int(v), nil
+}
+
+// validateEmbedColors checks that every color is #RRGGBB and every category is in category_order
+func validateEmbedColors(c *EmbedColors, categories map[string]bool) error {
+	if c == nil {
+		return nil
+	}
+	for _, state := range []struct{ name, color string }{{"online", c.Online}, {"partial", c.Partial}, {"offline", c.Offline}} {
+		if state.color == "" {
+			continue
+		}
+		if _, err := parseHexColor(state.color); err != nil {
+			return fmt.Errorf("embed_colors.%s: %v", state.name, err)
+		}
+	}
+	for _, category := range slices.Sorted(maps.Keys(c.Categories)) {
+		if !categories[category] {
+			return fmt.Errorf("embed_colors.categories: category '%s' is not defined in category_order", category)
+		}
+		if _, err := parseHexColor(c.Categories[category]); err != nil {
+			return fmt.Errorf("embed_colors.categories '%s': %v", category, err)
+		}
+	}
+	return nil
+}
+
+// colorOr returns the color s, or def if s is unset (colors were validated at load)
+func colorOr(s string, def int) int {
+	if v, err := parseHexColor(s); err == nil {
+		return v
+	}
+	return def
+}
+
+// stateColor returns the embed color for online of total shown servers being online
+// A status without servers counts as all online
+func (c *EmbedColors) stateColor(online, total int) int {
+	if c == nil {
+		c = &EmbedColors{}
+	}
+	switch {
+	case online == total:
+		return colorOr(c.Online, defaultOnlineColor)
+	case online == 0:
+		return colorOr(c.Offline, defaultOfflineColor)
+	}
+	return colorOr(c.Partial, defaultPartialColor)
+}
+
+// embedHeaderColors maps the category header fields of a status embed built from cfg to their category colors
+// (nil without category colors); headers appear in category_order, once per category
+func embedHeaderColors(embed *discordgo.MessageEmbed, cfg *Config) map[string]int {
+	if cfg.EmbedColors == nil || len(cfg.EmbedColors.Categories) == 0 {
+		return nil
+	}
+	colors := make(map[string]int)
+	ci := 0
+	for _, f := range embed.Fields {
+		if !isHeaderField(f) || ci >= len(cfg.CategoryOrder) {
+			continue
+		}
+		if color, ok := cfg.EmbedColors.Categories[cfg.CategoryOrder[ci]]; ok {
+			colors[f.Name] = colorOr(color, embed.Color)
+		}
+		ci++
+	}
+	return colors
+}
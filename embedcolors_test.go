@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestValidateEmbedColors tests hex parsing and unknown categories
+func TestValidateEmbedColors(t *testing.T) {
+	categories := map[string]bool{"Drift": true}
+	if err := validateEmbedColors(&EmbedColors{Online: "#12abEF", Categories: map[string]string{"Drift": "#000000"}}, categories); err != nil {
+		t.Errorf("Expected valid colors, got %v", err)
+	}
+	for _, c := range []*EmbedColors{
+		{Partial: "12abef"},
+		{Offline: "#12abe"},
+		{Online: "#12abeg"},
+		{Categories: map[string]string{"Drift": "red"}},
+		{Categories: map[string]string{"Track": "#000000"}},
+	} {
+		if err := validateEmbedColors(c, categories); err == nil || !strings.Contains(err.Error(), "embed_colors") {
+			t.Errorf("Expected an error for %+v, got %v", c, err)
+		}
+	}
+}
+
+// TestEmbedStateColor tests the default and configured colors of all-online, partial and all-offline
+func TestEmbedStateColor(t *testing.T) {
+	cfg := testMaintenanceConfig()
+	online := []ServerInfo{
+		{Name: "Drift #1", Category: "Drift", Map: "spa", Players: "1/10", NumPlayers: 1},
+		{Name: "Track #1", Category: "Track", Map: "monza", Players: "0/10", NumPlayers: 0},
+	}
+	partial := []ServerInfo{online[0], offlineServerInfo(cfg.Servers[1])}
+	offline := []ServerInfo{offlineServerInfo(cfg.Servers[0]), offlineServerInfo(cfg.Servers[1])}
+
+	for _, tc := range []struct {
+		infos []ServerInfo
+		want  int
+	}{{online, defaultOnlineColor}, {partial, defaultPartialColor}, {offline, defaultOfflineColor}} {
+		if got := buildEmbed(tc.infos, cfg).Color; got != tc.want {
+			t.Errorf("Expected %#06x, got %#06x", tc.want, got)
+		}
+	}
+
+	cfg.EmbedColors = &EmbedColors{Partial: "#123456"}
+	if got := buildEmbed(partial, cfg).Color; got != 0x123456 {
+		t.Errorf("Expected the configured partial color, got %#06x", got)
+	}
+	if got := buildEmbed(online, cfg).Color; got != defaultOnlineColor {
+		t.Errorf("Expected the default online color, got %#06x", got)
+	}
+}
+
+// TestEmbedCategoryColors tests that continuation pages take the color of the category at their top
+func TestEmbedCategoryColors(t *testing.T) {
+	embed := largeStatusEmbed(4, 12) // pages start in Cat0, Cat1 and Cat3
+	cfg := testStatusConfig()
+	cfg.CategoryOrder = []string{"Cat0", "Cat1", "Cat2", "Cat3"}
+	cfg.EmbedColors = &EmbedColors{Categories: map[string]string{"Cat0": "#000001", "Cat1": "#000002"}}
+
+	colors := embedHeaderColors(embed, cfg)
+	if len(colors) != 2 {
+		t.Fatalf("Expected colors for two headers, got %v", colors)
+	}
+	pages := paginateEmbed(embed, colors)
+	if len(pages) != 3 {
+		t.Fatalf("Expected 3 pages, got %d", len(pages))
+	}
+	if pages[0].Color != embed.Color || pages[1].Color != 0x000002 || pages[2].Color != embed.Color {
+		t.Errorf("Unexpected page colors %#06x, %#06x, %#06x", pages[0].Color, pages[1].Color, pages[2].Color)
+	}
+
+	cfg.EmbedColors = nil
+	if colors := embedHeaderColors(embed, cfg); colors != nil {
+		t.Errorf("Expected no header colors without embed_colors, got %v", colors)
+	}
+}
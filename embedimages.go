@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ================= EMBED IMAGES =================
+
+const (
+	// defaultThumbnailURL is the status embed thumbnail unless embed_images.thumbnail is set
+	defaultThumbnailURL = "https://upload.wikimedia.org/wikipedia/commons/thumb/d/d9/Flag_of_Norway.svg/320px-Flag_of_Norway.svg.png"
+	// embedImageNone disables the thumbnail or image
+	embedImageNone = "none"
+	// maxEmbedImageURL is Discord's limit for embed image URLs
+	maxEmbedImageURL = 2048
+)
+
+// EmbedImages overrides the thumbnail and image of the status embed (embed_images in config.json)
+// Each is an http(s) URL Discord can fetch, "none" to leave it out, or empty for the default
+type EmbedImages struct {
+	Thumbnail string `json:"thumbnail,omitempty"` // default: the Norwegian flag
+	Image     string `json:"image,omitempty"`     // default: http://<server_ip>/images/logo.png
+}
+
+// validateEmbedImages checks that the thumbnail and image are absolute http(s) URLs or "none"
+func validateEmbedImages(images *EmbedImages) error {
+	if images == nil {
+		return nil
+	}
+	for _, field := range []struct{ name, value string }{{"thumbnail", images.Thumbnail}, {"image", images.Image}} {
+		if field.value == "" || field.value == embedImageNone {
+			continue
+		}
+		u, err := url.Parse(field.value)
+		if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") || len(field.value) > maxEmbedImageURL {
+			return fmt.Errorf("embed_images.%s must be an http(s) URL of at most %d characters or \"none\"", field.name, maxEmbedImageURL)
+		}
+	}
+	return nil
+}
+
+// embedThumbnailURL returns the status embed thumbnail of cfg ("" = none)
+func embedThumbnailURL(cfg *Config) string {
+	return embedImageURL(cfg.EmbedImages, func(i *EmbedImages) string { return i.Thumbnail }, defaultThumbnailURL)
+}
+
+// embedLogoURL returns the status embed image of cfg ("" = none)
+func embedLogoURL(cfg *Config) string {
+	return embedImageURL(cfg.EmbedImages, func(i *EmbedImages) string { return i.Image }, "http://"+cfg.ServerIP+"/images/logo.png")
+}
+
+// embedImageURL resolves one configured image against its default
+func embedImageURL(images *EmbedImages, field func(*EmbedImages) string, def string) string {
+	if images == nil {
+		return def
+	}
+	switch v := field(images); v {
+	case "":
+		return def
+	case embedImageNone:
+		return ""
+	default:
+		return v
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+// TestValidateEmbedImages tests accepted URLs, "none" and rejected values
+func TestValidateEmbedImages(t *testing.T) {
+	if err := validateEmbedImages(&EmbedImages{Thumbnail: "https://cdn.example.com/flag.png", Image: embedImageNone}); err != nil {
+		t.Errorf("Expected valid images, got %v", err)
+	}
+	for _, images := range []*EmbedImages{
+		{Thumbnail: "/images/logo.png"},
+		{Image: "ftp://example.com/logo.png"},
+		{Image: "https://"},
+	} {
+		if err := validateEmbedImages(images); err == nil {
+			t.Errorf("Expected an error for %+v", images)
+		}
+	}
+}
+
+// TestBuildEmbedImages tests the default, replaced and disabled thumbnail and image
+func TestBuildEmbedImages(t *testing.T) {
+	cfg := testMaintenanceConfig()
+	embed := buildEmbed(nil, cfg)
+	if embed.Thumbnail == nil || embed.Thumbnail.URL != defaultThumbnailURL || embed.Image == nil || embed.Image.URL != "http://"+cfg.ServerIP+"/images/logo.png" {
+		t.Errorf("Expected the default thumbnail and logo, got %+v %+v", embed.Thumbnail, embed.Image)
+	}
+
+	cfg.EmbedImages = &EmbedImages{Thumbnail: embedImageNone, Image: "https://cdn.example.com/logo.png"}
+	embed = buildEmbed(nil, cfg)
+	if embed.Thumbnail != nil || embed.Image == nil || embed.Image.URL != "https://cdn.example.com/logo.png" {
+		t.Errorf("Expected no thumbnail and the configured image, got %+v %+v", embed.Thumbnail, embed.Image)
+	}
+}
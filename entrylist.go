@@ -0,0 +1,259 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bombom/absa-ac/pkg/supervisor"
+)
+
+// ================= ENTRY LISTS =================
+
+// EntryListCar is one car slot of a server's entry list
+type EntryListCar struct {
+	Model     string
+	Skin      string
+	Driver    string // empty for a free slot
+	Team      string
+	Nation    string
+	Connected bool
+}
+
+// EntryListPoller is implemented by pollers whose protocol reports the entry list (ac_http)
+// Other query types simply have no entry list
+type EntryListPoller interface {
+	PollEntryList(ctx context.Context, client *http.Client, server Server) ([]EntryListCar, error)
+}
+
+// carClassTokens are model name parts that name a racing class (ks_ferrari_488_gt3 -> GT3)
+var carClassTokens = map[string]string{
+	"gt1": "GT1", "gt2": "GT2", "gt3": "GT3", "gt4": "GT4", "gte": "GTE", "gtlm": "GTLM", "gtc": "GTC",
+	"lmp1": "LMP1", "lmp2": "LMP2", "lmp3": "LMP3", "lmh": "LMH", "lmdh": "LMDh", "dpi": "DPi",
+	"tcr": "TCR", "dtm": "DTM", "f1": "F1", "f2": "F2", "f3": "F3", "f4": "F4",
+}
+
+// carClassAliases are models whose name does not contain their class
+var carClassAliases = map[string]string{
+	"lms": "GT3", // Audi R8 LMS
+}
+
+// carClass returns the racing class of an AC car model, or the model without the Kunos ks_ prefix
+func carClass(model string) string {
+	lower := strings.ToLower(model)
+	tokens := strings.FieldsFunc(lower, func(r rune) bool { return r == '_' || r == '-' || r == ' ' })
+	for _, t := range tokens {
+		if class, ok := carClassTokens[t]; ok {
+			return class
+		}
+	}
+	for _, t := range tokens {
+		if class, ok := carClassAliases[t]; ok {
+			return class
+		}
+	}
+	if rest, ok := strings.CutPrefix(model, "ks_"); ok && rest != "" {
+		return rest // ks_mazda_mx5_cup -> mazda_mx5_cup
+	}
+	return model
+}
+
+// carClassCount is the number of entry list slots of one class
+type carClassCount struct {
+	class string
+	count int
+}
+
+// summarizeCarClasses counts entry list slots per class, most common first (ties by name)
+func summarizeCarClasses(cars []EntryListCar) []carClassCount {
+	counts := make(map[string]int)
+	for _, c := range cars {
+		counts[carClass(c.Model)]++
+	}
+	out := make([]carClassCount, 0, len(counts))
+	for class, n := range counts {
+		out = append(out, carClassCount{class: class, count: n})
+	}
+	slices.SortFunc(out, func(a, b carClassCount) int {
+		if c := cmp.Compare(b.count, a.count); c != 0 {
+			return c
+		}
+		return strings.Compare(a.class, b.class)
+	})
+	return out
+}
+
+// formatCarClasses renders "GT3 x12, LMP2 x4"
+func formatCarClasses(classes []carClassCount) string {
+	buf := make([]byte, 0, 16*len(classes))
+	for i, c := range classes {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		buf = append(buf, c.class...)
+		buf = append(buf, " x"...)
+		buf = strconv.AppendInt(buf, int64(c.count), 10)
+	}
+	return string(buf)
+}
+
+// entryList is the latest entry list fetched from a server
+type entryList struct {
+	cars []EntryListCar
+	at   time.Time
+}
+
+// entryListTracker fetches the entry lists of online servers after every poll and keeps the latest one
+// A failed fetch keeps the previous list; offline and removed servers have none
+type entryListTracker struct {
+	// showClasses puts the car class summary in the embed (EMBED_CAR_CLASSES)
+	showClasses bool
+
+	mu    sync.Mutex
+	lists map[serverKey]entryList
+}
+
+func newEntryListTracker(showClasses bool) *entryListTracker {
+	return &entryListTracker{showClasses: showClasses, lists: make(map[serverKey]entryList)}
+}
+
+// entryListTrackerFromEnv returns a tracker if ENTRY_LIST_ENABLED is true, nil otherwise
+func entryListTrackerFromEnv() *entryListTracker {
+	if os.Getenv("ENTRY_LIST_ENABLED") != "true" {
+		return nil
+	}
+	showClasses := os.Getenv("EMBED_CAR_CLASSES") == "true"
+	log.Printf("Entry lists enabled (car classes in the embed: %v)", showClasses)
+	return newEntryListTracker(showClasses)
+}
+
+// fetch polls the entry lists of the online servers in infos concurrently and stores them
+func (t *entryListTracker) fetch(client *http.Client, infos []ServerInfo, cfg *Config, now time.Time) {
+	servers := make(map[serverKey]Server, len(cfg.Servers))
+	for _, s := range cfg.Servers {
+		servers[serverKey{s.Name, s.Port}] = s
+	}
+
+	var wg sync.WaitGroup
+	current := make(map[serverKey]bool, len(infos))
+	for _, info := range infos {
+		key := serverKey{info.Name, info.Port}
+		if info.NumPlayers < 0 {
+			continue
+		}
+		current[key] = true
+		if info.Stale || !info.LastSeen.IsZero() {
+			continue // poll failed: keep the previous list
+		}
+		server, ok := servers[key]
+		if !ok {
+			continue
+		}
+		poller, ok := lookupPoller(server.QueryType)
+		if !ok {
+			continue
+		}
+		lp, ok := poller.(EntryListPoller)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer supervisor.Recover(fmt.Sprintf("entry list '%s'", server.Name), log.Default())
+			ctx, cancel := context.WithTimeout(context.Background(), client.Timeout)
+			defer cancel()
+			cars, err := lp.PollEntryList(ctx, client, server)
+			if err != nil {
+				log.Printf("Server '%s' entry list: %v", server.Name, err)
+				return
+			}
+			t.mu.Lock()
+			t.lists[key] = entryList{cars: cars, at: now}
+			t.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.lists {
+		if !current[key] {
+			delete(t.lists, key)
+		}
+	}
+}
+
+// get returns the latest entry list of a server named name
+func (t *entryListTracker) get(name string) (entryList, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, l := range t.lists {
+		if key.name == name {
+			return l, true
+		}
+	}
+	return entryList{}, false
+}
+
+// apply sets the car class summary on infos when it is shown in the embed
+func (t *entryListTracker) apply(infos []ServerInfo) []ServerInfo {
+	if !t.showClasses {
+		return infos
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range infos {
+		if l, ok := t.lists[serverKey{infos[i].Name, infos[i].Port}]; ok && infos[i].NumPlayers >= 0 {
+			infos[i].CarClasses = formatCarClasses(summarizeCarClasses(l.cars))
+		}
+	}
+	return infos
+}
+
+// trackEntryLists fetches entry lists after a poll and adds the car class summary (no-op when disabled)
+func (b *Bot) trackEntryLists(infos []ServerInfo, cfg *Config) []ServerInfo {
+	if b.entryLists == nil {
+		return infos
+	}
+	b.entryLists.fetch(pollClients.Get(cfg.HTTPClient), infos, cfg, time.Now())
+	return b.entryLists.apply(infos)
+}
+
+// entryListProvider adapts the tracker to api.EntryListProvider
+type entryListProvider struct {
+	tracker *entryListTracker
+}
+
+// EntryList implements api.EntryListProvider
+func (p *entryListProvider) EntryList(server string) (api.EntryList, bool) {
+	l, ok := p.tracker.get(server)
+	if !ok {
+		return api.EntryList{}, false
+	}
+	out := api.EntryList{
+		Server:    server,
+		UpdatedAt: l.at.UTC(),
+		Cars:      make([]api.EntryListCar, 0, len(l.cars)),
+		Classes:   []api.CarClassCount{},
+	}
+	for _, c := range l.cars {
+		out.Cars = append(out.Cars, api.EntryListCar{
+			Model: c.Model, Class: carClass(c.Model), Skin: c.Skin,
+			Driver: c.Driver, Team: c.Team, Nation: c.Nation, Connected: c.Connected,
+		})
+	}
+	for _, c := range summarizeCarClasses(l.cars) {
+		out.Classes = append(out.Classes, api.CarClassCount{Class: c.class, Count: c.count})
+	}
+	return out, true
+}
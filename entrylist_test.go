@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCarClass tests class detection from AC model names
+func TestCarClass(t *testing.T) {
+	tests := map[string]string{
+		"ks_ferrari_488_gt3":        "GT3",
+		"ks_porsche_911_gt3_r_2016": "GT3",
+		"ks_audi_r8_lms_2016":       "GT3",
+		"rss_lmp2_v8":               "LMP2",
+		"ks_mazda_mx5_cup":          "mazda_mx5_cup",
+		"bmw_m3_e30":                "bmw_m3_e30",
+	}
+	for model, want := range tests {
+		if got := carClass(model); got != want {
+			t.Errorf("carClass(%q) = %q, want %q", model, got, want)
+		}
+	}
+}
+
+// TestFormatCarClasses tests counting, ordering and the summary text
+func TestFormatCarClasses(t *testing.T) {
+	var cars []EntryListCar
+	for range 12 {
+		cars = append(cars, EntryListCar{Model: "ks_ferrari_488_gt3"})
+	}
+	for range 4 {
+		cars = append(cars, EntryListCar{Model: "rss_lmp2_v8"})
+	}
+	cars = append(cars, EntryListCar{Model: "ks_mercedes_amg_gt4"}, EntryListCar{Model: "ks_ginetta_gt4"}, EntryListCar{Model: "ks_bmw_m235i_racing"})
+
+	if got := formatCarClasses(summarizeCarClasses(cars)); got != "GT3 x12, LMP2 x4, GT4 x2, bmw_m235i_racing x1" {
+		t.Errorf("Unexpected summary %q", got)
+	}
+	if got := formatCarClasses(summarizeCarClasses(nil)); got != "" {
+		t.Errorf("Expected an empty summary, got %q", got)
+	}
+}
+
+// TestEntryListTracker tests fetching online servers only, the embed summary and forgetting offline servers
+func TestEntryListTracker(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "ac_http", "entrylist.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+	host, portStr, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	cfg := testMaintenanceConfig()
+	cfg.ServerIP = host
+	cfg.Servers = []Server{{Name: "Track #1", Category: "Track", Port: port}, {Name: "Drift #1", Category: "Drift", Port: port + 1}}
+	initializeServerIPs(cfg)
+	client := &http.Client{Timeout: 5 * time.Second}
+	infos := []ServerInfo{
+		{Name: "Track #1", Category: "Track", Map: "spa", Players: "2/20", NumPlayers: 2, IP: host, Port: port},
+		{Name: "Drift #1", Category: "Drift", Map: "Offline", Players: "0/0", NumPlayers: -1, IP: host, Port: port + 1},
+	}
+
+	tracker := newEntryListTracker(true)
+	tracker.fetch(client, infos, cfg, time.Now())
+	infos = tracker.apply(infos)
+	if infos[0].CarClasses != "GT3 x3, LMP2 x1" || infos[1].CarClasses != "" {
+		t.Errorf("Unexpected summaries %q / %q", infos[0].CarClasses, infos[1].CarClasses)
+	}
+
+	field := ""
+	for _, f := range statusEmbed(infos, cfg, false).Fields {
+		if strings.Contains(f.Name, "Track #1") {
+			field = f.Value
+		}
+	}
+	if !strings.Contains(field, "**Cars:** GT3 x3, LMP2 x1") {
+		t.Errorf("Expected the car classes in the embed, got %q", field)
+	}
+
+	list, ok := (&entryListProvider{tracker: tracker}).EntryList("Track #1")
+	if !ok || len(list.Cars) != 4 || list.Cars[0].Class != "GT3" || list.Classes[0].Count != 3 {
+		t.Errorf("Unexpected API entry list %+v (%v)", list, ok)
+	}
+	if _, ok := (&entryListProvider{tracker: tracker}).EntryList("Drift #1"); ok {
+		t.Error("Expected no entry list for an offline server")
+	}
+
+	// Going offline drops the list
+	infos[0].NumPlayers = -1
+	tracker.fetch(client, infos, cfg, time.Now())
+	if _, ok := tracker.get("Track #1"); ok {
+		t.Error("Expected the entry list dropped once the server is offline")
+	}
+
+	// Without EMBED_CAR_CLASSES the embed stays unchanged
+	infos[0].NumPlayers = 2
+	hidden := newEntryListTracker(false)
+	hidden.fetch(client, infos, cfg, time.Now())
+	infos[0].CarClasses = ""
+	if got := hidden.apply(infos)[0].CarClasses; got != "" {
+		t.Errorf("Expected no summary without EMBED_CAR_CLASSES, got %q", got)
+	}
+	if _, ok := hidden.get("Track #1"); !ok {
+		t.Error("Expected the entry list fetched for the API")
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ================= ENV-ONLY CONFIG =================
+
+// envDefaultCategoryEmoji is used for categories derived from ABSA_SERVERS when ABSA_CATEGORIES is unset
+const envDefaultCategoryEmoji = "🏁"
+
+// configFromEnv builds the config from CONFIG_JSON or ABSA_SERVERS for deployments without a config file
+// Returns nil (and no error) when neither is set; the source name is used in logs and read-only errors
+// The result is not validated: callers run the usual validation like for a loaded file
+func configFromEnv() (*Config, string, error) {
+	blob := os.Getenv("CONFIG_JSON")
+	servers := os.Getenv("ABSA_SERVERS")
+	switch {
+	case blob != "" && servers != "":
+		return nil, "", fmt.Errorf("CONFIG_JSON and ABSA_SERVERS are both set, use only one")
+	case blob != "":
+		var cfg Config
+		if err := decodeConfigJSON([]byte(blob), &cfg); err != nil {
+			return nil, "", fmt.Errorf("failed to parse CONFIG_JSON: %w", err)
+		}
+		return &cfg, "CONFIG_JSON", nil
+	case servers != "":
+		cfg, err := parseEnvServersConfig(os.Getenv("ABSA_SERVER_IP"), servers, os.Getenv("ABSA_CATEGORIES"), os.Getenv("ABSA_UPDATE_INTERVAL"))
+		if err != nil {
+			return nil, "", err
+		}
+		return cfg, "ABSA_SERVERS", nil
+	}
+	return nil, "", nil
+}
+
+// parseEnvServersConfig builds a config from the compact ABSA_* variables:
+//
+//	ABSA_SERVER_IP=203.0.113.10
+//	ABSA_SERVERS="GT3 #1:8081:GT3;Drift #1:8082:Drift:ac_http"   (name:port:category[:query_type])
+//	ABSA_CATEGORIES="GT3=🏎️,Drift=🟣"                           (optional: order and emojis)
+//	ABSA_UPDATE_INTERVAL=30                                     (optional)
+//
+// Without ABSA_CATEGORIES, categories follow first use in ABSA_SERVERS with envDefaultCategoryEmoji
+func parseEnvServersConfig(serverIP, servers, categories, interval string) (*Config, error) {
+	cfg := &Config{
+		ServerIP:       strings.TrimSpace(serverIP),
+		UpdateInterval: int(defaultUpdateInterval.Seconds()),
+		CategoryEmojis: map[string]string{},
+	}
+	if cfg.ServerIP == "" {
+		return nil, fmt.Errorf("ABSA_SERVER_IP is required when ABSA_SERVERS is set")
+	}
+	if interval = strings.TrimSpace(interval); interval != "" {
+		n, err := strconv.Atoi(interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ABSA_UPDATE_INTERVAL %q: must be a number of seconds", interval)
+		}
+		cfg.UpdateInterval = n
+	}
+
+	for _, entry := range strings.Split(categories, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, emoji, ok := strings.Cut(entry, "=")
+		name, emoji = strings.TrimSpace(name), strings.TrimSpace(emoji)
+		if !ok || name == "" || emoji == "" {
+			return nil, fmt.Errorf("invalid ABSA_CATEGORIES entry %q: expected name=emoji", entry)
+		}
+		cfg.CategoryOrder = append(cfg.CategoryOrder, name)
+		cfg.CategoryEmojis[name] = emoji
+	}
+	explicitCategories := len(cfg.CategoryOrder) > 0
+
+	for i, entry := range strings.Split(servers, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 && len(parts) != 4 {
+			return nil, fmt.Errorf("invalid ABSA_SERVERS entry %d %q: expected name:port:category[:query_type]", i+1, entry)
+		}
+		for j := range parts {
+			parts[j] = strings.TrimSpace(parts[j])
+		}
+		port, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid ABSA_SERVERS entry %d %q: port %q is not a number", i+1, entry, parts[1])
+		}
+		server := Server{Name: parts[0], Port: port, Category: parts[2]}
+		if len(parts) == 4 {
+			server.QueryType = parts[3]
+		}
+
+		// Derive categories in order of first use unless ABSA_CATEGORIES defines them
+		if _, known := cfg.CategoryEmojis[server.Category]; !known && !explicitCategories && server.Category != "" {
+			cfg.CategoryOrder = append(cfg.CategoryOrder, server.Category)
+			cfg.CategoryEmojis[server.Category] = envDefaultCategoryEmoji
+		}
+		cfg.Servers = append(cfg.Servers, server)
+	}
+	return cfg, nil
+}
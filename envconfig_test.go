@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// TestConfigFromEnv_None tests that no env config means file mode
+func TestConfigFromEnv_None(t *testing.T) {
+	t.Setenv("CONFIG_JSON", "")
+	t.Setenv("ABSA_SERVERS", "")
+
+	cfg, source, err := configFromEnv()
+	if cfg != nil || source != "" || err != nil {
+		t.Errorf("Expected no env config, got %+v %q %v", cfg, source, err)
+	}
+}
+
+// TestConfigFromEnv_JSON tests the CONFIG_JSON blob
+func TestConfigFromEnv_JSON(t *testing.T) {
+	t.Setenv("ABSA_SERVERS", "")
+	t.Setenv("CONFIG_JSON", `{"server_ip":"203.0.113.10","update_interval":30,"category_order":["GT3"],"category_emojis":{"GT3":"🏎️"},"servers":[{"name":"GT3 #1","port":8081,"category":"GT3"}]}`)
+
+	cfg, source, err := configFromEnv()
+	if err != nil {
+		t.Fatalf("configFromEnv failed: %v", err)
+	}
+	if source != "CONFIG_JSON" || cfg.ServerIP != "203.0.113.10" || len(cfg.Servers) != 1 {
+		t.Errorf("Unexpected config from %s: %+v", source, cfg)
+	}
+
+	t.Setenv("CONFIG_JSON", `{"server_ip":`)
+	if _, _, err := configFromEnv(); err == nil || !strings.Contains(err.Error(), "CONFIG_JSON") {
+		t.Errorf("Expected parse error naming CONFIG_JSON, got %v", err)
+	}
+
+	t.Setenv("ABSA_SERVERS", "a:8081:GT3")
+	if _, _, err := configFromEnv(); err == nil || !strings.Contains(err.Error(), "only one") {
+		t.Errorf("Expected conflict error, got %v", err)
+	}
+}
+
+// TestParseEnvServersConfig tests the compact ABSA_* format
+func TestParseEnvServersConfig(t *testing.T) {
+	cfg, err := parseEnvServersConfig("203.0.113.10", "GT3 #1:8081:GT3; Drift #1 : 8082 : Drift : ac_http ;", "GT3=🏎️, Drift=🟣", "60")
+	if err != nil {
+		t.Fatalf("parseEnvServersConfig failed: %v", err)
+	}
+	if cfg.UpdateInterval != 60 || strings.Join(cfg.CategoryOrder, ",") != "GT3,Drift" || cfg.CategoryEmojis["Drift"] != "🟣" {
+		t.Errorf("Unexpected settings: %+v", cfg)
+	}
+	if len(cfg.Servers) != 2 || cfg.Servers[1] != (Server{Name: "Drift #1", Port: 8082, Category: "Drift", QueryType: "ac_http"}) {
+		t.Errorf("Unexpected servers: %+v", cfg.Servers)
+	}
+	initializeServerIPs(cfg)
+	if err := validateConfigStructSafeRuntime(cfg); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
+
+// TestParseEnvServersConfig_DerivedCategories tests categories without ABSA_CATEGORIES
+func TestParseEnvServersConfig_DerivedCategories(t *testing.T) {
+	cfg, err := parseEnvServersConfig("203.0.113.10", "a:8081:Track;b:8082:Drift;c:8083:Track", "", "")
+	if err != nil {
+		t.Fatalf("parseEnvServersConfig failed: %v", err)
+	}
+	if strings.Join(cfg.CategoryOrder, ",") != "Track,Drift" || cfg.CategoryEmojis["Drift"] != envDefaultCategoryEmoji {
+		t.Errorf("Unexpected categories: %v %v", cfg.CategoryOrder, cfg.CategoryEmojis)
+	}
+	if cfg.UpdateInterval != int(defaultUpdateInterval.Seconds()) {
+		t.Errorf("UpdateInterval = %d, want default", cfg.UpdateInterval)
+	}
+}
+
+// TestParseEnvServersConfig_Errors tests rejected input
+func TestParseEnvServersConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name                                    string
+		serverIP, servers, categories, interval string
+		wantErr                                 string
+	}{
+		{"missing server ip", "", "a:8081:GT3", "", "", "ABSA_SERVER_IP"},
+		{"too few fields", "203.0.113.10", "a:8081", "", "", "name:port:category"},
+		{"bad port", "203.0.113.10", "a:http:GT3", "", "", "not a number"},
+		{"bad category entry", "203.0.113.10", "a:8081:GT3", "GT3", "", "name=emoji"},
+		{"bad interval", "203.0.113.10", "a:8081:GT3", "", "soon", "ABSA_UPDATE_INTERVAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseEnvServersConfig(tt.serverIP, tt.servers, tt.categories, tt.interval)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestReadOnlyConfigManager tests that env-sourced config rejects writes and never reloads
+func TestReadOnlyConfigManager(t *testing.T) {
+	cfg := testStatusConfig()
+	cm := NewReadOnlyConfigManager("ABSA_SERVERS", cfg)
+
+	if err := cm.WriteConfig(testStatusConfig()); !errors.Is(err, api.ErrConfigReadOnly) {
+		t.Errorf("WriteConfig: got %v, want ErrConfigReadOnly", err)
+	}
+	if err := cm.UpdateConfig(map[string]interface{}{"update_interval": 60}); !errors.Is(err, api.ErrConfigReadOnly) {
+		t.Errorf("UpdateConfig: got %v, want ErrConfigReadOnly", err)
+	}
+	if err := cm.checkAndReloadIfNeeded(); err != nil {
+		t.Errorf("checkAndReloadIfNeeded: %v", err)
+	}
+	if cm.GetConfig() != cfg {
+		t.Error("Expected the env config to stay in place")
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/supervisor"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= OUTAGE ESCALATION =================
+
+// EscalationConfig notifies increasingly loudly while a server stays down (alerts.escalation in config.json)
+// Each step fires once per outage when the downtime passes its threshold; when the server returns, every
+// destination that was notified gets a recovery notice with the total downtime
+type EscalationConfig struct {
+	Categories []string         `json:"categories,omitempty"` // empty = every category
+	Steps      []EscalationStep `json:"steps"`
+}
+
+// EscalationStep is one threshold of the escalation policy
+type EscalationStep struct {
+	After      string `json:"after"`                 // downtime before the step fires, e.g. "30m"
+	ChannelID  string `json:"channel_id,omitempty"`  // Discord channel, bot mode only
+	RoleID     string `json:"role_id,omitempty"`     // role pinged with the channel or Discord webhook message
+	WebhookURL string `json:"webhook_url,omitempty"` // Discord, Slack, PagerDuty (with routing_key) or generic JSON webhook
+	RoutingKey string `json:"routing_key,omitempty"` // PagerDuty Events v2 integration key: webhook_url gets trigger/resolve events
+	Status     bool   `json:"status,omitempty"`      // post next to the status message through every publisher
+}
+
+// after returns the step threshold (config already validated)
+func (s EscalationStep) after() time.Duration {
+	d, _ := time.ParseDuration(s.After)
+	return d
+}
+
+// validateEscalationConfig checks the escalation policy (nil is valid: no escalation)
+func validateEscalationConfig(e *EscalationConfig, categories map[string]bool) error {
+	if e == nil {
+		return nil
+	}
+	if len(e.Steps) == 0 {
+		return fmt.Errorf("alerts escalation: needs at least one step")
+	}
+	for _, cat := range e.Categories {
+		if !categories[cat] {
+			return fmt.Errorf("alerts escalation: category '%s' is not defined in category_order", cat)
+		}
+	}
+	var prev time.Duration
+	for i, step := range e.Steps {
+		d, err := time.ParseDuration(step.After)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("alerts escalation step %d: after must be a positive duration like 30m", i+1)
+		}
+		if d <= prev {
+			return fmt.Errorf("alerts escalation step %d: after must be longer than the previous step", i+1)
+		}
+		prev = d
+		if step.ChannelID == "" && step.WebhookURL == "" && !step.Status {
+			return fmt.Errorf("alerts escalation step %d: needs a destination (channel_id, webhook_url or status)", i+1)
+		}
+		if step.ChannelID != "" && !snowflakePattern.MatchString(step.ChannelID) {
+			return fmt.Errorf("alerts escalation step %d: channel_id %q is not a Discord channel ID", i+1, step.ChannelID)
+		}
+		if step.RoleID != "" {
+			if !snowflakePattern.MatchString(step.RoleID) {
+				return fmt.Errorf("alerts escalation step %d: role_id %q is not a Discord role ID", i+1, step.RoleID)
+			}
+			if step.ChannelID == "" && !isDiscordWebhookURL(step.WebhookURL) {
+				return fmt.Errorf("alerts escalation step %d: role_id needs channel_id or a Discord webhook_url", i+1)
+			}
+		}
+		if step.WebhookURL != "" && !validAlertWebhookURL(step.WebhookURL) {
+			return fmt.Errorf("alerts escalation step %d: webhook_url must be an http:// or https:// URL", i+1)
+		}
+		if step.RoutingKey != "" && step.WebhookURL == "" {
+			return fmt.Errorf("alerts escalation step %d: routing_key needs webhook_url (e.g. https://events.pagerduty.com/v2/enqueue)", i+1)
+		}
+	}
+	return nil
+}
+
+// applies reports whether outages of servers in category escalate (false for nil)
+func (e *EscalationConfig) applies(category string) bool {
+	return e != nil && (len(e.Categories) == 0 || slices.Contains(e.Categories, category))
+}
+
+// dueSteps returns how many steps have passed their threshold after downtime
+func (e *EscalationConfig) dueSteps(downtime time.Duration) int {
+	n := 0
+	for _, step := range e.Steps {
+		if downtime >= step.after() {
+			n++
+		}
+	}
+	return n
+}
+
+// firedSteps returns the first n steps, fewer if the policy shrank during the outage (nil for nil)
+func (e *EscalationConfig) firedSteps(n int) []EscalationStep {
+	if e == nil {
+		return nil
+	}
+	return e.Steps[:min(n, len(e.Steps))]
+}
+
+// escalationNotice is one escalation step, or the recovery notice for every step that fired
+type escalationNotice struct {
+	info     ServerInfo
+	steps    []EscalationStep
+	level    int // 1-based step number; for a recovery the highest step reached
+	downtime time.Duration
+	resolved bool
+}
+
+// message renders the notice; the role ping is added per destination
+func (n escalationNotice) message() string {
+	if n.resolved {
+		return fmt.Sprintf("✅ **%s** (%s) is back online after %s of downtime (escalation level %d)",
+			n.info.Name, n.info.Category, formatDataAge(n.downtime), n.level)
+	}
+	return fmt.Sprintf("🚨 **%s** (%s) has been offline for %s (escalation level %d)",
+		n.info.Name, n.info.Category, formatDataAge(n.downtime), n.level)
+}
+
+// severity grades the notice for PagerDuty and generic webhooks
+func (n escalationNotice) severity() alertSeverity {
+	switch {
+	case n.resolved:
+		return severityInfo
+	case n.level == 1:
+		return severityWarning
+	case n.level == 2:
+		return severityError
+	}
+	return severityCritical
+}
+
+// dedupKey identifies the outage at PagerDuty so the recovery resolves the incident
+func (n escalationNotice) dedupKey() string {
+	return fmt.Sprintf("absa-ac/outage/%s:%d", n.info.Name, n.info.Port)
+}
+
+// withRole prefixes msg with a role ping
+func withRole(msg, roleID string) string {
+	if roleID == "" {
+		return msg
+	}
+	return "<@&" + roleID + "> " + msg
+}
+
+// escalate delivers an escalation notice to its steps' destinations, each once (skipped on a standby replica)
+func (b *Bot) escalate(n escalationNotice) {
+	if !b.isLeader() {
+		return
+	}
+	msg := n.message()
+	var status bool
+	channels := make(map[string]bool)
+	webhooks := make(map[string]bool)
+	for _, step := range n.steps {
+		if n.resolved {
+			step.RoleID = "" // the recovery notice does not ping
+		}
+		if step.Status && !status {
+			status = true
+			b.SendAlert(msg)
+		}
+		if step.ChannelID != "" && !channels[step.ChannelID] {
+			channels[step.ChannelID] = true
+			if err := b.sendEscalationToChannel(step, msg); err != nil {
+				log.Printf("Error sending escalation for '%s' to channel %s: %v", n.info.Name, step.ChannelID, err)
+			}
+		}
+		if step.WebhookURL != "" && !webhooks[step.WebhookURL] {
+			webhooks[step.WebhookURL] = true
+			if err := b.alerts.postEscalation(step, n, msg); err != nil {
+				log.Printf("Error sending escalation for '%s' to webhook: %v", n.info.Name, err)
+			}
+		}
+	}
+}
+
+// escalateAsync delivers n in the background
+func (b *Bot) escalateAsync(n escalationNotice) {
+	go func() {
+		defer supervisor.Recover("escalation delivery", log.Default())
+		b.escalate(n)
+	}()
+}
+
+// sendEscalationToChannel posts msg to the step's channel, pinging its role
+func (b *Bot) sendEscalationToChannel(step EscalationStep, msg string) error {
+	if b.discord == nil {
+		return fmt.Errorf("channel_id steps require bot mode (DISCORD_TOKEN), not a webhook")
+	}
+	send := &discordgo.MessageSend{Content: withRole(msg, step.RoleID)}
+	if step.RoleID != "" {
+		send.AllowedMentions = &discordgo.MessageAllowedMentions{Roles: []string{step.RoleID}}
+	}
+	_, err := b.discord.session.ChannelMessageSendComplex(step.ChannelID, send)
+	return err
+}
+
+// postEscalation posts a notice to the step's webhook: PagerDuty events with a routing key, a message with
+// the role ping for Discord, plain text for Slack, and JSON otherwise
+func (r *alertRouter) postEscalation(step EscalationStep, n escalationNotice, msg string) error {
+	var payload any
+	switch {
+	case step.RoutingKey != "":
+		ev := pagerDutyEvent{RoutingKey: step.RoutingKey, EventAction: "trigger", DedupKey: n.dedupKey()}
+		if n.resolved {
+			ev.EventAction = "resolve"
+		} else {
+			ev.Payload = &pagerDutyPayload{
+				Summary:   strings.ReplaceAll(msg, "**", ""),
+				Source:    n.info.Name,
+				Severity:  n.severity().String(),
+				Component: n.info.Category,
+				CustomDetails: map[string]any{
+					"downtime_seconds": int(n.downtime.Seconds()),
+					"escalation_level": n.level,
+				},
+			}
+		}
+		payload = ev
+	case isDiscordWebhookURL(step.WebhookURL):
+		p := map[string]any{"content": withRole(msg, step.RoleID)}
+		if step.RoleID != "" {
+			p["allowed_mentions"] = map[string][]string{"roles": {step.RoleID}}
+		}
+		payload = p
+	case isSlackWebhookURL(step.WebhookURL):
+		payload = map[string]string{"text": msg}
+	default:
+		event := "server_escalation"
+		if n.resolved {
+			event = "server_recovered"
+		}
+		payload = map[string]any{
+			"event":            event,
+			"severity":         n.severity().String(),
+			"message":          msg,
+			"server":           n.info.Name,
+			"category":         n.info.Category,
+			"level":            n.level,
+			"downtime_seconds": int(n.downtime.Seconds()),
+			"time":             time.Now().UTC().Format(time.RFC3339),
+		}
+	}
+	return r.postJSON(step.WebhookURL, payload)
+}
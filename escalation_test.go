@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testEscalation is the 5m/30m/2h policy: message, role ping, PagerDuty
+func testEscalation(webhookURL string) *EscalationConfig {
+	return &EscalationConfig{Steps: []EscalationStep{
+		{After: "5m", ChannelID: fakeChannelID},
+		{After: "30m", ChannelID: fakeChannelID, RoleID: "222222222222222222"},
+		{After: "2h", WebhookURL: webhookURL, RoutingKey: "pd-key"},
+	}}
+}
+
+// TestValidateEscalationConfig tests the escalation policy checks
+func TestValidateEscalationConfig(t *testing.T) {
+	categories := map[string]bool{"Track": true}
+	valid := testEscalation("https://events.pagerduty.com/v2/enqueue")
+	valid.Steps[0].ChannelID = "123456789012345678"
+	valid.Steps[1].ChannelID = "123456789012345678"
+	if err := validateEscalationConfig(valid, categories); err != nil {
+		t.Fatalf("Expected valid policy, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     *EscalationConfig
+		wantErr string
+	}{
+		{"no steps", &EscalationConfig{}, "at least one step"},
+		{"unknown category", &EscalationConfig{Categories: []string{"Rally"}, Steps: []EscalationStep{{After: "5m", Status: true}}}, "category 'Rally'"},
+		{"bad after", &EscalationConfig{Steps: []EscalationStep{{After: "soon", Status: true}}}, "positive duration"},
+		{"not increasing", &EscalationConfig{Steps: []EscalationStep{{After: "30m", Status: true}, {After: "5m", Status: true}}}, "longer than the previous"},
+		{"no destination", &EscalationConfig{Steps: []EscalationStep{{After: "5m"}}}, "needs a destination"},
+		{"role without channel", &EscalationConfig{Steps: []EscalationStep{{After: "5m", Status: true, RoleID: "222222222222222222"}}}, "role_id needs"},
+		{"bad role", &EscalationConfig{Steps: []EscalationStep{{After: "5m", ChannelID: "123456789012345678", RoleID: "ops"}}}, "not a Discord role ID"},
+		{"routing key without webhook", &EscalationConfig{Steps: []EscalationStep{{After: "5m", Status: true, RoutingKey: "k"}}}, "routing_key needs webhook_url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateEscalationConfig(tt.cfg, categories)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestTrackOutages_Escalation tests that each step fires once at its threshold and the recovery reports all of them
+func TestTrackOutages_Escalation(t *testing.T) {
+	var r alertRouter
+	esc := testEscalation("https://events.pagerduty.com/v2/enqueue")
+	start := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	online := ServerInfo{Name: "Track 1", Category: "Track", Port: 8081, NumPlayers: 3}
+	offline := online
+	offline.NumPlayers = -1
+
+	r.trackOutages([]ServerInfo{online}, esc, start)
+	r.trackOutages([]ServerInfo{offline}, esc, start)
+
+	var levels []int
+	for _, after := range []time.Duration{4 * time.Minute, 5 * time.Minute, 6 * time.Minute, 31 * time.Minute, 3 * time.Hour} {
+		_, notices := r.trackOutages([]ServerInfo{offline}, esc, start.Add(after))
+		for _, n := range notices {
+			levels = append(levels, n.level)
+		}
+	}
+	if len(levels) != 3 || levels[0] != 1 || levels[1] != 2 || levels[2] != 3 {
+		t.Fatalf("Expected steps 1, 2 and 3 once each, got %v", levels)
+	}
+
+	_, notices := r.trackOutages([]ServerInfo{online}, esc, start.Add(3*time.Hour+10*time.Minute))
+	if len(notices) != 1 || !notices[0].resolved || len(notices[0].steps) != 3 || notices[0].downtime != 3*time.Hour+10*time.Minute {
+		t.Fatalf("Expected a recovery notice for all steps, got %+v", notices)
+	}
+	if !strings.Contains(notices[0].message(), "after 3h10m") {
+		t.Errorf("Expected the total downtime in %q", notices[0].message())
+	}
+
+	// A category outside the policy does not escalate; an outage ending before the first step sends no notice
+	esc.Categories = []string{"Drift"}
+	r.trackOutages([]ServerInfo{offline}, esc, start.Add(4*time.Hour))
+	if _, notices := r.trackOutages([]ServerInfo{offline}, esc, start.Add(6*time.Hour)); len(notices) != 0 {
+		t.Errorf("Expected no escalation for another category, got %+v", notices)
+	}
+	if _, notices := r.trackOutages([]ServerInfo{online}, esc, start.Add(7*time.Hour)); len(notices) != 0 {
+		t.Errorf("Expected no recovery notice without escalation, got %+v", notices)
+	}
+}
+
+// TestBotEscalate tests delivery: role ping in the channel, PagerDuty trigger and resolve with the same dedup key
+func TestBotEscalate(t *testing.T) {
+	var mu sync.Mutex
+	var events []pagerDutyEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev pagerDutyEvent
+		json.NewDecoder(r.Body).Decode(&ev)
+		mu.Lock()
+		events = append(events, ev)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	b := newTestBot(testStatusConfig())
+	fake := newFakeDiscord()
+	b.discord = NewDiscordPublisher(fake, fakeChannelID)
+	esc := testEscalation(srv.URL)
+	info := ServerInfo{Name: "Track 1", Category: "Track", Port: 8081}
+
+	b.escalate(escalationNotice{info: info, steps: esc.Steps[1:2], level: 2, downtime: 30 * time.Minute})
+	msgs := fake.channelMessages(fakeChannelID)
+	if len(msgs) != 1 || !strings.HasPrefix(msgs[0].Content, "<@&222222222222222222> 🚨 **Track 1**") || !strings.Contains(msgs[0].Content, "offline for 30m") {
+		t.Fatalf("Expected a role ping, got %v", msgs)
+	}
+
+	b.escalate(escalationNotice{info: info, steps: esc.Steps[2:3], level: 3, downtime: 2 * time.Hour})
+	b.escalate(escalationNotice{info: info, steps: esc.Steps, level: 3, downtime: 2*time.Hour + 5*time.Minute, resolved: true})
+	if len(events) != 2 {
+		t.Fatalf("Expected trigger and resolve, got %+v", events)
+	}
+	trigger, resolve := events[0], events[1]
+	if trigger.EventAction != "trigger" || trigger.RoutingKey != "pd-key" || trigger.Payload == nil || trigger.Payload.Severity != "critical" || trigger.Payload.Source != "Track 1" {
+		t.Errorf("Unexpected trigger %+v", trigger)
+	}
+	if resolve.EventAction != "resolve" || resolve.DedupKey != trigger.DedupKey || resolve.Payload != nil {
+		t.Errorf("Expected a resolve for the same incident, got %+v", resolve)
+	}
+
+	// Steps sharing a channel get one recovery notice, without a ping
+	msgs = fake.channelMessages(fakeChannelID)
+	if len(msgs) != 2 || strings.Contains(msgs[1].Content, "<@&") || !strings.Contains(msgs[1].Content, "back online after 2h5m") {
+		t.Errorf("Expected one recovery message without a ping, got %v", msgs)
+	}
+}
@@ -0,0 +1,572 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/fakeserver"
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeGuildID and fakeChannelID identify the status channel a fakeDiscord starts with
+const (
+	fakeGuildID   = "100"
+	fakeChannelID = "200"
+)
+
+// fakeDiscord is an in-memory DiscordSession: channels hold messages in posting order and IDs increase
+// like snowflakes. Errors queued with failNext are returned by the next call of that method
+type fakeDiscord struct {
+	mu       sync.Mutex
+	user     *discordgo.User
+	channels map[string]*discordgo.Channel
+	messages map[string][]*discordgo.Message
+	events   map[string]*discordgo.GuildScheduledEvent
+	nextID   int
+	perms    int64
+	appFlags int
+	errs     map[string][]error
+	calls    map[string]int
+}
+
+// newFakeDiscord creates a fake with one text channel #status and all permissions
+func newFakeDiscord() *fakeDiscord {
+	f := &fakeDiscord{
+		user:     &discordgo.User{ID: "1", Username: "statusbot"},
+		channels: map[string]*discordgo.Channel{},
+		messages: map[string][]*discordgo.Message{},
+		events:   map[string]*discordgo.GuildScheduledEvent{},
+		nextID:   1000,
+		perms:    discordgo.PermissionAll,
+		errs:     map[string][]error{},
+		calls:    map[string]int{},
+	}
+	f.channels[fakeChannelID] = &discordgo.Channel{ID: fakeChannelID, Name: "status", GuildID: fakeGuildID, Type: discordgo.ChannelTypeGuildText}
+	return f
+}
+
+// discordAPIError builds the REST error Discord returns for a JSON error code
+func discordAPIError(status, code int) error {
+	return &discordgo.RESTError{Response: &http.Response{StatusCode: status}, Message: &discordgo.APIErrorMessage{Code: code}}
+}
+
+// failNext makes the next call of method return err
+func (f *fakeDiscord) failNext(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errs[method] = append(f.errs[method], err)
+}
+
+// call counts a call and returns a queued error for it (caller holds f.mu)
+func (f *fakeDiscord) call(method string) error {
+	f.calls[method]++
+	if errs := f.errs[method]; len(errs) > 0 {
+		f.errs[method] = errs[1:]
+		return errs[0]
+	}
+	return nil
+}
+
+// count returns how often method was called
+func (f *fakeDiscord) count(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+// channelMessages returns a copy of the messages in channelID, oldest first
+func (f *fakeDiscord) channelMessages(channelID string) []*discordgo.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.messages[channelID])
+}
+
+// post adds a message by author to channelID (caller holds f.mu)
+func (f *fakeDiscord) post(channelID string, author *discordgo.User, content string, embeds []*discordgo.MessageEmbed, files int) (*discordgo.Message, error) {
+	if f.channels[channelID] == nil {
+		return nil, discordAPIError(http.StatusNotFound, discordgo.ErrCodeUnknownChannel)
+	}
+	f.nextID++
+	msg := &discordgo.Message{ID: strconv.Itoa(f.nextID), ChannelID: channelID, Author: author, Content: content, Embeds: embeds}
+	for i := 0; i < files; i++ {
+		msg.Attachments = append(msg.Attachments, &discordgo.MessageAttachment{ID: msg.ID + "-" + strconv.Itoa(i)})
+	}
+	f.messages[channelID] = append(f.messages[channelID], msg)
+	return msg, nil
+}
+
+// seed posts a message as another user (or the bot when author is nil) without counting a call
+func (f *fakeDiscord) seed(author *discordgo.User, content string, embeds ...*discordgo.MessageEmbed) *discordgo.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if author == nil {
+		author = f.user
+	}
+	msg, _ := f.post(fakeChannelID, author, content, embeds, 0)
+	return msg
+}
+
+// recreateChannel deletes the status channel and creates a new one with the same name and a new ID
+func (f *fakeDiscord) recreateChannel() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	old := f.channels[fakeChannelID]
+	delete(f.channels, fakeChannelID)
+	delete(f.messages, fakeChannelID)
+	f.nextID++
+	id := strconv.Itoa(f.nextID)
+	f.channels[id] = &discordgo.Channel{ID: id, Name: old.Name, GuildID: old.GuildID, Type: old.Type}
+	return id
+}
+
+// find returns the index of messageID in channelID (caller holds f.mu)
+func (f *fakeDiscord) find(channelID, messageID string) (int, error) {
+	if f.channels[channelID] == nil {
+		return -1, discordAPIError(http.StatusNotFound, discordgo.ErrCodeUnknownChannel)
+	}
+	i := slices.IndexFunc(f.messages[channelID], func(m *discordgo.Message) bool { return m.ID == messageID })
+	if i < 0 {
+		return -1, discordAPIError(http.StatusNotFound, discordgo.ErrCodeUnknownMessage)
+	}
+	return i, nil
+}
+
+func (f *fakeDiscord) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ChannelMessageSend"); err != nil {
+		return nil, err
+	}
+	return f.post(channelID, f.user, content, nil, 0)
+}
+
+func (f *fakeDiscord) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ChannelMessageSendEmbed"); err != nil {
+		return nil, err
+	}
+	return f.post(channelID, f.user, "", []*discordgo.MessageEmbed{embed}, 0)
+}
+
+func (f *fakeDiscord) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ChannelMessageSendComplex"); err != nil {
+		return nil, err
+	}
+	msg, err := f.post(channelID, f.user, data.Content, data.Embeds, len(data.Files))
+	if err == nil {
+		msg.Components = data.Components
+	}
+	return msg, err
+}
+
+func (f *fakeDiscord) ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ChannelMessageEditComplex"); err != nil {
+		return nil, err
+	}
+	i, err := f.find(m.Channel, m.ID)
+	if err != nil {
+		return nil, err
+	}
+	msg := f.messages[m.Channel][i]
+	if m.Embed != nil {
+		msg.Embeds = []*discordgo.MessageEmbed{m.Embed}
+	}
+	if m.Components != nil {
+		msg.Components = *m.Components
+	}
+	if m.Attachments != nil {
+		msg.Attachments = nil
+		for j := range m.Files {
+			msg.Attachments = append(msg.Attachments, &discordgo.MessageAttachment{ID: msg.ID + "-" + strconv.Itoa(j)})
+		}
+	}
+	return msg, nil
+}
+
+func (f *fakeDiscord) ChannelMessageDelete(channelID, messageID string, options ...discordgo.RequestOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ChannelMessageDelete"); err != nil {
+		return err
+	}
+	i, err := f.find(channelID, messageID)
+	if err != nil {
+		return err
+	}
+	f.messages[channelID] = slices.Delete(f.messages[channelID], i, i+1)
+	return nil
+}
+
+// ChannelMessages returns up to limit messages older than beforeID ("" = newest), newest first like Discord
+func (f *fakeDiscord) ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ChannelMessages"); err != nil {
+		return nil, err
+	}
+	if f.channels[channelID] == nil {
+		return nil, discordAPIError(http.StatusNotFound, discordgo.ErrCodeUnknownChannel)
+	}
+	before, _ := strconv.Atoi(beforeID)
+	var out []*discordgo.Message
+	msgs := f.messages[channelID]
+	for i := len(msgs) - 1; i >= 0 && len(out) < limit; i-- {
+		if id, _ := strconv.Atoi(msgs[i].ID); beforeID == "" || id < before {
+			out = append(out, msgs[i])
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeDiscord) Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Channel"); err != nil {
+		return nil, err
+	}
+	ch := f.channels[channelID]
+	if ch == nil {
+		return nil, discordAPIError(http.StatusNotFound, discordgo.ErrCodeUnknownChannel)
+	}
+	return ch, nil
+}
+
+func (f *fakeDiscord) GuildChannels(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("GuildChannels"); err != nil {
+		return nil, err
+	}
+	var out []*discordgo.Channel
+	for _, ch := range f.channels {
+		if ch.GuildID == guildID {
+			out = append(out, ch)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeDiscord) UserChannelPermissions(userID, channelID string, fetchOptions ...discordgo.RequestOption) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("UserChannelPermissions"); err != nil {
+		return 0, err
+	}
+	return f.perms, nil
+}
+
+func (f *fakeDiscord) GuildScheduledEventCreate(guildID string, event *discordgo.GuildScheduledEventParams, options ...discordgo.RequestOption) (*discordgo.GuildScheduledEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("GuildScheduledEventCreate"); err != nil {
+		return nil, err
+	}
+	f.nextID++
+	ev := &discordgo.GuildScheduledEvent{ID: strconv.Itoa(f.nextID), GuildID: guildID, Name: event.Name, Description: event.Description}
+	if event.ScheduledStartTime != nil {
+		ev.ScheduledStartTime = *event.ScheduledStartTime
+	}
+	f.events[ev.ID] = ev
+	return ev, nil
+}
+
+func (f *fakeDiscord) GuildScheduledEventEdit(guildID, eventID string, event *discordgo.GuildScheduledEventParams, options ...discordgo.RequestOption) (*discordgo.GuildScheduledEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("GuildScheduledEventEdit"); err != nil {
+		return nil, err
+	}
+	ev := f.events[eventID]
+	if ev == nil {
+		return nil, discordAPIError(http.StatusNotFound, discordgo.ErrCodeUnknownGuildScheduledEvent)
+	}
+	ev.Name, ev.Description = event.Name, event.Description
+	if event.ScheduledStartTime != nil {
+		ev.ScheduledStartTime = *event.ScheduledStartTime
+	}
+	return ev, nil
+}
+
+func (f *fakeDiscord) GuildScheduledEventDelete(guildID, eventID string, options ...discordgo.RequestOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("GuildScheduledEventDelete"); err != nil {
+		return err
+	}
+	if f.events[eventID] == nil {
+		return discordAPIError(http.StatusNotFound, discordgo.ErrCodeUnknownGuildScheduledEvent)
+	}
+	delete(f.events, eventID)
+	return nil
+}
+
+// scheduledEvents returns the guild's scheduled events by name
+func (f *fakeDiscord) scheduledEvents() map[string]*discordgo.GuildScheduledEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make(map[string]*discordgo.GuildScheduledEvent, len(f.events))
+	for _, ev := range f.events {
+		copied := *ev
+		out[ev.Name] = &copied
+	}
+	return out
+}
+
+// UserChannelCreate opens (or returns) the DM channel "dm-<user>"
+func (f *fakeDiscord) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("UserChannelCreate"); err != nil {
+		return nil, err
+	}
+	id := "dm-" + recipientID
+	if f.channels[id] == nil {
+		f.channels[id] = &discordgo.Channel{ID: id, Type: discordgo.ChannelTypeDM}
+	}
+	return f.channels[id], nil
+}
+
+// Application returns the bot's application with appFlags
+func (f *fakeDiscord) Application(appID string) (*discordgo.Application, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Application"); err != nil {
+		return nil, err
+	}
+	return &discordgo.Application{ID: f.user.ID, Flags: f.appFlags}, nil
+}
+
+func (f *fakeDiscord) BotUser() *discordgo.User {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.user
+}
+
+// newFakeDiscordBot creates a bot-mode Bot talking to f, wired like NewBot (retry publisher, lifecycle, refresh)
+// cleanupChannels lists CLEANUP_CHANNEL_IDS entries; the lifecycle is shut down when the test ends
+func newFakeDiscordBot(t *testing.T, f *fakeDiscord, cfg *Config, cleanupChannels ...string) *Bot {
+	t.Helper()
+	discord := NewDiscordPublisher(f, fakeChannelID)
+	discord.cleanupChannels = map[string]bool{}
+	for _, id := range cleanupChannels {
+		discord.cleanupChannels[id] = true
+	}
+	b := &Bot{configManager: NewConfigManager("/nonexistent/config.json", cfg), discord: discord}
+	b.publishers = []Publisher{newRetryPublisher(discord, b)}
+	if err := b.configureServices(false, "", "", "", nil, false, nil); err != nil {
+		t.Fatalf("configureServices failed: %v", err)
+	}
+	t.Cleanup(func() { b.lifecycle.Shutdown(time.Second) })
+	return b
+}
+
+// ready delivers a gateway Ready event, as on connect and on every reconnect
+func (f *fakeDiscord) ready(b *Bot) {
+	b.onReady(nil, &discordgo.Ready{User: f.BotUser()})
+}
+
+// statusMessagesIn returns the bot's status messages in channelID, oldest first
+func (f *fakeDiscord) statusMessagesIn(channelID string) []*discordgo.Message {
+	var out []*discordgo.Message
+	for _, msg := range f.channelMessages(channelID) {
+		if isStatusMessage(msg, f.BotUser().ID) {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestFakeDiscord_UpdateLoopPostsThenEdits tests that Ready starts the loop, which posts once and then edits
+func TestFakeDiscord_UpdateLoopPostsThenEdits(t *testing.T) {
+	f := newFakeDiscord()
+	b := newFakeDiscordBot(t, f, testStatusConfig())
+
+	f.ready(b)
+	waitFor(t, "the first status message", func() bool { return len(f.statusMessagesIn(fakeChannelID)) == 1 })
+	waitFor(t, "the first update to finish", func() bool { return !b.updates.busy.Load() })
+
+	if !b.tryPerformUpdate() {
+		t.Fatal("Expected the second update to run")
+	}
+	if got := len(f.statusMessagesIn(fakeChannelID)); got != 1 {
+		t.Errorf("Expected the status message to be edited, got %d status messages", got)
+	}
+	if f.count("ChannelMessageEditComplex") != 1 {
+		t.Errorf("Expected 1 edit, got %d", f.count("ChannelMessageEditComplex"))
+	}
+	if report := b.permissions.Load(); report == nil || len(report.missing) != 0 {
+		t.Errorf("Expected a passing permission check on ready, got %+v", report)
+	}
+}
+
+// TestFakeDiscord_ReconnectKeepsOneLoop tests that repeated Ready events neither start loops nor post messages again
+func TestFakeDiscord_ReconnectKeepsOneLoop(t *testing.T) {
+	f := newFakeDiscord()
+	b := newFakeDiscordBot(t, f, testStatusConfig())
+
+	f.ready(b)
+	waitFor(t, "the first status message", func() bool { return len(f.statusMessagesIn(fakeChannelID)) == 1 })
+	for i := 0; i < 3; i++ {
+		f.ready(b)
+	}
+	waitFor(t, "the first update to finish", func() bool { return !b.updates.busy.Load() })
+
+	if !b.lifecycle.Running(componentUpdateLoop) || !b.updateLoopActive.Load() {
+		t.Error("Expected the update loop to keep running")
+	}
+	b.tryPerformUpdate()
+	if got := len(f.statusMessagesIn(fakeChannelID)); got != 1 {
+		t.Errorf("Expected one status message after reconnects, got %d", got)
+	}
+}
+
+// TestFakeDiscord_RestartAdoptsOrCleansUp tests a restarted bot reusing the old status message, or deleting it with cleanup enabled
+func TestFakeDiscord_RestartAdoptsOrCleansUp(t *testing.T) {
+	f := newFakeDiscord()
+	first := newFakeDiscordBot(t, f, testStatusConfig())
+	first.tryPerformUpdate()
+	old := f.statusMessagesIn(fakeChannelID)
+	if len(old) != 1 {
+		t.Fatalf("Expected one status message, got %d", len(old))
+	}
+	f.seed(nil, "⚠️ an alert")
+	f.seed(&discordgo.User{ID: "2"}, "hello")
+
+	// Without cleanup the restarted bot edits the existing message
+	second := newFakeDiscordBot(t, f, testStatusConfig())
+	if err := second.discord.cleanupOldMessages(); err != nil {
+		t.Fatalf("cleanupOldMessages failed: %v", err)
+	}
+	second.tryPerformUpdate()
+	if got := f.statusMessagesIn(fakeChannelID); len(got) != 1 || got[0].ID != old[0].ID {
+		t.Errorf("Expected status message %s to be adopted, got %v", old[0].ID, got)
+	}
+
+	// With cleanup the old status message goes, other messages stay
+	third := newFakeDiscordBot(t, f, testStatusConfig(), fakeChannelID)
+	if err := third.discord.cleanupOldMessages(); err != nil {
+		t.Fatalf("cleanupOldMessages failed: %v", err)
+	}
+	third.tryPerformUpdate()
+	got := f.statusMessagesIn(fakeChannelID)
+	if len(got) != 1 || got[0].ID == old[0].ID {
+		t.Errorf("Expected a new status message replacing %s, got %v", old[0].ID, got)
+	}
+	if n := len(f.channelMessages(fakeChannelID)); n != 3 {
+		t.Errorf("Expected alert, user message and new status message, got %d messages", n)
+	}
+}
+
+// TestFakeDiscord_ChannelRecreated tests that a deleted status channel is re-resolved by name and the status reposted
+func TestFakeDiscord_ChannelRecreated(t *testing.T) {
+	f := newFakeDiscord()
+	b := newFakeDiscordBot(t, f, testStatusConfig())
+	if err := b.discord.rememberChannel(); err != nil {
+		t.Fatalf("rememberChannel failed: %v", err)
+	}
+	b.tryPerformUpdate()
+
+	newID := f.recreateChannel()
+	b.tryPerformUpdate() // edit fails with Unknown Channel and switches channels
+	if b.discord.channel() != newID {
+		t.Fatalf("Expected switch to channel %s, got %s", newID, b.discord.channel())
+	}
+	b.tryPerformUpdate()
+	if got := len(f.statusMessagesIn(newID)); got != 1 {
+		t.Errorf("Expected the status to be reposted in the new channel, got %d messages", got)
+	}
+}
+
+// TestFakeDiscord_PermissionLossAlerts tests that a 403 on the status edit posts one missing-permission alert
+func TestFakeDiscord_PermissionLossAlerts(t *testing.T) {
+	f := newFakeDiscord()
+	b := newFakeDiscordBot(t, f, testStatusConfig())
+	b.tryPerformUpdate()
+
+	f.failNext("ChannelMessageEditComplex", discordAPIError(http.StatusForbidden, discordgo.ErrCodeMissingPermissions))
+	f.failNext("ChannelMessageEditComplex", discordAPIError(http.StatusForbidden, discordgo.ErrCodeMissingPermissions))
+	b.tryPerformUpdate()
+	b.tryPerformUpdate()
+	if got := f.count("ChannelMessageSend"); got != 1 {
+		t.Errorf("Expected one alert for repeated 403s, got %d", got)
+	}
+	if got := len(f.statusMessagesIn(fakeChannelID)); got != 1 {
+		t.Errorf("Expected the status message to stay, got %d", got)
+	}
+}
+
+// startFakeServer serves spec with the game server simulator and returns it as a config entry
+func startFakeServer(t *testing.T, spec fakeserver.Spec, category string) Server {
+	t.Helper()
+	spec.Port = 1 // reported only; the listener port comes from httptest
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	srv := httptest.NewServer(fakeserver.New(spec, 1))
+	t.Cleanup(srv.Close)
+	host, portStr, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	return Server{Name: spec.Name, IP: host, Port: port, Category: category}
+}
+
+// TestFakeDiscord_PollsSimulatedServers tests polling simulated game servers through to the posted embed
+func TestFakeDiscord_PollsSimulatedServers(t *testing.T) {
+	cfg := testStatusConfig()
+	cfg.Servers = []Server{
+		startFakeServer(t, fakeserver.Spec{Name: "Drift 1", Players: []int{4, 9}, MaxPlayers: 16, Track: "content/tracks/ebisu"}, "Drift"),
+		startFakeServer(t, fakeserver.Spec{Name: "Track 1", FlapDown: fakeserver.Duration(time.Hour)}, "Track"),
+	}
+	f := newFakeDiscord()
+	b := newFakeDiscordBot(t, f, cfg)
+
+	embedText := func() string {
+		var text strings.Builder
+		for _, msg := range f.statusMessagesIn(fakeChannelID) {
+			for _, e := range msg.Embeds {
+				text.WriteString(e.Description)
+				for _, field := range e.Fields {
+					text.WriteString(field.Name + field.Value)
+				}
+			}
+		}
+		return text.String()
+	}
+
+	b.tryPerformUpdate()
+	text := embedText()
+	if !strings.Contains(text, "**Total Players:** 4") || !strings.Contains(text, "**Map:** ebisu") {
+		t.Errorf("Expected Drift 1 with 4 players on ebisu, got %q", text)
+	}
+	if !strings.Contains(text, ":red_circle: Track 1") {
+		t.Errorf("Expected Track 1 offline, got %q", text)
+	}
+
+	b.tryPerformUpdate()
+	if text := embedText(); !strings.Contains(text, "**Total Players:** 9") {
+		t.Errorf("Expected the next player count after the second poll, got %q", text)
+	}
+}
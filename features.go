@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ================= FEATURE FLAGS =================
+
+// Feature flag names, the keys of the config's features section
+const (
+	featureAlerts    = "alerts"
+	featureHistory   = "history"
+	featureHooks     = "hooks"
+	featureLogStream = "log_stream"
+)
+
+// featureFlag is a subsystem that can be switched off per deployment
+type featureFlag struct {
+	name string
+	def  bool
+	what string // for the startup log and errors
+}
+
+// featureFlags lists every flag; a subsystem without a flag here cannot be gated
+var featureFlags = []featureFlag{
+	{featureAlerts, true, "routed alerts and escalations (alerts section)"},
+	{featureHistory, true, "server history and uptime reports (HISTORY_ENABLED)"},
+	{featureHooks, true, "script hooks (hooks section)"},
+	{featureLogStream, true, "live log stream API (GET /api/v1/logs/stream)"},
+}
+
+// lookupFeature returns the flag called name
+func lookupFeature(name string) (featureFlag, bool) {
+	i := slices.IndexFunc(featureFlags, func(f featureFlag) bool { return f.name == name })
+	if i < 0 {
+		return featureFlag{}, false
+	}
+	return featureFlags[i], true
+}
+
+// featureEnvName is the variable overriding the flag name, e.g. FEATURE_LOG_STREAM
+func featureEnvName(name string) string {
+	return "FEATURE_" + strings.ToUpper(name)
+}
+
+// validateFeatures checks that the features section names known flags only
+func validateFeatures(features map[string]bool) error {
+	for name := range features {
+		if _, ok := lookupFeature(name); !ok {
+			names := make([]string, len(featureFlags))
+			for i, f := range featureFlags {
+				names[i] = f.name
+			}
+			return fmt.Errorf("features: unknown feature %q (known: %s)", name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// featureSet holds the flags of this run: the config's features section with FEATURE_<NAME> variables on top
+// Flags are read once at startup; changing them takes a restart. The zero value has every flag at its default
+type featureSet struct {
+	values  map[string]bool   // flags that are set (missing = default)
+	sources map[string]string // where each set flag came from, for the startup log
+}
+
+// featuresFromEnv resolves the flags from cfg (nil before setup) and the environment
+func featuresFromEnv(cfg *Config) (featureSet, error) {
+	fs := featureSet{values: map[string]bool{}, sources: map[string]string{}}
+	if cfg != nil {
+		for name, on := range cfg.Features {
+			fs.values[name] = on
+			fs.sources[name] = "config"
+		}
+	}
+	for _, f := range featureFlags {
+		env := featureEnvName(f.name)
+		v := os.Getenv(env)
+		if v == "" {
+			continue
+		}
+		switch strings.ToLower(v) {
+		case "true", "on", "1":
+			fs.values[f.name] = true
+		case "false", "off", "0":
+			fs.values[f.name] = false
+		default:
+			return featureSet{}, fmt.Errorf("invalid %s %q: must be true or false", env, v)
+		}
+		fs.sources[f.name] = env
+	}
+	return fs, nil
+}
+
+// enabled reports whether the flag name is on; name must be one of the feature* constants
+func (fs featureSet) enabled(name string) bool {
+	if on, ok := fs.values[name]; ok {
+		return on
+	}
+	f, ok := lookupFeature(name)
+	if !ok {
+		panic("unknown feature flag " + name)
+	}
+	return f.def
+}
+
+// summary describes every flag for the startup log, e.g. "alerts=on, history=off (FEATURE_HISTORY)"
+func (fs featureSet) summary() string {
+	parts := make([]string, len(featureFlags))
+	for i, f := range featureFlags {
+		state := "off"
+		if fs.enabled(f.name) {
+			state = "on"
+		}
+		parts[i] = f.name + "=" + state
+		if src, ok := fs.sources[f.name]; ok {
+			parts[i] += " (" + src + ")"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// logDisabled logs what every switched off flag turns off
+func (fs featureSet) logDisabled() {
+	for _, f := range featureFlags {
+		if !fs.enabled(f.name) {
+			log.Printf("Feature %s is off: %s disabled", f.name, f.what)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFeaturesFromEnv tests defaults, the config section and env overrides on top of it
+func TestFeaturesFromEnv(t *testing.T) {
+	var zero featureSet
+	for _, f := range featureFlags {
+		if zero.enabled(f.name) != f.def {
+			t.Errorf("Expected %s at its default in the zero value", f.name)
+		}
+	}
+
+	cfg := &Config{Features: map[string]bool{featureHistory: false, featureLogStream: false}}
+	t.Setenv("FEATURE_LOG_STREAM", "on")
+	t.Setenv("FEATURE_ALERTS", "false")
+	fs, err := featuresFromEnv(cfg)
+	if err != nil {
+		t.Fatalf("featuresFromEnv failed: %v", err)
+	}
+	if fs.enabled(featureHistory) || !fs.enabled(featureLogStream) || fs.enabled(featureAlerts) {
+		t.Errorf("Unexpected flags: %s", fs.summary())
+	}
+	want := "alerts=off (FEATURE_ALERTS), history=off (config), hooks=on, log_stream=on (FEATURE_LOG_STREAM)"
+	if got := fs.summary(); got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+
+	if fs, err := featuresFromEnv(nil); err != nil || !fs.enabled(featureHistory) {
+		t.Errorf("Expected defaults without a config, got %v %v", fs.summary(), err)
+	}
+	t.Setenv("FEATURE_ALERTS", "maybe")
+	if _, err := featuresFromEnv(cfg); err == nil || !strings.Contains(err.Error(), "FEATURE_ALERTS") {
+		t.Errorf("Expected an invalid value to be refused, got %v", err)
+	}
+}
+
+// TestValidateFeatures tests that unknown flags fail config validation
+func TestValidateFeatures(t *testing.T) {
+	if err := validateFeatures(map[string]bool{featureAlerts: false}); err != nil {
+		t.Errorf("Expected a known flag to pass, got %v", err)
+	}
+	err := validateFeatures(map[string]bool{"websockets": true})
+	if err == nil || !strings.Contains(err.Error(), `"websockets"`) || !strings.Contains(err.Error(), featureLogStream) {
+		t.Errorf("Expected the unknown flag and the known ones in the error, got %v", err)
+	}
+
+	cfg := testStatusConfig()
+	cfg.Features = map[string]bool{"histroy": false}
+	if err := validateConfigStructSafeRuntime(cfg); err == nil {
+		t.Error("Expected runtime validation to refuse an unknown flag")
+	}
+}
+
+// TestFeatureAlertsOff tests that the alerts flag silences routed alerts
+func TestFeatureAlertsOff(t *testing.T) {
+	b := newTestBot(testStatusConfig())
+	pub := &fakePublisher{name: "fake"}
+	b.publishers = []Publisher{pub}
+	b.features = featureSet{values: map[string]bool{featureAlerts: false}}
+
+	b.alert(newAlertEvent(alertDiscordPermission, "no permission"))
+	if len(pub.alerts) != 0 {
+		t.Errorf("Expected no alerts with the flag off, got %q", pub.alerts)
+	}
+}
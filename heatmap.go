@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// ================= OCCUPANCY HEATMAP =================
+
+// buildHeatmap averages the players of server ("" = all servers together) per weekday and hour in loc
+// A cell is the player-hours of its hours divided by how many of its hours have history, so hours the bot
+// did not run are left out rather than counted as empty
+func buildHeatmap(buckets []historyBucket, server string, from, to time.Time, loc *time.Location) *api.Heatmap {
+	var seconds [7][24]int64
+	hours := make(map[time.Time]bool)
+	var seen [7][24]int
+	for _, b := range buckets {
+		if (server != "" && b.Server != server) || b.Hour.Before(from) || !b.Hour.Before(to) {
+			continue
+		}
+		at := b.Hour.In(loc)
+		d, h := (int(at.Weekday())+6)%7, at.Hour() // Monday first
+		seconds[d][h] += b.PlayerSeconds
+		if !hours[b.Hour] {
+			hours[b.Hour] = true
+			seen[d][h]++
+		}
+	}
+
+	m := &api.Heatmap{Server: server, Timezone: loc.String(), From: from, To: to}
+	for d := range seconds {
+		for h := range seconds[d] {
+			if seen[d][h] == 0 {
+				continue
+			}
+			avg := math.Round(float64(seconds[d][h])/3600/float64(seen[d][h])*10) / 10
+			m.Players[d][h] = &avg
+		}
+	}
+	return m
+}
+
+// heatmapProvider adapts the bot's history to api.HeatmapProvider
+type heatmapProvider struct {
+	bot *Bot
+}
+
+// Heatmap implements api.HeatmapProvider
+func (p *heatmapProvider) Heatmap(server string, from, to time.Time, loc *time.Location) (*api.Heatmap, error) {
+	buckets := p.bot.history.buckets(from, to)
+	if server != "" {
+		cfg := p.bot.configManager.GetConfig()
+		configured := cfg != nil && slices.ContainsFunc(cfg.Servers, func(s Server) bool { return s.Name == server })
+		if !configured && !slices.ContainsFunc(buckets, func(b historyBucket) bool { return b.Server == server }) {
+			return nil, fmt.Errorf("%w '%s'", api.ErrUnknownServer, server)
+		}
+	}
+	return buildHeatmap(buckets, server, from, to, loc), nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestBuildHeatmap tests the averages per weekday and hour, the server filter, the time zone and cells without history
+func TestBuildHeatmap(t *testing.T) {
+	from := time.Date(2026, 9, 7, 0, 0, 0, 0, time.UTC) // a Monday
+	to := from.AddDate(0, 0, 14)
+	buckets := []historyBucket{
+		{Hour: from.Add(20 * time.Hour), Server: "Track #1", PlayerSeconds: 4 * 3600},
+		{Hour: from.Add(20 * time.Hour), Server: "Drift #1", PlayerSeconds: 2 * 3600},
+		{Hour: from.AddDate(0, 0, 7).Add(20 * time.Hour), Server: "Track #1", PlayerSeconds: 3600},
+		{Hour: from.Add(-time.Hour), Server: "Track #1", PlayerSeconds: 36000}, // before the range
+	}
+
+	all := buildHeatmap(buckets, "", from, to, time.UTC)
+	if p := all.Players[0][20]; p == nil || *p != 3.5 {
+		t.Errorf("Expected 3.5 players on Mondays at 20:00 over all servers, got %v", p)
+	}
+	if all.Players[6][23] != nil || all.Players[0][21] != nil {
+		t.Error("Expected no data for hours without history")
+	}
+
+	track := buildHeatmap(buckets, "Track #1", from, to, time.UTC)
+	if p := track.Players[0][20]; p == nil || *p != 2.5 || track.Server != "Track #1" {
+		t.Errorf("Expected 2.5 players on Track #1, got %v", p)
+	}
+
+	tokyo := time.FixedZone("JST", 9*3600)
+	shifted := buildHeatmap(buckets, "Track #1", from, to, tokyo)
+	if p := shifted.Players[1][5]; p == nil || *p != 2.5 || shifted.Timezone != "JST" {
+		t.Errorf("Expected the cell to move to Tuesday 05:00 in JST, got %v", p)
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/store"
+)
+
+// ================= SERVER HISTORY =================
+
+const (
+	// historyStateFile is where earlier releases kept the history next to config.json; it is imported into
+	// the database once and renamed to history.json.imported
+	historyStateFile = "history.json"
+	// defaultHistoryRetention is how long hourly buckets are kept unless HISTORY_RETENTION_DAYS is set
+	// (the current and the two previous months)
+	defaultHistoryRetention = 93 * 24 * time.Hour
+	// historyPruneInterval is how often buckets past the retention are dropped from memory
+	historyPruneInterval = time.Hour
+	// historyMetaLastReport is the history_meta row of the last monthly report posted
+	historyMetaLastReport = "last_report"
+)
+
+// historyBucket sums the polls of one server during one UTC hour
+type historyBucket struct {
+	Hour             time.Time `json:"hour"` // start of the hour, UTC
+	Server           string    `json:"server"`
+	Category         string    `json:"category"`
+	Polls            int       `json:"polls"`
+	OnlinePolls      int       `json:"online_polls"`
+	MaintenancePolls int       `json:"maintenance_polls,omitempty"` // not counted against uptime
+	// PlayerSeconds is the players of every poll times the update interval
+	PlayerSeconds int64            `json:"player_seconds"`
+	Tracks        map[string]int64 `json:"tracks,omitempty"` // map -> player seconds
+	// Sessions counts joins: the rise in players from one poll to the next
+	Sessions int `json:"sessions,omitempty"`
+}
+
+// historyKey identifies a bucket
+type historyKey struct {
+	hour   time.Time
+	server string
+}
+
+// historyState is the history held in memory (and the content of history.json before the database)
+type historyState struct {
+	Buckets []historyBucket `json:"buckets"`
+	// LastReport is the month ("2006-01") of the last monthly report posted, so a restart does not post it again
+	LastReport string `json:"last_report,omitempty"`
+}
+
+// historyStore records every poll into hourly buckets per server, the base of the uptime reports
+// Buckets older than retention are dropped. The history is kept in memory and written to the
+// history_buckets table by the writer; buckets are keyed by server name, so bots sharing a PostgreSQL
+// database need distinct server names
+type historyStore struct {
+	db        *store.DB
+	writer    *historyWriter // nil = memory only
+	retention time.Duration
+
+	mu       sync.Mutex
+	state    historyState
+	index    map[historyKey]int  // into state.Buckets
+	pending  map[historyKey]bool // changed buckets not queued yet (the queue was full)
+	prunedAt time.Time
+	// players is each server's count at the last poll it was online, for counting sessions (not saved:
+	// the first poll after a restart or an outage starts no sessions)
+	players map[string]int
+}
+
+// newHistoryStore returns a store writing to db with the default retention, flush interval and queue size
+// (nil db = memory only)
+func newHistoryStore(db *store.DB) *historyStore {
+	h := &historyStore{
+		db:        db,
+		retention: defaultHistoryRetention,
+		index:     make(map[historyKey]int),
+		pending:   make(map[historyKey]bool),
+		players:   make(map[string]int),
+	}
+	if db != nil {
+		h.writer = newHistoryWriter(db, h.retention, defaultHistoryFlushInterval, defaultHistoryQueueSize)
+	}
+	return h
+}
+
+// historyStoreFromEnv returns the store in db if HISTORY_ENABLED is true, nil otherwise
+// HISTORY_RETENTION_DAYS overrides how long the history is kept (default 93 days); see historyWriterFromEnv
+// for the write-behind settings
+func historyStoreFromEnv(configPath string, db *store.DB) (*historyStore, error) {
+	if os.Getenv("HISTORY_ENABLED") != "true" {
+		return nil, nil
+	}
+	if db == nil {
+		return nil, fmt.Errorf("HISTORY_ENABLED needs a database")
+	}
+	retention, err := retentionFromEnv("HISTORY_RETENTION_DAYS", defaultHistoryRetention)
+	if err != nil {
+		return nil, err
+	}
+	if retention == 0 {
+		return nil, fmt.Errorf("HISTORY_RETENTION_DAYS must be at least 1")
+	}
+	writer, err := historyWriterFromEnv(db, retention)
+	if err != nil {
+		return nil, err
+	}
+	h := newHistoryStore(db)
+	h.retention = retention
+	h.writer = writer
+	if err := h.load(time.Now()); err != nil {
+		return nil, err
+	}
+	h.importFile(filepath.Join(filepath.Dir(configPath), historyStateFile))
+	log.Printf("Server history enabled (%d hourly records in %s, kept %d days, written every %v)",
+		len(h.state.Buckets), db, int(retention.Hours()/24), writer.interval)
+	return h, nil
+}
+
+// load reads the buckets within the retention and the last report month from the database
+func (h *historyStore) load(now time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), databaseTimeout)
+	defer cancel()
+
+	rows, err := h.db.QueryContext(ctx, h.db.Rebind(`SELECT hour, server, category, polls, online_polls, maintenance_polls,
+		player_seconds, sessions, tracks FROM history_buckets WHERE hour >= ? ORDER BY hour, server`), now.Add(-h.retention).Unix())
+	if err != nil {
+		return fmt.Errorf("failed to read the server history: %w", err)
+	}
+	defer rows.Close()
+	var state historyState
+	for rows.Next() {
+		var b historyBucket
+		var hour int64
+		var tracks string
+		if err := rows.Scan(&hour, &b.Server, &b.Category, &b.Polls, &b.OnlinePolls, &b.MaintenancePolls,
+			&b.PlayerSeconds, &b.Sessions, &tracks); err != nil {
+			return fmt.Errorf("failed to read the server history: %w", err)
+		}
+		b.Hour = time.Unix(hour, 0).UTC()
+		if tracks != "" {
+			if err := json.Unmarshal([]byte(tracks), &b.Tracks); err != nil {
+				log.Printf("Warning: ignoring invalid tracks of %s at %s: %v", b.Server, b.Hour.Format(time.RFC3339), err)
+			}
+		}
+		state.Buckets = append(state.Buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read the server history: %w", err)
+	}
+	err = h.db.QueryRowContext(ctx, h.db.Rebind("SELECT value FROM history_meta WHERE name = ?"), historyMetaLastReport).Scan(&state.LastReport)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read the server history: %w", err)
+	}
+	h.state = state
+	h.reindex()
+	return nil
+}
+
+// importFile moves the history.json of an earlier release into an empty database and renames the file to
+// history.json.imported; with history in the database already, the file is left alone
+func (h *historyStore) importFile(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Warning: failed to read server history %s: %v", path, err)
+		}
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.state.Buckets) > 0 || h.state.LastReport != "" {
+		log.Printf("Warning: ignoring %s: the database has server history already", path)
+		return
+	}
+	var state historyState
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Printf("Warning: ignoring invalid server history %s: %v", path, err)
+		return
+	}
+	now := time.Now()
+	h.state = state
+	h.reindex()
+	h.prune(now)
+	for _, b := range h.state.Buckets {
+		h.writer.add(b)
+	}
+	if h.state.LastReport != "" {
+		h.writer.setLastReport(h.state.LastReport)
+	}
+	if err := h.writer.flush(now); err != nil {
+		return // logged; the file is imported again on the next start
+	}
+	if err := os.Rename(path, path+".imported"); err != nil {
+		log.Printf("Warning: failed to rename %s after importing it: %v", path, err)
+	}
+	log.Printf("Imported %d hourly records from %s", len(h.state.Buckets), path)
+}
+
+// reindex rebuilds the bucket index (caller holds h.mu)
+func (h *historyStore) reindex() {
+	h.index = make(map[historyKey]int, len(h.state.Buckets))
+	for i, b := range h.state.Buckets {
+		h.index[historyKey{b.Hour, b.Server}] = i
+	}
+}
+
+// record adds one poll of every server, each standing for interval of time
+// Offline and stale servers count as down; servers in maintenance count neither way
+// A session is counted for every player more than at the server's previous poll
+func (h *historyStore) record(infos []ServerInfo, interval time.Duration, now time.Time) {
+	if h == nil {
+		return
+	}
+	hour := now.UTC().Truncate(time.Hour)
+	seconds := int64(interval / time.Second)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, info := range infos {
+		key := historyKey{hour, info.Name}
+		i, ok := h.index[key]
+		if !ok {
+			h.state.Buckets = append(h.state.Buckets, historyBucket{Hour: hour, Server: info.Name, Category: info.Category})
+			i = len(h.state.Buckets) - 1
+			h.index[key] = i
+		}
+		if h.writer != nil {
+			h.pending[key] = true
+		}
+		b := &h.state.Buckets[i]
+		b.Category = info.Category
+		b.Polls++
+		prev, seen := h.players[info.Name]
+		delete(h.players, info.Name)
+		switch {
+		case info.Maintenance != nil:
+			b.MaintenancePolls++
+		case !serverDown(info):
+			b.OnlinePolls++
+			h.players[info.Name] = info.NumPlayers
+			if seen && info.NumPlayers > prev {
+				b.Sessions += info.NumPlayers - prev
+			}
+			if info.NumPlayers > 0 {
+				b.PlayerSeconds += int64(info.NumPlayers) * seconds
+				if b.Tracks == nil {
+					b.Tracks = make(map[string]int64)
+				}
+				b.Tracks[info.Map] += int64(info.NumPlayers) * seconds
+			}
+		}
+	}
+	h.queue()
+	if now.Sub(h.prunedAt) >= historyPruneInterval {
+		h.prune(now)
+		h.prunedAt = now
+	}
+}
+
+// queue hands a snapshot of the changed buckets to the writer (caller holds h.mu)
+// Buckets that do not fit stay pending and are queued again after the next poll
+func (h *historyStore) queue() {
+	for key := range h.pending {
+		i, ok := h.index[key]
+		if ok {
+			b := h.state.Buckets[i]
+			b.Tracks = maps.Clone(b.Tracks)
+			if !h.writer.enqueue(b) {
+				return
+			}
+		}
+		delete(h.pending, key)
+	}
+}
+
+// prune drops buckets older than the retention (caller holds h.mu)
+func (h *historyStore) prune(now time.Time) {
+	cutoff := now.Add(-h.retention)
+	n := len(h.state.Buckets)
+	h.state.Buckets = slices.DeleteFunc(h.state.Buckets, func(b historyBucket) bool { return b.Hour.Before(cutoff) })
+	if len(h.state.Buckets) != n {
+		h.reindex()
+	}
+}
+
+// flush writes the queued and pending buckets, on shutdown after the writer stopped
+func (h *historyStore) flush() {
+	if h == nil || h.writer == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for key := range h.pending {
+		if i, ok := h.index[key]; ok {
+			b := h.state.Buckets[i]
+			b.Tracks = maps.Clone(b.Tracks)
+			h.writer.add(b)
+		}
+	}
+	clear(h.pending)
+	h.writer.flush(time.Now())
+}
+
+// buckets returns a copy of the buckets whose hour is in [from, to)
+func (h *historyStore) buckets(from, to time.Time) []historyBucket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []historyBucket
+	for _, b := range h.state.Buckets {
+		if !b.Hour.Before(from) && b.Hour.Before(to) {
+			b.Tracks = maps.Clone(b.Tracks)
+			out = append(out, b)
+		}
+	}
+	return out
+}
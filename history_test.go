@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestHistoryRecord tests hourly buckets, maintenance and stale polls, player seconds by track and persistence
+func TestHistoryRecord(t *testing.T) {
+	db := testDatabase(t)
+	h := newHistoryStore(db)
+	now := time.Date(2026, 9, 30, 23, 10, 0, 0, time.UTC)
+	stale := ServerInfo{Name: "Track #1", Category: "Track", Map: "monza", NumPlayers: 3, LastSeen: now.Add(-time.Minute)}
+	online := []ServerInfo{
+		{Name: "Drift #1", Category: "Drift", Map: "ebisu", NumPlayers: 4},
+		{Name: "Track #1", Category: "Track", Map: "monza", NumPlayers: 2},
+	}
+
+	h.record(online, 30*time.Second, now)
+	h.record([]ServerInfo{{Name: "Drift #1", Category: "Drift", Map: "ebisu", NumPlayers: 4, Maintenance: &maintenanceEntry{}}, stale}, 30*time.Second, now.Add(time.Minute))
+	h.record(online, 30*time.Second, now.Add(time.Hour)) // next hour, new buckets
+
+	buckets := h.buckets(now.Truncate(time.Hour), now.Truncate(time.Hour).Add(time.Hour))
+	if len(buckets) != 2 {
+		t.Fatalf("Expected two buckets in the first hour, got %+v", buckets)
+	}
+	drift, track := buckets[0], buckets[1]
+	if drift.Polls != 2 || drift.OnlinePolls != 1 || drift.MaintenancePolls != 1 || drift.PlayerSeconds != 120 || drift.Tracks["ebisu"] != 120 {
+		t.Errorf("Unexpected Drift #1 bucket %+v", drift)
+	}
+	if track.Polls != 2 || track.OnlinePolls != 1 || track.PlayerSeconds != 60 {
+		t.Errorf("Expected the stale poll counted as down, got %+v", track)
+	}
+
+	h.flush()
+	reloaded := newHistoryStore(db)
+	if err := reloaded.load(now); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if got := reloaded.buckets(now.Add(-time.Hour), now.Add(2*time.Hour)); len(got) != 4 {
+		t.Errorf("Expected 4 buckets after reload, got %d", len(got))
+	}
+	reloaded.record(online, 30*time.Second, now.Add(defaultHistoryRetention+2*time.Hour))
+	if got := reloaded.buckets(now.Add(-time.Hour), now.Add(2*time.Hour)); len(got) != 0 {
+		t.Errorf("Expected buckets past the retention pruned, got %d", len(got))
+	}
+}
+
+// TestHistorySessions tests that sessions count rises in players, not the first poll or returns from an outage
+func TestHistorySessions(t *testing.T) {
+	h := newHistoryStore(nil)
+	now := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+	poll := func(players int) {
+		info := ServerInfo{Name: "Track #1", Category: "Track", Map: "spa", NumPlayers: players}
+		if players < 0 {
+			info = offlineServerInfo(Server{Name: "Track #1", Category: "Track"})
+		}
+		h.record([]ServerInfo{info}, 30*time.Second, now)
+		now = now.Add(30 * time.Second)
+	}
+	for _, players := range []int{3, 5, 2, 4, -1, 6, 7} {
+		poll(players)
+	}
+	b := h.buckets(now.Add(-time.Hour), now)
+	if len(b) != 1 || b[0].Sessions != 5 {
+		t.Errorf("Expected 2+2+1 sessions, got %+v", b)
+	}
+}
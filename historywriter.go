@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/metrics"
+	"github.com/bombom/absa-ac/pkg/store"
+)
+
+// ================= HISTORY WRITE-BEHIND =================
+
+const (
+	// defaultHistoryFlushInterval is how often queued history rows are written unless HISTORY_FLUSH_INTERVAL
+	// is set; at most this much is lost on a crash
+	defaultHistoryFlushInterval = time.Minute
+	// defaultHistoryQueueSize bounds the rows waiting for the writer unless HISTORY_QUEUE_SIZE is set
+	defaultHistoryQueueSize = 1000
+	// maxHistoryQueueSize bounds HISTORY_QUEUE_SIZE
+	maxHistoryQueueSize = 100000
+)
+
+// History writer metrics, served at /metrics with component="history"
+var (
+	historyQueueRows = metrics.NewGauge("absa_history_queue_rows",
+		"History rows waiting in the write-behind queue, by component", "component")
+	historyRowsDropped = metrics.NewCounter("absa_history_rows_dropped_total",
+		"History rows not queued because the queue was full (sent again with the next poll), by component", "component")
+	historyRowsWritten = metrics.NewCounter("absa_history_rows_written_total",
+		"History rows written to the database, by component", "component")
+	historyFlushFailures = metrics.NewCounter("absa_history_flush_failures_total",
+		"Failed history writes (the batch is kept for the next flush), by component", "component")
+)
+
+// historyWriter writes history rows to the database behind the polls: record queues a snapshot of every
+// changed bucket without blocking, and the writer keeps the latest snapshot per bucket and writes the batch
+// in one transaction every interval, so a poll costs no database round trip
+type historyWriter struct {
+	db        *store.DB
+	retention time.Duration
+	interval  time.Duration
+	rows      chan historyBucket
+
+	mu         sync.Mutex // held during a flush
+	batch      map[historyKey]historyBucket
+	lastReport string // month to write with the next flush ("" = unchanged)
+}
+
+func newHistoryWriter(db *store.DB, retention, interval time.Duration, size int) *historyWriter {
+	return &historyWriter{
+		db:        db,
+		retention: retention,
+		interval:  interval,
+		rows:      make(chan historyBucket, size),
+		batch:     make(map[historyKey]historyBucket),
+	}
+}
+
+// historyWriterFromEnv returns the writer for db with HISTORY_FLUSH_INTERVAL (1s-1h, default 1m) and
+// HISTORY_QUEUE_SIZE (default 1000)
+func historyWriterFromEnv(db *store.DB, retention time.Duration) (*historyWriter, error) {
+	interval := defaultHistoryFlushInterval
+	if v := os.Getenv("HISTORY_FLUSH_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < time.Second || d > time.Hour {
+			return nil, fmt.Errorf("invalid HISTORY_FLUSH_INTERVAL %q: must be a duration between 1s and 1h, e.g. 1m", v)
+		}
+		interval = d
+	}
+	size := defaultHistoryQueueSize
+	if v := os.Getenv("HISTORY_QUEUE_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxHistoryQueueSize {
+			return nil, fmt.Errorf("invalid HISTORY_QUEUE_SIZE %q: must be 1-%d", v, maxHistoryQueueSize)
+		}
+		size = n
+	}
+	return newHistoryWriter(db, retention, interval, size), nil
+}
+
+// enqueue queues a snapshot of b without blocking and reports whether it fit
+func (w *historyWriter) enqueue(b historyBucket) bool {
+	select {
+	case w.rows <- b:
+		return true
+	default:
+		historyRowsDropped.Inc(metrics.ComponentHistory)
+		return false
+	}
+}
+
+// add puts b into the batch, replacing an older snapshot of the same bucket
+func (w *historyWriter) add(b historyBucket) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.merge(b)
+}
+
+// merge keeps the newer of b and the batched snapshot of its bucket; buckets only grow, so the one with
+// more polls is newer (caller holds w.mu)
+func (w *historyWriter) merge(b historyBucket) {
+	key := historyKey{b.Hour, b.Server}
+	if old, ok := w.batch[key]; ok && old.Polls > b.Polls {
+		return
+	}
+	w.batch[key] = b
+}
+
+// setLastReport writes month as the last report posted with the next flush
+func (w *historyWriter) setLastReport(month string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastReport = month
+}
+
+// Run moves queued rows into the batch and writes it every interval until ctx is cancelled, then writes
+// what is left
+func (w *historyWriter) Run(ctx context.Context) error {
+	historyQueueRows.SetFunc(func() float64 { return float64(len(w.rows)) }, metrics.ComponentHistory)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			w.flush(time.Now())
+			return nil
+		case b := <-w.rows:
+			w.add(b)
+		case now := <-ticker.C:
+			w.flush(now)
+		}
+	}
+}
+
+// flush drains the queue and writes the batch, deleting rows past the retention; on failure the batch is
+// kept for the next flush
+func (w *historyWriter) flush(now time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for drained := false; !drained; {
+		select {
+		case b := <-w.rows:
+			w.merge(b)
+		default:
+			drained = true
+		}
+	}
+	if len(w.batch) == 0 && w.lastReport == "" {
+		return nil
+	}
+	if err := w.write(now); err != nil {
+		historyFlushFailures.Inc(metrics.ComponentHistory)
+		log.Printf("Warning: failed to write %d history rows to %s: %v", len(w.batch), w.db, err)
+		return err
+	}
+	historyRowsWritten.Add(float64(len(w.batch)), metrics.ComponentHistory)
+	clear(w.batch)
+	w.lastReport = ""
+	return nil
+}
+
+// write stores the batch and the last report month in one transaction (caller holds w.mu)
+func (w *historyWriter) write(now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), databaseTimeout)
+	defer cancel()
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	upsert, err := tx.PrepareContext(ctx, w.db.Rebind(`INSERT INTO history_buckets (hour, server, category, polls, online_polls,
+		maintenance_polls, player_seconds, sessions, tracks) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (hour, server) DO UPDATE SET category = excluded.category, polls = excluded.polls,
+		online_polls = excluded.online_polls, maintenance_polls = excluded.maintenance_polls,
+		player_seconds = excluded.player_seconds, sessions = excluded.sessions, tracks = excluded.tracks`))
+	if err != nil {
+		return err
+	}
+	defer upsert.Close()
+	cutoff := now.Add(-w.retention)
+	for _, b := range w.batch {
+		if b.Hour.Before(cutoff) {
+			continue
+		}
+		tracks := ""
+		if len(b.Tracks) > 0 {
+			data, _ := json.Marshal(b.Tracks)
+			tracks = string(data)
+		}
+		if _, err := upsert.ExecContext(ctx, b.Hour.Unix(), b.Server, b.Category, b.Polls, b.OnlinePolls,
+			b.MaintenancePolls, b.PlayerSeconds, b.Sessions, tracks); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, w.db.Rebind("DELETE FROM history_buckets WHERE hour < ?"), cutoff.Unix()); err != nil {
+		return err
+	}
+	if w.lastReport != "" {
+		if _, err := tx.ExecContext(ctx, w.db.Rebind(`INSERT INTO history_meta (name, value) VALUES (?, ?)
+			ON CONFLICT (name) DO UPDATE SET value = excluded.value`), historyMetaLastReport, w.lastReport); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/metrics"
+	"github.com/bombom/absa-ac/pkg/store"
+)
+
+// historyRows counts the buckets in the database
+func historyRows(t *testing.T, db *store.DB) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM history_buckets").Scan(&n); err != nil {
+		t.Fatalf("Failed to count history rows: %v", err)
+	}
+	return n
+}
+
+// TestHistoryWriterBatches tests that polls are written only on flush, and that rows dropped by a full
+// queue are counted and queued again with the next poll
+func TestHistoryWriterBatches(t *testing.T) {
+	db := testDatabase(t)
+	h := newHistoryStore(db)
+	h.writer = newHistoryWriter(db, h.retention, time.Hour, 1)
+	now := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+	poll := slotPoll(1)
+	dropped := historyRowsDropped.Value(metrics.ComponentHistory)
+
+	h.record(poll, 30*time.Second, now)
+	if got := historyRowsDropped.Value(metrics.ComponentHistory) - dropped; got != 1 {
+		t.Errorf("Expected one dropped row, got %v", got)
+	}
+	if len(h.pending) != 1 || historyRows(t, db) != 0 {
+		t.Fatalf("Expected one pending bucket and nothing written before a flush, got %d pending, %d rows", len(h.pending), historyRows(t, db))
+	}
+
+	h.writer.flush(now)
+	h.record(poll, 30*time.Second, now.Add(30*time.Second)) // queues the pending bucket first
+	h.flush()
+	if n := historyRows(t, db); n != 2 || len(h.pending) != 0 {
+		t.Fatalf("Expected both buckets written, got %d rows, %d pending", n, len(h.pending))
+	}
+	reloaded := newHistoryStore(db)
+	if err := reloaded.load(now); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	for _, b := range reloaded.buckets(now, now.Add(time.Hour)) {
+		if b.Polls != 2 {
+			t.Errorf("Expected the latest snapshot of %s written, got %+v", b.Server, b)
+		}
+	}
+}
+
+// TestHistoryWriterRetriesFailedFlush tests that a failed write keeps the batch for the next flush
+func TestHistoryWriterRetriesFailedFlush(t *testing.T) {
+	db := testDatabase(t)
+	h := newHistoryStore(db)
+	now := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+	h.record(slotPoll(1), 30*time.Second, now)
+
+	db.Exec("ALTER TABLE history_buckets RENAME TO history_buckets_away")
+	if err := h.writer.flush(now); err == nil {
+		t.Fatal("Expected the flush to fail without the table")
+	}
+	db.Exec("ALTER TABLE history_buckets_away RENAME TO history_buckets")
+	if err := h.writer.flush(now); err != nil || historyRows(t, db) != 2 {
+		t.Errorf("Expected the kept batch written, got %v and %d rows", err, historyRows(t, db))
+	}
+}
+
+// TestHistoryWriterRun tests that the writer flushes when it is stopped
+func TestHistoryWriterRun(t *testing.T) {
+	db := testDatabase(t)
+	h := newHistoryStore(db)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.writer.Run(ctx)
+		close(done)
+	}()
+
+	h.record(slotPoll(1), 30*time.Second, time.Now())
+	h.markReported(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC))
+	cancel()
+	<-done
+	reloaded := newHistoryStore(db)
+	if err := reloaded.load(time.Now()); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(reloaded.state.Buckets) != 2 || reloaded.state.LastReport != "2026-09" {
+		t.Errorf("Expected the queued rows and the last report written on stop, got %+v", reloaded.state)
+	}
+}
+
+// TestHistoryWriterFromEnv tests the flush interval and queue size settings
+func TestHistoryWriterFromEnv(t *testing.T) {
+	w, err := historyWriterFromEnv(nil, defaultHistoryRetention)
+	if err != nil || w.interval != defaultHistoryFlushInterval || cap(w.rows) != defaultHistoryQueueSize {
+		t.Fatalf("Expected the defaults, got %v", err)
+	}
+	t.Setenv("HISTORY_FLUSH_INTERVAL", "10s")
+	t.Setenv("HISTORY_QUEUE_SIZE", "50")
+	if w, err := historyWriterFromEnv(nil, defaultHistoryRetention); err != nil || w.interval != 10*time.Second || cap(w.rows) != 50 {
+		t.Errorf("Expected 10s and 50, got %v", err)
+	}
+	for name, v := range map[string]string{"HISTORY_FLUSH_INTERVAL": "2h", "HISTORY_QUEUE_SIZE": "0"} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, v)
+			if _, err := historyWriterFromEnv(nil, defaultHistoryRetention); err == nil {
+				t.Errorf("Expected an error for %s=%s", name, v)
+			}
+		})
+	}
+}
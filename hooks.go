@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	starlarkjson "go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// ================= SCRIPT HOOKS =================
+
+// Hook events, the values of a hook's "event"
+const (
+	hookPollComplete = "on_poll_complete"
+	hookStatusChange = "on_status_change"
+	hookConfigChange = "on_config_change"
+)
+
+const (
+	// defaultHookTimeout bounds one script run unless the hook sets timeout_seconds
+	defaultHookTimeout = 10 * time.Second
+	// maxHookTimeout bounds timeout_seconds
+	maxHookTimeout = 60 * time.Second
+	// maxHookSteps bounds the Starlark steps of one run, so a runaway loop ends before the timeout
+	maxHookSteps = 10_000_000
+	// hookQueueSize bounds the events waiting for the hook worker; more are dropped with a warning
+	hookQueueSize = 100
+	// maxHookResponseBody bounds the response body handed to a script by http.post/http.get
+	maxHookResponseBody = 64 << 10
+	// maxHooks bounds the hooks in the config
+	maxHooks = 20
+)
+
+// hookEvents lists the events in documentation order
+var hookEvents = []string{hookPollComplete, hookStatusChange, hookConfigChange}
+
+// HookConfig is one entry of the hooks section: a Starlark script run on an event
+// The script runs top to bottom with "event" (a dict), "http", "json" and "log" predeclared
+type HookConfig struct {
+	Name           string `json:"name"`
+	Event          string `json:"event"`
+	Script         string `json:"script"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+}
+
+// timeout returns how long one run may take
+func (h HookConfig) timeout() time.Duration {
+	if h.TimeoutSeconds > 0 {
+		return time.Duration(h.TimeoutSeconds) * time.Second
+	}
+	return defaultHookTimeout
+}
+
+// hookFileOptions allows if/for at the top level, so a hook needs no function around its code
+var hookFileOptions = &syntax.FileOptions{TopLevelControl: true, GlobalReassign: true, While: true}
+
+// hookPredeclared lists the names a script can use besides the Starlark builtins
+var hookPredeclared = map[string]bool{"event": true, "http": true, "json": true, "log": true}
+
+// compileHook parses and resolves a hook script
+func compileHook(name, script string) (*starlark.Program, error) {
+	_, prog, err := starlark.SourceProgramOptions(hookFileOptions, name, script, func(n string) bool { return hookPredeclared[n] })
+	return prog, err
+}
+
+// validateHooks checks names, events, timeouts and that every script compiles
+func validateHooks(hooks []HookConfig) error {
+	if len(hooks) > maxHooks {
+		return fmt.Errorf("hooks: at most %d hooks allowed (got %d)", maxHooks, len(hooks))
+	}
+	seen := make(map[string]bool, len(hooks))
+	for i, h := range hooks {
+		if strings.TrimSpace(h.Name) == "" {
+			return fmt.Errorf("hooks[%d]: name cannot be empty", i)
+		}
+		if seen[h.Name] {
+			return fmt.Errorf("hooks[%d]: duplicate name %q", i, h.Name)
+		}
+		seen[h.Name] = true
+		if !slices.Contains(hookEvents, h.Event) {
+			return fmt.Errorf("hooks[%d] (%s): unknown event %q (must be one of %s)", i, h.Name, h.Event, strings.Join(hookEvents, ", "))
+		}
+		if h.TimeoutSeconds < 0 || time.Duration(h.TimeoutSeconds)*time.Second > maxHookTimeout {
+			return fmt.Errorf("hooks[%d] (%s): timeout_seconds must be between 1 and %d", i, h.Name, int(maxHookTimeout/time.Second))
+		}
+		if strings.TrimSpace(h.Script) == "" {
+			return fmt.Errorf("hooks[%d] (%s): script cannot be empty", i, h.Name)
+		}
+		if _, err := compileHook(h.Name, h.Script); err != nil {
+			return fmt.Errorf("hooks[%d] (%s): %v", i, h.Name, err)
+		}
+	}
+	return nil
+}
+
+// hookEvent is an event waiting for the hook worker, with the hooks of the config it came from
+type hookEvent struct {
+	name    string
+	payload map[string]any
+	hooks   []HookConfig
+}
+
+// hookRunner runs the config's hooks on a background worker, so a slow script never delays a poll
+// Compiled scripts are cached by source, so unchanged hooks survive a reload without recompiling
+type hookRunner struct {
+	client *http.Client
+	queue  chan hookEvent
+
+	mu       sync.Mutex
+	programs map[string]*starlark.Program
+	states   map[string]string // server -> status at the last poll (on_status_change)
+}
+
+func newHookRunner() *hookRunner {
+	return &hookRunner{
+		client:   &http.Client{Timeout: maxHookTimeout},
+		queue:    make(chan hookEvent, hookQueueSize),
+		programs: make(map[string]*starlark.Program),
+		states:   make(map[string]string),
+	}
+}
+
+// enqueue queues an event for the hooks listening to it, without blocking
+func (r *hookRunner) enqueue(hooks []HookConfig, event string, payload map[string]any) {
+	if !slices.ContainsFunc(hooks, func(h HookConfig) bool { return h.Event == event }) {
+		return
+	}
+	payload["event"] = event
+	select {
+	case r.queue <- hookEvent{name: event, payload: payload, hooks: hooks}:
+	default:
+		log.Printf("Warning: hook queue full, dropping %s event", event)
+	}
+}
+
+// serverState is the status of a server as hooks see it
+func serverState(s ServerStatus) string {
+	switch {
+	case s.Maintenance:
+		return "maintenance"
+	case s.Online:
+		return "online"
+	}
+	return "offline"
+}
+
+// pollComplete queues on_poll_complete with the status, and on_status_change for every server whose state
+// changed since the last poll (servers seen for the first time start no change)
+func (r *hookRunner) pollComplete(snap *StatusSnapshot, hooks []HookConfig) {
+	r.mu.Lock()
+	var changes []map[string]any
+	for _, cat := range snap.Categories {
+		for _, s := range cat.Servers {
+			state := serverState(s)
+			if prev, ok := r.states[s.Name]; ok && prev != state {
+				changes = append(changes, map[string]any{
+					"time": snap.UpdatedAt, "server": s, "category": cat.Name, "from": prev, "to": state,
+				})
+			}
+			r.states[s.Name] = state
+		}
+	}
+	r.mu.Unlock()
+
+	r.enqueue(hooks, hookPollComplete, map[string]any{"time": snap.UpdatedAt, "status": snap})
+	for _, c := range changes {
+		r.enqueue(hooks, hookStatusChange, c)
+	}
+}
+
+// configChange queues on_config_change for the hooks of the new config, with a summary of the change
+func (r *hookRunner) configChange(old, new *Config, now time.Time) {
+	if new == nil {
+		return
+	}
+	r.enqueue(new.Hooks, hookConfigChange, map[string]any{
+		"time":       now,
+		"summary":    describeConfigChange(old, new),
+		"servers":    len(new.Servers),
+		"categories": new.CategoryOrder,
+	})
+}
+
+// Run runs queued events until ctx is cancelled
+func (r *hookRunner) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-r.queue:
+			r.dispatch(ctx, ev)
+		}
+	}
+}
+
+// dispatch runs every hook of the event in config order; a failing hook is logged and does not stop the others
+func (r *hookRunner) dispatch(ctx context.Context, ev hookEvent) {
+	for _, h := range ev.hooks {
+		if h.Event != ev.name {
+			continue
+		}
+		if err := r.run(ctx, h, ev.payload); err != nil {
+			log.Printf("Error in hook %s (%s): %v", h.Name, ev.name, err)
+		}
+	}
+}
+
+// program returns the compiled script of h
+func (r *hookRunner) program(h HookConfig) (*starlark.Program, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if prog, ok := r.programs[h.Script]; ok {
+		return prog, nil
+	}
+	prog, err := compileHook(h.Name, h.Script)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.programs) >= maxHooks*2 {
+		clear(r.programs) // drop scripts of old configs
+	}
+	r.programs[h.Script] = prog
+	return prog, nil
+}
+
+// run executes h with payload as the event, within the hook's timeout and the step limit
+func (r *hookRunner) run(ctx context.Context, h HookConfig, payload map[string]any) error {
+	prog, err := r.program(h)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout())
+	defer cancel()
+
+	thread := &starlark.Thread{
+		Name:  "hook " + h.Name,
+		Print: func(_ *starlark.Thread, msg string) { log.Printf("Hook %s: %s", h.Name, msg) },
+	}
+	thread.SetMaxExecutionSteps(maxHookSteps)
+	stop := context.AfterFunc(ctx, func() { thread.Cancel("timeout after " + h.timeout().String()) })
+	defer stop()
+
+	event, err := toStarlark(thread, payload)
+	if err != nil {
+		return err
+	}
+	_, err = prog.Init(thread, starlark.StringDict{
+		"event": event,
+		"http":  r.httpModule(ctx),
+		"json":  starlarkjson.Module,
+		"log": starlark.NewBuiltin("log", func(t *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var msg string
+			if err := starlark.UnpackPositionalArgs("log", args, kwargs, 1, &msg); err != nil {
+				return nil, err
+			}
+			t.Print(t, msg)
+			return starlark.None, nil
+		}),
+	})
+	return err
+}
+
+// toStarlark converts a JSON-encodable value into Starlark dicts, lists and scalars
+func toStarlark(thread *starlark.Thread, v any) (starlark.Value, error) {
+	data, err := jsonMarshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.Call(thread, starlarkjson.Module.Members["decode"], starlark.Tuple{starlark.String(data)}, nil)
+}
+
+// httpModule returns the "http" module: get(url, headers={}) and post(url, body, headers={}), each returning
+// a struct with status and body. A dict or list body is sent as JSON
+func (r *hookRunner) httpModule(ctx context.Context) *starlarkstruct.Module {
+	do := func(thread *starlark.Thread, method, url string, body starlark.Value, headers *starlark.Dict) (starlark.Value, error) {
+		if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+			return nil, fmt.Errorf("http.%s: url must start with http:// or https://", strings.ToLower(method))
+		}
+		var reader io.Reader
+		contentType := ""
+		switch b := body.(type) {
+		case nil, starlark.NoneType:
+		case starlark.String:
+			reader = strings.NewReader(string(b))
+			contentType = "text/plain; charset=utf-8"
+		default:
+			encoded, err := starlark.Call(thread, starlarkjson.Module.Members["encode"], starlark.Tuple{body}, nil)
+			if err != nil {
+				return nil, err
+			}
+			reader = strings.NewReader(string(encoded.(starlark.String)))
+			contentType = "application/json"
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, reader)
+		if err != nil {
+			return nil, err
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if headers != nil {
+			for _, item := range headers.Items() {
+				k, kok := starlark.AsString(item[0])
+				v, vok := starlark.AsString(item[1])
+				if !kok || !vok {
+					return nil, fmt.Errorf("http.%s: headers must map strings to strings", strings.ToLower(method))
+				}
+				req.Header.Set(k, v)
+			}
+		}
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxHookResponseBody))
+		if err != nil {
+			return nil, err
+		}
+		return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"status": starlark.MakeInt(resp.StatusCode),
+			"body":   starlark.String(data),
+		}), nil
+	}
+	return &starlarkstruct.Module{
+		Name: "http",
+		Members: starlark.StringDict{
+			"get": starlark.NewBuiltin("http.get", func(t *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+				var url string
+				var headers *starlark.Dict
+				if err := starlark.UnpackArgs("http.get", args, kwargs, "url", &url, "headers?", &headers); err != nil {
+					return nil, err
+				}
+				return do(t, http.MethodGet, url, nil, headers)
+			}),
+			"post": starlark.NewBuiltin("http.post", func(t *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+				var url string
+				var body starlark.Value
+				var headers *starlark.Dict
+				if err := starlark.UnpackArgs("http.post", args, kwargs, "url", &url, "body?", &body, "headers?", &headers); err != nil {
+					return nil, err
+				}
+				return do(t, http.MethodPost, url, body, headers)
+			}),
+		},
+	}
+}
+
+// jsonMarshal encodes v without escaping <, > and &, so scripts see names as configured
+func jsonMarshal(v any) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// runPollHooks queues the poll and status change hooks after an update (leader only, no-op without hooks)
+func (b *Bot) runPollHooks(snap *StatusSnapshot, cfg *Config) {
+	if b.hooks == nil || !b.isLeader() {
+		return
+	}
+	b.hooks.pollComplete(snap, cfg.Hooks)
+}
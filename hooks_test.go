@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestValidateHooks tests hook names, events, timeouts and script compilation
+func TestValidateHooks(t *testing.T) {
+	valid := HookConfig{Name: "notify", Event: hookPollComplete, Script: `log("players: %d" % event["status"]["total_players"])`}
+	if err := validateHooks([]HookConfig{valid}); err != nil {
+		t.Fatalf("Expected valid hook, got %v", err)
+	}
+	tests := []struct {
+		name  string
+		hooks []HookConfig
+		want  string
+	}{
+		{"empty name", []HookConfig{{Event: hookPollComplete, Script: "pass"}}, "name cannot be empty"},
+		{"duplicate", []HookConfig{valid, valid}, "duplicate name"},
+		{"unknown event", []HookConfig{{Name: "a", Event: "on_start", Script: "pass"}}, "unknown event"},
+		{"timeout", []HookConfig{{Name: "a", Event: hookPollComplete, Script: "pass", TimeoutSeconds: 61}}, "timeout_seconds"},
+		{"empty script", []HookConfig{{Name: "a", Event: hookPollComplete}}, "script cannot be empty"},
+		{"syntax error", []HookConfig{{Name: "a", Event: hookPollComplete, Script: "if :"}}, "hooks[0] (a)"},
+		{"undefined name", []HookConfig{{Name: "a", Event: hookPollComplete, Script: "discord.send(1)"}}, "undefined: discord"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHooks(tt.hooks)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestHookRunner_StatusChange tests that a status change hook posts to a webhook, and that the first poll starts no change
+func TestHookRunner_StatusChange(t *testing.T) {
+	bodies := make(chan map[string]any, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("X-Token") != "secret" {
+			t.Errorf("Unexpected headers %v", r.Header)
+		}
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("Invalid JSON body %q: %v", data, err)
+		}
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	hooks := []HookConfig{{
+		Name:  "webhook",
+		Event: hookStatusChange,
+		Script: `
+resp = http.post("` + srv.URL + `", {"server": event["server"]["name"], "from": event["from"], "to": event["to"]}, headers={"X-Token": "secret"})
+if resp.status != 200:
+    fail("webhook returned %d" % resp.status)
+`,
+	}}
+	if err := validateHooks(hooks); err != nil {
+		t.Fatalf("Invalid hooks: %v", err)
+	}
+	r := newHookRunner()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+
+	snap := func(online bool) *StatusSnapshot {
+		return &StatusSnapshot{UpdatedAt: time.Now(), Categories: []CategoryStatus{
+			{Name: "Drift", Servers: []ServerStatus{{Name: "Drift 1", Online: online}}},
+		}}
+	}
+	r.pollComplete(snap(true), hooks)
+	r.pollComplete(snap(true), hooks)
+	r.pollComplete(snap(false), hooks)
+
+	select {
+	case body := <-bodies:
+		if body["server"] != "Drift 1" || body["from"] != "online" || body["to"] != "offline" {
+			t.Errorf("Unexpected webhook body %v", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the status change hook")
+	}
+	select {
+	case body := <-bodies:
+		t.Errorf("Expected one status change, got another: %v", body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestHookRunner_Limits tests that a runaway script is stopped and a failing hook does not stop the next one
+func TestHookRunner_Limits(t *testing.T) {
+	ran := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		ran <- string(data)
+	}))
+	defer srv.Close()
+
+	hooks := []HookConfig{
+		{Name: "loop", Event: hookConfigChange, Script: "while True:\n    pass\n", TimeoutSeconds: 1},
+		{Name: "report", Event: hookConfigChange, Script: `http.post("` + srv.URL + `", event["summary"])`},
+	}
+	r := newHookRunner()
+	start := time.Now()
+	old := &Config{Servers: []Server{{Name: "A"}}}
+	r.configChange(old, &Config{Servers: []Server{{Name: "A"}, {Name: "B"}}, Hooks: hooks}, start)
+	r.dispatch(context.Background(), <-r.queue)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Runaway hook was not stopped (took %v)", elapsed)
+	}
+	select {
+	case summary := <-ran:
+		if summary == "" {
+			t.Error("Expected a change summary")
+		}
+	default:
+		t.Error("Expected the second hook to run after the first failed")
+	}
+}
+
+// TestHookRunner_NoListeners tests that events without a listening hook are not queued
+func TestHookRunner_NoListeners(t *testing.T) {
+	r := newHookRunner()
+	hooks := []HookConfig{{Name: "a", Event: hookConfigChange, Script: "pass"}}
+	r.pollComplete(&StatusSnapshot{UpdatedAt: time.Now()}, hooks)
+	if len(r.queue) != 0 {
+		t.Errorf("Expected no queued events, got %d", len(r.queue))
+	}
+}
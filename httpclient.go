@@ -0,0 +1,193 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ================= POLLING HTTP CLIENT =================
+
+// Defaults for the polling HTTP client when config omits http_client or individual fields
+// MaxIdleConnsPerHost is well above Go's default of 2 because every game server usually
+// lives on the same server_ip, so all polls share one host's idle pool
+const (
+	defaultPollTimeout             = 2 * time.Second
+	defaultPollMaxIdleConns        = 100
+	defaultPollMaxIdleConnsPerHost = 16
+	defaultPollIdleConnTimeout     = 90 * time.Second
+)
+
+// HTTPClientConfig tunes the shared transport used to poll game servers
+// All fields are optional; zero values fall back to the defaults above
+type HTTPClientConfig struct {
+	TimeoutSeconds         int    `json:"timeout_seconds,omitempty"`
+	MaxIdleConns           int    `json:"max_idle_conns,omitempty"`
+	MaxIdleConnsPerHost    int    `json:"max_idle_conns_per_host,omitempty"`
+	IdleConnTimeoutSeconds int    `json:"idle_conn_timeout_seconds,omitempty"`
+	DisableKeepAlives      bool   `json:"disable_keep_alives,omitempty"`
+	InsecureSkipVerify     bool   `json:"insecure_skip_verify,omitempty"` // self-signed game-server panels (HTTPS only)
+	ProxyURL               string `json:"proxy_url,omitempty"`            // http://, https:// or socks5:// proxy for polls
+	DNSCacheTTLSeconds     int    `json:"dns_cache_ttl_seconds,omitempty"`
+	DisableDNSCache        bool   `json:"disable_dns_cache,omitempty"`
+}
+
+// timeout returns the per-request poll timeout
+func (c HTTPClientConfig) timeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return defaultPollTimeout
+}
+
+// dnsCacheTTL returns how long resolved hostnames are cached
+func (c HTTPClientConfig) dnsCacheTTL() time.Duration {
+	if c.DNSCacheTTLSeconds > 0 {
+		return time.Duration(c.DNSCacheTTLSeconds) * time.Second
+	}
+	return defaultDNSCacheTTL
+}
+
+// validateHTTPClientConfig checks http_client settings (nil is valid: defaults apply)
+func validateHTTPClientConfig(c *HTTPClientConfig) error {
+	if c == nil {
+		return nil
+	}
+	if c.TimeoutSeconds < 0 {
+		return fmt.Errorf("http_client.timeout_seconds cannot be negative (got: %d)", c.TimeoutSeconds)
+	}
+	if c.MaxIdleConns < 0 || c.MaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("http_client idle connection limits cannot be negative")
+	}
+	if c.DNSCacheTTLSeconds < 0 {
+		return fmt.Errorf("http_client.dns_cache_ttl_seconds cannot be negative (got: %d)", c.DNSCacheTTLSeconds)
+	}
+	if c.IdleConnTimeoutSeconds < 0 {
+		return fmt.Errorf("http_client.idle_conn_timeout_seconds cannot be negative (got: %d)", c.IdleConnTimeoutSeconds)
+	}
+	if c.ProxyURL != "" {
+		u, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("http_client.proxy_url is invalid: %w", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("http_client.proxy_url scheme must be http, https or socks5 (got: %q)", u.Scheme)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("http_client.proxy_url must include a host")
+		}
+	}
+	return nil
+}
+
+// newPollTransport builds an http.Transport from settings
+// Hostnames are resolved through dns unless the DNS cache is disabled (dns may be nil)
+func newPollTransport(c HTTPClientConfig, dns *dnsCache) *http.Transport {
+	maxIdle := c.MaxIdleConns
+	if maxIdle == 0 {
+		maxIdle = defaultPollMaxIdleConns
+	}
+	maxIdlePerHost := c.MaxIdleConnsPerHost
+	if maxIdlePerHost == 0 {
+		maxIdlePerHost = defaultPollMaxIdleConnsPerHost
+	}
+	idleTimeout := defaultPollIdleConnTimeout
+	if c.IdleConnTimeoutSeconds > 0 {
+		idleTimeout = time.Duration(c.IdleConnTimeoutSeconds) * time.Second
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   c.timeout(),
+		KeepAlive: 30 * time.Second,
+	}
+
+	dialContext := dialer.DialContext
+	if dns != nil && !c.DisableDNSCache {
+		dns.SetTTL(c.dnsCacheTTL())
+		dialContext = dns.DialContext(dialer)
+	}
+
+	transport := &http.Transport{
+		DialContext:         dialContext,
+		MaxIdleConns:        maxIdle,
+		MaxIdleConnsPerHost: maxIdlePerHost,
+		IdleConnTimeout:     idleTimeout,
+		DisableKeepAlives:   c.DisableKeepAlives,
+		TLSHandshakeTimeout: c.timeout(),
+	}
+
+	if c.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	if c.ProxyURL != "" {
+		// Already validated by validateHTTPClientConfig
+		if proxyURL, err := url.Parse(c.ProxyURL); err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	return transport
+}
+
+// pollClientManager hands out a single shared *http.Client for all pollers
+// The client (and its connection pool) is rebuilt only when http_client settings change,
+// so polling many servers reuses keep-alive connections instead of opening new sockets each cycle
+type pollClientManager struct {
+	mu       sync.Mutex
+	settings HTTPClientConfig
+	client   *http.Client
+	chaos    *chaosInjector
+}
+
+// Get returns the shared client for the given settings (nil means defaults)
+func (m *pollClientManager) Get(c *HTTPClientConfig) *http.Client {
+	var settings HTTPClientConfig
+	if c != nil {
+		settings = *c
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client != nil && m.settings == settings {
+		return m.client
+	}
+
+	if m.client != nil {
+		// Release idle sockets held by the previous transport
+		m.client.CloseIdleConnections()
+		log.Println("Polling HTTP client settings changed, rebuilding transport")
+	}
+	if settings.InsecureSkipVerify {
+		log.Println("[WARNING] http_client.insecure_skip_verify=true: TLS certificates of polled servers are not verified")
+	}
+
+	m.settings = settings
+	m.client = &http.Client{
+		Timeout:   settings.timeout(),
+		Transport: m.chaos.wrapTransport(newPollTransport(settings, pollDNS)),
+	}
+	return m.client
+}
+
+// setChaos injects poll faults into the client from the next Get on (nil turns injection off)
+func (m *pollClientManager) setChaos(c *chaosInjector) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.chaos = c
+	if m.client != nil {
+		m.client.CloseIdleConnections()
+		m.client = nil
+	}
+}
+
+// pollClients is the process-wide shared polling client
+var pollClients = &pollClientManager{}
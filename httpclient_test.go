@@ -0,0 +1,125 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestValidateHTTPClientConfig tests http_client validation rules
+func TestValidateHTTPClientConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *HTTPClientConfig
+		wantErr bool
+	}{
+		{name: "Nil uses defaults", cfg: nil},
+		{name: "Valid tuning", cfg: &HTTPClientConfig{TimeoutSeconds: 5, MaxIdleConnsPerHost: 32, ProxyURL: "socks5://proxy:1080"}},
+		{name: "Negative timeout", cfg: &HTTPClientConfig{TimeoutSeconds: -1}, wantErr: true},
+		{name: "Negative idle conns", cfg: &HTTPClientConfig{MaxIdleConnsPerHost: -1}, wantErr: true},
+		{name: "Negative idle timeout", cfg: &HTTPClientConfig{IdleConnTimeoutSeconds: -5}, wantErr: true},
+		{name: "Unsupported proxy scheme", cfg: &HTTPClientConfig{ProxyURL: "ftp://proxy:21"}, wantErr: true},
+		{name: "Proxy without host", cfg: &HTTPClientConfig{ProxyURL: "http://"}, wantErr: true},
+		{name: "Negative DNS cache TTL", cfg: &HTTPClientConfig{DNSCacheTTLSeconds: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHTTPClientConfig(tt.cfg)
+			if tt.wantErr && err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestNewPollTransport tests that settings and defaults are applied to the transport
+func TestNewPollTransport(t *testing.T) {
+	tr := newPollTransport(HTTPClientConfig{}, nil)
+	if tr.MaxIdleConnsPerHost != defaultPollMaxIdleConnsPerHost {
+		t.Errorf("Expected default MaxIdleConnsPerHost %d, got %d", defaultPollMaxIdleConnsPerHost, tr.MaxIdleConnsPerHost)
+	}
+	if tr.Proxy != nil {
+		t.Error("Expected no proxy by default")
+	}
+	if tr.TLSClientConfig != nil && tr.TLSClientConfig.InsecureSkipVerify {
+		t.Error("Expected TLS verification enabled by default")
+	}
+
+	tr = newPollTransport(HTTPClientConfig{
+		MaxIdleConnsPerHost:    4,
+		IdleConnTimeoutSeconds: 10,
+		DisableKeepAlives:      true,
+		InsecureSkipVerify:     true,
+		ProxyURL:               "http://proxy.local:3128",
+	}, nil)
+	if tr.MaxIdleConnsPerHost != 4 {
+		t.Errorf("Expected MaxIdleConnsPerHost 4, got %d", tr.MaxIdleConnsPerHost)
+	}
+	if tr.IdleConnTimeout != 10*time.Second {
+		t.Errorf("Expected IdleConnTimeout 10s, got %v", tr.IdleConnTimeout)
+	}
+	if !tr.DisableKeepAlives {
+		t.Error("Expected keep-alives disabled")
+	}
+	if tr.TLSClientConfig == nil || !tr.TLSClientConfig.InsecureSkipVerify {
+		t.Error("Expected InsecureSkipVerify to be set")
+	}
+	if tr.Proxy == nil {
+		t.Error("Expected proxy to be configured")
+	}
+}
+
+// TestPollClientManager_ReusesClient tests that the shared client is only rebuilt on settings change
+func TestPollClientManager_ReusesClient(t *testing.T) {
+	m := &pollClientManager{}
+
+	first := m.Get(nil)
+	if first.Timeout != defaultPollTimeout {
+		t.Errorf("Expected default timeout %v, got %v", defaultPollTimeout, first.Timeout)
+	}
+	if m.Get(nil) != first {
+		t.Error("Expected same client for unchanged settings")
+	}
+	if m.Get(&HTTPClientConfig{}) != first {
+		t.Error("Expected empty settings to be equivalent to nil")
+	}
+
+	changed := m.Get(&HTTPClientConfig{TimeoutSeconds: 5})
+	if changed == first {
+		t.Error("Expected new client after settings change")
+	}
+	if changed.Timeout != 5*time.Second {
+		t.Errorf("Expected 5s timeout, got %v", changed.Timeout)
+	}
+}
+
+// TestFetchServerInfo_SharedClient tests polling a server through the shared client
+func TestFetchServerInfo_SharedClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"clients": 3, "maxclients": 10, "track": "content/tracks/ks_nordschleife"}`))
+	}))
+	defer srv.Close()
+
+	host, portStr, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	client := (&pollClientManager{}).Get(nil)
+	info := fetchServerInfo(client, Server{Name: "Test", IP: host, Port: port, Category: "Track"})
+
+	if info.NumPlayers != 3 {
+		t.Errorf("Expected 3 players, got %d", info.NumPlayers)
+	}
+	if info.Players != "3/10" {
+		t.Errorf("Expected '3/10', got '%s'", info.Players)
+	}
+	if info.Map != "ks_nordschleife" {
+		t.Errorf("Expected 'ks_nordschleife', got '%s'", info.Map)
+	}
+}
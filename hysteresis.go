@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// ================= STATUS HYSTERESIS =================
+
+// hysteresisTracker debounces online/offline changes: a server must be polled down offlineAfter times in a row
+// before it is shown offline, and up onlineAfter times in a row before it is shown online again
+// Until then the previous state is shown, so the embed, alerts, incidents and player history all see the
+// debounced status (it runs right after stale tracking, before everything else)
+type hysteresisTracker struct {
+	offlineAfter int
+	onlineAfter  int
+
+	mu      sync.Mutex
+	servers map[serverKey]*hysteresisState
+}
+
+// hysteresisState is the shown state of one server and the run of polls disagreeing with it
+type hysteresisState struct {
+	shown  ServerInfo
+	streak int
+}
+
+// newHysteresisTracker creates a tracker; thresholds of 1 change state on the first disagreeing poll
+func newHysteresisTracker(offlineAfter, onlineAfter int) *hysteresisTracker {
+	return &hysteresisTracker{offlineAfter: offlineAfter, onlineAfter: onlineAfter, servers: make(map[serverKey]*hysteresisState)}
+}
+
+// apply returns infos with each server's change held back until enough consecutive polls agree
+// A server's first poll is shown as is
+func (t *hysteresisTracker) apply(infos []ServerInfo) []ServerInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := make(map[serverKey]bool, len(infos))
+	out := make([]ServerInfo, len(infos))
+	for i, info := range infos {
+		key := serverKey{info.Name, info.Port}
+		current[key] = true
+		out[i] = info
+
+		st := t.servers[key]
+		if st == nil {
+			t.servers[key] = &hysteresisState{shown: info}
+			continue
+		}
+		down := serverDown(info)
+		wasDown := serverDown(st.shown)
+		if down == wasDown {
+			st.shown = info
+			st.streak = 0
+			continue
+		}
+
+		st.streak++
+		need := t.onlineAfter
+		if down {
+			need = t.offlineAfter
+		}
+		if st.streak >= need {
+			if need > 1 {
+				state := "online"
+				if down {
+					state = "offline"
+				}
+				log.Printf("Server '%s' %s for %d consecutive polls, showing it %s", info.Name, state, st.streak, state)
+			}
+			st.shown = info
+			st.streak = 0
+			continue
+		}
+
+		// Keep showing the previous state; the current config decides grouping and address
+		held := st.shown
+		held.Category = info.Category
+		held.IP = info.IP
+		out[i] = held
+	}
+
+	// Forget servers removed from the config
+	for key := range t.servers {
+		if !current[key] {
+			delete(t.servers, key)
+		}
+	}
+	return out
+}
+
+// applyHysteresis debounces status changes in a poll result (no-op when disabled)
+func (b *Bot) applyHysteresis(infos []ServerInfo) []ServerInfo {
+	if b.hysteresis == nil {
+		return infos
+	}
+	return b.hysteresis.apply(infos)
+}
+
+// hysteresisTrackerFromEnv returns a tracker if HYSTERESIS_OFFLINE_POLLS or HYSTERESIS_ONLINE_POLLS is above 1,
+// nil otherwise (every poll result is shown as is)
+func hysteresisTrackerFromEnv() (*hysteresisTracker, error) {
+	offlineAfter, err := hysteresisPollsFromEnv("HYSTERESIS_OFFLINE_POLLS")
+	if err != nil {
+		return nil, err
+	}
+	onlineAfter, err := hysteresisPollsFromEnv("HYSTERESIS_ONLINE_POLLS")
+	if err != nil {
+		return nil, err
+	}
+	if offlineAfter == 1 && onlineAfter == 1 {
+		return nil, nil
+	}
+	log.Printf("Status hysteresis enabled: offline after %d consecutive failed polls, online after %d consecutive successful polls",
+		offlineAfter, onlineAfter)
+	return newHysteresisTracker(offlineAfter, onlineAfter), nil
+}
+
+// hysteresisPollsFromEnv reads a poll count from name (default 1)
+func hysteresisPollsFromEnv(name string) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive number of polls", name, v)
+	}
+	return n, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestHysteresisTracker_HoldsChanges tests that offline and online changes need consecutive agreeing polls
+func TestHysteresisTracker_HoldsChanges(t *testing.T) {
+	tr := newHysteresisTracker(3, 2)
+	online := ServerInfo{Name: "Drift #1", Category: "Drift", Map: "ebisu", NumPlayers: 5, Port: 8081}
+	failed := offlineServerInfo(Server{Name: "Drift #1", Category: "Drift", Port: 8081})
+
+	tr.apply([]ServerInfo{online})
+
+	// Two failed polls keep showing the server online; the third flags it offline
+	for i := 1; i <= 2; i++ {
+		if got := tr.apply([]ServerInfo{failed})[0]; serverDown(got) || got.NumPlayers != 5 {
+			t.Fatalf("Poll %d: expected the server still online, got %+v", i, got)
+		}
+	}
+	if got := tr.apply([]ServerInfo{failed})[0]; !serverDown(got) {
+		t.Fatalf("Expected the server offline after 3 failed polls, got %+v", got)
+	}
+
+	// One successful poll is not enough to clear it, two are
+	if got := tr.apply([]ServerInfo{online})[0]; !serverDown(got) {
+		t.Fatalf("Expected the server still offline, got %+v", got)
+	}
+	if got := tr.apply([]ServerInfo{online})[0]; serverDown(got) || got.NumPlayers != 5 {
+		t.Fatalf("Expected the server online after 2 successful polls, got %+v", got)
+	}
+}
+
+// TestHysteresisTracker_Flapping tests that alternating polls never change the shown state
+func TestHysteresisTracker_Flapping(t *testing.T) {
+	tr := newHysteresisTracker(2, 2)
+	online := ServerInfo{Name: "Track #1", Category: "Track", NumPlayers: 2, Port: 8082}
+	failed := offlineServerInfo(Server{Name: "Track #1", Category: "Track", Port: 8082})
+
+	tr.apply([]ServerInfo{online})
+	for i := 0; i < 6; i++ {
+		poll := failed
+		if i%2 == 1 {
+			poll = online
+		}
+		if got := tr.apply([]ServerInfo{poll})[0]; serverDown(got) {
+			t.Fatalf("Poll %d: expected the flapping server to stay online, got %+v", i, got)
+		}
+	}
+}
+
+// TestHysteresisTracker_ConfigAndRemoved tests that held data follows the config and removed servers are forgotten
+func TestHysteresisTracker_ConfigAndRemoved(t *testing.T) {
+	tr := newHysteresisTracker(2, 1)
+	online := ServerInfo{Name: "Drift #1", Category: "Drift", IP: "10.0.0.1", NumPlayers: 5, Port: 8081}
+	failed := offlineServerInfo(Server{Name: "Drift #1", Category: "Track", IP: "10.0.0.2", Port: 8081})
+
+	tr.apply([]ServerInfo{online})
+	if got := tr.apply([]ServerInfo{failed})[0]; got.Category != "Track" || got.IP != "10.0.0.2" || got.NumPlayers != 5 {
+		t.Errorf("Expected held data with the current category and address, got %+v", got)
+	}
+
+	tr.apply(nil)
+	if len(tr.servers) != 0 {
+		t.Errorf("Expected removed server forgotten, got %v", tr.servers)
+	}
+	if got := tr.apply([]ServerInfo{failed})[0]; !serverDown(got) {
+		t.Errorf("Expected a new server's first poll shown as is, got %+v", got)
+	}
+}
+
+func TestHysteresisTrackerFromEnv(t *testing.T) {
+	t.Setenv("HYSTERESIS_OFFLINE_POLLS", "")
+	t.Setenv("HYSTERESIS_ONLINE_POLLS", "1")
+	if tr, err := hysteresisTrackerFromEnv(); tr != nil || err != nil {
+		t.Errorf("Unset: got %v, %v", tr, err)
+	}
+	t.Setenv("HYSTERESIS_OFFLINE_POLLS", "3")
+	if tr, err := hysteresisTrackerFromEnv(); err != nil || tr == nil || tr.offlineAfter != 3 || tr.onlineAfter != 1 {
+		t.Errorf("3: got %v, %v", tr, err)
+	}
+	t.Setenv("HYSTERESIS_ONLINE_POLLS", "0")
+	if _, err := hysteresisTrackerFromEnv(); err == nil {
+		t.Error("Expected 0 to be rejected")
+	}
+}
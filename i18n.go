@@ -0,0 +1,190 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= COMMAND LOCALIZATION =================
+
+// builtinLocales is the i18n bundle shipped with the bot: one <locale>.json per Discord locale
+//
+//go:embed i18n/*.json
+var builtinLocales embed.FS
+
+// commandNamePattern is what Discord accepts as a command or option name (localized names included)
+var commandNamePattern = regexp.MustCompile(`^[-_'\p{L}\p{N}\p{Devanagari}\p{Thai}]{1,32}$`)
+
+// maxCommandDescription is Discord's limit for command and option descriptions, in characters
+const maxCommandDescription = 100
+
+// commandText is the localized name and description of a command, subcommand or option (empty = English)
+type commandText struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// localeFile is one <locale>.json of the bundle
+// Commands are keyed by their path of English names ("maintenance", "maintenance.on", "maintenance.on.server");
+// options are texts for options by English name wherever no path entry exists ("server")
+type localeFile struct {
+	Commands map[string]commandText `json:"commands"`
+	Options  map[string]commandText `json:"options"`
+}
+
+// commandLocales holds the command texts of every locale in the bundle
+type commandLocales map[discordgo.Locale]localeFile
+
+// commandLocalesFromEnv loads the built-in bundle, then the <locale>.json files in I18N_DIR if set
+// Names and descriptions in I18N_DIR replace the built-in ones with the same key, so communities can fix or add translations
+func commandLocalesFromEnv() (commandLocales, error) {
+	locales := make(commandLocales)
+	if err := locales.load(builtinLocales, "i18n"); err != nil {
+		return nil, fmt.Errorf("built-in i18n bundle: %w", err)
+	}
+	if dir := os.Getenv("I18N_DIR"); dir != "" {
+		if err := locales.load(os.DirFS(dir), "."); err != nil {
+			return nil, fmt.Errorf("I18N_DIR %s: %w", dir, err)
+		}
+	}
+	if len(locales) > 0 {
+		log.Printf("Slash commands localized for %d locale(s): %s", len(locales), strings.Join(locales.names(), ", "))
+	}
+	return locales, nil
+}
+
+// load merges the <locale>.json files in dir of fsys into l; other files are ignored
+func (l commandLocales) load(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, filepath.ToSlash(filepath.Join(dir, "*.json")))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		locale := discordgo.Locale(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if _, ok := discordgo.Locales[locale]; !ok || locale == discordgo.Unknown {
+			return fmt.Errorf("%s: %q is not a Discord locale (e.g. de, fr, es-ES, pt-BR)", file, locale)
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		var f localeFile
+		if err := json.Unmarshal(data, &f); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := f.validate(); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		merged := l[locale]
+		if merged.Commands == nil {
+			merged = localeFile{Commands: make(map[string]commandText), Options: make(map[string]commandText)}
+		}
+		mergeTexts(merged.Commands, f.Commands)
+		mergeTexts(merged.Options, f.Options)
+		l[locale] = merged
+	}
+	return nil
+}
+
+// mergeTexts copies the names and descriptions set in src over those in dst
+func mergeTexts(dst, src map[string]commandText) {
+	for key, text := range src {
+		merged := dst[key]
+		if text.Name != "" {
+			merged.Name = text.Name
+		}
+		if text.Description != "" {
+			merged.Description = text.Description
+		}
+		dst[key] = merged
+	}
+}
+
+// validate checks names and descriptions against Discord's limits; names must be lowercase
+func (f localeFile) validate() error {
+	for _, section := range []map[string]commandText{f.Commands, f.Options} {
+		for _, key := range slices.Sorted(maps.Keys(section)) {
+			text := section[key]
+			if text.Name != "" && (!commandNamePattern.MatchString(text.Name) || strings.ToLower(text.Name) != text.Name) {
+				return fmt.Errorf("%s: name %q must be 1-32 lowercase letters, digits, - or _", key, text.Name)
+			}
+			if utf8.RuneCountInString(text.Description) > maxCommandDescription {
+				return fmt.Errorf("%s: description is longer than %d characters", key, maxCommandDescription)
+			}
+		}
+	}
+	return nil
+}
+
+// names returns the locales in the bundle, sorted
+func (l commandLocales) names() []string {
+	names := make([]string, 0, len(l))
+	for locale := range l {
+		names = append(names, string(locale))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// localize sets the localized names and descriptions of defs (no-op for an empty bundle)
+// Options are copied before they are changed, since commands share option definitions between subcommands.
+// Interactions keep carrying the English names, so handlers are unaffected
+func (l commandLocales) localize(defs []*discordgo.ApplicationCommand) {
+	if len(l) == 0 {
+		return
+	}
+	for _, def := range defs {
+		names, descriptions := l.texts(def.Name, def.Name)
+		if len(names) > 0 {
+			def.NameLocalizations = &names
+		}
+		if len(descriptions) > 0 {
+			def.DescriptionLocalizations = &descriptions
+		}
+		def.Options = l.localizeOptions(def.Name, def.Options)
+	}
+}
+
+// localizeOptions returns localized copies of the options under path
+func (l commandLocales) localizeOptions(path string, options []*discordgo.ApplicationCommandOption) []*discordgo.ApplicationCommandOption {
+	out := make([]*discordgo.ApplicationCommandOption, len(options))
+	for i, opt := range options {
+		c := *opt
+		optPath := path + "." + opt.Name
+		c.NameLocalizations, c.DescriptionLocalizations = l.texts(optPath, opt.Name)
+		c.Options = l.localizeOptions(optPath, opt.Options)
+		out[i] = &c
+	}
+	return out
+}
+
+// texts looks up path in every locale, falling back to the option texts of name for options
+func (l commandLocales) texts(path, name string) (names, descriptions map[discordgo.Locale]string) {
+	names = make(map[discordgo.Locale]string)
+	descriptions = make(map[discordgo.Locale]string)
+	for locale, f := range l {
+		text, ok := f.Commands[path]
+		if !ok && path != name {
+			text = f.Options[name]
+		}
+		if text.Name != "" {
+			names[locale] = text.Name
+		}
+		if text.Description != "" {
+			descriptions[locale] = text.Description
+		}
+	}
+	return names, descriptions
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// commandPaths returns every command, subcommand and option path ("maintenance.on.server") with its option name
+func commandPaths() map[string]string {
+	paths := make(map[string]string)
+	var walk func(path string, options []*discordgo.ApplicationCommandOption)
+	walk = func(path string, options []*discordgo.ApplicationCommandOption) {
+		for _, opt := range options {
+			paths[path+"."+opt.Name] = opt.Name
+			walk(path+"."+opt.Name, opt.Options)
+		}
+	}
+	for name, c := range slashCommands() {
+		paths[name] = name
+		walk(name, c.def.Options)
+	}
+	return paths
+}
+
+// TestBuiltinLocales tests that the shipped bundle loads, only names existing commands and options,
+// and translates the description of every command, subcommand and option
+func TestBuiltinLocales(t *testing.T) {
+	t.Setenv("I18N_DIR", "")
+	locales, err := commandLocalesFromEnv()
+	if err != nil {
+		t.Fatalf("commandLocalesFromEnv: %v", err)
+	}
+	if len(locales) == 0 {
+		t.Fatal("Expected built-in locales")
+	}
+	paths := commandPaths()
+	optionNames := make(map[string]bool)
+	for _, name := range paths {
+		optionNames[name] = true
+	}
+	for locale, f := range locales {
+		for key := range f.Commands {
+			if _, ok := paths[key]; !ok {
+				t.Errorf("%s: %q is not a command or option", locale, key)
+			}
+		}
+		for key := range f.Options {
+			if !optionNames[key] {
+				t.Errorf("%s: option %q does not exist", locale, key)
+			}
+		}
+		for path, name := range paths {
+			if _, descriptions := locales.texts(path, name); descriptions[locale] == "" {
+				t.Errorf("%s: %q has no description", locale, path)
+			}
+		}
+	}
+}
+
+// TestLocalizeCommands tests the localized definitions: shared options copied, English names kept,
+// no two siblings with the same localized name
+func TestLocalizeCommands(t *testing.T) {
+	locales := make(commandLocales)
+	if err := locales.load(builtinLocales, "i18n"); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	var defs []*discordgo.ApplicationCommand
+	for _, c := range slashCommands() {
+		defs = append(defs, c.def)
+	}
+	locales.localize(defs)
+
+	var checkSiblings func(path string, options []*discordgo.ApplicationCommandOption)
+	checkSiblings = func(path string, options []*discordgo.ApplicationCommandOption) {
+		for locale := range locales {
+			seen := make(map[string]bool)
+			for _, opt := range options {
+				name := opt.NameLocalizations[locale]
+				if name == "" {
+					name = opt.Name
+				}
+				if seen[name] {
+					t.Errorf("%s: %s has two options named %q", locale, path, name)
+				}
+				seen[name] = true
+			}
+		}
+		for _, opt := range options {
+			checkSiblings(path+"."+opt.Name, opt.Options)
+		}
+	}
+	for _, def := range defs {
+		checkSiblings(def.Name, def.Options)
+		if def.Name == "maintenance" {
+			if (*def.NameLocalizations)[discordgo.German] != "wartung" {
+				t.Errorf("Expected /maintenance localized, got %+v", def.NameLocalizations)
+			}
+			on, off := def.Options[0].Options[0], def.Options[1].Options[0]
+			if on == off || on.Name != "server" || on.NameLocalizations[discordgo.French] != "serveur" {
+				t.Errorf("Expected copied, localized server options, got %+v and %+v", on, off)
+			}
+		}
+	}
+}
+
+// TestCommandLocalesFromEnv tests I18N_DIR overrides and new locales, and refused files
+func TestCommandLocalesFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("de.json", `{"commands": {"maintenance": {"name": "wartungsmodus"}}}`)
+	write("nl.json", `{"commands": {"maintenance": {"name": "onderhoud", "description": "Markeer een server als in onderhoud"}}}`)
+	write("README.txt", "ignored")
+	t.Setenv("I18N_DIR", dir)
+
+	locales, err := commandLocalesFromEnv()
+	if err != nil {
+		t.Fatalf("commandLocalesFromEnv: %v", err)
+	}
+	names, descriptions := locales.texts("maintenance", "maintenance")
+	if names[discordgo.German] != "wartungsmodus" || names[discordgo.Dutch] != "onderhoud" {
+		t.Errorf("Expected the override and the new locale, got %v", names)
+	}
+	if !strings.HasPrefix(descriptions[discordgo.German], "Einen Spielserver") {
+		t.Errorf("Expected the built-in German description kept, got %q", descriptions[discordgo.German])
+	}
+
+	invalid := map[string]string{
+		"xx.json": `{}`,
+		"de.json": `{"commands": {"maintenance": {"name": "Wartung"}}}`,
+		"fr.json": `{"commands": {"maintenance": {"name": "mode maintenance"}}}`,
+		"it.json": `{"commands": {"maintenance": {"description": "` + strings.Repeat("a", 101) + `"}}}`,
+		"ja.json": `{`,
+	}
+	for name, content := range invalid {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("I18N_DIR", dir)
+		if _, err := commandLocalesFromEnv(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
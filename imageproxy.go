@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= EMBED IMAGE PROXY =================
+
+const (
+	// defaultImageProxyTTL is how long a fetched image is served before it is fetched again
+	defaultImageProxyTTL = time.Hour
+	// imageProxyTimeout bounds one fetch from the image source
+	imageProxyTimeout = 10 * time.Second
+	// maxProxiedImage is Discord's size limit for embed images
+	maxProxiedImage = 8 << 20
+)
+
+// Names of the proxied images, as in /api/v1/images/{name}
+const (
+	proxiedThumbnail = "thumbnail"
+	proxiedImage     = "image"
+)
+
+// cachedImage is a fetched image and where it came from
+type cachedImage struct {
+	src         string
+	data        []byte
+	contentType string
+	fetched     time.Time
+}
+
+// imageProxy serves the embed thumbnail and image from the bot's public API address, for sources Discord
+// cannot reach (the default logo at http://<server_ip>/images/logo.png on a LAN). The embed links to the proxy;
+// the proxy fetches the source on demand and caches it
+type imageProxy struct {
+	publicURL string // base URL Discord reaches the API at, without trailing slash
+	ttl       time.Duration
+	client    *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedImage // name -> image
+}
+
+// imageProxyFromEnv returns the proxy if IMAGE_PROXY is true, nil otherwise
+// IMAGE_PROXY_PUBLIC_URL (required) is the http(s) address of the API as seen from the internet;
+// IMAGE_PROXY_TTL is how long images are cached (default 1h)
+func imageProxyFromEnv() (*imageProxy, error) {
+	if os.Getenv("IMAGE_PROXY") != "true" {
+		return nil, nil
+	}
+	raw := strings.TrimSuffix(os.Getenv("IMAGE_PROXY_PUBLIC_URL"), "/")
+	u, err := url.Parse(raw)
+	if raw == "" || err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("IMAGE_PROXY requires IMAGE_PROXY_PUBLIC_URL, the http(s) address Discord reaches the API at (e.g. https://status.example.com)")
+	}
+	ttl := defaultImageProxyTTL
+	if v := os.Getenv("IMAGE_PROXY_TTL"); v != "" {
+		ttl, err = time.ParseDuration(v)
+		if err != nil || ttl < time.Minute || ttl > 24*time.Hour {
+			return nil, fmt.Errorf("invalid IMAGE_PROXY_TTL %q: must be a duration between 1m and 24h", v)
+		}
+	}
+	log.Printf("Image proxy enabled: embed images served from %s%s (cached for %v)", raw, api.ImagesPath, ttl)
+	return &imageProxy{
+		publicURL: raw,
+		ttl:       ttl,
+		client:    &http.Client{Timeout: imageProxyTimeout},
+		cache:     make(map[string]cachedImage),
+	}, nil
+}
+
+// imageSources returns the source URL of every proxied image of cfg; disabled images are left out
+func imageSources(cfg *Config) map[string]string {
+	sources := make(map[string]string, 2)
+	if cfg == nil {
+		return sources
+	}
+	if src := embedThumbnailURL(cfg); src != "" {
+		sources[proxiedThumbnail] = src
+	}
+	if src := embedLogoURL(cfg); src != "" {
+		sources[proxiedImage] = src
+	}
+	return sources
+}
+
+// proxiedURL is the public URL of image name; the version changes with the source, so Discord's own cache
+// does not keep showing an image that was replaced in the config
+func (p *imageProxy) proxiedURL(name, src string) string {
+	sum := sha256.Sum256([]byte(src))
+	return p.publicURL + api.ImagesPath + "/" + name + "?v=" + hex.EncodeToString(sum[:4])
+}
+
+// rewrite points the thumbnail and image of a status embed built from cfg at the proxy (no-op for nil p)
+// An attached banner stays as it is
+func (p *imageProxy) rewrite(embed *discordgo.MessageEmbed, cfg *Config) {
+	if p == nil {
+		return
+	}
+	sources := imageSources(cfg)
+	if embed.Thumbnail != nil && embed.Thumbnail.URL == sources[proxiedThumbnail] {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: p.proxiedURL(proxiedThumbnail, sources[proxiedThumbnail])}
+	}
+	if embed.Image != nil && embed.Image.URL == sources[proxiedImage] {
+		embed.Image = &discordgo.MessageEmbedImage{URL: p.proxiedURL(proxiedImage, sources[proxiedImage])}
+	}
+}
+
+// fetch returns image name from src, from the cache while it is fresh
+// When the source fails, an older copy of the same source is served rather than nothing
+func (p *imageProxy) fetch(ctx context.Context, name, src string, now time.Time) ([]byte, string, error) {
+	p.mu.Lock()
+	cached, ok := p.cache[name]
+	p.mu.Unlock()
+	if ok && cached.src == src && now.Sub(cached.fetched) < p.ttl {
+		return cached.data, cached.contentType, nil
+	}
+
+	data, contentType, err := p.download(ctx, src)
+	if err != nil {
+		if ok && cached.src == src {
+			log.Printf("Warning: image proxy: %v; serving the copy from %s", err, cached.fetched.UTC().Format(time.RFC3339))
+			return cached.data, cached.contentType, nil
+		}
+		return nil, "", err
+	}
+	p.mu.Lock()
+	p.cache[name] = cachedImage{src: src, data: data, contentType: contentType, fetched: now}
+	p.mu.Unlock()
+	return data, contentType, nil
+}
+
+// download fetches one image, refusing other content and images over Discord's size limit
+func (p *imageProxy) download(ctx context.Context, src string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetching %s: %w", src, err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetching %s: %w", src, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetching %s: HTTP %d", src, resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, "", fmt.Errorf("fetching %s: content type %q is not an image", src, contentType)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxProxiedImage+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("fetching %s: %w", src, err)
+	}
+	if len(data) > maxProxiedImage {
+		return nil, "", fmt.Errorf("fetching %s: larger than %d MiB", src, maxProxiedImage>>20)
+	}
+	return data, contentType, nil
+}
+
+// imageProxyProvider adapts the bot's image proxy to api.ImageProxy, resolving names against the current config
+type imageProxyProvider struct {
+	bot *Bot
+}
+
+// Image implements api.ImageProxy
+func (p *imageProxyProvider) Image(ctx context.Context, name string) ([]byte, string, error) {
+	src, ok := imageSources(p.bot.configManager.GetConfig())[name]
+	if !ok {
+		return nil, "", fmt.Errorf("%w '%s'", api.ErrUnknownImage, name)
+	}
+	return p.bot.images.fetch(ctx, name, src, time.Now())
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// testImageProxy returns a proxy publishing at https://status.example.com
+func testImageProxy() *imageProxy {
+	return &imageProxy{publicURL: "https://status.example.com", ttl: time.Hour, client: &http.Client{Timeout: time.Second}, cache: make(map[string]cachedImage)}
+}
+
+// TestImageProxyRewrite tests that the embed links the proxy, versioned by source, and leaves disabled images out
+func TestImageProxyRewrite(t *testing.T) {
+	p := testImageProxy()
+	cfg := testMaintenanceConfig()
+	embed := buildEmbed(nil, cfg)
+	p.rewrite(embed, cfg)
+	if !strings.HasPrefix(embed.Image.URL, "https://status.example.com/api/v1/images/image?v=") ||
+		!strings.HasPrefix(embed.Thumbnail.URL, "https://status.example.com/api/v1/images/thumbnail?v=") {
+		t.Fatalf("Expected proxied URLs, got %s and %s", embed.Image.URL, embed.Thumbnail.URL)
+	}
+
+	before := embed.Image.URL
+	cfg.EmbedImages = &EmbedImages{Thumbnail: embedImageNone, Image: "http://10.0.0.5/club.png"}
+	embed = buildEmbed(nil, cfg)
+	p.rewrite(embed, cfg)
+	if embed.Thumbnail != nil || embed.Image.URL == before || !strings.Contains(embed.Image.URL, "/images/image?v=") {
+		t.Errorf("Expected a new image version and no thumbnail, got %+v %+v", embed.Thumbnail, embed.Image)
+	}
+
+	embed = statusEmbed(nil, cfg, true)
+	p.rewrite(embed, cfg)
+	if !strings.HasPrefix(embed.Image.URL, "attachment://") {
+		t.Errorf("Expected the attached banner left alone, got %s", embed.Image.URL)
+	}
+}
+
+// TestImageProxyFetch tests caching, the stale copy when the source fails and refused content
+func TestImageProxyFetch(t *testing.T) {
+	var hits atomic.Int32
+	var down atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch {
+		case down.Load():
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.URL.Path == "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>"))
+		default:
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("\x89PNG"))
+		}
+	}))
+	defer srv.Close()
+
+	p := testImageProxy()
+	ctx := context.Background()
+	now := time.Now()
+	for range 2 {
+		data, contentType, err := p.fetch(ctx, proxiedImage, srv.URL+"/logo.png", now)
+		if err != nil || string(data) != "\x89PNG" || contentType != "image/png" {
+			t.Fatalf("Unexpected fetch %q %q %v", data, contentType, err)
+		}
+	}
+	if hits.Load() != 1 {
+		t.Errorf("Expected one fetch within the TTL, got %d", hits.Load())
+	}
+
+	down.Store(true)
+	if data, _, err := p.fetch(ctx, proxiedImage, srv.URL+"/logo.png", now.Add(2*time.Hour)); err != nil || string(data) != "\x89PNG" {
+		t.Errorf("Expected the stale copy while the source is down, got %q %v", data, err)
+	}
+	if _, _, err := p.fetch(ctx, proxiedImage, srv.URL+"/other.png", now); err == nil {
+		t.Error("Expected an error for a new source that is down")
+	}
+
+	down.Store(false)
+	if _, _, err := p.fetch(ctx, proxiedThumbnail, srv.URL+"/page.html", now); err == nil || !strings.Contains(err.Error(), "not an image") {
+		t.Errorf("Expected HTML refused, got %v", err)
+	}
+}
+
+// TestImageProxyProvider tests that names resolve against the current config
+func TestImageProxyProvider(t *testing.T) {
+	cfg := testMaintenanceConfig()
+	cfg.EmbedImages = &EmbedImages{Image: embedImageNone}
+	b := newTestBot(cfg)
+	b.images = testImageProxy()
+	if _, _, err := (&imageProxyProvider{bot: b}).Image(context.Background(), proxiedImage); !errors.Is(err, api.ErrUnknownImage) {
+		t.Errorf("Expected a disabled image to be unknown, got %v", err)
+	}
+}
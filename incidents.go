@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ================= INCIDENTS (PAGERDUTY / OPSGENIE) =================
+
+// Default API endpoints; OPSGENIE_API_URL selects the EU instance (https://api.eu.opsgenie.com)
+const (
+	defaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	defaultOpsgenieAPIURL     = "https://api.opsgenie.com"
+	// opsgenieMessageMax is Opsgenie's limit for the alert message
+	opsgenieMessageMax = 130
+)
+
+// Incident keys: one open incident per condition, resolved when it clears
+const (
+	incidentAllServersDown     = "absa-ac/all-servers-down"
+	incidentDiscordUnreachable = "absa-ac/discord-unreachable"
+)
+
+// incident is a critical condition reported to the on-call services
+type incident struct {
+	Key      string // dedup key (PagerDuty) / alias (Opsgenie)
+	Summary  string
+	Severity alertSeverity
+	Details  map[string]string
+}
+
+// incidentNotifier opens and resolves incidents in an on-call service
+type incidentNotifier interface {
+	Name() string
+	Trigger(inc incident) error
+	Resolve(key string) error
+}
+
+// incidentManager reports critical conditions to PagerDuty and/or Opsgenie
+// Each key is triggered once while open and resolved once when it clears
+type incidentManager struct {
+	notifiers []incidentNotifier
+
+	mu   sync.Mutex
+	open map[string]bool
+}
+
+// incidentsFromEnv returns a manager for PAGERDUTY_ROUTING_KEY and/or OPSGENIE_API_KEY, nil if neither is set
+// PAGERDUTY_EVENTS_URL and OPSGENIE_API_URL override the API endpoints (EU instance, proxies, tests)
+func incidentsFromEnv() (*incidentManager, error) {
+	var notifiers []incidentNotifier
+	client := &http.Client{Timeout: alertWebhookTimeout}
+	source := incidentSource()
+
+	if key := os.Getenv("PAGERDUTY_ROUTING_KEY"); key != "" {
+		endpoint, err := incidentURLFromEnv("PAGERDUTY_EVENTS_URL", defaultPagerDutyEventsURL)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, &pagerDutyNotifier{url: endpoint, routingKey: key, client: client, source: source})
+	}
+	if key := os.Getenv("OPSGENIE_API_KEY"); key != "" {
+		endpoint, err := incidentURLFromEnv("OPSGENIE_API_URL", defaultOpsgenieAPIURL)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, &opsgenieNotifier{apiURL: strings.TrimSuffix(endpoint, "/"), apiKey: key, client: client, source: source})
+	}
+	if len(notifiers) == 0 {
+		return nil, nil
+	}
+
+	m := newIncidentManager(notifiers...)
+	names := make([]string, len(notifiers))
+	for i, n := range notifiers {
+		names[i] = n.Name()
+	}
+	log.Printf("Incident reporting enabled: %s (all servers down, Discord unreachable)", strings.Join(names, ", "))
+	return m, nil
+}
+
+// incidentURLFromEnv reads an http(s) endpoint from name, def if unset
+func incidentURLFromEnv(name, def string) (string, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	if !validAlertWebhookURL(v) {
+		return "", fmt.Errorf("invalid %s %q: must be an http:// or https:// URL", name, v)
+	}
+	return v, nil
+}
+
+func newIncidentManager(notifiers ...incidentNotifier) *incidentManager {
+	return &incidentManager{notifiers: notifiers, open: make(map[string]bool)}
+}
+
+// incidentSource is the event source reported to the on-call services: the host (container) name
+func incidentSource() string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "absa-ac"
+}
+
+// trigger opens inc unless it is already open (no-op for nil)
+// If every notifier fails the incident stays closed, so the next report retries
+func (m *incidentManager) trigger(inc incident) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.open[inc.Key] {
+		return
+	}
+	delivered := false
+	for _, n := range m.notifiers {
+		if err := n.Trigger(inc); err != nil {
+			log.Printf("Error opening incident %s in %s: %v", inc.Key, n.Name(), err)
+			continue
+		}
+		delivered = true
+	}
+	if delivered {
+		m.open[inc.Key] = true
+		log.Printf("Incident opened: %s", inc.Summary)
+	}
+}
+
+// resolve closes the incident key if it is open (no-op for nil)
+func (m *incidentManager) resolve(key string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.open[key] {
+		return
+	}
+	for _, n := range m.notifiers {
+		if err := n.Resolve(key); err != nil {
+			log.Printf("Error resolving incident %s in %s: %v", key, n.Name(), err)
+		}
+	}
+	delete(m.open, key)
+	log.Printf("Incident resolved: %s", key)
+}
+
+// checkAllServersDown opens an incident when every polled server is down and resolves it otherwise
+// Servers in maintenance mode and parked cloud servers do not count; with all of them excluded the incident resolves
+// Called at the end of performUpdate, so the on-call APIs never delay the status update
+func (b *Bot) checkAllServersDown(infos []ServerInfo) {
+	if b.incidents == nil || !b.isLeader() || len(infos) == 0 {
+		return
+	}
+	down := 0
+	for _, info := range infos {
+		if info.Maintenance != nil || info.CloudPlanned {
+			continue
+		}
+		if !serverDown(info) {
+			b.incidents.resolve(incidentAllServersDown)
+			return
+		}
+		down++
+	}
+	if down == 0 {
+		b.incidents.resolve(incidentAllServersDown)
+		return
+	}
+	b.incidents.trigger(incident{
+		Key:      incidentAllServersDown,
+		Summary:  fmt.Sprintf("All %d game servers are down", down),
+		Severity: severityCritical,
+		Details:  map[string]string{"servers": fmt.Sprint(down)},
+	})
+}
+
+// discordUnreachable implements discordErrorHandler: opens the Discord incident
+func (b *Bot) discordUnreachable(err error) {
+	b.openDiscordIncident(err)
+}
+
+// openDiscordIncident reports that the status message cannot be updated (no-op without incident reporting)
+func (b *Bot) openDiscordIncident(err error) {
+	if b.incidents == nil || !b.isLeader() {
+		return
+	}
+	b.incidents.trigger(incident{
+		Key:      incidentDiscordUnreachable,
+		Summary:  "Status bot cannot reach Discord: " + err.Error(),
+		Severity: severityCritical,
+		Details:  map[string]string{"error": err.Error()},
+	})
+}
+
+// pagerDutyEvent is a PagerDuty Events API v2 event
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"` // trigger or resolve
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"` // required for trigger only
+}
+
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"` // info, warning, error or critical
+	Component     string         `json:"component,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+// pagerDutyNotifier implements incidentNotifier with the PagerDuty Events API v2
+type pagerDutyNotifier struct {
+	url        string
+	routingKey string
+	client     *http.Client
+	source     string
+}
+
+func (p *pagerDutyNotifier) Name() string { return "PagerDuty" }
+
+// Trigger implements incidentNotifier
+func (p *pagerDutyNotifier) Trigger(inc incident) error {
+	details := make(map[string]any, len(inc.Details))
+	for k, v := range inc.Details {
+		details[k] = v
+	}
+	return postAlertJSON(p.client, p.url, nil, pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "trigger",
+		DedupKey:    inc.Key,
+		Payload: &pagerDutyPayload{
+			Summary:       inc.Summary,
+			Source:        p.source,
+			Severity:      inc.Severity.String(),
+			Component:     "absa-ac",
+			CustomDetails: details,
+		},
+	})
+}
+
+// Resolve implements incidentNotifier
+func (p *pagerDutyNotifier) Resolve(key string) error {
+	return postAlertJSON(p.client, p.url, nil, pagerDutyEvent{RoutingKey: p.routingKey, EventAction: "resolve", DedupKey: key})
+}
+
+// opsgenieNotifier implements incidentNotifier with the Opsgenie Alert API
+type opsgenieNotifier struct {
+	apiURL string
+	apiKey string
+	client *http.Client
+	source string
+}
+
+func (o *opsgenieNotifier) Name() string { return "Opsgenie" }
+
+// opsgeniePriority maps a severity to an Opsgenie priority
+func opsgeniePriority(s alertSeverity) string {
+	switch s {
+	case severityCritical:
+		return "P1"
+	case severityError:
+		return "P2"
+	case severityWarning:
+		return "P3"
+	}
+	return "P5"
+}
+
+func (o *opsgenieNotifier) header() http.Header {
+	return http.Header{"Authorization": {"GenieKey " + o.apiKey}}
+}
+
+// Trigger implements incidentNotifier: creates an alert whose alias deduplicates repeats
+func (o *opsgenieNotifier) Trigger(inc incident) error {
+	message := inc.Summary
+	if r := []rune(message); len(r) > opsgenieMessageMax {
+		message = string(r[:opsgenieMessageMax-1]) + "…"
+	}
+	return postAlertJSON(o.client, o.apiURL+"/v2/alerts", o.header(), map[string]any{
+		"message":     message,
+		"alias":       inc.Key,
+		"description": inc.Summary,
+		"priority":    opsgeniePriority(inc.Severity),
+		"source":      o.source,
+		"entity":      "absa-ac",
+		"details":     inc.Details,
+	})
+}
+
+// Resolve implements incidentNotifier: closes the alert by alias
+func (o *opsgenieNotifier) Resolve(key string) error {
+	endpoint := o.apiURL + "/v2/alerts/" + url.PathEscape(key) + "/close?identifierType=alias"
+	return postAlertJSON(o.client, endpoint, o.header(), map[string]string{
+		"source": o.source,
+		"note":   "Resolved at " + time.Now().UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingNotifier records incident calls
+type recordingNotifier struct {
+	mu        sync.Mutex
+	err       error
+	triggered []incident
+	resolved  []string
+}
+
+func (n *recordingNotifier) Name() string { return "recording" }
+
+func (n *recordingNotifier) Trigger(inc incident) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	n.triggered = append(n.triggered, inc)
+	return nil
+}
+
+func (n *recordingNotifier) Resolve(key string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.resolved = append(n.resolved, key)
+	return nil
+}
+
+func (n *recordingNotifier) counts() (int, int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.triggered), len(n.resolved)
+}
+
+// TestIncidentManager tests that an incident is triggered once while open and resolved once
+func TestIncidentManager(t *testing.T) {
+	rec := &recordingNotifier{}
+	m := newIncidentManager(rec)
+	inc := incident{Key: incidentAllServersDown, Summary: "All 3 game servers are down", Severity: severityCritical}
+
+	m.resolve(inc.Key)
+	rec.err = errors.New("503")
+	m.trigger(inc)
+	rec.err = nil
+	m.trigger(inc)
+	m.trigger(inc)
+	if triggered, resolved := rec.counts(); triggered != 1 || resolved != 0 {
+		t.Fatalf("Expected one trigger after the failed one and no resolve, got %d/%d", triggered, resolved)
+	}
+	m.resolve(inc.Key)
+	m.resolve(inc.Key)
+	if _, resolved := rec.counts(); resolved != 1 {
+		t.Errorf("Expected one resolve, got %d", resolved)
+	}
+
+	var none *incidentManager
+	none.trigger(inc)
+	none.resolve(inc.Key)
+}
+
+// TestPagerDutyNotifier tests the Events API v2 payloads
+func TestPagerDutyNotifier(t *testing.T) {
+	var events []pagerDutyEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev pagerDutyEvent
+		json.NewDecoder(r.Body).Decode(&ev)
+		events = append(events, ev)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p := &pagerDutyNotifier{url: srv.URL, routingKey: "pd-key", client: srv.Client(), source: "host-1"}
+	inc := incident{Key: incidentDiscordUnreachable, Summary: "Status bot cannot reach Discord", Severity: severityCritical, Details: map[string]string{"error": "503"}}
+	if err := p.Trigger(inc); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Resolve(inc.Key); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %+v", events)
+	}
+	trigger, resolve := events[0], events[1]
+	if trigger.EventAction != "trigger" || trigger.RoutingKey != "pd-key" || trigger.DedupKey != incidentDiscordUnreachable ||
+		trigger.Payload.Source != "host-1" || trigger.Payload.Severity != "critical" || trigger.Payload.CustomDetails["error"] != "503" {
+		t.Errorf("Unexpected trigger %+v %+v", trigger, trigger.Payload)
+	}
+	if resolve.EventAction != "resolve" || resolve.DedupKey != incidentDiscordUnreachable || resolve.Payload != nil {
+		t.Errorf("Unexpected resolve %+v", resolve)
+	}
+}
+
+// TestOpsgenieNotifier tests alert creation by alias, closing by alias and the API key header
+func TestOpsgenieNotifier(t *testing.T) {
+	type request struct {
+		uri, auth string
+		body      map[string]any
+	}
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, request{r.URL.RequestURI(), r.Header.Get("Authorization"), body})
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	o := &opsgenieNotifier{apiURL: srv.URL, apiKey: "og-key", client: srv.Client(), source: "host-1"}
+	inc := incident{Key: incidentAllServersDown, Summary: strings.Repeat("x", 200), Severity: severityCritical}
+	if err := o.Trigger(inc); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Resolve(inc.Key); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %+v", requests)
+	}
+	create, closeReq := requests[0], requests[1]
+	if create.uri != "/v2/alerts" || create.auth != "GenieKey og-key" || create.body["alias"] != incidentAllServersDown || create.body["priority"] != "P1" {
+		t.Errorf("Unexpected create request %+v", create)
+	}
+	if msg, _ := create.body["message"].(string); len([]rune(msg)) != opsgenieMessageMax {
+		t.Errorf("Expected the message cut to %d characters, got %d", opsgenieMessageMax, len([]rune(msg)))
+	}
+	if closeReq.uri != "/v2/alerts/absa-ac%2Fall-servers-down/close?identifierType=alias" || closeReq.auth != "GenieKey og-key" {
+		t.Errorf("Unexpected close request %+v", closeReq)
+	}
+}
+
+// TestIncidentsFromEnv tests env parsing
+func TestIncidentsFromEnv(t *testing.T) {
+	t.Setenv("PAGERDUTY_ROUTING_KEY", "")
+	t.Setenv("OPSGENIE_API_KEY", "")
+	if m, err := incidentsFromEnv(); m != nil || err != nil {
+		t.Errorf("Expected disabled, got %v, %v", m, err)
+	}
+
+	t.Setenv("PAGERDUTY_ROUTING_KEY", "pd-key")
+	t.Setenv("PAGERDUTY_EVENTS_URL", "not a url")
+	if _, err := incidentsFromEnv(); err == nil {
+		t.Error("Expected error for an invalid PAGERDUTY_EVENTS_URL")
+	}
+	t.Setenv("PAGERDUTY_EVENTS_URL", "")
+	t.Setenv("OPSGENIE_API_KEY", "og-key")
+	t.Setenv("OPSGENIE_API_URL", "https://api.eu.opsgenie.com/")
+	m, err := incidentsFromEnv()
+	if err != nil || m == nil || len(m.notifiers) != 2 {
+		t.Fatalf("Expected both notifiers, got %v, %v", m, err)
+	}
+	if pd := m.notifiers[0].(*pagerDutyNotifier); pd.url != defaultPagerDutyEventsURL {
+		t.Errorf("Expected the default events URL, got %q", pd.url)
+	}
+	if og := m.notifiers[1].(*opsgenieNotifier); og.apiURL != "https://api.eu.opsgenie.com" {
+		t.Errorf("Expected the EU API URL without the trailing slash, got %q", og.apiURL)
+	}
+}
+
+// TestBotIncidents tests the all-servers-down incident and the Discord incident from the error handler
+func TestBotIncidents(t *testing.T) {
+	b := newTestBot(testStatusConfig())
+	b.fatal = make(chan error, 1)
+	rec := &recordingNotifier{}
+	b.incidents = newIncidentManager(rec)
+
+	up := ServerInfo{Name: "Track 1", Port: 8081, NumPlayers: 2}
+	down := ServerInfo{Name: "Track 2", Port: 8082, NumPlayers: -1}
+	b.checkAllServersDown([]ServerInfo{up, down})
+	b.checkAllServersDown(nil)
+	b.checkAllServersDown([]ServerInfo{down})
+	b.checkAllServersDown([]ServerInfo{down})
+	if n, _ := rec.counts(); n != 1 || rec.triggered[0].Key != incidentAllServersDown {
+		t.Fatalf("Expected one all-servers-down incident, got %+v", rec.triggered)
+	}
+	b.checkAllServersDown([]ServerInfo{up, down})
+	if _, n := rec.counts(); n != 1 {
+		t.Fatalf("Expected the incident resolved, got %v", rec.resolved)
+	}
+
+	b.discordUnreachable(errCircuitOpen)
+	b.discordFailed(discordErrPermission, errors.New("403"))
+	if n, _ := rec.counts(); n != 2 || rec.triggered[1].Key != incidentDiscordUnreachable {
+		t.Fatalf("Expected one Discord incident, got %+v", rec.triggered)
+	}
+	b.discordRecovered()
+	if _, n := rec.counts(); n != 2 || rec.resolved[1] != incidentDiscordUnreachable {
+		t.Errorf("Expected the Discord incident resolved, got %v", rec.resolved)
+	}
+}
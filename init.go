@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ================= INIT COMMAND =================
+
+// initConfigTemplate is the example config written by `init`
+// JSON has no comments, so notes live in "_comment" keys; the bot ignores them and
+// the API drops them on the first save
+const initConfigTemplate = `{
+  "_comment": "Starter config written by 'init'. Replace server_ip and the servers below, then start the bot. Every server uses server_ip; 'port' is the AC HTTP port (usually 8081+).",
+  "server_ip": "your.server.ip",
+  "_comment_update_interval": "Seconds between status updates (minimum 1, 30+ recommended).",
+  "update_interval": 30,
+  "_comment_categories": "category_order sets the section order in Discord; every category needs an emoji.",
+  "category_order": ["Drift", "Track"],
+  "category_emojis": {
+    "Drift": "🟣",
+    "Track": "🏁"
+  },
+  "_comment_servers": "Each server needs a unique name, a port and a category from category_order. Optional: query_type (default 'ac_http').",
+  "servers": [
+    {
+      "name": "Drift Server 1",
+      "port": 8081,
+      "category": "Drift"
+    },
+    {
+      "name": "Track Server 1",
+      "port": 8082,
+      "category": "Track"
+    }
+  ]
+}
+`
+
+// initEnvTemplate is the .env template written by `init`; %s is the generated API token
+const initEnvTemplate = `# Written by 'init'. Keep this file private (mode 0600), never commit it.
+
+# Discord configuration (required unless DISCORD_WEBHOOK_URL is set)
+DISCORD_TOKEN=your_bot_token_here
+CHANNEL_ID=your_channel_id
+
+# Webhook mode (alternative to DISCORD_TOKEN/CHANNEL_ID, for communities that can't add bots)
+# DISCORD_WEBHOOK_URL=https://discord.com/api/webhooks/<id>/<token>
+
+# API configuration (optional). The token below was generated for this deployment.
+# API_ENABLED=true
+# API_PORT=3001
+API_BEARER_TOKEN=%s
+# API_CORS_ORIGINS=https://example.com
+# API_TRUSTED_PROXY_IPS=
+
+# Proxy configuration (optional)
+# PROXY_ENABLED=true
+# PROXY_PORT=8080
+# PROXY_API_URL=http://localhost:3001
+# PROXY_USER=admin
+# PROXY_PASSWORD=your-secure-password
+
+# See .env.example for all optional features (status page, Slack, Matrix, banner, ...)
+`
+
+// generateAPIToken returns a random URL-safe token accepted by isStrongToken (64 chars)
+func generateAPIToken() (string, error) {
+	b := make([]byte, 48)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// writeNewFile writes data to path, refusing to replace an existing file unless force is set
+func writeNewFile(path string, data []byte, perm os.FileMode, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, perm)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists (use -force to overwrite)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	// OpenFile keeps the mode of an existing file; tighten it when overwriting
+	return os.Chmod(path, perm)
+}
+
+// runInit implements `bot init`: writes an example config and a .env template with a fresh API token
+// Both paths are checked before anything is written so a conflict leaves no partial result
+func runInit(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	fs.SetOutput(out)
+	configPath := fs.String("c", "", "Path to write config.json (default /data/config.json, same as the bot)")
+	fs.StringVar(configPath, "config", "", "Path to write config.json")
+	envPath := fs.String("env", ".env", "Path to write the .env template")
+	force := fs.Bool("force", false, "Overwrite existing files")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil // -h printed the usage
+		}
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	cfgPath := getConfigPath(*configPath)
+
+	if !*force {
+		for _, p := range []string{cfgPath, *envPath} {
+			if _, err := os.Stat(p); err == nil {
+				return fmt.Errorf("%s already exists (use -force to overwrite)", p)
+			}
+		}
+	}
+	if dir := filepath.Dir(cfgPath); dir != "." {
+		if _, err := os.Stat(dir); err != nil {
+			return fmt.Errorf("config directory %s is not available (use -c to choose another path): %w", dir, err)
+		}
+	}
+
+	token, err := generateAPIToken()
+	if err != nil {
+		return err
+	}
+
+	if err := writeNewFile(cfgPath, []byte(initConfigTemplate), 0644, *force); err != nil {
+		return err
+	}
+	if err := writeNewFile(*envPath, []byte(fmt.Sprintf(initEnvTemplate, token)), 0600, *force); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Wrote %s and %s (with a generated API_BEARER_TOKEN)\n", cfgPath, *envPath)
+	fmt.Fprintln(out, "Next: set server_ip and servers in the config, DISCORD_TOKEN and CHANNEL_ID in .env, then start the bot.")
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestRunInit tests that init writes a loadable config and a private .env with a strong token
+func TestRunInit(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.json")
+	envPath := filepath.Join(dir, ".env")
+
+	var out bytes.Buffer
+	if err := runInit([]string{"-c", cfgPath, "-env", envPath}, &out); err != nil {
+		t.Fatalf("runInit failed: %v", err)
+	}
+
+	cfg, err := loadConfig(cfgPath)
+	if err != nil || cfg == nil {
+		t.Fatalf("Generated config does not load: %v", err)
+	}
+	initializeServerIPs(cfg)
+	if err := validateConfigStructSafeRuntime(cfg); err != nil {
+		t.Errorf("Generated config is invalid: %v", err)
+	}
+
+	info, err := os.Stat(envPath)
+	if err != nil {
+		t.Fatalf("Expected .env to be written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 && runtime.GOOS != "windows" {
+		t.Errorf(".env mode = %o, want 600", perm)
+	}
+
+	data, _ := os.ReadFile(envPath)
+	var token string
+	for _, line := range strings.Split(string(data), "\n") {
+		if v, ok := strings.CutPrefix(line, "API_BEARER_TOKEN="); ok {
+			token = v
+		}
+	}
+	if !isStrongToken(token) {
+		t.Errorf("Generated token %q is not accepted by isStrongToken", token)
+	}
+	if strings.Contains(out.String(), token) {
+		t.Error("Token must not be printed")
+	}
+}
+
+// TestRunInit_ExistingFiles tests that init never overwrites without -force
+func TestRunInit_ExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.json")
+	envPath := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envPath, []byte("DISCORD_TOKEN=keep\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runInit([]string{"-c", cfgPath, "-env", envPath}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("Expected already exists error, got %v", err)
+	}
+	if _, err := os.Stat(cfgPath); !os.IsNotExist(err) {
+		t.Error("Config must not be written when .env conflicts")
+	}
+
+	if err := runInit([]string{"-c", cfgPath, "-env", envPath, "-force"}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("runInit -force failed: %v", err)
+	}
+	data, _ := os.ReadFile(envPath)
+	if strings.Contains(string(data), "DISCORD_TOKEN=keep") {
+		t.Error("Expected -force to overwrite .env")
+	}
+}
+
+// TestRunInit_MissingDirectory tests the hint when the default /data directory does not exist
+func TestRunInit_MissingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	err := runInit([]string{"-c", filepath.Join(dir, "missing", "config.json"), "-env", filepath.Join(dir, ".env")}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "use -c") {
+		t.Errorf("Expected missing directory error, got %v", err)
+	}
+}
+
+// TestGenerateAPIToken_Unique tests that tokens differ between calls
+func TestGenerateAPIToken_Unique(t *testing.T) {
+	a, err := generateAPIToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := generateAPIToken()
+	if a == b || len(a) != 64 {
+		t.Errorf("Unexpected tokens %q / %q", a, b)
+	}
+}
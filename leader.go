@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// ================= LEADER ELECTION =================
+
+// defaultLeaderRetryInterval is how often a standby tries to take over the leader lock
+const defaultLeaderRetryInterval = 5 * time.Second
+
+// LeaderLock is a mutual-exclusion lock shared by all replicas
+// TryAcquire never blocks and returns true while this instance holds the lock (repeat calls are fine)
+type LeaderLock interface {
+	// Name describes the lock in logs
+	Name() string
+	TryAcquire() (bool, error)
+	Release() error
+}
+
+// LeaderElection decides which replica publishes status updates
+// Every replica keeps polling so a standby has a warm snapshot; only the leader edits messages
+type LeaderElection struct {
+	lock  LeaderLock
+	retry time.Duration
+
+	leader atomic.Bool
+
+	// onElected runs once each time this instance becomes leader (nil = no callback)
+	onElected func()
+}
+
+// NewLeaderElection creates an election around lock; retry is how often a standby tries again
+func NewLeaderElection(lock LeaderLock, retry time.Duration) *LeaderElection {
+	return &LeaderElection{lock: lock, retry: retry}
+}
+
+// IsLeader reports whether this instance currently holds the lock
+func (e *LeaderElection) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// tryAcquire attempts to take the lock once, running onElected on a standby -> leader transition
+func (e *LeaderElection) tryAcquire() {
+	if e.leader.Load() {
+		return
+	}
+	ok, err := e.lock.TryAcquire()
+	if err != nil {
+		log.Printf("Leader election: failed to acquire %s: %v", e.lock.Name(), err)
+		return
+	}
+	if !ok {
+		return
+	}
+	e.leader.Store(true)
+	log.Printf("Leader election: acquired %s, this instance now publishes status updates", e.lock.Name())
+	if e.onElected != nil {
+		e.onElected()
+	}
+}
+
+// Run tries to become leader until ctx is cancelled, then releases the lock so a standby takes over
+func (e *LeaderElection) Run(ctx context.Context) error {
+	e.tryAcquire()
+	if !e.leader.Load() {
+		log.Printf("Leader election: %s is held by another instance, running as standby (polling only)", e.lock.Name())
+	}
+
+	ticker := time.NewTicker(e.retry)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if e.leader.Swap(false) {
+				if err := e.lock.Release(); err != nil {
+					log.Printf("Leader election: failed to release %s: %v", e.lock.Name(), err)
+				} else {
+					log.Printf("Leader election: released %s", e.lock.Name())
+				}
+			}
+			return nil
+		case <-ticker.C:
+			e.tryAcquire()
+		}
+	}
+}
+
+// fileLeaderLock is an advisory lock (flock) on a file on a volume shared by all replicas
+// The kernel drops the lock when the holder exits, so a crashed leader is replaced within one retry interval
+type fileLeaderLock struct {
+	path string
+	f    *os.File
+}
+
+// Name implements LeaderLock
+func (l *fileLeaderLock) Name() string {
+	return "lock file " + l.path
+}
+
+// TryAcquire implements LeaderLock
+func (l *fileLeaderLock) TryAcquire() (bool, error) {
+	if l.f != nil {
+		return true, nil
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, err
+	}
+	ok, err := tryLockFile(f)
+	if err != nil || !ok {
+		f.Close()
+		return false, err
+	}
+
+	// Record the holder for operators; the lock itself is the flock, not the content
+	host, _ := os.Hostname()
+	if err := f.Truncate(0); err == nil {
+		fmt.Fprintf(f, "%s pid %d since %s\n", host, os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	}
+	l.f = f
+	return true, nil
+}
+
+// Release implements LeaderLock (closing the file drops the flock)
+func (l *fileLeaderLock) Release() error {
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// leaderElectionFromEnv returns an election if LEADER_LOCK_FILE is set, nil otherwise (single instance, always leader)
+// LEADER_RETRY_INTERVAL (Go duration, default 5s) sets how quickly a standby takes over
+func leaderElectionFromEnv() (*LeaderElection, error) {
+	path := os.Getenv("LEADER_LOCK_FILE")
+	if path == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("LEADER_LOCK_FILE directory is not available: %w", err)
+	}
+
+	retry := defaultLeaderRetryInterval
+	if v := os.Getenv("LEADER_RETRY_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 100*time.Millisecond {
+			return nil, fmt.Errorf("invalid LEADER_RETRY_INTERVAL %q: use a duration of at least 100ms (e.g. 5s)", v)
+		}
+		retry = d
+	}
+
+	log.Printf("Leader election enabled: lock file %s (standby retry every %v)", path, retry)
+	return NewLeaderElection(&fileLeaderLock{path: path}, retry), nil
+}
+
+// isLeader reports whether this instance may publish (always true without leader election)
+func (b *Bot) isLeader() bool {
+	return b.leader == nil || b.leader.IsLeader()
+}
+
+// onElected takes over publishing: prepares the channel like at startup and posts right away
+// Bot mode deletes or adopts the previous leader's status message (same bot user) per CLEANUP_CHANNEL_IDS
+func (b *Bot) onElected() {
+	if b.discord != nil && b.discord.session.BotUser() != nil {
+		if err := b.discord.cleanupOldMessages(); err != nil {
+			log.Printf("Warning: cleanup after election failed: %v", err)
+		}
+	}
+	b.requestRefresh()
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// tryLockFile is unsupported without flock; LEADER_LOCK_FILE fails instead of electing two leaders
+func tryLockFile(f *os.File) (bool, error) {
+	return false, errors.New("file locks are not supported on this platform")
+}
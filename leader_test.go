@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeLeaderLock is a LeaderLock controlled by the test
+type fakeLeaderLock struct {
+	mu       sync.Mutex
+	free     bool
+	held     bool
+	released int
+}
+
+func (l *fakeLeaderLock) Name() string { return "fake lock" }
+
+func (l *fakeLeaderLock) TryAcquire() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.free {
+		l.held = true
+	}
+	return l.held, nil
+}
+
+func (l *fakeLeaderLock) Release() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held = false
+	l.released++
+	return nil
+}
+
+func (l *fakeLeaderLock) setFree(free bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.free = free
+}
+
+// TestFileLeaderLock tests that only one holder gets the lock and release hands it over
+func TestFileLeaderLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	a := &fileLeaderLock{path: path}
+	b := &fileLeaderLock{path: path}
+
+	if ok, err := a.TryAcquire(); !ok || err != nil {
+		t.Fatalf("First acquire: ok=%v err=%v", ok, err)
+	}
+	if ok, err := a.TryAcquire(); !ok || err != nil {
+		t.Errorf("Repeat acquire by holder: ok=%v err=%v", ok, err)
+	}
+	if ok, err := b.TryAcquire(); ok || err != nil {
+		t.Fatalf("Second instance acquired a held lock: ok=%v err=%v", ok, err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "pid") {
+		t.Errorf("Expected holder info in lock file, got %q", data)
+	}
+
+	if err := a.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if ok, err := b.TryAcquire(); !ok || err != nil {
+		t.Errorf("Expected takeover after release: ok=%v err=%v", ok, err)
+	}
+	b.Release()
+}
+
+// TestLeaderElection_Run tests standby, takeover with onElected, and release on shutdown
+func TestLeaderElection_Run(t *testing.T) {
+	lock := &fakeLeaderLock{}
+	e := NewLeaderElection(lock, 10*time.Millisecond)
+	var elected atomic.Int32
+	e.onElected = func() { elected.Add(1) }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	if e.IsLeader() {
+		t.Fatal("Expected standby while the lock is held elsewhere")
+	}
+
+	lock.setFree(true)
+	deadline := time.Now().Add(time.Second)
+	for !e.IsLeader() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !e.IsLeader() {
+		t.Fatal("Expected takeover once the lock is free")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if elected.Load() != 1 {
+		t.Errorf("onElected called %d times, want 1", elected.Load())
+	}
+
+	cancel()
+	<-done
+	if e.IsLeader() || lock.released != 1 {
+		t.Errorf("Expected lock released on shutdown (leader=%v, released=%d)", e.IsLeader(), lock.released)
+	}
+}
+
+// TestPublishStatus_StandbySkips tests that a standby polls but does not publish
+func TestPublishStatus_StandbySkips(t *testing.T) {
+	p := &fakePublisher{name: "p"}
+	b := newTestBot(nil)
+	b.publishers = []Publisher{p}
+	b.leader = NewLeaderElection(&fakeLeaderLock{}, time.Second)
+
+	b.publishStatus(&StatusUpdate{Snapshot: &StatusSnapshot{}})
+	b.SendAlert("down")
+	if len(p.updates) != 0 || len(p.alerts) != 0 {
+		t.Errorf("Standby published %d updates and %d alerts", len(p.updates), len(p.alerts))
+	}
+
+	b.leader.leader.Store(true)
+	b.publishStatus(&StatusUpdate{Snapshot: &StatusSnapshot{}})
+	if len(p.updates) != 1 {
+		t.Errorf("Leader published %d updates, want 1", len(p.updates))
+	}
+}
+
+// TestOnElected_RequestsRefresh tests that a new leader posts without waiting for the next tick
+func TestOnElected_RequestsRefresh(t *testing.T) {
+	b := newTestBot(nil)
+	b.refresh = make(chan struct{}, 1)
+
+	b.onElected()
+	if len(b.refresh) != 1 {
+		t.Error("Expected a refresh request after election")
+	}
+}
+
+// TestLeaderElectionFromEnv tests env parsing
+func TestLeaderElectionFromEnv(t *testing.T) {
+	t.Setenv("LEADER_LOCK_FILE", "")
+	if e, err := leaderElectionFromEnv(); e != nil || err != nil {
+		t.Errorf("Expected disabled election, got %v %v", e, err)
+	}
+
+	dir := t.TempDir()
+	t.Setenv("LEADER_LOCK_FILE", filepath.Join(dir, "leader.lock"))
+	t.Setenv("LEADER_RETRY_INTERVAL", "2s")
+	e, err := leaderElectionFromEnv()
+	if err != nil || e == nil || e.retry != 2*time.Second {
+		t.Fatalf("Unexpected election %+v, err %v", e, err)
+	}
+
+	t.Setenv("LEADER_RETRY_INTERVAL", "soon")
+	if _, err := leaderElectionFromEnv(); err == nil {
+		t.Error("Expected invalid LEADER_RETRY_INTERVAL to fail")
+	}
+
+	t.Setenv("LEADER_RETRY_INTERVAL", "")
+	t.Setenv("LEADER_LOCK_FILE", filepath.Join(dir, "missing", "leader.lock"))
+	if _, err := leaderElectionFromEnv(); err == nil {
+		t.Error("Expected missing directory to fail")
+	}
+}
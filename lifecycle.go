@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/supervisor"
+)
+
+// ================= LIFECYCLE MANAGER =================
+
+// Lifecycle owns every long-lived bot goroutine (update loop, API, proxy, watchdog)
+// Each component is started at most once under supervision and cancelled via a shared context,
+// so Discord reconnects (repeated Ready events) or config changes cannot spawn duplicates
+// Short tasks started by components (one status update) are tracked too, so Shutdown waits for them
+type Lifecycle struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[string]bool
+	tasks   map[string]int
+	stopped bool
+
+	// onPanic is passed to the supervisor of every component (crash reports)
+	onPanic func(name string, pe *supervisor.PanicError)
+	// onFatal is passed to the supervisor of every component (component cannot start)
+	onFatal func(name string, err error)
+}
+
+// NewLifecycle creates a lifecycle manager whose components are cancelled when parent is done or Shutdown is called
+func NewLifecycle(parent context.Context) *Lifecycle {
+	ctx, cancel := context.WithCancel(parent)
+	return &Lifecycle{
+		ctx:     ctx,
+		cancel:  cancel,
+		running: make(map[string]bool),
+		tasks:   make(map[string]int),
+	}
+}
+
+// Context returns the context shared by all managed components
+func (l *Lifecycle) Context() context.Context {
+	return l.ctx
+}
+
+// Go starts fn as a supervised component named name
+// Returns false without starting anything if a component with that name is already running
+// or the lifecycle has been shut down
+func (l *Lifecycle) Go(name string, fn func(context.Context) error) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stopped || l.running[name] {
+		return false
+	}
+	l.running[name] = true
+
+	l.wg.Add(1)
+	go func() {
+		defer func() {
+			l.mu.Lock()
+			delete(l.running, name)
+			l.mu.Unlock()
+			l.wg.Done()
+		}()
+		supervisor.Run(l.ctx, name, fn, supervisor.Options{OnPanic: l.onPanic, OnFatal: l.onFatal})
+	}()
+	return true
+}
+
+// Task runs fn once in a goroutine that Shutdown waits for, recovering a panic (no restart)
+// Returns false without running fn once the lifecycle has been shut down
+func (l *Lifecycle) Task(name string, fn func()) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stopped {
+		return false
+	}
+	l.tasks[name]++
+
+	l.wg.Add(1)
+	go func() {
+		defer func() {
+			l.mu.Lock()
+			if l.tasks[name]--; l.tasks[name] == 0 {
+				delete(l.tasks, name)
+			}
+			l.mu.Unlock()
+			l.wg.Done()
+		}()
+		defer supervisor.Recover(name, log.Default())
+		fn()
+	}()
+	return true
+}
+
+// SetPanicHandler calls fn when a component panics, before it is restarted
+// Must be called before the first Go
+func (l *Lifecycle) SetPanicHandler(fn func(name string, pe *supervisor.PanicError)) {
+	l.onPanic = fn
+}
+
+// SetFatalHandler calls fn when a component fails with a supervisor.Fatal error and is not restarted
+// Must be called before the first Go
+func (l *Lifecycle) SetFatalHandler(fn func(name string, err error)) {
+	l.onFatal = fn
+}
+
+// Running reports whether a component with the given name is currently running
+func (l *Lifecycle) Running(name string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.running[name]
+}
+
+// Shutdown cancels all components and waits for them and running tasks to exit, up to timeout
+// Safe to call multiple times; returns an error naming components that did not stop in time
+func (l *Lifecycle) Shutdown(timeout time.Duration) error {
+	l.mu.Lock()
+	l.stopped = true
+	l.mu.Unlock()
+
+	l.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		names := make([]string, 0, len(l.running)+len(l.tasks))
+		for name := range l.running {
+			names = append(names, name)
+		}
+		for name := range l.tasks {
+			names = append(names, name)
+		}
+		log.Printf("Warning: components still running after %v: %v", timeout, names)
+		return fmt.Errorf("%d component(s) did not stop within %v", len(names), timeout)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestLifecycle_GoStartsOnce tests that a named component cannot be started twice while running
+func TestLifecycle_GoStartsOnce(t *testing.T) {
+	l := NewLifecycle(context.Background())
+	defer l.Shutdown(time.Second)
+
+	var starts atomic.Int32
+	block := func(ctx context.Context) error {
+		starts.Add(1)
+		<-ctx.Done()
+		return nil
+	}
+
+	if !l.Go("loop", block) {
+		t.Fatal("Expected first start to succeed")
+	}
+	for i := 0; i < 5; i++ {
+		if l.Go("loop", block) {
+			t.Fatal("Expected duplicate start to be rejected")
+		}
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if got := starts.Load(); got != 1 {
+		t.Errorf("Expected 1 start, got %d", got)
+	}
+	if !l.Running("loop") {
+		t.Error("Expected component to be reported as running")
+	}
+}
+
+// TestLifecycle_RestartAfterExit tests that a component can be started again after it exits cleanly
+func TestLifecycle_RestartAfterExit(t *testing.T) {
+	l := NewLifecycle(context.Background())
+	defer l.Shutdown(time.Second)
+
+	done := make(chan struct{})
+	l.Go("oneshot", func(ctx context.Context) error {
+		close(done)
+		return nil
+	})
+	<-done
+
+	deadline := time.Now().Add(time.Second)
+	for l.Running("oneshot") {
+		if time.Now().After(deadline) {
+			t.Fatal("Component still reported running after exit")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if !l.Go("oneshot", func(ctx context.Context) error { return nil }) {
+		t.Error("Expected restart after clean exit to succeed")
+	}
+}
+
+// TestLifecycle_ShutdownCancelsAll tests deterministic cancellation of every component
+func TestLifecycle_ShutdownCancelsAll(t *testing.T) {
+	l := NewLifecycle(context.Background())
+
+	var stopped atomic.Int32
+	for _, name := range []string{"a", "b", "c"} {
+		l.Go(name, func(ctx context.Context) error {
+			<-ctx.Done()
+			stopped.Add(1)
+			return nil
+		})
+	}
+
+	if err := l.Shutdown(time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if got := stopped.Load(); got != 3 {
+		t.Errorf("Expected 3 components stopped, got %d", got)
+	}
+
+	// No new components after shutdown
+	if l.Go("late", func(ctx context.Context) error { return nil }) {
+		t.Error("Expected Go to be rejected after shutdown")
+	}
+
+	// Idempotent
+	if err := l.Shutdown(time.Second); err != nil {
+		t.Errorf("Second Shutdown failed: %v", err)
+	}
+}
+
+// TestLifecycle_ShutdownTimeout tests that a stuck component is reported instead of hanging shutdown
+func TestLifecycle_ShutdownTimeout(t *testing.T) {
+	l := NewLifecycle(context.Background())
+
+	release := make(chan struct{})
+	defer close(release)
+	l.Go("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	if err := l.Shutdown(20 * time.Millisecond); err == nil {
+		t.Error("Expected timeout error for stuck component")
+	}
+}
+
+// TestLifecycle_ShutdownWaitsForTasks tests that Shutdown waits for a running task and refuses new ones
+func TestLifecycle_ShutdownWaitsForTasks(t *testing.T) {
+	l := NewLifecycle(context.Background())
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	l.Task("status update", func() {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+	})
+	<-started
+
+	if err := l.Shutdown(time.Second); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Expected Shutdown to wait for the running task")
+	}
+	if l.Task("status update", func() { t.Error("Task ran after shutdown") }) {
+		t.Error("Expected a task to be refused after shutdown")
+	}
+}
+
+// TestLifecycle_TaskPanic tests that a panicking task does not crash the process or block shutdown
+func TestLifecycle_TaskPanic(t *testing.T) {
+	l := NewLifecycle(context.Background())
+	l.Task("status update", func() { panic("boom") })
+	if err := l.Shutdown(time.Second); err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// ================= CONFIG LINT =================
+
+const (
+	// lintMinUpdateInterval is the interval below which Discord edit rate limits become likely
+	lintMinUpdateInterval = 10
+	// lintMinPort flags privileged ports, which game server HTTP endpoints practically never use
+	lintMinPort = 1024
+	// lintProbeTimeout bounds the reachability probe of one server, so a config write is not held up by a dead one
+	lintProbeTimeout = 3 * time.Second
+)
+
+// serverProbe checks whether a server answers its poller (nil = reachable)
+type serverProbe func(ctx context.Context, cfg *Config, server Server) error
+
+// lintConfig reports non-fatal issues in a config that already passed validation
+// probe is optional; when set every server is polled once and unreachable ones are reported
+// Cancelling ctx (e.g. the client disconnected) cancels the probes
+func lintConfig(ctx context.Context, cfg *Config, probe serverProbe) []api.LintWarning {
+	if cfg == nil {
+		return nil
+	}
+	var warnings []api.LintWarning
+	warn := func(field, format string, args ...any) {
+		warnings = append(warnings, api.LintWarning{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if cfg.UpdateInterval < lintMinUpdateInterval {
+		warn("update_interval", "update_interval of %d seconds is very low; Discord may rate-limit message edits (%d or more recommended)",
+			cfg.UpdateInterval, lintMinUpdateInterval)
+	}
+
+	// Categories: shared emojis, emojis for categories that are never shown, categories without servers
+	inOrder := make(map[string]bool, len(cfg.CategoryOrder))
+	emojiOwner := make(map[string]string)
+	for _, cat := range cfg.CategoryOrder {
+		inOrder[cat] = true
+		emoji := cfg.CategoryEmojis[cat]
+		if owner, ok := emojiOwner[emoji]; ok {
+			warn("category_emojis."+cat, "category '%s' uses the same emoji as '%s'", cat, owner)
+		} else {
+			emojiOwner[emoji] = cat
+		}
+	}
+	var unused []string
+	for cat := range cfg.CategoryEmojis {
+		if !inOrder[cat] {
+			unused = append(unused, cat)
+		}
+	}
+	sort.Strings(unused)
+	for _, cat := range unused {
+		warn("category_emojis."+cat, "category '%s' has an emoji but is not in category_order, so it is never shown", cat)
+	}
+
+	used := make(map[string]bool)
+	for _, server := range cfg.Servers {
+		used[server.Category] = true
+	}
+	for _, cat := range cfg.CategoryOrder {
+		if !used[cat] {
+			warn("category_order", "category '%s' has no servers and will show as an empty section", cat)
+		}
+	}
+
+	// Servers: duplicate names or addresses, unusual ports
+	names := make(map[string]int)
+	addrs := make(map[string]int)
+	for i, server := range cfg.Servers {
+		field := fmt.Sprintf("servers[%d]", i)
+		if first, ok := names[server.Name]; ok {
+			warn(field+".name", "server name '%s' is also used by servers[%d]; PATCH merges servers by name", server.Name, first)
+		} else {
+			names[server.Name] = i
+		}
+		addr := fmt.Sprintf("%s:%d", server.IP, server.Port)
+		if first, ok := addrs[addr]; ok {
+			warn(field+".port", "server '%s' polls the same address %s as servers[%d]", server.Name, addr, first)
+		} else {
+			addrs[addr] = i
+		}
+		if server.Port < lintMinPort {
+			warn(field+".port", "server '%s' uses unusual port %d (below %d)", server.Name, server.Port, lintMinPort)
+		}
+	}
+
+	if probe != nil {
+		warnings = append(warnings, lintReachability(ctx, cfg, probe)...)
+	}
+	return warnings
+}
+
+// lintReachability probes all servers concurrently and reports those that do not answer
+func lintReachability(ctx context.Context, cfg *Config, probe serverProbe) []api.LintWarning {
+	errs := make([]error, len(cfg.Servers))
+	var wg sync.WaitGroup
+	for i, server := range cfg.Servers {
+		wg.Add(1)
+		go func(i int, server Server) {
+			defer wg.Done()
+			errs[i] = probe(ctx, cfg, server)
+		}(i, server)
+	}
+	wg.Wait()
+
+	var warnings []api.LintWarning
+	for i, err := range errs {
+		if err != nil {
+			warnings = append(warnings, api.LintWarning{
+				Field:   fmt.Sprintf("servers[%d]", i),
+				Message: fmt.Sprintf("server '%s' is not reachable right now: %v", cfg.Servers[i].Name, err),
+			})
+		}
+	}
+	return warnings
+}
+
+// pollProbe checks reachability with the server's registered poller and the shared polling client
+// A server that does not answer within lintProbeTimeout counts as unreachable
+func pollProbe(ctx context.Context, cfg *Config, server Server) error {
+	ctx, cancel := context.WithTimeout(ctx, lintProbeTimeout)
+	defer cancel()
+	_, _, err := probeServer(ctx, pollClients.Get(cfg.HTTPClient), server)
+	return err
+}
+
+// configLinter adapts ConfigManager to api.ConfigLinter
+type configLinter struct {
+	cm    *ConfigManager
+	probe serverProbe
+}
+
+// LintConfig lints the current config, probing every server until ctx is done
+func (l *configLinter) LintConfig(ctx context.Context) []api.LintWarning {
+	return lintConfig(ctx, l.cm.GetConfig(), l.probe)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// lintFields returns the field of every warning, in order
+func lintFields(warnings []api.LintWarning) []string {
+	fields := make([]string, len(warnings))
+	for i, w := range warnings {
+		fields[i] = w.Field
+	}
+	return fields
+}
+
+// TestLintConfig_Clean tests that a typical config produces no warnings
+func TestLintConfig_Clean(t *testing.T) {
+	cfg := testStatusConfig()
+	cfg.Servers = []Server{
+		{Name: "Drift 1", IP: "203.0.113.10", Port: 8081, Category: "Drift"},
+		{Name: "Track 1", IP: "203.0.113.10", Port: 8082, Category: "Track"},
+	}
+
+	if warnings := lintConfig(t.Context(), cfg, nil); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %+v", warnings)
+	}
+}
+
+// TestLintConfig_Warnings tests each non-fatal rule
+func TestLintConfig_Warnings(t *testing.T) {
+	cfg := &Config{
+		ServerIP:       "203.0.113.10",
+		UpdateInterval: 3,
+		CategoryOrder:  []string{"Drift", "Track", "GT3"},
+		CategoryEmojis: map[string]string{"Drift": "🟣", "Track": "🟣", "GT3": "🔵", "Old": "⚪"},
+		Servers: []Server{
+			{Name: "Drift 1", IP: "203.0.113.10", Port: 8081, Category: "Drift"},
+			{Name: "Drift 1", IP: "203.0.113.10", Port: 8082, Category: "Drift"},
+			{Name: "Track 1", IP: "203.0.113.10", Port: 8081, Category: "Track"},
+			{Name: "Track 2", IP: "203.0.113.10", Port: 80, Category: "Track"},
+		},
+	}
+
+	got := strings.Join(lintFields(lintConfig(t.Context(), cfg, nil)), ",")
+	want := strings.Join([]string{
+		"update_interval",
+		"category_emojis.Track", // same emoji as Drift
+		"category_emojis.Old",   // not in category_order
+		"category_order",        // GT3 has no servers
+		"servers[1].name",       // duplicate name
+		"servers[2].port",       // duplicate address
+		"servers[3].port",       // privileged port
+	}, ",")
+	if got != want {
+		t.Errorf("Warning fields:\n got  %s\n want %s", got, want)
+	}
+}
+
+// TestLintConfig_Reachability tests that probe failures are reported per server
+func TestLintConfig_Reachability(t *testing.T) {
+	cfg := testStatusConfig()
+	cfg.Servers = []Server{
+		{Name: "Drift 1", IP: "203.0.113.10", Port: 8081, Category: "Drift"},
+		{Name: "Track 1", IP: "203.0.113.10", Port: 8082, Category: "Track"},
+	}
+	probe := func(_ context.Context, _ *Config, s Server) error {
+		if s.Port == 8082 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	warnings := lintConfig(t.Context(), cfg, probe)
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %+v", warnings)
+	}
+	if warnings[0].Field != "servers[1]" || !strings.Contains(warnings[0].Message, "connection refused") {
+		t.Errorf("Unexpected warning: %+v", warnings[0])
+	}
+}
+
+// TestPollProbe tests the default probe against a live and a dead endpoint
+func TestPollProbe(t *testing.T) {
+	host, port, ts := newACInfoServer(t, `{"track":"spa","clients":1,"maxclients":10}`)
+
+	cfg := testStatusConfig()
+	if err := pollProbe(t.Context(), cfg, Server{Name: "Live", IP: host, Port: port}); err != nil {
+		t.Errorf("Expected live server to be reachable, got %v", err)
+	}
+
+	// A cancelled request (client gone) stops the probe
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := pollProbe(ctx, cfg, Server{Name: "Live", IP: host, Port: port}); err == nil {
+		t.Error("Expected a cancelled probe to fail")
+	}
+
+	ts.Close()
+	if err := pollProbe(t.Context(), cfg, Server{Name: "Dead", IP: host, Port: port}); err == nil {
+		t.Error("Expected closed server to be unreachable")
+	}
+	if err := pollProbe(t.Context(), cfg, Server{Name: "Odd", IP: host, Port: port, QueryType: "nope"}); err == nil {
+		t.Error("Expected unknown query_type to fail")
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bombom/absa-ac/pkg/listen"
+)
+
+// ================= LISTENERS =================
+
+const (
+	// defaultPublicRateLimit is the requests per second per client on the public listener
+	defaultPublicRateLimit = 5
+	// defaultPublicRateBurst is the burst per client on the public listener
+	defaultPublicRateBurst = 10
+)
+
+// listenersFromEnv opens the listeners of API_LISTEN, API_PUBLIC_LISTEN and PROXY_LISTEN and hands them to the servers
+// Each *_LISTEN overrides its *_PORT with a list of TCP addresses, unix:///path sockets and systemd:name
+// activated sockets (see pkg/listen). Unset keeps listening on all interfaces on the port
+// API_PUBLIC_LISTEN adds a second API listener serving only the read-only public endpoints
+func (b *Bot) listenersFromEnv() error {
+	if spec := os.Getenv("API_LISTEN"); spec != "" {
+		if b.apiServer == nil {
+			return fmt.Errorf("API_LISTEN is set but the API is disabled (set API_ENABLED=true)")
+		}
+		listeners, err := openListeners("API_LISTEN", spec)
+		if err != nil {
+			return err
+		}
+		b.apiServer.SetListeners(listeners)
+	}
+	if spec := os.Getenv("API_PUBLIC_LISTEN"); spec != "" {
+		if b.apiServer == nil {
+			return fmt.Errorf("API_PUBLIC_LISTEN is set but the API is disabled (set API_ENABLED=true)")
+		}
+		public, err := publicListenerFromEnv()
+		if err != nil {
+			return err
+		}
+		if public.Listeners, err = openListeners("API_PUBLIC_LISTEN", spec); err != nil {
+			return err
+		}
+		b.apiServer.SetPublicListener(public)
+	}
+	if spec := os.Getenv("PROXY_LISTEN"); spec != "" {
+		if b.proxyServer == nil {
+			return fmt.Errorf("PROXY_LISTEN is set but the proxy is disabled (set PROXY_ENABLED=true)")
+		}
+		listeners, err := openListeners("PROXY_LISTEN", spec)
+		if err != nil {
+			return err
+		}
+		b.proxyServer.SetListeners(listeners)
+	}
+	return nil
+}
+
+// openListeners opens the listeners of one *_LISTEN variable
+func openListeners(name, spec string) ([]net.Listener, error) {
+	listeners, err := listen.Open(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	log.Printf("%s: %s", name, listen.Describe(listeners))
+	return listeners, nil
+}
+
+// publicListenerFromEnv reads the CORS origins and rate limit of the public listener (API_PUBLIC_LISTEN)
+// API_PUBLIC_CORS_ORIGINS defaults to any origin; API_PUBLIC_RATE_LIMIT and API_PUBLIC_RATE_BURST to 5/s, burst 10
+func publicListenerFromEnv() (api.PublicListener, error) {
+	p := api.PublicListener{RequestsPerSecond: defaultPublicRateLimit, Burst: defaultPublicRateBurst}
+	if v := os.Getenv("API_PUBLIC_CORS_ORIGINS"); v != "" {
+		for _, o := range strings.Split(v, ",") {
+			if o = strings.TrimSpace(o); o != "" {
+				p.CORSOrigins = append(p.CORSOrigins, o)
+			}
+		}
+		if len(p.CORSOrigins) > 1 && slices.Contains(p.CORSOrigins, "*") {
+			return p, fmt.Errorf("invalid API_PUBLIC_CORS_ORIGINS: wildcard '*' cannot be combined with specific origins")
+		}
+	}
+	for _, setting := range []struct {
+		name string
+		dst  *int
+	}{{"API_PUBLIC_RATE_LIMIT", &p.RequestsPerSecond}, {"API_PUBLIC_RATE_BURST", &p.Burst}} {
+		v := os.Getenv(setting.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000 {
+			return p, fmt.Errorf("invalid %s %q: must be between 1 and 1000", setting.name, v)
+		}
+		*setting.dst = n
+	}
+	return p, nil
+}
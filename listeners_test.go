@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bombom/absa-ac/pkg/proxy"
+)
+
+// TestListenersFromEnv_RequiresServer tests that *_LISTEN is rejected when its server is disabled
+func TestListenersFromEnv_RequiresServer(t *testing.T) {
+	b := newTestBot(nil)
+	if err := b.listenersFromEnv(); err != nil {
+		t.Errorf("Expected no error without *_LISTEN, got %v", err)
+	}
+	t.Setenv("API_LISTEN", "127.0.0.1:0")
+	if err := b.listenersFromEnv(); err == nil {
+		t.Error("Expected error for API_LISTEN with the API disabled")
+	}
+	t.Setenv("API_LISTEN", "")
+	t.Setenv("API_PUBLIC_LISTEN", "127.0.0.1:0")
+	if err := b.listenersFromEnv(); err == nil {
+		t.Error("Expected error for API_PUBLIC_LISTEN with the API disabled")
+	}
+	t.Setenv("API_PUBLIC_LISTEN", "")
+	t.Setenv("PROXY_LISTEN", "127.0.0.1:0")
+	if err := b.listenersFromEnv(); err == nil {
+		t.Error("Expected error for PROXY_LISTEN with the proxy disabled")
+	}
+}
+
+// TestPublicListenerFromEnv tests the defaults, CORS origins and rate limits of the public listener
+func TestPublicListenerFromEnv(t *testing.T) {
+	p, err := publicListenerFromEnv()
+	if err != nil || p.RequestsPerSecond != defaultPublicRateLimit || p.Burst != defaultPublicRateBurst || p.CORSOrigins != nil {
+		t.Fatalf("Unexpected defaults %+v (%v)", p, err)
+	}
+	t.Setenv("API_PUBLIC_CORS_ORIGINS", "https://a.example.com, https://b.example.com")
+	t.Setenv("API_PUBLIC_RATE_LIMIT", "2")
+	t.Setenv("API_PUBLIC_RATE_BURST", "4")
+	p, err = publicListenerFromEnv()
+	if err != nil || len(p.CORSOrigins) != 2 || p.CORSOrigins[1] != "https://b.example.com" || p.RequestsPerSecond != 2 || p.Burst != 4 {
+		t.Errorf("Unexpected settings %+v (%v)", p, err)
+	}
+
+	for name, value := range map[string]string{
+		"API_PUBLIC_CORS_ORIGINS": "*, https://a.example.com",
+		"API_PUBLIC_RATE_LIMIT":   "0",
+		"API_PUBLIC_RATE_BURST":   "many",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := publicListenerFromEnv(); err == nil || !strings.Contains(err.Error(), name) {
+				t.Errorf("Expected error for %s=%q, got %v", name, value, err)
+			}
+		})
+	}
+}
+
+// TestListenersFromEnv_UnixSocketProxy tests the API served on a Unix socket and the proxy reaching it
+// through PROXY_API_URL=unix://
+func TestListenersFromEnv_UnixSocketProxy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix sockets are not used on Windows hosts")
+	}
+	sock := filepath.Join(t.TempDir(), "api.sock")
+	logger := log.New(io.Discard, "", 0)
+
+	b := newTestBot(testStatusConfig())
+	b.apiServer = api.NewServer(b.configManager, "0", "api-token", nil, nil, logger)
+	b.proxyServer = proxy.NewServer(proxy.Config{
+		Port: "0", APIURL: "unix://" + sock, Username: "admin", Password: "secret", BearerToken: "api-token",
+	}, logger)
+	t.Setenv("API_LISTEN", "unix://"+sock)
+	if err := b.listenersFromEnv(); err != nil {
+		t.Fatalf("listenersFromEnv failed: %v", err)
+	}
+
+	// The proxy listens on an ephemeral loopback port the test needs to know
+	proxyListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.proxyServer.SetListeners([]net.Listener{proxyListener})
+
+	ctx := context.Background()
+	go b.apiServer.Start(ctx)
+	go b.proxyServer.Start(ctx)
+	t.Cleanup(func() {
+		b.proxyServer.Stop()
+		b.apiServer.Stop()
+	})
+
+	req, _ := http.NewRequest("GET", "http://"+proxyListener.Addr().String()+"/api/config", nil)
+	req.SetBasicAuth("admin", "secret")
+	var resp *http.Response
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if resp, err = http.DefaultClient.Do(req); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("Request through the proxy failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("Expected 200 through the proxy, got %d (body: %s)", resp.StatusCode, body)
+	}
+}
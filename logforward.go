@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bombom/absa-ac/api"
+	"golang.org/x/time/rate"
+)
+
+// ================= LOG FORWARDING =================
+
+// Defaults for LOG_FORWARD_RATE (messages per minute) and LOG_FORWARD_DEDUPE_WINDOW
+const (
+	defaultLogForwardRate   = 6
+	defaultLogForwardWindow = 10 * time.Minute
+	// logForwardMaxLen keeps a forwarded line well inside Discord's 2000 character message limit
+	logForwardMaxLen = 1800
+	// logForwardPrefix starts the forwarder's own log lines, which are never forwarded (no feedback loop)
+	logForwardPrefix = "Log forwarding:"
+)
+
+// logLevel is the severity of a log line, derived from its wording (the log package has no levels)
+type logLevel int
+
+const (
+	levelInfo logLevel = iota
+	levelWarn
+	levelError
+)
+
+func (l logLevel) String() string {
+	switch l {
+	case levelWarn:
+		return "WARN"
+	case levelError:
+		return "ERROR"
+	}
+	return "INFO"
+}
+
+// logPrefixPattern matches the date, time and optional file:line prefix written by the log package
+var logPrefixPattern = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(\.\d+)? (\S+\.go:\d+: )?`)
+
+// digitsPattern matches numbers, which vary between otherwise identical lines (counts, durations, IDs)
+var digitsPattern = regexp.MustCompile(`\d+`)
+
+// logMessage returns line without the log package prefix
+func logMessage(line string) string {
+	return logPrefixPattern.ReplaceAllString(line, "")
+}
+
+// classifyLogLine returns the level of a log line
+// Warnings start with "Warning", "WARNING", "[WARNING]" or "WARN"; errors start with "Error", "ERROR", "FATAL",
+// "Failed" or "panic", or report that something failed ("Config reload check failed: ...")
+func classifyLogLine(line string) logLevel {
+	msg := logMessage(line)
+	for _, p := range []string{"Warning", "WARNING", "[WARNING]", "WARN"} {
+		if strings.HasPrefix(msg, p) {
+			return levelWarn
+		}
+	}
+	for _, p := range []string{"Error", "ERROR", "FATAL", "Failed", "panic"} {
+		if strings.HasPrefix(msg, p) {
+			return levelError
+		}
+	}
+	if strings.Contains(msg, " failed: ") || strings.Contains(msg, " panicked: ") {
+		return levelError
+	}
+	return levelInfo
+}
+
+// logForwarder posts WARN/ERROR log lines to a Discord admin channel
+// Repeats of a line (numbers ignored) within the dedupe window are counted instead of posted, and a token
+// bucket bounds the message rate; both counts are reported with the next message
+type logForwarder struct {
+	logs     *logRing
+	send     func(msg string) error
+	minLevel logLevel
+	limiter  *rate.Limiter
+	window   time.Duration
+
+	// seen maps a normalized line to its dedupe state; only touched by the Run goroutine
+	seen map[string]*forwardedLine
+	// rateLimited counts lines dropped by the limiter since the last posted message
+	rateLimited int
+}
+
+// forwardedLine is the dedupe state of one normalized line
+type forwardedLine struct {
+	posted     time.Time
+	suppressed int
+	last       string
+}
+
+// logForwarderFromEnv returns a forwarder posting to LOG_FORWARD_CHANNEL_ID, nil if unset
+// Lines come from the log buffer (already redacted) and are sent through the bot session, so the buffer and
+// bot mode are required. LOG_FORWARD_LEVEL (warn or error), LOG_FORWARD_RATE (messages per minute) and
+// LOG_FORWARD_DEDUPE_WINDOW tune what is posted. Each replica forwards its own log
+func logForwarderFromEnv(b *Bot, logs *logRing) (*logForwarder, error) {
+	channelID := os.Getenv("LOG_FORWARD_CHANNEL_ID")
+	if channelID == "" {
+		return nil, nil
+	}
+	if logs == nil {
+		return nil, fmt.Errorf("LOG_FORWARD_CHANNEL_ID requires the log buffer (LOG_BUFFER_LINES > 0)")
+	}
+	if b.discord == nil {
+		return nil, fmt.Errorf("LOG_FORWARD_CHANNEL_ID requires bot mode (DISCORD_TOKEN), not a webhook")
+	}
+
+	minLevel := levelWarn
+	switch v := strings.ToLower(os.Getenv("LOG_FORWARD_LEVEL")); v {
+	case "", "warn", "warning":
+	case "error":
+		minLevel = levelError
+	default:
+		return nil, fmt.Errorf("invalid LOG_FORWARD_LEVEL %q: must be warn or error", v)
+	}
+	perMinute := defaultLogForwardRate
+	if v := os.Getenv("LOG_FORWARD_RATE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid LOG_FORWARD_RATE %q: must be at least 1 message per minute", v)
+		}
+		perMinute = n
+	}
+	window := defaultLogForwardWindow
+	if v := os.Getenv("LOG_FORWARD_DEDUPE_WINDOW"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid LOG_FORWARD_DEDUPE_WINDOW %q: must be a duration like 10m (0 disables deduplication)", v)
+		}
+		window = d
+	}
+
+	f := newLogForwarder(logs, func(msg string) error {
+		_, err := b.discord.session.ChannelMessageSend(channelID, msg)
+		return err
+	}, minLevel, perMinute, window)
+	log.Printf("Log forwarding enabled: %s and above to channel %s (at most %d per minute, repeats grouped for %v)",
+		minLevel, channelID, perMinute, window)
+	return f, nil
+}
+
+func newLogForwarder(logs *logRing, send func(string) error, minLevel logLevel, perMinute int, window time.Duration) *logForwarder {
+	return &logForwarder{
+		logs:     logs,
+		send:     send,
+		minLevel: minLevel,
+		limiter:  rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), perMinute),
+		window:   window,
+		seen:     make(map[string]*forwardedLine),
+	}
+}
+
+// Run forwards new log lines until ctx is done
+func (f *logForwarder) Run(ctx context.Context) error {
+	lines, unsubscribe := f.logs.Subscribe()
+	defer unsubscribe()
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case l := <-lines:
+			f.handle(l, time.Now())
+		case now := <-ticker.C:
+			f.flush(now)
+		}
+	}
+}
+
+// handle posts l if its level is high enough, it is not a repeat within the window and the rate allows it
+func (f *logForwarder) handle(l api.LogLine, now time.Time) {
+	level := classifyLogLine(l.Line)
+	msg := logMessage(l.Line)
+	if level < f.minLevel || strings.HasPrefix(msg, logForwardPrefix) {
+		return
+	}
+
+	key := digitsPattern.ReplaceAllString(msg, "#")
+	var repeats int
+	if seen := f.seen[key]; seen != nil && f.window > 0 {
+		if now.Sub(seen.posted) < f.window {
+			seen.suppressed++
+			seen.last = l.Line
+			return
+		}
+		repeats = seen.suppressed
+	}
+	if !f.post(formatForwardedLine(level, l.Line, repeats, f.window), now) {
+		return
+	}
+	f.seen[key] = &forwardedLine{posted: now}
+}
+
+// flush reports repeats of lines whose window has ended without a new occurrence and forgets those lines
+func (f *logForwarder) flush(now time.Time) {
+	for key, seen := range f.seen {
+		if now.Sub(seen.posted) < f.window {
+			continue
+		}
+		if seen.suppressed > 0 {
+			text := fmt.Sprintf("↻ Repeated %d more times in %v, last:\n```\n%s\n```", seen.suppressed, f.window, truncateLogLine(seen.last))
+			if !f.post(text, now) {
+				continue // retried on the next tick
+			}
+		}
+		delete(f.seen, key)
+	}
+}
+
+// post sends text if the rate allows it, reporting lines dropped by the rate limit since the last message
+func (f *logForwarder) post(text string, now time.Time) bool {
+	if !f.limiter.AllowN(now, 1) {
+		f.rateLimited++
+		return false
+	}
+	if f.rateLimited > 0 {
+		text += fmt.Sprintf("\n(%d more log lines were not forwarded: rate limit)", f.rateLimited)
+	}
+	if err := f.send(text); err != nil {
+		log.Printf("%s failed to post to Discord: %v", logForwardPrefix, err)
+		return false
+	}
+	f.rateLimited = 0
+	return true
+}
+
+// formatForwardedLine renders one forwarded line, noting how often it repeated in the previous window
+func formatForwardedLine(level logLevel, line string, repeats int, window time.Duration) string {
+	icon := "⚠️"
+	if level == levelError {
+		icon = "🛑"
+	}
+	text := fmt.Sprintf("%s **%s**\n```\n%s\n```", icon, level, truncateLogLine(line))
+	if repeats > 0 {
+		text += fmt.Sprintf("(repeated %d more times in the previous %v)", repeats, window)
+	}
+	return text
+}
+
+// truncateLogLine shortens line to logForwardMaxLen and keeps it from closing the code block
+func truncateLogLine(line string) string {
+	line = strings.ReplaceAll(line, "```", "`\u200b``")
+	if len(line) > logForwardMaxLen {
+		cut := logForwardMaxLen
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		line = line[:cut] + "…"
+	}
+	return line
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// TestClassifyLogLine tests level detection from the wording of real log lines
+func TestClassifyLogLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want logLevel
+	}{
+		{"2026/01/02 03:04:05 main.go:394: Config reloaded successfully", levelInfo},
+		{"2026/01/02 03:04:05 main.go:1362: Config reload check failed: invalid JSON", levelError},
+		{"2026/01/02 03:04:05 retry.go:88: Error updating status (discord, background retry): 503", levelError},
+		{"2026/01/02 03:04:05 Failed to delete message 123: 404", levelError},
+		{"2026/01/02 03:04:05 main.go:12: Warning: watchdog disabled: bad WATCHDOG_USEC", levelWarn},
+		{"2026/01/02 03:04:05 main.go:12: [WARNING] ALLOW_CORS_ANY=true", levelWarn},
+		{"2026/01/02 03:04:05 main.go:12: Warning: failed to delete old backup", levelWarn},
+		{"2026/01/02 03:04:05 main.go:12: update loop panicked: nil map", levelError},
+		{"Error without a log prefix", levelError},
+	}
+	for _, tt := range tests {
+		if got := classifyLogLine(tt.line); got != tt.want {
+			t.Errorf("classifyLogLine(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+// recordingSend collects forwarded messages
+type recordingSend struct {
+	msgs []string
+	err  error
+}
+
+func (r *recordingSend) send(msg string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.msgs = append(r.msgs, msg)
+	return nil
+}
+
+// TestLogForwarder_Dedupe tests that repeats within the window are counted and reported afterwards
+func TestLogForwarder_Dedupe(t *testing.T) {
+	rec := &recordingSend{}
+	f := newLogForwarder(nil, rec.send, levelWarn, 100, 10*time.Minute)
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	f.handle(api.LogLine{Line: "2026/01/02 03:00:00 Config reloaded successfully"}, now)
+	f.handle(api.LogLine{Line: "2026/01/02 03:00:00 Config reload check failed: line 3"}, now)
+	f.handle(api.LogLine{Line: "2026/01/02 03:01:00 Config reload check failed: line 4"}, now.Add(time.Minute))
+	f.handle(api.LogLine{Line: "2026/01/02 03:02:00 Config reload check failed: line 5"}, now.Add(2*time.Minute))
+	if len(rec.msgs) != 1 || !strings.Contains(rec.msgs[0], "**ERROR**") || !strings.Contains(rec.msgs[0], "line 3") {
+		t.Fatalf("Expected only the first failure posted, got %q", rec.msgs)
+	}
+
+	// Repeats are reported with the next occurrence after the window
+	f.handle(api.LogLine{Line: "2026/01/02 03:11:00 Config reload check failed: line 6"}, now.Add(11*time.Minute))
+	if len(rec.msgs) != 2 || !strings.Contains(rec.msgs[1], "line 6") || !strings.Contains(rec.msgs[1], "repeated 2 more times") {
+		t.Fatalf("Expected the next occurrence with the repeat count, got %q", rec.msgs)
+	}
+
+	// ... or by the periodic flush when the line stops
+	f.handle(api.LogLine{Line: "2026/01/02 03:12:00 Config reload check failed: line 7"}, now.Add(12*time.Minute))
+	f.flush(now.Add(15 * time.Minute))
+	if len(rec.msgs) != 2 {
+		t.Fatalf("Expected no flush within the window, got %q", rec.msgs)
+	}
+	f.flush(now.Add(22 * time.Minute))
+	if len(rec.msgs) != 3 || !strings.Contains(rec.msgs[2], "Repeated 1 more times") || !strings.Contains(rec.msgs[2], "line 7") {
+		t.Fatalf("Expected a repeat summary, got %q", rec.msgs)
+	}
+	if len(f.seen) != 0 {
+		t.Errorf("Expected flushed lines forgotten, got %v", f.seen)
+	}
+}
+
+// TestLogForwarder_RateLimit tests that the rate limit drops lines and reports the count with the next message
+func TestLogForwarder_RateLimit(t *testing.T) {
+	rec := &recordingSend{}
+	f := newLogForwarder(nil, rec.send, levelWarn, 2, 0)
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	for i, msg := range []string{"Error a", "Error b", "Error c", "Error d"} {
+		f.handle(api.LogLine{Seq: uint64(i), Line: msg}, now)
+	}
+	if len(rec.msgs) != 2 {
+		t.Fatalf("Expected the burst of 2 posted, got %q", rec.msgs)
+	}
+	f.handle(api.LogLine{Line: "Error e"}, now.Add(time.Minute))
+	if len(rec.msgs) != 3 || !strings.Contains(rec.msgs[2], "2 more log lines were not forwarded") {
+		t.Fatalf("Expected the dropped count reported, got %q", rec.msgs)
+	}
+}
+
+// TestLogForwarder_Filtering tests the level threshold, the feedback guard and send failures
+func TestLogForwarder_Filtering(t *testing.T) {
+	rec := &recordingSend{}
+	f := newLogForwarder(nil, rec.send, levelError, 100, time.Minute)
+	now := time.Now()
+
+	f.handle(api.LogLine{Line: "Warning: something odd"}, now)
+	f.handle(api.LogLine{Line: logForwardPrefix + " failed to post to Discord: 500"}, now)
+	if len(rec.msgs) != 0 {
+		t.Errorf("Expected nothing posted, got %q", rec.msgs)
+	}
+
+	rec.err = errors.New("discord down")
+	f.handle(api.LogLine{Line: "Error updating status"}, now)
+	rec.err = nil
+	f.handle(api.LogLine{Line: "Error updating status"}, now)
+	if len(rec.msgs) != 1 {
+		t.Errorf("Expected a failed post not to count as posted, got %q", rec.msgs)
+	}
+
+	long := "Error " + strings.Repeat("é", logForwardMaxLen)
+	f.handle(api.LogLine{Line: long}, now)
+	if msg := rec.msgs[len(rec.msgs)-1]; len(msg) > 2000 || !strings.Contains(msg, "…") {
+		t.Errorf("Expected a truncated message, got %d bytes", len(msg))
+	}
+}
+
+// TestLogForwarderFromEnv tests requirements, env parsing and forwarding through the log buffer
+func TestLogForwarderFromEnv(t *testing.T) {
+	b := newTestBot(testStatusConfig())
+	logs := newLogRing(10)
+
+	t.Setenv("LOG_FORWARD_CHANNEL_ID", "")
+	if f, err := logForwarderFromEnv(b, logs); f != nil || err != nil {
+		t.Error("Expected forwarding disabled when unset")
+	}
+
+	t.Setenv("LOG_FORWARD_CHANNEL_ID", "999")
+	if _, err := logForwarderFromEnv(b, nil); err == nil {
+		t.Error("Expected error without the log buffer")
+	}
+	if _, err := logForwarderFromEnv(b, logs); err == nil {
+		t.Error("Expected error in webhook mode")
+	}
+	fake := newFakeDiscord()
+	b.discord = NewDiscordPublisher(fake, fakeChannelID)
+	for name, value := range map[string]string{
+		"LOG_FORWARD_LEVEL":         "debug",
+		"LOG_FORWARD_RATE":          "0",
+		"LOG_FORWARD_DEDUPE_WINDOW": "soon",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := logForwarderFromEnv(b, logs); err == nil {
+				t.Errorf("Expected error for %s=%q", name, value)
+			}
+		})
+	}
+
+	t.Setenv("LOG_FORWARD_LEVEL", "error")
+	f, err := logForwarderFromEnv(b, logs)
+	if f == nil || err != nil {
+		t.Fatalf("Expected forwarder in bot mode, got %v", err)
+	}
+	if f.minLevel != levelError {
+		t.Errorf("Expected error level, got %v", f.minLevel)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.Run(ctx)
+	waitFor(t, "the subscription", func() bool {
+		logs.mu.Lock()
+		defer logs.mu.Unlock()
+		return len(logs.subs) == 1
+	})
+	logs.Write([]byte("2026/01/02 03:04:05 Warning: not forwarded\n"))
+	logs.Write([]byte("2026/01/02 03:04:05 Config reload check failed: bad JSON\n"))
+	waitFor(t, "the forwarded error", func() bool { return fake.count("ChannelMessageSend") == 1 })
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// ================= LOG BUFFER =================
+
+// defaultLogBufferLines is the number of log entries kept when LOG_BUFFER_LINES is unset
+const defaultLogBufferLines = 500
+
+// logSubscriberBuffer is how many lines a live tail subscriber may lag behind before lines are dropped
+const logSubscriberBuffer = 256
+
+// logRing keeps the most recent log entries for the log endpoint, its live tail and crash bundles
+// Installed below the redacting writer, so it only ever holds redacted text
+type logRing struct {
+	mu      sync.Mutex
+	entries []api.LogLine
+	next    int
+	full    bool
+	seq     uint64 // sequence number of the newest entry
+	subs    map[chan api.LogLine]struct{}
+}
+
+func newLogRing(size int) *logRing {
+	return &logRing{entries: make([]api.LogLine, size), subs: make(map[chan api.LogLine]struct{})}
+}
+
+// logRingFromEnv installs a ring of the last LOG_BUFFER_LINES log entries (default 500, 0 disables it)
+// Returns nil when disabled
+func logRingFromEnv() (*logRing, error) {
+	size := defaultLogBufferLines
+	if v := os.Getenv("LOG_BUFFER_LINES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid LOG_BUFFER_LINES %q: must be a number of lines (0 disables the buffer)", v)
+		}
+		size = n
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	r := newLogRing(size)
+	log.SetOutput(&redactingWriter{underlying: io.MultiWriter(os.Stderr, r)})
+	return r, nil
+}
+
+// Write stores p as one entry (the log package writes each entry with a single call) and passes it to
+// subscribers that have room for it
+func (r *logRing) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	r.seq++
+	line := api.LogLine{Seq: r.seq, Line: strings.TrimSuffix(string(p), "\n")}
+	r.entries[r.next] = line
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	for ch := range r.subs {
+		select {
+		case ch <- line:
+		default: // slow subscriber: it sees a gap in Seq
+		}
+	}
+	r.mu.Unlock()
+	return len(p), nil
+}
+
+// lines returns the stored entries, oldest first. Caller holds r.mu
+func (r *logRing) lines() []api.LogLine {
+	var out []api.LogLine
+	if r.full {
+		out = append(out, r.entries[r.next:]...)
+	}
+	return append(out, r.entries[:r.next]...)
+}
+
+// scrub replaces values in the stored entries with "[purged]" and returns how many entries it changed
+// (0 for a nil ring). Lines already sent to stderr or a log forwarder are out of reach
+func (r *logRing) scrub(values []string) int {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed := 0
+	for i := range r.entries {
+		line := r.entries[i].Line
+		for _, v := range values {
+			line = strings.ReplaceAll(line, v, "[purged]")
+		}
+		if line != r.entries[i].Line {
+			r.entries[i].Line = line
+			changed++
+		}
+	}
+	return changed
+}
+
+// String returns the stored entries as log text, oldest first (empty for a nil ring)
+func (r *logRing) String() string {
+	if r == nil {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var b strings.Builder
+	for _, l := range r.lines() {
+		b.WriteString(l.Line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Tail implements api.LogSource
+func (r *logRing) Tail(n int) []api.LogLine {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lines := r.lines()
+	if n < len(lines) {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// Since implements api.LogSource
+func (r *logRing) Since(seq uint64) []api.LogLine {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lines := r.lines()
+	for i, l := range lines {
+		if l.Seq > seq {
+			return lines[i:]
+		}
+	}
+	return nil
+}
+
+// Subscribe implements api.LogSource
+func (r *logRing) Subscribe() (<-chan api.LogLine, func()) {
+	ch := make(chan api.LogLine, logSubscriberBuffer)
+	r.mu.Lock()
+	r.subs[ch] = struct{}{}
+	r.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.subs, ch)
+			r.mu.Unlock()
+		})
+	}
+}
+
+// Capacity implements api.LogSource
+func (r *logRing) Capacity() int {
+	return len(r.entries)
+}
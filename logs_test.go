@@ -0,0 +1,98 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLogRing tests that the ring keeps the newest entries in order
+func TestLogRing(t *testing.T) {
+	r := newLogRing(3)
+	for _, line := range []string{"a\n", "b\n", "c\n", "d\n"} {
+		r.Write([]byte(line))
+	}
+	if got := r.String(); got != "b\nc\nd\n" {
+		t.Errorf("Expected the last 3 entries, got %q", got)
+	}
+	if tail := r.Tail(2); len(tail) != 2 || tail[0].Line != "c" || tail[1].Seq != 4 {
+		t.Errorf("Unexpected tail %+v", tail)
+	}
+	if tail := r.Tail(10); len(tail) != 3 {
+		t.Errorf("Expected the whole ring, got %+v", tail)
+	}
+	if since := r.Since(3); len(since) != 1 || since[0].Line != "d" {
+		t.Errorf("Unexpected lines since 3: %+v", since)
+	}
+	if since := r.Since(1); len(since) != 3 {
+		t.Errorf("Expected lines dropped from the ring to be skipped, got %+v", since)
+	}
+	if since := r.Since(4); since != nil {
+		t.Errorf("Expected nothing after the newest line, got %+v", since)
+	}
+	var nilRing *logRing
+	if nilRing.String() != "" {
+		t.Error("Expected a nil ring to be empty")
+	}
+}
+
+// TestLogRing_Subscribe tests live delivery, dropped lines for a full subscriber and unsubscribing
+func TestLogRing_Subscribe(t *testing.T) {
+	r := newLogRing(10)
+	lines, unsubscribe := r.Subscribe()
+	r.Write([]byte("first\n"))
+	select {
+	case l := <-lines:
+		if l.Seq != 1 || l.Line != "first" {
+			t.Errorf("Unexpected line %+v", l)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the line to be delivered")
+	}
+
+	// A subscriber that does not read never blocks logging
+	for range logSubscriberBuffer + 10 {
+		r.Write([]byte("flood\n"))
+	}
+	if len(lines) != logSubscriberBuffer {
+		t.Errorf("Expected a full subscriber buffer, got %d", len(lines))
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if len(r.subs) != 0 {
+		t.Error("Expected the subscriber removed")
+	}
+}
+
+// TestLogRingFromEnv tests LOG_BUFFER_LINES parsing and that the log feeds the ring redacted
+func TestLogRingFromEnv(t *testing.T) {
+	output := log.Writer()
+	t.Cleanup(func() { log.SetOutput(output) })
+
+	for _, v := range []string{"-1", "many"} {
+		t.Setenv("LOG_BUFFER_LINES", v)
+		if _, err := logRingFromEnv(); err == nil {
+			t.Errorf("Expected an error for LOG_BUFFER_LINES=%q", v)
+		}
+	}
+	t.Setenv("LOG_BUFFER_LINES", "0")
+	if r, err := logRingFromEnv(); r != nil || err != nil {
+		t.Errorf("Expected a disabled buffer, got %v, %v", r, err)
+	}
+
+	t.Setenv("LOG_BUFFER_LINES", "20")
+	r, err := logRingFromEnv()
+	if err != nil || r == nil {
+		t.Fatalf("logRingFromEnv failed: %v", err)
+	}
+	if r.Capacity() != 20 {
+		t.Errorf("Capacity = %d, want 20", r.Capacity())
+	}
+	log.Printf("login with token=abc123secret")
+	got := r.String()
+	if !strings.Contains(got, "token=[REDACTED]") || strings.Contains(got, "abc123secret") {
+		t.Errorf("Expected the buffered line redacted, got %q", got)
+	}
+}
@@ -3,16 +3,21 @@ package main
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -20,7 +25,11 @@ import (
 	"time"
 
 	"github.com/bombom/absa-ac/api"
+	"github.com/bombom/absa-ac/pkg/atomicfile"
+	"github.com/bombom/absa-ac/pkg/metrics"
 	"github.com/bombom/absa-ac/pkg/proxy"
+	"github.com/bombom/absa-ac/pkg/store"
+	"github.com/bombom/absa-ac/pkg/supervisor"
 	"github.com/bwmarrin/discordgo"
 	"net"
 )
@@ -80,9 +89,14 @@ func RedactSecrets(s string) string {
 			return "[REDACTED]"
 		})
 	}
+	// Webhook URLs embed their token in the path (appears in transport errors)
+	s = webhookTokenPattern.ReplaceAllString(s, "${1}[REDACTED]")
 	return s
 }
 
+// webhookTokenPattern matches the token segment of Discord webhook URLs
+var webhookTokenPattern = regexp.MustCompile(`(discord(?:app)?\.com/api(?:/v\d+)?/webhooks/\d+/)[A-Za-z0-9\-_]+`)
+
 type redactingWriter struct{ underlying io.Writer }
 
 func (rw *redactingWriter) Write(p []byte) (int, error) {
@@ -170,10 +184,11 @@ var (
 )
 
 type Server struct {
-	Name     string `json:"name"`
-	IP       string `json:"ip"`
-	Port     int    `json:"port"`
-	Category string `json:"category"`
+	Name      string `json:"name"`
+	IP        string `json:"ip"`
+	Port      int    `json:"port"`
+	Category  string `json:"category"`
+	QueryType string `json:"query_type,omitempty"` // Poller protocol (default: ac_http)
 }
 
 // ConfigManager provides thread-safe access to configuration with dynamic reload
@@ -184,10 +199,27 @@ type ConfigManager struct {
 	configPath  string
 	lastModTime time.Time
 	mu          sync.RWMutex
+
+	// lastTarget and lastHash identify the loaded file content (symlinks resolved, SHA-256)
+	// Kubernetes ConfigMap updates swap a symlink and may keep or even rewind mtimes, so the hash decides
+	lastTarget string
+	lastHash   [sha256.Size]byte
+
+	// onChange is called after every successful config swap (nil = no listener)
+	onChange func(old, new *Config)
+
+	// readOnlySource names the non-file config source (e.g. "CONFIG_JSON"); writes and reloads are disabled when set
+	readOnlySource string
+
+	// backups is how writes back up the replaced file (zero value = defaultBackupPolicy)
+	backups backupPolicy
+
+	// lastReload is the result of the last reload of a changed file, for diagnostics (nil = none yet)
+	lastReload *api.DiagnosticsReload
 }
 
 // NewConfigManager creates a new ConfigManager with an initial configuration
-// Stores initial config in atomic.Value for lock-free access
+// Stores initial config in atomic.Value for lock-free access; the manager owns it from now on
 // Records initial file modification time to detect future changes
 func NewConfigManager(configPath string, initial *Config) *ConfigManager {
 	cm := &ConfigManager{
@@ -195,21 +227,38 @@ func NewConfigManager(configPath string, initial *Config) *ConfigManager {
 	}
 	cm.config.Store(initial)
 
-	// Get initial file modification time (only if config exists)
+	// Record the initial file state (only if config exists)
 	if initial != nil {
-		if modTime, err := cm.getLastModTime(); err == nil {
-			cm.lastModTime = modTime
+		if state, err := cm.readConfigFile(); err == nil {
+			cm.recordConfigFile(state)
 		} else {
-			log.Printf("Warning: failed to get initial config mod time: %v", err)
+			log.Printf("Warning: failed to get initial config file state: %v", err)
 		}
 	}
 
 	return cm
 }
 
+// NewReadOnlyConfigManager creates a ConfigManager for a config that does not come from a file
+// source is reported in errors; all writes fail with api.ErrConfigReadOnly and file reloads are skipped
+func NewReadOnlyConfigManager(source string, initial *Config) *ConfigManager {
+	cm := &ConfigManager{readOnlySource: source}
+	cm.config.Store(initial)
+	return cm
+}
+
+// readOnlyErr returns the write error for a read-only manager (nil for file-backed managers)
+func (cm *ConfigManager) readOnlyErr() error {
+	if cm.readOnlySource == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: loaded from %s, change the environment and restart to update it", api.ErrConfigReadOnly, cm.readOnlySource)
+}
+
 // GetConfig returns the current configuration (thread-safe, lock-free read)
 // atomic.Value.Load() provides zero-copy access without mutex contention
 // Multiple goroutines can call this simultaneously during server polling
+// The result is a shared read-only snapshot (see Config); use Clone before modifying it
 func (cm *ConfigManager) GetConfig() *Config {
 	val := cm.config.Load()
 	if val == nil {
@@ -218,88 +267,144 @@ func (cm *ConfigManager) GetConfig() *Config {
 	return val.(*Config)
 }
 
-// getLastModTime retrieves the modification time of the config file (changes indicate config modifications requiring reload)
-// Returns raw os.Stat error for caller to handle (file not found, permission denied, etc.)
-func (cm *ConfigManager) getLastModTime() (time.Time, error) {
-	info, err := os.Stat(cm.configPath)
+// SetOnChange registers fn to be called after each reload or write with the old and new config
+// fn runs while the config lock is held: it must not block or call ConfigManager write methods
+func (cm *ConfigManager) SetOnChange(fn func(old, new *Config)) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.onChange = fn
+}
+
+// storeConfig atomically swaps in cfg and notifies the change listener (caller holds cm.mu)
+func (cm *ConfigManager) storeConfig(cfg *Config) {
+	old := cm.GetConfig()
+	cm.config.Store(cfg)
+	if cm.onChange != nil {
+		cm.onChange(old, cfg)
+	}
+}
+
+// configFileState is a snapshot of the config file used for change detection
+type configFileState struct {
+	target  string // path after resolving symlinks
+	modTime time.Time
+	data    []byte
+	hash    [sha256.Size]byte
+}
+
+// readConfigFile reads the config file through any symlinks (ConfigMap mounts point config.json at ..data/config.json)
+// Returns raw os errors for the caller to handle (file not found, permission denied, etc.)
+func (cm *ConfigManager) readConfigFile() (configFileState, error) {
+	target, err := filepath.EvalSymlinks(cm.configPath)
+	if err != nil {
+		return configFileState{}, err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return configFileState{}, err
+	}
+	data, err := os.ReadFile(target)
 	if err != nil {
-		return time.Time{}, err
+		return configFileState{}, err
 	}
-	return info.ModTime(), nil
+	return configFileState{target: target, modTime: info.ModTime(), data: data, hash: sha256.Sum256(data)}, nil
+}
+
+// recordConfigFile marks state as the loaded file content (caller holds cm.mu or owns cm)
+func (cm *ConfigManager) recordConfigFile(state configFileState) {
+	cm.lastTarget = state.target
+	cm.lastModTime = state.modTime
+	cm.lastHash = state.hash
 }
 
-// checkAndReloadIfNeeded checks if the config file has changed and reloads synchronously.
-// Uses a short 5ms debounce to batch rapid file writes (e.g., editor saves).
-// Returns after reload completes (or immediately if no change detected).
+// checkAndReloadIfNeeded checks if the config file content has changed and reloads synchronously.
+// Changes are detected by content hash, so symlink swaps (Kubernetes ConfigMaps) and writes that
+// keep the mtime are picked up; touching the file without changing it does not reload.
+// Uses a short 5ms debounce and only reloads content that reads the same twice, so a file that is
+// still being written is retried on the next check instead of half-loaded.
 // Holds the lock during the entire operation to prevent race conditions.
 func (cm *ConfigManager) checkAndReloadIfNeeded() error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
+	// Env-sourced config never changes at runtime
+	if cm.readOnlySource != "" {
+		return nil
+	}
+
 	// If no config currently loaded, check if file exists now
 	if cm.config.Load() == nil {
 		log.Printf("No config loaded, checking if config file exists...")
 	}
 
-	// Check current file modification time
-	currentModTime, err := cm.getLastModTime()
+	state, err := cm.readConfigFile()
 	if err != nil {
 		if os.IsNotExist(err) {
 			log.Printf("Config file not found, skipping reload")
 			return nil
 		}
-		return fmt.Errorf("failed to stat config file: %w", err)
+		return cm.recordReload(fmt.Errorf("failed to read config file: %w", err))
 	}
 
-	// No change detected
-	if currentModTime.Equal(cm.lastModTime) || currentModTime.Before(cm.lastModTime) {
+	// Same content (mtime touched, or symlink swapped to an identical file): nothing to reload
+	if state.hash == cm.lastHash {
+		if state.target != cm.lastTarget {
+			log.Printf("Config file now resolves to %s (content unchanged)", state.target)
+		}
+		cm.recordConfigFile(state)
 		return nil
 	}
 
-	// File has changed, wait briefly to batch rapid writes
+	// Content changed, wait briefly to batch rapid writes
 	// Short debounce: wait 5ms for additional writes to settle
 	// This prevents excessive reloads during editor save operations
 	time.Sleep(5 * time.Millisecond)
 
-	// Re-check file modification time after debounce
-	currentModTime, err = cm.getLastModTime()
+	// Re-read after debounce: only load content that is stable across both reads
+	settled, err := cm.readConfigFile()
 	if err != nil {
-		return fmt.Errorf("failed to stat config file: %w", err)
+		if os.IsNotExist(err) {
+			log.Printf("Config file disappeared during reload, retrying on next check")
+			return nil
+		}
+		return cm.recordReload(fmt.Errorf("failed to read config file: %w", err))
 	}
-
-	// If no change detected after debounce (file was written before our check), skip
-	if currentModTime.Equal(cm.lastModTime) || currentModTime.Before(cm.lastModTime) {
+	if settled.hash != state.hash {
+		log.Printf("Config file still changing, retrying on next check")
 		return nil
 	}
 
 	log.Printf("Config file modified, attempting reload from: %s", cm.configPath)
 
-	// Load new config
-	newCfg, err := loadConfig(cm.configPath)
-	if err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
-	}
-
-	// If loadConfig returned nil (file not found), skip reload
-	if newCfg == nil {
-		log.Printf("Config file not found during reload attempt")
-		return nil
+	// Parse new config (from the bytes that were hashed, not a third read)
+	var newCfg Config
+	if err := parseConfigFile(settled.data, &newCfg); err != nil {
+		return cm.recordReload(fmt.Errorf("failed to parse config from %s: %w", cm.configPath, err))
 	}
 
 	// Validate new config
-	if err := validateConfigStructSafeRuntime(newCfg); err != nil {
-		return fmt.Errorf("config validation failed: %w", err)
+	if err := validateConfigStructSafeRuntime(&newCfg); err != nil {
+		return cm.recordReload(fmt.Errorf("config validation failed: %w", err))
 	}
 
 	// Initialize server IPs from global ServerIP setting.
-	initializeServerIPs(newCfg)
+	initializeServerIPs(&newCfg)
 
-	// Success: atomically swap config and update mod time
-	cm.config.Store(newCfg)
-	cm.lastModTime = currentModTime
+	// Success: atomically swap config and record the file state
+	cm.storeConfig(&newCfg)
+	cm.recordConfigFile(settled)
 	log.Println("Config reloaded successfully")
 
-	return nil
+	return cm.recordReload(nil)
+}
+
+// recordReload remembers the outcome of a reload attempt for diagnostics and returns err (caller holds cm.mu)
+func (cm *ConfigManager) recordReload(err error) error {
+	cm.lastReload = &api.DiagnosticsReload{At: time.Now().UTC(), OK: err == nil}
+	if err != nil {
+		cm.lastReload.Error = err.Error()
+	}
+	return err
 }
 
 // Cleanup releases resources
@@ -311,13 +416,22 @@ func (cm *ConfigManager) Cleanup() {
 
 // WriteConfig writes a complete new configuration to disk with backup and atomic write
 // Creates backup file before modifying, writes to temp file, then atomic rename
+// Stores a deep copy of newConfig; the caller's struct is left unmodified
 // Returns error if validation fails (config unchanged on disk)
-// Triggers reload via file mtime change on success
+// Swaps the in-memory config directly on success; the file watcher does not reload it again
 // Thread-safe: serializes concurrent writes using RWMutex write lock
 func (cm *ConfigManager) WriteConfig(newConfig *Config) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
+	if err := cm.readOnlyErr(); err != nil {
+		return err
+	}
+
+	// Copy on write: the stored snapshot never aliases the caller's struct, so neither
+	// initializeServerIPs below nor later changes by the caller are seen by readers
+	newConfig = newConfig.Clone()
+
 	// Validate new config before making any changes
 	if err := validateConfigStructSafeRuntime(newConfig); err != nil {
 		return fmt.Errorf("config validation failed: %w", err)
@@ -331,43 +445,30 @@ func (cm *ConfigManager) WriteConfig(newConfig *Config) error {
 		return fmt.Errorf("backup creation failed: %w", err)
 	}
 
-	// Serialize config to JSON
-	data, err := json.MarshalIndent(newConfig, "", "  ")
+	// Serialize config to JSON, keeping the key order, unknown keys and comment header of the current file
+	data, err := cm.encodeConfigFile(newConfig)
 	if err != nil {
 		return fmt.Errorf("JSON encoding failed: %w", err)
 	}
 
-	// Atomic write: temp file + rename
-	if err := cm.atomicWrite(data); err != nil {
-		return fmt.Errorf("atomic write failed: %w", err)
-	}
-
-	// Update mod time to trigger reload (must hold lock until complete)
-	// Moving touchConfigFile inside lock prevents race with reload
-	if err := cm.touchConfigFile(); err != nil {
-		return fmt.Errorf("failed to update config mod time: %w", err)
-	}
-
-	// Atomically swap in-memory config and update mod time
-	// This ensures GetConfig returns the new config immediately after write
-	cm.config.Store(newConfig)
-	cm.lastModTime, err = cm.getLastModTime()
-	if err != nil {
-		return fmt.Errorf("failed to get config mod time: %w", err)
-	}
-
-	return nil
+	// Atomic write, then swap in-memory config and record the written file state
+	// GetConfig returns the new config immediately and the file watcher sees no change to reload
+	return cm.commitWrite(newConfig, data)
 }
 
 // UpdateConfig applies a partial configuration update by merging with existing config
 // Reads current config, merges partial changes using deep merge, then writes
 // Returns error if validation fails or merge cannot be performed
-// Triggers reload via file mtime change on success
+// Swaps the in-memory config directly on success; the file watcher does not reload it again
 // Thread-safe: serializes concurrent writes using RWMutex write lock
 func (cm *ConfigManager) UpdateConfig(partial map[string]interface{}) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
+	if err := cm.readOnlyErr(); err != nil {
+		return err
+	}
+
 	// Get current config as baseline
 	current := cm.GetConfig()
 
@@ -376,6 +477,10 @@ func (cm *ConfigManager) UpdateConfig(partial map[string]interface{}) error {
 	if err != nil {
 		return fmt.Errorf("config merge failed: %w", err)
 	}
+	// GetConfigAny serves passwords redacted; sent back unchanged they keep their value
+	if err := restoreRedactedPasswords(merged.Passwords, current.Passwords); err != nil {
+		return fmt.Errorf("merged config validation failed: %w", err)
+	}
 
 	// Validate merged config
 	if err := validateConfigStructSafeRuntime(merged); err != nil {
@@ -390,96 +495,20 @@ func (cm *ConfigManager) UpdateConfig(partial map[string]interface{}) error {
 		return fmt.Errorf("backup creation failed: %w", err)
 	}
 
-	// Serialize merged config
-	data, err := json.MarshalIndent(merged, "", "  ")
+	// Serialize merged config in the layout of the current file
+	data, err := cm.encodeConfigFile(merged)
 	if err != nil {
 		return fmt.Errorf("JSON encoding failed: %w", err)
 	}
 
-	// Atomic write
-	if err := cm.atomicWrite(data); err != nil {
-		return fmt.Errorf("atomic write failed: %w", err)
-	}
-
-	// Update mod time
-	if err := cm.touchConfigFile(); err != nil {
-		log.Printf("Warning: failed to update config mod time: %v", err)
-	}
-
-	// Atomically swap in-memory config and update mod time
-	// This ensures GetConfig returns the merged config immediately after update
-	cm.config.Store(merged)
-	cm.lastModTime, err = cm.getLastModTime()
-	if err != nil {
-		log.Printf("Warning: failed to get config mod time: %v", err)
-	}
-
-	return nil
-}
-
-// createBackup creates a backup of the current config file with rotation
-// Implements 3-version backup rotation: .backup.1 (latest) -> .backup.2 -> .backup.3 (oldest)
-// Backup path is config.json.backup in same directory as config file
-// Returns nil if config file doesn't exist yet (first-time write)
-func (cm *ConfigManager) createBackup() error {
-	// Read existing config file
-	data, err := os.ReadFile(cm.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// No existing config to backup (first write)
-			return nil
-		}
-		return err
-	}
-
-	// Implement backup rotation: .backup.1 (latest) -> .backup.2 -> .backup.3 (oldest)
-	backupPaths := []string{
-		cm.configPath + ".backup.3", // Oldest - deleted first
-		cm.configPath + ".backup.2",
-		cm.configPath + ".backup.1",
-		cm.configPath + ".backup", // Current backup
-	}
-
-	// Rotate: delete .backup.3 if exists
-	if _, err := os.Stat(backupPaths[0]); err == nil {
-		if err := os.Remove(backupPaths[0]); err != nil {
-			return fmt.Errorf("failed to remove %s: %w", backupPaths[0], err)
-		}
-	}
-
-	// Rotate: .backup.2 -> .backup.3
-	if _, err := os.Stat(backupPaths[1]); err == nil {
-		if err := os.Rename(backupPaths[1], backupPaths[0]); err != nil {
-			return fmt.Errorf("failed to rename %s -> %s: %w", backupPaths[1], backupPaths[0], err)
-		}
-	}
-
-	// Rotate: .backup.1 -> .backup.2
-	if _, err := os.Stat(backupPaths[2]); err == nil {
-		if err := os.Rename(backupPaths[2], backupPaths[1]); err != nil {
-			return fmt.Errorf("failed to rename %s -> %s: %w", backupPaths[2], backupPaths[1], err)
-		}
-	}
-
-	// Current -> .backup.1
-	if _, err := os.Stat(backupPaths[3]); err == nil {
-		if err := os.Rename(backupPaths[3], backupPaths[2]); err != nil {
-			return fmt.Errorf("failed to rename %s -> %s: %w", backupPaths[3], backupPaths[2], err)
-		}
-	}
-
-	// Write current config to .backup
-	if err := os.WriteFile(backupPaths[3], data, 0644); err != nil {
-		return fmt.Errorf("failed to write backup: %w", err)
-	}
-
-	log.Printf("Config backup rotated: %s (latest of 3 versions)", backupPaths[3])
-	return nil
+	// Atomic write, then swap in-memory config and record the written file state
+	// GetConfig returns the merged config immediately and the file watcher sees no change to reload
+	return cm.commitWrite(merged, data)
 }
 
 // atomicWrite writes data to config file using atomic temp-file-then-rename pattern
 // Prevents partial writes during crash/power loss
-// Write to temp file, then rename over original (atomic on POSIX systems)
+// Write to temp file, then replace the original (rename on POSIX, ReplaceFileW on Windows)
 func (cm *ConfigManager) atomicWrite(data []byte) error {
 	// Create temp file in same directory as target
 	dir := filepath.Dir(cm.configPath)
@@ -514,8 +543,8 @@ func (cm *ConfigManager) atomicWrite(data []byte) error {
 	}
 	tmpFile = nil // Prevent defer cleanup (file successfully closed)
 
-	// Atomic rename over target
-	if err := os.Rename(tmpPath, cm.configPath); err != nil {
+	// Atomic replace of target
+	if err := atomicfile.Replace(tmpPath, cm.configPath); err != nil {
 		// On rename error, tmpFile already closed but defer won't cleanup
 		// Manually clean up the orphaned temp file
 		os.Remove(tmpPath)
@@ -526,11 +555,27 @@ func (cm *ConfigManager) atomicWrite(data []byte) error {
 	return nil
 }
 
-// touchConfigFile updates the modification time of the config file
-// This triggers the reload mechanism (mtime-based polling)
-func (cm *ConfigManager) touchConfigFile() error {
-	now := time.Now()
-	return os.Chtimes(cm.configPath, now, now)
+// commitWrite atomically writes data, swaps in cfg and records the written file state (caller holds cm.mu)
+// The state is recorded from the bytes written rather than a re-read, so the next watcher check
+// matches its hash and skips the reload, while an external edit landing right after the rename
+// still differs and is picked up. The mtime comes from the renamed file itself.
+func (cm *ConfigManager) commitWrite(cfg *Config, data []byte) error {
+	if err := cm.atomicWrite(data); err != nil {
+		return fmt.Errorf("atomic write failed: %w", err)
+	}
+	cm.storeConfig(cfg)
+
+	state := configFileState{target: cm.configPath, hash: sha256.Sum256(data)}
+	if target, err := filepath.EvalSymlinks(cm.configPath); err == nil {
+		state.target = target
+	}
+	if info, err := os.Stat(state.target); err == nil {
+		state.modTime = info.ModTime()
+	} else {
+		log.Printf("Warning: failed to get config file state: %v", err)
+	}
+	cm.recordConfigFile(state)
+	return nil
 }
 
 // WriteConfigAny is an adapter for the API interface that accepts any
@@ -541,12 +586,20 @@ func (cm *ConfigManager) WriteConfigAny(cfg any) error {
 	if err != nil {
 		return err
 	}
+	// GetConfigAny serves passwords redacted; sent back unchanged they keep their value
+	if err := restoreRedactedPasswords(config.Passwords, cm.GetConfig().Passwords); err != nil {
+		return err
+	}
 	return cm.WriteConfig(config)
 }
 
-// GetConfigAny returns the current config as any (for API compatibility)
+// GetConfigAny returns a private copy of the current config as any (for API compatibility)
+// Handlers outside this package cannot be held to the read-only rule, so they never get the shared snapshot
+// Server passwords are redacted: the API reads them only through the password endpoints
 func (cm *ConfigManager) GetConfigAny() any {
-	return cm.GetConfig()
+	cfg := cm.GetConfig().Clone()
+	redactPasswords(cfg.Passwords)
+	return cfg
 }
 
 // deepMergeConfig merges a partial config map with an existing Config struct
@@ -767,6 +820,66 @@ func validateConfigStructSafeRuntime(cfg *Config) error {
 		if !categoryMap[server.Category] {
 			return fmt.Errorf("server '%s' has category '%s' which is not defined in category_order", server.Name, server.Category)
 		}
+
+		if err := validateQueryType(server); err != nil {
+			return err
+		}
+	}
+
+	if err := validateHTTPClientConfig(cfg.HTTPClient); err != nil {
+		return err
+	}
+
+	if err := validateAlertsConfig(cfg.Alerts, categoryMap); err != nil {
+		return err
+	}
+
+	if err := validateRaceEvents(cfg.Events, cfg.Servers); err != nil {
+		return err
+	}
+
+	if err := validateRotations(cfg.Rotations, cfg.Servers); err != nil {
+		return err
+	}
+
+	if err := validateWhitelists(cfg.Whitelists, cfg.Servers); err != nil {
+		return err
+	}
+
+	if err := validateServerFiles(cfg.ServerFiles, cfg.Servers); err != nil {
+		return err
+	}
+
+	if err := validateContainers(cfg.Containers, cfg.Servers); err != nil {
+		return err
+	}
+
+	if err := validatePterodactyl(cfg.Pterodactyl, cfg); err != nil {
+		return err
+	}
+
+	if err := validateCloud(cfg.CloudInstances, cfg.Servers); err != nil {
+		return err
+	}
+
+	if err := validatePasswords(cfg.Passwords, cfg.Servers); err != nil {
+		return err
+	}
+
+	if err := validateEmbedColors(cfg.EmbedColors, categoryMap); err != nil {
+		return err
+	}
+
+	if err := validateEmbedImages(cfg.EmbedImages); err != nil {
+		return err
+	}
+
+	if err := validateFeatures(cfg.Features); err != nil {
+		return err
+	}
+
+	if err := validateHooks(cfg.Hooks); err != nil {
+		return err
 	}
 
 	return nil
@@ -780,33 +893,294 @@ type ServerInfo struct {
 	Map        string
 	Players    string // "X/Y" format
 	NumPlayers int    // For sorting/totaling (-1 = offline)
+	MaxPlayers int
 	IP         string
 	Port       int
+
+	// LastSeen is the time of the last successful poll when showing last known data (zero = polled just now)
+	LastSeen time.Time
+	// Stale marks last known data older than STALE_AFTER_INTERVALS intervals
+	Stale bool
+	// Trend is the player trend indicator over the last hour ("" when PLAYER_TRENDS is off or history is short)
+	Trend string
+	// Maintenance is set while the server is flagged for maintenance (nil = normal operation)
+	Maintenance *maintenanceEntry
+	// Event is the race event running on the server (nil = none)
+	Event *activeEvent
+	// NextTrack is the next entry of the server's track rotation (nil = none configured or all past)
+	NextTrack *upcomingTrack
+	// CarClasses is the car class summary of the entry list ("GT3 x12, LMP2 x4", only with EMBED_CAR_CLASSES)
+	CarClasses string
+	// Power is the process state of a managed server when it is not running, or the pending action (nil = running)
+	Power *serverPower
+	// CloudPlanned marks a cloud server that is stopped, starting or stopping on purpose (no outage alerts)
+	CloudPlanned bool
 }
 
 type Bot struct {
+	// session is the gateway connection (nil in webhook mode, where gateway-only features are skipped)
 	session       *discordgo.Session
-	channelID     string
 	configManager *ConfigManager
-	serverMessage *discordgo.Message
-	messageMutex  sync.RWMutex
+
+	// gatewayConnects counts gateway connections opened, so reconnects can be told from the first one
+	gatewayConnects atomic.Uint64
+	// gatewayUp is true while the gateway connection is open
+	gatewayUp atomic.Bool
+
+	// discord manages the status message through the bot session (nil in webhook mode)
+	discord *DiscordPublisher
+
+	// publishers receive every status update and alert (Discord or webhook, plus Slack/Matrix if configured)
+	publishers []Publisher
 
 	// API server (optional - nil if disabled)
 	apiServer *api.Server
-	apiCancel context.CancelFunc
 
 	// Proxy server (optional - nil if disabled)
 	proxyServer *proxy.Server
-	proxyCancel context.CancelFunc
+
+	// Service manager watchdog (always present, pings only when NOTIFY_SOCKET is set)
+	watchdog *Watchdog
+
+	// updates guards performUpdate against overlapping runs
+	updates updateGuard
+
+	// refresh requests an out-of-cycle update (category layout changed; see requestRefresh)
+	refresh chan struct{}
+
+	// lastStatus holds the most recent poll result (nil until the first poll completes)
+	lastStatus atomic.Pointer[StatusSnapshot]
+
+	// statusPage renders static HTML/JSON after each poll (optional - nil if disabled)
+	statusPage *StatusPageRenderer
+
+	// banner renders a PNG status image after each poll (optional - nil if disabled)
+	banner *StatusBanner
+
+	// lastBanner holds the most recent PNG banner (nil until the first poll completes)
+	lastBanner atomic.Pointer[[]byte]
+
+	// images proxies the embed thumbnail and image through the API (nil = disabled)
+	images *imageProxy
+
+	// lastPoll holds the most recent poll results for the embed preview (nil until the first poll completes)
+	lastPoll atomic.Pointer[polledServers]
+
+	// lastUpdate holds the most recent published update, the base of the offline status posted on shutdown
+	lastUpdate atomic.Pointer[StatusUpdate]
+
+	// offline configures the final status posted on shutdown (zero value = off)
+	offline offlineStatus
+
+	// drainTimeout bounds how long API and proxy shutdown wait for in-flight requests (0 = default)
+	drainTimeout time.Duration
+
+	// lifecycle owns all background goroutines (update loop, API, proxy, watchdog)
+	lifecycle *Lifecycle
+
+	// updateLoopActive is set while startUpdateLoop runs; a second concurrent start is refused
+	// Backs up the lifecycle's per-name guard against callers that bypass the lifecycle manager
+	updateLoopActive atomic.Bool
+
+	// leader gates publishing when several replicas run (optional - nil = always leader)
+	leader *LeaderElection
+
+	// shards splits polling across instances (optional - nil = poll every server)
+	shards *PollSharding
+
+	// stale keeps last known data for failed polls (optional - nil = failed polls show offline)
+	stale *staleTracker
+
+	// scheduledEvents mirrors config events as Discord scheduled events (bot mode only - nil in webhook mode)
+	scheduledEvents *scheduledEventSync
+
+	// maintenance holds the runtime per-server maintenance flags (zero value ready to use)
+	maintenance maintenanceRegistry
+
+	// hysteresis holds back online/offline changes until enough polls agree (optional - nil = show every poll as is)
+	hysteresis *hysteresisTracker
+
+	// trends renders player trend indicators from recent polls (optional - nil = no indicators)
+	trends *trendTracker
+
+	// entryLists fetches the entry lists of online servers after each poll (optional - nil = not fetched)
+	entryLists *entryListTracker
+
+	// standings scores the AC results folder and posts the championship (optional - nil = off)
+	standings *standingsModule
+
+	// db stores the history and the audit log (optional - nil = no database)
+	db *store.DB
+
+	// history records every poll in hourly buckets for the uptime reports (optional - nil = off)
+	history *historyStore
+
+	// slaReports posts the previous month's uptime report (optional - nil = off)
+	slaReports *slaReporter
+
+	// drivers links Steam GUIDs to Discord members for mentions in results (optional - nil = names only)
+	drivers *driverRegistry
+
+	// retention prunes audit entries, results files and driver links past their limits (optional - nil = kept)
+	retention *retentionPolicy
+
+	// whitelists writes reserved slots to the entry lists on this host (zero value ready to use)
+	whitelists whitelistSync
+
+	// whitelistRoles limits /whitelist to members with one of these roles (empty = Discord permissions only)
+	whitelistRoles []string
+
+	// power reads container states and runs start/stop/restart (nil = no backend enabled)
+	power *powerControl
+
+	// cloud starts servers from the status message buttons and stops them when empty (nil = off, always nil in webhook mode)
+	cloud *cloudControl
+
+	// slots DMs members who asked to be told when a full server has a free slot (nil = off, always nil in webhook mode)
+	slots *slotNotifier
+
+	// textCommands answers !servers and !status in allowlisted channels (nil = off, always nil in webhook mode)
+	textCommands *textCommands
+
+	// commandLocales translates slash command names and descriptions at registration (nil = English only)
+	commandLocales commandLocales
+
+	// permissionAlerted suppresses repeated missing-permission alerts until an update succeeds again
+	permissionAlerted atomic.Bool
+
+	// permissions holds the last channel permission self-check (nil until ready, always nil in webhook mode)
+	permissions atomic.Pointer[permissionReport]
+
+	// privileges holds the last intent and permission audit (nil until ready, always nil in webhook mode)
+	privileges atomic.Pointer[privilegeReport]
+
+	// fatal receives an unrecoverable error (revoked token); WaitForShutdown then exits non-zero
+	fatal chan error
+
+	// crashes writes crash bundles on panics and fatal errors (optional - nil = off)
+	crashes *crashReporter
+
+	// logForward posts warnings and errors to a Discord admin channel (optional - nil = off)
+	logForward *logForwarder
+
+	// alerts tracks server outages and reload failures for the alert routing table (see alert)
+	alerts alertRouter
+
+	// incidents reports critical conditions to PagerDuty/Opsgenie (optional - nil = off)
+	incidents *incidentManager
+
+	// offsite uploads the state backup archive to S3-compatible storage (optional - nil = off)
+	offsite *offsiteBackup
+
+	// versions checks the release feed for a newer version (optional - nil = off)
+	versions *versionChecker
+
+	// features holds the feature flags read at startup (zero value = defaults)
+	features featureSet
+
+	// hooks runs the config's script hooks (nil when the hooks feature flag is off)
+	hooks *hookRunner
 }
 
+// Component names registered with the lifecycle manager
+const (
+	componentUpdateLoop  = "update loop"
+	componentAPIServer   = "API server"
+	componentProxyServer = "proxy server"
+	componentWatchdog    = "watchdog"
+	componentLeader      = "leader election"
+	componentLogForward  = "log forwarding"
+	componentHistory     = "history writer"
+	componentOffsite     = "offsite backup"
+	componentVersions    = "version check"
+	componentHooks       = "script hooks"
+)
+
 // Config holds application configuration loaded from config.json
+// A Config held by a ConfigManager is an immutable snapshot shared by every reader without locks:
+// never modify it in place. To change the config, Clone it, edit the copy and pass it to WriteConfig
 type Config struct {
-	ServerIP       string            `json:"server_ip"`
-	UpdateInterval int               `json:"update_interval"`
-	CategoryOrder  []string          `json:"category_order"`
-	CategoryEmojis map[string]string `json:"category_emojis"`
-	Servers        []Server          `json:"servers"`
+	ServerIP       string                       `json:"server_ip"`
+	UpdateInterval int                          `json:"update_interval"`
+	CategoryOrder  []string                     `json:"category_order"`
+	CategoryEmojis map[string]string            `json:"category_emojis"`
+	Servers        []Server                     `json:"servers"`
+	HTTPClient     *HTTPClientConfig            `json:"http_client,omitempty"`
+	Alerts         *AlertsConfig                `json:"alerts,omitempty"`
+	Events         []RaceEvent                  `json:"events,omitempty"`
+	Rotations      map[string][]TrackRotation   `json:"rotations,omitempty"`
+	Whitelists     map[string]Whitelist         `json:"whitelists,omitempty"`
+	ServerFiles    *ServerFilesConfig           `json:"server_files,omitempty"`
+	Containers     map[string]string            `json:"containers,omitempty"` // server name -> Docker container
+	Pterodactyl    map[string]PterodactylServer `json:"pterodactyl,omitempty"`
+	CloudInstances *CloudConfig                 `json:"cloud_instances,omitempty"`
+	Passwords      map[string]ServerPassword    `json:"passwords,omitempty"`
+	EmbedColors    *EmbedColors                 `json:"embed_colors,omitempty"`
+	EmbedImages    *EmbedImages                 `json:"embed_images,omitempty"`
+	Features       map[string]bool              `json:"features,omitempty"` // feature flag -> on (see featureFlags)
+	Hooks          []HookConfig                 `json:"hooks,omitempty"`
+}
+
+// Clone returns a deep copy of c that shares no slices, maps or pointers with it (nil for nil)
+// Keep it in sync with the Config fields: every reference type must be copied here
+func (c *Config) Clone() *Config {
+	if c == nil {
+		return nil
+	}
+	out := *c
+	out.CategoryOrder = slices.Clone(c.CategoryOrder)
+	out.CategoryEmojis = maps.Clone(c.CategoryEmojis)
+	out.Servers = slices.Clone(c.Servers)
+	if c.HTTPClient != nil {
+		settings := *c.HTTPClient
+		out.HTTPClient = &settings
+	}
+	if c.Alerts != nil {
+		alerts := AlertsConfig{Routes: slices.Clone(c.Alerts.Routes)}
+		for i, r := range alerts.Routes {
+			alerts.Routes[i].Events = slices.Clone(r.Events)
+			alerts.Routes[i].Categories = slices.Clone(r.Categories)
+			if r.QuietHours != nil {
+				quiet := *r.QuietHours
+				alerts.Routes[i].QuietHours = &quiet
+			}
+		}
+		if c.Alerts.Escalation != nil {
+			esc := *c.Alerts.Escalation
+			esc.Categories = slices.Clone(esc.Categories)
+			esc.Steps = slices.Clone(esc.Steps)
+			alerts.Escalation = &esc
+		}
+		alerts.Rules = slices.Clone(c.Alerts.Rules)
+		out.Alerts = &alerts
+	}
+	out.Events = slices.Clone(c.Events)
+	if c.Rotations != nil {
+		out.Rotations = make(map[string][]TrackRotation, len(c.Rotations))
+		for server, rotation := range c.Rotations {
+			out.Rotations[server] = slices.Clone(rotation)
+		}
+	}
+	if c.Whitelists != nil {
+		out.Whitelists = make(map[string]Whitelist, len(c.Whitelists))
+		for server, wl := range c.Whitelists {
+			wl.Drivers = slices.Clone(wl.Drivers)
+			out.Whitelists[server] = wl
+		}
+	}
+	out.ServerFiles = cloneServerFiles(c.ServerFiles)
+	out.Containers = maps.Clone(c.Containers)
+	out.Pterodactyl = maps.Clone(c.Pterodactyl)
+	out.CloudInstances = cloneCloud(c.CloudInstances)
+	out.Features = maps.Clone(c.Features)
+	out.Hooks = slices.Clone(c.Hooks)
+	out.Passwords = clonePasswords(c.Passwords)
+	out.EmbedColors = cloneEmbedColors(c.EmbedColors)
+	if c.EmbedImages != nil {
+		images := *c.EmbedImages
+		out.EmbedImages = &images
+	}
+	return &out
 }
 
 // loadConfig reads and parses config.json
@@ -828,7 +1202,7 @@ func loadConfig(providedPath string) (*Config, error) {
 	}
 
 	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	if err := parseConfigFile(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config from %s: %w", configPath, err)
 	}
 
@@ -892,99 +1266,144 @@ func validateConfigStruct(cfg *Config) {
 		if !categoryMap[server.Category] {
 			log.Fatalf("Configuration error: server '%s' has category '%s' which is not defined in category_order", server.Name, server.Category)
 		}
-	}
 
-	log.Printf("Configuration validated: %d servers across %d categories", len(cfg.Servers), len(cfg.CategoryOrder))
-}
+		if err := validateQueryType(server); err != nil {
+			log.Fatalf("Configuration error: %v", err)
+		}
+	}
 
-// initializeServerIPs sets the IP field for each server to the global ServerIP value.
-// This is called after config load to populate server IPs from the centralized ServerIP setting,
-// avoiding redundancy in the config file while maintaining per-server IP fields for URL construction.
-func initializeServerIPs(cfg *Config) {
-	for i := range cfg.Servers {
-		cfg.Servers[i].IP = cfg.ServerIP
+	if err := validateHTTPClientConfig(cfg.HTTPClient); err != nil {
+		log.Fatalf("Configuration error: %v", err)
 	}
-}
 
-// ================= HTTP CLIENT =================
+	if err := validateAlertsConfig(cfg.Alerts, categoryMap); err != nil {
+		log.Fatalf("Configuration error: %v", err)
+	}
 
-var httpClient = &http.Client{
-	Timeout: 2 * time.Second,
-}
+	if err := validateRaceEvents(cfg.Events, cfg.Servers); err != nil {
+		log.Fatalf("Configuration error: %v", err)
+	}
 
-func fetchAllServers(cfgManager *ConfigManager) []ServerInfo {
-	cfg := cfgManager.GetConfig()
-	if cfg == nil {
-		return []ServerInfo{}
+	if err := validateRotations(cfg.Rotations, cfg.Servers); err != nil {
+		log.Fatalf("Configuration error: %v", err)
 	}
-	var wg sync.WaitGroup
-	infos := make([]ServerInfo, len(cfg.Servers))
-	mu := sync.Mutex{}
 
-	for i, server := range cfg.Servers {
-		wg.Add(1)
-		go func(idx int, s Server) {
-			defer wg.Done()
-			info := fetchServerInfo(s)
+	if err := validateWhitelists(cfg.Whitelists, cfg.Servers); err != nil {
+		log.Fatalf("Configuration error: %v", err)
+	}
 
-			mu.Lock()
-			infos[idx] = info
-			mu.Unlock()
-		}(i, server)
+	if err := validateServerFiles(cfg.ServerFiles, cfg.Servers); err != nil {
+		log.Fatalf("Configuration error: %v", err)
 	}
 
-	wg.Wait()
-	return infos
-}
+	if err := validateContainers(cfg.Containers, cfg.Servers); err != nil {
+		log.Fatalf("Configuration error: %v", err)
+	}
 
-func fetchServerInfo(server Server) ServerInfo {
-	url := fmt.Sprintf("http://%s:%d/info", server.IP, server.Port)
+	if err := validatePterodactyl(cfg.Pterodactyl, cfg); err != nil {
+		log.Fatalf("Configuration error: %v", err)
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
+	if err := validateCloud(cfg.CloudInstances, cfg.Servers); err != nil {
+		log.Fatalf("Configuration error: %v", err)
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		log.Printf("Server '%s' failed to create request: %v", server.Name, err)
-		return offlineServerInfo(server)
+	if err := validatePasswords(cfg.Passwords, cfg.Servers); err != nil {
+		log.Fatalf("Configuration error: %v", err)
 	}
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		log.Printf("Server '%s' (%s) request failed: %v", server.Name, url, err)
-		return offlineServerInfo(server)
+	if err := validateEmbedColors(cfg.EmbedColors, categoryMap); err != nil {
+		log.Fatalf("Configuration error: %v", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Server '%s' (%s) returned status %d", server.Name, url, resp.StatusCode)
-		return offlineServerInfo(server)
+	if err := validateEmbedImages(cfg.EmbedImages); err != nil {
+		log.Fatalf("Configuration error: %v", err)
+	}
+
+	if err := validateFeatures(cfg.Features); err != nil {
+		log.Fatalf("Configuration error: %v", err)
+	}
+
+	if err := validateHooks(cfg.Hooks); err != nil {
+		log.Fatalf("Configuration error: %v", err)
+	}
+
+	log.Printf("Configuration validated: %d servers across %d categories", len(cfg.Servers), len(cfg.CategoryOrder))
+}
+
+// initializeServerIPs sets the IP field for each server to the global ServerIP value.
+// This is called after config load to populate server IPs from the centralized ServerIP setting,
+// avoiding redundancy in the config file while maintaining per-server IP fields for URL construction.
+func initializeServerIPs(cfg *Config) {
+	for i := range cfg.Servers {
+		cfg.Servers[i].IP = cfg.ServerIP
 	}
+}
 
-	var data struct {
-		Clients    int    `json:"clients"`
-		MaxClients int    `json:"maxclients"`
-		Track      string `json:"track"`
+// ================= SERVER POLLING =================
+
+// fetchAllServers polls every server in cfg concurrently
+// Callers pass the same cfg to the renderers so a concurrent reload cannot mix old and new categories
+func fetchAllServers(cfg *Config) []ServerInfo {
+	if cfg == nil {
+		return []ServerInfo{}
+	}
+	var wg sync.WaitGroup
+	infos := make([]ServerInfo, len(cfg.Servers))
+	mu := sync.Mutex{}
+
+	// All pollers share one client so keep-alive connections are reused across servers and cycles
+	client := pollClients.Get(cfg.HTTPClient)
+
+	for i, server := range cfg.Servers {
+		wg.Add(1)
+		go func(idx int, s Server) {
+			defer wg.Done()
+
+			// A panic while polling one server must not kill the process: report it offline
+			info := offlineServerInfo(s)
+			func() {
+				defer supervisor.Recover(fmt.Sprintf("fetch server '%s'", s.Name), log.Default())
+				info = fetchServerInfo(client, s)
+			}()
+
+			mu.Lock()
+			infos[idx] = info
+			mu.Unlock()
+		}(i, server)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		log.Printf("Server '%s' (%s) failed to decode response: %v", server.Name, url, err)
+	wg.Wait()
+	return infos
+}
+
+// fetchServerInfo polls one server with the poller registered for its query_type
+// Any poll error is logged and reported as offline
+func fetchServerInfo(client *http.Client, server Server) ServerInfo {
+	poller, ok := lookupPoller(server.QueryType)
+	if !ok {
+		log.Printf("Server '%s' has unknown query_type '%s'", server.Name, server.QueryType)
 		return offlineServerInfo(server)
 	}
 
-	trackName := filepath.Base(data.Track)
-	if trackName == "." || trackName == "" {
-		trackName = "Unknown"
+	ctx, cancel := context.WithTimeout(context.Background(), client.Timeout)
+	defer cancel()
+
+	result, err := poller.Poll(ctx, client, server)
+	if err != nil {
+		log.Printf("Server '%s' %v", server.Name, err)
+		return offlineServerInfo(server)
 	}
 
-	log.Printf("Server '%s' online: %s, players %d/%d", server.Name, trackName, data.Clients, data.MaxClients)
+	log.Printf("Server '%s' online: %s, players %d/%d", server.Name, result.Map, result.Players, result.MaxPlayers)
 
 	return ServerInfo{
 		Name:       server.Name,
 		Category:   server.Category,
-		Map:        trackName,
-		Players:    fmt.Sprintf("%d/%d", data.Clients, data.MaxClients),
-		NumPlayers: data.Clients,
+		Map:        result.Map,
+		Players:    fmt.Sprintf("%d/%d", result.Players, result.MaxPlayers),
+		NumPlayers: result.Players,
+		MaxPlayers: result.MaxPlayers,
 		IP:         server.IP,
 		Port:       server.Port,
 	}
@@ -1004,191 +1423,231 @@ func offlineServerInfo(server Server) ServerInfo {
 
 // ================= DISCORD INTEGRATION =================
 
-func buildEmbed(infos []ServerInfo, cfgManager *ConfigManager) *discordgo.MessageEmbed {
-	cfg := cfgManager.GetConfig()
-
-	// Group servers and calculate totals
-	grouped := make(map[string][]ServerInfo)
-	categoryTotals := make(map[string]int)
+// buildEmbed renders the full status embed from scratch, one field group per category in cfg.CategoryOrder
+// Every update rebuilds all fields, so renamed or removed categories never linger in the edited message
+// Runs every tick for every server, so it avoids per-field maps and fmt: servers are grouped by index,
+// all fields share one backing array and their text is built in a reused byte buffer
+func buildEmbed(infos []ServerInfo, cfg *Config) *discordgo.MessageEmbed {
+	// Group servers by position in category_order (counting sort keeps poll order within a category)
+	categoryIndex := make(map[string]int, len(cfg.CategoryOrder))
+	for i, category := range cfg.CategoryOrder {
+		categoryIndex[category] = i
+	}
+	categoryTotals := make([]int, len(cfg.CategoryOrder))
+	groupStart := make([]int, len(cfg.CategoryOrder)+1)
 	totalPlayers := 0
+	online := 0
 
 	for _, info := range infos {
-		grouped[info.Category] = append(grouped[info.Category], info)
+		ci, ok := categoryIndex[info.Category]
+		if !ok {
+			continue // not in category_order, never shown
+		}
+		groupStart[ci+1]++
+		if info.NumPlayers >= 0 && info.Maintenance == nil {
+			online++
+		}
 		if info.NumPlayers > 0 {
-			categoryTotals[info.Category] += info.NumPlayers
+			categoryTotals[ci] += info.NumPlayers
 			totalPlayers += info.NumPlayers
 		}
 	}
+	for i := 1; i < len(groupStart); i++ {
+		groupStart[i] += groupStart[i-1]
+	}
+	grouped := make([]int, groupStart[len(groupStart)-1])
+	next := slices.Clone(groupStart[:len(cfg.CategoryOrder)])
+	for i, info := range infos {
+		if ci, ok := categoryIndex[info.Category]; ok {
+			grouped[next[ci]] = i
+			next[ci]++
+		}
+	}
 
 	// Build embed
 	embed := &discordgo.MessageEmbed{
-		Title:       "ABSA Official Servers",
-		Description: fmt.Sprintf(":bust_in_silhouette: **Total Players:** %d", totalPlayers),
-		Color:       0x00FF00, // Green
-		Thumbnail: &discordgo.MessageEmbedThumbnail{
-			URL: "https://upload.wikimedia.org/wikipedia/commons/thumb/d/d9/Flag_of_Norway.svg/320px-Flag_of_Norway.svg.png",
-		},
-		Image: &discordgo.MessageEmbedImage{
-			URL: fmt.Sprintf("http://%s/images/logo.png", cfg.ServerIP),
-		},
+		Title:       defaultStatusTitle,
+		Description: ":bust_in_silhouette: **Total Players:** " + strconv.Itoa(totalPlayers),
+		Color:       cfg.EmbedColors.stateColor(online, len(grouped)),
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: fmt.Sprintf("Updates every %d seconds", cfg.UpdateInterval),
+			Text: "Updates every " + strconv.Itoa(cfg.UpdateInterval) + " seconds",
 		},
 	}
 
-	// Append fields by category
-	for _, category := range cfg.CategoryOrder {
-		emoji := cfg.CategoryEmojis[category]
-		total := categoryTotals[category]
+	if src := embedThumbnailURL(cfg); src != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: src}
+	}
+	if src := embedLogoURL(cfg); src != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: src}
+	}
 
+	// One header and one spacer per category plus one field per server
+	fields := make([]discordgo.MessageEmbedField, 0, len(grouped)+2*len(cfg.CategoryOrder))
+	embed.Fields = make([]*discordgo.MessageEmbedField, 0, cap(fields))
+	addField := func(name, value string) {
+		fields = append(fields, discordgo.MessageEmbedField{Name: name, Value: value, Inline: false})
+		embed.Fields = append(embed.Fields, &fields[len(fields)-1])
+	}
+
+	// Append fields by category
+	buf := make([]byte, 0, 256)
+	for ci, category := range cfg.CategoryOrder {
 		// Category header field
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:   fmt.Sprintf("%s **%s Servers — %d players**", emoji, category, total),
-			Value:  "\u200b", // Zero-width space
-			Inline: false,
-		})
+		buf = append(buf[:0], cfg.CategoryEmojis[category]...)
+		buf = append(buf, " **"...)
+		buf = append(buf, category...)
+		buf = append(buf, " Servers — "...)
+		buf = strconv.AppendInt(buf, int64(categoryTotals[ci]), 10)
+		buf = append(buf, " players**"...)
+		addField(string(buf), embedFieldBlank)
 
 		// Individual server fields
-		for _, info := range grouped[category] {
-			statusEmoji := ":green_circle:"
-			if info.NumPlayers < 0 {
-				statusEmoji = ":red_circle:"
+		for _, idx := range grouped[groupStart[ci]:groupStart[ci+1]] {
+			info := &infos[idx]
+			statusEmoji := ":green_circle: "
+			if info.Maintenance != nil {
+				statusEmoji = ":wrench: "
+			} else if info.NumPlayers < 0 {
+				statusEmoji = ":red_circle: "
+			} else if info.Stale {
+				statusEmoji = ":white_circle: "
+			}
+			name := statusEmoji + info.Name
+			if info.Event != nil {
+				name = statusEmoji + "🏁 " + info.Name
 			}
 
-			joinURL := fmt.Sprintf(
-				"https://acstuff.club/s/q:race/online/join?ip=%s&httpPort=%d",
-				info.IP, info.Port,
-			)
-
-			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-				Name: fmt.Sprintf("%s %s", statusEmoji, info.Name),
-				Value: fmt.Sprintf(
-					"**Map:** %s\n**Players:** %s\n[Join Server](%s)",
-					info.Map, info.Players, joinURL,
-				),
-				Inline: false,
-			})
+			buf = append(buf[:0], "**Map:** "...)
+			buf = append(buf, info.Map...)
+			buf = append(buf, "\n**Players:** "...)
+			buf = append(buf, info.Players...)
+			if info.Trend != "" {
+				buf = append(buf, ' ')
+				buf = append(buf, info.Trend...)
+			}
+			buf = append(buf, "\n[Join Server]("...)
+			buf = appendJoinURL(buf, info.IP, info.Port)
+			buf = append(buf, ')')
+			if info.NumPlayers >= 0 && info.Stale {
+				buf = append(buf, "\n*data "...)
+				buf = appendDataAge(buf, time.Since(info.LastSeen))
+				buf = append(buf, " old*"...)
+			}
+			if ev := info.Event; ev != nil {
+				buf = append(buf, "\n🏁 **"...)
+				buf = append(buf, ev.name...)
+				buf = append(buf, "** until "...)
+				buf = ev.until.UTC().AppendFormat(buf, "15:04 MST")
+			}
+			if info.CarClasses != "" {
+				buf = append(buf, "\n**Cars:** "...)
+				buf = append(buf, info.CarClasses...)
+			}
+			if p := info.Power; p != nil {
+				buf = append(buf, "\n**"...)
+				buf = append(buf, p.label...)
+				buf = append(buf, ":** "...)
+				buf = append(buf, p.state...)
+			}
+			if next := info.NextTrack; next != nil {
+				buf = append(buf, "\n**Next:** "...)
+				buf = append(buf, next.track...)
+				buf = append(buf, ' ')
+				buf = appendCountdown(buf, next.at, time.Now())
+			}
+			if m := info.Maintenance; m != nil {
+				buf = append(buf, "\n*Maintenance until "...)
+				buf = m.until.UTC().AppendFormat(buf, "15:04 MST")
+				if m.reason != "" {
+					buf = append(buf, " — "...)
+					buf = append(buf, m.reason...)
+				}
+				buf = append(buf, '*')
+			}
+			addField(name, string(buf))
 		}
 
 		// Spacer after category
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:   "\u200b",
-			Value:  "\u200b",
-			Inline: false,
-		})
+		addField(embedFieldBlank, embedFieldBlank)
 	}
 
 	return embed
 }
 
-func (b *Bot) getStatusMessage() *discordgo.Message {
-	b.messageMutex.RLock()
-	defer b.messageMutex.RUnlock()
-	return b.serverMessage
-}
-
-func (b *Bot) setStatusMessage(msg *discordgo.Message) {
-	b.messageMutex.Lock()
-	defer b.messageMutex.Unlock()
-	b.serverMessage = msg
-}
+// ================= EVENT HANDLERS =================
 
-func (b *Bot) updateStatusMessage(embed *discordgo.MessageEmbed) error {
-	existing := b.getStatusMessage()
+func (b *Bot) onReady(s *discordgo.Session, event *discordgo.Ready) {
+	log.Printf("✅ Logged in as %s", event.User.Username)
 
-	var msg *discordgo.Message
-	var err error
+	// Remember the channel name so a deleted and recreated channel can be found again
+	if err := b.discord.rememberChannel(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	b.checkPermissions()
+	b.registerSlashCommands(s)
+	b.syncScheduledEvents(b.configManager.GetConfig())
 
-	if existing == nil {
-		// Create new message
-		msg, err = b.session.ChannelMessageSendEmbed(b.channelID, embed)
-		if err != nil {
-			return fmt.Errorf("failed to send message: %w", err)
+	// Clean up or adopt old status messages (a standby must not touch the leader's status message)
+	if b.isLeader() {
+		if err := b.discord.cleanupOldMessages(); err != nil {
+			log.Printf("Warning: cleanup failed: %v", err)
 		}
-		b.setStatusMessage(msg)
-		log.Println("Initial status message posted")
-	} else {
-		// Edit existing message
-		msg, err = b.session.ChannelMessageEditComplex(
-			&discordgo.MessageEdit{
-				ID:      existing.ID,
-				Channel: b.channelID,
-				Embed:   embed,
-			},
-		)
-		if err != nil {
-			// Message might have been deleted - recreate
-			if restError, ok := err.(*discordgo.RESTError); ok && restError.Response != nil && restError.Response.StatusCode == 404 {
-				msg, err = b.session.ChannelMessageSendEmbed(b.channelID, embed)
-				if err != nil {
-					return fmt.Errorf("failed to recreate message: %w", err)
-				}
-				b.setStatusMessage(msg)
-				log.Println("Status message recreated (previous was deleted)")
-				return nil
-			}
-			return fmt.Errorf("failed to edit message: %w", err)
-		}
-		b.setStatusMessage(msg)
-		log.Println("Status message updated")
 	}
 
-	return nil
+	// Start update loop in background goroutine (restarted with backoff on panic)
+	// Ready fires again on every reconnect; duplicate starts are refused
+	b.launchUpdateLoop()
 }
 
-// ================= EVENT HANDLERS =================
-
-func (b *Bot) onReady(s *discordgo.Session, event *discordgo.Ready) {
-	log.Printf("✅ Logged in as %s", s.State.User.Username)
-
-	// Clean up old messages
-	if err := b.cleanupOldMessages(); err != nil {
-		log.Printf("Warning: cleanup failed: %v", err)
+func (b *Bot) registerHandlers() {
+	if b.session == nil {
+		// No gateway connection in webhook mode, so Ready never fires
+		return
 	}
-
-	// Start update loop in background goroutine
-	go b.startUpdateLoop()
+	b.session.AddHandler(b.onReady)
+	b.session.AddHandler(b.onGatewayConnect)
+	b.session.AddHandler(b.onGatewayDisconnect)
+	b.session.AddHandler(b.onInteraction)
+	b.session.AddHandler(b.onMessageCreate)
 }
 
-func (b *Bot) cleanupOldMessages() error {
-	// Fetch messages (Discord API returns max 100 per request)
-	messages, err := b.session.ChannelMessages(b.channelID, 100, "", "", "")
-	if err != nil {
-		return fmt.Errorf("failed to fetch messages: %w", err)
-	}
+// ================= UPDATE LOOP =================
 
-	botUserID := b.session.State.User.ID
-	deletedCount := 0
+// defaultUpdateInterval is used while no config is loaded
+const defaultUpdateInterval = 30 * time.Second
 
-	for _, msg := range messages {
-		if msg.Author.ID == botUserID {
-			if err := b.session.ChannelMessageDelete(b.channelID, msg.ID); err != nil {
-				log.Printf("Failed to delete message %s: %v", msg.ID, err)
-			} else {
-				deletedCount++
-			}
-		}
+// currentUpdateInterval returns the configured update interval, or the default if no config is loaded
+func (b *Bot) currentUpdateInterval() time.Duration {
+	cfg := b.configManager.GetConfig()
+	if cfg == nil {
+		return defaultUpdateInterval
 	}
-
-	log.Printf("Cleaned up %d old bot messages", deletedCount)
-	return nil
+	return time.Duration(cfg.UpdateInterval) * time.Second
 }
 
-func (b *Bot) registerHandlers() {
-	b.session.AddHandler(b.onReady)
+// launchUpdateLoop starts the update loop under the lifecycle manager, which owns it until shutdown
+// Returns false and logs if the loop is already running (reconnects, config changes) or the bot is shutting down
+func (b *Bot) launchUpdateLoop() bool {
+	if !b.lifecycle.Go(componentUpdateLoop, b.startUpdateLoop) {
+		log.Println("Update loop already running or shutting down, not starting another")
+		return false
+	}
+	return true
 }
 
-// ================= UPDATE LOOP =================
+// startUpdateLoop polls servers and updates the status message every update interval
+// Runs until ctx is cancelled; panics propagate to the supervisor which restarts the loop
+// Start it via launchUpdateLoop; a call while another instance runs logs and returns nil immediately
+func (b *Bot) startUpdateLoop(ctx context.Context) error {
+	if !b.updateLoopActive.CompareAndSwap(false, true) {
+		log.Println("Warning: refusing to start a second update loop for this bot")
+		return nil
+	}
+	defer b.updateLoopActive.Store(false)
 
-func (b *Bot) startUpdateLoop() {
-	// Use default interval if no config loaded
-	defaultInterval := 30 * time.Second
-	cfg := b.configManager.GetConfig()
-	interval := defaultInterval
-	if cfg != nil {
-		interval = time.Duration(cfg.UpdateInterval) * time.Second
-	} else {
-		log.Printf("No config loaded, using default update interval: %v", defaultInterval)
+	interval := b.currentUpdateInterval()
+	if b.configManager.GetConfig() == nil {
+		log.Printf("No config loaded, using default update interval: %v", defaultUpdateInterval)
 	}
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -1197,27 +1656,128 @@ func (b *Bot) startUpdateLoop() {
 	currentInterval := interval
 
 	// Immediate first update
-	b.performUpdate()
+	b.tryPerformUpdate()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-b.refresh:
+			log.Println("Refresh requested, rebuilding status message")
+			b.lifecycle.Task("status refresh", b.runRefresh)
+			continue
+		case <-ticker.C:
+		}
 
-	for range ticker.C {
 		// Check for config updates before each update
-		if err := b.checkForConfigUpdates(); err != nil {
+		err := b.checkForConfigUpdates()
+		if err != nil {
 			log.Printf("Config reload check failed: %v", err)
 		}
+		b.alertReloadFailure(err)
+		// This tick already renders the reloaded config, so a refresh queued by the reload is redundant
+		b.drainRefresh()
 
 		// Check if interval changed and update ticker
-		cfg := b.configManager.GetConfig()
-		newInterval := defaultInterval
-		if cfg != nil {
-			newInterval = time.Duration(cfg.UpdateInterval) * time.Second
-		}
+		newInterval := b.currentUpdateInterval()
 		if newInterval != currentInterval {
 			ticker.Reset(newInterval)
 			currentInterval = newInterval
 			log.Printf("Update interval changed to %v", newInterval)
 		}
 
-		b.performUpdate()
+		// Run asynchronously so a slow poll cannot delay tick handling; overlapping ticks are skipped
+		// The lifecycle tracks it, so shutdown waits for it before posting the offline status
+		b.lifecycle.Task("status update", func() { b.tryPerformUpdate() })
+	}
+}
+
+// slowUpdateWarnThreshold is the number of consecutive updates exceeding the update interval
+// before a warning is logged (a single slow poll is normal when a server times out)
+const slowUpdateWarnThreshold = 3
+
+// updateGuard ensures at most one performUpdate runs at a time
+// Ticks arriving while an update is in flight are skipped (not queued) and counted
+type updateGuard struct {
+	busy         atomic.Bool
+	skippedTicks atomic.Uint64
+	slowUpdates  atomic.Uint64
+
+	// consecutiveSlow is only touched by the goroutine holding busy
+	consecutiveSlow int
+
+	// recent holds the last updateHistorySize completed updates, oldest first, for diagnostics
+	recentMu sync.Mutex
+	recent   []api.DiagnosticsUpdate
+}
+
+// updateHistorySize is the number of completed updates kept for diagnostics
+const updateHistorySize = 10
+
+// record remembers a completed update that started at start and took took
+func (g *updateGuard) record(start time.Time, took time.Duration) {
+	g.recentMu.Lock()
+	defer g.recentMu.Unlock()
+	g.recent = append(g.recent, api.DiagnosticsUpdate{At: start.UTC(), DurationMs: took.Milliseconds()})
+	if len(g.recent) > updateHistorySize {
+		g.recent = slices.Delete(g.recent, 0, len(g.recent)-updateHistorySize)
+	}
+}
+
+// Recent returns the last completed updates, newest first
+func (g *updateGuard) Recent() []api.DiagnosticsUpdate {
+	g.recentMu.Lock()
+	defer g.recentMu.Unlock()
+	recent := append([]api.DiagnosticsUpdate{}, g.recent...)
+	slices.Reverse(recent)
+	return recent
+}
+
+// SkippedTicks returns the number of update ticks skipped because a previous update was still running
+func (g *updateGuard) SkippedTicks() uint64 {
+	return g.skippedTicks.Load()
+}
+
+// SlowUpdates returns the number of updates that took longer than the update interval
+func (g *updateGuard) SlowUpdates() uint64 {
+	return g.slowUpdates.Load()
+}
+
+// tryPerformUpdate runs performUpdate unless another update is already in flight
+// Returns false if the tick was skipped
+// Beats the watchdog after every completed update so a hung poll is detected as a stall
+func (b *Bot) tryPerformUpdate() bool {
+	if !b.updates.busy.CompareAndSwap(false, true) {
+		skipped := b.updates.skippedTicks.Add(1)
+		updateTicksSkipped.Inc(metrics.ComponentDiscord)
+		log.Printf("Skipping update tick: previous update still running (%d ticks skipped total)", skipped)
+		return false
+	}
+	defer b.updates.busy.Store(false)
+
+	start := time.Now()
+	b.performUpdate()
+	took := time.Since(start)
+	b.updates.record(start, took)
+	b.recordUpdateDuration(took, b.currentUpdateInterval())
+	b.watchdog.Beat()
+	return true
+}
+
+// recordUpdateDuration tracks updates exceeding the interval and warns when it keeps happening
+// Caller must hold updates.busy
+func (b *Bot) recordUpdateDuration(took, interval time.Duration) {
+	if took <= interval {
+		b.updates.consecutiveSlow = 0
+		return
+	}
+
+	b.updates.slowUpdates.Add(1)
+	updatesSlow.Inc(metrics.ComponentDiscord)
+	b.updates.consecutiveSlow++
+	if b.updates.consecutiveSlow == slowUpdateWarnThreshold {
+		log.Printf("Warning: last %d updates exceeded the update interval (latest took %v, interval %v); consider raising update_interval",
+			slowUpdateWarnThreshold, took.Round(time.Millisecond), interval)
 	}
 }
 
@@ -1228,16 +1788,56 @@ func (b *Bot) performUpdate() {
 		return
 	}
 
-	// Fetch all server info concurrently
-	infos := fetchAllServers(b.configManager)
+	// Fetch all server info concurrently (only this instance's shard when sharding is enabled)
+	now := time.Now()
+	infos := b.trackTrends(b.markMaintenance(b.applyHysteresis(b.trackStaleness(b.pollServers(cfg), cfg)), cfg, now))
+	infos = markRotations(markRaceEvents(infos, cfg, now), cfg, now)
+	infos = b.trackEntryLists(infos, cfg)
+	infos = b.trackPower(infos, cfg)
+	infos = b.trackCloud(infos, cfg)
+	b.refreshStandings()
+	b.syncWhitelists(cfg)
+	b.alertOutages(infos, cfg)
+	b.notifySlots(infos)
+	b.history.record(infos, time.Duration(cfg.UpdateInterval)*time.Second, now)
+	b.postMonthlyReport(now)
+	b.enforceRetention(now)
+	snap := buildStatusSnapshot(infos, cfg, time.Now())
+	b.lastStatus.Store(snap)
+	b.lastPoll.Store(&polledServers{infos: infos, at: snap.UpdatedAt})
+	b.runPollHooks(snap, cfg)
+
+	// Render static status page (failure must not block the Discord update)
+	if b.statusPage != nil {
+		if err := b.statusPage.Render(snap, cfg.UpdateInterval); err != nil {
+			log.Printf("Error rendering status page: %v", err)
+		}
+	}
+
+	// Render PNG banner (failure falls back to a plain embed)
+	var banner []byte
+	if b.banner != nil {
+		data, err := b.banner.Render(snap)
+		if err != nil {
+			log.Printf("Error rendering status banner: %v", err)
+		} else {
+			b.lastBanner.Store(&data)
+			if b.banner.attach {
+				banner = data
+			}
+		}
+	}
 
 	// Build embed
-	embed := buildEmbed(infos, b.configManager)
+	embed := statusEmbed(infos, cfg, banner != nil)
+	b.images.rewrite(embed, cfg)
 
-	// Send updated embed to Discord
-	if err := b.updateStatusMessage(embed); err != nil {
-		log.Printf("Error updating status: %v", err)
-	}
+	// Send the same update to Discord and any other configured targets
+	u := &StatusUpdate{Snapshot: snap, Embed: embed, HeaderColors: embedHeaderColors(embed, cfg), Banner: banner, Components: b.statusComponents(cfg, infos)}
+	b.lastUpdate.Store(u)
+	b.publishStatus(u)
+
+	b.checkAllServersDown(infos)
 }
 
 // ================= BOT CONSTRUCTION =================
@@ -1248,7 +1848,8 @@ func createDiscordSession(token string) (*discordgo.Session, error) {
 		return nil, fmt.Errorf("failed to create Discord session: %w", err)
 	}
 
-	session.Identify.Intents = discordgo.IntentGuildMessages
+	// Intents are set from the enabled features right before connecting (Bot.gatewayIntents)
+	session.Identify.Intents = discordgo.IntentsNone
 
 	return session, nil
 }
@@ -1269,16 +1870,43 @@ func NewBot(cfgManager *ConfigManager, token, channelID string, apiEnabled bool,
 		return nil, err
 	}
 
+	discord := NewDiscordPublisher(gatewaySession{session}, channelID)
+	discord.cleanupChannels = cleanupChannelsFromEnv()
 	bot := &Bot{
 		session:       session,
-		channelID:     channelID,
 		configManager: cfgManager,
+		discord:       discord,
+	}
+	bot.publishers = []Publisher{newRetryPublisher(discord, bot)}
+	if err := bot.configureServices(apiEnabled, apiPort, apiBearerToken, apiCorsOrigins, apiTrustedProxies, proxyEnabled, proxyConfig); err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+// NewWebhookBot creates a Bot that publishes through a Discord webhook (no bot token or gateway)
+// Cleanup of old messages is skipped; the status message ID is tracked by the publisher
+func NewWebhookBot(cfgManager *ConfigManager, webhook *WebhookPublisher, apiEnabled bool, apiPort, apiBearerToken, apiCorsOrigins string, apiTrustedProxies []string, proxyEnabled bool, proxyConfig *proxy.Config) (*Bot, error) {
+	if webhook == nil {
+		return nil, fmt.Errorf("webhook publisher is nil")
 	}
 
+	bot := &Bot{configManager: cfgManager}
+	bot.publishers = []Publisher{newRetryPublisher(webhook, bot)}
+	if err := bot.configureServices(apiEnabled, apiPort, apiBearerToken, apiCorsOrigins, apiTrustedProxies, proxyEnabled, proxyConfig); err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+// configureServices sets up the optional API/proxy servers, watchdog and lifecycle shared by both modes
+func (b *Bot) configureServices(apiEnabled bool, apiPort, apiBearerToken, apiCorsOrigins string, apiTrustedProxies []string, proxyEnabled bool, proxyConfig *proxy.Config) error {
+	cfgManager := b.configManager
+
 	// Create API server if enabled
 	if apiEnabled {
 		if apiBearerToken == "" {
-			return nil, fmt.Errorf("API_ENABLED=true but API_BEARER_TOKEN is not set")
+			return fmt.Errorf("API_ENABLED=true but API_BEARER_TOKEN is not set")
 		}
 
 		// Parse CORS origins
@@ -1291,61 +1919,115 @@ func NewBot(cfgManager *ConfigManager, token, channelID string, apiEnabled bool,
 			}
 		}
 
-		bot.apiServer = api.NewServer(cfgManager, apiPort, apiBearerToken, corsOrigins, apiTrustedProxies, log.Default())
+		b.apiServer = api.NewServer(cfgManager, apiPort, apiBearerToken, corsOrigins, apiTrustedProxies, log.Default())
+		b.apiServer.SetConfigLinter(&configLinter{cm: cfgManager, probe: pollProbe})
+		b.apiServer.SetServerTester(&serverTester{cm: cfgManager})
+		b.apiServer.SetServersCSV(&serversCSV{cm: cfgManager})
+		b.apiServer.SetEmbedPreviewer(&embedPreviewer{bot: b})
+		if cfgManager.readOnlySource == "" {
+			b.apiServer.SetConfigBackups(&configBackups{cm: cfgManager})
+			b.apiServer.EnableConfigAudit(api.DefaultAuditSize)
+		}
 		log.Printf("API server configured on port %s with CORS origins: %s", apiPort, apiCorsOrigins)
 	}
 
 	// Create proxy server if enabled
 	if proxyEnabled {
 		if proxyConfig == nil {
-			return nil, fmt.Errorf("PROXY_ENABLED=true but proxy config is nil")
+			return fmt.Errorf("PROXY_ENABLED=true but proxy config is nil")
 		}
-		bot.proxyServer = proxy.NewServer(*proxyConfig, log.Default())
+		b.proxyServer = proxy.NewServer(*proxyConfig, log.Default())
 		log.Printf("Proxy server configured on port %s forwarding to %s", proxyConfig.Port, proxyConfig.APIURL)
 	}
 
-	return &Bot{
-		session:       session,
-		channelID:     channelID,
-		configManager: cfgManager,
-		apiServer:     bot.apiServer,
-		proxyServer:   bot.proxyServer,
-	}, nil
+	b.watchdog = NewWatchdog(watchdogStallIntervalsFromEnv(), b.currentUpdateInterval)
+	b.lifecycle = NewLifecycle(context.Background())
+	// A component that cannot start (e.g. the API port is taken) stops the bot instead of retrying forever
+	b.lifecycle.SetFatalHandler(func(name string, err error) {
+		b.fail(fmt.Errorf("%s: %w", name, err))
+	})
+
+	// Rebuild the status message right away when a reload or GUI write changes categories
+	b.refresh = make(chan struct{}, 1)
+	cfgManager.SetOnChange(b.onConfigChange)
+
+	b.fatal = make(chan error, 1)
+
+	return nil
 }
 
 // Start launches the Discord bot and optional API server
 // Discord bot connects immediately, API server starts in background goroutine
 func (b *Bot) Start() error {
-	if err := b.session.Open(); err != nil {
-		return fmt.Errorf("failed to open Discord connection: %w", err)
+	if b.session == nil {
+		// Webhook mode: no gateway, start polling immediately
+		if b.launchUpdateLoop() {
+			log.Println("Webhook mode: update loop started (no gateway connection)")
+		}
+	} else {
+		// Request only the intents enabled features need; a privileged one must be enabled in the portal first
+		b.session.Identify.Intents = b.gatewayIntents()
+		if err := b.checkPrivilegedIntents(); err != nil {
+			return err
+		}
+		if err := b.session.Open(); err != nil {
+			return fmt.Errorf("failed to open Discord connection: %w", err)
+		}
 	}
 
-	// Start API server in background if configured
-	if b.apiServer != nil {
-		ctx, cancel := context.WithCancel(context.Background())
-		b.apiCancel = cancel
+	// Compete for leadership in the background; standbys poll but do not publish
+	if b.leader != nil {
+		b.lifecycle.Go(componentLeader, b.leader.Run)
+	}
 
-		go func() {
-			if err := b.apiServer.Start(ctx); err != nil {
-				log.Printf("API server error: %v", err)
-			}
-		}()
+	// Start API server in background if configured
+	if b.apiServer != nil && b.lifecycle.Go(componentAPIServer, b.apiServer.Start) {
 		log.Println("API server started")
 	}
 
 	// Start proxy server in background if configured
-	if b.proxyServer != nil {
-		ctx, cancel := context.WithCancel(context.Background())
-		b.proxyCancel = cancel
-
-		go func() {
-			if err := b.proxyServer.Start(ctx); err != nil {
-				log.Printf("Proxy server error: %v", err)
-			}
-		}()
+	if b.proxyServer != nil && b.lifecycle.Go(componentProxyServer, b.proxyServer.Start) {
 		log.Println("Proxy server started")
 	}
 
+	// Post warnings and errors to the admin channel if configured
+	if b.logForward != nil {
+		b.lifecycle.Go(componentLogForward, b.logForward.Run)
+	}
+
+	// Write the server history to the database behind the polls
+	if b.history != nil && b.history.writer != nil {
+		b.lifecycle.Go(componentHistory, b.history.writer.Run)
+	}
+
+	// Upload the state backup archive offsite on a schedule if configured
+	if b.offsite != nil {
+		b.lifecycle.Go(componentOffsite, b.offsite.Run)
+	}
+
+	// Check for a newer release at startup and daily if configured
+	if b.versions != nil {
+		b.lifecycle.Go(componentVersions, b.versions.Run)
+	}
+
+	// Run the config's script hooks in the background
+	if b.hooks != nil {
+		b.lifecycle.Go(componentHooks, b.hooks.Run)
+	}
+
+	// Start service manager watchdog pings if WATCHDOG_USEC is set
+	pingInterval, err := watchdogInterval()
+	if err != nil {
+		log.Printf("Warning: watchdog disabled: %v", err)
+	} else if pingInterval > 0 {
+		b.lifecycle.Go(componentWatchdog, func(ctx context.Context) error {
+			b.watchdog.Run(ctx, pingInterval)
+			return nil
+		})
+		log.Printf("Watchdog enabled: ping every %v, stall after %d update intervals", pingInterval, b.watchdog.stallIntervals)
+	}
+	b.watchdog.Ready()
+
 	return nil
 }
 
@@ -1353,24 +2035,30 @@ func (b *Bot) WaitForShutdown() {
 	sigchan := make(chan os.Signal, 1)
 	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 
-	<-sigchan
+	var fatalErr error
+	select {
+	case <-sigchan:
+	case fatalErr = <-b.fatal:
+	}
 	log.Println("Shutting down...")
+	b.watchdog.Stopping()
 
-	// Stop proxy server if running
-	if b.proxyServer != nil && b.proxyCancel != nil {
-		log.Println("Stopping proxy server...")
-		b.proxyCancel()
-		if err := b.proxyServer.Stop(); err != nil {
-			log.Printf("Error stopping proxy server: %v", err)
-		}
+	// Leader election releases the lock during shutdown, so decide about the offline status now
+	wasLeader := b.isLeader()
+
+	// Cancel update loop, API, proxy and watchdog together and wait for them to exit
+	// API and proxy servers stop accepting and drain in-flight requests on context cancellation
+	log.Println("Stopping background components...")
+	if err := b.lifecycle.Shutdown(b.shutdownTimeout()); err != nil {
+		log.Printf("Error stopping components: %v", err)
 	}
 
-	// Stop API server if running
-	if b.apiServer != nil && b.apiCancel != nil {
-		log.Println("Stopping API server...")
-		b.apiCancel()
-		if err := b.apiServer.Stop(); err != nil {
-			log.Printf("Error stopping API server: %v", err)
+	// Final update once the update loop has stopped, so it cannot be overwritten
+	b.publishOffline(wasLeader)
+	b.history.flush() // the rows of the last polls, queued after the writer stopped
+	if b.db != nil {
+		if err := b.db.Close(); err != nil {
+			log.Printf("Error closing database: %v", err)
 		}
 	}
 
@@ -1379,11 +2067,17 @@ func (b *Bot) WaitForShutdown() {
 		b.configManager.Cleanup()
 	}
 
-	if err := b.session.Close(); err != nil {
-		log.Printf("Error closing Discord session: %v", err)
+	if b.session != nil {
+		if err := b.session.Close(); err != nil {
+			log.Printf("Error closing Discord session: %v", err)
+		}
 	}
 
 	log.Println("Shutdown complete")
+	if fatalErr != nil {
+		b.crashes.report(fmt.Sprintf("unrecoverable error: %v", fatalErr), nil)
+		log.Fatalf("Exiting after unrecoverable error: %v", fatalErr)
+	}
 }
 
 // checkForConfigUpdates wraps checkAndReloadIfNeeded for use in update loop
@@ -1410,12 +2104,19 @@ func validateConfig() (token, channelID string, err error) {
 	return token, channelID, nil
 }
 
+// checkNotRootUser refuses to start as root. Windows has no uid (Geteuid is -1); admin rights there are
+// governed by the account and UAC, so the check is skipped
 func checkNotRootUser() {
+	if runtime.GOOS == "windows" {
+		return
+	}
 	if os.Geteuid() == 0 {
 		log.Fatalf("SECURITY: Container must not run as root! UID 0 detected. Please rebuild or run with --user/-u flag (see README). Refusing to start.")
 	}
 }
 
+// checkFilePerm checks that path exists and has mode want. On Windows access is controlled by ACLs and
+// Go reports every file as 0666 (0444 if read-only), so only the existence is checked there
 func checkFilePerm(path string, want os.FileMode, require bool) {
 	fi, err := os.Stat(path)
 	if err != nil {
@@ -1426,6 +2127,9 @@ func checkFilePerm(path string, want os.FileMode, require bool) {
 		}
 		return
 	}
+	if runtime.GOOS == "windows" {
+		return
+	}
 	mode := fi.Mode().Perm()
 	if mode != want {
 		msg := fmt.Sprintf("SECURITY: %s permissions %o (want %o)", path, mode, want)
@@ -1443,9 +2147,42 @@ func main() {
 
 	checkNotRootUser()
 
+	// `init` subcommand: write starter config and .env, then exit
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		if err := runInit(os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("init failed: %v", err)
+		}
+		return
+	}
+
+	// `proxy-account` subcommand: manage the proxy's admin accounts, then exit
+	if len(os.Args) > 1 && os.Args[1] == "proxy-account" {
+		if err := runProxyAccount(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			log.Fatalf("proxy-account failed: %v", err)
+		}
+		return
+	}
+
+	// `backup` and `restore` subcommands: archive or restore the bot state (DATABASE_URL from .env), then exit
+	if len(os.Args) > 1 && (os.Args[1] == "backup" || os.Args[1] == "restore") {
+		if err := loadEnv(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		run := runBackup
+		if os.Args[1] == "restore" {
+			run = runRestore
+		}
+		if err := run(os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("%s failed: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	// Parse command-line flags for config path
 	configPath := flag.String("c", "", "Path to config.json file")
 	flag.StringVar(configPath, "config", "", "Path to config.json file")
+	flag.BoolVar(&lenientConfig, "lenient", false, "Accept unknown config keys (logged as warnings) instead of failing")
+	diagnostics := flag.Bool("diagnostics", false, "Print the diagnostics report of the running bot (or of the config, if it is not running) and exit")
 	flag.Parse()
 
 	// Load environment variables from .env file (optional)
@@ -1453,6 +2190,28 @@ func main() {
 		log.Printf("Warning: %v", err)
 	}
 
+	if *diagnostics {
+		if err := runDiagnostics(os.Stdout, *configPath); err != nil {
+			log.Fatalf("diagnostics failed: %v", err)
+		}
+		return
+	}
+
+	log.Printf("absa-ac %s starting", readBuildInfo())
+
+	// Recent log lines for the API log endpoint and crash bundles
+	logs, err := logRingFromEnv()
+	if err != nil {
+		log.Fatalf("Log buffer configuration error: %v", err)
+	}
+
+	// Crash bundles (stacks, recent logs, redacted config) for panics and fatal errors
+	crashes, err := crashReporterFromEnv(logs)
+	if err != nil {
+		log.Fatalf("Crash report configuration error: %v", err)
+	}
+	defer crashes.recoverPanic()
+
 	// Read API configuration from environment
 	apiEnabled = os.Getenv("API_ENABLED") == "true"
 	apiPort = os.Getenv("API_PORT")
@@ -1542,30 +2301,459 @@ func main() {
 		log.Printf("Proxy server enabled on port %s forwarding to %s", cfg.Port, cfg.APIURL)
 	}
 
-	token, channelID, err := validateConfig()
+	// Webhook mode: DISCORD_WEBHOOK_URL without DISCORD_TOKEN (for communities that can't add bots)
+	var webhook *WebhookPublisher
+	var token, channelID string
+	if os.Getenv("DISCORD_WEBHOOK_URL") != "" && os.Getenv("DISCORD_TOKEN") == "" {
+		webhook, err = webhookPublisherFromEnv(getConfigPath(*configPath))
+		if err != nil {
+			log.Fatalf("Configuration error: DISCORD_WEBHOOK_URL: %v", err)
+		}
+		log.Println("Webhook mode enabled: publishing via DISCORD_WEBHOOK_URL (old message cleanup disabled)")
+	} else {
+		if os.Getenv("DISCORD_WEBHOOK_URL") != "" {
+			log.Println("Warning: both DISCORD_TOKEN and DISCORD_WEBHOOK_URL set, using bot mode")
+		}
+		token, channelID, err = validateConfig()
+		if err != nil {
+			log.Fatalf("Configuration error: %v", err)
+		}
+	}
+
+	// Env-only mode: CONFIG_JSON or ABSA_SERVERS replace config.json (read-only, no reload)
+	envCfg, envSource, err := configFromEnv()
 	if err != nil {
 		log.Fatalf("Configuration error: %v", err)
 	}
 
-	// Load and validate config.json
-	cfg, err := loadConfig(*configPath)
+	var cfg *Config
+	var configManager *ConfigManager
+	if envCfg != nil {
+		if *configPath != "" {
+			log.Printf("Warning: %s is set, ignoring config file %s", envSource, *configPath)
+		}
+		cfg = envCfg
+		validateConfigStruct(cfg)
+		initializeServerIPs(cfg)
+		configManager = NewReadOnlyConfigManager(envSource, cfg)
+		log.Printf("Loaded config from %s (%d servers, read-only)", envSource, len(cfg.Servers))
+	} else {
+		// Load and validate config.json
+		cfg, err = loadConfig(*configPath)
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+		if cfg == nil {
+			log.Printf("Config file not found, starting without config. Waiting for config...")
+		} else {
+			validateConfigStruct(cfg)
+
+			// Initialize server IPs before ConfigManager creation (required for lock-free readers via atomic.Value)
+			initializeServerIPs(cfg)
+		}
+
+		// Create config manager with initial config (may be nil)
+		configManager = NewConfigManager(getConfigPath(*configPath), cfg)
+
+		// Backups written before each config change (rotated slots or timestamped files)
+		backups, err := backupPolicyFromEnv()
+		if err != nil {
+			log.Fatalf("Config backup configuration error: %v", err)
+		}
+		configManager.SetBackupPolicy(backups)
+	}
+	var bot *Bot
+	if webhook != nil {
+		bot, err = NewWebhookBot(configManager, webhook, apiEnabled, apiPort, apiBearerToken, apiCorsOrigins, apiTrustedProxyList, proxyEnabled, proxyCfg)
+	} else {
+		bot, err = NewBot(configManager, token, channelID, apiEnabled, apiPort, apiBearerToken, apiCorsOrigins, apiTrustedProxyList, proxyEnabled, proxyCfg)
+	}
+	if err != nil {
+		log.Fatalf("Failed to create bot: %v", err)
+	}
+	if crashes != nil {
+		bot.crashes = crashes
+		crashes.setConfig(configManager.GetConfig)
+		bot.lifecycle.SetPanicHandler(crashes.componentPanic)
+	}
+
+	// Feature flags gating experimental subsystems (features section, FEATURE_<NAME> overrides)
+	bot.features, err = featuresFromEnv(cfg)
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		log.Fatalf("Feature flag configuration error: %v", err)
 	}
+	log.Printf("Feature flags: %s", bot.features.summary())
+	bot.features.logDisabled()
+	if bot.features.enabled(featureHooks) {
+		bot.hooks = newHookRunner()
+	}
+
+	// Setup mode: without a config, the API offers a guided bootstrap that writes the first config
 	if cfg == nil {
-		log.Printf("Config file not found, starting without config. Waiting for config...")
-	} else {
-		validateConfigStruct(cfg)
+		if bot.apiServer != nil {
+			bot.apiServer.SetSetupWizard(newSetupWizard(configManager))
+			log.Printf("Setup mode: create the initial config via the API at %s", api.SetupPath)
+		} else {
+			log.Println("Setup mode unavailable: set API_ENABLED=true to bootstrap the config via the API, or create the config file")
+		}
+	}
 
-		// Initialize server IPs before ConfigManager creation (required for lock-free readers via atomic.Value)
-		initializeServerIPs(cfg)
+	// Self-diagnostics report for bug reports (also printed by the -diagnostics flag)
+	if bot.apiServer != nil {
+		bot.apiServer.SetDiagnostics(&diagnosticsProvider{bot: bot})
+	}
+
+	// Recent logs and a live tail for the admin GUI
+	if bot.apiServer != nil && logs != nil {
+		bot.apiServer.SetLogSource(logs)
+		if !bot.features.enabled(featureLogStream) {
+			bot.apiServer.DisableLogStream()
+		}
 	}
 
-	// Create config manager with initial config (may be nil)
-	configManager := NewConfigManager(getConfigPath(*configPath), cfg)
-	bot, err := NewBot(configManager, token, channelID, apiEnabled, apiPort, apiBearerToken, apiCorsOrigins, apiTrustedProxyList, proxyEnabled, proxyCfg)
+	// Optional Discord alerts for new-IP proxy logins and repeated failures
+	loginNotify, err := proxyLoginNotifierFromEnv(bot, proxyCfg != nil && proxyCfg.AuditLogFile != "")
 	if err != nil {
-		log.Fatalf("Failed to create bot: %v", err)
+		log.Fatalf("Proxy alert configuration error: %v", err)
+	}
+	if loginNotify != nil {
+		bot.proxyServer.SetLoginNotifier(loginNotify)
+	}
+
+	// Optional forwarding of warnings and errors to a Discord admin channel
+	bot.logForward, err = logForwarderFromEnv(bot, logs)
+	if err != nil {
+		log.Fatalf("Log forwarding configuration error: %v", err)
+	}
+
+	// Optional check for new releases (logs, /health, Discord admin channel)
+	bot.versions, err = versionCheckerFromEnv(bot)
+	if err != nil {
+		log.Fatalf("Version check configuration error: %v", err)
+	}
+
+	// Optional PagerDuty/Opsgenie incidents for critical conditions
+	bot.incidents, err = incidentsFromEnv()
+	if err != nil {
+		log.Fatalf("Incident reporting configuration error: %v", err)
+	}
+
+	// Optional unauthenticated status endpoint for community websites
+	if bot.apiServer != nil && os.Getenv("API_PUBLIC_STATUS_ENABLED") == "true" {
+		showAddresses := os.Getenv("API_PUBLIC_STATUS_SHOW_ADDRESSES") == "true"
+		bot.apiServer.SetStatusProvider(&publicStatusProvider{bot: bot, showAddresses: showAddresses})
+		log.Printf("Public status endpoint enabled at %s (server addresses shown: %v)", api.PublicStatusPath, showAddresses)
+	}
+
+	// Optional removal date for v1 API routes, announced in the Sunset header and /api/versions
+	if v := os.Getenv("API_V1_SUNSET"); v != "" && bot.apiServer != nil {
+		sunset, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			log.Fatalf("API configuration error: API_V1_SUNSET must be a date like 2027-06-30: %v", err)
+		}
+		bot.apiServer.SetV1Sunset(sunset)
+		log.Printf("API v1 routes announced for removal on %s", v)
+	}
+
+	// Optional static status page written on every poll
+	statusPage, err := statusPageRendererFromEnv()
+	if err != nil {
+		log.Fatalf("Status page configuration error: %v", err)
+	}
+	bot.statusPage = statusPage
+
+	// Optional Slack status mirror
+	slack, err := slackPublisherFromEnv(getConfigPath(*configPath))
+	if err != nil {
+		log.Fatalf("Slack configuration error: %v", err)
+	}
+	if slack != nil {
+		bot.publishers = append(bot.publishers, slack)
+	}
+
+	// Optional Matrix status mirror
+	matrix, err := matrixPublisherFromEnv(getConfigPath(*configPath))
+	if err != nil {
+		log.Fatalf("Matrix configuration error: %v", err)
+	}
+	if matrix != nil {
+		bot.publishers = append(bot.publishers, matrix)
+	}
+
+	// Optional PNG status banner (API endpoint and/or Discord attachment)
+	bot.banner = statusBannerFromEnv()
+	if bot.banner != nil && bot.apiServer != nil {
+		bot.apiServer.SetStatusImageProvider(&statusBannerProvider{bot: bot})
+		log.Printf("Status banner endpoint enabled at %s", api.StatusImagePath)
+	}
+
+	// Optional leader election for multi-replica deployments (only the leader publishes)
+	leader, err := leaderElectionFromEnv()
+	if err != nil {
+		log.Fatalf("Leader election configuration error: %v", err)
+	}
+	if leader != nil {
+		leader.onElected = bot.onElected
+		bot.leader = leader
+	}
+
+	// Optional poll sharding across instances (requires leader election)
+	shards, err := pollShardingFromEnv(leader != nil)
+	if err != nil {
+		log.Fatalf("Poll sharding configuration error: %v", err)
+	}
+	bot.shards = shards
+
+	// Optional stale data tracking (failed polls keep the last known data)
+	stale, err := staleTrackerFromEnv()
+	if err != nil {
+		log.Fatalf("Stale data configuration error: %v", err)
+	}
+	bot.stale = stale
+
+	// Optional status hysteresis (flapping servers change state only after consecutive polls agree)
+	hysteresis, err := hysteresisTrackerFromEnv()
+	if err != nil {
+		log.Fatalf("Status hysteresis configuration error: %v", err)
+	}
+	bot.hysteresis = hysteresis
+
+	// Maintenance flags set via the API or /maintenance clear themselves after MAINTENANCE_TTL unless given a TTL
+	maintenanceTTL, err := maintenanceTTLFromEnv()
+	if err != nil {
+		log.Fatalf("Maintenance configuration error: %v", err)
+	}
+	bot.maintenance.ttl = maintenanceTTL
+	if bot.apiServer != nil {
+		bot.apiServer.SetMaintenanceController(&maintenanceController{bot: bot})
+	}
+
+	// Optional iCalendar feed of race events and maintenance windows for league organizers
+	if cal := eventCalendarFromEnv(bot); cal != nil && bot.apiServer != nil {
+		bot.apiServer.SetEventCalendar(cal)
+		log.Printf("Events calendar enabled at %s (server addresses shown: %v)", api.EventsCalendarPath, cal.showAddresses)
+	}
+
+	// Optional proxy of the embed thumbnail and image for sources Discord cannot reach (needs the API)
+	images, err := imageProxyFromEnv()
+	if err != nil {
+		log.Fatalf("Image proxy configuration error: %v", err)
+	}
+	if images != nil {
+		if bot.apiServer == nil {
+			log.Fatalf("Image proxy configuration error: IMAGE_PROXY requires API_ENABLED=true")
+		}
+		bot.images = images
+		bot.apiServer.SetImageProxy(&imageProxyProvider{bot: bot})
+	}
+
+	// Track rotations can be edited via the API (saved in config.json like any other config write)
+	if bot.apiServer != nil {
+		bot.apiServer.SetRotationEditor(&rotationEditor{cm: configManager})
+	}
+
+	// Reserved slots via the API and /whitelist (saved in config.json, written to entry_list.ini files)
+	bot.whitelistRoles = whitelistRolesFromEnv()
+	if bot.apiServer != nil {
+		bot.apiServer.SetWhitelistEditor(&whitelistEditor{bot: bot})
+	}
+
+	// Join passwords of event servers: /password DMs them to members with the configured roles, the API rotates them
+	if bot.apiServer != nil {
+		bot.apiServer.SetPasswordManager(&passwordManager{bot: bot})
+	}
+
+	// Optional deployment of server_cfg.ini/entry_list.ini rendered from the server_files templates (API only)
+	deployer, err := configDeployerFromEnv(configManager)
+	if err != nil {
+		log.Fatalf("Deployment configuration error: %v", err)
+	}
+	if deployer != nil && bot.apiServer != nil {
+		bot.apiServer.SetConfigDeployer(deployer)
+	}
+
+	// Optional power control: start/stop/restart via Docker (config containers) or Pterodactyl (config pterodactyl),
+	// state in the embed; a running panel state also stands in for failed polls
+	var backends []powerBackend
+	docker, err := dockerBackendFromEnv()
+	if err != nil {
+		log.Fatalf("Docker configuration error: %v", err)
+	}
+	if docker != nil {
+		backends = append(backends, docker)
+	}
+	if panel := pterodactylBackendFromEnv(); panel != nil {
+		backends = append(backends, panel)
+	}
+	if len(backends) > 0 {
+		bot.power = newPowerControl(backends...)
+		if bot.apiServer != nil {
+			bot.apiServer.SetPowerController(&powerController{bot: bot})
+		}
+	}
+
+	// Optional cloud instances: "Request" buttons under the status start stopped servers, idle ones are stopped
+	// (bot mode: buttons need the gateway)
+	if bot.discord != nil {
+		bot.cloud = cloudControlFromEnv()
+	}
+
+	// Optional "Notify me" buttons under full servers; members get a DM when a slot opens (bot mode)
+	slots, err := slotNotifierFromEnv()
+	if err != nil {
+		log.Fatalf("Slot notification configuration error: %v", err)
+	}
+	if bot.discord != nil {
+		bot.slots = slots
+	}
+
+	// Optional legacy text commands (!servers, !status) for guilds without slash commands; reading messages needs
+	// the privileged message content intent, which is only requested while they are enabled (bot mode)
+	textCmds, err := textCommandsFromEnv()
+	if err != nil {
+		log.Fatalf("Text command configuration error: %v", err)
+	}
+	if bot.discord != nil {
+		bot.textCommands = textCmds
+	}
+
+	// Slash command names and descriptions per Discord locale from the i18n bundle (plus I18N_DIR overrides, bot mode)
+	if bot.discord != nil {
+		locales, err := commandLocalesFromEnv()
+		if err != nil {
+			log.Fatalf("Localization configuration error: %v", err)
+		}
+		bot.commandLocales = locales
+	}
+
+	// Race events in config.json become Discord scheduled events (bot mode: needs the status channel's guild)
+	if bot.discord != nil {
+		bot.scheduledEvents = newScheduledEventSync(scheduledEventsStatePath(configManager.configPath))
+	}
+
+	// Optional player trend indicators (arrow or sparkline per server)
+	trends, err := trendTrackerFromEnv()
+	if err != nil {
+		log.Fatalf("Player trends configuration error: %v", err)
+	}
+	bot.trends = trends
+
+	// Optional entry lists (car classes in the embed, full list via the API)
+	bot.entryLists = entryListTrackerFromEnv()
+	if bot.entryLists != nil && bot.apiServer != nil {
+		bot.apiServer.SetEntryListProvider(&entryListProvider{tracker: bot.entryLists})
+	}
+
+	// Optional driver registration (/driver link) so results mention members
+	bot.drivers = driverRegistryFromEnv(configManager.configPath)
+	if bot.drivers != nil && bot.apiServer != nil {
+		bot.apiServer.SetDriverDirectory(&driverDirectory{registry: bot.drivers})
+	}
+
+	// Optional database (bot.db next to config.json, or DATABASE_URL) for the server history and the audit log
+	historyEnabled := os.Getenv("HISTORY_ENABLED") == "true" && bot.features.enabled(featureHistory)
+	bot.db, err = databaseFromEnv(configManager.configPath, historyEnabled)
+	if err != nil {
+		log.Fatalf("Database configuration error: %v", err)
+	}
+	if bot.db != nil && bot.apiServer != nil {
+		if err := bot.apiServer.SetAuditStore(&auditStore{db: bot.db}); err != nil {
+			log.Fatalf("Database error: %v", err)
+		}
+	}
+
+	// Optional hourly server history: uptime, usage and heatmap reports via the API and a monthly post in bot mode
+	// The history feature flag turns it off along with the reports, whatever HISTORY_ENABLED says
+	if bot.features.enabled(featureHistory) {
+		bot.history, err = historyStoreFromEnv(configManager.configPath, bot.db)
+		if err != nil {
+			log.Fatalf("History configuration error: %v", err)
+		}
+		if bot.history != nil && bot.apiServer != nil {
+			bot.apiServer.SetSLAProvider(&slaProvider{bot: bot})
+			bot.apiServer.SetUsageProvider(&usageProvider{bot: bot})
+			bot.apiServer.SetHeatmapProvider(&heatmapProvider{bot: bot})
+		}
+		slaReports, err := slaReporterFromEnv(bot.history)
+		if err != nil {
+			log.Fatalf("Uptime report configuration error: %v", err)
+		}
+		if slaReports != nil && bot.discord == nil {
+			log.Fatalf("Uptime report configuration error: SLA_REPORT_ENABLED needs bot mode (DISCORD_TOKEN)")
+		}
+		bot.slaReports = slaReports
+	}
+
+	// Optional championship standings from the AC results folder
+	standings, err := standingsFromEnv(configManager.configPath)
+	if err != nil {
+		log.Fatalf("Standings configuration error: %v", err)
+	}
+	if standings != nil {
+		standings.drivers = bot.drivers
+	}
+	bot.standings = standings
+	if standings != nil && bot.apiServer != nil {
+		bot.apiServer.SetStandingsProvider(&standingsProvider{module: standings})
+		bot.apiServer.SetResultsIngester(&resultsIngester{bot: bot})
+	}
+
+	// Optional retention limits for personal and operational data, and purges on request via the API
+	bot.retention, err = retentionPolicyFromEnv()
+	if err != nil {
+		log.Fatalf("Retention configuration error: %v", err)
+	}
+	if bot.retention != nil && bot.retention.results > 0 && bot.standings == nil {
+		log.Fatalf("Retention configuration error: RESULTS_RETENTION_DAYS needs STANDINGS_RESULTS_DIR")
+	}
+	if bot.retention != nil && bot.retention.drivers > 0 && bot.drivers == nil {
+		log.Fatalf("Retention configuration error: DRIVER_RETENTION_DAYS needs DRIVER_REGISTRATION_ENABLED=true")
+	}
+	if bot.apiServer != nil {
+		bot.apiServer.SetDataPurger(&dataPurger{bot: bot, logs: logs})
+		bot.apiServer.SetStateArchiver(&stateArchiver{bot: bot})
+	}
+
+	// Optional scheduled upload of the backup archive to S3-compatible storage
+	bot.offsite, err = offsiteBackupFromEnv()
+	if err != nil {
+		log.Fatalf("Offsite backup configuration error: %v", err)
+	}
+	if bot.offsite != nil {
+		bot.offsite.archive = (&stateArchiver{bot: bot}).WriteStateBackup
+		bot.offsite.isLeader = bot.isLeader
+	}
+
+	// Report the Discord permission self-check (bot mode), the last offsite backup and new releases on /health
+	if bot.apiServer != nil && (bot.discord != nil || bot.offsite != nil || bot.versions != nil) {
+		bot.apiServer.SetHealthReporter(&botHealthReporter{bot: bot})
+	}
+
+	// Optional chaos injection (test only: random poll failures, slow polls and Discord edit errors)
+	chaos, err := chaosFromEnv()
+	if err != nil {
+		log.Fatalf("Chaos configuration error: %v", err)
+	}
+	pollClients.setChaos(chaos)
+	if bot.discord != nil {
+		bot.discord.session = chaos.wrapSession(bot.discord.session)
+	}
+	if webhook != nil {
+		webhook.api = chaos.wrapWebhookAPI(webhook.api)
+	}
+
+	// Graceful drain for API and proxy shutdown
+	drainTimeout, err := drainTimeoutFromEnv()
+	if err != nil {
+		log.Fatalf("Shutdown configuration error: %v", err)
+	}
+	bot.setDrainTimeout(drainTimeout)
+	if err := bot.listenersFromEnv(); err != nil {
+		log.Fatalf("Listener configuration error: %v", err)
+	}
+	bot.offline, err = offlineStatusFromEnv()
+	if err != nil {
+		log.Fatalf("Shutdown configuration error: %v", err)
 	}
 
 	bot.registerHandlers()
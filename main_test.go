@@ -1,13 +1,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/bombom/absa-ac/pkg/metrics"
 )
 
 // TestInitializeServerIPs_Normal tests that all servers get their IP set correctly
@@ -871,6 +878,138 @@ func TestCheckAndReloadIfNeeded_RapidChanges(t *testing.T) {
 	}
 }
 
+// writeConfigMapVersion writes cfg into a new ConfigMap-style version directory and points ..data at it
+// Layout matches a Kubernetes volume: config.json -> ..data/config.json, ..data -> ..<version>
+func writeConfigMapVersion(t *testing.T, dir, version string, cfg *Config, modTime time.Time) {
+	t.Helper()
+	versionDir := filepath.Join(dir, ".."+version)
+	if err := os.Mkdir(versionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(cfg)
+	file := filepath.Join(versionDir, "config.json")
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	// Atomic symlink swap, like the kubelet
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(".."+version, tmpLink); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpLink, filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "config.json")); os.IsNotExist(err) {
+		if err := os.Symlink(filepath.Join("..data", "config.json"), filepath.Join(dir, "config.json")); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestCheckAndReloadIfNeeded_ConfigMapSymlinkSwap tests reload when ..data is swapped to a file with an older mtime
+func TestCheckAndReloadIfNeeded_ConfigMapSymlinkSwap(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	now := time.Now()
+
+	initial := testStatusConfig()
+	writeConfigMapVersion(t, dir, "v1", initial, now)
+	cm := NewConfigManager(configPath, initial)
+
+	updated := testStatusConfig()
+	updated.ServerIP = "198.51.100.7"
+	writeConfigMapVersion(t, dir, "v2", updated, now.Add(-time.Hour))
+
+	if err := cm.checkAndReloadIfNeeded(); err != nil {
+		t.Fatalf("checkAndReloadIfNeeded failed: %v", err)
+	}
+	if got := cm.GetConfig().ServerIP; got != "198.51.100.7" {
+		t.Errorf("Expected reload after symlink swap, got ServerIP %s", got)
+	}
+	if !strings.HasSuffix(cm.lastTarget, filepath.Join("..v2", "config.json")) {
+		t.Errorf("Expected target to track ..v2, got %s", cm.lastTarget)
+	}
+}
+
+// TestCheckAndReloadIfNeeded_SameMtime tests that a content change is detected even when mtime is unchanged
+func TestCheckAndReloadIfNeeded_SameMtime(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	initial := testStatusConfig()
+	data, _ := json.Marshal(initial)
+	os.WriteFile(configPath, data, 0644)
+	info, _ := os.Stat(configPath)
+	cm := NewConfigManager(configPath, initial)
+
+	updated := testStatusConfig()
+	updated.UpdateInterval = 60
+	data, _ = json.Marshal(updated)
+	os.WriteFile(configPath, data, 0644)
+	os.Chtimes(configPath, info.ModTime(), info.ModTime())
+
+	if err := cm.checkAndReloadIfNeeded(); err != nil {
+		t.Fatalf("checkAndReloadIfNeeded failed: %v", err)
+	}
+	if cm.GetConfig().UpdateInterval != 60 {
+		t.Error("Expected reload despite unchanged mtime")
+	}
+}
+
+// TestCheckAndReloadIfNeeded_TouchOnly tests that touching the file or swapping to identical content does not reload
+func TestCheckAndReloadIfNeeded_TouchOnly(t *testing.T) {
+	dir := t.TempDir()
+	initial := testStatusConfig()
+	writeConfigMapVersion(t, dir, "v1", initial, time.Now().Add(-time.Hour))
+	cm := NewConfigManager(filepath.Join(dir, "config.json"), initial)
+
+	var changes atomic.Int32
+	cm.SetOnChange(func(_, _ *Config) { changes.Add(1) })
+
+	writeConfigMapVersion(t, dir, "v2", initial, time.Now())
+	if err := cm.checkAndReloadIfNeeded(); err != nil {
+		t.Fatalf("checkAndReloadIfNeeded failed: %v", err)
+	}
+	if changes.Load() != 0 {
+		t.Errorf("Expected no reload for identical content, got %d", changes.Load())
+	}
+	if cm.GetConfig() != initial {
+		t.Error("Expected the loaded config to be kept")
+	}
+}
+
+// TestCheckAndReloadIfNeeded_PartialFile tests that a truncated file keeps the old config and is retried
+func TestCheckAndReloadIfNeeded_PartialFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	initial := testStatusConfig()
+	data, _ := json.Marshal(initial)
+	os.WriteFile(configPath, data, 0644)
+	cm := NewConfigManager(configPath, initial)
+
+	updated := testStatusConfig()
+	updated.ServerIP = "198.51.100.7"
+	full, _ := json.Marshal(updated)
+	os.WriteFile(configPath, full[:len(full)/2], 0644)
+
+	if err := cm.checkAndReloadIfNeeded(); err == nil {
+		t.Fatal("Expected parse error for partial file")
+	}
+	if cm.GetConfig() != initial {
+		t.Fatal("Expected old config to be kept")
+	}
+
+	// Once the write completes the next check loads it
+	os.WriteFile(configPath, full, 0644)
+	if err := cm.checkAndReloadIfNeeded(); err != nil {
+		t.Fatalf("checkAndReloadIfNeeded failed: %v", err)
+	}
+	if cm.GetConfig().ServerIP != "198.51.100.7" {
+		t.Error("Expected reload after the write completed")
+	}
+}
+
 // TestNewConfigManager tests ConfigManager creation with valid config
 func TestNewConfigManager(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -2351,3 +2490,287 @@ func TestConfigManager_UpdateConfig_Normal(t *testing.T) {
 		t.Errorf("Should have 2 servers, got %d", len(cfg.Servers))
 	}
 }
+
+// TestConfigManager_WriteNoRedundantReload tests that API writes are applied once and not reloaded by the file watcher
+func TestConfigManager_WriteNoRedundantReload(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	initial := testStatusConfig()
+	data, _ := json.Marshal(initial)
+	os.WriteFile(configPath, data, 0644)
+	cm := NewConfigManager(configPath, initial)
+
+	var changes atomic.Int32
+	cm.SetOnChange(func(_, _ *Config) { changes.Add(1) })
+
+	updated := testStatusConfig()
+	updated.ServerIP = "198.51.100.7"
+	if err := cm.WriteConfig(updated); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+	if err := cm.UpdateConfig(map[string]interface{}{"update_interval": 90}); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+	written := cm.GetConfig()
+
+	info, _ := os.Stat(configPath)
+	if !cm.lastModTime.Equal(info.ModTime()) {
+		t.Errorf("Expected recorded mtime %v, got %v", info.ModTime(), cm.lastModTime)
+	}
+	if err := cm.checkAndReloadIfNeeded(); err != nil {
+		t.Fatalf("checkAndReloadIfNeeded failed: %v", err)
+	}
+	if changes.Load() != 2 || cm.GetConfig() != written {
+		t.Errorf("Expected exactly one swap per write and no reload, got %d swaps", changes.Load())
+	}
+
+	// An external edit after the write is still picked up
+	external := testStatusConfig()
+	external.UpdateInterval = 45
+	data, _ = json.Marshal(external)
+	os.WriteFile(configPath, data, 0644)
+	if err := cm.checkAndReloadIfNeeded(); err != nil {
+		t.Fatalf("checkAndReloadIfNeeded failed: %v", err)
+	}
+	if cm.GetConfig().UpdateInterval != 45 {
+		t.Error("Expected reload of the external edit")
+	}
+}
+
+// newTestBot creates a Bot with no Discord session for exercising update loop logic
+func newTestBot(cfg *Config) *Bot {
+	b := &Bot{configManager: NewConfigManager("/nonexistent/config.json", cfg)}
+	b.watchdog = NewWatchdog(defaultWatchdogStallIntervals, b.currentUpdateInterval)
+	b.lifecycle = NewLifecycle(context.Background())
+	return b
+}
+
+// TestTryPerformUpdate_SkipsWhenBusy tests that a tick arriving during an update is skipped and counted
+func TestTryPerformUpdate_SkipsWhenBusy(t *testing.T) {
+	b := newTestBot(nil)
+	skippedBefore := updateTicksSkipped.Value(metrics.ComponentDiscord)
+
+	b.updates.busy.Store(true)
+	if b.tryPerformUpdate() {
+		t.Error("Expected update to be skipped while busy")
+	}
+	if b.tryPerformUpdate() {
+		t.Error("Expected second update to be skipped while busy")
+	}
+	if got := b.updates.SkippedTicks(); got != 2 {
+		t.Errorf("Expected 2 skipped ticks, got %d", got)
+	}
+	if got := updateTicksSkipped.Value(metrics.ComponentDiscord) - skippedBefore; got != 2 {
+		t.Errorf("Expected absa_update_ticks_skipped_total to grow by 2, got %v", got)
+	}
+
+	b.updates.busy.Store(false)
+	if !b.tryPerformUpdate() {
+		t.Error("Expected update to run when not busy")
+	}
+	if b.updates.busy.Load() {
+		t.Error("Expected busy flag to be released after update")
+	}
+}
+
+// TestStartUpdateLoop_RefusesSecondStart tests that one bot never runs two update loops, via the lifecycle or directly
+func TestStartUpdateLoop_RefusesSecondStart(t *testing.T) {
+	b := newTestBot(nil)
+
+	if !b.launchUpdateLoop() {
+		t.Fatal("Expected first start to succeed")
+	}
+	deadline := time.Now().Add(time.Second)
+	for !b.updateLoopActive.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("Update loop did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if b.launchUpdateLoop() {
+		t.Error("Expected duplicate start via the lifecycle to be refused")
+	}
+
+	// A direct call bypassing the lifecycle returns immediately instead of running a second loop
+	done := make(chan error, 1)
+	go func() { done <- b.startUpdateLoop(context.Background()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected nil for a refused start, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Second update loop was started")
+	}
+
+	if err := b.lifecycle.Shutdown(time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if b.updateLoopActive.Load() {
+		t.Error("Expected the active flag to be released after shutdown")
+	}
+	if b.launchUpdateLoop() {
+		t.Error("Expected no start after shutdown")
+	}
+}
+
+// TestTryPerformUpdate_Concurrent tests that concurrent ticks never run overlapping updates
+func TestTryPerformUpdate_Concurrent(t *testing.T) {
+	b := newTestBot(nil)
+
+	var wg sync.WaitGroup
+	var ran atomic.Int32
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if b.tryPerformUpdate() {
+				ran.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if int(ran.Load())+int(b.updates.SkippedTicks()) != 50 {
+		t.Errorf("Expected ran+skipped == 50, got %d+%d", ran.Load(), b.updates.SkippedTicks())
+	}
+}
+
+// TestRecordUpdateDuration tests slow update counting and consecutive reset
+func TestRecordUpdateDuration(t *testing.T) {
+	b := newTestBot(nil)
+	interval := 10 * time.Second
+	slowBefore := updatesSlow.Value(metrics.ComponentDiscord)
+
+	b.recordUpdateDuration(11*time.Second, interval)
+	b.recordUpdateDuration(12*time.Second, interval)
+	if b.updates.consecutiveSlow != 2 {
+		t.Errorf("Expected 2 consecutive slow updates, got %d", b.updates.consecutiveSlow)
+	}
+
+	b.recordUpdateDuration(5*time.Second, interval)
+	if b.updates.consecutiveSlow != 0 {
+		t.Errorf("Expected consecutive count reset after fast update, got %d", b.updates.consecutiveSlow)
+	}
+	if got := b.updates.SlowUpdates(); got != 2 {
+		t.Errorf("Expected 2 slow updates total, got %d", got)
+	}
+	if got := updatesSlow.Value(metrics.ComponentDiscord) - slowBefore; got != 2 {
+		t.Errorf("Expected absa_update_slow_total to grow by 2, got %v", got)
+	}
+}
+
+// TestConfigClone tests that a clone shares no slices, maps or pointers with the original
+func TestConfigClone(t *testing.T) {
+	orig := testStatusConfig()
+	orig.Servers = []Server{{Name: "Drift 1", Port: 8081, Category: "Drift"}}
+	orig.HTTPClient = &HTTPClientConfig{TimeoutSeconds: 3}
+	orig.Alerts = &AlertsConfig{
+		Routes:     []AlertRoute{{Events: []string{alertServerDown}, Status: true, QuietHours: &QuietHours{Start: "22:00", End: "07:00"}}},
+		Escalation: &EscalationConfig{Steps: []EscalationStep{{After: "5m", Status: true}}},
+	}
+	orig.Events = []RaceEvent{{Name: "Cup", Server: "Drift 1", Start: "2026-10-18T18:00:00Z"}}
+	orig.ServerFiles = &ServerFilesConfig{
+		Templates: map[string]string{"cfg": "NAME={{.Server.Name}}"},
+		Servers:   map[string]ServerFileSet{"Drift 1": {Target: "https://hooks.example.com", Files: map[string]string{"server_cfg.ini": "cfg"}}},
+	}
+	orig.Pterodactyl = map[string]PterodactylServer{"Drift 1": {PanelURL: "https://panel.example.com", ServerID: "1a2b3c4d", APIKey: "ptlc_test"}}
+
+	clone := orig.Clone()
+	if !reflect.DeepEqual(clone, orig) {
+		t.Fatalf("Expected an equal copy, got %+v", clone)
+	}
+	clone.CategoryOrder[0] = "Changed"
+	clone.CategoryEmojis["Drift"] = "x"
+	clone.Servers[0].Name = "Changed"
+	clone.HTTPClient.TimeoutSeconds = 9
+	clone.Alerts.Routes[0].Events[0] = alertServerUp
+	clone.Alerts.Routes[0].QuietHours.Start = "23:00"
+	clone.Alerts.Escalation.Steps[0].After = "1m"
+	clone.Events[0].Name = "Changed"
+	clone.ServerFiles.Servers["Drift 1"].Files["server_cfg.ini"] = "other"
+	if orig.ServerFiles.Servers["Drift 1"].Files["server_cfg.ini"] != "cfg" {
+		t.Error("Expected the original server_files unchanged")
+	}
+	clone.Pterodactyl["Drift 1"] = PterodactylServer{}
+	if orig.Pterodactyl["Drift 1"].APIKey != "ptlc_test" {
+		t.Error("Expected the original pterodactyl section unchanged")
+	}
+	if orig.CategoryOrder[0] != "Drift" || orig.CategoryEmojis["Drift"] != "🟣" || orig.Servers[0].Name != "Drift 1" || orig.HTTPClient.TimeoutSeconds != 3 ||
+		orig.Alerts.Routes[0].Events[0] != alertServerDown || orig.Alerts.Routes[0].QuietHours.Start != "22:00" ||
+		orig.Alerts.Escalation.Steps[0].After != "5m" || orig.Events[0].Name != "Cup" {
+		t.Errorf("Expected the original unchanged, got %+v", orig)
+	}
+
+	var none *Config
+	if none.Clone() != nil {
+		t.Error("Expected nil for a nil config")
+	}
+}
+
+// TestConfigManager_CopyOnWrite tests that writers and API readers never share the stored snapshot
+func TestConfigManager_CopyOnWrite(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	cm := NewConfigManager(configPath, nil)
+
+	next := testStatusConfig()
+	next.Servers = []Server{{Name: "Drift 1", Port: 8081, Category: "Drift"}}
+	if err := cm.WriteConfig(next); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+	if next.Servers[0].IP != "" {
+		t.Error("Expected WriteConfig to leave the caller's config unmodified")
+	}
+	next.Servers[0].Name = "Edited later"
+	stored := cm.GetConfig()
+	if stored == next || stored.Servers[0].Name != "Drift 1" || stored.Servers[0].IP != next.ServerIP {
+		t.Errorf("Expected a private snapshot with server IPs set, got %+v", stored.Servers[0])
+	}
+
+	apiCopy := cm.GetConfigAny().(*Config)
+	apiCopy.Servers[0].Port = 1
+	apiCopy.CategoryEmojis["Drift"] = "x"
+	if stored.Servers[0].Port != 8081 || stored.CategoryEmojis["Drift"] != "🟣" {
+		t.Error("Expected changes to the API copy not to reach the stored snapshot")
+	}
+}
+
+// TestConfigManager_ConcurrentReadersAndWrites tests readers walking snapshots while writes reuse one struct (run with -race)
+func TestConfigManager_ConcurrentReadersAndWrites(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	next := testStatusConfig()
+	next.Servers = []Server{{Name: "Drift 1", Port: 8081, Category: "Drift"}}
+	cm := NewConfigManager(configPath, next.Clone())
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				cfg := cm.GetConfig()
+				for _, s := range cfg.Servers {
+					_ = s.Name + s.IP
+				}
+				_ = cfg.CategoryEmojis["Drift"]
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		next.Servers[0].Port = 8081 + i
+		next.CategoryEmojis["Drift"] = strconv.Itoa(i)
+		if err := cm.WriteConfig(next); err != nil {
+			t.Fatalf("WriteConfig failed: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+	if got := cm.GetConfig().Servers[0].Port; got != 8100 {
+		t.Errorf("Expected the last write to win, got port %d", got)
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= MAINTENANCE MODE =================
+
+const (
+	// defaultMaintenanceTTL is how long maintenance mode lasts unless MAINTENANCE_TTL or the request says otherwise
+	defaultMaintenanceTTL = 2 * time.Hour
+	// maxMaintenanceTTL bounds a single maintenance window, so a forgotten flag cannot hide an outage for long
+	maxMaintenanceTTL = 7 * 24 * time.Hour
+	// maintenanceReasonMax bounds the reason shown in the embed
+	maintenanceReasonMax = 100
+)
+
+// maintenanceEntry is a server in maintenance mode
+type maintenanceEntry struct {
+	server string
+	reason string
+	setBy  string
+	since  time.Time
+	until  time.Time
+}
+
+// maintenanceRegistry holds the runtime maintenance flags by server name (every server with the name)
+// Flags are not saved in the config and are lost on restart; each one clears itself after its TTL
+// The zero value is ready to use with defaultMaintenanceTTL
+type maintenanceRegistry struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	servers map[string]maintenanceEntry
+}
+
+// defaultTTL returns the TTL used when a request gives none
+func (m *maintenanceRegistry) defaultTTL() time.Duration {
+	if m.ttl > 0 {
+		return m.ttl
+	}
+	return defaultMaintenanceTTL
+}
+
+// set flags server until now+ttl (0 = default TTL), replacing an existing flag
+func (m *maintenanceRegistry) set(server, reason, setBy string, ttl time.Duration, now time.Time) maintenanceEntry {
+	if ttl <= 0 {
+		ttl = m.defaultTTL()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.servers == nil {
+		m.servers = make(map[string]maintenanceEntry)
+	}
+	e := maintenanceEntry{server: server, reason: reason, setBy: setBy, since: now, until: now.Add(ttl)}
+	m.servers[server] = e
+	return e
+}
+
+// clear removes the flag and reports whether the server had one
+func (m *maintenanceRegistry) clear(server string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.servers[server]
+	delete(m.servers, server)
+	return ok
+}
+
+// expire removes flags whose TTL passed at now, or whose server is not in names (removed from the config)
+// and returns them
+func (m *maintenanceRegistry) expire(now time.Time, names map[string]bool) []maintenanceEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var expired []maintenanceEntry
+	for name, e := range m.servers {
+		if !now.Before(e.until) || !names[name] {
+			expired = append(expired, e)
+			delete(m.servers, name)
+		}
+	}
+	return expired
+}
+
+// list returns the active flags sorted by server name
+func (m *maintenanceRegistry) list(now time.Time) []maintenanceEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []maintenanceEntry
+	for _, e := range m.servers {
+		if now.Before(e.until) {
+			out = append(out, e)
+		}
+	}
+	slices.SortFunc(out, func(a, b maintenanceEntry) int { return strings.Compare(a.server, b.server) })
+	return out
+}
+
+// active returns the flag of server if it is in maintenance mode at now
+func (m *maintenanceRegistry) active(server string, now time.Time) (maintenanceEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.servers[server]
+	return e, ok && now.Before(e.until)
+}
+
+// markMaintenance clears expired flags and marks poll results of servers in maintenance mode
+func (b *Bot) markMaintenance(infos []ServerInfo, cfg *Config, now time.Time) []ServerInfo {
+	names := make(map[string]bool, len(cfg.Servers))
+	for _, s := range cfg.Servers {
+		names[s.Name] = true
+	}
+	for _, e := range b.maintenance.expire(now, names) {
+		log.Printf("🔧 Maintenance mode for '%s' ended (TTL expired or server removed)", e.server)
+	}
+	for i := range infos {
+		if e, ok := b.maintenance.active(infos[i].Name, now); ok {
+			infos[i].Maintenance = &e
+		}
+	}
+	return infos
+}
+
+// setMaintenance puts every server named server in maintenance mode and refreshes the status right away
+func (b *Bot) setMaintenance(server string, ttl time.Duration, reason, setBy string) (maintenanceEntry, error) {
+	cfg := b.configManager.GetConfig()
+	if cfg == nil || !slices.ContainsFunc(cfg.Servers, func(s Server) bool { return s.Name == server }) {
+		return maintenanceEntry{}, fmt.Errorf("%w '%s'", api.ErrUnknownServer, server)
+	}
+	if ttl > maxMaintenanceTTL {
+		return maintenanceEntry{}, fmt.Errorf("ttl %v exceeds the maximum of %v", ttl, maxMaintenanceTTL)
+	}
+	if r := []rune(reason); len(r) > maintenanceReasonMax {
+		return maintenanceEntry{}, fmt.Errorf("reason is longer than %d characters", maintenanceReasonMax)
+	}
+	e := b.maintenance.set(server, reason, setBy, ttl, time.Now())
+	log.Printf("🔧 Maintenance mode for '%s' until %s (set by %s)", server, e.until.UTC().Format(time.RFC3339), setBy)
+	b.requestRefresh()
+	return e, nil
+}
+
+// clearMaintenance ends maintenance mode for server and refreshes the status right away
+func (b *Bot) clearMaintenance(server, clearedBy string) bool {
+	if !b.maintenance.clear(server) {
+		return false
+	}
+	log.Printf("🔧 Maintenance mode for '%s' cleared by %s", server, clearedBy)
+	b.requestRefresh()
+	return true
+}
+
+// maintenanceTTLFromEnv reads the default maintenance TTL from MAINTENANCE_TTL (default 2h)
+func maintenanceTTLFromEnv() (time.Duration, error) {
+	v := os.Getenv("MAINTENANCE_TTL")
+	if v == "" {
+		return defaultMaintenanceTTL, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 || d > maxMaintenanceTTL {
+		return 0, fmt.Errorf("invalid MAINTENANCE_TTL %q: must be a duration between 1s and %v, e.g. 2h", v, maxMaintenanceTTL)
+	}
+	return d, nil
+}
+
+// maintenanceController adapts the bot to api.MaintenanceController
+type maintenanceController struct {
+	bot *Bot
+}
+
+// ListMaintenance implements api.MaintenanceController
+func (c *maintenanceController) ListMaintenance() []api.MaintenanceEntry {
+	var out []api.MaintenanceEntry
+	for _, e := range c.bot.maintenance.list(time.Now()) {
+		out = append(out, e.apiEntry())
+	}
+	return out
+}
+
+// SetMaintenance implements api.MaintenanceController
+func (c *maintenanceController) SetMaintenance(server string, ttl time.Duration, reason, setBy string) (api.MaintenanceEntry, error) {
+	e, err := c.bot.setMaintenance(server, ttl, reason, setBy)
+	if err != nil {
+		return api.MaintenanceEntry{}, err
+	}
+	return e.apiEntry(), nil
+}
+
+// ClearMaintenance implements api.MaintenanceController
+func (c *maintenanceController) ClearMaintenance(server string) bool {
+	return c.bot.clearMaintenance(server, "api")
+}
+
+func (e maintenanceEntry) apiEntry() api.MaintenanceEntry {
+	return api.MaintenanceEntry{Server: e.server, Reason: e.reason, SetBy: e.setBy, Since: e.since.UTC(), Until: e.until.UTC()}
+}
+
+// maintenanceCommand is /maintenance on|off|list, limited to members with Manage Server by default
+func maintenanceCommand() *slashCommand {
+	manageGuild := int64(discordgo.PermissionManageGuild)
+	serverOption := &discordgo.ApplicationCommandOption{
+		Type: discordgo.ApplicationCommandOptionString, Name: "server", Description: "Server name",
+		Required: true, Autocomplete: true,
+	}
+	return &slashCommand{
+		def: &discordgo.ApplicationCommand{
+			Name:                     "maintenance",
+			Description:              "Flag a game server as under maintenance (wrench in the status, no alerts)",
+			DefaultMemberPermissions: &manageGuild,
+			Contexts:                 &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild},
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type: discordgo.ApplicationCommandOptionSubCommand, Name: "on", Description: "Start maintenance mode",
+					Options: []*discordgo.ApplicationCommandOption{
+						serverOption,
+						{Type: discordgo.ApplicationCommandOptionString, Name: "duration", Description: "How long, e.g. 90m (default 2h)"},
+						{Type: discordgo.ApplicationCommandOptionString, Name: "reason", Description: "Shown in the status", MaxLength: maintenanceReasonMax},
+					},
+				},
+				{
+					Type: discordgo.ApplicationCommandOptionSubCommand, Name: "off", Description: "End maintenance mode",
+					Options: []*discordgo.ApplicationCommandOption{serverOption},
+				},
+				{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "list", Description: "Servers in maintenance mode"},
+			},
+		},
+		run:      runMaintenanceCommand,
+		complete: completeServerName,
+	}
+}
+
+// runMaintenanceCommand handles /maintenance
+func runMaintenanceCommand(b *Bot, user invoker, opts []*discordgo.ApplicationCommandInteractionDataOption) string {
+	if len(opts) != 1 {
+		return "Unknown subcommand"
+	}
+	sub := opts[0]
+	server := optionString(sub.Options, "server")
+	switch sub.Name {
+	case "on":
+		var ttl time.Duration
+		if v := optionString(sub.Options, "duration"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return fmt.Sprintf("❌ Invalid duration %q, use something like 90m or 2h", v)
+			}
+			ttl = d
+		}
+		e, err := b.setMaintenance(server, ttl, optionString(sub.Options, "reason"), user.name)
+		if err != nil {
+			return "❌ " + err.Error()
+		}
+		return fmt.Sprintf("🔧 **%s** is in maintenance mode until <t:%d:t>", server, e.until.Unix())
+	case "off":
+		if !b.clearMaintenance(server, user.name) {
+			return fmt.Sprintf("**%s** is not in maintenance mode", server)
+		}
+		return fmt.Sprintf("✅ Maintenance mode for **%s** ended", server)
+	case "list":
+		entries := b.maintenance.list(time.Now())
+		if len(entries) == 0 {
+			return "No servers in maintenance mode"
+		}
+		var sb strings.Builder
+		for _, e := range entries {
+			fmt.Fprintf(&sb, "🔧 **%s** until <t:%d:t> (by %s)", e.server, e.until.Unix(), e.setBy)
+			if e.reason != "" {
+				sb.WriteString(" — " + e.reason)
+			}
+			sb.WriteByte('\n')
+		}
+		return sb.String()
+	}
+	return "Unknown subcommand"
+}
+
+// completeServerName suggests configured server names containing the typed text (Discord shows at most 25)
+func completeServerName(b *Bot, focused *discordgo.ApplicationCommandInteractionDataOption) []*discordgo.ApplicationCommandOptionChoice {
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	cfg := b.configManager.GetConfig()
+	if cfg == nil {
+		return choices
+	}
+	typed := strings.ToLower(focused.StringValue())
+	seen := make(map[string]bool)
+	for _, s := range cfg.Servers {
+		if seen[s.Name] || !strings.Contains(strings.ToLower(s.Name), typed) {
+			continue
+		}
+		seen[s.Name] = true
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: s.Name, Value: s.Name})
+		if len(choices) == 25 {
+			break
+		}
+	}
+	return choices
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// testMaintenanceConfig has one Drift and one Track server
+func testMaintenanceConfig() *Config {
+	cfg := testStatusConfig()
+	cfg.Servers = []Server{
+		{Name: "Drift #1", Category: "Drift", Port: 8081},
+		{Name: "Track #1", Category: "Track", Port: 8082},
+	}
+	return cfg
+}
+
+// TestMaintenanceRegistry tests default TTL, expiry and forgetting removed servers
+func TestMaintenanceRegistry(t *testing.T) {
+	var m maintenanceRegistry
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	names := map[string]bool{"Drift #1": true, "Track #1": true}
+
+	if e := m.set("Drift #1", "new layout", "api", 0, now); !e.until.Equal(now.Add(defaultMaintenanceTTL)) {
+		t.Errorf("Expected the default TTL, got until %v", e.until)
+	}
+	m.ttl = 30 * time.Minute
+	m.set("Track #1", "", "admin", 0, now)
+	if got := m.list(now); len(got) != 2 || got[0].server != "Drift #1" || got[1].until != now.Add(30*time.Minute) {
+		t.Fatalf("Expected both flags sorted by name, got %+v", got)
+	}
+
+	if expired := m.expire(now.Add(time.Hour), names); len(expired) != 1 || expired[0].server != "Track #1" {
+		t.Errorf("Expected Track #1 expired, got %+v", expired)
+	}
+	if _, ok := m.active("Drift #1", now.Add(time.Hour)); !ok {
+		t.Error("Expected Drift #1 still in maintenance")
+	}
+	if expired := m.expire(now.Add(time.Hour), map[string]bool{}); len(expired) != 1 {
+		t.Errorf("Expected the removed server's flag dropped, got %+v", expired)
+	}
+	if m.clear("Drift #1") {
+		t.Error("Expected nothing to clear")
+	}
+}
+
+// TestBotMaintenance tests setting flags, marking poll results, rendering and clearing
+func TestBotMaintenance(t *testing.T) {
+	cfg := testMaintenanceConfig()
+	b := newTestBot(cfg)
+	b.refresh = make(chan struct{}, 1)
+
+	if _, err := b.setMaintenance("Nope", 0, "", "api"); !errors.Is(err, api.ErrUnknownServer) {
+		t.Errorf("Expected ErrUnknownServer, got %v", err)
+	}
+	if _, err := b.setMaintenance("Drift #1", maxMaintenanceTTL+time.Hour, "", "api"); err == nil {
+		t.Error("Expected a TTL above the maximum to be rejected")
+	}
+	if _, err := b.setMaintenance("Drift #1", time.Hour, "new layout", "api"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-b.refresh:
+	default:
+		t.Error("Expected a status refresh")
+	}
+
+	infos := b.markMaintenance([]ServerInfo{
+		{Name: "Drift #1", Category: "Drift", Map: "ebisu", Players: "0/16", NumPlayers: 0, MaxPlayers: 16, Port: 8081},
+		{Name: "Track #1", Category: "Track", Map: "spa", Players: "3/20", NumPlayers: 3, MaxPlayers: 20, Port: 8082},
+	}, cfg, time.Now())
+	if infos[0].Maintenance == nil || infos[1].Maintenance != nil {
+		t.Fatalf("Expected only Drift #1 marked, got %+v", infos)
+	}
+
+	embed := statusEmbed(infos, cfg, false)
+	var field *discordgo.MessageEmbedField
+	for _, f := range embed.Fields {
+		if strings.Contains(f.Name, "Drift #1") {
+			field = f
+		}
+	}
+	if field == nil || !strings.HasPrefix(field.Name, ":wrench: ") || !strings.Contains(field.Value, "*Maintenance until ") || !strings.Contains(field.Value, "new layout") {
+		t.Errorf("Expected a wrench and the maintenance note, got %+v", field)
+	}
+	srv := buildStatusSnapshot(infos, cfg, time.Now()).Categories[0].Servers[0]
+	if !srv.Maintenance || srv.MaintenanceUntil == nil || !strings.HasSuffix(srv.MaintenanceNote(), "— new layout") {
+		t.Errorf("Expected maintenance in the status JSON, got %+v", srv)
+	}
+
+	if !b.clearMaintenance("Drift #1", "admin") || b.clearMaintenance("Drift #1", "admin") {
+		t.Error("Expected the flag cleared exactly once")
+	}
+}
+
+// TestMaintenanceSuppressesAlerts tests that no outage alerts, escalation or incidents fire in maintenance mode
+func TestMaintenanceSuppressesAlerts(t *testing.T) {
+	var r alertRouter
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	esc := &EscalationConfig{Steps: []EscalationStep{{After: "5m", Status: true}}}
+	online := ServerInfo{Name: "Track #1", Category: "Track", Port: 8082, NumPlayers: 3}
+	offline := online
+	offline.NumPlayers = -1
+	maintained := offline
+	maintained.Maintenance = &maintenanceEntry{server: "Track #1"}
+
+	r.trackOutages([]ServerInfo{online}, esc, now)
+	for i := 1; i <= 3; i++ {
+		if events, notices := r.trackOutages([]ServerInfo{maintained}, esc, now.Add(time.Duration(i)*10*time.Minute)); len(events) != 0 || len(notices) != 0 {
+			t.Fatalf("Expected no alerts in maintenance, got %+v %+v", events, notices)
+		}
+	}
+	// Still down when maintenance ends: the outage is reported from then on
+	if events, _ := r.trackOutages([]ServerInfo{offline}, esc, now.Add(time.Hour)); len(events) != 1 || events[0].Type != alertServerDown {
+		t.Errorf("Expected a server_down alert after maintenance, got %+v", events)
+	}
+
+	b := newTestBot(testStatusConfig())
+	rec := &recordingNotifier{}
+	b.incidents = newIncidentManager(rec)
+	b.checkAllServersDown([]ServerInfo{maintained, {Name: "Drift #1", Port: 8081, NumPlayers: -1}})
+	b.checkAllServersDown([]ServerInfo{maintained})
+	if n, _ := rec.counts(); n != 1 || rec.triggered[0].Summary != "All 1 game servers are down" {
+		t.Errorf("Expected one incident counting only servers outside maintenance, got %+v", rec.triggered)
+	}
+	if _, n := rec.counts(); n != 1 {
+		t.Errorf("Expected the incident resolved with every server in maintenance, got %v", rec.resolved)
+	}
+}
+
+// TestMaintenanceCommand tests /maintenance on, list and off
+func TestMaintenanceCommand(t *testing.T) {
+	b := newTestBot(testMaintenanceConfig())
+	str := func(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+	}
+	sub := func(name string, opts ...*discordgo.ApplicationCommandInteractionDataOption) []*discordgo.ApplicationCommandInteractionDataOption {
+		return []*discordgo.ApplicationCommandInteractionDataOption{{Name: name, Type: discordgo.ApplicationCommandOptionSubCommand, Options: opts}}
+	}
+
+	if reply := runMaintenanceCommand(b, invoker{name: "admin"}, sub("on", str("server", "Track #1"), str("duration", "soon"))); !strings.Contains(reply, "Invalid duration") {
+		t.Errorf("Expected an invalid duration reply, got %q", reply)
+	}
+	if reply := runMaintenanceCommand(b, invoker{name: "admin"}, sub("on", str("server", "Track #1"), str("duration", "45m"), str("reason", "kerbs"))); !strings.HasPrefix(reply, "🔧 **Track #1**") {
+		t.Errorf("Unexpected reply %q", reply)
+	}
+	if e, ok := b.maintenance.active("Track #1", time.Now()); !ok || e.setBy != "admin" || e.reason != "kerbs" || time.Until(e.until) > 45*time.Minute {
+		t.Errorf("Unexpected flag %+v", e)
+	}
+	if reply := runMaintenanceCommand(b, invoker{name: "admin"}, sub("list")); !strings.Contains(reply, "**Track #1**") || !strings.Contains(reply, "kerbs") {
+		t.Errorf("Unexpected list %q", reply)
+	}
+	if reply := runMaintenanceCommand(b, invoker{name: "admin"}, sub("off", str("server", "Track #1"))); !strings.HasPrefix(reply, "✅") {
+		t.Errorf("Unexpected reply %q", reply)
+	}
+	if reply := runMaintenanceCommand(b, invoker{name: "admin"}, sub("list")); reply != "No servers in maintenance mode" {
+		t.Errorf("Unexpected list %q", reply)
+	}
+
+	choices := completeServerName(b, str("server", "track"))
+	if len(choices) != 1 || choices[0].Value != "Track #1" {
+		t.Errorf("Expected Track #1 suggested, got %+v", choices)
+	}
+}
+
+func TestMaintenanceTTLFromEnv(t *testing.T) {
+	t.Setenv("MAINTENANCE_TTL", "")
+	if d, err := maintenanceTTLFromEnv(); err != nil || d != defaultMaintenanceTTL {
+		t.Errorf("Unset: got %v, %v", d, err)
+	}
+	t.Setenv("MAINTENANCE_TTL", "45m")
+	if d, err := maintenanceTTLFromEnv(); err != nil || d != 45*time.Minute {
+		t.Errorf("45m: got %v, %v", d, err)
+	}
+	for _, v := range []string{"soon", "-1h", "400h"} {
+		t.Setenv("MAINTENANCE_TTL", v)
+		if _, err := maintenanceTTLFromEnv(); err == nil {
+			t.Errorf("Expected %q to be rejected", v)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ================= MATRIX =================
+
+const (
+	// matrixStateFile stores the event ID of the status message next to config.json
+	matrixStateFile = "matrix_event_id"
+	// matrixRequestTimeout bounds each client-server API call
+	matrixRequestTimeout = 10 * time.Second
+)
+
+// MatrixPublisher posts a status message to a Matrix room and keeps it current with m.replace edits
+// Clients such as Element show only the latest edit, so the room sees a single updating message
+type MatrixPublisher struct {
+	homeserver string
+	token      string
+	roomID     string
+	title      string
+	statePath  string
+	client     *http.Client
+
+	txn atomic.Uint64
+
+	mu      sync.Mutex
+	eventID string
+}
+
+// NewMatrixPublisher creates a publisher for roomID on homeserver using an access token
+// statePath persists the status event ID across restarts (empty disables persistence)
+func NewMatrixPublisher(homeserver, token, roomID, title, statePath string) *MatrixPublisher {
+	if title == "" {
+		title = defaultStatusTitle
+	}
+	p := &MatrixPublisher{
+		homeserver: strings.TrimRight(homeserver, "/"),
+		token:      token,
+		roomID:     roomID,
+		title:      title,
+		statePath:  statePath,
+		client:     &http.Client{Timeout: matrixRequestTimeout},
+	}
+	p.loadEventID()
+	return p
+}
+
+// Name implements Publisher
+func (p *MatrixPublisher) Name() string {
+	return "Matrix"
+}
+
+// loadEventID restores the status event ID from the state file
+func (p *MatrixPublisher) loadEventID() {
+	if p.statePath == "" {
+		return
+	}
+	data, err := os.ReadFile(p.statePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Warning: failed to read Matrix state %s: %v", p.statePath, err)
+		}
+		return
+	}
+	p.eventID = strings.TrimSpace(string(data))
+}
+
+// saveEventID persists the status event ID (failure only costs a duplicate message after restart)
+func (p *MatrixPublisher) saveEventID(id string) {
+	if p.statePath == "" {
+		return
+	}
+	if err := os.WriteFile(p.statePath, []byte(id+"\n"), 0600); err != nil {
+		log.Printf("Warning: failed to save Matrix state %s: %v", p.statePath, err)
+	}
+}
+
+// matrixError is a client-server API error response
+type matrixError struct {
+	Status  int
+	ErrCode string `json:"errcode"`
+	Message string `json:"error"`
+}
+
+func (e *matrixError) Error() string {
+	return fmt.Sprintf("matrix request failed: HTTP %d %s: %s", e.Status, e.ErrCode, e.Message)
+}
+
+// sendMessage sends an m.room.message event and returns its event ID
+func (p *MatrixPublisher) sendMessage(content map[string]any) (string, error) {
+	return p.roomPut("send/m.room.message", content)
+}
+
+// redact removes the content of eventID from the room
+func (p *MatrixPublisher) redact(eventID string) error {
+	_, err := p.roomPut("redact/"+url.PathEscape(eventID), map[string]any{"reason": "status message removed"})
+	return err
+}
+
+// roomPut issues a transactional PUT /rooms/{roomId}/{action}/{txnId} and returns the resulting event ID
+// Each call uses a fresh transaction ID so retries after a restart are not deduplicated away
+func (p *MatrixPublisher) roomPut(action string, content map[string]any) (string, error) {
+	body, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode Matrix event: %w", err)
+	}
+
+	txnID := fmt.Sprintf("absa-%d-%d", time.Now().UnixNano(), p.txn.Add(1))
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/%s/%s",
+		p.homeserver, url.PathEscape(p.roomID), action, url.PathEscape(txnID))
+
+	ctx, cancel := context.WithTimeout(context.Background(), matrixRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Matrix request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("matrix request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		merr := &matrixError{Status: resp.StatusCode}
+		json.NewDecoder(resp.Body).Decode(merr)
+		return "", merr
+	}
+
+	var out struct {
+		EventID string `json:"event_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode Matrix response: %w", err)
+	}
+	return out.EventID, nil
+}
+
+// UpdateStatus implements Publisher: edits the status event, posting a new one if none exists yet
+func (p *MatrixPublisher) UpdateStatus(u *StatusUpdate) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	content := renderMatrixMessage(u.Snapshot, p.title)
+
+	if p.eventID != "" {
+		edit := map[string]any{
+			"msgtype":        content["msgtype"],
+			"body":           "* " + content["body"].(string),
+			"format":         content["format"],
+			"formatted_body": content["formatted_body"],
+			"m.new_content":  content,
+			"m.relates_to": map[string]any{
+				"rel_type": "m.replace",
+				"event_id": p.eventID,
+			},
+		}
+		if _, err := p.sendMessage(edit); err != nil {
+			return fmt.Errorf("failed to edit Matrix status message: %w", err)
+		}
+		return nil
+	}
+
+	eventID, err := p.sendMessage(content)
+	if err != nil {
+		return fmt.Errorf("failed to post Matrix status message: %w", err)
+	}
+	p.eventID = eventID
+	p.saveEventID(eventID)
+	log.Println("Initial Matrix status message posted")
+	return nil
+}
+
+// DeleteStatus implements Publisher: redacts the status event and forgets its ID
+func (p *MatrixPublisher) DeleteStatus() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.eventID == "" {
+		return nil
+	}
+	if err := p.redact(p.eventID); err != nil {
+		return fmt.Errorf("failed to redact Matrix status message: %w", err)
+	}
+	p.eventID = ""
+	if p.statePath != "" {
+		if err := os.Remove(p.statePath); err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: failed to remove Matrix state %s: %v", p.statePath, err)
+		}
+	}
+	return nil
+}
+
+// SendAlert implements Publisher
+func (p *MatrixPublisher) SendAlert(msg string) error {
+	if _, err := p.sendMessage(map[string]any{"msgtype": "m.notice", "body": msg}); err != nil {
+		return fmt.Errorf("failed to send Matrix alert: %w", err)
+	}
+	return nil
+}
+
+// renderMatrixMessage builds an m.notice (no notifications for bots) with plain text and HTML bodies
+func renderMatrixMessage(snap *StatusSnapshot, title string) map[string]any {
+	var text, htm strings.Builder
+
+	fmt.Fprintf(&text, "%s\n", title)
+	fmt.Fprintf(&htm, "<h3>%s</h3>", html.EscapeString(title))
+	if snap.Offline != nil {
+		fmt.Fprintf(&text, "🔴 %s\n", snap.Offline.Text())
+		fmt.Fprintf(&htm, "<p>🔴 <b>%s</b></p>", html.EscapeString(snap.Offline.Text()))
+	}
+	// An offline notice without server data shows no player count
+	if snap.Offline == nil || len(snap.Categories) > 0 {
+		fmt.Fprintf(&text, "Total Players: %d\n", snap.TotalPlayers)
+		fmt.Fprintf(&htm, "<p>👤 <b>Total Players:</b> %d</p>", snap.TotalPlayers)
+	}
+
+	for _, cat := range snap.Categories {
+		fmt.Fprintf(&text, "\n%s %s Servers — %d players\n", cat.Emoji, cat.Name, cat.Players)
+		fmt.Fprintf(&htm, "<p><b>%s %s Servers — %d players</b></p><ul>",
+			html.EscapeString(cat.Emoji), html.EscapeString(cat.Name), cat.Players)
+
+		for _, srv := range cat.Servers {
+			if note := srv.MaintenanceNote(); note != "" && !srv.Online {
+				fmt.Fprintf(&text, "🔧 %s — %s\n", srv.Name, note)
+				fmt.Fprintf(&htm, "<li>🔧 %s — %s</li>", html.EscapeString(srv.Name), html.EscapeString(note))
+				continue
+			}
+			if !srv.Online {
+				fmt.Fprintf(&text, "🔴 %s — offline\n", srv.Name)
+				fmt.Fprintf(&htm, "<li>🔴 %s — offline</li>", html.EscapeString(srv.Name))
+				continue
+			}
+			dot, note := "🟢", ""
+			if age := srv.StaleAge(snap.UpdatedAt); age != "" {
+				dot, note = "⚪", fmt.Sprintf(" — data %s old", age)
+			}
+			if m := srv.MaintenanceNote(); m != "" {
+				dot, note = "🔧", note+" — "+m
+			}
+			fmt.Fprintf(&text, "%s %s — %s — %s%s\n", dot, srv.Name, srv.Map, srv.PlayerCount(), note)
+			fmt.Fprintf(&htm, "<li>%s %s — %s — %s%s", dot, html.EscapeString(srv.Name), html.EscapeString(srv.Map), srv.PlayerCount(), html.EscapeString(note))
+			if srv.JoinURL != "" {
+				fmt.Fprintf(&htm, ` — <a href="%s">Join</a>`, html.EscapeString(srv.JoinURL))
+			}
+			htm.WriteString("</li>")
+		}
+		htm.WriteString("</ul>")
+	}
+
+	updated := snap.UpdatedAt.Format("2006-01-02 15:04:05 MST")
+	fmt.Fprintf(&text, "\nUpdated %s", updated)
+	fmt.Fprintf(&htm, "<p><sub>Updated %s</sub></p>", updated)
+
+	return map[string]any{
+		"msgtype":        "m.notice",
+		"body":           text.String(),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": htm.String(),
+	}
+}
+
+// matrixPublisherFromEnv returns a publisher if MATRIX_HOMESERVER_URL, MATRIX_ACCESS_TOKEN and MATRIX_ROOM_ID are set
+// Returns nil if none are set; the event ID state file is stored next to configPath
+func matrixPublisherFromEnv(configPath string) (*MatrixPublisher, error) {
+	homeserver := os.Getenv("MATRIX_HOMESERVER_URL")
+	token := os.Getenv("MATRIX_ACCESS_TOKEN")
+	roomID := os.Getenv("MATRIX_ROOM_ID")
+	if homeserver == "" && token == "" && roomID == "" {
+		return nil, nil
+	}
+	if homeserver == "" || token == "" || roomID == "" {
+		return nil, fmt.Errorf("MATRIX_HOMESERVER_URL, MATRIX_ACCESS_TOKEN and MATRIX_ROOM_ID must all be set")
+	}
+	u, err := url.Parse(homeserver)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("MATRIX_HOMESERVER_URL must be an http(s) URL, got %q", homeserver)
+	}
+	log.Printf("Matrix status mirror enabled for room %s on %s", roomID, u.Host)
+	return NewMatrixPublisher(homeserver, token, roomID, os.Getenv("MATRIX_STATUS_TITLE"), filepath.Join(filepath.Dir(configPath), matrixStateFile)), nil
+}
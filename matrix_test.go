@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeMatrix records sent events and returns sequential event IDs
+type fakeMatrix struct {
+	mu     sync.Mutex
+	paths  []string
+	events []map[string]any
+	status int
+}
+
+func (f *fakeMatrix) handler(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer syt_test" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"errcode": "M_UNKNOWN_TOKEN", "error": "Invalid token"})
+		return
+	}
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		json.NewEncoder(w).Encode(map[string]string{"errcode": "M_FORBIDDEN", "error": "not in room"})
+		return
+	}
+
+	var event map[string]any
+	json.NewDecoder(r.Body).Decode(&event)
+	f.paths = append(f.paths, r.URL.EscapedPath())
+	f.events = append(f.events, event)
+	json.NewEncoder(w).Encode(map[string]string{"event_id": "$event" + string(rune('0'+len(f.events)))})
+}
+
+func newTestMatrixPublisher(t *testing.T, f *fakeMatrix) *MatrixPublisher {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(f.handler))
+	t.Cleanup(srv.Close)
+	return NewMatrixPublisher(srv.URL+"/", "syt_test", "!room:example.org", "", filepath.Join(t.TempDir(), matrixStateFile))
+}
+
+// TestMatrixPublisher_PostThenEdit tests that the first publish posts and later ones send m.replace edits
+func TestMatrixPublisher_PostThenEdit(t *testing.T) {
+	f := &fakeMatrix{}
+	p := newTestMatrixPublisher(t, f)
+
+	if err := p.UpdateStatus(&StatusUpdate{Snapshot: testMirrorSnapshot()}); err != nil {
+		t.Fatalf("First publish failed: %v", err)
+	}
+	if err := p.UpdateStatus(&StatusUpdate{Snapshot: testMirrorSnapshot()}); err != nil {
+		t.Fatalf("Second publish failed: %v", err)
+	}
+
+	if len(f.events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(f.events))
+	}
+	if !strings.HasPrefix(f.paths[0], "/_matrix/client/v3/rooms/%21room:example.org/send/m.room.message/") {
+		t.Errorf("Unexpected send path %s", f.paths[0])
+	}
+	if f.paths[0] == f.paths[1] {
+		t.Error("Expected a unique transaction ID per event")
+	}
+
+	relates, ok := f.events[1]["m.relates_to"].(map[string]any)
+	if !ok || relates["rel_type"] != "m.replace" || relates["event_id"] != "$event1" {
+		t.Errorf("Expected m.replace relation to $event1, got %v", f.events[1]["m.relates_to"])
+	}
+	if _, ok := f.events[1]["m.new_content"]; !ok {
+		t.Error("Expected m.new_content in edit")
+	}
+
+	// Event ID survives a restart
+	restarted := NewMatrixPublisher("https://matrix.example.org", "syt_test", "!room:example.org", "", p.statePath)
+	if restarted.eventID != "$event1" {
+		t.Errorf("Expected persisted event ID, got %q", restarted.eventID)
+	}
+}
+
+// TestMatrixPublisher_ErrorResponse tests that API errors are surfaced with their errcode
+func TestMatrixPublisher_ErrorResponse(t *testing.T) {
+	f := &fakeMatrix{status: http.StatusForbidden}
+	p := newTestMatrixPublisher(t, f)
+
+	err := p.UpdateStatus(&StatusUpdate{Snapshot: testMirrorSnapshot()})
+	if err == nil || !strings.Contains(err.Error(), "M_FORBIDDEN") {
+		t.Errorf("Expected M_FORBIDDEN error, got %v", err)
+	}
+	if p.eventID != "" {
+		t.Errorf("Expected no event ID after failure, got %q", p.eventID)
+	}
+}
+
+// TestRenderMatrixMessage tests notice type and HTML escaping
+func TestRenderMatrixMessage(t *testing.T) {
+	content := renderMatrixMessage(testMirrorSnapshot(), "Status")
+
+	if content["msgtype"] != "m.notice" {
+		t.Errorf("Expected m.notice, got %v", content["msgtype"])
+	}
+	formatted := content["formatted_body"].(string)
+	if !strings.Contains(formatted, "Drift &lt;2&gt; — offline") {
+		t.Errorf("Expected escaped server name, got %s", formatted)
+	}
+	if !strings.Contains(formatted, `<a href="https://acstuff.club/`) {
+		t.Errorf("Expected join link, got %s", formatted)
+	}
+	if !strings.Contains(content["body"].(string), "Total Players: 2") {
+		t.Errorf("Expected plain text total, got %s", content["body"])
+	}
+}
+
+// TestMatrixPublisherFromEnv tests enablement and URL validation
+func TestMatrixPublisherFromEnv(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	t.Setenv("MATRIX_HOMESERVER_URL", "")
+	t.Setenv("MATRIX_ACCESS_TOKEN", "")
+	t.Setenv("MATRIX_ROOM_ID", "")
+	if p, err := matrixPublisherFromEnv(configPath); p != nil || err != nil {
+		t.Errorf("Expected disabled, got %v (%v)", p, err)
+	}
+
+	t.Setenv("MATRIX_ACCESS_TOKEN", "syt_test")
+	if _, err := matrixPublisherFromEnv(configPath); err == nil {
+		t.Error("Expected error for partial configuration")
+	}
+
+	t.Setenv("MATRIX_ROOM_ID", "!room:example.org")
+	t.Setenv("MATRIX_HOMESERVER_URL", "matrix.example.org")
+	if _, err := matrixPublisherFromEnv(configPath); err == nil {
+		t.Error("Expected error for homeserver URL without scheme")
+	}
+
+	t.Setenv("MATRIX_HOMESERVER_URL", "https://matrix.example.org")
+	p, err := matrixPublisherFromEnv(configPath)
+	if err != nil || p == nil {
+		t.Fatalf("Expected publisher, got %v (%v)", p, err)
+	}
+}
+
+// TestMatrixPublisher_DeleteAndAlert tests redaction of the status event and alert notices
+func TestMatrixPublisher_DeleteAndAlert(t *testing.T) {
+	f := &fakeMatrix{}
+	p := newTestMatrixPublisher(t, f)
+
+	if err := p.UpdateStatus(&StatusUpdate{Snapshot: testMirrorSnapshot()}); err != nil {
+		t.Fatalf("UpdateStatus failed: %v", err)
+	}
+	if err := p.DeleteStatus(); err != nil {
+		t.Fatalf("DeleteStatus failed: %v", err)
+	}
+	if !strings.Contains(f.paths[1], "/redact/$event1/") {
+		t.Errorf("Expected redaction of $event1, got %s", f.paths[1])
+	}
+	if p.eventID != "" {
+		t.Errorf("Expected event ID cleared, got %q", p.eventID)
+	}
+
+	if err := p.SendAlert("Server down"); err != nil {
+		t.Fatalf("SendAlert failed: %v", err)
+	}
+	if f.events[2]["body"] != "Server down" || f.events[2]["msgtype"] != "m.notice" {
+		t.Errorf("Unexpected alert event %v", f.events[2])
+	}
+}
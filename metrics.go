@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/bombom/absa-ac/pkg/metrics"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= METRICS =================
+
+// Discord gateway metrics; the API serves them at /metrics together with the API and proxy metrics
+var (
+	gatewayConnected = metrics.NewGauge("absa_discord_gateway_connected",
+		"1 while the Discord gateway connection is open, by component", "component")
+	gatewayReconnects = metrics.NewCounter("absa_discord_gateway_reconnects_total",
+		"Discord gateway connections opened after the first one (resumed or new sessions), by component", "component")
+	gatewayDisconnects = metrics.NewCounter("absa_discord_gateway_disconnects_total",
+		"Discord gateway connections lost or closed, by component", "component")
+)
+
+// Update loop metrics, mirroring the counters in the diagnostics report
+var (
+	updateTicksSkipped = metrics.NewCounter("absa_update_ticks_skipped_total",
+		"Status update ticks skipped because the previous update was still running, by component", "component")
+	updatesSlow = metrics.NewCounter("absa_update_slow_total",
+		"Status updates that took longer than the update interval, by component", "component")
+)
+
+// onGatewayConnect counts gateway connections; discordgo sends Connect after every successful (re)connect
+func (b *Bot) onGatewayConnect(_ *discordgo.Session, _ *discordgo.Connect) {
+	if b.gatewayConnects.Add(1) > 1 {
+		gatewayReconnects.Inc(metrics.ComponentDiscord)
+	}
+	b.gatewayUp.Store(true)
+	gatewayConnected.Set(1, metrics.ComponentDiscord)
+}
+
+// onGatewayDisconnect counts lost gateway connections; discordgo reconnects on its own afterwards
+func (b *Bot) onGatewayDisconnect(_ *discordgo.Session, _ *discordgo.Disconnect) {
+	gatewayDisconnects.Inc(metrics.ComponentDiscord)
+	b.gatewayUp.Store(false)
+	gatewayConnected.Set(0, metrics.ComponentDiscord)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bombom/absa-ac/pkg/metrics"
+)
+
+// TestGatewayMetrics tests that the first connection is not counted as a reconnect
+func TestGatewayMetrics(t *testing.T) {
+	b := newTestBot(nil)
+	reconnects := gatewayReconnects.Value(metrics.ComponentDiscord)
+	disconnects := gatewayDisconnects.Value(metrics.ComponentDiscord)
+
+	b.onGatewayConnect(nil, nil)
+	if gatewayReconnects.Value(metrics.ComponentDiscord) != reconnects || gatewayConnected.Value(metrics.ComponentDiscord) != 1 {
+		t.Error("Expected the first connection to be counted as connected, not as a reconnect")
+	}
+
+	b.onGatewayDisconnect(nil, nil)
+	if gatewayDisconnects.Value(metrics.ComponentDiscord) != disconnects+1 || gatewayConnected.Value(metrics.ComponentDiscord) != 0 {
+		t.Error("Expected the disconnect counted and the gateway marked disconnected")
+	}
+
+	b.onGatewayConnect(nil, nil)
+	if gatewayReconnects.Value(metrics.ComponentDiscord) != reconnects+1 || gatewayConnected.Value(metrics.ComponentDiscord) != 1 {
+		t.Error("Expected the second connection to be counted as a reconnect")
+	}
+}
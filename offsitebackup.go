@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bombom/absa-ac/pkg/s3put"
+)
+
+// ================= OFFSITE BACKUPS =================
+
+const (
+	// defaultOffsiteBackupInterval is how often the backup archive is uploaded unless BACKUP_S3_INTERVAL is set
+	defaultOffsiteBackupInterval = 24 * time.Hour
+	// defaultOffsiteBackupRetention is how long uploaded archives are kept unless BACKUP_S3_RETENTION_DAYS is set
+	defaultOffsiteBackupRetention = 30 * 24 * time.Hour
+	// defaultOffsiteBackupPrefix starts the object keys unless BACKUP_S3_PREFIX is set
+	defaultOffsiteBackupPrefix = "absa-ac/"
+	// offsiteBackupRetry is the wait after a failed upload (or the interval, if shorter)
+	offsiteBackupRetry = 15 * time.Minute
+	// offsiteBackupTimeout bounds one upload, including the listing and pruning
+	offsiteBackupTimeout = 10 * time.Minute
+)
+
+// objectStore is the part of the S3 client offsite backups use
+type objectStore interface {
+	PutObject(ctx context.Context, key string, body io.Reader, size int64, contentType string) (int64, error)
+	ListObjects(ctx context.Context, prefix string) ([]s3put.Object, error)
+	DeleteObject(ctx context.Context, key string) error
+}
+
+// offsiteBackup uploads the state backup archive to S3-compatible storage every interval and deletes
+// uploads older than the retention; only the leader uploads
+// The last upload is found by listing the bucket at start, so a restart does not upload again early
+type offsiteBackup struct {
+	store     objectStore
+	bucket    string // for logs
+	prefix    string
+	interval  time.Duration
+	retention time.Duration // 0 = keep every upload
+
+	archive  func(w io.Writer, now time.Time) error
+	isLeader func() bool
+
+	mu          sync.Mutex
+	lastSuccess time.Time
+	lastKey     string
+	lastErr     error
+}
+
+// offsiteBackupFromEnv returns the uploader if BACKUP_S3_BUCKET is set, nil otherwise
+// BACKUP_S3_ENDPOINT, BACKUP_S3_ACCESS_KEY_ID and BACKUP_S3_SECRET_ACCESS_KEY are required
+func offsiteBackupFromEnv() (*offsiteBackup, error) {
+	bucket := os.Getenv("BACKUP_S3_BUCKET")
+	if bucket == "" {
+		return nil, nil
+	}
+	endpoint := os.Getenv("BACKUP_S3_ENDPOINT")
+	if endpoint == "" {
+		return nil, fmt.Errorf("BACKUP_S3_BUCKET requires BACKUP_S3_ENDPOINT, e.g. https://s3.eu-central-1.amazonaws.com")
+	}
+	region := os.Getenv("BACKUP_S3_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	client, err := s3put.New(endpoint, region, bucket, os.Getenv("BACKUP_S3_ACCESS_KEY_ID"), os.Getenv("BACKUP_S3_SECRET_ACCESS_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("BACKUP_S3: %w", err)
+	}
+
+	prefix := defaultOffsiteBackupPrefix
+	if v, ok := os.LookupEnv("BACKUP_S3_PREFIX"); ok {
+		prefix = strings.TrimPrefix(v, "/")
+	}
+	interval := defaultOffsiteBackupInterval
+	if v := os.Getenv("BACKUP_S3_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < time.Hour || d > 30*24*time.Hour {
+			return nil, fmt.Errorf("invalid BACKUP_S3_INTERVAL %q: must be a duration between 1h and 720h, e.g. 24h", v)
+		}
+		interval = d
+	}
+	retention, err := retentionFromEnv("BACKUP_S3_RETENTION_DAYS", defaultOffsiteBackupRetention)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Offsite backups enabled: s3://%s/%s every %v, kept %s", bucket, prefix, interval, formatRetention(retention))
+	return &offsiteBackup{store: client, bucket: bucket, prefix: prefix, interval: interval, retention: retention}, nil
+}
+
+// formatRetention describes a retention for logs
+func formatRetention(d time.Duration) string {
+	if d == 0 {
+		return "forever"
+	}
+	return fmt.Sprintf("%d days", int(d/(24*time.Hour)))
+}
+
+// Run uploads an archive whenever the last upload is an interval old, until ctx is cancelled
+func (o *offsiteBackup) Run(ctx context.Context) error {
+	if err := o.loadLastSuccess(ctx); err != nil {
+		log.Printf("Warning: offsite backup: could not list s3://%s/%s: %v", o.bucket, o.prefix, err)
+	}
+	delay := o.wait(time.Now())
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case now := <-timer.C:
+			if o.isLeader == nil || o.isLeader() {
+				o.upload(ctx, now)
+				delay = o.wait(time.Now())
+				continue
+			}
+			// A standby only follows the leader's uploads, so it takes over the schedule on failover
+			o.loadLastSuccess(ctx)
+			delay = max(o.wait(time.Now()), min(offsiteBackupRetry, o.interval))
+		}
+	}
+}
+
+// wait returns how long until the next upload is due: an interval after the last success, or
+// offsiteBackupRetry after a failure
+func (o *offsiteBackup) wait(now time.Time) time.Duration {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	next := o.lastSuccess.Add(o.interval)
+	if o.lastErr != nil {
+		next = now.Add(min(offsiteBackupRetry, o.interval))
+	}
+	return max(next.Sub(now), 0)
+}
+
+// backupKeys returns the keys of earlier uploads under the prefix, oldest first
+func (o *offsiteBackup) backupKeys(ctx context.Context) ([]s3put.Object, error) {
+	objects, err := o.store.ListObjects(ctx, o.prefix+"absa-backup-")
+	if err != nil {
+		return nil, err
+	}
+	objects = slices.DeleteFunc(objects, func(obj s3put.Object) bool { return !strings.HasSuffix(obj.Key, ".zip") })
+	slices.SortFunc(objects, func(a, b s3put.Object) int { return a.LastModified.Compare(b.LastModified) })
+	return objects, nil
+}
+
+// loadLastSuccess takes the newest upload in the bucket as the last success
+func (o *offsiteBackup) loadLastSuccess(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, offsiteBackupTimeout)
+	defer cancel()
+	objects, err := o.backupKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+	newest := objects[len(objects)-1]
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if newest.LastModified.After(o.lastSuccess) {
+		o.lastSuccess = newest.LastModified
+		o.lastKey = newest.Key
+	}
+	return nil
+}
+
+// upload writes one archive to the bucket and prunes expired uploads; errors are logged and kept for /health
+func (o *offsiteBackup) upload(ctx context.Context, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, offsiteBackupTimeout)
+	defer cancel()
+	key := o.prefix + api.StateBackupName(now)
+	size, err := o.putArchive(ctx, key, now)
+
+	o.mu.Lock()
+	o.lastErr = err
+	if err == nil {
+		o.lastSuccess = now
+		o.lastKey = key
+	}
+	o.mu.Unlock()
+	if err != nil {
+		log.Printf("Error: offsite backup to s3://%s/%s failed: %v", o.bucket, key, err)
+		return err
+	}
+	log.Printf("Offsite backup uploaded to s3://%s/%s (%d bytes)", o.bucket, key, size)
+	o.prune(ctx, now)
+	return nil
+}
+
+// putArchive streams the archive to key while it is written, so it is never held in memory whole
+// An archive error fails the upload, which is then aborted
+func (o *offsiteBackup) putArchive(ctx context.Context, key string, now time.Time) (int64, error) {
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(o.archive(pw, now))
+	}()
+	size, err := o.store.PutObject(ctx, key, pr, -1, "application/zip")
+	// A failed upload stops reading; unblock the archive writer before waiting for it
+	pr.CloseWithError(fmt.Errorf("upload ended"))
+	<-done
+	return size, err
+}
+
+// prune deletes uploads older than the retention; the newest upload is always kept
+func (o *offsiteBackup) prune(ctx context.Context, now time.Time) {
+	if o.retention == 0 {
+		return
+	}
+	objects, err := o.backupKeys(ctx)
+	if err != nil {
+		log.Printf("Warning: offsite backup: could not list s3://%s/%s to prune: %v", o.bucket, o.prefix, err)
+		return
+	}
+	cutoff := now.Add(-o.retention)
+	for _, obj := range objects[:max(len(objects)-1, 0)] {
+		if !obj.LastModified.Before(cutoff) {
+			break
+		}
+		if err := o.store.DeleteObject(ctx, obj.Key); err != nil {
+			log.Printf("Warning: offsite backup: could not delete expired s3://%s/%s: %v", o.bucket, obj.Key, err)
+			continue
+		}
+		log.Printf("Offsite backup: deleted expired s3://%s/%s", o.bucket, obj.Key)
+	}
+}
+
+// healthCheck reports the last upload: failing after an error, or when no upload succeeded for two intervals
+func (o *offsiteBackup) healthCheck(now time.Time) api.HealthCheckResult {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	res := api.HealthCheckResult{Name: "offsite_backup"}
+	if !o.lastSuccess.IsZero() {
+		last := o.lastSuccess
+		res.LastSuccess = &last
+	}
+	switch {
+	case o.lastErr != nil:
+		res.Detail = "last upload failed: " + o.lastErr.Error()
+	case o.lastSuccess.IsZero():
+		// Nothing uploaded yet: fine until the first upload is due
+		res.OK = true
+		res.Detail = "no upload yet"
+	case now.Sub(o.lastSuccess) > 2*o.interval:
+		res.Detail = fmt.Sprintf("no upload for %s", formatDataAge(now.Sub(o.lastSuccess)))
+	default:
+		res.OK = true
+		res.Detail = fmt.Sprintf("uploaded s3://%s/%s", o.bucket, o.lastKey)
+	}
+	return res
+}
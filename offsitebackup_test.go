@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/s3put"
+)
+
+// memoryObjectStore is an in-memory objectStore
+type memoryObjectStore struct {
+	mu      sync.Mutex
+	objects map[string]s3put.Object
+	putErr  error
+}
+
+func (m *memoryObjectStore) PutObject(ctx context.Context, key string, body io.Reader, size int64, contentType string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.putErr != nil {
+		return 0, m.putErr // without reading, like a refused upload
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return 0, err
+	}
+	m.objects[key] = s3put.Object{Key: key, Size: int64(len(data)), LastModified: time.Now()}
+	return int64(len(data)), nil
+}
+
+func (m *memoryObjectStore) ListObjects(ctx context.Context, prefix string) ([]s3put.Object, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var objects []s3put.Object
+	for key, obj := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+func (m *memoryObjectStore) DeleteObject(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func newTestOffsiteBackup(store *memoryObjectStore) *offsiteBackup {
+	return &offsiteBackup{
+		store:     store,
+		bucket:    "bucket",
+		prefix:    "absa-ac/",
+		interval:  24 * time.Hour,
+		retention: 30 * 24 * time.Hour,
+		archive: func(w io.Writer, now time.Time) error {
+			_, err := io.WriteString(w, "zip")
+			return err
+		},
+	}
+}
+
+// TestOffsiteBackup_UploadAndPrune tests uploads, pruning past the retention (keeping the newest) and the
+// health check after success and failure
+func TestOffsiteBackup_UploadAndPrune(t *testing.T) {
+	now := time.Now()
+	store := &memoryObjectStore{objects: map[string]s3put.Object{
+		"absa-ac/absa-backup-old.zip":    {Key: "absa-ac/absa-backup-old.zip", LastModified: now.Add(-40 * 24 * time.Hour)},
+		"absa-ac/absa-backup-recent.zip": {Key: "absa-ac/absa-backup-recent.zip", LastModified: now.Add(-10 * 24 * time.Hour)},
+		"absa-ac/notes.txt":              {Key: "absa-ac/notes.txt", LastModified: now.Add(-400 * 24 * time.Hour)},
+	}}
+	o := newTestOffsiteBackup(store)
+	if check := o.healthCheck(now); !check.OK || check.LastSuccess != nil {
+		t.Errorf("Expected OK without uploads, got %+v", check)
+	}
+
+	if err := o.upload(context.Background(), now); err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	key := "absa-ac/absa-backup-" + now.UTC().Format("2006-01-02T15-04-05") + ".zip"
+	if _, ok := store.objects[key]; !ok {
+		t.Errorf("Expected %s uploaded, got %v", key, store.objects)
+	}
+	if _, ok := store.objects["absa-ac/absa-backup-old.zip"]; ok {
+		t.Error("Expected the upload past the retention deleted")
+	}
+	if _, ok := store.objects["absa-ac/absa-backup-recent.zip"]; !ok {
+		t.Error("Expected the recent upload kept")
+	}
+	if _, ok := store.objects["absa-ac/notes.txt"]; !ok {
+		t.Error("Expected objects that are not backups kept")
+	}
+	check := o.healthCheck(now)
+	if !check.OK || check.LastSuccess == nil || !check.LastSuccess.Equal(now) || !strings.Contains(check.Detail, key) {
+		t.Errorf("Expected OK with the last success, got %+v", check)
+	}
+	if w := o.wait(now); w != 24*time.Hour {
+		t.Errorf("Expected the next upload in 24h, got %v", w)
+	}
+
+	store.putErr = errors.New("AccessDenied")
+	if err := o.upload(context.Background(), now.Add(24*time.Hour)); err == nil {
+		t.Fatal("Expected the upload to fail")
+	}
+	if check := o.healthCheck(now.Add(24 * time.Hour)); check.OK || check.LastSuccess == nil || !strings.Contains(check.Detail, "AccessDenied") {
+		t.Errorf("Expected failing check keeping the last success, got %+v", check)
+	}
+	if w := o.wait(now.Add(24 * time.Hour)); w != offsiteBackupRetry {
+		t.Errorf("Expected a retry after %v, got %v", offsiteBackupRetry, w)
+	}
+}
+
+// TestOffsiteBackup_KeepsNewest tests that the newest upload survives even when it is past the retention
+func TestOffsiteBackup_KeepsNewest(t *testing.T) {
+	now := time.Now()
+	store := &memoryObjectStore{objects: map[string]s3put.Object{
+		"absa-ac/absa-backup-a.zip": {Key: "absa-ac/absa-backup-a.zip", LastModified: now.Add(-90 * 24 * time.Hour)},
+		"absa-ac/absa-backup-b.zip": {Key: "absa-ac/absa-backup-b.zip", LastModified: now.Add(-60 * 24 * time.Hour)},
+	}}
+	o := newTestOffsiteBackup(store)
+	o.prune(context.Background(), now)
+	if _, ok := store.objects["absa-ac/absa-backup-b.zip"]; !ok || len(store.objects) != 1 {
+		t.Errorf("Expected only the newest upload kept, got %v", store.objects)
+	}
+}
+
+// TestOffsiteBackup_LastSuccessFromBucket tests that a restart schedules from the newest upload and that a
+// stale upload fails the health check
+func TestOffsiteBackup_LastSuccessFromBucket(t *testing.T) {
+	now := time.Now()
+	store := &memoryObjectStore{objects: map[string]s3put.Object{
+		"absa-ac/absa-backup-a.zip": {Key: "absa-ac/absa-backup-a.zip", LastModified: now.Add(-20 * time.Hour)},
+	}}
+	o := newTestOffsiteBackup(store)
+	if err := o.loadLastSuccess(context.Background()); err != nil {
+		t.Fatalf("loadLastSuccess failed: %v", err)
+	}
+	if w := o.wait(now); w != 4*time.Hour {
+		t.Errorf("Expected the next upload in 4h, got %v", w)
+	}
+	if check := o.healthCheck(now.Add(40 * time.Hour)); check.OK || !strings.Contains(check.Detail, "no upload for") {
+		t.Errorf("Expected a stale upload to fail the check, got %+v", check)
+	}
+}
+
+// TestOffsiteBackupFromEnv tests the required settings and defaults
+func TestOffsiteBackupFromEnv(t *testing.T) {
+	if o, err := offsiteBackupFromEnv(); o != nil || err != nil {
+		t.Fatalf("Expected disabled without BACKUP_S3_BUCKET, got %v %v", o, err)
+	}
+	t.Setenv("BACKUP_S3_BUCKET", "backups")
+	if _, err := offsiteBackupFromEnv(); err == nil {
+		t.Error("Expected an error without BACKUP_S3_ENDPOINT")
+	}
+	t.Setenv("BACKUP_S3_ENDPOINT", "https://s3.example.com")
+	if _, err := offsiteBackupFromEnv(); err == nil {
+		t.Error("Expected an error without credentials")
+	}
+	t.Setenv("BACKUP_S3_ACCESS_KEY_ID", "key")
+	t.Setenv("BACKUP_S3_SECRET_ACCESS_KEY", "secret")
+	o, err := offsiteBackupFromEnv()
+	if err != nil || o.prefix != defaultOffsiteBackupPrefix || o.interval != defaultOffsiteBackupInterval || o.retention != defaultOffsiteBackupRetention {
+		t.Fatalf("Expected defaults, got %+v %v", o, err)
+	}
+	t.Setenv("BACKUP_S3_INTERVAL", "10m")
+	if _, err := offsiteBackupFromEnv(); err == nil {
+		t.Error("Expected an interval below 1h to be refused")
+	}
+}
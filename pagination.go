@@ -0,0 +1,248 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= EMBED PAGINATION =================
+
+// Discord rejects embeds with more than 25 fields or 6000 characters in total
+const (
+	embedMaxFields = 25
+	embedMaxChars  = 6000
+)
+
+// embedPageReserve leaves room for the page suffix and the status marker in the footer
+const embedPageReserve = 16
+
+// continuedTitleSuffix marks the title of continuation pages
+const continuedTitleSuffix = " (continued)"
+
+// embedFieldBlank is the zero-width space used for spacer fields and header values
+const embedFieldBlank = "\u200b"
+
+// embedFieldChars counts the characters of a field toward the embed limit
+func embedFieldChars(f *discordgo.MessageEmbedField) int {
+	return utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+}
+
+// isSpacerField reports whether f is the blank field separating categories
+func isSpacerField(f *discordgo.MessageEmbedField) bool {
+	return f.Name == embedFieldBlank && f.Value == embedFieldBlank
+}
+
+// isHeaderField reports whether f is a category header (title only), which stays with the field after it
+func isHeaderField(f *discordgo.MessageEmbedField) bool {
+	return f.Value == embedFieldBlank && f.Name != embedFieldBlank
+}
+
+// paginateEmbed splits embed into pages that each fit in one Discord message
+// The first page keeps the description, thumbnail and image; continuation pages repeat the title with
+// continuedTitleSuffix and take the color in headerColors of the category header at their top, if any.
+// Every page of a split gets "Page k/n" in the footer. An embed that fits is returned as is
+func paginateEmbed(embed *discordgo.MessageEmbed, headerColors map[string]int) []*discordgo.MessageEmbed {
+	footer := ""
+	if embed.Footer != nil {
+		footer = embed.Footer.Text
+	}
+	firstOverhead := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description) + utf8.RuneCountInString(footer)
+	if embed.Author != nil {
+		firstOverhead += utf8.RuneCountInString(embed.Author.Name)
+	}
+
+	chars := firstOverhead
+	for _, f := range embed.Fields {
+		chars += embedFieldChars(f)
+	}
+	if len(embed.Fields) <= embedMaxFields && chars+embedPageReserve <= embedMaxChars {
+		return []*discordgo.MessageEmbed{embed}
+	}
+
+	contOverhead := utf8.RuneCountInString(embed.Title+continuedTitleSuffix) + utf8.RuneCountInString(footer)
+	var chunks [][]*discordgo.MessageEmbedField
+	var page []*discordgo.MessageEmbedField
+	var chunkHeaders []string // category header in effect at the top of each chunk
+	header, pageHeader := "", ""
+	budget := embedMaxChars - embedPageReserve - firstOverhead
+	for _, f := range embed.Fields {
+		if len(page) == embedMaxFields || embedFieldChars(f) > budget {
+			// Carry a trailing category header over so it stays with its first server
+			var carry []*discordgo.MessageEmbedField
+			if n := len(page); n > 1 && isHeaderField(page[n-1]) {
+				carry, page = page[n-1:], page[:n-1]
+			}
+			chunks = append(chunks, page)
+			chunkHeaders = append(chunkHeaders, pageHeader)
+			page = carry
+			if len(carry) > 0 {
+				pageHeader = carry[0].Name
+			}
+			budget = embedMaxChars - embedPageReserve - contOverhead
+			for _, c := range carry {
+				budget -= embedFieldChars(c)
+			}
+		}
+		if len(page) == 0 && len(chunks) > 0 && isSpacerField(f) {
+			continue // no blank gap at the top of a continuation page
+		}
+		if isHeaderField(f) {
+			header = f.Name
+		}
+		if len(page) == 0 {
+			pageHeader = header
+		}
+		page = append(page, f)
+		budget -= embedFieldChars(f)
+	}
+	if len(page) > 0 || len(chunks) == 0 {
+		chunks = append(chunks, page)
+		chunkHeaders = append(chunkHeaders, pageHeader)
+	}
+	if len(chunks) == 1 {
+		// Over the limit only because of the reserve; keep the embed unsplit
+		return []*discordgo.MessageEmbed{embed}
+	}
+
+	pages := make([]*discordgo.MessageEmbed, len(chunks))
+	for i, fields := range chunks {
+		var p discordgo.MessageEmbed
+		if i == 0 {
+			p = *embed
+		} else {
+			p = discordgo.MessageEmbed{
+				Title:     embed.Title + continuedTitleSuffix,
+				URL:       embed.URL,
+				Color:     embed.Color,
+				Timestamp: embed.Timestamp,
+			}
+			if color, ok := headerColors[chunkHeaders[i]]; ok {
+				p.Color = color
+			}
+		}
+		p.Fields = fields
+		p.Footer = &discordgo.MessageEmbedFooter{Text: pageFooter(footer, i+1, len(chunks))}
+		if embed.Footer != nil {
+			p.Footer.IconURL = embed.Footer.IconURL
+		}
+		pages[i] = &p
+	}
+	return pages
+}
+
+// pageFooter appends "Page k/n" to the footer text
+func pageFooter(text string, k, n int) string {
+	if text == "" {
+		return fmt.Sprintf("Page %d/%d", k, n)
+	}
+	return fmt.Sprintf("%s • Page %d/%d", text, k, n)
+}
+
+// statusPageNumber returns the page number of a posted status message (1 if it was not split)
+func statusPageNumber(msg *discordgo.Message) int {
+	for _, e := range msg.Embeds {
+		if e.Footer == nil {
+			continue
+		}
+		text := strings.TrimSuffix(e.Footer.Text, statusEmbedMarker)
+		i := strings.LastIndex(text, "Page ")
+		if i < 0 {
+			continue
+		}
+		var k, n int
+		if _, err := fmt.Sscanf(text[i:], "Page %d/%d", &k, &n); err == nil && k >= 1 {
+			return k
+		}
+	}
+	return 1
+}
+
+// latestStatusGroup picks the messages of the most recent status post from messages (newest first)
+// A split status is posted in order, so the group runs from the newest message back to its page 1.
+// The result is in page order
+func latestStatusGroup(messages []*discordgo.Message) []*discordgo.Message {
+	var group []*discordgo.Message
+	for _, msg := range messages {
+		group = append([]*discordgo.Message{msg}, group...)
+		if statusPageNumber(msg) == 1 {
+			break
+		}
+	}
+	return group
+}
+
+// statusPageOps posts, edits and deletes the messages of one status group on a Discord target
+// files is the banner attachment, passed for the first page only (nil for the others); first tells edit
+// whether it handles page 1, which owns the attachments
+type statusPageOps struct {
+	send   func(embed *discordgo.MessageEmbed, files []*discordgo.File) (string, error)
+	edit   func(id string, embed *discordgo.MessageEmbed, files []*discordgo.File, first bool) error
+	delete func(id string) error
+}
+
+// isNotFound reports whether err is a Discord 404 (message or webhook gone)
+func isNotFound(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == 404
+}
+
+// syncStatusPages brings the posted group ids in line with pages and returns the new group
+// Existing messages are edited in place and surplus ones deleted. When the group has to grow, or one of its
+// messages was deleted, the whole group is reposted so the pages stay consecutive in the channel.
+// On error the returned ids are the messages that exist at that point
+func syncStatusPages(ids []string, pages []*discordgo.MessageEmbed, files []*discordgo.File, ops statusPageOps) ([]string, error) {
+	if len(ids) > 0 && len(ids) >= len(pages) {
+		for i, page := range pages {
+			var pageFiles []*discordgo.File
+			if i == 0 {
+				pageFiles = files
+			}
+			if err := ops.edit(ids[i], page, pageFiles, i == 0); err != nil {
+				if !isNotFound(err) {
+					return ids, fmt.Errorf("failed to edit message: %w", err)
+				}
+				log.Printf("Status message %s was deleted, reposting all %d pages", ids[i], len(pages))
+				return repostStatusPages(ids, pages, files, ops)
+			}
+		}
+		for _, id := range ids[len(pages):] {
+			if err := ops.delete(id); err != nil && !isNotFound(err) {
+				log.Printf("Failed to delete surplus status page %s: %v", id, err)
+			}
+		}
+		return ids[:len(pages)], nil
+	}
+	if len(ids) > 0 {
+		log.Printf("Status grew from %d to %d pages, reposting", len(ids), len(pages))
+	}
+	return repostStatusPages(ids, pages, files, ops)
+}
+
+// repostStatusPages deletes the old group (best effort) and posts every page as a new message
+func repostStatusPages(ids []string, pages []*discordgo.MessageEmbed, files []*discordgo.File, ops statusPageOps) ([]string, error) {
+	for _, id := range ids {
+		if err := ops.delete(id); err != nil && !isNotFound(err) {
+			log.Printf("Failed to delete old status page %s: %v", id, err)
+		}
+	}
+	rewindFiles(files)
+
+	posted := make([]string, 0, len(pages))
+	for i, page := range pages {
+		var pageFiles []*discordgo.File
+		if i == 0 {
+			pageFiles = files
+		}
+		id, err := ops.send(page, pageFiles)
+		if err != nil {
+			return posted, fmt.Errorf("failed to send message: %w", err)
+		}
+		posted = append(posted, id)
+	}
+	return posted, nil
+}
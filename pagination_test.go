@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// embedChars counts an embed's characters the way Discord does for the 6000 limit
+func embedChars(e *discordgo.MessageEmbed) int {
+	n := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+	if e.Footer != nil {
+		n += utf8.RuneCountInString(e.Footer.Text)
+	}
+	for _, f := range e.Fields {
+		n += embedFieldChars(f)
+	}
+	return n
+}
+
+// largeStatusEmbed renders categories × perCategory servers with buildEmbed
+func largeStatusEmbed(categories, perCategory int) *discordgo.MessageEmbed {
+	cfg := testStatusConfig()
+	cfg.CategoryOrder = nil
+	var infos []ServerInfo
+	for c := 0; c < categories; c++ {
+		cat := fmt.Sprintf("Cat%d", c)
+		cfg.CategoryOrder = append(cfg.CategoryOrder, cat)
+		cfg.CategoryEmojis[cat] = "🏁"
+		for s := 0; s < perCategory; s++ {
+			infos = append(infos, ServerInfo{Name: fmt.Sprintf("%s server %d", cat, s), Category: cat, Map: "spa", Players: "1/20", NumPlayers: 1, IP: cfg.ServerIP, Port: 8000 + c*100 + s})
+		}
+	}
+	return buildEmbed(infos, cfg)
+}
+
+// TestPaginateEmbed_FitsUnchanged tests that a small embed is not split or modified
+func TestPaginateEmbed_FitsUnchanged(t *testing.T) {
+	embed := largeStatusEmbed(2, 3)
+	pages := paginateEmbed(embed, nil)
+	if len(pages) != 1 || pages[0] != embed {
+		t.Fatalf("Expected the embed unchanged, got %d pages", len(pages))
+	}
+}
+
+// TestPaginateEmbed_FieldLimit tests splitting by field count with continuation headers
+func TestPaginateEmbed_FieldLimit(t *testing.T) {
+	embed := largeStatusEmbed(4, 12) // 4 × (12 servers + header + spacer) = 56 fields
+	pages := paginateEmbed(embed, nil)
+	if len(pages) != 3 {
+		t.Fatalf("Expected 3 pages, got %d", len(pages))
+	}
+
+	servers := 0
+	for i, p := range pages {
+		if len(p.Fields) > embedMaxFields {
+			t.Errorf("Page %d has %d fields", i+1, len(p.Fields))
+		}
+		if want := fmt.Sprintf("Page %d/3", i+1); !strings.HasSuffix(p.Footer.Text, want) {
+			t.Errorf("Page %d footer %q, want suffix %q", i+1, p.Footer.Text, want)
+		}
+		if isSpacerField(p.Fields[0]) {
+			t.Errorf("Page %d starts with a spacer", i+1)
+		}
+		if isHeaderField(p.Fields[len(p.Fields)-1]) {
+			t.Errorf("Page %d ends with a category header", i+1)
+		}
+		for _, f := range p.Fields {
+			if strings.Contains(f.Value, "Join Server") {
+				servers++
+			}
+		}
+	}
+	if servers != 48 {
+		t.Errorf("Expected all 48 servers across pages, got %d", servers)
+	}
+
+	first, cont := pages[0], pages[1]
+	if first.Title != defaultStatusTitle || first.Description != embed.Description || first.Image == nil || first.Thumbnail == nil {
+		t.Error("Expected the first page to keep title, description and images")
+	}
+	if cont.Title != defaultStatusTitle+continuedTitleSuffix || cont.Description != "" || cont.Image != nil || cont.Color != embed.Color {
+		t.Errorf("Unexpected continuation page %+v", cont)
+	}
+	if embed.Footer.Text != "Updates every 30 seconds" || len(embed.Fields) != 56 {
+		t.Error("Original embed was modified")
+	}
+}
+
+// TestPaginateEmbed_CharLimit tests splitting by total characters
+func TestPaginateEmbed_CharLimit(t *testing.T) {
+	embed := &discordgo.MessageEmbed{Title: "Status", Footer: &discordgo.MessageEmbedFooter{Text: "Updates every 30 seconds"}}
+	for i := 0; i < 10; i++ {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: fmt.Sprintf("Server %d", i), Value: strings.Repeat("x", 1000)})
+	}
+	pages := paginateEmbed(embed, nil)
+	if len(pages) != 2 {
+		t.Fatalf("Expected 2 pages, got %d", len(pages))
+	}
+	for i, p := range pages {
+		if n := embedChars(markStatusEmbed(p)); n > embedMaxChars {
+			t.Errorf("Page %d has %d characters", i+1, n)
+		}
+	}
+}
+
+// TestLatestStatusGroup tests that the newest post is picked back to its first page, in page order
+func TestLatestStatusGroup(t *testing.T) {
+	page := func(id string, k, n int) *discordgo.Message {
+		footer := "Updates every 30 seconds" + statusEmbedMarker
+		if n > 1 {
+			footer = pageFooter("Updates every 30 seconds", k, n) + statusEmbedMarker
+		}
+		return &discordgo.Message{ID: id, Embeds: []*discordgo.MessageEmbed{{Footer: &discordgo.MessageEmbedFooter{Text: footer}}}}
+	}
+
+	// Newest first: a 3-page post, then an older single message
+	group := latestStatusGroup([]*discordgo.Message{page("13", 3, 3), page("12", 2, 3), page("11", 1, 3), page("5", 1, 1)})
+	if len(group) != 3 || group[0].ID != "11" || group[2].ID != "13" {
+		t.Errorf("Unexpected group %v", group)
+	}
+
+	group = latestStatusGroup([]*discordgo.Message{page("5", 1, 1), page("4", 1, 1)})
+	if len(group) != 1 || group[0].ID != "5" {
+		t.Errorf("Expected only the newest single message, got %v", group)
+	}
+}
+
+// fakePageTarget implements statusPageOps in memory
+type fakePageTarget struct {
+	next     int
+	sent     []string
+	edited   []string
+	deleted  []string
+	editErrs map[string]error
+}
+
+func (f *fakePageTarget) ops() statusPageOps {
+	return statusPageOps{
+		send: func(embed *discordgo.MessageEmbed, files []*discordgo.File) (string, error) {
+			f.next++
+			id := fmt.Sprintf("m%d", f.next)
+			f.sent = append(f.sent, id)
+			return id, nil
+		},
+		edit: func(id string, embed *discordgo.MessageEmbed, files []*discordgo.File, first bool) error {
+			if err := f.editErrs[id]; err != nil {
+				return err
+			}
+			f.edited = append(f.edited, id)
+			return nil
+		},
+		delete: func(id string) error {
+			f.deleted = append(f.deleted, id)
+			return nil
+		},
+	}
+}
+
+func testPages(n int) []*discordgo.MessageEmbed {
+	pages := make([]*discordgo.MessageEmbed, n)
+	for i := range pages {
+		pages[i] = &discordgo.MessageEmbed{Title: fmt.Sprintf("page %d", i+1)}
+	}
+	return pages
+}
+
+// TestSyncStatusPages tests the lifecycle of a status group
+func TestSyncStatusPages(t *testing.T) {
+	t.Run("Post", func(t *testing.T) {
+		f := &fakePageTarget{}
+		ids, err := syncStatusPages(nil, testPages(2), nil, f.ops())
+		if err != nil || len(ids) != 2 || ids[0] != "m1" || ids[1] != "m2" {
+			t.Errorf("Expected two new messages, got %v (%v)", ids, err)
+		}
+	})
+
+	t.Run("Edit in place", func(t *testing.T) {
+		f := &fakePageTarget{}
+		ids, err := syncStatusPages([]string{"a", "b"}, testPages(2), nil, f.ops())
+		if err != nil || len(f.sent) != 0 || len(f.edited) != 2 || strings.Join(ids, ",") != "a,b" {
+			t.Errorf("Expected both pages edited, got ids %v sent %v edited %v (%v)", ids, f.sent, f.edited, err)
+		}
+	})
+
+	t.Run("Shrink deletes surplus", func(t *testing.T) {
+		f := &fakePageTarget{}
+		ids, err := syncStatusPages([]string{"a", "b", "c"}, testPages(1), nil, f.ops())
+		if err != nil || strings.Join(ids, ",") != "a" || strings.Join(f.deleted, ",") != "b,c" {
+			t.Errorf("Expected surplus pages deleted, got ids %v deleted %v (%v)", ids, f.deleted, err)
+		}
+	})
+
+	t.Run("Grow reposts the group", func(t *testing.T) {
+		f := &fakePageTarget{}
+		ids, err := syncStatusPages([]string{"a"}, testPages(2), nil, f.ops())
+		if err != nil || strings.Join(ids, ",") != "m1,m2" || strings.Join(f.deleted, ",") != "a" || len(f.edited) != 0 {
+			t.Errorf("Expected the group reposted, got ids %v deleted %v edited %v (%v)", ids, f.deleted, f.edited, err)
+		}
+	})
+
+	t.Run("Deleted page reposts the group", func(t *testing.T) {
+		f := &fakePageTarget{editErrs: map[string]error{"b": restError(404)}}
+		ids, err := syncStatusPages([]string{"a", "b"}, testPages(2), nil, f.ops())
+		if err != nil || strings.Join(ids, ",") != "m1,m2" || strings.Join(f.deleted, ",") != "a,b" {
+			t.Errorf("Expected the group reposted, got ids %v deleted %v (%v)", ids, f.deleted, err)
+		}
+	})
+
+	t.Run("Edit error keeps the group", func(t *testing.T) {
+		f := &fakePageTarget{editErrs: map[string]error{"a": restError(500)}}
+		ids, err := syncStatusPages([]string{"a", "b"}, testPages(2), nil, f.ops())
+		if err == nil || strings.Join(ids, ",") != "a,b" || len(f.sent) != 0 {
+			t.Errorf("Expected error with group unchanged, got ids %v sent %v (%v)", ids, f.sent, err)
+		}
+		if classifyDiscordError(err) != discordErrServer {
+			t.Error("Expected the REST error to stay classifiable through wrapping")
+		}
+	})
+}
+
+// TestWebhookPublisher_Pages tests that a split status is posted as several messages and persisted as a group
+func TestWebhookPublisher_Pages(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), webhookStateFile)
+	api := &fakeWebhookAPI{nextID: "111"}
+	p := &WebhookPublisher{api: api, id: "1", token: "t", statePath: statePath}
+
+	if err := p.UpdateStatus(&StatusUpdate{Embed: largeStatusEmbed(4, 12)}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if api.executes != 3 || len(p.messageIDs) != 3 {
+		t.Fatalf("Expected 3 messages posted, got %d executes, IDs %v", api.executes, p.messageIDs)
+	}
+
+	restarted := &WebhookPublisher{statePath: statePath}
+	restarted.loadMessageID()
+	if len(restarted.messageIDs) != 3 {
+		t.Errorf("Expected 3 persisted IDs, got %v", restarted.messageIDs)
+	}
+
+	// Back to a single page: surplus messages are deleted and the state file shrinks
+	if err := p.UpdateStatus(&StatusUpdate{Embed: largeStatusEmbed(1, 2)}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if api.deletes != 2 || len(p.messageIDs) != 1 {
+		t.Errorf("Expected 2 surplus messages deleted, got %d deletes, IDs %v", api.deletes, p.messageIDs)
+	}
+	data, err := os.ReadFile(statePath)
+	if err != nil || strings.TrimSpace(string(data)) != "111" {
+		t.Errorf("Expected state file with one ID, got %q (%v)", data, err)
+	}
+}
+
+// TestIsNotFound tests 404 detection through wrapping
+func TestIsNotFound(t *testing.T) {
+	if !isNotFound(fmt.Errorf("failed: %w", restError(404))) {
+		t.Error("Expected wrapped 404 to be detected")
+	}
+	if isNotFound(restError(403)) || isNotFound(errors.New("404")) {
+		t.Error("Expected only REST 404s to match")
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= SERVER PASSWORDS =================
+
+const (
+	// maxServerPasswordLen bounds a password (AC clients type it in)
+	maxServerPasswordLen = 64
+	// rotatedPasswordLen is the length of generated passwords
+	rotatedPasswordLen = 12
+	// passwordAlphabet leaves out characters that are easy to confuse (0/O, 1/l/I)
+	passwordAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	// redactedPassword replaces passwords in the config served by the API; written back, it keeps the saved one
+	redactedPassword = "***"
+)
+
+// ServerPassword is the join password of a password-protected server (passwords in config.json, keyed by server name)
+// It reaches members through /password only if they hold one of Roles; server_files templates can use it as .Password
+type ServerPassword struct {
+	Password  string   `json:"password"`
+	Roles     []string `json:"roles"`                // Discord role IDs whose members may receive it
+	RotatedAt string   `json:"rotated_at,omitempty"` // RFC 3339, set by rotation
+}
+
+// clonePasswords deep-copies the passwords section (nil for nil)
+func clonePasswords(passwords map[string]ServerPassword) map[string]ServerPassword {
+	if passwords == nil {
+		return nil
+	}
+	out := make(map[string]ServerPassword, len(passwords))
+	for server, p := range passwords {
+		p.Roles = slices.Clone(p.Roles)
+		out[server] = p
+	}
+	return out
+}
+
+// redactPasswords replaces every password with redactedPassword (passwords must be a private copy)
+func redactPasswords(passwords map[string]ServerPassword) {
+	for server, p := range passwords {
+		p.Password = redactedPassword
+		passwords[server] = p
+	}
+}
+
+// restoreRedactedPasswords puts the saved password back where a write sent redactedPassword
+// A redacted password for a server without a saved one is an error: there is nothing to keep
+func restoreRedactedPasswords(passwords, saved map[string]ServerPassword) error {
+	for _, server := range slices.Sorted(maps.Keys(passwords)) {
+		p := passwords[server]
+		if p.Password != redactedPassword {
+			continue
+		}
+		old, ok := saved[server]
+		if !ok {
+			return fmt.Errorf("password of server '%s' is %q but no password is saved for it", server, redactedPassword)
+		}
+		p.Password = old.Password
+		passwords[server] = p
+	}
+	return nil
+}
+
+// validatePasswords checks the passwords section: servers exist, passwords are printable without spaces, roles are IDs
+func validatePasswords(passwords map[string]ServerPassword, servers []Server) error {
+	serverNames := make(map[string]bool, len(servers))
+	for _, s := range servers {
+		serverNames[s.Name] = true
+	}
+	for _, server := range slices.Sorted(maps.Keys(passwords)) {
+		p := passwords[server]
+		if !serverNames[server] {
+			return fmt.Errorf("password for server '%s' which is not defined in servers", server)
+		}
+		if p.Password == "" || len(p.Password) > maxServerPasswordLen {
+			return fmt.Errorf("password of server '%s' must be 1-%d characters", server, maxServerPasswordLen)
+		}
+		if strings.IndexFunc(p.Password, func(r rune) bool { return r <= ' ' || r > '~' }) >= 0 {
+			return fmt.Errorf("password of server '%s' may only contain printable ASCII without spaces", server)
+		}
+		if len(p.Roles) == 0 {
+			return fmt.Errorf("password of server '%s' has no roles: nobody could receive it", server)
+		}
+		for _, role := range p.Roles {
+			if !snowflakePattern.MatchString(role) {
+				return fmt.Errorf("password of server '%s': role %q is not a Discord role ID", server, role)
+			}
+		}
+		if p.RotatedAt != "" {
+			if _, err := time.Parse(time.RFC3339, p.RotatedAt); err != nil {
+				return fmt.Errorf("password of server '%s': rotated_at must be RFC 3339", server)
+			}
+		}
+	}
+	return nil
+}
+
+// generatePassword returns a random password of rotatedPasswordLen characters from passwordAlphabet
+func generatePassword() string {
+	b := make([]byte, rotatedPasswordLen)
+	for i := range b {
+		b[i] = passwordAlphabet[randIndex(len(passwordAlphabet))]
+	}
+	return string(b)
+}
+
+// randIndex returns a uniform random number in [0, n) from crypto/rand
+func randIndex(n int) int {
+	var buf [1]byte
+	limit := 256 - 256%n // reject values that would bias the result
+	for {
+		if _, err := rand.Read(buf[:]); err != nil {
+			panic(fmt.Sprintf("crypto/rand failed: %v", err))
+		}
+		if int(buf[0]) < limit {
+			return int(buf[0]) % n
+		}
+	}
+}
+
+// rotatePassword replaces the password of server with a generated one and saves the config
+// Deploy the server files (or edit server_cfg.ini) afterwards: the AC server keeps the old password until then
+func (b *Bot) rotatePassword(server string, now time.Time) (ServerPassword, error) {
+	cfg := b.configManager.GetConfig()
+	if cfg == nil {
+		return ServerPassword{}, fmt.Errorf("no config loaded")
+	}
+	p, ok := cfg.Passwords[server]
+	if !ok {
+		return ServerPassword{}, fmt.Errorf("%w '%s' (no password configured)", api.ErrUnknownServer, server)
+	}
+	next := cfg.Clone()
+	p.Roles = slices.Clone(p.Roles)
+	p.Password = generatePassword()
+	p.RotatedAt = now.UTC().Format(time.RFC3339)
+	next.Passwords[server] = p
+	if err := b.configManager.WriteConfig(next); err != nil {
+		return ServerPassword{}, err
+	}
+	return p, nil
+}
+
+// passwordCommand is /password, usable by every member who can post messages; the password only goes to
+// members holding one of the server's roles, by DM
+func passwordCommand() *slashCommand {
+	sendMessages := int64(discordgo.PermissionSendMessages)
+	return &slashCommand{
+		def: &discordgo.ApplicationCommand{
+			Name:                     "password",
+			Description:              "Get the join password of an event server by DM",
+			DefaultMemberPermissions: &sendMessages,
+			Contexts:                 &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild},
+			Options: []*discordgo.ApplicationCommandOption{{
+				Type: discordgo.ApplicationCommandOptionString, Name: "server", Description: "Server name",
+				Required: true, Autocomplete: true,
+			}},
+		},
+		run:      runPasswordCommand,
+		complete: completePasswordServer,
+		enabled: func(b *Bot) bool {
+			cfg := b.configManager.GetConfig()
+			return b.discord != nil && cfg != nil && len(cfg.Passwords) > 0
+		},
+	}
+}
+
+// completePasswordServer suggests the servers that have a password
+func completePasswordServer(b *Bot, focused *discordgo.ApplicationCommandInteractionDataOption) []*discordgo.ApplicationCommandOptionChoice {
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	cfg := b.configManager.GetConfig()
+	if cfg == nil {
+		return choices
+	}
+	typed := strings.ToLower(focused.StringValue())
+	for _, server := range slices.Sorted(maps.Keys(cfg.Passwords)) {
+		if !strings.Contains(strings.ToLower(server), typed) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: server, Value: server})
+		if len(choices) == 25 {
+			break
+		}
+	}
+	return choices
+}
+
+// runPasswordCommand handles /password: checks the member's roles and sends the password by DM
+// Every hand-out and refusal is logged
+func runPasswordCommand(b *Bot, user invoker, opts []*discordgo.ApplicationCommandInteractionDataOption) string {
+	server := optionString(opts, "server")
+	cfg := b.configManager.GetConfig()
+	if cfg == nil || b.discord == nil {
+		return "❌ No config loaded"
+	}
+	p, ok := cfg.Passwords[server]
+	if !ok {
+		return fmt.Sprintf("**%s** has no password", server)
+	}
+	if !slices.ContainsFunc(p.Roles, func(r string) bool { return slices.Contains(user.roles, r) }) {
+		log.Printf("Audit: %s was refused the password of '%s' (missing role)", user.name, server)
+		return fmt.Sprintf("❌ You need one of the event roles to get the password of **%s**", server)
+	}
+
+	msg := fmt.Sprintf("🔑 Password for **%s**: `%s`", server, p.Password)
+	if idx := slices.IndexFunc(cfg.Servers, func(s Server) bool { return s.Name == server }); idx >= 0 {
+		ip := cfg.Servers[idx].IP
+		if ip == "" {
+			ip = cfg.ServerIP
+		}
+		msg += fmt.Sprintf(" — [Join Server](%s)", joinURL(ip, cfg.Servers[idx].Port))
+	}
+	ch, err := b.discord.session.UserChannelCreate(user.id)
+	if err == nil {
+		_, err = b.discord.session.ChannelMessageSend(ch.ID, msg)
+	}
+	if err != nil {
+		log.Printf("Error sending the password of '%s' to %s: %v", server, user.name, err)
+		return "❌ Could not DM you; allow direct messages from server members and try again"
+	}
+	log.Printf("Audit: %s received the password of '%s' by DM", user.name, server)
+	return fmt.Sprintf("📬 Sent the password of **%s** to your DMs", server)
+}
+
+// passwordManager adapts the bot to api.PasswordManager
+type passwordManager struct {
+	bot *Bot
+}
+
+// Passwords implements api.PasswordManager
+func (m *passwordManager) Passwords() []api.ServerPassword {
+	out := []api.ServerPassword{}
+	cfg := m.bot.configManager.GetConfig()
+	if cfg == nil {
+		return out
+	}
+	for _, server := range slices.Sorted(maps.Keys(cfg.Passwords)) {
+		out = append(out, cfg.Passwords[server].apiPassword(server))
+	}
+	return out
+}
+
+// RotatePassword implements api.PasswordManager
+func (m *passwordManager) RotatePassword(server string) (api.ServerPassword, error) {
+	p, err := m.bot.rotatePassword(server, time.Now())
+	if err != nil {
+		return api.ServerPassword{}, err
+	}
+	return p.apiPassword(server), nil
+}
+
+func (p ServerPassword) apiPassword(server string) api.ServerPassword {
+	return api.ServerPassword{Server: server, Password: p.Password, Roles: slices.Clone(p.Roles), RotatedAt: p.RotatedAt}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// eventRole is the role allowed to receive Track #1's password in these tests
+const eventRole = "123456789012345678"
+
+// testPasswordConfig gives Track #1 the password "hunter2" for eventRole
+func testPasswordConfig() *Config {
+	cfg := testMaintenanceConfig()
+	initializeServerIPs(cfg)
+	cfg.Passwords = map[string]ServerPassword{"Track #1": {Password: "hunter2", Roles: []string{eventRole}}}
+	return cfg
+}
+
+// TestPasswordCommand tests that only members with the role get the password, by DM
+func TestPasswordCommand(t *testing.T) {
+	f := newFakeDiscord()
+	b := newFakeDiscordBot(t, f, testPasswordConfig())
+	opts := []*discordgo.ApplicationCommandInteractionDataOption{{Name: "server", Type: discordgo.ApplicationCommandOptionString, Value: "Track #1"}}
+	member := invoker{id: "11", name: "alice", roles: []string{eventRole}}
+
+	if !passwordCommand().enabled(b) {
+		t.Fatal("Expected /password enabled with a configured password")
+	}
+	if reply := runPasswordCommand(b, invoker{id: "12", name: "bob", roles: []string{"999"}}, opts); !strings.Contains(reply, "You need one of the event roles") {
+		t.Errorf("Expected bob to be refused, got %q", reply)
+	}
+	if msgs := f.channelMessages("dm-12"); len(msgs) != 0 {
+		t.Errorf("Expected no DM to bob, got %+v", msgs)
+	}
+	if reply := runPasswordCommand(b, member, opts); !strings.Contains(reply, "Sent the password") {
+		t.Fatalf("Unexpected reply %q", reply)
+	}
+	msgs := f.channelMessages("dm-11")
+	if len(msgs) != 1 || !strings.Contains(msgs[0].Content, "`hunter2`") || !strings.Contains(msgs[0].Content, "8082") {
+		t.Errorf("Expected the password and join link by DM, got %+v", msgs)
+	}
+
+	f.failNext("ChannelMessageSend", discordAPIError(http.StatusForbidden, discordgo.ErrCodeCannotSendMessagesToThisUser))
+	if reply := runPasswordCommand(b, member, opts); !strings.Contains(reply, "Could not DM you") {
+		t.Errorf("Expected closed DMs to be reported, got %q", reply)
+	}
+
+	other := []*discordgo.ApplicationCommandInteractionDataOption{{Name: "server", Type: discordgo.ApplicationCommandOptionString, Value: "Drift #1"}}
+	if reply := runPasswordCommand(b, member, other); !strings.Contains(reply, "has no password") {
+		t.Errorf("Unexpected reply %q", reply)
+	}
+	focused := &discordgo.ApplicationCommandInteractionDataOption{Name: "server", Type: discordgo.ApplicationCommandOptionString, Value: ""}
+	if choices := completePasswordServer(b, focused); len(choices) != 1 || choices[0].Name != "Track #1" {
+		t.Errorf("Expected only Track #1 suggested, got %+v", choices)
+	}
+}
+
+// TestRotatePassword tests that rotation saves a new generated password and keeps the roles
+func TestRotatePassword(t *testing.T) {
+	cfg := testPasswordConfig()
+	cm := NewConfigManager(filepath.Join(t.TempDir(), "config.json"), cfg)
+	if err := cm.WriteConfig(cfg); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+	b := newTestBot(cfg)
+	b.configManager = cm
+	m := &passwordManager{bot: b}
+
+	p, err := m.RotatePassword("Track #1")
+	if err != nil {
+		t.Fatalf("RotatePassword failed: %v", err)
+	}
+	if len(p.Password) != rotatedPasswordLen || p.Password == "hunter2" || p.RotatedAt == "" || p.Roles[0] != eventRole {
+		t.Errorf("Unexpected rotated password %+v", p)
+	}
+	if got := b.configManager.GetConfig().Passwords["Track #1"]; got.Password != p.Password {
+		t.Errorf("Expected the new password saved, got %+v", got)
+	}
+	if _, err := time.Parse(time.RFC3339, p.RotatedAt); err != nil {
+		t.Errorf("rotated_at = %q: %v", p.RotatedAt, err)
+	}
+	if _, err := m.RotatePassword("Drift #1"); !errors.Is(err, api.ErrUnknownServer) {
+		t.Errorf("Expected ErrUnknownServer, got %v", err)
+	}
+	if list := m.Passwords(); len(list) != 1 || list[0].Server != "Track #1" {
+		t.Errorf("Unexpected list %+v", list)
+	}
+}
+
+// TestConfigAny_RedactsPasswords tests that the API reads passwords redacted and that writing the redacted
+// value back keeps the saved password
+func TestConfigAny_RedactsPasswords(t *testing.T) {
+	cfg := testPasswordConfig()
+	cm := NewConfigManager(filepath.Join(t.TempDir(), "config.json"), cfg)
+	if err := cm.WriteConfig(cfg); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+
+	served := cm.GetConfigAny().(*Config)
+	if got := served.Passwords["Track #1"].Password; got != redactedPassword {
+		t.Fatalf("Expected the password redacted, got %q", got)
+	}
+	if cm.GetConfig().Passwords["Track #1"].Password != "hunter2" {
+		t.Fatal("Expected redaction not to touch the saved config")
+	}
+
+	// PUT of what GET returned
+	served.UpdateInterval = 45
+	if err := cm.WriteConfigAny(served); err != nil {
+		t.Fatalf("WriteConfigAny failed: %v", err)
+	}
+	if got := cm.GetConfig().Passwords["Track #1"].Password; got != "hunter2" {
+		t.Errorf("Expected the password kept on PUT, got %q", got)
+	}
+
+	// PATCH with the redacted value and new roles, then with a new password
+	patch := map[string]interface{}{"passwords": map[string]interface{}{"Track #1": map[string]interface{}{
+		"password": redactedPassword, "roles": []interface{}{eventRole, "223456789012345678"}}}}
+	if err := cm.UpdateConfig(patch); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+	if got := cm.GetConfig().Passwords["Track #1"]; got.Password != "hunter2" || len(got.Roles) != 2 {
+		t.Errorf("Expected the password kept on PATCH, got %+v", got)
+	}
+	patch = map[string]interface{}{"passwords": map[string]interface{}{"Track #1": map[string]interface{}{"password": "swordfish"}}}
+	if err := cm.UpdateConfig(patch); err != nil {
+		t.Fatalf("UpdateConfig failed: %v", err)
+	}
+	if got := cm.GetConfig().Passwords["Track #1"].Password; got != "swordfish" {
+		t.Errorf("Expected the new password saved, got %q", got)
+	}
+
+	// A redacted password for a server without a saved one cannot be resolved
+	patch = map[string]interface{}{"passwords": map[string]interface{}{"Drift #1": map[string]interface{}{
+		"password": redactedPassword, "roles": []interface{}{eventRole}}}}
+	if err := cm.UpdateConfig(patch); err == nil || !strings.Contains(err.Error(), "no password is saved") {
+		t.Errorf("Expected an error for a redacted new password, got %v", err)
+	}
+}
+
+// TestValidatePasswords tests server names, password characters and roles
+func TestValidatePasswords(t *testing.T) {
+	servers := testMaintenanceConfig().Servers
+	valid := ServerPassword{Password: "hunter2", Roles: []string{eventRole}}
+	if err := validatePasswords(map[string]ServerPassword{"Track #1": valid}, servers); err != nil {
+		t.Errorf("Expected valid passwords, got %v", err)
+	}
+	invalid := map[string]map[string]ServerPassword{
+		"unknown server": {"Rally #1": valid},
+		"empty":          {"Track #1": {Roles: valid.Roles}},
+		"space":          {"Track #1": {Password: "hunter 2", Roles: valid.Roles}},
+		"no roles":       {"Track #1": {Password: "hunter2"}},
+		"role name":      {"Track #1": {Password: "hunter2", Roles: []string{"Event Drivers"}}},
+		"rotated_at":     {"Track #1": {Password: "hunter2", Roles: valid.Roles, RotatedAt: "yesterday"}},
+	}
+	for name, p := range invalid {
+		if err := validatePasswords(p, servers); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// TestGeneratePassword tests length and alphabet of generated passwords
+func TestGeneratePassword(t *testing.T) {
+	a, b := generatePassword(), generatePassword()
+	if len(a) != rotatedPasswordLen || a == b {
+		t.Errorf("Expected two different %d character passwords, got %q and %q", rotatedPasswordLen, a, b)
+	}
+	if strings.Trim(a, passwordAlphabet) != "" {
+		t.Errorf("Password %q uses characters outside the alphabet", a)
+	}
+}
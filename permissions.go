@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= PERMISSION SELF-CHECK =================
+
+// channelPermission is a Discord permission the bot uses in the status channel
+type channelPermission struct {
+	bit     int64
+	name    string
+	purpose string
+}
+
+// statusChannelPermissions are checked on ready; Attach Files is added when the banner is attached
+var statusChannelPermissions = []channelPermission{
+	{discordgo.PermissionViewChannel, "View Channel", "see the status channel"},
+	{discordgo.PermissionSendMessages, "Send Messages", "post the status message and alerts"},
+	{discordgo.PermissionEmbedLinks, "Embed Links", "render the status embed"},
+	{discordgo.PermissionReadMessageHistory, "Read Message History", "find old status messages at startup"},
+	{discordgo.PermissionManageMessages, "Manage Messages", "clean up old messages"},
+}
+
+// bannerAttachPermission is required when DISCORD_ATTACH_BANNER is enabled
+var bannerAttachPermission = channelPermission{discordgo.PermissionAttachFiles, "Attach Files", "attach the status banner"}
+
+// missingChannelPermissions returns the permissions not granted by perms
+func missingChannelPermissions(perms int64, attachBanner bool) []channelPermission {
+	required := statusChannelPermissions
+	if attachBanner {
+		required = append(append([]channelPermission{}, required...), bannerAttachPermission)
+	}
+	var missing []channelPermission
+	for _, p := range required {
+		if perms&p.bit == 0 {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// permissionReport is the result of the last permission self-check
+type permissionReport struct {
+	channelID string
+	err       error
+	missing   []channelPermission
+}
+
+// healthCheck renders the report for /health
+func (r *permissionReport) healthCheck() api.HealthCheckResult {
+	res := api.HealthCheckResult{Name: "discord_permissions", OK: r.err == nil && len(r.missing) == 0}
+	switch {
+	case r.err != nil:
+		res.Detail = fmt.Sprintf("could not check permissions in channel %s: %v", r.channelID, r.err)
+	case len(r.missing) > 0:
+		names := make([]string, len(r.missing))
+		for i, p := range r.missing {
+			names[i] = p.name
+		}
+		res.Missing = names
+		res.Detail = fmt.Sprintf("missing in channel %s: %s", r.channelID, strings.Join(names, ", "))
+	default:
+		res.Detail = "all required permissions granted in channel " + r.channelID
+	}
+	return res
+}
+
+// checkPermissions verifies the bot's permissions in the status channel and logs each missing one
+// Runs on ready so misconfiguration shows up at startup instead of as opaque 403s later
+func (b *Bot) checkPermissions() {
+	channelID := b.discord.channel()
+	report := &permissionReport{channelID: channelID}
+	perms, err := b.discord.session.UserChannelPermissions(b.discord.session.BotUser().ID, channelID)
+	if err != nil {
+		report.err = err
+		log.Printf("Warning: could not check permissions in channel %s: %v", channelID, err)
+	} else {
+		report.missing = missingChannelPermissions(perms, b.banner != nil && b.banner.attach)
+		for _, p := range report.missing {
+			log.Printf("Warning: missing Discord permission %q in channel %s (needed to %s)", p.name, channelID, p.purpose)
+		}
+		if len(report.missing) == 0 {
+			log.Printf("Permission check passed for channel %s", channelID)
+		}
+	}
+	b.permissions.Store(report)
+	b.auditPrivileges(perms, err)
+}
+
+// botHealthReporter adapts the bot's self-checks to api.HealthReporter
+type botHealthReporter struct {
+	bot *Bot
+}
+
+// HealthChecks implements api.HealthReporter (empty until the first check ran)
+func (h *botHealthReporter) HealthChecks() []api.HealthCheckResult {
+	checks := []api.HealthCheckResult{}
+	if report := h.bot.permissions.Load(); report != nil {
+		checks = append(checks, report.healthCheck())
+	}
+	if h.bot.offsite != nil {
+		checks = append(checks, h.bot.offsite.healthCheck(time.Now()))
+	}
+	if h.bot.versions != nil {
+		checks = append(checks, h.bot.versions.healthCheck())
+	}
+	return checks
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// TestMissingChannelPermissions tests detection of each missing permission and the banner-only Attach Files
+func TestMissingChannelPermissions(t *testing.T) {
+	all := int64(discordgo.PermissionViewChannel | discordgo.PermissionSendMessages | discordgo.PermissionEmbedLinks |
+		discordgo.PermissionReadMessageHistory | discordgo.PermissionManageMessages)
+
+	if missing := missingChannelPermissions(all, false); len(missing) != 0 {
+		t.Errorf("Expected nothing missing, got %v", missing)
+	}
+
+	missing := missingChannelPermissions(all&^discordgo.PermissionEmbedLinks&^discordgo.PermissionManageMessages, false)
+	var names []string
+	for _, p := range missing {
+		names = append(names, p.name)
+	}
+	if !reflect.DeepEqual(names, []string{"Embed Links", "Manage Messages"}) {
+		t.Errorf("Unexpected missing permissions %v", names)
+	}
+
+	if missing := missingChannelPermissions(all, true); len(missing) != 1 || missing[0].name != "Attach Files" {
+		t.Errorf("Expected Attach Files missing with banner attachments, got %v", missing)
+	}
+	if missing := missingChannelPermissions(discordgo.PermissionAll, true); len(missing) != 0 {
+		t.Errorf("Expected administrator to pass, got %v", missing)
+	}
+}
+
+// TestPermissionReport_HealthCheck tests the /health rendering of passed, failed and unknown checks
+func TestPermissionReport_HealthCheck(t *testing.T) {
+	ok := (&permissionReport{channelID: "42"}).healthCheck()
+	if !ok.OK || ok.Name != "discord_permissions" || len(ok.Missing) != 0 {
+		t.Errorf("Unexpected passing check %+v", ok)
+	}
+
+	failed := (&permissionReport{channelID: "42", missing: []channelPermission{bannerAttachPermission}}).healthCheck()
+	if failed.OK || !reflect.DeepEqual(failed.Missing, []string{"Attach Files"}) || failed.Detail != "missing in channel 42: Attach Files" {
+		t.Errorf("Unexpected failing check %+v", failed)
+	}
+
+	unknown := (&permissionReport{channelID: "42", err: errors.New("HTTP 404")}).healthCheck()
+	if unknown.OK || unknown.Detail == "" {
+		t.Errorf("Unexpected check for lookup error %+v", unknown)
+	}
+}
+
+// TestBotHealthReporter tests that no check is reported before the first self-check
+func TestBotHealthReporter(t *testing.T) {
+	b := newTestBot(nil)
+	h := &botHealthReporter{bot: b}
+	if checks := h.HealthChecks(); len(checks) != 0 {
+		t.Errorf("Expected no checks before ready, got %v", checks)
+	}
+	b.permissions.Store(&permissionReport{channelID: "42"})
+	if checks := h.HealthChecks(); len(checks) != 1 || !checks[0].OK {
+		t.Errorf("Expected one passing check, got %v", checks)
+	}
+}
@@ -0,0 +1,11 @@
+// Package atomicfile replaces files so readers see either the old or the new content, never a partial write.
+// Callers write the new content to a temp file in the target's directory and pass it to Replace.
+// POSIX rename(2) does this atomically; Windows needs ReplaceFileW, which also keeps the target's ACLs
+// and tolerates the short-lived locks virus scanners and editors hold on recently written files.
+package atomicfile
+
+// Replace moves src over dst, creating dst if it does not exist. src and dst must be on the same volume
+// (create src next to dst). On failure src is left in place for the caller to remove
+func Replace(src, dst string) error {
+	return replace(src, dst)
+}
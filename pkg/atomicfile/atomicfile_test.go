@@ -0,0 +1,57 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTemp writes data to a temp file next to dst, as callers of Replace do
+func writeTemp(t *testing.T, dst, data string) string {
+	t.Helper()
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "replace-*.tmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp.WriteString(data)
+	tmp.Close()
+	return tmp.Name()
+}
+
+func TestReplace(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "config.json")
+
+	// New target
+	if err := Replace(writeTemp(t, dst, "v1"), dst); err != nil {
+		t.Fatalf("Replace (new file) failed: %v", err)
+	}
+	// Existing target, held open by a reader like an editor or the config watcher
+	reader, err := os.Open(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if err := Replace(writeTemp(t, dst, "v2"), dst); err != nil {
+		t.Fatalf("Replace (existing file) failed: %v", err)
+	}
+
+	if data, _ := os.ReadFile(dst); string(data) != "v2" {
+		t.Errorf("Expected v2, got %q", data)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(dst)); len(entries) != 1 {
+		t.Errorf("Expected only the target left, got %d files", len(entries))
+	}
+}
+
+func TestReplace_MissingSource(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "config.json")
+	os.WriteFile(dst, []byte("v1"), 0644)
+
+	if err := Replace(filepath.Join(dir, "missing.tmp"), dst); err == nil {
+		t.Error("Expected an error for a missing source")
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "v1" {
+		t.Errorf("Expected the target untouched, got %q", data)
+	}
+}
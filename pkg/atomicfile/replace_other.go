@@ -0,0 +1,10 @@
+//go:build !windows
+
+package atomicfile
+
+import "os"
+
+// replace relies on rename(2) replacing dst atomically
+func replace(src, dst string) error {
+	return os.Rename(src, dst)
+}
@@ -0,0 +1,83 @@
+package atomicfile
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var procReplaceFileW = syscall.NewLazyDLL("kernel32.dll").NewProc("ReplaceFileW")
+
+// replaceFileIgnoreMergeErrors keeps going when the target's attributes or ACLs cannot be merged into src
+const replaceFileIgnoreMergeErrors = 0x00000002
+
+// Windows error codes Replace retries: another process has the file open for a moment
+const (
+	errorAccessDenied             syscall.Errno = 5
+	errorSharingViolation         syscall.Errno = 32
+	errorUnableToRemoveReplaced   syscall.Errno = 1175
+	errorUnableToMoveReplacement  syscall.Errno = 1176
+	errorUnableToMoveReplacement2 syscall.Errno = 1177
+)
+
+// replaceRetries and replaceRetryDelay bound how long a locked target is waited for (about one second)
+const (
+	replaceRetries    = 10
+	replaceRetryDelay = 100 * time.Millisecond
+)
+
+// replace uses ReplaceFileW for an existing dst (MoveFileEx via os.Rename fails while another process
+// has dst open and drops its ACLs) and os.Rename for a new one. Transient sharing errors are retried
+func replace(src, dst string) error {
+	var err error
+	for attempt := 0; attempt < replaceRetries; attempt++ {
+		if _, statErr := os.Stat(dst); errors.Is(statErr, os.ErrNotExist) {
+			err = os.Rename(src, dst)
+		} else {
+			err = replaceFile(src, dst)
+		}
+		if err == nil || !transient(err) {
+			return err
+		}
+		time.Sleep(replaceRetryDelay)
+	}
+	return err
+}
+
+// replaceFile calls ReplaceFileW(dst, src) without a backup file
+func replaceFile(src, dst string) error {
+	dstPtr, err := syscall.UTF16PtrFromString(dst)
+	if err != nil {
+		return err
+	}
+	srcPtr, err := syscall.UTF16PtrFromString(src)
+	if err != nil {
+		return err
+	}
+	ok, _, callErr := procReplaceFileW.Call(
+		uintptr(unsafe.Pointer(dstPtr)),
+		uintptr(unsafe.Pointer(srcPtr)),
+		0, // no backup file
+		replaceFileIgnoreMergeErrors,
+		0, 0,
+	)
+	if ok == 0 {
+		return &os.LinkError{Op: "replace", Old: src, New: dst, Err: callErr}
+	}
+	return nil
+}
+
+// transient reports errors caused by another process briefly holding src or dst open
+func transient(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	switch errno {
+	case errorAccessDenied, errorSharingViolation, errorUnableToRemoveReplaced, errorUnableToMoveReplacement, errorUnableToMoveReplacement2:
+		return true
+	}
+	return false
+}
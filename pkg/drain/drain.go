@@ -0,0 +1,65 @@
+// Package drain shuts HTTP servers down gracefully: stop accepting, close idle connections,
+// let in-flight requests finish within a deadline, then force-close whatever is left.
+// Shared by the API server and the proxy so both report drained request counts the same way.
+package drain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout is how long Shutdown waits for in-flight requests when no timeout is configured
+const DefaultTimeout = 30 * time.Second
+
+// Tracker counts in-flight requests so shutdown can report how many it drained
+type Tracker struct {
+	active atomic.Int64
+}
+
+// Wrap counts requests passing through next; install it as the outermost handler
+func (t *Tracker) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.active.Add(1)
+		defer t.active.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Active returns the number of requests currently being served
+func (t *Tracker) Active() int64 {
+	return t.active.Load()
+}
+
+// Shutdown drains srv: listeners close immediately, idle keep-alive connections are closed, and
+// active requests get up to timeout to finish (timeout <= 0 uses DefaultTimeout)
+// Requests still running at the deadline are cut off via srv.Close and reported in the returned error
+func Shutdown(srv *http.Server, t *Tracker, timeout time.Duration, logger *log.Logger, name string) error {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	inFlight := t.Active()
+	logger.Printf("%s: draining %d in-flight requests (timeout %v)", name, inFlight, timeout)
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := srv.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		aborted := t.Active()
+		srv.Close()
+		logger.Printf("%s: drain timed out after %v: %d requests drained, %d aborted", name, timeout, inFlight-aborted, aborted)
+		return fmt.Errorf("%s drain timed out after %v with %d requests still running", name, timeout, aborted)
+	}
+	if err != nil {
+		return fmt.Errorf("%s shutdown failed: %w", name, err)
+	}
+
+	logger.Printf("%s: drained %d requests in %v", name, inFlight, time.Since(start).Round(time.Millisecond))
+	return nil
+}
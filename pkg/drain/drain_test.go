@@ -0,0 +1,124 @@
+package drain
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a goroutine-safe log sink
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// startServer serves handler (wrapped by t) on a random port and returns its base URL
+func startServer(t *testing.T, tracker *Tracker, handler http.Handler) (*http.Server, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: tracker.Wrap(handler)}
+	go srv.Serve(ln)
+	return srv, "http://" + ln.Addr().String()
+}
+
+// TestShutdown_DrainsInFlight tests that a running request completes and is counted
+func TestShutdown_DrainsInFlight(t *testing.T) {
+	tracker := &Tracker{}
+	started := make(chan struct{})
+	srv, url := startServer(t, tracker, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		io.WriteString(w, "done")
+	}))
+
+	result := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			result <- "error: " + err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		result <- string(body)
+	}()
+	<-started
+
+	var logs syncBuffer
+	if err := Shutdown(srv, tracker, time.Second, log.New(&logs, "", 0), "test server"); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if got := <-result; got != "done" {
+		t.Errorf("In-flight request got %q, want done", got)
+	}
+	if !strings.Contains(logs.String(), "drained 1 requests") {
+		t.Errorf("Expected drained count in logs, got %q", logs.String())
+	}
+
+	// No new connections after shutdown
+	if _, err := http.Get(url); err == nil {
+		t.Error("Expected new requests to be refused after shutdown")
+	}
+}
+
+// TestShutdown_Timeout tests that requests exceeding the deadline are aborted and reported
+func TestShutdown_Timeout(t *testing.T) {
+	tracker := &Tracker{}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	srv, url := startServer(t, tracker, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+
+	go http.Get(url)
+	<-started
+
+	var logs syncBuffer
+	err := Shutdown(srv, tracker, 50*time.Millisecond, log.New(&logs, "", 0), "test server")
+	if err == nil || !strings.Contains(err.Error(), "1 requests still running") {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "1 aborted") {
+		t.Errorf("Expected aborted count in logs, got %q", logs.String())
+	}
+}
+
+// TestShutdown_Idle tests an immediate drain with no requests
+func TestShutdown_Idle(t *testing.T) {
+	tracker := &Tracker{}
+	srv, _ := startServer(t, tracker, http.NotFoundHandler())
+
+	var logs syncBuffer
+	if err := Shutdown(srv, tracker, 0, log.New(&logs, "", 0), "test server"); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if !strings.Contains(logs.String(), "timeout 30s") || !strings.Contains(logs.String(), "drained 0 requests") {
+		t.Errorf("Unexpected logs %q", logs.String())
+	}
+}
@@ -0,0 +1,243 @@
+// Package fakeserver simulates Assetto Corsa game servers answering GET /info, for tests and local demos.
+// Player counts follow a fixed sequence, servers can flap between online and offline on a schedule,
+// and responses can be delayed; randomness (latency jitter) comes from a seed, so runs are reproducible.
+package fakeserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Offline modes: how a server looks to the poller while it is down
+const (
+	// OfflineError answers 503 Service Unavailable (default)
+	OfflineError = "error"
+	// OfflineTimeout never answers; the request hangs until the client gives up
+	OfflineTimeout = "timeout"
+	// OfflineGarbage answers 200 with a body that is not JSON (proxy error page)
+	OfflineGarbage = "garbage"
+)
+
+// Duration is a time.Duration read from JSON as a Go duration string ("250ms", "2m")
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"250ms\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Spec describes one simulated server
+type Spec struct {
+	Name       string   `json:"name"`
+	Port       int      `json:"port"`
+	Track      string   `json:"track"`       // content path as AC reports it (default content/tracks/ks_vallelunga)
+	Players    []int    `json:"players"`     // player counts cycled one step per answered poll (default [0])
+	MaxPlayers int      `json:"max_players"` // default 24
+	Latency    Duration `json:"latency"`     // fixed delay before every response
+	Jitter     Duration `json:"jitter"`      // extra random delay up to this value, drawn from the seed
+	FlapUp     Duration `json:"flap_up"`     // online part of the flap cycle (0 with FlapDown 0 = always online)
+	FlapDown   Duration `json:"flap_down"`   // offline part of the flap cycle (FlapUp 0 = always offline)
+	Offline    string   `json:"offline"`     // OfflineError, OfflineTimeout or OfflineGarbage
+}
+
+// File is the JSON layout of a simulator config file
+type File struct {
+	Seed    uint64 `json:"seed"`
+	Servers []Spec `json:"servers"`
+}
+
+// LoadFile reads and validates a simulator config file
+func LoadFile(path string) (*File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var f File
+	dec := json.NewDecoder(file)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(f.Servers) == 0 {
+		return nil, fmt.Errorf("%s: no servers defined", path)
+	}
+	for i := range f.Servers {
+		if err := f.Servers[i].Validate(); err != nil {
+			return nil, fmt.Errorf("%s: server %d: %w", path, i+1, err)
+		}
+	}
+	return &f, nil
+}
+
+// Validate checks the spec and fills in defaults
+func (s *Spec) Validate() error {
+	if s.Port < 1 || s.Port > 65535 {
+		return fmt.Errorf("port %d out of range", s.Port)
+	}
+	if s.Name == "" {
+		s.Name = fmt.Sprintf("Fake Server %d", s.Port)
+	}
+	if s.Track == "" {
+		s.Track = "content/tracks/ks_vallelunga"
+	}
+	if s.MaxPlayers == 0 {
+		s.MaxPlayers = 24
+	}
+	if len(s.Players) == 0 {
+		s.Players = []int{0}
+	}
+	for _, n := range s.Players {
+		if n < 0 || n > s.MaxPlayers {
+			return fmt.Errorf("player count %d outside 0..%d", n, s.MaxPlayers)
+		}
+	}
+	if s.Latency < 0 || s.Jitter < 0 || s.FlapUp < 0 || s.FlapDown < 0 {
+		return fmt.Errorf("durations must not be negative")
+	}
+	switch s.Offline {
+	case "":
+		s.Offline = OfflineError
+	case OfflineError, OfflineTimeout, OfflineGarbage:
+	default:
+		return fmt.Errorf("offline must be %s, %s or %s, got %q", OfflineError, OfflineTimeout, OfflineGarbage, s.Offline)
+	}
+	return nil
+}
+
+// Info is the subset of the AC /info response the simulator fills in
+type Info struct {
+	Name       string   `json:"name"`
+	Port       int      `json:"port"`
+	Clients    int      `json:"clients"`
+	MaxClients int      `json:"maxclients"`
+	Track      string   `json:"track"`
+	Cars       []string `json:"cars"`
+	Pass       bool     `json:"pass"`
+}
+
+// Simulator serves GET /info for one Spec
+type Simulator struct {
+	spec  Spec
+	start time.Time
+
+	// now and sleep are replaceable in tests
+	now   func() time.Time
+	sleep func(r *http.Request, d time.Duration)
+
+	mu    sync.Mutex
+	rng   *rand.Rand
+	polls int
+}
+
+// New creates a simulator for spec (call spec.Validate first); the flap schedule starts online now
+func New(spec Spec, seed uint64) *Simulator {
+	return &Simulator{
+		spec:  spec,
+		start: time.Now(),
+		now:   time.Now,
+		sleep: sleepOrCancel,
+		rng:   rand.New(rand.NewPCG(seed, uint64(spec.Port))),
+	}
+}
+
+// sleepOrCancel waits d or until the request is cancelled
+func sleepOrCancel(r *http.Request, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-r.Context().Done():
+	}
+}
+
+// Online reports whether the server is up at t according to the flap schedule
+func (s *Simulator) Online(t time.Time) bool {
+	up, down := time.Duration(s.spec.FlapUp), time.Duration(s.spec.FlapDown)
+	if down == 0 {
+		return true
+	}
+	if up == 0 {
+		return false
+	}
+	return t.Sub(s.start)%(up+down) < up
+}
+
+// next returns the response delay and, when online, the player count for this poll
+func (s *Simulator) next(online bool) (time.Duration, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delay := time.Duration(s.spec.Latency)
+	if s.spec.Jitter > 0 {
+		delay += time.Duration(s.rng.Int64N(int64(s.spec.Jitter) + 1))
+	}
+	if !online {
+		return delay, 0
+	}
+	players := s.spec.Players[s.polls%len(s.spec.Players)]
+	s.polls++
+	return delay, players
+}
+
+// ServeHTTP implements http.Handler
+func (s *Simulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/info" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	online := s.Online(s.now())
+	delay, players := s.next(online)
+	if !online && s.spec.Offline == OfflineTimeout {
+		<-r.Context().Done()
+		return
+	}
+	if delay > 0 {
+		s.sleep(r, delay)
+	}
+
+	if !online {
+		if s.spec.Offline == OfflineGarbage {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html><body>502 Bad Gateway</body></html>"))
+			return
+		}
+		http.Error(w, "server offline", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Info{
+		Name:       s.spec.Name,
+		Port:       s.spec.Port,
+		Clients:    players,
+		MaxClients: s.spec.MaxPlayers,
+		Track:      s.spec.Track,
+		Cars:       []string{"ks_mazda_mx5_cup"},
+	})
+}
@@ -0,0 +1,151 @@
+package fakeserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestSimulator validates spec and returns a simulator on a fake clock without real sleeps
+func newTestSimulator(t *testing.T, spec Spec, now *time.Time) (*Simulator, *[]time.Duration) {
+	t.Helper()
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	s := New(spec, 7)
+	s.start = *now
+	s.now = func() time.Time { return *now }
+	var slept []time.Duration
+	s.sleep = func(_ *http.Request, d time.Duration) { slept = append(slept, d) }
+	return s, &slept
+}
+
+func poll(s *Simulator) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/info", nil))
+	return rec
+}
+
+// TestSimulator_PlayerSequence tests the /info body and that player counts cycle per poll
+func TestSimulator_PlayerSequence(t *testing.T) {
+	now := time.Now()
+	s, _ := newTestSimulator(t, Spec{Name: "Drift 1", Port: 8081, Players: []int{2, 5}, MaxPlayers: 16}, &now)
+
+	var got []int
+	for i := 0; i < 3; i++ {
+		rec := poll(s)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Status = %d", rec.Code)
+		}
+		var info Info
+		if err := json.NewDecoder(rec.Body).Decode(&info); err != nil {
+			t.Fatalf("Failed to decode: %v", err)
+		}
+		if info.Name != "Drift 1" || info.MaxClients != 16 || info.Track != "content/tracks/ks_vallelunga" {
+			t.Errorf("Unexpected info %+v", info)
+		}
+		got = append(got, info.Clients)
+	}
+	if got[0] != 2 || got[1] != 5 || got[2] != 2 {
+		t.Errorf("Expected players 2,5,2, got %v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/other", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for other paths, got %d", rec.Code)
+	}
+}
+
+// TestSimulator_Flapping tests the online/offline schedule and offline modes
+func TestSimulator_Flapping(t *testing.T) {
+	now := time.Now()
+	s, _ := newTestSimulator(t, Spec{Port: 8081, Players: []int{3, 4}, FlapUp: Duration(time.Minute), FlapDown: Duration(30 * time.Second)}, &now)
+
+	if rec := poll(s); rec.Code != http.StatusOK {
+		t.Errorf("Expected online at start, got %d", rec.Code)
+	}
+	now = now.Add(70 * time.Second)
+	if rec := poll(s); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected offline in the down phase, got %d", rec.Code)
+	}
+	now = now.Add(30 * time.Second)
+	rec := poll(s)
+	var info Info
+	json.NewDecoder(rec.Body).Decode(&info)
+	if rec.Code != http.StatusOK || info.Clients != 4 {
+		t.Errorf("Expected online again with the next player count, got %d (%d players)", rec.Code, info.Clients)
+	}
+
+	garbage, _ := newTestSimulator(t, Spec{Port: 8082, FlapDown: Duration(time.Second), Offline: OfflineGarbage}, &now)
+	if rec := poll(garbage); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<html>") {
+		t.Errorf("Expected an HTML error page, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	hang, _ := newTestSimulator(t, Spec{Port: 8083, FlapDown: Duration(time.Second), Offline: OfflineTimeout}, &now)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	hang.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/info", nil).WithContext(ctx))
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("Expected the request to hang until cancelled")
+	}
+}
+
+// TestSimulator_Latency tests fixed latency plus seeded jitter
+func TestSimulator_Latency(t *testing.T) {
+	now := time.Now()
+	spec := Spec{Port: 8081, Latency: Duration(100 * time.Millisecond), Jitter: Duration(50 * time.Millisecond)}
+	a, sleptA := newTestSimulator(t, spec, &now)
+	b, sleptB := newTestSimulator(t, spec, &now)
+	for i := 0; i < 5; i++ {
+		poll(a)
+		poll(b)
+	}
+	for i, d := range *sleptA {
+		if d < 100*time.Millisecond || d > 150*time.Millisecond {
+			t.Errorf("Delay %v outside latency+jitter", d)
+		}
+		if (*sleptB)[i] != d {
+			t.Errorf("Expected the same delays for the same seed, got %v and %v", d, (*sleptB)[i])
+		}
+	}
+}
+
+// TestLoadFile tests parsing, defaults and rejected specs
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "servers.json")
+		os.WriteFile(path, []byte(content), 0644)
+		return path
+	}
+
+	f, err := LoadFile(write(`{"seed": 3, "servers": [{"port": 8081, "players": [1, 2], "latency": "250ms", "flap_up": "2m", "flap_down": "30s"}]}`))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	spec := f.Servers[0]
+	if f.Seed != 3 || spec.Latency != Duration(250*time.Millisecond) || spec.MaxPlayers != 24 || spec.Offline != OfflineError || spec.Name != "Fake Server 8081" {
+		t.Errorf("Unexpected file %+v", f)
+	}
+
+	for _, bad := range []string{
+		`{"servers": []}`,
+		`{"servers": [{"port": 0}]}`,
+		`{"servers": [{"port": 8081, "players": [30]}]}`,
+		`{"servers": [{"port": 8081, "latency": 5}]}`,
+		`{"servers": [{"port": 8081, "offline": "sometimes"}]}`,
+		`{"servers": [{"port": 8081, "colour": "red"}]}`,
+	} {
+		if _, err := LoadFile(write(bad)); err == nil {
+			t.Errorf("Expected error for %s", bad)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package listen
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// listenFdsStart is the first file descriptor passed by socket activation (SD_LISTEN_FDS_START)
+const listenFdsStart = 3
+
+// activation holds the sockets passed by the service manager, by FileDescriptorName
+// Each name can be taken once, so the API and the proxy cannot end up serving the same socket
+var activation struct {
+	once  sync.Once
+	mu    sync.Mutex
+	files map[string][]*os.File
+	err   error
+}
+
+// activated returns listeners for the sockets passed by systemd under name
+func activated(name string) ([]net.Listener, error) {
+	activation.once.Do(func() {
+		activation.files, activation.err = activationFiles(os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getenv("LISTEN_FDNAMES"), listenFdsStart)
+		// Like sd_listen_fds(1): the sockets are ours, children must not see them
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	})
+	if activation.err != nil {
+		return nil, activation.err
+	}
+
+	activation.mu.Lock()
+	files, ok := activation.files[name]
+	delete(activation.files, name)
+	activation.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no socket named %q passed by systemd (set FileDescriptorName=%s in the .socket unit)", name, name)
+	}
+
+	var listeners []net.Listener
+	for _, f := range files {
+		l, err := net.FileListener(f)
+		f.Close() // FileListener dups the descriptor
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("systemd socket %s: %w", name, err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// activationFiles reads the socket activation environment (see sd_listen_fds(3)): LISTEN_PID must be this
+// process, LISTEN_FDS sockets start at fd start, LISTEN_FDNAMES names them (colon-separated, "unknown" if unset)
+func activationFiles(pid, fds, names string, start int) (map[string][]*os.File, error) {
+	if fds == "" {
+		return nil, fmt.Errorf("no sockets passed by systemd (LISTEN_FDS is not set; start via a .socket unit)")
+	}
+	if pid != strconv.Itoa(os.Getpid()) {
+		return nil, fmt.Errorf("sockets passed by systemd are for process %s, not this one (%d)", pid, os.Getpid())
+	}
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", fds)
+	}
+	var nameList []string
+	if names != "" {
+		nameList = strings.Split(names, ":")
+	}
+
+	files := make(map[string][]*os.File)
+	for i := 0; i < n; i++ {
+		fd := start + i
+		name := "unknown"
+		if i < len(nameList) && nameList[i] != "" {
+			name = nameList[i]
+		}
+		if err := closeOnExec(fd); err != nil {
+			return nil, fmt.Errorf("systemd socket %d: %w", fd, err)
+		}
+		files[name] = append(files[name], os.NewFile(uintptr(fd), "systemd:"+name))
+	}
+	return files, nil
+}
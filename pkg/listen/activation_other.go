@@ -0,0 +1,10 @@
+//go:build !unix
+
+package listen
+
+import "errors"
+
+// closeOnExec fails: socket activation passes Unix file descriptors, which this platform does not have
+func closeOnExec(fd int) error {
+	return errors.New("socket activation is not supported on this platform")
+}
@@ -0,0 +1,61 @@
+//go:build unix
+
+package listen
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestActivationFiles(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	f, err := l.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	pid := strconv.Itoa(os.Getpid())
+	// passedFd duplicates the socket, as if systemd had passed it; activationFiles takes ownership of the copy
+	passedFd := func() int {
+		fd, err := syscall.Dup(int(f.Fd()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fd
+	}
+
+	files, err := activationFiles(pid, "1", "api", passedFd())
+	if err != nil {
+		t.Fatalf("activationFiles failed: %v", err)
+	}
+	if len(files["api"]) != 1 {
+		t.Fatalf("Expected one socket named api, got %v", files)
+	}
+	passed, err := net.FileListener(files["api"][0])
+	files["api"][0].Close()
+	if err != nil {
+		t.Fatalf("FileListener failed: %v", err)
+	}
+	defer passed.Close()
+	if body := get(t, passed, func() (net.Conn, error) { return net.Dial("tcp", l.Addr().String()) }); body != "ok" {
+		t.Errorf("Expected ok over the passed socket, got %q", body)
+	}
+
+	files, _ = activationFiles(pid, "1", "", passedFd())
+	if len(files["unknown"]) != 1 {
+		t.Fatalf("Expected an unnamed socket as unknown, got %v", files)
+	}
+	files["unknown"][0].Close()
+	for _, tt := range []struct{ pid, fds string }{{pid, ""}, {"1", "1"}, {pid, "x"}} {
+		if _, err := activationFiles(tt.pid, tt.fds, "", -1); err == nil {
+			t.Errorf("Expected error for LISTEN_PID=%s LISTEN_FDS=%s", tt.pid, tt.fds)
+		}
+	}
+}
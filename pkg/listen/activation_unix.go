@@ -0,0 +1,11 @@
+//go:build unix
+
+package listen
+
+import "syscall"
+
+// closeOnExec keeps a passed socket from leaking into child processes
+func closeOnExec(fd int) error {
+	syscall.CloseOnExec(fd)
+	return nil
+}
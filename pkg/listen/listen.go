@@ -0,0 +1,169 @@
+// Package listen opens the listeners of the API and proxy servers from an address list such as
+// "127.0.0.1:3001,unix:///run/absa/api.sock": TCP addresses, Unix sockets and sockets passed by
+// systemd socket activation (LISTEN_FDS). A sidecar can then reach a server over a socket without
+// a TCP port being exposed.
+package listen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// UnixSocketMode is the mode of created Unix sockets: owner and group (e.g. a sidecar sharing the group) may connect
+const UnixSocketMode fs.FileMode = 0660
+
+// Address is one parsed listen address
+type Address struct {
+	// Network is "tcp", "unix" or "systemd"
+	Network string
+	// Address is host:port for tcp, the socket path for unix and the FileDescriptorName for systemd
+	Address string
+}
+
+// String returns the address in the form Parse accepts
+func (a Address) String() string {
+	switch a.Network {
+	case "unix":
+		return "unix://" + a.Address
+	case "systemd":
+		return "systemd:" + a.Address
+	}
+	return a.Address
+}
+
+// Parse parses a comma-separated list of listen addresses:
+//
+//	3001, :3001, 127.0.0.1:3001, [::1]:3001, tcp://host:port   TCP
+//	unix:///run/absa/api.sock                                  Unix socket at an absolute path
+//	systemd:api                                                sockets passed by systemd with FileDescriptorName=api
+func Parse(spec string) ([]Address, error) {
+	var addrs []Address
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		addr, err := parseOne(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid listen address %q: %w", part, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no listen address")
+	}
+	return addrs, nil
+}
+
+func parseOne(s string) (Address, error) {
+	if path, ok := strings.CutPrefix(s, "unix://"); ok {
+		if !strings.HasPrefix(path, "/") {
+			return Address{}, errors.New("unix socket path must be absolute (unix:///path/to.sock)")
+		}
+		return Address{Network: "unix", Address: path}, nil
+	}
+	if name, ok := strings.CutPrefix(s, "systemd:"); ok {
+		if name == "" || strings.ContainsAny(name, ":/") {
+			return Address{}, errors.New("expected systemd:<FileDescriptorName>")
+		}
+		return Address{Network: "systemd", Address: name}, nil
+	}
+	s = strings.TrimPrefix(s, "tcp://")
+	if _, err := strconv.Atoi(s); err == nil {
+		s = ":" + s
+	}
+	_, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return Address{}, errors.New("expected a port, host:port, unix:///path or systemd:<name>")
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return Address{}, fmt.Errorf("port %q out of range", port)
+	}
+	return Address{Network: "tcp", Address: s}, nil
+}
+
+// Open parses spec and opens its listeners. On error the listeners opened so far are closed
+func Open(spec string) ([]net.Listener, error) {
+	addrs, err := Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	var listeners []net.Listener
+	fail := func(err error) ([]net.Listener, error) {
+		for _, l := range listeners {
+			l.Close()
+		}
+		return nil, err
+	}
+	for _, addr := range addrs {
+		switch addr.Network {
+		case "tcp":
+			l, err := net.Listen("tcp", addr.Address)
+			if err != nil {
+				return fail(err)
+			}
+			listeners = append(listeners, l)
+		case "unix":
+			l, err := listenUnix(addr.Address)
+			if err != nil {
+				return fail(err)
+			}
+			listeners = append(listeners, l)
+		case "systemd":
+			ls, err := activated(addr.Address)
+			if err != nil {
+				return fail(err)
+			}
+			listeners = append(listeners, ls...)
+		}
+	}
+	return listeners, nil
+}
+
+// listenUnix creates a Unix socket at path, replacing a stale socket left by a crashed process
+// Any other existing file is an error rather than being deleted. The socket is removed again on Close
+func listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, UnixSocketMode); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to chmod %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Name returns the address of l for logs: "127.0.0.1:3001" for TCP, "unix:/run/absa/api.sock" for sockets
+func Name(l net.Listener) string {
+	if l.Addr().Network() == "tcp" {
+		return l.Addr().String()
+	}
+	return l.Addr().Network() + ":" + l.Addr().String()
+}
+
+// Describe returns the addresses of listeners for logs, e.g. "127.0.0.1:3001, unix:/run/absa/api.sock"
+func Describe(listeners []net.Listener) string {
+	parts := make([]string, len(listeners))
+	for i, l := range listeners {
+		parts[i] = Name(l)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,128 @@
+package listen
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	addrs, err := Parse("3001, 127.0.0.1:3002,[::1]:3003,tcp://:3004,unix:///run/absa/api.sock,systemd:api")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := []Address{
+		{"tcp", ":3001"}, {"tcp", "127.0.0.1:3002"}, {"tcp", "[::1]:3003"}, {"tcp", ":3004"},
+		{"unix", "/run/absa/api.sock"}, {"systemd", "api"},
+	}
+	if len(addrs) != len(want) {
+		t.Fatalf("Expected %d addresses, got %v", len(want), addrs)
+	}
+	for i := range want {
+		if addrs[i] != want[i] {
+			t.Errorf("Address %d = %+v, want %+v", i, addrs[i], want[i])
+		}
+	}
+	if got := addrs[4].String(); got != "unix:///run/absa/api.sock" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for _, bad := range []string{"", " , ", "localhost", "99999", "unix://relative.sock", "systemd:", "host:port"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+// get serves one request on l and returns the body fetched over dial
+func get(t *testing.T, l net.Listener, dial func() (net.Conn, error)) string {
+	t.Helper()
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(_ context.Context, _, _ string) (net.Conn, error) { return dial() },
+	}}
+	resp, err := client.Get("http://localhost/")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestOpen_TCPAndUnix(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "api.sock")
+	listeners, err := Open("127.0.0.1:0,unix://" + sock)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if len(listeners) != 2 {
+		t.Fatalf("Expected 2 listeners, got %d", len(listeners))
+	}
+	if desc := Describe(listeners); !strings.HasPrefix(desc, "127.0.0.1:") || !strings.HasSuffix(desc, ", unix:"+sock) {
+		t.Errorf("Describe = %q", desc)
+	}
+	if info, err := os.Stat(sock); err != nil || info.Mode().Perm() != UnixSocketMode {
+		t.Errorf("Expected socket mode %o, got %v (%v)", UnixSocketMode, info, err)
+	}
+
+	if body := get(t, listeners[1], func() (net.Conn, error) { return net.Dial("unix", sock) }); body != "ok" {
+		t.Errorf("Expected ok over the socket, got %q", body)
+	}
+	listeners[0].Close()
+	if _, err := os.Stat(sock); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket removed on close, got %v", err)
+	}
+}
+
+func TestOpen_UnixSocketFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// A stale socket (no process behind it) is replaced
+	stale := filepath.Join(dir, "stale.sock")
+	l, err := net.Listen("unix", stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+	listeners, err := Open("unix://" + stale)
+	if err != nil {
+		t.Fatalf("Expected a stale socket to be replaced, got %v", err)
+	}
+
+	// A socket in use and a regular file are left alone
+	if _, err := Open("unix://" + stale); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("Expected an in-use error, got %v", err)
+	}
+	listeners[0].Close()
+
+	file := filepath.Join(dir, "config.json")
+	os.WriteFile(file, []byte("{}"), 0644)
+	if _, err := Open("unix://" + file); err == nil {
+		t.Error("Expected an error for a regular file")
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("Expected the regular file kept: %v", err)
+	}
+}
+
+func TestOpen_ClosesOnError(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "api.sock")
+	if _, err := Open("unix://" + sock + ",systemd:missing"); err == nil {
+		t.Fatal("Expected an error without socket activation")
+	}
+	if _, err := os.Stat(sock); !os.IsNotExist(err) {
+		t.Errorf("Expected the opened socket closed and removed, got %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+// Package metrics is a small Prometheus-compatible metrics registry shared by the API, the proxy and the
+// Discord bot, so one scrape of the API's /metrics covers the whole binary.
+//
+// Every metric carries a component label ("api", "proxy", "discord") and metrics measuring the same
+// thing in several components share one name (see shared.go), so a single dashboard can break them down.
+// The registry writes the Prometheus text exposition format; there is no dependency on client_golang.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the Prometheus text exposition format served by Handler
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are latency histogram buckets in seconds, from 5ms to 10s
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metric types as written in # TYPE lines
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// Registry holds metric families by name
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// Default is the registry of the process; the New* functions register in it
+var Default = NewRegistry()
+
+// NewRegistry returns an empty registry (tests use their own to get predictable output)
+func NewRegistry() *Registry {
+	return &Registry{families: map[string]*family{}}
+}
+
+// family is one metric name with its series, keyed by label values
+type family struct {
+	name    string
+	help    string
+	typ     string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series is one combination of label values
+type series struct {
+	values []string
+	// value is the counter or gauge value; fn, when set, is read at scrape time instead
+	value float64
+	fn    func() float64
+	// counts are cumulative per bucket (the last one is +Inf) for histograms
+	counts []uint64
+	sum    float64
+}
+
+// register returns the family called name, creating it on first use
+// Registering a name again with the same type and labels returns the existing family (packages and tests can
+// declare the metrics they share); a different type or label set is a programming error and panics
+func (reg *Registry) register(name, help, typ string, buckets []float64, labels []string) *family {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if f, ok := reg.families[name]; ok {
+		if f.typ != typ || !slices.Equal(f.labels, labels) {
+			panic(fmt.Sprintf("metrics: %s registered again as %s%v (was %s%v)", name, typ, labels, f.typ, f.labels))
+		}
+		return f
+	}
+	f := &family{name: name, help: help, typ: typ, labels: labels, buckets: buckets, series: map[string]*series{}}
+	reg.families[name] = f
+	return f
+}
+
+// get returns the series for values, creating it on first use (caller holds f.mu)
+func (f *family) get(values []string) *series {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s takes labels %v, got %d values", f.name, f.labels, len(values)))
+	}
+	key := seriesKey(values)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{values: slices.Clone(values)}
+		if f.typ == typeHistogram {
+			s.counts = make([]uint64, len(f.buckets)+1)
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// seriesKey joins label values into a map key (0xff never occurs in UTF-8)
+func seriesKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// add adds v to the series for values
+func (f *family) add(v float64, values []string) {
+	f.mu.Lock()
+	f.get(values).value += v
+	f.mu.Unlock()
+}
+
+// value returns the current value of the series for values (0 if it was never set)
+func (f *family) value(values []string) float64 {
+	f.mu.Lock()
+	s, ok := f.series[seriesKey(values)]
+	if !ok {
+		f.mu.Unlock()
+		return 0
+	}
+	v, fn := s.value, s.fn
+	f.mu.Unlock()
+	if fn != nil {
+		return fn()
+	}
+	return v
+}
+
+// setFunc makes the series for values read fn at scrape time
+func (f *family) setFunc(fn func() float64, values []string) {
+	f.mu.Lock()
+	f.get(values).fn = fn
+	f.mu.Unlock()
+}
+
+// Counter is a monotonically increasing count, such as requests or failures
+type Counter struct{ f *family }
+
+// NewCounter registers a counter in Default; names end in _total by convention
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.NewCounter(name, help, labels...)
+}
+
+// NewCounter registers a counter in reg
+func (reg *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{reg.register(name, help, typeCounter, nil, labels)}
+}
+
+// Inc adds one to the series for the label values
+func (c *Counter) Inc(values ...string) {
+	c.f.add(1, values)
+}
+
+// Add adds v (which must not be negative) to the series for the label values
+func (c *Counter) Add(v float64, values ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.f.name))
+	}
+	c.f.add(v, values)
+}
+
+// SetFunc reads the series for the label values from fn at scrape time, for counts kept elsewhere
+func (c *Counter) SetFunc(fn func() float64, values ...string) {
+	c.f.setFunc(fn, values)
+}
+
+// Value returns the count of the series for the label values
+func (c *Counter) Value(values ...string) float64 {
+	return c.f.value(values)
+}
+
+// Gauge is a value that goes up and down, such as active sessions
+type Gauge struct{ f *family }
+
+// NewGauge registers a gauge in Default
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return Default.NewGauge(name, help, labels...)
+}
+
+// NewGauge registers a gauge in reg
+func (reg *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{reg.register(name, help, typeGauge, nil, labels)}
+}
+
+// Set sets the series for the label values to v
+func (g *Gauge) Set(v float64, values ...string) {
+	g.f.mu.Lock()
+	g.f.get(values).value = v
+	g.f.mu.Unlock()
+}
+
+// Add adds v (negative to decrease) to the series for the label values
+func (g *Gauge) Add(v float64, values ...string) {
+	g.f.add(v, values)
+}
+
+// SetFunc reads the series for the label values from fn at scrape time
+func (g *Gauge) SetFunc(fn func() float64, values ...string) {
+	g.f.setFunc(fn, values)
+}
+
+// Value returns the value of the series for the label values
+func (g *Gauge) Value(values ...string) float64 {
+	return g.f.value(values)
+}
+
+// Histogram counts observations, such as latencies, in cumulative buckets
+type Histogram struct{ f *family }
+
+// NewHistogram registers a histogram with upper bounds buckets (sorted, without +Inf) in Default
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.NewHistogram(name, help, buckets, labels...)
+}
+
+// NewHistogram registers a histogram in reg
+func (reg *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{reg.register(name, help, typeHistogram, buckets, labels)}
+}
+
+// Observe records v in the series for the label values
+func (h *Histogram) Observe(v float64, values ...string) {
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+	s := h.f.get(values)
+	for i, bound := range h.f.buckets {
+		if v <= bound {
+			s.counts[i]++
+		}
+	}
+	s.counts[len(h.f.buckets)]++
+	s.sum += v
+}
+
+// Handler serves the Default registry
+func Handler() http.Handler {
+	return Default
+}
+
+// ServeHTTP writes the registry in the text exposition format
+func (reg *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	reg.Write(w)
+}
+
+// Write writes every family with at least one series, sorted by name and label values
+func (reg *Registry) Write(w io.Writer) error {
+	reg.mu.Lock()
+	families := make([]*family, 0, len(reg.families))
+	for _, f := range reg.families {
+		families = append(families, f)
+	}
+	reg.mu.Unlock()
+	slices.SortFunc(families, func(a, b *family) int { return strings.Compare(a.name, b.name) })
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// write writes the HELP, TYPE and sample lines of f
+// The series are copied under f.mu and functions are read after unlocking, so a function may take locks
+// of code that also updates metrics
+func (f *family) write(w io.Writer) {
+	f.mu.Lock()
+	snapshot := make([]series, 0, len(f.series))
+	for _, s := range f.series {
+		snapshot = append(snapshot, series{values: s.values, value: s.value, fn: s.fn, counts: slices.Clone(s.counts), sum: s.sum})
+	}
+	f.mu.Unlock()
+	if len(snapshot) == 0 {
+		return
+	}
+	slices.SortFunc(snapshot, func(a, b series) int { return slices.Compare(a.values, b.values) })
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, helpEscaper.Replace(f.help), f.name, f.typ)
+	for _, s := range snapshot {
+		labels := labelString(f.labels, s.values, "", "")
+		if f.typ != typeHistogram {
+			v := s.value
+			if s.fn != nil {
+				v = s.fn()
+			}
+			fmt.Fprintf(w, "%s%s %s\n", f.name, labels, formatValue(v))
+			continue
+		}
+		for i, bound := range f.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, labelString(f.labels, s.values, "le", formatValue(bound)), s.counts[i])
+		}
+		count := s.counts[len(f.buckets)]
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, labelString(f.labels, s.values, "le", "+Inf"), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, labels, formatValue(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, labels, count)
+	}
+}
+
+// labelString formats {name="value",...}, with an extra label (the histogram le) when extraName is set
+func labelString(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, name, labelEscaper.Replace(values[i]))
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, extraName, extraValue)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// labelEscaper escapes label values as the exposition format expects (backslash, quote, newline)
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// helpEscaper escapes HELP text (backslash, newline)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// formatValue writes floats the way Prometheus does (+Inf, -Inf, NaN, shortest representation)
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
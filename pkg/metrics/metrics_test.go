@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// output returns the exposition of reg
+func output(t *testing.T, reg *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := reg.Write(&b); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	return b.String()
+}
+
+func TestRegistry_Exposition(t *testing.T) {
+	reg := NewRegistry()
+	requests := reg.NewCounter("test_requests_total", "Requests\nserved", "component", "code")
+	sessions := reg.NewGauge("test_sessions_active", "Active sessions", "component")
+	latency := reg.NewHistogram("test_latency_seconds", "Latency", []float64{0.1, 1}, "component")
+	reg.NewCounter("test_unused_total", "Never incremented")
+
+	requests.Inc("proxy", "200")
+	requests.Add(2, "api", "200")
+	requests.Inc("api", "401")
+	sessions.SetFunc(func() float64 { return 3 }, "proxy")
+	latency.Observe(0.05, "proxy")
+	latency.Observe(0.5, "proxy")
+	latency.Observe(5, "proxy")
+
+	want := `# HELP test_latency_seconds Latency
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{component="proxy",le="0.1"} 1
+test_latency_seconds_bucket{component="proxy",le="1"} 2
+test_latency_seconds_bucket{component="proxy",le="+Inf"} 3
+test_latency_seconds_sum{component="proxy"} 5.55
+test_latency_seconds_count{component="proxy"} 3
+# HELP test_requests_total Requests\nserved
+# TYPE test_requests_total counter
+test_requests_total{component="api",code="200"} 2
+test_requests_total{component="api",code="401"} 1
+test_requests_total{component="proxy",code="200"} 1
+# HELP test_sessions_active Active sessions
+# TYPE test_sessions_active gauge
+test_sessions_active{component="proxy"} 3
+`
+	if got := output(t, reg); got != want {
+		t.Errorf("Unexpected exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegistry_LabelEscaping(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewGauge("test_info", "Info", "value").Set(1, "a\"b\\c\nd")
+	if got := output(t, reg); !strings.Contains(got, `test_info{value="a\"b\\c\nd"} 1`) {
+		t.Errorf("Expected escaped label value, got:\n%s", got)
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	reg := NewRegistry()
+	a := reg.NewCounter("test_total", "Test", "component")
+	b := reg.NewCounter("test_total", "Test", "component")
+	a.Inc("api")
+	b.Inc("api")
+	if got := output(t, reg); !strings.Contains(got, `test_total{component="api"} 2`) {
+		t.Errorf("Expected re-registration to share the series, got:\n%s", got)
+	}
+
+	for name, register := range map[string]func(){
+		"other type":   func() { reg.NewGauge("test_total", "Test", "component") },
+		"other labels": func() { reg.NewCounter("test_total", "Test", "component", "code") },
+		"label count":  func() { a.Inc("api", "200") },
+		"negative add": func() { a.Add(-1, "api") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			register()
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ObserveRequest(ComponentAPI, "BREW", 418, 20*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Header().Get("Content-Type") != ContentType {
+		t.Errorf("Unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `absa_http_requests_total{component="api",method="other",code="418"} 1`) ||
+		!strings.Contains(body, `absa_http_request_duration_seconds_count{component="api"} 1`) {
+		t.Errorf("Expected the request in the default registry, got:\n%s", body)
+	}
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Component label values
+const (
+	ComponentAPI     = "api"
+	ComponentProxy   = "proxy"
+	ComponentDiscord = "discord"
+	ComponentHistory = "history"
+)
+
+// Metrics recorded by more than one component, so dashboards can compare them by the component label
+var (
+	HTTPRequests = NewCounter("absa_http_requests_total",
+		"HTTP requests served, by component, method and status code", "component", "method", "code")
+	HTTPRequestDuration = NewHistogram("absa_http_request_duration_seconds",
+		"Time to serve HTTP requests, by component", DefaultBuckets, "component")
+	AuthFailures = NewCounter("absa_auth_failures_total",
+		"Rejected credentials, by component and reason (missing, malformed, invalid, locked)", "component", "reason")
+)
+
+// Auth failure reasons
+const (
+	AuthMissing   = "missing"
+	AuthMalformed = "malformed"
+	AuthInvalid   = "invalid"
+	// AuthLocked is a login refused unchecked after too many failures from one IP
+	AuthLocked = "locked"
+)
+
+// ObserveRequest records a served HTTP request
+// Unknown methods are counted as "other" so clients cannot create series at will
+func ObserveRequest(component, method string, code int, d time.Duration) {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions:
+	default:
+		method = "other"
+	}
+	HTTPRequests.Inc(component, method, strconv.Itoa(code))
+	HTTPRequestDuration.Observe(d.Seconds(), component)
+}
@@ -0,0 +1,302 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/atomicfile"
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id parameters (OWASP password storage recommendation: 19 MiB, 2 iterations, 1 thread)
+const (
+	argonMemory  = 19 * 1024
+	argonTime    = 2
+	argonThreads = 1
+	argonKeyLen  = 32
+	argonSaltLen = 16
+)
+
+// verifyCacheTTL is how long a successful login is remembered. Browsers resend Basic Auth with every
+// request, and each Argon2id check takes argonMemory of memory and tens of milliseconds.
+const verifyCacheTTL = 5 * time.Minute
+
+// argonSlots bounds the Argon2id checks running at once, and so their memory
+var argonSlots = make(chan struct{}, max(runtime.NumCPU(), 2))
+
+// MinPasswordLength matches the PROXY_PASSWORD minimum (OWASP minimum).
+const MinPasswordLength = 8
+
+// ErrUnknownAccount is returned by Remove for a username that is not in the file.
+var ErrUnknownAccount = errors.New("unknown account")
+
+// Accounts is a file of named admin accounts, one "username:argon2id-hash" per line.
+// Lines starting with # are comments. The file is re-read when it changes on disk,
+// so accounts added or removed with the CLI take effect without a restart.
+// Successful verifications are cached for verifyCacheTTL under an HMAC of user and password
+// with a per-process key; the cache is dropped whenever the accounts change.
+type Accounts struct {
+	path string
+
+	mu       sync.Mutex
+	hashes   map[string]string
+	modTime  time.Time
+	cacheKey []byte
+	verified map[string]time.Time
+	now      func() time.Time
+}
+
+// LoadAccounts reads the accounts file at path; a missing file is an empty account list.
+func LoadAccounts(path string) (*Accounts, error) {
+	cacheKey := make([]byte, 32)
+	if _, err := rand.Read(cacheKey); err != nil {
+		return nil, fmt.Errorf("failed to generate cache key: %w", err)
+	}
+	a := &Accounts{path: path, hashes: map[string]string{}, cacheKey: cacheKey, verified: map[string]time.Time{}, now: time.Now}
+	if err := a.reload(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// reload re-reads the file if its modification time changed (caller holds a.mu or owns a).
+func (a *Accounts) reload() error {
+	info, err := os.Stat(a.path)
+	if os.IsNotExist(err) {
+		a.hashes, a.modTime = map[string]string{}, time.Time{}
+		clear(a.verified)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read accounts file: %w", err)
+	}
+	if info.ModTime().Equal(a.modTime) && a.hashes != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(a.path)
+	if err != nil {
+		return fmt.Errorf("failed to read accounts file: %w", err)
+	}
+	hashes := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, hash, ok := strings.Cut(text, ":")
+		if !ok || name == "" || !strings.HasPrefix(hash, "$argon2id$") {
+			return fmt.Errorf("accounts file %s line %d: expected username:argon2id-hash", a.path, line)
+		}
+		hashes[name] = hash
+	}
+	a.hashes, a.modTime = hashes, info.ModTime()
+	clear(a.verified)
+	return nil
+}
+
+// Verify reports whether user and password match an account.
+// Unknown users are checked against a dummy hash so timing does not reveal which names exist.
+func (a *Accounts) Verify(user, password string) bool {
+	key := a.verifyKey(user, password)
+	a.mu.Lock()
+	if err := a.reload(); err != nil {
+		// Keep the last good list; a half-written file must not lock everyone out
+		log.Printf("WARN: proxy accounts: %v", err)
+	}
+	hash, ok := a.hashes[user]
+	if at, cached := a.verified[key]; ok && cached && a.now().Sub(at) < verifyCacheTTL {
+		a.mu.Unlock()
+		return true
+	}
+	a.mu.Unlock()
+
+	if !ok {
+		verifyPasswordLimited(dummyHash(), password)
+		return false
+	}
+	if !verifyPasswordLimited(hash, password) {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// An account changed during the check must be checked again next time
+	if a.hashes[user] == hash {
+		now := a.now()
+		for k, at := range a.verified {
+			if now.Sub(at) >= verifyCacheTTL {
+				delete(a.verified, k)
+			}
+		}
+		a.verified[key] = now
+	}
+	return true
+}
+
+// verifyKey is the cache key of a user/password pair; the password itself is never kept.
+func (a *Accounts) verifyKey(user, password string) string {
+	mac := hmac.New(sha256.New, a.cacheKey)
+	mac.Write([]byte(user))
+	mac.Write([]byte{0})
+	mac.Write([]byte(password))
+	return string(mac.Sum(nil))
+}
+
+// Names returns the account names in alphabetical order.
+func (a *Accounts) Names() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	names := make([]string, 0, len(a.hashes))
+	for name := range a.hashes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Add creates or replaces the account user with a new password hash and saves the file.
+func (a *Accounts) Add(user, password string) error {
+	if user == "" || strings.ContainsAny(user, ": \t\r\n") || strings.HasPrefix(user, "#") {
+		return fmt.Errorf("invalid username %q: must be non-empty without spaces or ':'", user)
+	}
+	if len(password) < MinPasswordLength {
+		return fmt.Errorf("password must be at least %d characters (got %d)", MinPasswordLength, len(password))
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.reload(); err != nil {
+		return err
+	}
+	a.hashes[user] = hash
+	clear(a.verified)
+	return a.save()
+}
+
+// Remove deletes the account user and saves the file.
+func (a *Accounts) Remove(user string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.reload(); err != nil {
+		return err
+	}
+	if _, ok := a.hashes[user]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownAccount, user)
+	}
+	delete(a.hashes, user)
+	clear(a.verified)
+	return a.save()
+}
+
+// save writes the accounts atomically with owner-only permissions (caller holds a.mu).
+func (a *Accounts) save() error {
+	names := make([]string, 0, len(a.hashes))
+	for name := range a.hashes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString("# Proxy admin accounts (username:argon2id-hash). Manage with: proxy-account add|remove|list\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s:%s\n", name, a.hashes[name])
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(a.path), ".accounts-*")
+	if err != nil {
+		return fmt.Errorf("failed to write accounts file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write accounts file: %w", err)
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write accounts file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write accounts file: %w", err)
+	}
+	if err := atomicfile.Replace(tmp.Name(), a.path); err != nil {
+		return fmt.Errorf("failed to write accounts file: %w", err)
+	}
+	if info, err := os.Stat(a.path); err == nil {
+		a.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// HashPassword returns an encoded Argon2id hash:
+// $argon2id$v=19$m=<KiB>,t=<iterations>,p=<threads>$<salt>$<key> (unpadded base64)
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argonMemory, argonTime, argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// verifyPassword checks password against an encoded Argon2id hash using the hash's own parameters.
+func verifyPassword(encoded, password string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false
+	}
+	var version int
+	var memory, iterations uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil || threads == 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+	got := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// verifyPasswordLimited is verifyPassword holding one of argonSlots.
+func verifyPasswordLimited(encoded, password string) bool {
+	argonSlots <- struct{}{}
+	defer func() { <-argonSlots }()
+	return verifyPassword(encoded, password)
+}
+
+// dummyHash is verified for unknown usernames so a failed login costs the same either way.
+// Computed on first use so programs that never verify a login do not pay for it.
+var dummyHash = sync.OnceValue(func() string {
+	h, _ := HashPassword("dummy-password-for-timing")
+	return h
+})
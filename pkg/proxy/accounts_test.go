@@ -0,0 +1,204 @@
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=19456,t=2,p=1$") {
+		t.Errorf("unexpected hash format %q", hash)
+	}
+	if !verifyPassword(hash, "correct horse") {
+		t.Error("expected password to verify")
+	}
+	if verifyPassword(hash, "wrong horse") {
+		t.Error("expected wrong password to fail")
+	}
+	if other, _ := HashPassword("correct horse"); other == hash {
+		t.Error("expected a fresh salt per hash")
+	}
+	if verifyPassword("$argon2id$garbage", "correct horse") {
+		t.Error("expected malformed hash to fail")
+	}
+}
+
+func TestAccounts_AddRemovePersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxy_accounts")
+
+	accounts, err := LoadAccounts(path)
+	if err != nil {
+		t.Fatalf("LoadAccounts on missing file: %v", err)
+	}
+	if len(accounts.Names()) != 0 {
+		t.Fatalf("expected no accounts, got %v", accounts.Names())
+	}
+
+	if err := accounts.Add("alice", "alicepass1"); err != nil {
+		t.Fatalf("Add alice: %v", err)
+	}
+	if err := accounts.Add("bob", "bobpass12"); err != nil {
+		t.Fatalf("Add bob: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("accounts file not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 && runtime.GOOS != "windows" {
+		t.Errorf("file mode = %o, want 600", perm)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "alicepass1") {
+		t.Error("plaintext password written to accounts file")
+	}
+
+	// A second instance (the running proxy) sees the accounts written by the CLI
+	reloaded, err := LoadAccounts(path)
+	if err != nil {
+		t.Fatalf("LoadAccounts: %v", err)
+	}
+	if got := strings.Join(reloaded.Names(), ","); got != "alice,bob" {
+		t.Errorf("Names() = %q, want alice,bob", got)
+	}
+	if !reloaded.Verify("alice", "alicepass1") || reloaded.Verify("alice", "bobpass12") {
+		t.Error("expected per-account passwords")
+	}
+	if reloaded.Verify("carol", "alicepass1") {
+		t.Error("expected unknown user to fail")
+	}
+
+	if err := accounts.Remove("bob"); err != nil {
+		t.Fatalf("Remove bob: %v", err)
+	}
+	if err := accounts.Remove("bob"); !errors.Is(err, ErrUnknownAccount) {
+		t.Errorf("Remove unknown = %v, want ErrUnknownAccount", err)
+	}
+
+	// Removal takes effect in the running proxy without a restart
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(path, future, future)
+	if reloaded.Verify("bob", "bobpass12") {
+		t.Error("expected removed account to be rejected after reload")
+	}
+}
+
+// TestAccounts_VerifyCache tests that a successful login is cached for verifyCacheTTL, never a
+// failure, and that changing the accounts drops the cache
+func TestAccounts_VerifyCache(t *testing.T) {
+	accounts, _ := LoadAccounts(filepath.Join(t.TempDir(), "proxy_accounts"))
+	accounts.Add("alice", "alicepass1")
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	accounts.now = func() time.Time { return now }
+
+	if accounts.Verify("alice", "wrongpass1") || len(accounts.verified) != 0 {
+		t.Fatal("Expected a failed login not to be cached")
+	}
+	if !accounts.Verify("alice", "alicepass1") || len(accounts.verified) != 1 {
+		t.Fatal("Expected the successful login cached")
+	}
+	for key := range accounts.verified {
+		if strings.Contains(key, "alicepass1") {
+			t.Error("Expected the cache key not to contain the password")
+		}
+	}
+	if accounts.Verify("alice", "alicepass2") {
+		t.Error("Expected the cache not to accept another password")
+	}
+
+	// Expired entries are checked again and pruned
+	now = now.Add(verifyCacheTTL)
+	if !accounts.Verify("alice", "alicepass1") || len(accounts.verified) != 1 {
+		t.Errorf("Expected one fresh cache entry, got %d", len(accounts.verified))
+	}
+
+	// A new password takes effect at once
+	accounts.Add("alice", "alicepass2")
+	if accounts.Verify("alice", "alicepass1") || !accounts.Verify("alice", "alicepass2") {
+		t.Error("Expected the cache dropped after a password change")
+	}
+	accounts.Remove("alice")
+	if accounts.Verify("alice", "alicepass2") {
+		t.Error("Expected a removed account rejected")
+	}
+}
+
+func TestAccounts_Validation(t *testing.T) {
+	accounts, _ := LoadAccounts(filepath.Join(t.TempDir(), "proxy_accounts"))
+	for _, name := range []string{"", "a b", "a:b", "#admin"} {
+		if err := accounts.Add(name, "password123"); err == nil {
+			t.Errorf("expected error for username %q", name)
+		}
+	}
+	if err := accounts.Add("alice", "short"); err == nil {
+		t.Error("expected error for short password")
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad")
+	os.WriteFile(bad, []byte("# comment\nalice:plaintext\n"), 0600)
+	if _, err := LoadAccounts(bad); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected line 2 error, got %v", err)
+	}
+}
+
+func TestConfigValidation_AccountsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxy_accounts")
+	cfg := Config{AccountsFile: path, BearerToken: "token"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "has no accounts") {
+		t.Errorf("expected empty accounts file error, got %v", err)
+	}
+
+	accounts, _ := LoadAccounts(path)
+	accounts.Add("alice", "alicepass1")
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected accounts file to replace PROXY_USER/PROXY_PASSWORD, got %v", err)
+	}
+
+	cfg.BearerToken = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected bearer token to stay required")
+	}
+}
+
+func TestAccessLog_AttributesAccount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxy_accounts")
+	accounts, _ := LoadAccounts(path)
+	accounts.Add("alice", "alicepass1")
+
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := AccessLog(BasicAuthFunc(accounts.Verify, nil, logger)(ok), logger)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/config", nil)
+	req.SetBasicAuth("alice", "alicepass1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if !strings.Contains(buf.String(), "PATCH /api/config from 192.0.2.1 as alice - 200") {
+		t.Errorf("expected account in access log, got %q", buf.String())
+	}
+
+	buf.Reset()
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("mallory", "guessing1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+	if !strings.Contains(buf.String(), `auth failed for user "mallory"`) || strings.Contains(buf.String(), " as mallory") {
+		t.Errorf("expected failed login without attribution, got %q", buf.String())
+	}
+}
@@ -0,0 +1,230 @@
+package proxy
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// loginSessionIdle is how long a user/IP/browser stays "logged in" for the audit log.
+// Basic Auth resends credentials with every request, so only the first success after
+// this much inactivity is recorded as a login.
+const loginSessionIdle = 30 * time.Minute
+
+// Repeated failure alerting: failureAlertThreshold failures from one IP within failureAlertWindow
+const (
+	failureAlertThreshold = 5
+	failureAlertWindow    = 15 * time.Minute
+)
+
+// auditLogMaxSize is the size at which the audit log is rotated to <path>.1 (one old file is kept)
+const auditLogMaxSize = 10 << 20
+
+// maxAuditField caps the username and user agent recorded and alerted, which come from the client
+const maxAuditField = 200
+
+// LoginAttempt is one line of the audit log (JSON Lines).
+type LoginAttempt struct {
+	Time      time.Time `json:"time"`
+	User      string    `json:"user"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+}
+
+// LoginAudit appends proxy login attempts to a file and reports suspicious ones to notify.
+// Every failure is recorded; a success is recorded once per loginSessionIdle per user, IP and user agent.
+// Notifications are sent for a login from an IP the user has not logged in from before (known IPs
+// are read back from the audit log, so they survive restarts) and for repeated failures from one IP.
+// The log is rotated to <path>.1 at auditLogMaxSize, so it takes at most twice that on disk.
+type LoginAudit struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	size     int64
+	maxSize  int64
+	notify   func(msg string) // nil = no notifications
+	sessions map[string]time.Time
+	knownIPs map[string]map[string]bool
+	failures map[string][]time.Time
+	now      func() time.Time
+}
+
+// OpenLoginAudit opens (or creates) the audit log at path and loads the IPs each user logged in from,
+// including those in the rotated <path>.1.
+func OpenLoginAudit(path string) (*LoginAudit, error) {
+	a := &LoginAudit{
+		path:     path,
+		maxSize:  auditLogMaxSize,
+		sessions: map[string]time.Time{},
+		knownIPs: map[string]map[string]bool{},
+		failures: map[string][]time.Time{},
+		now:      time.Now,
+	}
+	for _, name := range []string{path + ".1", path} {
+		existing, err := os.Open(name)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(existing)
+		for scanner.Scan() {
+			var attempt LoginAttempt
+			if json.Unmarshal(scanner.Bytes(), &attempt) == nil && attempt.Success {
+				a.rememberIP(attempt.User, attempt.IP)
+			}
+		}
+		existing.Close()
+	}
+
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// open opens the audit log for appending and notes its size (caller holds a.mu or owns a).
+func (a *LoginAudit) open() error {
+	file, err := os.OpenFile(a.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	a.file = file
+	a.size = info.Size()
+	return nil
+}
+
+// write appends line, first rotating the log to <path>.1 when line would take it past maxSize
+// (caller holds a.mu).
+func (a *LoginAudit) write(line []byte) error {
+	if a.size > 0 && a.size+int64(len(line)) > a.maxSize {
+		a.file.Close()
+		if err := os.Rename(a.path, a.path+".1"); err != nil {
+			log.Printf("WARN: proxy audit log rotation failed: %v", err)
+		}
+		if err := a.open(); err != nil {
+			return err
+		}
+	}
+	n, err := a.file.Write(line)
+	a.size += int64(n)
+	return err
+}
+
+// SetNotifier sets where login alerts are sent. notify runs on the request path, so it must not block.
+func (a *LoginAudit) SetNotifier(notify func(msg string)) {
+	a.notify = notify
+}
+
+// Close closes the audit log file.
+func (a *LoginAudit) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Close()
+}
+
+// Record logs the login attempt of user from r.
+func (a *LoginAudit) Record(r *http.Request, user string, success bool) {
+	attempt := LoginAttempt{
+		Time:      a.now().UTC(),
+		User:      truncateField(user),
+		IP:        getClientIP(r),
+		UserAgent: truncateField(r.UserAgent()),
+		Success:   success,
+	}
+
+	a.mu.Lock()
+	a.prune(attempt.Time)
+	var alert string
+	if success {
+		key := sessionKey(attempt.User, attempt.IP, attempt.UserAgent)
+		last, active := a.sessions[key]
+		a.sessions[key] = attempt.Time
+		if active && attempt.Time.Sub(last) < loginSessionIdle {
+			a.mu.Unlock()
+			return
+		}
+		// The first login of a user is not "new"; alerting starts once there is history
+		if len(a.knownIPs[attempt.User]) > 0 && !a.knownIPs[attempt.User][attempt.IP] {
+			alert = fmt.Sprintf("🔐 Proxy login by **%s** from new IP %s (%s)",
+				escapeMarkdown(attempt.User), attempt.IP, escapeMarkdown(attempt.UserAgent))
+		}
+		a.rememberIP(attempt.User, attempt.IP)
+	} else {
+		recent := a.failures[attempt.IP][:0]
+		for _, t := range a.failures[attempt.IP] {
+			if attempt.Time.Sub(t) < failureAlertWindow {
+				recent = append(recent, t)
+			}
+		}
+		recent = append(recent, attempt.Time)
+		a.failures[attempt.IP] = recent
+		if len(recent) == failureAlertThreshold {
+			alert = fmt.Sprintf("⚠️ %d failed proxy logins from %s in the last %v (last username tried: \"%s\")",
+				failureAlertThreshold, attempt.IP, failureAlertWindow, escapeMarkdown(attempt.User))
+		}
+	}
+
+	line, _ := json.Marshal(attempt)
+	if err := a.write(append(line, '\n')); err != nil {
+		log.Printf("WARN: proxy audit log write failed: %v", err)
+	}
+	a.mu.Unlock()
+
+	if alert != "" && a.notify != nil {
+		a.notify(alert)
+	}
+}
+
+// rememberIP adds ip to the IPs user has logged in from (caller holds a.mu or owns a).
+func (a *LoginAudit) rememberIP(user, ip string) {
+	if a.knownIPs[user] == nil {
+		a.knownIPs[user] = map[string]bool{}
+	}
+	a.knownIPs[user][ip] = true
+}
+
+// prune forgets idle sessions and old failures so the maps stay bounded (caller holds a.mu).
+func (a *LoginAudit) prune(now time.Time) {
+	for key, last := range a.sessions {
+		if now.Sub(last) >= loginSessionIdle {
+			delete(a.sessions, key)
+		}
+	}
+	for ip, times := range a.failures {
+		if len(times) == 0 || now.Sub(times[len(times)-1]) >= failureAlertWindow {
+			delete(a.failures, ip)
+		}
+	}
+}
+
+// truncateField caps a client-supplied value at maxAuditField runes.
+func truncateField(s string) string {
+	if utf8.RuneCountInString(s) <= maxAuditField {
+		return s
+	}
+	return string([]rune(s)[:maxAuditField-1]) + "…"
+}
+
+// markdownEscaper keeps client-supplied values from formatting alerts or breaking out of them:
+// Discord markdown is escaped and line breaks are flattened
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`, "#", `\#`,
+	"[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "<", `\<`, "@", "@\u200b", "\r", " ", "\n", " ",
+)
+
+// escapeMarkdown escapes s for a Discord alert.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,196 @@
+package proxy
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readAttempts returns the audit log entries at path
+func readAttempts(t *testing.T, path string) []LoginAttempt {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open audit log: %v", err)
+	}
+	defer f.Close()
+	var attempts []LoginAttempt
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var a LoginAttempt
+		if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+			t.Fatalf("invalid audit line %q: %v", scanner.Text(), err)
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts
+}
+
+func loginRequest(ip, agent string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+	r.RemoteAddr = ip
+	r.Header.Set("User-Agent", agent)
+	return r
+}
+
+func TestLoginAudit_RecordsLogins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxy_audit.log")
+	audit, err := OpenLoginAudit(path)
+	if err != nil {
+		t.Fatalf("OpenLoginAudit: %v", err)
+	}
+	defer audit.Close()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	audit.now = func() time.Time { return now }
+
+	audit.Record(loginRequest("203.0.113.7", "Firefox"), "alice", false)
+	audit.Record(loginRequest("203.0.113.7", "Firefox"), "alice", true)
+	now = now.Add(time.Minute)
+	audit.Record(loginRequest("203.0.113.7", "Firefox"), "alice", true) // same session
+	audit.Record(loginRequest("203.0.113.7", "curl"), "alice", true)    // other client
+	now = now.Add(loginSessionIdle)
+	audit.Record(loginRequest("203.0.113.7", "Firefox"), "alice", true) // after idle
+
+	attempts := readAttempts(t, path)
+	if len(attempts) != 4 {
+		t.Fatalf("expected 4 audit entries, got %+v", attempts)
+	}
+	first := attempts[0]
+	if first.Success || first.User != "alice" || first.IP != "203.0.113.7" || first.UserAgent != "Firefox" || !first.Time.Equal(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected failure entry %+v", first)
+	}
+	if !attempts[1].Success || attempts[2].UserAgent != "curl" {
+		t.Errorf("unexpected entries %+v", attempts[1:])
+	}
+
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 && runtime.GOOS != "windows" {
+		t.Errorf("audit log mode = %o, want 600", info.Mode().Perm())
+	}
+}
+
+func TestLoginAudit_Notifications(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxy_audit.log")
+	audit, _ := OpenLoginAudit(path)
+	var alerts []string
+	audit.SetNotifier(func(msg string) { alerts = append(alerts, msg) })
+
+	// First login ever is not alerted; a second IP is
+	audit.Record(loginRequest("198.51.100.1", "Firefox"), "alice", true)
+	audit.Record(loginRequest("198.51.100.2", "Firefox"), "alice", true)
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "**alice** from new IP 198.51.100.2") {
+		t.Fatalf("expected one new-IP alert, got %q", alerts)
+	}
+
+	// Repeated failures from one IP alert once at the threshold
+	for i := 0; i < failureAlertThreshold+2; i++ {
+		audit.Record(loginRequest("192.0.2.66", "curl"), "admin", false)
+	}
+	if len(alerts) != 2 || !strings.Contains(alerts[1], "5 failed proxy logins from 192.0.2.66") {
+		t.Fatalf("expected one failure alert, got %q", alerts)
+	}
+	audit.Close()
+
+	// Known IPs are read back from the log after a restart
+	reopened, _ := OpenLoginAudit(path)
+	defer reopened.Close()
+	alerts = nil
+	reopened.SetNotifier(func(msg string) { alerts = append(alerts, msg) })
+	reopened.Record(loginRequest("198.51.100.2", "Firefox"), "alice", true)
+	reopened.Record(loginRequest("198.51.100.3", "Firefox"), "alice", true)
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "198.51.100.3") {
+		t.Errorf("expected only the unseen IP alerted after restart, got %q", alerts)
+	}
+}
+
+// TestLoginAudit_AlertEscaping tests that client-supplied names and user agents cannot format or
+// stretch alerts
+func TestLoginAudit_AlertEscaping(t *testing.T) {
+	audit, _ := OpenLoginAudit(filepath.Join(t.TempDir(), "proxy_audit.log"))
+	defer audit.Close()
+	var alerts []string
+	audit.SetNotifier(func(msg string) { alerts = append(alerts, msg) })
+
+	audit.Record(loginRequest("198.51.100.1", "Firefox"), "alice", true)
+	audit.Record(loginRequest("198.51.100.2", "**@everyone**\n# big "+strings.Repeat("x", 500)), "alice", true)
+	for i := 0; i < failureAlertThreshold; i++ {
+		audit.Record(loginRequest("192.0.2.66", "curl"), "<@123> `code`", false)
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("expected two alerts, got %q", alerts)
+	}
+	if strings.Contains(alerts[0], "**@everyone") || strings.Contains(alerts[0], "@everyone") || strings.Contains(alerts[0], "\n") {
+		t.Errorf("user agent not escaped: %q", alerts[0])
+	}
+	if len([]rune(alerts[0])) > 2*maxAuditField+100 {
+		t.Errorf("user agent not truncated: %d runes", len([]rune(alerts[0])))
+	}
+	if strings.Contains(alerts[1], "<@123>") || strings.Contains(alerts[1], "`code`") {
+		t.Errorf("username not escaped: %q", alerts[1])
+	}
+}
+
+// TestLoginAudit_Rotation tests that the log moves to <path>.1 at its size limit and known IPs
+// are still read from there
+func TestLoginAudit_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxy_audit.log")
+	audit, _ := OpenLoginAudit(path)
+	audit.maxSize = 300 // two lines per file
+	audit.Record(loginRequest("198.51.100.1", "Firefox"), "alice", true)
+	for i := 0; i < 2; i++ {
+		audit.Record(loginRequest("192.0.2.66", "curl"), "admin", false)
+	}
+	audit.Close()
+
+	for _, name := range []string{path, path + ".1"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+		if info.Size() > 300 {
+			t.Errorf("%s is %d bytes, want at most 300", name, info.Size())
+		}
+	}
+
+	// alice's IP from the rotated file is still known: a new IP alerts
+	reopened, _ := OpenLoginAudit(path)
+	defer reopened.Close()
+	var alerts []string
+	reopened.SetNotifier(func(msg string) { alerts = append(alerts, msg) })
+	reopened.Record(loginRequest("198.51.100.9", "Firefox"), "alice", true)
+	if len(alerts) != 1 {
+		t.Errorf("expected a new-IP alert after rotation, got %q", alerts)
+	}
+}
+
+func TestBasicAuthFunc_Audit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxy_audit.log")
+	audit, _ := OpenLoginAudit(path)
+	defer audit.Close()
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := BasicAuthFunc(staticCredentials("admin", "password123"), audit, log.New(io.Discard, "", 0))(ok)
+
+	// No credentials (the browser's first request) and /health are not login attempts
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/admin/", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	for _, pass := range []string{"wrong-pass", "password123"} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+		req.SetBasicAuth("admin", pass)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	attempts := readAttempts(t, path)
+	if len(attempts) != 2 || attempts[0].Success || !attempts[1].Success {
+		t.Errorf("expected a failure then a success, got %+v", attempts)
+	}
+}
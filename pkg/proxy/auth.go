@@ -5,14 +5,38 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+
+	"github.com/bombom/absa-ac/pkg/metrics"
+	"github.com/bombom/absa-ac/pkg/requestid"
 )
 
-// BasicAuth middleware validates HTTP Basic Auth credentials.
+// BasicAuth middleware validates HTTP Basic Auth credentials against a single credential pair.
 // DL-002: Uses HTTP Basic Auth (RFC 7617) for browser-native authentication
 // DL-007: Constant-time password comparison prevents timing attacks
 func BasicAuth(username, password string, logger *log.Logger) func(http.Handler) http.Handler {
+	return BasicAuthFunc(staticCredentials(username, password), nil, logger)
+}
+
+// staticCredentials returns a credential check for a single username/password pair.
+func staticCredentials(username, password string) func(user, password string) bool {
+	return func(providedUser, providedPass string) bool {
+		// DL-007: Constant-time comparison prevents timing attacks
+		userMatch := subtle.ConstantTimeCompare([]byte(providedUser), []byte(username)) == 1
+		passMatch := subtle.ConstantTimeCompare([]byte(providedPass), []byte(password)) == 1
+		return userMatch && passMatch
+	}
+}
+
+// BasicAuthFunc middleware validates HTTP Basic Auth credentials with check (e.g. Accounts.Verify).
+// The authenticated username is recorded for the access log (see AccessLog), and every checked
+// login attempt in audit (nil = no audit log).
+// An IP with maxLoginFailures failures in loginFailureWindow gets 429 without a check.
+func BasicAuthFunc(check func(user, password string) bool, audit *LoginAudit, logger *log.Logger) func(http.Handler) http.Handler {
+	limiter := newLoginLimiter()
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// DL-008: Health endpoint bypasses auth (matches existing API pattern)
@@ -23,6 +47,7 @@ func BasicAuth(username, password string, logger *log.Logger) func(http.Handler)
 
 			auth := r.Header.Get("Authorization")
 			if auth == "" {
+				metrics.AuthFailures.Inc(metrics.ComponentProxy, metrics.AuthMissing)
 				// DL-002: 401 response includes WWW-Authenticate header for browser dialog
 				w.Header().Set("WWW-Authenticate", `Basic realm="Proxy"`)
 				writeProxyError(w, http.StatusUnauthorized, "Missing Authorization header")
@@ -33,6 +58,7 @@ func BasicAuth(username, password string, logger *log.Logger) func(http.Handler)
 			const prefix = "Basic "
 			if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
 				w.Header().Set("WWW-Authenticate", `Basic realm="Proxy"`)
+				metrics.AuthFailures.Inc(metrics.ComponentProxy, metrics.AuthMalformed)
 				writeProxyError(w, http.StatusUnauthorized, "Invalid Authorization header format")
 				return
 			}
@@ -41,6 +67,7 @@ func BasicAuth(username, password string, logger *log.Logger) func(http.Handler)
 			decoded, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
 			if err != nil {
 				w.Header().Set("WWW-Authenticate", `Basic realm="Proxy"`)
+				metrics.AuthFailures.Inc(metrics.ComponentProxy, metrics.AuthMalformed)
 				writeProxyError(w, http.StatusUnauthorized, "Invalid credentials encoding")
 				return
 			}
@@ -50,6 +77,7 @@ func BasicAuth(username, password string, logger *log.Logger) func(http.Handler)
 			colonIdx := strings.Index(credentials, ":")
 			if colonIdx < 0 {
 				w.Header().Set("WWW-Authenticate", `Basic realm="Proxy"`)
+				metrics.AuthFailures.Inc(metrics.ComponentProxy, metrics.AuthMalformed)
 				writeProxyError(w, http.StatusUnauthorized, "Invalid credentials format")
 				return
 			}
@@ -57,19 +85,33 @@ func BasicAuth(username, password string, logger *log.Logger) func(http.Handler)
 			providedUser := credentials[:colonIdx]
 			providedPass := credentials[colonIdx+1:]
 
-			// DL-007: Constant-time comparison prevents timing attacks
-			userMatch := subtle.ConstantTimeCompare([]byte(providedUser), []byte(username)) == 1
-			passMatch := subtle.ConstantTimeCompare([]byte(providedPass), []byte(password)) == 1
+			clientIP := getClientIP(r)
+			if wait := limiter.retryAfter(clientIP); wait > 0 {
+				metrics.AuthFailures.Inc(metrics.ComponentProxy, metrics.AuthLocked)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				writeProxyError(w, http.StatusTooManyRequests, "Too many failed logins, try again later")
+				return
+			}
 
-			if !userMatch || !passMatch {
+			ok := check(providedUser, providedPass)
+			if audit != nil {
+				audit.Record(r, providedUser, ok)
+			}
+			if !ok {
+				limiter.fail(clientIP)
+				metrics.AuthFailures.Inc(metrics.ComponentProxy, metrics.AuthInvalid)
 				// DL-007: Log auth failures with source IP for audit (R-002 mitigation)
-				clientIP := getClientIP(r)
-				logger.Printf("WARN: proxy auth failed from %s", clientIP)
+				logger.Printf("WARN: proxy auth failed for user %q from %s (request_id=%s)", providedUser, clientIP, requestid.From(r))
 				w.Header().Set("WWW-Authenticate", `Basic realm="Proxy"`)
 				writeProxyError(w, http.StatusUnauthorized, "Invalid credentials")
 				return
 			}
 
+			limiter.succeed(clientIP)
+			if proxySessions.touch(sessionKey(providedUser, clientIP, r.UserAgent())) {
+				sessionsStarted.Inc(metrics.ComponentProxy)
+			}
+			setRequestUser(r, providedUser)
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -77,23 +119,15 @@ func BasicAuth(username, password string, logger *log.Logger) func(http.Handler)
 
 // writeProxyError writes a JSON error response.
 // Uses json.Marshal to ensure proper escaping of special characters.
+// The request ID set by requestid.Middleware is included so users can quote it.
 func writeProxyError(w http.ResponseWriter, status int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
+	body := map[string]string{"error": message}
+	if id := w.Header().Get(requestid.Header); id != "" {
+		body["request_id"] = id
+	}
 	// Use json.Marshal for proper JSON escaping (quotes, backslashes, control chars)
-	data, _ := json.Marshal(map[string]string{"error": message})
+	data, _ := json.Marshal(body)
 	w.Write(data)
 }
-
-// getClientIP extracts client IP from request.
-func getClientIP(r *http.Request) string {
-	ip := r.RemoteAddr
-	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-		if parts := strings.Split(forwarded, ","); len(parts) > 0 {
-			ip = strings.TrimSpace(parts[0])
-		}
-	} else if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
-		ip = realIP
-	}
-	return ip
-}
@@ -0,0 +1,90 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// maxForwardedIPs bounds the X-Forwarded-For chain walked for the client IP
+const maxForwardedIPs = 10
+
+// clientIPKey is the context key of the client IP resolved by ClientIP
+type clientIPKey struct{}
+
+// ClientIP middleware resolves the client IP once per request for AccessLog, BasicAuth and the
+// login audit. It is the RemoteAddr host; X-Forwarded-For and X-Real-IP are only honoured when
+// RemoteAddr is one of trustedProxies (PROXY_TRUSTED_PROXY_IPS), since any client can send them.
+func ClientIP(trustedProxies []string) func(http.Handler) http.Handler {
+	trusted := make(map[string]bool, len(trustedProxies))
+	for _, ip := range trustedProxies {
+		trusted[normalizeIP(ip)] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolveClientIP(r, trusted)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip)))
+		})
+	}
+}
+
+// getClientIP returns the IP resolved by ClientIP, or the RemoteAddr host outside it
+func getClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteHost(r)
+}
+
+// resolveClientIP walks X-Forwarded-For from the right and returns the first hop that is not a
+// trusted proxy; X-Real-IP is used when there is no X-Forwarded-For
+// Anything malformed falls back to the RemoteAddr host
+func resolveClientIP(r *http.Request, trusted map[string]bool) string {
+	remote := remoteHost(r)
+	if !trusted[remote] {
+		return remote
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		parts := strings.Split(forwarded, ",")
+		if len(parts) > maxForwardedIPs {
+			return remote
+		}
+		for i := len(parts) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(parts[i]))
+			if ip == nil {
+				return remote
+			}
+			if hop := normalizeIP(ip.String()); !trusted[hop] {
+				return hop
+			}
+		}
+		return remote
+	}
+	if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); realIP != nil {
+		return normalizeIP(realIP.String())
+	}
+	return remote
+}
+
+// remoteHost is the RemoteAddr without its port (RemoteAddr as is when it has none)
+func remoteHost(r *http.Request) string {
+	host := r.RemoteAddr
+	if h, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		host = h
+	}
+	return normalizeIP(host)
+}
+
+// normalizeIP converts IPv4-mapped IPv6 addresses to IPv4 so both forms match
+func normalizeIP(s string) string {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return s
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.String()
+	}
+	return ip.String()
+}
@@ -0,0 +1,55 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"port stripped", "203.0.113.7:51234", nil, "203.0.113.7"},
+		{"IPv6 port stripped", "[2001:db8::1]:51234", nil, "2001:db8::1"},
+		{"untrusted X-Forwarded-For ignored", "203.0.113.7:51234", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "203.0.113.7"},
+		{"untrusted X-Real-IP ignored", "203.0.113.7:51234", map[string]string{"X-Real-IP": "198.51.100.1"}, "203.0.113.7"},
+		{"trusted proxy X-Forwarded-For", "10.0.0.2:443", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"rightmost untrusted hop", "10.0.0.2:443", map[string]string{"X-Forwarded-For": "192.0.2.66, 198.51.100.1, 10.0.0.3"}, "198.51.100.1"},
+		{"trusted proxy X-Real-IP", "10.0.0.2:443", map[string]string{"X-Real-IP": "198.51.100.1"}, "198.51.100.1"},
+		{"malformed hop falls back", "10.0.0.2:443", map[string]string{"X-Forwarded-For": "not-an-ip"}, "10.0.0.2"},
+		{"only trusted hops fall back", "10.0.0.2:443", map[string]string{"X-Forwarded-For": "10.0.0.3"}, "10.0.0.2"},
+		{"IPv4-mapped trusted proxy", "[::ffff:10.0.0.2]:443", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := ClientIP([]string{"10.0.0.2", "10.0.0.3"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = getClientIP(r)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Errorf("client IP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetClientIP_WithoutMiddleware tests the fallback to the RemoteAddr host
+func TestGetClientIP_WithoutMiddleware(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	if got := getClientIP(req); got != "203.0.113.7" {
+		t.Errorf("client IP = %q, want 203.0.113.7", got)
+	}
+}
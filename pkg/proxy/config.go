@@ -2,7 +2,9 @@ package proxy
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"strings"
 )
 
 // Config holds proxy server configuration loaded from environment variables.
@@ -11,10 +13,24 @@ import (
 // DL-006: Proxy forwards to configurable API URL
 type Config struct {
 	Port        string // Port to listen on (default: 8080)
-	APIURL      string // URL of the upstream API (default: http://localhost:3001)
+	APIURL      string // URL of the upstream API (default: http://localhost:3001, or unix:///path for a socket)
 	Username    string // Basic Auth username
 	Password    string // Basic Auth password
 	BearerToken string // Bearer token for API authentication
+
+	// AccountsFile holds named admin accounts with Argon2id hashes (PROXY_ACCOUNTS_FILE)
+	// When set, logins are checked against it instead of Username/Password
+	AccountsFile string
+
+	// AuditLogFile records every login attempt as JSON lines (PROXY_AUDIT_LOG, empty = off)
+	AuditLogFile string
+
+	// TrustedProxies may set X-Forwarded-For/X-Real-IP for the client IP (PROXY_TRUSTED_PROXY_IPS, comma-separated)
+	// Without them the client IP is the connection's address
+	TrustedProxies []string
+
+	// DebugBodies logs redacted request/response headers and bodies (PROXY_DEBUG_BODIES=true, debugging only)
+	DebugBodies bool
 }
 
 // LoadFromEnv reads configuration from environment variables.
@@ -36,26 +52,54 @@ func LoadFromEnv() Config {
 		bearerToken = os.Getenv("API_BEARER_TOKEN")
 	}
 
+	var trustedProxies []string
+	for _, ip := range strings.Split(os.Getenv("PROXY_TRUSTED_PROXY_IPS"), ",") {
+		if ip = strings.TrimSpace(ip); ip != "" {
+			trustedProxies = append(trustedProxies, ip)
+		}
+	}
+
 	return Config{
 		Port:        port,
 		APIURL:      apiURL,
 		Username:    os.Getenv("PROXY_USER"),
 		Password:    os.Getenv("PROXY_PASSWORD"),
 		BearerToken: bearerToken,
+
+		AccountsFile: os.Getenv("PROXY_ACCOUNTS_FILE"),
+		AuditLogFile: os.Getenv("PROXY_AUDIT_LOG"),
+		DebugBodies:  os.Getenv("PROXY_DEBUG_BODIES") == "true",
+
+		TrustedProxies: trustedProxies,
 	}
 }
 
 // Validate ensures configuration is valid before starting the proxy.
 // DL-015: Fail-fast on missing/invalid credentials with PROXY_ENABLED=true
 // DL-016: 8+ character minimum for password (OWASP minimum)
+// With PROXY_ACCOUNTS_FILE the single credential pair is optional; the file must be readable
 func (c Config) Validate() error {
-	if c.Username == "" {
+	if c.AccountsFile != "" {
+		accounts, err := LoadAccounts(c.AccountsFile)
+		if err != nil {
+			return fmt.Errorf("PROXY_ACCOUNTS_FILE: %w", err)
+		}
+		if len(accounts.Names()) == 0 {
+			return fmt.Errorf("PROXY_ACCOUNTS_FILE %s has no accounts (add one with: proxy-account add <name>)", c.AccountsFile)
+		}
+	} else if c.Username == "" {
 		return fmt.Errorf("PROXY_USER is required when PROXY_ENABLED=true")
 	}
-	if len(c.Password) < 8 {
+	if c.AccountsFile == "" && len(c.Password) < MinPasswordLength {
 		return fmt.Errorf("PROXY_PASSWORD must be at least 8 characters (got %d)", len(c.Password))
 	}
 
+	for _, ip := range c.TrustedProxies {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("PROXY_TRUSTED_PROXY_IPS: invalid IP address %q", ip)
+		}
+	}
+
 	if c.BearerToken == "" {
 		return fmt.Errorf("PROXY_BEARER_TOKEN (or API_BEARER_TOKEN) is required when PROXY_ENABLED=true")
 	}
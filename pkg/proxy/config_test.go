@@ -135,6 +135,17 @@ func TestConfigValidation(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "invalid trusted proxy",
+			config: Config{
+				Username:       "admin",
+				Password:       "password123",
+				BearerToken:    "token",
+				TrustedProxies: []string{"10.0.0.2", "proxy.local"},
+			},
+			expectError: true,
+			errorMsg:    "PROXY_TRUSTED_PROXY_IPS",
+		},
 		{
 			name: "missing username",
 			config: Config{
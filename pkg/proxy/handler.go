@@ -4,8 +4,12 @@ import (
 	"context"
 	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/bombom/absa-ac/pkg/metrics"
+	"github.com/bombom/absa-ac/pkg/requestid"
 )
 
 // hopByHopHeaders are headers that should not be forwarded to upstream.
@@ -24,7 +28,11 @@ var hopByHopHeaders = []string{
 // ProxyHandler creates a handler that forwards requests to the upstream API.
 // DL-003: Proxy injects Bearer token when forwarding to API
 // DL-013: Returns 502 on upstream failure, 504 on timeout
-func ProxyHandler(apiURL, bearerToken string, client *http.Client, logger *log.Logger) func(http.Handler) http.Handler {
+// Each forwarded request is logged to logger as a structured "proxy_request" JSON record; with logBodies
+// (PROXY_DEBUG_BODIES) a "proxy_request_debug" record adds request/response headers and bodies.
+// Authorization headers, cookies and token/password fields are always redacted.
+func ProxyHandler(apiURL, bearerToken string, client *http.Client, logger *log.Logger, logBodies bool) func(http.Handler) http.Handler {
+	records := slog.New(slog.NewJSONHandler(loggerWriter{logger}, nil))
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Skip proxying for health endpoint (handled directly)
@@ -40,9 +48,16 @@ func ProxyHandler(apiURL, bearerToken string, client *http.Client, logger *log.L
 				upstreamURL += "?" + r.URL.RawQuery
 			}
 
-			upstreamReq, err := http.NewRequestWithContext(r.Context(), r.Method, upstreamURL, r.Body)
+			// Debug mode copies the first maxLoggedBody bytes of each body as it streams through
+			var reqBody, respBody bodyCapture
+			body := r.Body
+			if logBodies && body != nil {
+				body = io.NopCloser(io.TeeReader(r.Body, &reqBody))
+			}
+
+			upstreamReq, err := http.NewRequestWithContext(r.Context(), r.Method, upstreamURL, body)
 			if err != nil {
-				logger.Printf("ERROR: failed to create upstream request: %v", err)
+				logger.Printf("ERROR: failed to create upstream request (request_id=%s): %v", requestid.From(r), err)
 				writeProxyError(w, http.StatusInternalServerError, "Failed to create upstream request")
 				return
 			}
@@ -74,25 +89,34 @@ func ProxyHandler(apiURL, bearerToken string, client *http.Client, logger *log.L
 
 			// DL-003: Inject Bearer token for API authentication
 			upstreamReq.Header.Set("Authorization", "Bearer "+bearerToken)
+			// The API logs and reports the same request ID as the proxy
+			upstreamReq.Header.Set(requestid.Header, requestid.From(r))
 
 			// Forward request to upstream
+			upstreamStart := time.Now()
 			resp, err := client.Do(upstreamReq)
 			if err != nil {
 				if ctxErr := r.Context().Err(); ctxErr == context.DeadlineExceeded {
+					upstreamDuration.Observe(time.Since(upstreamStart).Seconds(), metrics.ComponentProxy, upstreamTimeout)
 					// DL-013: Timeout returns 504 Gateway Timeout
-					logger.Printf("ERROR: upstream timeout: %v", err)
+					logger.Printf("ERROR: upstream timeout (request_id=%s): %v", requestid.From(r), err)
 					writeProxyError(w, http.StatusGatewayTimeout, "Upstream timeout")
 					return
 				}
 				// DL-013: Connection error returns 502 Bad Gateway
-				logger.Printf("ERROR: upstream connection failed: %v", err)
+				upstreamDuration.Observe(time.Since(upstreamStart).Seconds(), metrics.ComponentProxy, upstreamError)
+				logger.Printf("ERROR: upstream connection failed (request_id=%s): %v", requestid.From(r), err)
 				writeProxyError(w, http.StatusBadGateway, "Upstream connection failed")
 				return
 			}
 			defer resp.Body.Close()
+			upstreamDuration.Observe(time.Since(upstreamStart).Seconds(), metrics.ComponentProxy, upstreamOK)
 
-			// Copy response headers
+			// Copy response headers (the request ID is already set by requestid.Middleware)
 			for key, values := range resp.Header {
+				if key == http.CanonicalHeaderKey(requestid.Header) {
+					continue
+				}
 				for _, value := range values {
 					w.Header().Add(key, value)
 				}
@@ -100,11 +124,32 @@ func ProxyHandler(apiURL, bearerToken string, client *http.Client, logger *log.L
 
 			// Copy response status and body
 			w.WriteHeader(resp.StatusCode)
-			if _, copyErr := io.Copy(w, resp.Body); copyErr != nil {
-				logger.Printf("ERROR: response body copy failed: %v", copyErr)
+			var out io.Writer = w
+			if logBodies {
+				out = io.MultiWriter(w, &respBody)
+			}
+			if _, copyErr := io.Copy(out, resp.Body); copyErr != nil {
+				logger.Printf("ERROR: response body copy failed (request_id=%s): %v", requestid.From(r), copyErr)
 			}
 
-			logger.Printf("INFO: %s %s -> %d (%v)", r.Method, r.URL.Path, resp.StatusCode, time.Since(start))
+			attrs := []any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"query", redactQuery(r.URL.RawQuery),
+				"status", resp.StatusCode,
+				"latency_ms", time.Since(start).Milliseconds(),
+				"user", requestUser(r),
+				"request_id", requestid.From(r),
+			}
+			records.Info("proxy_request", attrs...)
+			if logBodies {
+				records.Info("proxy_request_debug", append(attrs,
+					"request_headers", redactHeaders(r.Header),
+					"request_body", redactBody(reqBody.buf, reqBody.truncated),
+					"response_headers", redactHeaders(resp.Header),
+					"response_body", redactBody(respBody.buf, respBody.truncated),
+				)...)
+			}
 		})
 	}
 }
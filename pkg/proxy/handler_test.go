@@ -0,0 +1,66 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bombom/absa-ac/pkg/requestid"
+)
+
+// TestProxyHandler_RequestID tests that the proxy forwards its request ID upstream, logs it and
+// returns it once in the response and in error bodies
+func TestProxyHandler_RequestID(t *testing.T) {
+	var upstreamID string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamID = r.Header.Get(requestid.Header)
+		w.Header().Set(requestid.Header, upstreamID) // the API echoes it like requestid.Middleware
+		w.Write([]byte(`{}`))
+	}))
+	defer upstream.Close()
+
+	var logs bytes.Buffer
+	newHandler := func(apiURL string) http.Handler {
+		handler := ProxyHandler(apiURL, "api-token", upstream.Client(), log.New(io.Discard, "", 0), false)(http.NotFoundHandler())
+		return requestid.Middleware(AccessLog(handler, log.New(&logs, "", 0)))
+	}
+
+	// Generated when the client sends none
+	rec := httptest.NewRecorder()
+	newHandler(upstream.URL).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/config", nil))
+	id := rec.Header().Get(requestid.Header)
+	if id == "" || upstreamID != id {
+		t.Fatalf("Expected the upstream to receive the response ID %q, got %q", id, upstreamID)
+	}
+	if got := rec.Header().Values(requestid.Header); len(got) != 1 {
+		t.Errorf("Expected one %s header, got %v", requestid.Header, got)
+	}
+	if !strings.Contains(logs.String(), "request_id="+id) {
+		t.Errorf("Expected the access log to name the request ID, got %q", logs.String())
+	}
+
+	// A client ID is kept over both hops
+	req := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+	req.Header.Set(requestid.Header, "client-trace-42")
+	rec = httptest.NewRecorder()
+	newHandler(upstream.URL).ServeHTTP(rec, req)
+	if upstreamID != "client-trace-42" || rec.Header().Get(requestid.Header) != "client-trace-42" {
+		t.Errorf("Expected client-trace-42 upstream and in the response, got %q and %q", upstreamID, rec.Header().Get(requestid.Header))
+	}
+
+	// Error bodies carry it
+	rec = httptest.NewRecorder()
+	newHandler("http://127.0.0.1:1").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/config", nil))
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if rec.Code != http.StatusBadGateway || body["request_id"] == "" || body["request_id"] != rec.Header().Get(requestid.Header) {
+		t.Errorf("Expected a 502 naming the request ID, got %d %v", rec.Code, body)
+	}
+}
@@ -1,13 +1,47 @@
 package proxy
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"time"
+
+	"github.com/bombom/absa-ac/pkg/metrics"
+	"github.com/bombom/absa-ac/pkg/requestid"
 )
 
+// requestUserKey is the context key of the *string filled in by BasicAuthFunc
+type requestUserKey struct{}
+
+// setRequestUser records the authenticated username for AccessLog (no-op outside AccessLog)
+func setRequestUser(r *http.Request, user string) {
+	if holder, ok := r.Context().Value(requestUserKey{}).(*string); ok {
+		*holder = user
+	}
+}
+
+// requestUser returns the authenticated username ("" before BasicAuthFunc or outside AccessLog)
+func requestUser(r *http.Request) string {
+	if holder, ok := r.Context().Value(requestUserKey{}).(*string); ok {
+		return *holder
+	}
+	return ""
+}
+
+// loggerWriter writes each structured record as one line of logger, keeping its prefix and flags
+type loggerWriter struct {
+	logger *log.Logger
+}
+
+func (w loggerWriter) Write(p []byte) (int, error) {
+	w.logger.Print(string(p))
+	return len(p), nil
+}
+
 // AccessLog middleware logs all requests at INFO level.
-// DL-007: Extracts source IP from X-Forwarded-For (first hop) or X-Real-IP header
+// DL-007: Logs the source IP resolved by ClientIP (forwarding headers only from trusted proxies)
+// Authenticated requests name the account ("as alice") so changes can be attributed
+// Each line ends with the request ID, which the API logs for the forwarded request too
 func AccessLog(next http.Handler, logger *log.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -15,17 +49,26 @@ func AccessLog(next http.Handler, logger *log.Logger) http.Handler {
 		// Wrap response writer to capture status code
 		wrapped := &loggingResponseWriter{ResponseWriter: w, status: http.StatusOK}
 
+		// BasicAuthFunc runs further in and fills in the username
+		var user string
+		r = r.WithContext(context.WithValue(r.Context(), requestUserKey{}, &user))
+
 		next.ServeHTTP(wrapped, r)
 
 		clientIP := getClientIP(r)
+		if user != "" {
+			clientIP += " as " + user
+		}
 
 		duration := time.Since(start)
-		logger.Printf("INFO: %s %s from %s - %d (%v)",
+		metrics.ObserveRequest(metrics.ComponentProxy, r.Method, wrapped.status, duration)
+		logger.Printf("INFO: %s %s from %s - %d (%v) request_id=%s",
 			r.Method,
 			r.URL.Path,
 			clientIP,
 			wrapped.status,
 			duration,
+			requestid.From(r),
 		)
 	})
 }
@@ -0,0 +1,76 @@
+package proxy
+
+import (
+	"sync"
+	"time"
+)
+
+// Failed login limiting: after maxLoginFailures failures within loginFailureWindow an IP is refused
+// without checking its credentials until the oldest failure leaves the window. Checking an account
+// password costs an Argon2id hash, so unlimited guesses would also be a cheap way to load the host.
+const (
+	maxLoginFailures   = 10
+	loginFailureWindow = 5 * time.Minute
+)
+
+// loginLimiter tracks recent failed logins per client IP.
+type loginLimiter struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+	now      func() time.Time
+}
+
+func newLoginLimiter() *loginLimiter {
+	return &loginLimiter{failures: map[string][]time.Time{}, now: time.Now}
+}
+
+// retryAfter returns how long ip must wait before its next attempt, 0 if it may try now.
+func (l *loginLimiter) retryAfter(ip string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	recent := l.recent(ip, now)
+	if len(recent) < maxLoginFailures {
+		return 0
+	}
+	return recent[0].Add(loginFailureWindow).Sub(now)
+}
+
+// fail records a failed login from ip.
+func (l *loginLimiter) fail(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.prune(now)
+	l.failures[ip] = append(l.recent(ip, now), now)
+}
+
+// succeed forgets the failures of ip.
+func (l *loginLimiter) succeed(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, ip)
+}
+
+// recent drops the failures of ip that left the window and returns the rest (caller holds l.mu).
+func (l *loginLimiter) recent(ip string, now time.Time) []time.Time {
+	times := l.failures[ip]
+	for len(times) > 0 && now.Sub(times[0]) >= loginFailureWindow {
+		times = times[1:]
+	}
+	if len(times) == 0 {
+		delete(l.failures, ip)
+		return nil
+	}
+	l.failures[ip] = times
+	return times
+}
+
+// prune forgets IPs without a failure in the window so the map stays bounded (caller holds l.mu).
+func (l *loginLimiter) prune(now time.Time) {
+	for ip, times := range l.failures {
+		if len(times) == 0 || now.Sub(times[len(times)-1]) >= loginFailureWindow {
+			delete(l.failures, ip)
+		}
+	}
+}
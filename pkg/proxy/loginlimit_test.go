@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	l := newLoginLimiter()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < maxLoginFailures; i++ {
+		if wait := l.retryAfter("192.0.2.66"); wait != 0 {
+			t.Fatalf("Expected attempt %d allowed, got wait %v", i+1, wait)
+		}
+		l.fail("192.0.2.66")
+		now = now.Add(time.Second)
+	}
+	if wait := l.retryAfter("192.0.2.66"); wait != loginFailureWindow-maxLoginFailures*time.Second {
+		t.Errorf("Expected a wait until the first failure leaves the window, got %v", wait)
+	}
+	if wait := l.retryAfter("198.51.100.1"); wait != 0 {
+		t.Errorf("Expected other IPs unaffected, got %v", wait)
+	}
+
+	now = now.Add(loginFailureWindow)
+	if wait := l.retryAfter("192.0.2.66"); wait != 0 || len(l.failures) != 0 {
+		t.Errorf("Expected failures forgotten after the window, got %v and %d IPs", wait, len(l.failures))
+	}
+
+	l.fail("192.0.2.66")
+	l.succeed("192.0.2.66")
+	if len(l.failures) != 0 {
+		t.Error("Expected a successful login to clear the failures")
+	}
+}
+
+// TestBasicAuthFunc_LocksOutRepeatedFailures tests that an IP over the failure limit gets 429
+// without its credentials being checked, even correct ones
+func TestBasicAuthFunc_LocksOutRepeatedFailures(t *testing.T) {
+	checks := 0
+	check := func(user, password string) bool {
+		checks++
+		return password == "password123"
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := ClientIP(nil)(BasicAuthFunc(check, nil, log.New(io.Discard, "", 0))(ok))
+
+	login := func(ip, pass string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+		req.RemoteAddr = ip + ":40000"
+		req.SetBasicAuth("admin", pass)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < maxLoginFailures; i++ {
+		if rec := login("192.0.2.66", "guess"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected 401 for failure %d, got %d", i+1, rec.Code)
+		}
+	}
+	rec := login("192.0.2.66", "password123")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 429 with Retry-After, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if checks != maxLoginFailures {
+		t.Errorf("Expected %d credential checks, got %d", maxLoginFailures, checks)
+	}
+	if rec := login("198.51.100.1", "password123"); rec.Code != http.StatusOK {
+		t.Errorf("Expected other IPs to log in, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/metrics"
+)
+
+// Proxy metrics besides the shared request and auth failure counters (see pkg/metrics).
+var (
+	upstreamDuration = metrics.NewHistogram("absa_proxy_upstream_duration_seconds",
+		"Time until the upstream API answered a forwarded request, by component and outcome (ok, error, timeout)",
+		metrics.DefaultBuckets, "component", "outcome")
+	sessionsStarted = metrics.NewCounter("absa_sessions_started_total",
+		"Login sessions started, by component", "component")
+	sessionsActive = metrics.NewGauge("absa_sessions_active",
+		"Login sessions with a request in the last 30 minutes, by component", "component")
+)
+
+// Upstream outcomes of forwarded requests.
+const (
+	upstreamOK      = "ok"
+	upstreamError   = "error"
+	upstreamTimeout = "timeout"
+)
+
+// proxySessions counts the login sessions of every BasicAuthFunc in the process.
+var proxySessions = newLoginSessions()
+
+func init() {
+	sessionsActive.SetFunc(func() float64 { return float64(proxySessions.active()) }, metrics.ComponentProxy)
+}
+
+// loginSessions tracks login sessions for metrics: a session is one user, IP and user agent, and ends after
+// loginSessionIdle without a successful request (Basic Auth has no logout).
+type loginSessions struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+	now  func() time.Time
+}
+
+func newLoginSessions() *loginSessions {
+	return &loginSessions{last: map[string]time.Time{}, now: time.Now}
+}
+
+// touch records a successful request of the session key and reports whether it started a new session.
+func (s *loginSessions) touch(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	last, ok := s.last[key]
+	s.last[key] = now
+	return !ok || now.Sub(last) >= loginSessionIdle
+}
+
+// active returns the number of sessions that are not idle, forgetting the idle ones.
+func (s *loginSessions) active() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for key, last := range s.last {
+		if now.Sub(last) >= loginSessionIdle {
+			delete(s.last, key)
+		}
+	}
+	return len(s.last)
+}
+
+// sessionKey identifies a login session of user from ip with userAgent.
+func sessionKey(user, ip, userAgent string) string {
+	return user + "\x00" + ip + "\x00" + userAgent
+}
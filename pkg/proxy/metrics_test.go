@@ -0,0 +1,72 @@
+package proxy
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/metrics"
+)
+
+func TestLoginSessions(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := newLoginSessions()
+	s.now = func() time.Time { return now }
+
+	if !s.touch("alice") || s.touch("alice") || !s.touch("bob") {
+		t.Fatal("expected a new session per key and none for a repeated request")
+	}
+	if got := s.active(); got != 2 {
+		t.Errorf("expected 2 active sessions, got %d", got)
+	}
+
+	now = now.Add(loginSessionIdle)
+	if got := s.active(); got != 0 {
+		t.Errorf("expected idle sessions to end, got %d", got)
+	}
+	if !s.touch("alice") {
+		t.Error("expected a new session after the idle timeout")
+	}
+}
+
+func TestMetrics_AuthAndUpstream(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer api.Close()
+	logger := log.New(io.Discard, "", 0)
+	handler := AccessLog(BasicAuth("admin", "password123", logger)(
+		ProxyHandler(api.URL, "token", api.Client(), logger, false)(http.NotFoundHandler())), logger)
+
+	invalid := metrics.AuthFailures.Value(metrics.ComponentProxy, metrics.AuthInvalid)
+	started := sessionsStarted.Value(metrics.ComponentProxy)
+	requests := metrics.HTTPRequests.Value(metrics.ComponentProxy, "GET", "200")
+
+	req := httptest.NewRequest("GET", "/api/config", nil)
+	req.SetBasicAuth("admin", "wrong")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	for range 2 {
+		req = httptest.NewRequest("GET", "/api/config", nil)
+		req.Header.Set("User-Agent", "metrics-test")
+		req.SetBasicAuth("admin", "password123")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if metrics.AuthFailures.Value(metrics.ComponentProxy, metrics.AuthInvalid) != invalid+1 {
+		t.Error("expected one invalid login counted")
+	}
+	if sessionsStarted.Value(metrics.ComponentProxy) != started+1 || sessionsActive.Value(metrics.ComponentProxy) < 1 {
+		t.Error("expected one session started for two requests")
+	}
+	if metrics.HTTPRequests.Value(metrics.ComponentProxy, "GET", "200") != requests+2 {
+		t.Error("expected two proxied requests counted")
+	}
+
+	var out strings.Builder
+	metrics.Default.Write(&out)
+	if !strings.Contains(out.String(), `absa_proxy_upstream_duration_seconds_count{component="proxy",outcome="ok"}`) {
+		t.Errorf("expected upstream latency in the exposition, got:\n%s", out.String())
+	}
+}
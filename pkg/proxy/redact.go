@@ -0,0 +1,127 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces secret values in proxy logs.
+const redacted = "[REDACTED]"
+
+// maxLoggedBody caps how much of a request or response body is logged in debug mode.
+const maxLoggedBody = 4096
+
+// sensitiveHeaders never appear in logs with their values.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// sensitiveKeyParts mark query parameters and JSON fields whose values are secrets.
+var sensitiveKeyParts = []string{"token", "password", "secret", "authorization", "bearer", "api_key", "apikey"}
+
+// secretAssignment matches key=value or "key": "value" pairs with a sensitive key in non-JSON bodies.
+var secretAssignment = regexp.MustCompile(`(?i)((?:token|password|secret|bearer|api[_-]?key)["']?\s*[=:]\s*["']?)[^"'&\s,}]+`)
+
+// isSensitiveKey reports whether a field or parameter name holds a secret.
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeaders flattens h for logging with sensitive header values replaced.
+func redactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for key, values := range h {
+		if sensitiveHeaders[http.CanonicalHeaderKey(key)] || isSensitiveKey(key) {
+			out[key] = redacted
+			continue
+		}
+		out[key] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// redactQuery returns rawQuery with the values of sensitive parameters replaced.
+func redactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redacted // unparseable; do not risk logging it
+	}
+	for key := range values {
+		if isSensitiveKey(key) {
+			values[key] = []string{redacted}
+		}
+	}
+	return values.Encode()
+}
+
+// redactBody returns body for logging: sensitive JSON fields are replaced at any depth,
+// non-JSON bodies get key=value secrets replaced. Bodies over maxLoggedBody are truncated
+// (and then treated as non-JSON).
+func redactBody(body []byte, truncated bool) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if !truncated {
+		var v any
+		if json.Unmarshal(body, &v) == nil {
+			out, _ := json.Marshal(redactJSON(v))
+			return string(out)
+		}
+	}
+	s := secretAssignment.ReplaceAllString(string(body), "${1}"+redacted)
+	if truncated {
+		s += "...(truncated)"
+	}
+	return s
+}
+
+// redactJSON replaces the values of sensitive keys in a decoded JSON value.
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, inner := range v {
+			if isSensitiveKey(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(inner)
+			}
+		}
+	case []any:
+		for i, inner := range v {
+			v[i] = redactJSON(inner)
+		}
+	}
+	return v
+}
+
+// bodyCapture keeps the first maxLoggedBody bytes written to it (for debug body logging).
+type bodyCapture struct {
+	buf       []byte
+	truncated bool
+}
+
+// Write never fails and reports the full length so it can sit in a TeeReader or MultiWriter.
+func (c *bodyCapture) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := maxLoggedBody - len(c.buf); n > room {
+		c.truncated = true
+		p = p[:max(room, 0)]
+	}
+	c.buf = append(c.buf, p...)
+	return n, nil
+}
@@ -4,9 +4,16 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/bombom/absa-ac/pkg/drain"
+	"github.com/bombom/absa-ac/pkg/listen"
+	"github.com/bombom/absa-ac/pkg/requestid"
+	"github.com/bombom/absa-ac/pkg/supervisor"
 )
 
 // Server manages the reverse proxy HTTP server.
@@ -18,6 +25,18 @@ type Server struct {
 	logger     *log.Logger
 	httpClient *http.Client // DL-011: Reused for upstream requests
 
+	// loginNotify receives login alerts from the audit log (nil = no alerts)
+	loginNotify func(msg string)
+
+	// listeners replace the default TCP listener on the port (nil = listen on ":"+port)
+	listeners []net.Listener
+
+	// drainTimeout bounds how long shutdown waits for in-flight requests (0 = drain.DefaultTimeout)
+	drainTimeout time.Duration
+
+	// inFlight counts active requests so shutdown can report how many it drained
+	inFlight drain.Tracker
+
 	// wg tracks graceful shutdown completion
 	wg sync.WaitGroup
 
@@ -37,6 +56,15 @@ func NewServer(cfg Config, logger *log.Logger) *Server {
 		DisableCompression: false,
 	}
 
+	// A unix:// API URL reaches the API over its Unix socket (API_LISTEN=unix://...); requests use a placeholder host
+	if path, ok := strings.CutPrefix(cfg.APIURL, "unix://"); ok {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		}
+		cfg.APIURL = "http://localhost"
+	}
+
 	httpClient := &http.Client{
 		Timeout:   30 * time.Second, // DL-011: 30s reasonable for internal API calls
 		Transport: transport,
@@ -55,8 +83,28 @@ func NewServer(cfg Config, logger *log.Logger) *Server {
 	}
 }
 
+// SetDrainTimeout sets how long shutdown waits for in-flight requests before closing them.
+// Must be called before Start.
+func (s *Server) SetDrainTimeout(d time.Duration) {
+	s.drainTimeout = d
+}
+
+// SetListeners serves on already opened listeners (TCP, Unix sockets, systemd sockets) instead of the port.
+// The server closes them on shutdown. Must be called before Start.
+func (s *Server) SetListeners(listeners []net.Listener) {
+	s.listeners = listeners
+}
+
+// SetLoginNotifier sets where alerts about new-IP logins and repeated failures are sent.
+// Only used with PROXY_AUDIT_LOG; notify must not block. Must be called before Start.
+func (s *Server) SetLoginNotifier(notify func(msg string)) {
+	s.loginNotify = notify
+}
+
 // Start begins the HTTP server in a background goroutine.
 // Blocks until Stop() is called, then performs graceful shutdown.
+// Errors that prevent starting (port in use, unreadable accounts or audit file) are marked supervisor.Fatal,
+// so the server is not restarted in a loop.
 func (s *Server) Start(ctx context.Context) error {
 	serverCtx, serverCancel := context.WithCancel(ctx)
 
@@ -69,32 +117,71 @@ func (s *Server) Start(ctx context.Context) error {
 	// DL-008: Health endpoint bypasses auth (matches existing API pattern)
 	mux.HandleFunc("GET /health", s.healthHandler)
 
-	// Apply middleware chain (inside-out): mux -> ProxyHandler -> BasicAuth -> AccessLog
-	// Request flow: AccessLog -> BasicAuth -> ProxyHandler -> mux
-	handler := ProxyHandler(s.config.APIURL, s.config.BearerToken, s.httpClient, s.logger)(mux)
-	handler = BasicAuth(s.config.Username, s.config.Password, s.logger)(handler)
+	// Apply middleware chain (inside-out): mux -> ProxyHandler -> upstream guard -> BasicAuth -> AccessLog -> client IP -> request ID -> drain tracker
+	// Request flow: drain tracker -> request ID -> client IP -> AccessLog -> BasicAuth -> upstream guard -> ProxyHandler -> mux
+	upstream := newUpstreamHealth(s.config.APIURL, s.httpClient, s.logger)
+	handler := ProxyHandler(s.config.APIURL, s.config.BearerToken, s.httpClient, s.logger, s.config.DebugBodies)(mux)
+	handler = upstream.guard(handler)
+	var audit *LoginAudit
+	if s.config.AuditLogFile != "" {
+		var err error
+		if audit, err = OpenLoginAudit(s.config.AuditLogFile); err != nil {
+			serverCancel()
+			return supervisor.Fatal(fmt.Errorf("proxy audit log: %w", err))
+		}
+		defer audit.Close()
+		audit.SetNotifier(s.loginNotify)
+		s.logger.Printf("Proxy login attempts audited to %s", s.config.AuditLogFile)
+	}
+	auth, err := s.authMiddleware(audit)
+	if err != nil {
+		serverCancel()
+		return supervisor.Fatal(err)
+	}
+	handler = auth(handler)
 	handler = AccessLog(handler, s.logger)
+	handler = ClientIP(s.config.TrustedProxies)(handler)
+	handler = requestid.Middleware(handler)
+	handler = s.inFlight.Wrap(handler)
 
 	s.httpServer.Handler = handler
 
+	// Upstream health checks stop with the server
 	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done()
-		s.logger.Printf("Proxy server listening on %s", s.httpServer.Addr)
+		upstream.run(serverCtx, upstreamCheckInterval)
+	}()
 
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			s.logger.Printf("Proxy server error: %v", err)
+	// Bind before serving so a taken port fails Start instead of only being logged
+	listeners := s.listeners
+	if len(listeners) == 0 {
+		l, err := net.Listen("tcp", s.httpServer.Addr)
+		if err != nil {
+			serverCancel()
+			s.wg.Wait() // upstream health checks
+			return supervisor.Fatal(fmt.Errorf("proxy server: %w", err))
 		}
-	}()
+		listeners = []net.Listener{l}
+	}
+	for _, l := range listeners {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.logger.Printf("Proxy server listening on %s", listen.Name(l))
+
+			if err := s.httpServer.Serve(l); err != nil && err != http.ErrServerClosed {
+				s.logger.Printf("Proxy server error on %s: %v", listen.Name(l), err)
+			}
+		}()
+	}
 
 	<-serverCtx.Done()
 	s.logger.Println("Shutting down proxy server...")
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
-		return fmt.Errorf("proxy server shutdown failed: %w", err)
+	if err := drain.Shutdown(s.httpServer, &s.inFlight, s.drainTimeout, s.logger, "Proxy server"); err != nil {
+		s.wg.Wait()
+		return err
 	}
 
 	s.wg.Wait()
@@ -103,6 +190,20 @@ func (s *Server) Start(ctx context.Context) error {
 	return nil
 }
 
+// authMiddleware checks logins against the accounts file if configured, else the PROXY_USER/PROXY_PASSWORD pair.
+// Login attempts are recorded in audit (nil = no audit log).
+func (s *Server) authMiddleware(audit *LoginAudit) (func(http.Handler) http.Handler, error) {
+	if s.config.AccountsFile == "" {
+		return BasicAuthFunc(staticCredentials(s.config.Username, s.config.Password), audit, s.logger), nil
+	}
+	accounts, err := LoadAccounts(s.config.AccountsFile)
+	if err != nil {
+		return nil, fmt.Errorf("proxy accounts: %w", err)
+	}
+	s.logger.Printf("Proxy using %d admin account(s) from %s", len(accounts.Names()), s.config.AccountsFile)
+	return BasicAuthFunc(accounts.Verify, audit, s.logger), nil
+}
+
 // Stop gracefully shuts down the HTTP server.
 func (s *Server) Stop() error {
 	s.cancelMu.Lock()
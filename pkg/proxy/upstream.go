@@ -0,0 +1,148 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/requestid"
+)
+
+// Upstream health checking: the API's /health is polled every upstreamCheckInterval and the
+// upstream is marked unhealthy after upstreamFailThreshold failures in a row (one success recovers).
+const (
+	upstreamCheckInterval = 5 * time.Second
+	upstreamCheckTimeout  = 2 * time.Second
+	upstreamFailThreshold = 2
+)
+
+// ReasonUpstreamUnavailable is the machine-readable reason in 503 responses while the API is down.
+const ReasonUpstreamUnavailable = "upstream_unavailable"
+
+// upstreamHealth tracks whether the upstream API answers its health check.
+// It starts healthy so requests are forwarded until a check says otherwise.
+type upstreamHealth struct {
+	apiURL string
+	client *http.Client
+	logger *log.Logger
+
+	mu       sync.Mutex
+	healthy  bool
+	failures int
+	detail   string    // last failure while unhealthy
+	since    time.Time // when the upstream became unhealthy
+}
+
+func newUpstreamHealth(apiURL string, client *http.Client, logger *log.Logger) *upstreamHealth {
+	return &upstreamHealth{apiURL: apiURL, client: client, logger: logger, healthy: true}
+}
+
+// run checks the upstream right away and then every interval until ctx is done.
+func (h *upstreamHealth) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		h.report(h.check(ctx))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check calls the upstream /health once.
+func (h *upstreamHealth) check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, upstreamCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.apiURL+"/health", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("health check timed out after %v", upstreamCheckTimeout)
+		}
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// report records a check result (nil = healthy) and logs state changes.
+func (h *upstreamHealth) report(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		if !h.healthy {
+			h.logger.Printf("INFO: upstream API healthy again after %v", time.Since(h.since).Round(time.Second))
+		}
+		h.healthy, h.failures, h.detail = true, 0, ""
+		return
+	}
+	h.failures++
+	if h.healthy && h.failures >= upstreamFailThreshold {
+		h.healthy, h.since = false, time.Now()
+		h.logger.Printf("WARN: upstream API unhealthy, answering 503 until it recovers: %v", err)
+	}
+	if !h.healthy {
+		h.detail = err.Error()
+	}
+}
+
+// status returns whether the upstream is healthy and, if not, why and since when.
+func (h *upstreamHealth) status() (bool, string, time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.healthy, h.detail, h.since
+}
+
+// guard answers 503 right away while the upstream is unhealthy instead of letting requests time out.
+// Browsers navigating to a page get a small self-refreshing HTML page; API calls get JSON with
+// "reason": ReasonUpstreamUnavailable, which the admin UI shows as a banner.
+func (h *upstreamHealth) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		healthy, detail, since := h.status()
+		if healthy || r.URL.Path == "/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		retryAfter := int(upstreamCheckInterval / time.Second)
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		if strings.Contains(r.Header.Get("Accept"), "text/html") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, `<!DOCTYPE html><html><head><meta http-equiv="refresh" content="%d"><title>Bot API unavailable</title></head>`+
+				`<body><h1>Bot API unavailable</h1><p>The proxy cannot reach the bot API (%s) since %s. This page retries every %d seconds.</p></body></html>`,
+				retryAfter, html.EscapeString(detail), since.UTC().Format(time.RFC3339), retryAfter)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		body := map[string]any{
+			"error":               "Bot API unavailable",
+			"reason":              ReasonUpstreamUnavailable,
+			"details":             detail,
+			"since":               since.UTC().Format(time.RFC3339),
+			"retry_after_seconds": retryAfter,
+		}
+		if id := w.Header().Get(requestid.Header); id != "" {
+			body["request_id"] = id
+		}
+		data, _ := json.Marshal(body)
+		w.Write(data)
+	})
+}
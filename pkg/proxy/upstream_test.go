@@ -0,0 +1,118 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUpstreamHealth_Report(t *testing.T) {
+	h := newUpstreamHealth("http://unused", http.DefaultClient, log.New(io.Discard, "", 0))
+
+	h.report(errors.New("connection refused"))
+	if healthy, _, _ := h.status(); !healthy {
+		t.Fatal("expected one failure to be tolerated")
+	}
+	h.report(errors.New("connection refused"))
+	healthy, detail, since := h.status()
+	if healthy || detail != "connection refused" || since.IsZero() {
+		t.Fatalf("expected unhealthy after %d failures, got %v %q", upstreamFailThreshold, healthy, detail)
+	}
+	h.report(nil)
+	if healthy, detail, _ := h.status(); !healthy || detail != "" {
+		t.Error("expected one success to recover")
+	}
+}
+
+func TestUpstreamHealth_Check(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			t.Errorf("unexpected check path %s", r.URL.Path)
+		}
+		w.WriteHeader(int(status.Load()))
+	}))
+	h := newUpstreamHealth(api.URL, api.Client(), log.New(io.Discard, "", 0))
+
+	if err := h.check(context.Background()); err != nil {
+		t.Errorf("expected healthy upstream, got %v", err)
+	}
+	status.Store(http.StatusInternalServerError)
+	if err := h.check(context.Background()); err == nil || !strings.Contains(err.Error(), "HTTP 500") {
+		t.Errorf("expected HTTP 500 error, got %v", err)
+	}
+	api.Close()
+	if err := h.check(context.Background()); err == nil {
+		t.Error("expected error for unreachable upstream")
+	}
+}
+
+func TestUpstreamHealth_Guard(t *testing.T) {
+	h := newUpstreamHealth("http://unused", http.DefaultClient, log.New(io.Discard, "", 0))
+	var forwarded int
+	handler := h.guard(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { forwarded++ }))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/config", nil))
+	if forwarded != 1 {
+		t.Fatal("expected requests forwarded while healthy")
+	}
+
+	h.report(errors.New("dial tcp: connection refused"))
+	h.report(errors.New("dial tcp: connection refused"))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/config", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "5" || forwarded != 1 {
+		t.Fatalf("expected fast 503, got %d (forwarded %d)", rec.Code, forwarded)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if body["reason"] != ReasonUpstreamUnavailable || body["details"] != "dial tcp: connection refused" {
+		t.Errorf("unexpected body %v", body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), `http-equiv="refresh"`) || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("expected self-refreshing HTML page, got %q", rec.Body.String())
+	}
+
+	// The proxy's own health endpoint is unaffected
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	if forwarded != 2 {
+		t.Error("expected /health to bypass the guard")
+	}
+}
+
+func TestUpstreamHealth_RunStops(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer api.Close()
+	h := newUpstreamHealth(api.URL, api.Client(), log.New(io.Discard, "", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("run did not stop on context cancellation")
+	}
+}
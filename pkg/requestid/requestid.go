@@ -0,0 +1,71 @@
+// Package requestid gives every HTTP request an ID for correlating logs across the proxy and the API.
+// An incoming X-Request-ID is kept when it is well-formed, so one ID follows a request over every hop.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// Header carries the request ID on requests and responses
+const Header = "X-Request-ID"
+
+// maxLength bounds an accepted incoming ID
+const maxLength = 128
+
+type contextKey struct{}
+
+// New returns a random ID of 16 hex characters
+func New() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Valid reports whether an incoming ID can be kept: 1 to 128 letters, digits and - _ . :
+// Anything else (spaces, quotes, control characters) could forge log lines, so it is replaced
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		ch := id[i]
+		if !('a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || '0' <= ch && ch <= '9' ||
+			ch == '-' || ch == '_' || ch == '.' || ch == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+// Middleware keeps a valid incoming X-Request-ID or generates one, and sets it on the request header,
+// the request context and the response header before next runs
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !Valid(id) {
+			id = New()
+		}
+		r.Header.Set(Header, id)
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+	})
+}
+
+// NewContext returns ctx carrying id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the ID in ctx ("" outside Middleware)
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// From returns the ID of r ("" outside Middleware)
+func From(r *http.Request) string {
+	return FromContext(r.Context())
+}
@@ -0,0 +1,61 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestValid tests accepted and replaced incoming IDs
+func TestValid(t *testing.T) {
+	for _, id := range []string{"abc123", "0f8fad5b-d9cb-469f-a165-70867728950e", "trace:span.1_a"} {
+		if !Valid(id) {
+			t.Errorf("Expected %q to be valid", id)
+		}
+	}
+	for _, id := range []string{"", "a b", `a"b`, "a\nb", strings.Repeat("a", 129)} {
+		if Valid(id) {
+			t.Errorf("Expected %q to be invalid", id)
+		}
+	}
+	if id := New(); len(id) != 16 || !Valid(id) || id == New() {
+		t.Errorf("Unexpected generated ID %q", id)
+	}
+}
+
+// TestMiddleware tests that an incoming ID is kept, an invalid one replaced, and the ID reaches the context and response
+func TestMiddleware(t *testing.T) {
+	var seen, forwarded string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, forwarded = From(r), r.Header.Get(Header)
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"incoming", "client-42", true},
+		{"missing", "", false},
+		{"forged", "x\" level=error", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(Header, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(Header)
+			if !Valid(got) || seen != got || forwarded != got {
+				t.Errorf("Response %q, context %q and request header %q should be one valid ID", got, seen, forwarded)
+			}
+			if (got == tt.incoming) != tt.keep {
+				t.Errorf("Incoming %q kept = %v, want %v", tt.incoming, got == tt.incoming, tt.keep)
+			}
+		})
+	}
+}
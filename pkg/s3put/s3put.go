@@ -0,0 +1,90 @@
+// Package s3put stores objects in S3-compatible storage (AWS S3, Cloudflare R2, Backblaze B2, MinIO, ...).
+// It wraps minio-go with what offsite backups need: streaming put, list by prefix and delete, with
+// path-style URLs so any S3-compatible endpoint works.
+package s3put
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// partSize is the part size of uploads of unknown length; one part is buffered in memory at a time
+// and an upload can have 10000 parts (160 GiB)
+const partSize = 16 << 20
+
+// Object is one entry of a listing
+type Object struct {
+	Key          string
+	LastModified time.Time
+	Size         int64
+}
+
+// Client talks to one bucket; it is safe for concurrent use
+type Client struct {
+	client *minio.Client
+	bucket string
+}
+
+// New returns a client for bucket at endpoint (an http or https URL without a path)
+func New(endpoint, region, bucket, accessKey, secretKey string) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+		return nil, fmt.Errorf("invalid endpoint %q: must be an http(s) URL without a path, e.g. https://s3.eu-central-1.amazonaws.com", endpoint)
+	}
+	if bucket == "" || strings.Contains(bucket, "/") {
+		return nil, fmt.Errorf("invalid bucket %q", bucket)
+	}
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("access key and secret key are required")
+	}
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure:       u.Scheme == "https",
+		Region:       region, // set, so the bucket location is never looked up
+		BucketLookup: minio.BucketLookupPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	return &Client{client: client, bucket: bucket}, nil
+}
+
+// PutObject uploads body as key and returns the bytes uploaded
+// size -1 (unknown) streams body as a multipart upload of partSize parts, so it is never held in memory whole
+func (c *Client) PutObject(ctx context.Context, key string, body io.Reader, size int64, contentType string) (int64, error) {
+	info, err := c.client.PutObject(ctx, c.bucket, key, body, size, minio.PutObjectOptions{
+		ContentType: contentType,
+		PartSize:    partSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("s3: put %s: %w", key, err)
+	}
+	return info.Size, nil
+}
+
+// DeleteObject removes key; a missing key is not an error
+func (c *Client) DeleteObject(ctx context.Context, key string) error {
+	if err := c.client.RemoveObject(ctx, c.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("s3: delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// ListObjects returns every object whose key starts with prefix, following continuation tokens
+func (c *Client) ListObjects(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	for obj := range c.client.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("s3: list %s: %w", prefix, obj.Err)
+		}
+		objects = append(objects, Object{Key: obj.Key, LastModified: obj.LastModified, Size: obj.Size})
+	}
+	return objects, nil
+}
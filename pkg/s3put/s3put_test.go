@@ -0,0 +1,181 @@
+package s3put
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// fakeBucket is an in-memory bucket speaking the subset of the S3 API minio-go uses here
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	uploads map[string]map[int][]byte // upload ID -> part number -> data
+	pageLen int
+	nextID  int
+}
+
+func (f *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextID++
+		id := strconv.Itoa(f.nextID)
+		f.uploads[id] = map[int][]byte{}
+		fmt.Fprintf(w, `<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, key, id)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `<Error><Code>NoSuchUpload</Code></Error>`)
+			return
+		}
+		n, _ := strconv.Atoi(query.Get("partNumber"))
+		parts[n] = readBody(r)
+		w.Header().Set("ETag", `"part`+strconv.Itoa(n)+`"`)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		parts := f.uploads[query.Get("uploadId")]
+		var data []byte
+		for n := 1; n <= len(parts); n++ {
+			data = append(data, parts[n]...)
+		}
+		f.objects[key] = data
+		delete(f.uploads, query.Get("uploadId"))
+		fmt.Fprintf(w, `<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>%s</Key><ETag>"done"</ETag></CompleteMultipartUploadResult>`, key)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		f.objects[key] = readBody(r)
+		w.Header().Set("ETag", `"object"`)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && key == "" && query.Get("list-type") == "2":
+		var keys []string
+		for k := range f.objects {
+			if strings.HasPrefix(k, query.Get("prefix")) && k > query.Get("continuation-token") {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		truncated := len(keys) > f.pageLen
+		if truncated {
+			keys = keys[:f.pageLen]
+		}
+		io.WriteString(w, `<ListBucketResult><Name>bucket</Name>`)
+		for _, k := range keys {
+			fmt.Fprintf(w, `<Contents><Key>%s</Key><LastModified>2026-10-17T00:00:00.000Z</LastModified><Size>%d</Size></Contents>`, k, len(f.objects[k]))
+		}
+		if truncated {
+			io.WriteString(w, `<IsTruncated>true</IsTruncated><NextContinuationToken>`+keys[len(keys)-1]+`</NextContinuationToken>`)
+		}
+		io.WriteString(w, `</ListBucketResult>`)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+		io.WriteString(w, `<Error><Code>NotImplemented</Code></Error>`)
+	}
+}
+
+// readBody returns the request body, decoding the signed aws-chunked encoding minio-go uses over http
+func readBody(r *http.Request) []byte {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		data, _ := io.ReadAll(r.Body)
+		return data
+	}
+	var data []byte
+	br := bufio.NewReader(r.Body)
+	for {
+		header, err := br.ReadString('\n')
+		if err != nil {
+			return data
+		}
+		size, err := strconv.ParseInt(strings.SplitN(header, ";", 2)[0], 16, 64)
+		if err != nil || size == 0 {
+			return data
+		}
+		chunk := make([]byte, size+2) // data and CRLF
+		if _, err := io.ReadFull(br, chunk); err != nil {
+			return data
+		}
+		data = append(data, chunk[:size]...)
+	}
+}
+
+// TestClient tests streamed multipart put, paged listing, delete and error responses against a fake bucket
+func TestClient(t *testing.T) {
+	bucket := &fakeBucket{objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}, pageLen: 2}
+	srv := httptest.NewServer(bucket)
+	defer srv.Close()
+	c, err := New(srv.URL, "auto", "bucket", "key", "secret")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	ctx := context.Background()
+	for _, key := range []string{"absa/a.zip", "absa/b.zip", "absa/c d.zip", "other/x.zip"} {
+		if n, err := c.PutObject(ctx, key, strings.NewReader("zip"), -1, "application/zip"); err != nil || n != 3 {
+			t.Fatalf("PutObject(%s) = %d, %v", key, n, err)
+		}
+	}
+	if string(bucket.objects["absa/c d.zip"]) != "zip" {
+		t.Errorf("Expected the key with a space stored, got %v", bucket.objects)
+	}
+
+	// A body longer than one part is uploaded in parts without knowing its length
+	big := bytes.Repeat([]byte("0123456789abcdef"), partSize/16+1)
+	if n, err := c.PutObject(ctx, "absa/big.zip", io.MultiReader(bytes.NewReader(big)), -1, "application/zip"); err != nil || n != int64(len(big)) {
+		t.Fatalf("PutObject(big) = %d, %v", n, err)
+	}
+	if !bytes.Equal(bucket.objects["absa/big.zip"], big) || len(bucket.uploads) != 0 {
+		t.Errorf("Expected the parts joined into absa/big.zip (%d bytes), got %d bytes and %d open uploads", len(big), len(bucket.objects["absa/big.zip"]), len(bucket.uploads))
+	}
+
+	objects, err := c.ListObjects(ctx, "absa/")
+	if err != nil || len(objects) != 4 || objects[3].Key != "absa/c d.zip" || objects[0].Size != 3 || objects[0].LastModified.IsZero() {
+		t.Fatalf("Expected 4 objects over 2 pages, got %+v %v", objects, err)
+	}
+	if err := c.DeleteObject(ctx, "absa/a.zip"); err != nil || bucket.objects["absa/a.zip"] != nil {
+		t.Errorf("DeleteObject failed: %v", err)
+	}
+
+	wrong, err := New(srv.URL, "auto", "bucket", "wrong", "secret")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	var s3err minio.ErrorResponse
+	if _, err := wrong.PutObject(ctx, "absa/d.zip", strings.NewReader("zip"), -1, ""); !errors.As(err, &s3err) || s3err.Code != "AccessDenied" || s3err.StatusCode != 403 {
+		t.Errorf("Expected AccessDenied, got %v", err)
+	}
+}
+
+// TestNew tests endpoint and credential checks
+func TestNew(t *testing.T) {
+	for _, endpoint := range []string{"s3.amazonaws.com", "ftp://host", "https://host/path"} {
+		if _, err := New(endpoint, "us-east-1", "b", "k", "s"); err == nil {
+			t.Errorf("Expected %q to be refused", endpoint)
+		}
+	}
+	if _, err := New("https://host", "us-east-1", "b", "", "s"); err == nil {
+		t.Error("Expected missing credentials to be refused")
+	}
+}
@@ -0,0 +1,171 @@
+// Package store is the SQL storage layer for data that outgrows JSON files: SQLite (embedded, pure Go) by
+// default and PostgreSQL for communities that centralize the data of several bots.
+// Queries are written once with ? placeholders and passed through Rebind; the SQL used by callers must be
+// valid in both dialects (TEXT, INTEGER, BIGINT and INSERT ... ON CONFLICT ... DO UPDATE are).
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // registers "pgx"
+	_ "modernc.org/sqlite"             // registers "sqlite"
+)
+
+// Dialect is the SQL flavour of a database
+type Dialect string
+
+const (
+	SQLite   Dialect = "sqlite"
+	Postgres Dialect = "postgres"
+)
+
+// sqliteBusyTimeout is how long SQLite waits for a lock held by another connection or process
+const sqliteBusyTimeout = 5 * time.Second
+
+// DB is an open database with its dialect
+type DB struct {
+	*sql.DB
+	Dialect Dialect
+	// location names the database for logs, without credentials
+	location string
+}
+
+// Open opens and pings the database at dsn: postgres:// or postgresql:// URLs use PostgreSQL, sqlite:PATH
+// or a plain file path SQLite (the file is created if missing)
+func Open(dsn string) (*DB, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("empty database URL")
+	}
+	var db *DB
+	switch {
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PostgreSQL URL: %w", err)
+		}
+		conn, err := sql.Open("pgx", dsn)
+		if err != nil {
+			return nil, err
+		}
+		db = &DB{DB: conn, Dialect: Postgres, location: "postgres://" + u.Host + u.Path}
+	default:
+		path := strings.TrimPrefix(strings.TrimPrefix(dsn, "sqlite://"), "sqlite:")
+		if path == "" {
+			return nil, fmt.Errorf("empty SQLite path in %q", dsn)
+		}
+		pragmas := url.Values{}
+		pragmas.Add("_pragma", "busy_timeout("+strconv.FormatInt(sqliteBusyTimeout.Milliseconds(), 10)+")")
+		pragmas.Add("_pragma", "journal_mode(WAL)")
+		conn, err := sql.Open("sqlite", "file:"+path+"?"+pragmas.Encode())
+		if err != nil {
+			return nil, err
+		}
+		// One writer at a time is all SQLite allows; a single connection avoids SQLITE_BUSY between our own
+		conn.SetMaxOpenConns(1)
+		db = &DB{DB: conn, Dialect: SQLite, location: "sqlite:" + path}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open %s: %w", db, err)
+	}
+	return db, nil
+}
+
+// String names the database without credentials
+func (db *DB) String() string {
+	return db.location
+}
+
+// Rebind rewrites the ? placeholders of query for the dialect ($1, $2, ... for PostgreSQL)
+// Question marks inside quoted strings are left alone
+func (db *DB) Rebind(query string) string {
+	if db.Dialect != Postgres {
+		return query
+	}
+	var b strings.Builder
+	n, quoted := 0, false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == '?' && !quoted:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Migration is one schema change; Statements run in order in one transaction
+type Migration struct {
+	Version    int
+	Name       string
+	Statements []string
+}
+
+// Migrate applies the migrations with a version above the recorded one, in version order, and records each
+// Versions must be unique and positive; a database migrated by a newer release (unknown version) is refused
+func (db *DB) Migrate(ctx context.Context, migrations []Migration) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at BIGINT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	current, err := db.SchemaVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read the schema version: %w", err)
+	}
+
+	latest := 0
+	for i, m := range migrations {
+		if m.Version <= latest {
+			return fmt.Errorf("migration %d (%s) is out of order", m.Version, m.Name)
+		}
+		latest = migrations[i].Version
+	}
+	if current > latest {
+		return fmt.Errorf("%s has schema version %d, newer than this release knows (%d)", db, current, latest)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := db.apply(ctx, m); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// apply runs one migration and records it in the same transaction
+func (db *DB) apply(ctx context.Context, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range m.Statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, db.Rebind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+		m.Version, m.Name, time.Now().Unix()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
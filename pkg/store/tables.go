@@ -0,0 +1,143 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// identifierPattern matches the table and column names Export and Import accept, so names read from a
+// backup cannot inject SQL
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// Table holds the rows of one table in a dialect-neutral form, for backups that move between SQLite and
+// PostgreSQL. Values are int64, float64, string or nil
+type Table struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+}
+
+// UnmarshalJSON decodes integers as int64, so large values such as nanosecond times keep their precision
+func (t *Table) UnmarshalJSON(data []byte) error {
+	type plain Table
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode((*plain)(t)); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		for i, v := range row {
+			n, ok := v.(json.Number)
+			if !ok {
+				continue
+			}
+			if v, err := n.Int64(); err == nil {
+				row[i] = v
+				continue
+			}
+			f, err := n.Float64()
+			if err != nil {
+				return fmt.Errorf("table %s: invalid number %s", t.Name, n)
+			}
+			row[i] = f
+		}
+	}
+	return nil
+}
+
+// SchemaVersion returns the highest applied migration (0 before Migrate)
+func (db *DB) SchemaVersion(ctx context.Context) (int, error) {
+	var v int
+	err := db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&v)
+	return v, err
+}
+
+// ExportTable reads every row of the table name
+func (db *DB) ExportTable(ctx context.Context, name string) (*Table, error) {
+	if !identifierPattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid table name %q", name)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT * FROM "+name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	t := &Table{Name: name, Columns: columns, Rows: [][]any{}}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		for i, v := range values {
+			switch v := v.(type) {
+			case []byte:
+				values[i] = string(v)
+			case int32:
+				values[i] = int64(v)
+			case int:
+				values[i] = int64(v)
+			}
+		}
+		t.Rows = append(t.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return t, nil
+}
+
+// ImportTables replaces the rows of every table with those of tables in one transaction
+// The tables must exist (run Migrate first); columns missing from a table keep their defaults
+func (db *DB) ImportTables(ctx context.Context, tables []*Table) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, t := range tables {
+		if !identifierPattern.MatchString(t.Name) {
+			return fmt.Errorf("invalid table name %q", t.Name)
+		}
+		for _, c := range t.Columns {
+			if !identifierPattern.MatchString(c) {
+				return fmt.Errorf("table %s: invalid column name %q", t.Name, c)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+t.Name); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", t.Name, err)
+		}
+		if len(t.Rows) == 0 {
+			continue
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(t.Columns)), ", ")
+		insert, err := tx.PrepareContext(ctx, db.Rebind(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+			t.Name, strings.Join(t.Columns, ", "), placeholders)))
+		if err != nil {
+			return fmt.Errorf("failed to restore %s: %w", t.Name, err)
+		}
+		for _, row := range t.Rows {
+			if len(row) != len(t.Columns) {
+				insert.Close()
+				return fmt.Errorf("table %s: row has %d values for %d columns", t.Name, len(row), len(t.Columns))
+			}
+			if _, err := insert.ExecContext(ctx, row...); err != nil {
+				insert.Close()
+				return fmt.Errorf("failed to restore %s: %w", t.Name, err)
+			}
+		}
+		insert.Close()
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,157 @@
+// Package supervisor runs long-lived goroutines with panic recovery and restart backoff.
+// A panic in one component (update loop, API server, proxy, cleanup) must not take down the whole bot.
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+)
+
+const (
+	defaultInitialBackoff = 1 * time.Second
+	defaultMaxBackoff     = 1 * time.Minute
+
+	// stableRunDuration resets backoff: a component that ran this long before failing is considered healthy
+	stableRunDuration = 5 * time.Minute
+)
+
+// PanicError wraps a recovered panic value with the stack trace at the point of panic
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// fatalError marks an error that a restart cannot fix
+type fatalError struct {
+	err error
+}
+
+func (e *fatalError) Error() string { return e.err.Error() }
+func (e *fatalError) Unwrap() error { return e.err }
+
+// Fatal marks err as permanent: Run does not restart the component and calls Options.OnFatal instead
+// Use it when a component cannot start at all (port already in use, bad certificate, unreadable config);
+// retrying would only hide that the component is missing
+func Fatal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &fatalError{err: err}
+}
+
+// IsFatal reports whether err or an error it wraps was marked with Fatal
+func IsFatal(err error) bool {
+	var fe *fatalError
+	return errors.As(err, &fe)
+}
+
+// Options configures restart behavior for Run
+// Zero values use defaults (1s initial backoff, 1m max backoff, log.Default())
+type Options struct {
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Logger         *log.Logger
+	// OnPanic, when set, is called after a panic of the component is logged and before it is restarted
+	OnPanic func(name string, pe *PanicError)
+	// OnFatal, when set, is called when the component returns an error marked with Fatal (it is not restarted)
+	OnFatal func(name string, err error)
+}
+
+func (o Options) withDefaults() Options {
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = defaultInitialBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = defaultMaxBackoff
+	}
+	if o.MaxBackoff < o.InitialBackoff {
+		o.MaxBackoff = o.InitialBackoff
+	}
+	if o.Logger == nil {
+		o.Logger = log.Default()
+	}
+	return o
+}
+
+// Call runs fn and converts a panic into a *PanicError
+// Returns fn's own error otherwise
+func Call(ctx context.Context, fn func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
+
+// Run executes fn under supervision until ctx is cancelled, fn returns nil or fn returns a Fatal error
+// Panics and other errors are logged (panics with stack trace) and fn is restarted with exponential backoff
+// Backoff resets after fn has run for stableRunDuration without failing
+// Blocks until the component exits for good; callers typically invoke it with `go`
+func Run(ctx context.Context, name string, fn func(context.Context) error, opts Options) {
+	opts = opts.withDefaults()
+	backoff := opts.InitialBackoff
+
+	for {
+		started := time.Now()
+		err := Call(ctx, fn)
+
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			return
+		}
+
+		if pe, ok := err.(*PanicError); ok {
+			opts.Logger.Printf("ERROR: %s panicked: %v\n%s", name, pe.Value, pe.Stack)
+			if opts.OnPanic != nil {
+				opts.OnPanic(name, pe)
+			}
+		} else if IsFatal(err) {
+			opts.Logger.Printf("ERROR: %s failed permanently, not restarting: %v", name, err)
+			if opts.OnFatal != nil {
+				opts.OnFatal(name, err)
+			}
+			return
+		} else {
+			opts.Logger.Printf("ERROR: %s failed: %v", name, err)
+		}
+
+		if time.Since(started) >= stableRunDuration {
+			backoff = opts.InitialBackoff
+		}
+
+		opts.Logger.Printf("Restarting %s in %v", name, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > opts.MaxBackoff {
+			backoff = opts.MaxBackoff
+		}
+	}
+}
+
+// Recover logs a panic with its stack trace and swallows it
+// Must be called directly via defer: `defer supervisor.Recover("fetch server", logger)`
+// Use for short-lived goroutines where a restart makes no sense (one poll, one request)
+func Recover(name string, logger *log.Logger) {
+	if r := recover(); r != nil {
+		if logger == nil {
+			logger = log.Default()
+		}
+		logger.Printf("ERROR: %s panicked: %v\n%s", name, r, debug.Stack())
+	}
+}
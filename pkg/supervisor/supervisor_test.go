@@ -0,0 +1,170 @@
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a goroutine-safe log sink
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestCall_RecoversPanic(t *testing.T) {
+	err := Call(context.Background(), func(context.Context) error {
+		panic("boom")
+	})
+
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected *PanicError, got %T (%v)", err, err)
+	}
+	if pe.Value != "boom" {
+		t.Errorf("Value = %v, want boom", pe.Value)
+	}
+	if len(pe.Stack) == 0 {
+		t.Error("expected stack trace to be captured")
+	}
+}
+
+func TestCall_ReturnsError(t *testing.T) {
+	want := errors.New("failed")
+	if err := Call(context.Background(), func(context.Context) error { return want }); err != want {
+		t.Errorf("err = %v, want %v", err, want)
+	}
+}
+
+func TestRun_RestartsAfterPanic(t *testing.T) {
+	var out syncBuffer
+	logger := log.New(&out, "", 0)
+
+	var calls, panics atomic.Int32
+	onPanic := func(name string, pe *PanicError) {
+		if name == "worker" && pe.Value == "boom" {
+			panics.Add(1)
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		Run(context.Background(), "worker", func(context.Context) error {
+			if calls.Add(1) < 3 {
+				panic("boom")
+			}
+			return nil // clean exit stops supervision
+		}, Options{InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, Logger: logger, OnPanic: onPanic})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after component exited cleanly")
+	}
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("calls = %d, want 3", got)
+	}
+	if got := panics.Load(); got != 2 {
+		t.Errorf("OnPanic calls = %d, want 2", got)
+	}
+	logs := out.String()
+	if !strings.Contains(logs, "worker panicked: boom") {
+		t.Errorf("expected panic to be logged, got: %s", logs)
+	}
+	if !strings.Contains(logs, "goroutine") {
+		t.Errorf("expected stack trace in log, got: %s", logs)
+	}
+}
+
+func TestRun_StopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	logger := log.New(&syncBuffer{}, "", 0)
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, "worker", func(context.Context) error {
+			calls.Add(1)
+			return errors.New("always failing")
+		}, Options{InitialBackoff: time.Hour, Logger: logger})
+		close(done)
+	}()
+
+	// Wait for first failure, then cancel during backoff
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after context cancellation")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1 (no restart after cancel)", got)
+	}
+}
+
+func TestRecover_SwallowsPanic(t *testing.T) {
+	var out syncBuffer
+	logger := log.New(&out, "", 0)
+
+	func() {
+		defer Recover("task", logger)
+		panic("boom")
+	}()
+
+	if !strings.Contains(out.String(), "task panicked: boom") {
+		t.Errorf("expected panic to be logged, got: %s", out.String())
+	}
+}
+
+func TestRun_FatalErrorStops(t *testing.T) {
+	var out syncBuffer
+	var calls atomic.Int32
+	var fatalName string
+	var fatalErr error
+	bind := errors.New("listen tcp :3001: bind: address already in use")
+
+	Run(context.Background(), "API server", func(context.Context) error {
+		calls.Add(1)
+		return fmt.Errorf("start: %w", Fatal(bind))
+	}, Options{InitialBackoff: time.Millisecond, Logger: log.New(&out, "", 0), OnFatal: func(name string, err error) {
+		fatalName, fatalErr = name, err
+	}})
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1 (no restart)", got)
+	}
+	if fatalName != "API server" || !errors.Is(fatalErr, bind) || !IsFatal(fatalErr) {
+		t.Errorf("OnFatal got %q %v", fatalName, fatalErr)
+	}
+	if !strings.Contains(out.String(), "failed permanently") || strings.Contains(out.String(), "Restarting") {
+		t.Errorf("unexpected log: %s", out.String())
+	}
+	if Fatal(nil) != nil || IsFatal(bind) {
+		t.Error("Fatal(nil) must be nil and plain errors are not fatal")
+	}
+}
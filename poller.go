@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ================= POLLERS =================
+
+// defaultQueryType is used for servers without a query_type (Assetto Corsa HTTP /info)
+const defaultQueryType = "ac_http"
+
+// PollResult is the live game data a poller reports for one server
+// Server identity (name, category, address) is filled in by the caller
+type PollResult struct {
+	Map        string
+	Players    int
+	MaxPlayers int
+}
+
+// Poller queries one game server for its live status
+// Implementations must honor ctx (it carries the poll timeout) and return an error when the server is unreachable
+type Poller interface {
+	Poll(ctx context.Context, client *http.Client, server Server) (PollResult, error)
+}
+
+// PollerFunc adapts a function to the Poller interface
+type PollerFunc func(ctx context.Context, client *http.Client, server Server) (PollResult, error)
+
+// Poll implements Poller
+func (f PollerFunc) Poll(ctx context.Context, client *http.Client, server Server) (PollResult, error) {
+	return f(ctx, client, server)
+}
+
+// pollerRegistry maps query_type to its poller
+var pollerRegistry = struct {
+	mu      sync.RWMutex
+	pollers map[string]Poller
+}{pollers: make(map[string]Poller)}
+
+// RegisterPoller makes a poller available under queryType
+// Protocols live in their own file and register from init(); panics on empty or duplicate names like database/sql
+func RegisterPoller(queryType string, p Poller) {
+	if queryType == "" {
+		panic("RegisterPoller: empty query type")
+	}
+	if p == nil {
+		panic("RegisterPoller: nil poller for " + queryType)
+	}
+
+	pollerRegistry.mu.Lock()
+	defer pollerRegistry.mu.Unlock()
+	if _, dup := pollerRegistry.pollers[queryType]; dup {
+		panic("RegisterPoller: duplicate query type " + queryType)
+	}
+	pollerRegistry.pollers[queryType] = p
+}
+
+// lookupPoller returns the poller for queryType (empty = defaultQueryType)
+func lookupPoller(queryType string) (Poller, bool) {
+	if queryType == "" {
+		queryType = defaultQueryType
+	}
+	pollerRegistry.mu.RLock()
+	defer pollerRegistry.mu.RUnlock()
+	p, ok := pollerRegistry.pollers[queryType]
+	return p, ok
+}
+
+// registeredQueryTypes returns all registered query types, sorted (for error messages)
+func registeredQueryTypes() []string {
+	pollerRegistry.mu.RLock()
+	defer pollerRegistry.mu.RUnlock()
+	types := make([]string, 0, len(pollerRegistry.pollers))
+	for t := range pollerRegistry.pollers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// validateQueryType checks that a server's query_type has a registered poller
+func validateQueryType(server Server) error {
+	if _, ok := lookupPoller(server.QueryType); !ok {
+		return fmt.Errorf("server '%s' has unknown query_type '%s' (available: %v)", server.Name, server.QueryType, registeredQueryTypes())
+	}
+	return nil
+}
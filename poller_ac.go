@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+)
+
+// ================= ASSETTO CORSA (HTTP /info) =================
+
+func init() {
+	RegisterPoller(defaultQueryType, acHTTPPoller{})
+}
+
+// acHTTPPoller polls /info, and the car list behind /JSON| when ENTRY_LIST_ENABLED is set
+type acHTTPPoller struct{}
+
+// Poll implements Poller
+func (acHTTPPoller) Poll(ctx context.Context, client *http.Client, server Server) (PollResult, error) {
+	return pollACHTTP(ctx, client, server)
+}
+
+// PollEntryList implements EntryListPoller
+func (acHTTPPoller) PollEntryList(ctx context.Context, client *http.Client, server Server) ([]EntryListCar, error) {
+	return pollACEntryList(ctx, client, server)
+}
+
+// pollACHTTP queries the Assetto Corsa server HTTP port (GET /info)
+func pollACHTTP(ctx context.Context, client *http.Client, server Server) (PollResult, error) {
+	url := fmt.Sprintf("http://%s:%d/info", server.IP, server.Port)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return PollResult{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return PollResult{}, fmt.Errorf("(%s) request failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return PollResult{}, fmt.Errorf("(%s) returned status %d", url, resp.StatusCode)
+	}
+
+	result, err := parseACInfo(resp.Body)
+	if err != nil {
+		return PollResult{}, fmt.Errorf("(%s) %w", url, err)
+	}
+	return result, nil
+}
+
+// parseACInfo decodes an /info response body
+// The track is reported as a content path (content/tracks/ks_nordschleife); only the last element is shown
+func parseACInfo(r io.Reader) (PollResult, error) {
+	var data struct {
+		Clients    int    `json:"clients"`
+		MaxClients int    `json:"maxclients"`
+		Track      string `json:"track"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return PollResult{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	trackName := filepath.Base(data.Track)
+	if trackName == "." || trackName == "" {
+		trackName = "Unknown"
+	}
+
+	return PollResult{Map: trackName, Players: data.Clients, MaxPlayers: data.MaxClients}, nil
+}
+
+// pollACEntryList queries the car list of an Assetto Corsa server (GET /JSON|, the endpoint Content Manager uses)
+func pollACEntryList(ctx context.Context, client *http.Client, server Server) ([]EntryListCar, error) {
+	url := fmt.Sprintf("http://%s:%d/JSON|", server.IP, server.Port)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	// The AC server matches the raw request line: send the pipe unescaped
+	req.URL.Opaque = "/JSON|"
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("(%s) request failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("(%s) returned status %d", url, resp.StatusCode)
+	}
+
+	cars, err := parseACEntryList(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("(%s) %w", url, err)
+	}
+	return cars, nil
+}
+
+// parseACEntryList decodes a /JSON| response body
+func parseACEntryList(r io.Reader) ([]EntryListCar, error) {
+	var data struct {
+		Cars []struct {
+			Model        string `json:"Model"`
+			Skin         string `json:"Skin"`
+			DriverName   string `json:"DriverName"`
+			DriverTeam   string `json:"DriverTeam"`
+			DriverNation string `json:"DriverNation"`
+			IsConnected  bool   `json:"IsConnected"`
+		} `json:"Cars"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode entry list: %w", err)
+	}
+
+	cars := make([]EntryListCar, 0, len(data.Cars))
+	for _, c := range data.Cars {
+		cars = append(cars, EntryListCar{
+			Model:     c.Model,
+			Skin:      c.Skin,
+			Driver:    c.DriverName,
+			Team:      c.DriverTeam,
+			Nation:    c.DriverNation,
+			Connected: c.IsConnected,
+		})
+	}
+	return cars, nil
+}
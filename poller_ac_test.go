@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseACInfo tests decoding recorded /info responses
+func TestParseACInfo(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    PollResult
+		wantErr bool
+	}{
+		{fixture: "info_online.json", want: PollResult{Map: "tourist", Players: 7, MaxPlayers: 24}},
+		{fixture: "info_no_track.json", want: PollResult{Map: "Unknown", Players: 0, MaxPlayers: 16}},
+		{fixture: "info_not_json.html", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "ac_http", tt.fixture))
+			if err != nil {
+				t.Fatalf("Failed to open fixture: %v", err)
+			}
+			defer f.Close()
+
+			got, err := parseACInfo(f)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected decode error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestPollACHTTP tests the HTTP poller against a server replaying a fixture
+func TestPollACHTTP(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "ac_http", "info_online.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/info" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	host, portStr, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	got, err := pollACHTTP(context.Background(), srv.Client(), Server{Name: "Test", IP: host, Port: port})
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if got.Players != 7 || got.MaxPlayers != 24 || got.Map != "tourist" {
+		t.Errorf("Unexpected result %+v", got)
+	}
+}
+
+// TestPollACHTTP_Errors tests that non-200 responses and unreachable servers are errors
+func TestPollACHTTP_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	host, portStr, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	if _, err := pollACHTTP(context.Background(), srv.Client(), Server{IP: host, Port: port}); err == nil {
+		t.Error("Expected error for HTTP 502")
+	}
+
+	srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := pollACHTTP(ctx, http.DefaultClient, Server{IP: host, Port: port}); err == nil {
+		t.Error("Expected error for closed server")
+	}
+}
+
+// TestPollACEntryList tests the entry list request (unescaped pipe) and decoding
+func TestPollACEntryList(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "ac_http", "entrylist.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI != "/JSON|" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	host, portStr, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	cars, err := acHTTPPoller{}.PollEntryList(context.Background(), srv.Client(), Server{Name: "Test", IP: host, Port: port})
+	if err != nil {
+		t.Fatalf("Entry list poll failed: %v", err)
+	}
+	want := EntryListCar{Model: "ks_ferrari_488_gt3", Skin: "red", Driver: "Ola Nordmann", Team: "Team Fjord", Nation: "NOR", Connected: true}
+	if len(cars) != 4 || cars[0] != want || cars[1].Connected || cars[1].Driver != "" {
+		t.Errorf("Unexpected cars %+v", cars)
+	}
+
+	if _, err := parseACEntryList(strings.NewReader("<html>")); err == nil {
+		t.Error("Expected decode error for a non-JSON body")
+	}
+}
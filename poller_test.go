@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// withTestPoller registers p under queryType for the duration of the test
+func withTestPoller(t *testing.T, queryType string, p Poller) {
+	t.Helper()
+	RegisterPoller(queryType, p)
+	t.Cleanup(func() {
+		pollerRegistry.mu.Lock()
+		delete(pollerRegistry.pollers, queryType)
+		pollerRegistry.mu.Unlock()
+	})
+}
+
+// TestLookupPoller_Default tests that an empty query_type uses the Assetto Corsa poller
+func TestLookupPoller_Default(t *testing.T) {
+	if _, ok := lookupPoller(""); !ok {
+		t.Error("Expected default poller for empty query_type")
+	}
+	if _, ok := lookupPoller(defaultQueryType); !ok {
+		t.Errorf("Expected %s to be registered", defaultQueryType)
+	}
+	if _, ok := lookupPoller("no_such_protocol"); ok {
+		t.Error("Expected unknown query_type to be missing")
+	}
+}
+
+// TestRegisterPoller_Panics tests registration guards
+func TestRegisterPoller_Panics(t *testing.T) {
+	tests := []struct {
+		name      string
+		queryType string
+		poller    Poller
+	}{
+		{name: "Empty name", queryType: "", poller: PollerFunc(nil)},
+		{name: "Nil poller", queryType: "test_nil", poller: nil},
+		{name: "Duplicate", queryType: defaultQueryType, poller: PollerFunc(pollACHTTP)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			RegisterPoller(tt.queryType, tt.poller)
+		})
+	}
+}
+
+// TestFetchServerInfo_CustomPoller tests that servers are dispatched to the poller for their query_type
+func TestFetchServerInfo_CustomPoller(t *testing.T) {
+	withTestPoller(t, "test_game", PollerFunc(func(ctx context.Context, client *http.Client, s Server) (PollResult, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("Expected poll context to carry the client timeout")
+		}
+		return PollResult{Map: "monza", Players: 5, MaxPlayers: 30}, nil
+	}))
+
+	client := (&pollClientManager{}).Get(nil)
+	info := fetchServerInfo(client, Server{Name: "Custom", Category: "Track", QueryType: "test_game", IP: "203.0.113.10", Port: 9000})
+
+	if info.NumPlayers != 5 || info.MaxPlayers != 30 || info.Map != "monza" || info.Players != "5/30" {
+		t.Errorf("Unexpected info %+v", info)
+	}
+	if info.Name != "Custom" || info.Category != "Track" || info.Port != 9000 {
+		t.Errorf("Expected server identity preserved, got %+v", info)
+	}
+}
+
+// TestFetchServerInfo_PollerErrorIsOffline tests that poll errors and unknown types report offline
+func TestFetchServerInfo_PollerErrorIsOffline(t *testing.T) {
+	withTestPoller(t, "test_broken", PollerFunc(func(ctx context.Context, client *http.Client, s Server) (PollResult, error) {
+		return PollResult{}, errors.New("protocol error")
+	}))
+	client := (&pollClientManager{}).Get(nil)
+
+	for _, queryType := range []string{"test_broken", "no_such_protocol"} {
+		info := fetchServerInfo(client, Server{Name: "Broken", QueryType: queryType})
+		if info.NumPlayers != -1 {
+			t.Errorf("query_type %s: expected offline, got %+v", queryType, info)
+		}
+	}
+}
+
+// TestValidateQueryType tests config validation of query_type
+func TestValidateQueryType(t *testing.T) {
+	if err := validateQueryType(Server{Name: "Default"}); err != nil {
+		t.Errorf("Expected empty query_type to be valid, got %v", err)
+	}
+	if err := validateQueryType(Server{Name: "Bad", QueryType: "quake3"}); err == nil {
+		t.Error("Expected error for unknown query_type")
+	}
+
+	cfg := &Config{
+		ServerIP:       "127.0.0.1",
+		UpdateInterval: 30,
+		CategoryOrder:  []string{"Drift"},
+		CategoryEmojis: map[string]string{"Drift": "🟣"},
+		Servers:        []Server{{Name: "Bad", Port: 8081, Category: "Drift", QueryType: "quake3"}},
+	}
+	if err := validateConfigStructSafeRuntime(cfg); err == nil {
+		t.Error("Expected runtime validation to reject unknown query_type")
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bombom/absa-ac/pkg/supervisor"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= SERVER POWER CONTROL =================
+
+// Power states reported by backends
+const (
+	powerRunning    = "running"
+	powerStarting   = "starting"
+	powerStopping   = "stopping"
+	powerStopped    = "stopped"
+	powerRestarting = "restarting"
+	powerPaused     = "paused"
+	powerUnhealthy  = "unhealthy"
+	powerSuspended  = "suspended" // by the hosting panel
+	powerUnknown    = "unknown"   // the backend could not be asked
+)
+
+// Power actions
+const (
+	powerStart   = "start"
+	powerStop    = "stop"
+	powerRestart = "restart"
+)
+
+const (
+	// powerStateTimeout bounds one state query after a poll
+	powerStateTimeout = 5 * time.Second
+	// powerActionTimeout bounds one action (stopping waits for the AC server to exit)
+	powerActionTimeout = 60 * time.Second
+)
+
+// powerBackend controls the process of a game server (a Docker container or a Pterodactyl server)
+type powerBackend interface {
+	name() string
+	// label is what the state is shown as in the embed ("Container")
+	label() string
+	// target returns what the backend controls for server, for logs and the API ("" = not managed by this backend)
+	target(cfg *Config, server string) string
+	state(ctx context.Context, cfg *Config, server string) (string, error)
+	act(ctx context.Context, cfg *Config, server, action string) error
+}
+
+// pollFallback is implemented by backends whose "running" state stands in for a failed poll (Pterodactyl):
+// the server is shown online with unknown track and players instead of offline
+type pollFallback interface {
+	fallbackForPolls()
+}
+
+// powerStatus is the last known power state of a server
+type powerStatus struct {
+	backend string
+	label   string
+	target  string
+	state   string
+	err     string // why the state is unknown
+	at      time.Time
+	// fallback is set when a running state stands in for a failed poll
+	fallback bool
+}
+
+// serverPower is the power state shown under a server in the embed
+type serverPower struct {
+	label string // "Container"
+	state string
+}
+
+// powerControl reads the power state of managed servers after every poll and runs power actions
+// Actions run in the background (stopping can take longer than a slash command may wait); one per server at a time
+type powerControl struct {
+	backends []powerBackend
+
+	mu      sync.Mutex
+	states  map[string]powerStatus // by server name
+	pending map[string]string      // server -> action in progress
+
+	// running tracks background actions (tests wait on it)
+	running sync.WaitGroup
+}
+
+func newPowerControl(backends ...powerBackend) *powerControl {
+	return &powerControl{backends: backends, states: make(map[string]powerStatus), pending: make(map[string]string)}
+}
+
+// backendFor returns the backend managing server and its target
+func (p *powerControl) backendFor(cfg *Config, server string) (powerBackend, string) {
+	for _, b := range p.backends {
+		if t := b.target(cfg, server); t != "" {
+			return b, t
+		}
+	}
+	return nil, ""
+}
+
+// refresh queries the state of every managed server concurrently and forgets servers no longer managed
+func (p *powerControl) refresh(cfg *Config, now time.Time) {
+	var wg sync.WaitGroup
+	managed := make(map[string]bool)
+	for _, s := range cfg.Servers {
+		backend, _ := p.backendFor(cfg, s.Name)
+		if backend == nil || managed[s.Name] {
+			continue
+		}
+		managed[s.Name] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer supervisor.Recover(fmt.Sprintf("power state '%s'", s.Name), log.Default())
+			p.query(cfg, s.Name, backend, now)
+		}()
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for server := range p.states {
+		if !managed[server] {
+			delete(p.states, server)
+		}
+	}
+}
+
+// query asks backend for the state of server and stores it; a failure is logged when it first occurs
+func (p *powerControl) query(cfg *Config, server string, backend powerBackend, now time.Time) powerStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), powerStateTimeout)
+	defer cancel()
+	_, fallback := backend.(pollFallback)
+	st := powerStatus{backend: backend.name(), label: backend.label(), target: backend.target(cfg, server), at: now, fallback: fallback}
+	state, err := backend.state(ctx, cfg, server)
+	if err != nil {
+		st.state, st.err = powerUnknown, err.Error()
+	} else {
+		st.state = state
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if prev := p.states[server]; err != nil && prev.err != st.err {
+		log.Printf("Server '%s' %s state: %v", server, backend.name(), err)
+	}
+	p.states[server] = st
+	return st
+}
+
+// apply shows the power state on infos of managed servers that are not running
+// A server whose poll failed while a fallback backend reports it running is shown online
+func (p *powerControl) apply(infos []ServerInfo) []ServerInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := range infos {
+		info := &infos[i]
+		st, ok := p.states[info.Name]
+		if !ok {
+			continue
+		}
+		if action, busy := p.pending[info.Name]; busy {
+			info.Power = &serverPower{label: st.label, state: action + " requested"}
+		} else if st.state != powerRunning {
+			info.Power = &serverPower{label: st.label, state: st.state}
+		} else if st.fallback && info.NumPlayers < 0 && info.Maintenance == nil {
+			info.Map, info.Players, info.NumPlayers, info.MaxPlayers = "Unknown", "?", 0, 0
+			info.Power = &serverPower{label: st.label, state: "running (not answering queries)"}
+		}
+	}
+	return infos
+}
+
+// trackPower refreshes power states after a poll and shows them in the embed (no-op when disabled)
+func (b *Bot) trackPower(infos []ServerInfo, cfg *Config) []ServerInfo {
+	if b.power == nil {
+		return infos
+	}
+	b.power.refresh(cfg, time.Now())
+	return b.power.apply(infos)
+}
+
+// start runs action on server in the background and returns its state at the time of the request
+// Errors: api.ErrUnknownServer (not managed), api.ErrPowerBusy (another action running), invalid action
+func (p *powerControl) start(cfg *Config, server, action, by string) (powerStatus, error) {
+	if action != powerStart && action != powerStop && action != powerRestart {
+		return powerStatus{}, fmt.Errorf("unknown action '%s' (use start, stop or restart)", action)
+	}
+	if cfg == nil {
+		return powerStatus{}, fmt.Errorf("no config loaded")
+	}
+	backend, target := p.backendFor(cfg, server)
+	if backend == nil {
+		return powerStatus{}, fmt.Errorf("%w '%s' (no container or panel configured)", api.ErrUnknownServer, server)
+	}
+
+	p.mu.Lock()
+	if running, busy := p.pending[server]; busy {
+		p.mu.Unlock()
+		return powerStatus{}, fmt.Errorf("%w: %s of '%s' is still running", api.ErrPowerBusy, running, server)
+	}
+	p.pending[server] = action
+	st, ok := p.states[server]
+	if !ok {
+		st = powerStatus{backend: backend.name(), label: backend.label(), target: target, state: powerUnknown}
+		p.states[server] = st
+	}
+	p.mu.Unlock()
+
+	log.Printf("Audit: %s requested %s of '%s' (%s %s)", by, action, server, backend.name(), target)
+	p.running.Add(1)
+	go func() {
+		defer p.running.Done()
+		defer func() {
+			p.mu.Lock()
+			delete(p.pending, server)
+			p.mu.Unlock()
+		}()
+		defer supervisor.Recover(fmt.Sprintf("power %s '%s'", action, server), log.Default())
+		ctx, cancel := context.WithTimeout(context.Background(), powerActionTimeout)
+		defer cancel()
+		if err := backend.act(ctx, cfg, server, action); err != nil {
+			log.Printf("Error running %s of '%s': %v", action, server, err)
+		} else {
+			log.Printf("Finished %s of '%s'", action, server)
+		}
+		p.query(cfg, server, backend, time.Now())
+	}()
+	return st, nil
+}
+
+// powerCommand is /power start|stop|restart|status, limited to members with Manage Server by default
+func powerCommand() *slashCommand {
+	manageGuild := int64(discordgo.PermissionManageGuild)
+	serverOption := []*discordgo.ApplicationCommandOption{{
+		Type: discordgo.ApplicationCommandOptionString, Name: "server", Description: "Server name",
+		Required: true, Autocomplete: true,
+	}}
+	return &slashCommand{
+		def: &discordgo.ApplicationCommand{
+			Name:                     "power",
+			Description:              "Start, stop or restart a game server (Docker container or Pterodactyl panel)",
+			DefaultMemberPermissions: &manageGuild,
+			Contexts:                 &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild},
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionSubCommand, Name: powerStart, Description: "Start the server", Options: serverOption},
+				{Type: discordgo.ApplicationCommandOptionSubCommand, Name: powerStop, Description: "Stop the server", Options: serverOption},
+				{Type: discordgo.ApplicationCommandOptionSubCommand, Name: powerRestart, Description: "Restart the server", Options: serverOption},
+				{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "status", Description: "Show the server's process state", Options: serverOption},
+			},
+		},
+		run:      runPowerCommand,
+		complete: completeServerName,
+		enabled:  func(b *Bot) bool { return b.power != nil },
+	}
+}
+
+// runPowerCommand handles /power
+func runPowerCommand(b *Bot, user invoker, opts []*discordgo.ApplicationCommandInteractionDataOption) string {
+	if b.power == nil || len(opts) != 1 {
+		return "Unknown subcommand"
+	}
+	sub := opts[0]
+	server := optionString(sub.Options, "server")
+	cfg := b.configManager.GetConfig()
+	if sub.Name == "status" {
+		if cfg == nil {
+			return "❌ No config loaded"
+		}
+		backend, target := b.power.backendFor(cfg, server)
+		if backend == nil {
+			return fmt.Sprintf("**%s** has no container or panel configured", server)
+		}
+		st := b.power.query(cfg, server, backend, time.Now())
+		if st.err != "" {
+			return fmt.Sprintf("❓ **%s** (%s %s): %s", server, backend.name(), target, st.err)
+		}
+		return fmt.Sprintf("**%s** (%s %s) is %s", server, backend.name(), target, st.state)
+	}
+
+	if _, err := b.power.start(cfg, server, sub.Name, user.name); err != nil {
+		if errors.Is(err, api.ErrUnknownServer) {
+			return fmt.Sprintf("❌ **%s** has no container or panel configured", server)
+		}
+		return "❌ " + err.Error()
+	}
+	return fmt.Sprintf("⏳ Sent %s to **%s**; the status shows the result after the next update", sub.Name, server)
+}
+
+// powerController adapts powerControl to api.PowerController
+type powerController struct {
+	bot *Bot
+}
+
+// PowerStates implements api.PowerController
+func (c *powerController) PowerStates() []api.PowerStatus {
+	p := c.bot.power
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]api.PowerStatus, 0, len(p.states))
+	for server, st := range p.states {
+		out = append(out, st.apiStatus(server, p.pending[server]))
+	}
+	slices.SortFunc(out, func(a, b api.PowerStatus) int { return strings.Compare(a.Server, b.Server) })
+	return out
+}
+
+// PowerAction implements api.PowerController
+func (c *powerController) PowerAction(server, action, by string) (api.PowerStatus, error) {
+	st, err := c.bot.power.start(c.bot.configManager.GetConfig(), server, action, by)
+	if err != nil {
+		return api.PowerStatus{}, err
+	}
+	return st.apiStatus(server, action), nil
+}
+
+func (st powerStatus) apiStatus(server, pending string) api.PowerStatus {
+	out := api.PowerStatus{Server: server, Backend: st.backend, Target: st.target, State: st.state, Error: st.err, Pending: pending}
+	if !st.at.IsZero() {
+		out.UpdatedAt = st.at.UTC()
+	}
+	return out
+}
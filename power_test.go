@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakePowerBackend keeps container states in a map; act blocks until release is closed when set
+type fakePowerBackend struct {
+	mu      sync.Mutex
+	states  map[string]string
+	err     error
+	release chan struct{}
+	actions []string
+}
+
+func (f *fakePowerBackend) name() string { return "docker" }
+
+func (f *fakePowerBackend) label() string { return "Container" }
+
+func (f *fakePowerBackend) target(cfg *Config, server string) string { return cfg.Containers[server] }
+
+func (f *fakePowerBackend) state(ctx context.Context, cfg *Config, server string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return "", f.err
+	}
+	return f.states[cfg.Containers[server]], nil
+}
+
+func (f *fakePowerBackend) act(ctx context.Context, cfg *Config, server, action string) error {
+	target := cfg.Containers[server]
+	if f.release != nil {
+		<-f.release
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actions = append(f.actions, action+" "+target)
+	if action == powerStop {
+		f.states[target] = powerStopped
+	} else {
+		f.states[target] = powerRunning
+	}
+	return nil
+}
+
+// testPowerBot manages "Track #1" as container ac-track
+func testPowerBot() (*Bot, *fakePowerBackend) {
+	cfg := testMaintenanceConfig()
+	cfg.Containers = map[string]string{"Track #1": "ac-track"}
+	b := newTestBot(cfg)
+	backend := &fakePowerBackend{states: map[string]string{"ac-track": powerStopped}}
+	b.power = newPowerControl(backend)
+	return b, backend
+}
+
+// TestPowerStateInEmbed tests that stopped containers show in the server info and running ones do not
+func TestPowerStateInEmbed(t *testing.T) {
+	b, backend := testPowerBot()
+	cfg := b.configManager.GetConfig()
+	infos := []ServerInfo{{Name: "Drift #1", Category: "Drift"}, {Name: "Track #1", Category: "Track", NumPlayers: -1}}
+
+	infos = b.trackPower(infos, cfg)
+	if infos[0].Power != nil || infos[1].Power == nil || *infos[1].Power != (serverPower{label: "Container", state: powerStopped}) {
+		t.Errorf("Power = %+v / %+v, want only Track #1 stopped", infos[0].Power, infos[1].Power)
+	}
+	embed := buildEmbed(infos, cfg)
+	if !strings.Contains(embed.Fields[len(embed.Fields)-2].Value, "**Container:** stopped") {
+		t.Errorf("Expected the container state in the embed, got %+v", embed.Fields)
+	}
+
+	backend.states["ac-track"] = powerRunning
+	infos[1].Power = nil
+	if infos = b.trackPower(infos, cfg); infos[1].Power != nil {
+		t.Errorf("Expected no state for a running container, got %+v", infos[1].Power)
+	}
+
+	backend.err = errors.New("socket unreachable")
+	if infos = b.trackPower(infos, cfg); infos[1].Power == nil || infos[1].Power.state != powerUnknown {
+		t.Errorf("Expected unknown after a failed query, got %+v", infos[1].Power)
+	}
+}
+
+// TestPowerAction tests background actions, the busy guard, unknown servers and invalid actions
+func TestPowerAction(t *testing.T) {
+	b, backend := testPowerBot()
+	cfg := b.configManager.GetConfig()
+	backend.release = make(chan struct{})
+
+	if _, err := b.power.start(cfg, "Track #1", powerRestart, "test"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, err := b.power.start(cfg, "Track #1", powerStop, "test"); !errors.Is(err, api.ErrPowerBusy) {
+		t.Errorf("Expected ErrPowerBusy while restarting, got %v", err)
+	}
+	if infos := b.power.apply([]ServerInfo{{Name: "Track #1"}}); infos[0].Power == nil || infos[0].Power.state != "restart requested" {
+		t.Errorf("Expected the pending action in the embed, got %+v", infos[0].Power)
+	}
+	close(backend.release)
+	b.power.running.Wait()
+
+	if len(backend.actions) != 1 || backend.actions[0] != "restart ac-track" {
+		t.Errorf("Actions = %v", backend.actions)
+	}
+	states := (&powerController{bot: b}).PowerStates()
+	if len(states) != 1 || states[0].State != powerRunning || states[0].Pending != "" {
+		t.Errorf("States after the restart = %+v", states)
+	}
+
+	if _, err := b.power.start(cfg, "Drift #1", powerStart, "test"); !errors.Is(err, api.ErrUnknownServer) {
+		t.Errorf("Expected ErrUnknownServer for an unmanaged server, got %v", err)
+	}
+	if _, err := b.power.start(cfg, "Track #1", "explode", "test"); err == nil {
+		t.Error("Expected an error for an unknown action")
+	}
+}
+
+// TestPowerCommand tests /power actions and status
+func TestPowerCommand(t *testing.T) {
+	b, backend := testPowerBot()
+	admin := invoker{id: "1", name: "admin"}
+	sub := func(name, server string) []*discordgo.ApplicationCommandInteractionDataOption {
+		return []*discordgo.ApplicationCommandInteractionDataOption{{
+			Name: name, Type: discordgo.ApplicationCommandOptionSubCommand,
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{{Name: "server", Type: discordgo.ApplicationCommandOptionString, Value: server}},
+		}}
+	}
+
+	if reply := runPowerCommand(b, admin, sub("start", "Track #1")); !strings.HasPrefix(reply, "⏳") {
+		t.Fatalf("Unexpected reply %q", reply)
+	}
+	b.power.running.Wait()
+	if backend.states["ac-track"] != powerRunning {
+		t.Errorf("Expected the container started, got %q", backend.states["ac-track"])
+	}
+	if reply := runPowerCommand(b, admin, sub("status", "Track #1")); !strings.Contains(reply, "is running") {
+		t.Errorf("Unexpected status %q", reply)
+	}
+	if reply := runPowerCommand(b, admin, sub("stop", "Drift #1")); !strings.Contains(reply, "no container") {
+		t.Errorf("Expected an unmanaged server refused, got %q", reply)
+	}
+	if cmd := powerCommand(); cmd.enabled(&Bot{}) || !cmd.enabled(b) {
+		t.Error("Expected /power registered only with a backend")
+	}
+}
+
+// TestPowerRefreshForgetsUnmanaged tests that servers removed from containers lose their state
+func TestPowerRefreshForgetsUnmanaged(t *testing.T) {
+	b, _ := testPowerBot()
+	cfg := b.configManager.GetConfig()
+	b.power.refresh(cfg, time.Now())
+	next := cfg.Clone()
+	next.Containers = nil
+	b.power.refresh(next, time.Now())
+	if states := (&powerController{bot: b}).PowerStates(); len(states) != 0 {
+		t.Errorf("Expected no states, got %+v", states)
+	}
+}
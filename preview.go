@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= EMBED PREVIEW =================
+
+// polledServers is the latest poll result, kept so the embed can be re-rendered on demand
+type polledServers struct {
+	infos []ServerInfo
+	at    time.Time
+}
+
+// statusEmbed renders the Discord status embed; attachBanner points the image at the attached PNG banner
+func statusEmbed(infos []ServerInfo, cfg *Config, attachBanner bool) *discordgo.MessageEmbed {
+	embed := buildEmbed(infos, cfg)
+	if attachBanner {
+		embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + bannerAttachmentName}
+	}
+	return embed
+}
+
+// embedPreviewer implements api.EmbedPreviewer: the latest poll rendered with the current config,
+// split into pages like the Discord publishers do
+type embedPreviewer struct {
+	bot *Bot
+}
+
+// EmbedPreview implements api.EmbedPreviewer
+func (p *embedPreviewer) EmbedPreview() *api.EmbedPreview {
+	poll := p.bot.lastPoll.Load()
+	cfg := p.bot.configManager.GetConfig()
+	if poll == nil || cfg == nil {
+		return nil
+	}
+	attach := p.bot.banner != nil && p.bot.banner.attach
+	embed := statusEmbed(poll.infos, cfg, attach)
+	p.bot.images.rewrite(embed, cfg)
+	pages := paginateEmbed(embed, embedHeaderColors(embed, cfg))
+	return &api.EmbedPreview{Embeds: pages, Markdown: embedMarkdown(pages), PolledAt: poll.at.UTC()}
+}
+
+// embedMarkdown approximates how Discord displays the embed pages, separated by horizontal rules
+// Blank (zero-width space) fields become empty lines
+func embedMarkdown(pages []*discordgo.MessageEmbed) string {
+	var sb strings.Builder
+	for i, e := range pages {
+		if i > 0 {
+			sb.WriteString("\n---\n\n")
+		}
+		if e.Title != "" {
+			fmt.Fprintf(&sb, "## %s\n\n", e.Title)
+		}
+		if e.Description != "" {
+			fmt.Fprintf(&sb, "%s\n\n", e.Description)
+		}
+		for _, f := range e.Fields {
+			for _, line := range []string{f.Name, f.Value} {
+				if line = strings.ReplaceAll(line, embedFieldBlank, ""); line != "" {
+					sb.WriteString(line + "\n")
+				}
+			}
+			sb.WriteString("\n")
+		}
+		if e.Image != nil {
+			fmt.Fprintf(&sb, "![image](%s)\n\n", e.Image.URL)
+		}
+		if e.Footer != nil && e.Footer.Text != "" {
+			fmt.Fprintf(&sb, "*%s*\n", e.Footer.Text)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestEmbedPreview tests rendering the latest poll with the current config
+func TestEmbedPreview(t *testing.T) {
+	cfg := testStatusConfig()
+	b := newTestBot(cfg)
+	p := &embedPreviewer{bot: b}
+	if p.EmbedPreview() != nil {
+		t.Fatal("Expected no preview before the first poll")
+	}
+
+	polled := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	infos := []ServerInfo{{Name: "Drift 1", Category: "Drift", Map: "ebisu", Players: "2/16", NumPlayers: 2, MaxPlayers: 16, IP: cfg.ServerIP, Port: 8081}}
+	b.lastPoll.Store(&polledServers{infos: infos, at: polled})
+
+	preview := p.EmbedPreview()
+	if preview == nil {
+		t.Fatal("Expected a preview after a poll")
+	}
+	if !preview.PolledAt.Equal(polled) {
+		t.Errorf("PolledAt = %v, want %v", preview.PolledAt, polled)
+	}
+	for _, want := range []string{"## " + defaultStatusTitle, "Drift Servers — 2 players", "**Map:** ebisu", "*Updates every 30 seconds*"} {
+		if !strings.Contains(preview.Markdown, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, preview.Markdown)
+		}
+	}
+	if strings.Contains(preview.Markdown, embedFieldBlank) {
+		t.Error("Expected zero-width spaces stripped from markdown")
+	}
+}
+
+// TestEmbedMarkdown_Pages tests that split embeds are rendered page by page
+func TestEmbedMarkdown_Pages(t *testing.T) {
+	md := embedMarkdown(paginateEmbed(largeStatusEmbed(4, 12), nil))
+	if n := strings.Count(md, "\n---\n"); n != 2 {
+		t.Errorf("Expected 3 pages separated by 2 rules, got %d", n)
+	}
+	if !strings.Contains(md, "## "+defaultStatusTitle+continuedTitleSuffix) || !strings.Contains(md, "Page 3/3") {
+		t.Error("Expected continuation headers and page footers in markdown")
+	}
+}
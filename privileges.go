@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= INTENT AND PRIVILEGE AUDIT =================
+
+// gatewayIntent is a Discord gateway intent the bot can request
+type gatewayIntent struct {
+	bit        discordgo.Intent
+	name       string
+	privileged bool
+	purpose    string
+	// appFlags are the application flags that show the intent enabled in the Developer Portal (privileged only)
+	appFlags int
+}
+
+// Application flags of the privileged intents (the limited variants apply to unverified bots under 100 guilds)
+const (
+	appFlagGatewayPresence              = 1 << 12
+	appFlagGatewayPresenceLimited       = 1 << 13
+	appFlagGatewayGuildMembers          = 1 << 14
+	appFlagGatewayGuildMembersLimited   = 1 << 15
+	appFlagGatewayMessageContent        = 1 << 18
+	appFlagGatewayMessageContentLimited = 1 << 19
+)
+
+var (
+	guildMessagesIntent  = gatewayIntent{bit: discordgo.IntentGuildMessages, name: "Guild Messages", purpose: "receive text commands"}
+	messageContentIntent = gatewayIntent{bit: discordgo.IntentMessageContent, name: "Message Content", privileged: true,
+		purpose: "read text commands", appFlags: appFlagGatewayMessageContent | appFlagGatewayMessageContentLimited}
+)
+
+// privilegedIntents are all privileged intents, for reporting those enabled in the portal but not requested
+var privilegedIntents = []gatewayIntent{
+	{bit: discordgo.IntentGuildPresences, name: "Presence", privileged: true, appFlags: appFlagGatewayPresence | appFlagGatewayPresenceLimited},
+	{bit: discordgo.IntentGuildMembers, name: "Server Members", privileged: true, appFlags: appFlagGatewayGuildMembers | appFlagGatewayGuildMembersLimited},
+	messageContentIntent,
+}
+
+// requiredIntents returns the intents the enabled features need; everything else (status message, slash
+// commands, buttons, DMs) works over REST and interactions, which need no intent
+// Privileged intents are only ever requested through a feature that needs them
+func (b *Bot) requiredIntents() []gatewayIntent {
+	var intents []gatewayIntent
+	if b.textCommands != nil {
+		intents = append(intents, guildMessagesIntent, messageContentIntent)
+	}
+	return intents
+}
+
+// gatewayIntents returns the intent bits to identify with
+func (b *Bot) gatewayIntents() discordgo.Intent {
+	bits := discordgo.IntentsNone
+	for _, i := range b.requiredIntents() {
+		bits |= i.bit
+	}
+	return bits
+}
+
+// checkPrivilegedIntents returns an error if a requested privileged intent is not enabled for the application,
+// which Discord would answer by closing the gateway ("disallowed intents"). A failed lookup is only logged
+func (b *Bot) checkPrivilegedIntents() error {
+	var needed []gatewayIntent
+	for _, i := range b.requiredIntents() {
+		if i.privileged {
+			needed = append(needed, i)
+		}
+	}
+	if len(needed) == 0 {
+		return nil
+	}
+	app, err := b.discord.session.Application("@me")
+	if err != nil {
+		log.Printf("Warning: could not check the privileged intents of the application: %v", err)
+		return nil
+	}
+	for _, i := range needed {
+		if app.Flags&i.appFlags == 0 {
+			return fmt.Errorf("the %s intent (needed to %s) is not enabled: turn it on under Bot → Privileged Gateway Intents in the Discord Developer Portal, or disable the feature", i.name, i.purpose)
+		}
+	}
+	return nil
+}
+
+// discordPermission names a permission bit
+type discordPermission struct {
+	bit  int64
+	name string
+}
+
+// permissionNames are the permissions reported by the audit, in Discord's order
+var permissionNames = []discordPermission{
+	{discordgo.PermissionCreateInstantInvite, "Create Invite"},
+	{discordgo.PermissionKickMembers, "Kick Members"},
+	{discordgo.PermissionBanMembers, "Ban Members"},
+	{discordgo.PermissionAdministrator, "Administrator"},
+	{discordgo.PermissionManageChannels, "Manage Channels"},
+	{discordgo.PermissionManageGuild, "Manage Server"},
+	{discordgo.PermissionAddReactions, "Add Reactions"},
+	{discordgo.PermissionViewAuditLogs, "View Audit Log"},
+	{discordgo.PermissionVoicePrioritySpeaker, "Priority Speaker"},
+	{discordgo.PermissionVoiceStreamVideo, "Video"},
+	{discordgo.PermissionViewChannel, "View Channel"},
+	{discordgo.PermissionSendMessages, "Send Messages"},
+	{discordgo.PermissionSendTTSMessages, "Send TTS Messages"},
+	{discordgo.PermissionManageMessages, "Manage Messages"},
+	{discordgo.PermissionEmbedLinks, "Embed Links"},
+	{discordgo.PermissionAttachFiles, "Attach Files"},
+	{discordgo.PermissionReadMessageHistory, "Read Message History"},
+	{discordgo.PermissionMentionEveryone, "Mention Everyone"},
+	{discordgo.PermissionUseExternalEmojis, "Use External Emojis"},
+	{discordgo.PermissionViewGuildInsights, "View Server Insights"},
+	{discordgo.PermissionVoiceConnect, "Connect"},
+	{discordgo.PermissionVoiceSpeak, "Speak"},
+	{discordgo.PermissionVoiceMuteMembers, "Mute Members"},
+	{discordgo.PermissionVoiceDeafenMembers, "Deafen Members"},
+	{discordgo.PermissionVoiceMoveMembers, "Move Members"},
+	{discordgo.PermissionVoiceUseVAD, "Use Voice Activity"},
+	{discordgo.PermissionChangeNickname, "Change Nickname"},
+	{discordgo.PermissionManageNicknames, "Manage Nicknames"},
+	{discordgo.PermissionManageRoles, "Manage Roles"},
+	{discordgo.PermissionManageWebhooks, "Manage Webhooks"},
+	{discordgo.PermissionManageGuildExpressions, "Manage Expressions"},
+	{discordgo.PermissionUseApplicationCommands, "Use Application Commands"},
+	{discordgo.PermissionVoiceRequestToSpeak, "Request to Speak"},
+	{discordgo.PermissionManageEvents, "Manage Events"},
+	{discordgo.PermissionManageThreads, "Manage Threads"},
+	{discordgo.PermissionCreatePublicThreads, "Create Public Threads"},
+	{discordgo.PermissionCreatePrivateThreads, "Create Private Threads"},
+	{discordgo.PermissionUseExternalStickers, "Use External Stickers"},
+	{discordgo.PermissionSendMessagesInThreads, "Send Messages in Threads"},
+	{discordgo.PermissionUseEmbeddedActivities, "Use Activities"},
+	{discordgo.PermissionModerateMembers, "Timeout Members"},
+	{discordgo.PermissionCreateGuildExpressions, "Create Expressions"},
+	{discordgo.PermissionCreateEvents, "Create Events"},
+	{discordgo.PermissionSendVoiceMessages, "Send Voice Messages"},
+	{discordgo.PermissionSendPolls, "Create Polls"},
+	{discordgo.PermissionUseExternalApps, "Use External Apps"},
+}
+
+// permissionList names the bits of perms; Administrator alone stands for every permission
+func permissionList(perms int64) []string {
+	if perms&discordgo.PermissionAdministrator != 0 {
+		return []string{"Administrator"}
+	}
+	names := []string{}
+	for _, p := range permissionNames {
+		if perms&p.bit != 0 {
+			names = append(names, p.name)
+		}
+	}
+	return names
+}
+
+// usedPermissions returns the permissions the enabled features use in the status channel
+// Scheduled events need Manage Events once config.json has events
+func (b *Bot) usedPermissions(cfg *Config) []channelPermission {
+	used := append([]channelPermission{}, statusChannelPermissions...)
+	if b.banner != nil && b.banner.attach {
+		used = append(used, bannerAttachPermission)
+	}
+	if b.scheduledEvents != nil && cfg != nil && len(cfg.Events) > 0 {
+		used = append(used, channelPermission{discordgo.PermissionManageEvents, "Manage Events", "mirror race events as scheduled events"})
+	}
+	return used
+}
+
+// privilegeReport compares the intents and permissions the bot uses with those it was given
+type privilegeReport struct {
+	intents           []gatewayIntent
+	privilegedGranted []string // privileged intents enabled in the Developer Portal (nil = unknown)
+	channelID         string
+	used              []channelPermission
+	granted           int64
+	err               error // permission lookup failed: granted is unknown
+}
+
+// unusedPermissions returns the granted permissions no enabled feature uses: candidates to remove from the bot's role
+func (r *privilegeReport) unusedPermissions() []string {
+	if r.err != nil {
+		return nil
+	}
+	if r.granted&discordgo.PermissionAdministrator != 0 {
+		return []string{"Administrator"}
+	}
+	var used int64
+	for _, p := range r.used {
+		used |= p.bit
+	}
+	return permissionList(r.granted &^ used)
+}
+
+// unusedPrivilegedIntents returns the privileged intents enabled in the portal that the bot does not request
+func (r *privilegeReport) unusedPrivilegedIntents() []string {
+	var unused []string
+	for _, name := range r.privilegedGranted {
+		if !slices.ContainsFunc(r.intents, func(i gatewayIntent) bool { return i.name == name }) {
+			unused = append(unused, name)
+		}
+	}
+	return unused
+}
+
+// intentNames lists the requested intents for the log ("none" without any)
+func (r *privilegeReport) intentNames() string {
+	names := make([]string, len(r.intents))
+	for i, intent := range r.intents {
+		names[i] = intent.name
+		if intent.privileged {
+			names[i] += " (privileged)"
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// diagnostics renders the report for the diagnostics endpoint
+func (r *privilegeReport) diagnostics() *api.DiagnosticsPrivileges {
+	d := &api.DiagnosticsPrivileges{
+		Intents:                  []api.DiagnosticsIntent{},
+		PrivilegedIntentsGranted: r.privilegedGranted,
+		Channel:                  r.channelID,
+		PermissionsUsed:          []string{},
+		PermissionsUnused:        r.unusedPermissions(),
+	}
+	for _, i := range r.intents {
+		d.Intents = append(d.Intents, api.DiagnosticsIntent{Name: i.name, Privileged: i.privileged, Purpose: i.purpose})
+	}
+	for _, p := range r.used {
+		d.PermissionsUsed = append(d.PermissionsUsed, p.name)
+	}
+	if r.err != nil {
+		d.Error = r.err.Error()
+	} else {
+		d.PermissionsGranted = permissionList(r.granted)
+	}
+	return d
+}
+
+// auditPrivileges logs the intents and status channel permissions in use against those granted and keeps the
+// report for diagnostics; granted and err are the result of the permission lookup in the status channel
+func (b *Bot) auditPrivileges(granted int64, err error) {
+	r := &privilegeReport{
+		intents:   b.requiredIntents(),
+		channelID: b.discord.channel(),
+		used:      b.usedPermissions(b.configManager.GetConfig()),
+		granted:   granted,
+		err:       err,
+	}
+	if app, err := b.discord.session.Application("@me"); err == nil {
+		r.privilegedGranted = []string{}
+		for _, i := range privilegedIntents {
+			if app.Flags&i.appFlags != 0 {
+				r.privilegedGranted = append(r.privilegedGranted, i.name)
+			}
+		}
+	}
+
+	log.Printf("Privilege audit: gateway intents requested: %s", r.intentNames())
+	if unused := r.unusedPrivilegedIntents(); len(unused) > 0 {
+		log.Printf("Privilege audit: privileged intents enabled in the Developer Portal but not used: %s (they can be turned off)", strings.Join(unused, ", "))
+	}
+	if unused := r.unusedPermissions(); len(unused) > 0 {
+		log.Printf("Warning: privilege audit: permissions granted in channel %s but not used: %s (remove them from the bot's role)", r.channelID, strings.Join(unused, ", "))
+	}
+	b.privileges.Store(r)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// TestRequiredIntents tests that no intent is requested by default and text commands add Message Content
+func TestRequiredIntents(t *testing.T) {
+	b := newTestBot(testMaintenanceConfig())
+	if got := b.gatewayIntents(); got != discordgo.IntentsNone {
+		t.Errorf("Expected no intents by default, got %d", got)
+	}
+	b.textCommands = &textCommands{prefix: "!"}
+	if got := b.gatewayIntents(); got != discordgo.IntentGuildMessages|discordgo.IntentMessageContent {
+		t.Errorf("Expected Guild Messages and Message Content with text commands, got %d", got)
+	}
+}
+
+// TestCheckPrivilegedIntents tests that a privileged intent not enabled in the portal stops the start
+func TestCheckPrivilegedIntents(t *testing.T) {
+	f := newFakeDiscord()
+	b := newFakeDiscordBot(t, f, testMaintenanceConfig())
+	if err := b.checkPrivilegedIntents(); err != nil || f.count("Application") != 0 {
+		t.Errorf("Expected no lookup without privileged intents, got %v (%d calls)", err, f.count("Application"))
+	}
+
+	b.textCommands = &textCommands{prefix: "!"}
+	if err := b.checkPrivilegedIntents(); err == nil || !strings.Contains(err.Error(), "Message Content intent") {
+		t.Errorf("Expected the missing Message Content intent, got %v", err)
+	}
+	f.appFlags = appFlagGatewayMessageContentLimited
+	if err := b.checkPrivilegedIntents(); err != nil {
+		t.Errorf("Expected the limited flag to be enough, got %v", err)
+	}
+	f.failNext("Application", errors.New("HTTP 500"))
+	if err := b.checkPrivilegedIntents(); err != nil {
+		t.Errorf("Expected a failed lookup not to stop the start, got %v", err)
+	}
+}
+
+// TestAuditPrivileges tests the report of used against granted permissions and portal intents
+func TestAuditPrivileges(t *testing.T) {
+	f := newFakeDiscord()
+	b := newFakeDiscordBot(t, f, testMaintenanceConfig())
+	var used int64
+	for _, p := range statusChannelPermissions {
+		used |= p.bit
+	}
+	f.perms = used | discordgo.PermissionManageRoles | discordgo.PermissionMentionEveryone
+	f.appFlags = appFlagGatewayGuildMembers
+
+	b.checkPermissions()
+	r := b.privileges.Load()
+	if r == nil {
+		t.Fatal("Expected a privilege report after the permission check")
+	}
+	if got := r.unusedPermissions(); !reflect.DeepEqual(got, []string{"Mention Everyone", "Manage Roles"}) {
+		t.Errorf("Unexpected unused permissions %v", got)
+	}
+	if got := r.unusedPrivilegedIntents(); !reflect.DeepEqual(got, []string{"Server Members"}) {
+		t.Errorf("Unexpected unused privileged intents %v", got)
+	}
+	d := r.diagnostics()
+	if len(d.Intents) != 0 || len(d.PermissionsUsed) != len(statusChannelPermissions) || len(d.PermissionsGranted) != len(statusChannelPermissions)+2 {
+		t.Errorf("Unexpected diagnostics %+v", d)
+	}
+
+	f.perms = discordgo.PermissionAll
+	b.checkPermissions()
+	if got := b.privileges.Load().unusedPermissions(); !reflect.DeepEqual(got, []string{"Administrator"}) {
+		t.Errorf("Expected Administrator reported alone, got %v", got)
+	}
+
+	f.failNext("UserChannelPermissions", errors.New("HTTP 404"))
+	b.checkPermissions()
+	if d := b.privileges.Load().diagnostics(); d.Error == "" || d.PermissionsGranted != nil || d.PermissionsUnused != nil {
+		t.Errorf("Expected unknown granted permissions after a failed lookup, got %+v", d)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bombom/absa-ac/pkg/proxy"
+)
+
+// ================= PROXY ACCOUNT COMMAND =================
+
+// defaultProxyAccountsFile is used when neither -file nor PROXY_ACCOUNTS_FILE is set
+const defaultProxyAccountsFile = "/data/proxy_accounts"
+
+// runProxyAccount implements `bot proxy-account add|remove|list`: manages the proxy's named admin accounts
+// add reads the password from the first line of stdin so it never appears in the process list or shell history
+func runProxyAccount(args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("proxy-account", flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.Usage = func() {
+		fmt.Fprintln(out, "Usage: proxy-account [-file path] add <name> | remove <name> | list")
+		fmt.Fprintln(out, "add reads the password from the first line of stdin.")
+		fs.PrintDefaults()
+	}
+	file := fs.String("file", "", "Accounts file (default PROXY_ACCOUNTS_FILE or "+defaultProxyAccountsFile+")")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil // -h printed the usage
+		}
+		return err
+	}
+	path := *file
+	if path == "" {
+		path = os.Getenv("PROXY_ACCOUNTS_FILE")
+	}
+	if path == "" {
+		path = defaultProxyAccountsFile
+	}
+
+	accounts, err := proxy.LoadAccounts(path)
+	if err != nil {
+		return err
+	}
+
+	cmd, rest := fs.Arg(0), fs.Args()
+	if len(rest) > 0 {
+		rest = rest[1:]
+	}
+	switch {
+	case cmd == "list" && len(rest) == 0:
+		for _, name := range accounts.Names() {
+			fmt.Fprintln(out, name)
+		}
+		return nil
+	case cmd == "add" && len(rest) == 1:
+		password, err := readPasswordLine(in)
+		if err != nil {
+			return err
+		}
+		if err := accounts.Add(rest[0], password); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Saved account %s in %s\n", rest[0], path)
+		return nil
+	case cmd == "remove" && len(rest) == 1:
+		if err := accounts.Remove(rest[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Removed account %s from %s\n", rest[0], path)
+		return nil
+	}
+	fs.Usage()
+	return fmt.Errorf("expected add <name>, remove <name> or list")
+}
+
+// readPasswordLine reads one line from in, without the line ending
+func readPasswordLine(in io.Reader) (string, error) {
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", fmt.Errorf("no password on stdin (e.g. read -rs PW && echo \"$PW\" | proxy-account add <name>)")
+	}
+	return line, nil
+}
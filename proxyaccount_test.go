@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bombom/absa-ac/pkg/proxy"
+)
+
+// TestRunProxyAccount tests adding, listing and removing accounts through the CLI
+func TestRunProxyAccount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxy_accounts")
+	run := func(stdin string, args ...string) (string, error) {
+		var out bytes.Buffer
+		err := runProxyAccount(append([]string{"-file", path}, args...), strings.NewReader(stdin), &out)
+		return out.String(), err
+	}
+
+	if _, err := run("alicepass1\n", "add", "alice"); err != nil {
+		t.Fatalf("add alice failed: %v", err)
+	}
+	if _, err := run("bobpass12", "add", "bob"); err != nil {
+		t.Fatalf("add bob without trailing newline failed: %v", err)
+	}
+	if out, err := run("", "list"); err != nil || out != "alice\nbob\n" {
+		t.Errorf("Expected both accounts listed, got %q (%v)", out, err)
+	}
+
+	accounts, err := proxy.LoadAccounts(path)
+	if err != nil || !accounts.Verify("alice", "alicepass1") {
+		t.Errorf("Expected alice to verify with the stdin password (%v)", err)
+	}
+
+	if _, err := run("", "remove", "bob"); err != nil {
+		t.Fatalf("remove bob failed: %v", err)
+	}
+	if out, _ := run("", "list"); out != "alice\n" {
+		t.Errorf("Expected only alice after remove, got %q", out)
+	}
+}
+
+// TestRunProxyAccount_Errors tests rejected invocations
+func TestRunProxyAccount_Errors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxy_accounts")
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+	}{
+		{"no command", "", nil},
+		{"add without name", "password123\n", []string{"add"}},
+		{"add without password", "", []string{"add", "alice"}},
+		{"short password", "short\n", []string{"add", "alice"}},
+		{"remove unknown", "", []string{"remove", "nobody"}},
+		{"unknown command", "", []string{"rename", "alice"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := runProxyAccount(append([]string{"-file", path}, tt.args...), strings.NewReader(tt.stdin), &out); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
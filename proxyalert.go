@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= PROXY LOGIN ALERTS =================
+
+// proxyLoginNotifierFromEnv returns a notifier posting proxy login alerts to PROXY_ALERT_CHANNEL_ID, nil if unset
+// Alerts come from the proxy audit log (PROXY_AUDIT_LOG) and are sent through the bot session, so webhook mode
+// is not supported. Each replica alerts about its own proxy, leader or not
+func proxyLoginNotifierFromEnv(b *Bot, auditEnabled bool) (func(msg string), error) {
+	channelID := os.Getenv("PROXY_ALERT_CHANNEL_ID")
+	if channelID == "" {
+		return nil, nil
+	}
+	if !auditEnabled {
+		return nil, fmt.Errorf("PROXY_ALERT_CHANNEL_ID requires PROXY_ENABLED=true and PROXY_AUDIT_LOG")
+	}
+	if b.discord == nil {
+		return nil, fmt.Errorf("PROXY_ALERT_CHANNEL_ID requires bot mode (DISCORD_TOKEN), not a webhook")
+	}
+	log.Printf("Proxy login alerts enabled in channel %s", channelID)
+	return func(msg string) {
+		// Called on the proxy request path; never hold up the login
+		// Alerts quote client-supplied names and user agents, so they never ping anyone
+		go func() {
+			if _, err := b.discord.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+				Content:         msg,
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			}); err != nil {
+				log.Printf("Failed to send proxy login alert: %v", err)
+			}
+		}()
+	}, nil
+}
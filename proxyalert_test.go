@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestProxyLoginNotifierFromEnv tests PROXY_ALERT_CHANNEL_ID requirements
+func TestProxyLoginNotifierFromEnv(t *testing.T) {
+	b := newTestBot(testStatusConfig())
+
+	t.Setenv("PROXY_ALERT_CHANNEL_ID", "")
+	if notify, err := proxyLoginNotifierFromEnv(b, true); notify != nil || err != nil {
+		t.Error("Expected alerts disabled when unset")
+	}
+
+	t.Setenv("PROXY_ALERT_CHANNEL_ID", "999")
+	if _, err := proxyLoginNotifierFromEnv(b, false); err == nil {
+		t.Error("Expected error without the audit log")
+	}
+	if _, err := proxyLoginNotifierFromEnv(b, true); err == nil {
+		t.Error("Expected error in webhook mode")
+	}
+	f := newFakeDiscord()
+	b.discord = NewDiscordPublisher(f, fakeChannelID)
+	notify, err := proxyLoginNotifierFromEnv(b, true)
+	if notify == nil || err != nil {
+		t.Fatalf("Expected notifier in bot mode, got %v", err)
+	}
+	notify("login alert")
+	waitFor(t, "the alert", func() bool { return f.count("ChannelMessageSendComplex") == 1 })
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ================= PTERODACTYL =================
+
+// pterodactylTimeout bounds one panel request
+const pterodactylTimeout = 10 * time.Second
+
+// pterodactylServerIDPattern matches a panel server identifier (8 characters) or UUID
+var pterodactylServerIDPattern = regexp.MustCompile(`^[0-9a-f]{8}(-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})?$`)
+
+// PterodactylServer links a server to its Pterodactyl panel (pterodactyl in config.json, keyed by server name)
+type PterodactylServer struct {
+	PanelURL string `json:"panel_url"` // https://panel.example.com
+	ServerID string `json:"server_id"` // identifier from the panel's server URL (8 characters) or UUID
+	APIKey   string `json:"api_key"`   // client API key (ptlc_...) of an account allowed to control the server
+}
+
+// validatePterodactyl checks the pterodactyl section; a server is controlled by Docker or the panel, not both
+func validatePterodactyl(servers map[string]PterodactylServer, cfg *Config) error {
+	serverNames := make(map[string]bool, len(cfg.Servers))
+	for _, s := range cfg.Servers {
+		serverNames[s.Name] = true
+	}
+	for _, server := range slices.Sorted(maps.Keys(servers)) {
+		p := servers[server]
+		if !serverNames[server] {
+			return fmt.Errorf("pterodactyl for server '%s' which is not defined in servers", server)
+		}
+		if _, ok := cfg.Containers[server]; ok {
+			return fmt.Errorf("server '%s' has both a container and a pterodactyl panel; pick one", server)
+		}
+		u, err := url.Parse(p.PanelURL)
+		if err != nil || u.Host == "" || (u.Scheme != "https" && !(u.Scheme == "http" && isLoopbackHost(u.Hostname()))) {
+			return fmt.Errorf("pterodactyl of server '%s': panel_url must be an https:// URL (http is allowed on localhost only)", server)
+		}
+		if !pterodactylServerIDPattern.MatchString(p.ServerID) {
+			return fmt.Errorf("pterodactyl of server '%s': server_id must be the 8-character identifier or UUID from the panel", server)
+		}
+		if p.APIKey == "" {
+			return fmt.Errorf("pterodactyl of server '%s': api_key is required (a client API key from the panel's account page)", server)
+		}
+	}
+	return nil
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback address
+func isLoopbackHost(host string) bool {
+	ip := net.ParseIP(host)
+	return host == "localhost" || (ip != nil && ip.IsLoopback())
+}
+
+// pterodactylBackend reads power states and sends power signals through the Pterodactyl client API
+// A "running" panel state also stands in for failed polls (pollFallback)
+type pterodactylBackend struct {
+	client *http.Client
+}
+
+// pterodactylBackendFromEnv returns the backend if PTERODACTYL_ENABLED is true, nil otherwise
+func pterodactylBackendFromEnv() *pterodactylBackend {
+	if os.Getenv("PTERODACTYL_ENABLED") != "true" {
+		return nil
+	}
+	log.Printf("Pterodactyl integration enabled")
+	return newPterodactylBackend()
+}
+
+func newPterodactylBackend() *pterodactylBackend {
+	return &pterodactylBackend{client: &http.Client{Timeout: pterodactylTimeout}}
+}
+
+// name implements powerBackend
+func (p *pterodactylBackend) name() string {
+	return "pterodactyl"
+}
+
+// label implements powerBackend
+func (p *pterodactylBackend) label() string {
+	return "Panel"
+}
+
+// target implements powerBackend: the panel server identifier
+func (p *pterodactylBackend) target(cfg *Config, server string) string {
+	return cfg.Pterodactyl[server].ServerID
+}
+
+// fallbackForPolls implements pollFallback
+func (p *pterodactylBackend) fallbackForPolls() {}
+
+// state implements powerBackend: the panel's current_state (running, starting, stopping, offline)
+func (p *pterodactylBackend) state(ctx context.Context, cfg *Config, server string) (string, error) {
+	var res struct {
+		Attributes struct {
+			CurrentState string `json:"current_state"`
+			IsSuspended  bool   `json:"is_suspended"`
+		} `json:"attributes"`
+	}
+	resp, err := p.do(ctx, cfg.Pterodactyl[server], http.MethodGet, "/resources", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", fmt.Errorf("failed to decode panel resources: %w", err)
+	}
+	if res.Attributes.IsSuspended {
+		return powerSuspended, nil
+	}
+	switch res.Attributes.CurrentState {
+	case "running":
+		return powerRunning, nil
+	case "starting":
+		return powerStarting, nil
+	case "stopping":
+		return powerStopping, nil
+	case "offline":
+		return powerStopped, nil
+	}
+	return res.Attributes.CurrentState, nil
+}
+
+// act implements powerBackend: sends the start, stop or restart signal (the panel runs it asynchronously)
+func (p *pterodactylBackend) act(ctx context.Context, cfg *Config, server, action string) error {
+	body, _ := json.Marshal(map[string]string{"signal": action})
+	resp, err := p.do(ctx, cfg.Pterodactyl[server], http.MethodPost, "/power", body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a request to the client API of the server's panel
+func (p *pterodactylBackend) do(ctx context.Context, ps PterodactylServer, method, path string, body []byte) (*http.Response, error) {
+	endpoint := strings.TrimSuffix(ps.PanelURL, "/") + "/api/client/servers/" + url.PathEscape(ps.ServerID) + path
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+ps.APIKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("panel request failed: %w", err)
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var errBody struct {
+		Errors []struct {
+			Detail string `json:"detail"`
+		} `json:"errors"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&errBody)
+	detail := http.StatusText(resp.StatusCode)
+	if len(errBody.Errors) > 0 && errBody.Errors[0].Detail != "" {
+		detail = errBody.Errors[0].Detail
+	}
+	return nil, fmt.Errorf("panel returned status %d: %s", resp.StatusCode, detail)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakePanel serves the Pterodactyl client API endpoints the backend uses for server 1a2b3c4d
+type fakePanel struct {
+	mu        sync.Mutex
+	state     string // current_state
+	suspended bool
+	signals   []string
+}
+
+func (f *fakePanel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer ptlc_test" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errors":[{"code":"AuthenticationException","status":"401","detail":"Unauthenticated."}]}`))
+		return
+	}
+	switch r.Method + " " + r.URL.Path {
+	case "GET /api/client/servers/1a2b3c4d/resources":
+		json.NewEncoder(w).Encode(map[string]any{"object": "stats", "attributes": map[string]any{
+			"current_state": f.state, "is_suspended": f.suspended,
+		}})
+	case "POST /api/client/servers/1a2b3c4d/power":
+		var body struct {
+			Signal string `json:"signal"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		f.signals = append(f.signals, body.Signal)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[{"code":"NotFoundHttpException","status":"404","detail":"The requested resource could not be found on the server."}]}`))
+	}
+}
+
+// testPanelConfig links "Track #1" to the panel at url
+func testPanelConfig(url string) *Config {
+	cfg := testMaintenanceConfig()
+	cfg.Pterodactyl = map[string]PterodactylServer{"Track #1": {PanelURL: url + "/", ServerID: "1a2b3c4d", APIKey: "ptlc_test"}}
+	return cfg
+}
+
+// TestPterodactylBackendState tests the mapping of panel states, suspension and API errors
+func TestPterodactylBackendState(t *testing.T) {
+	panel := &fakePanel{}
+	srv := httptest.NewServer(panel)
+	defer srv.Close()
+	cfg := testPanelConfig(srv.URL)
+	p := newPterodactylBackend()
+	ctx := context.Background()
+
+	want := map[string]string{"running": powerRunning, "starting": powerStarting, "stopping": powerStopping, "offline": powerStopped}
+	for state, wantState := range want {
+		panel.state = state
+		if got, err := p.state(ctx, cfg, "Track #1"); err != nil || got != wantState {
+			t.Errorf("state(%s) = %q, %v; want %q", state, got, err, wantState)
+		}
+	}
+	panel.suspended = true
+	if got, _ := p.state(ctx, cfg, "Track #1"); got != powerSuspended {
+		t.Errorf("Expected suspended, got %q", got)
+	}
+
+	ps := cfg.Pterodactyl["Track #1"]
+	ps.APIKey = "ptlc_wrong"
+	cfg.Pterodactyl["Track #1"] = ps
+	if _, err := p.state(ctx, cfg, "Track #1"); err == nil || !strings.Contains(err.Error(), "Unauthenticated.") {
+		t.Errorf("Expected the panel's error detail, got %v", err)
+	}
+}
+
+// TestPterodactylBackendAct tests that actions are sent as power signals
+func TestPterodactylBackendAct(t *testing.T) {
+	panel := &fakePanel{state: "running"}
+	srv := httptest.NewServer(panel)
+	defer srv.Close()
+	cfg := testPanelConfig(srv.URL)
+	p := newPterodactylBackend()
+
+	for _, action := range []string{powerStop, powerStart, powerRestart} {
+		if err := p.act(context.Background(), cfg, "Track #1", action); err != nil {
+			t.Fatalf("act(%s): %v", action, err)
+		}
+	}
+	if got := strings.Join(panel.signals, ","); got != "stop,start,restart" {
+		t.Errorf("Signals = %s, want stop,start,restart", got)
+	}
+
+	ps := cfg.Pterodactyl["Track #1"]
+	ps.ServerID = "9f9f9f9f"
+	cfg.Pterodactyl["Track #1"] = ps
+	if err := p.act(context.Background(), cfg, "Track #1", powerStart); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected an error for an unknown panel server, got %v", err)
+	}
+}
+
+// TestPterodactylPollFallback tests that a running panel server whose poll failed is shown online
+func TestPterodactylPollFallback(t *testing.T) {
+	panel := &fakePanel{state: "running"}
+	srv := httptest.NewServer(panel)
+	defer srv.Close()
+	cfg := testPanelConfig(srv.URL)
+	b := newTestBot(cfg)
+	b.power = newPowerControl(newPterodactylBackend())
+
+	offline := func() []ServerInfo {
+		return []ServerInfo{
+			{Name: "Drift #1", Category: "Drift", Map: "Offline", Players: "0/0", NumPlayers: -1},
+			{Name: "Track #1", Category: "Track", Map: "Offline", Players: "0/0", NumPlayers: -1},
+		}
+	}
+	infos := b.trackPower(offline(), cfg)
+	if infos[0].NumPlayers != -1 || infos[0].Power != nil {
+		t.Errorf("Expected the unmanaged server to stay offline, got %+v", infos[0])
+	}
+	track := infos[1]
+	if track.NumPlayers != 0 || track.Map != "Unknown" || track.Power == nil || *track.Power != (serverPower{label: "Panel", state: "running (not answering queries)"}) {
+		t.Errorf("Expected the panel fallback, got %+v (power %+v)", track, track.Power)
+	}
+	if embed := buildEmbed(infos, cfg); !strings.Contains(embed.Fields[len(embed.Fields)-2].Value, "**Panel:** running (not answering queries)") {
+		t.Errorf("Expected the panel state in the embed, got %+v", embed.Fields)
+	}
+
+	panel.state = "offline"
+	infos = b.trackPower(offline(), cfg)
+	if infos[1].NumPlayers != -1 || infos[1].Power == nil || infos[1].Power.state != powerStopped {
+		t.Errorf("Expected a stopped panel server to stay offline, got %+v (power %+v)", infos[1], infos[1].Power)
+	}
+
+	if _, err := b.power.start(cfg, "Track #1", powerStart, "tester"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	b.power.running.Wait()
+	if got := strings.Join(panel.signals, ","); got != "start" {
+		t.Errorf("Signals = %s, want start", got)
+	}
+}
+
+// TestValidatePterodactyl tests server names, panel URLs, identifiers, keys and the container overlap
+func TestValidatePterodactyl(t *testing.T) {
+	valid := PterodactylServer{PanelURL: "https://panel.example.com", ServerID: "1a2b3c4d", APIKey: "ptlc_test"}
+	cfg := testMaintenanceConfig()
+	if err := validatePterodactyl(map[string]PterodactylServer{"Track #1": valid}, cfg); err != nil {
+		t.Errorf("Expected valid panel, got %v", err)
+	}
+	uuid := valid
+	uuid.ServerID = "1a2b3c4d-0000-4000-8000-123456789abc"
+	if err := validatePterodactyl(map[string]PterodactylServer{"Track #1": uuid}, cfg); err != nil {
+		t.Errorf("Expected a UUID to be valid, got %v", err)
+	}
+	local := valid
+	local.PanelURL = "http://127.0.0.1:8080"
+	if err := validatePterodactyl(map[string]PterodactylServer{"Track #1": local}, cfg); err != nil {
+		t.Errorf("Expected http on loopback to be valid, got %v", err)
+	}
+
+	invalid := map[string]func(p *PterodactylServer){
+		"http panel":  func(p *PterodactylServer) { p.PanelURL = "http://panel.example.com" },
+		"no host":     func(p *PterodactylServer) { p.PanelURL = "https://" },
+		"server path": func(p *PterodactylServer) { p.ServerID = "../../admin" },
+		"no key":      func(p *PterodactylServer) { p.APIKey = "" },
+	}
+	for name, mutate := range invalid {
+		p := valid
+		mutate(&p)
+		if err := validatePterodactyl(map[string]PterodactylServer{"Track #1": p}, cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if err := validatePterodactyl(map[string]PterodactylServer{"Rally #1": valid}, cfg); err == nil {
+		t.Error("Expected an error for an unknown server")
+	}
+	cfg.Containers = map[string]string{"Track #1": "ac-track"}
+	if err := validatePterodactyl(map[string]PterodactylServer{"Track #1": valid}, cfg); err == nil {
+		t.Error("Expected an error for a server with both a container and a panel")
+	}
+}
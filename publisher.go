@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/bombom/absa-ac/pkg/supervisor"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= PUBLISHERS =================
+
+// StatusUpdate is one poll result in every rendered form a publisher may need
+// Publishers must treat it as read-only: the same update is delivered to all of them concurrently
+type StatusUpdate struct {
+	// Snapshot is the platform-neutral poll result (Slack, Matrix, ...)
+	Snapshot *StatusSnapshot
+	// Embed is the Discord rendering (bot and webhook publishers)
+	Embed *discordgo.MessageEmbed
+	// HeaderColors color the continuation pages by the category header at their top (nil = embed color)
+	HeaderColors map[string]int
+	// Banner is the PNG to attach as the embed image (nil = no attachment)
+	Banner []byte
+	// DropAttachments removes a previously attached banner when Banner is nil (offline notice)
+	DropAttachments bool
+	// Components are the buttons under the last page (nil = leave them as they are, empty = remove them)
+	// Only the bot publisher shows them: webhook messages cannot have interactive components
+	Components []discordgo.MessageComponent
+}
+
+// Publisher is a target that shows the live status message and receives alerts
+// Implementations keep track of their own status message (ID persisted where the platform requires it)
+type Publisher interface {
+	// Name identifies the publisher in logs
+	Name() string
+	// UpdateStatus creates the status message, or edits it if it already exists
+	UpdateStatus(u *StatusUpdate) error
+	// DeleteStatus removes the status message (no-op if none was posted)
+	DeleteStatus() error
+	// SendAlert posts a standalone message next to the status message
+	SendAlert(msg string) error
+}
+
+// eachPublisher runs fn for every publisher concurrently and waits for all of them
+// One slow or failing target never delays or blocks the others; panics are recovered per publisher
+func (b *Bot) eachPublisher(action string, fn func(Publisher) error) {
+	var wg sync.WaitGroup
+	for _, p := range b.publishers {
+		wg.Add(1)
+		go func(p Publisher) {
+			defer wg.Done()
+			defer supervisor.Recover(p.Name()+" publisher", log.Default())
+			if err := fn(p); err != nil {
+				log.Printf("Error %s (%s): %v", action, p.Name(), err)
+			}
+		}(p)
+	}
+	wg.Wait()
+}
+
+// publishStatus delivers the update to every publisher (skipped on a standby replica)
+func (b *Bot) publishStatus(u *StatusUpdate) {
+	if !b.isLeader() {
+		return
+	}
+	b.eachPublisher("updating status", func(p Publisher) error {
+		return p.UpdateStatus(u)
+	})
+}
+
+// SendAlert posts msg through every publisher (skipped on a standby replica)
+func (b *Bot) SendAlert(msg string) {
+	if !b.isLeader() {
+		return
+	}
+	b.eachPublisher("sending alert", func(p Publisher) error {
+		return p.SendAlert(msg)
+	})
+}
+
+// bannerFiles wraps the banner as a Discord attachment (nil if there is no banner)
+// Each call returns a fresh reader so concurrent publishers do not share read offsets
+func bannerFiles(banner []byte) []*discordgo.File {
+	if banner == nil {
+		return nil
+	}
+	return []*discordgo.File{{Name: bannerAttachmentName, ContentType: "image/png", Reader: bytes.NewReader(banner)}}
+}
+
+// ================= DISCORD (BOT SESSION) =================
+
+// DiscordSession is the subset of the Discord bot session used for REST calls
+// Production wraps *discordgo.Session (gatewaySession); tests use an in-memory fake, so the update loop,
+// reconnects (Ready) and cleanup can run end to end without Discord
+type DiscordSession interface {
+	ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageDelete(channelID, messageID string, options ...discordgo.RequestOption) error
+	ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error)
+	Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	GuildChannels(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Channel, error)
+	UserChannelPermissions(userID, channelID string, fetchOptions ...discordgo.RequestOption) (int64, error)
+	GuildScheduledEventCreate(guildID string, event *discordgo.GuildScheduledEventParams, options ...discordgo.RequestOption) (*discordgo.GuildScheduledEvent, error)
+	GuildScheduledEventEdit(guildID, eventID string, event *discordgo.GuildScheduledEventParams, options ...discordgo.RequestOption) (*discordgo.GuildScheduledEvent, error)
+	GuildScheduledEventDelete(guildID, eventID string, options ...discordgo.RequestOption) error
+	// Application returns the bot's application ("@me"), whose flags show the privileged intents enabled for it
+	Application(appID string) (*discordgo.Application, error)
+	// BotUser returns the logged-in bot user (nil before the first Ready)
+	BotUser() *discordgo.User
+}
+
+// gatewaySession adapts *discordgo.Session to DiscordSession
+type gatewaySession struct {
+	*discordgo.Session
+}
+
+// BotUser implements DiscordSession from the gateway state
+func (g gatewaySession) BotUser() *discordgo.User {
+	if g.State == nil {
+		return nil
+	}
+	return g.State.User
+}
+
+// DiscordPublisher manages the status message in a channel through the bot session
+type DiscordPublisher struct {
+	session DiscordSession
+
+	mu        sync.RWMutex
+	channelID string
+	// statusIDs is the posted status group: one message per embed page, in page order
+	statusIDs []string
+
+	// channelName, guildID and parentID identify the channel if it is deleted and recreated (see rememberChannel)
+	channelName string
+	guildID     string
+	parentID    string
+
+	// cleanupChannels lists channels where old status messages are deleted at startup ("*" = all)
+	// Elsewhere the newest status message is adopted and edited instead
+	cleanupChannels map[string]bool
+}
+
+// NewDiscordPublisher creates a publisher posting to channelID
+func NewDiscordPublisher(session DiscordSession, channelID string) *DiscordPublisher {
+	return &DiscordPublisher{session: session, channelID: channelID}
+}
+
+// Name implements Publisher
+func (d *DiscordPublisher) Name() string {
+	return "Discord"
+}
+
+// channel returns the current status channel ID (changes if the channel is re-resolved)
+func (d *DiscordPublisher) channel() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.channelID
+}
+
+// guild returns the status channel's guild ("" before rememberChannel)
+func (d *DiscordPublisher) guild() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.guildID
+}
+
+// statusMessages returns the IDs of the posted status group in page order (nil before the first post)
+func (d *DiscordPublisher) statusMessages() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.statusIDs
+}
+
+func (d *DiscordPublisher) setStatusMessages(ids []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statusIDs = ids
+}
+
+// UpdateStatus implements Publisher: posts or edits the status message group (one message per embed page)
+// If a banner is present it is uploaded as an attachment of the first page, replacing the previous one
+func (d *DiscordPublisher) UpdateStatus(u *StatusUpdate) error {
+	existing := d.statusMessages()
+	pages := paginateEmbed(u.Embed, u.HeaderColors)
+	for i, page := range pages {
+		pages[i] = markStatusEmbed(page)
+	}
+
+	ids, err := syncStatusPages(existing, pages, bannerFiles(u.Banner), statusPageOps{
+		send: func(embed *discordgo.MessageEmbed, files []*discordgo.File) (string, error) {
+			var components []discordgo.MessageComponent
+			if embed == pages[len(pages)-1] {
+				components = u.Components
+			}
+			msg, err := d.sendStatusMessage(embed, files, components)
+			if err != nil {
+				return "", err
+			}
+			return msg.ID, nil
+		},
+		edit: func(id string, embed *discordgo.MessageEmbed, files []*discordgo.File, first bool) error {
+			edit := &discordgo.MessageEdit{
+				ID:      id,
+				Channel: d.channel(),
+				Embed:   embed,
+			}
+			if u.Components != nil {
+				// Buttons only on the last page; a page that used to be last loses them
+				components := []discordgo.MessageComponent{}
+				if embed == pages[len(pages)-1] {
+					components = u.Components
+				}
+				edit.Components = &components
+			}
+			if files != nil || (first && u.DropAttachments) {
+				// Replace the previous banner instead of accumulating attachments
+				edit.Files = files
+				edit.Attachments = &[]*discordgo.MessageAttachment{}
+			}
+			_, err := d.session.ChannelMessageEditComplex(edit)
+			return err
+		},
+		delete: func(id string) error {
+			return d.session.ChannelMessageDelete(d.channel(), id)
+		},
+	})
+	d.setStatusMessages(ids)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(existing) == 0:
+		log.Printf("Initial status message posted (%d pages)", len(ids))
+	case len(ids) == 1:
+		log.Println("Status message updated")
+	default:
+		log.Printf("Status message updated (%d pages)", len(ids))
+	}
+	return nil
+}
+
+// sendStatusMessage posts a new status message, with attachments and buttons if any
+func (d *DiscordPublisher) sendStatusMessage(embed *discordgo.MessageEmbed, files []*discordgo.File, components []discordgo.MessageComponent) (*discordgo.Message, error) {
+	if len(files) == 0 && len(components) == 0 {
+		return d.session.ChannelMessageSendEmbed(d.channel(), embed)
+	}
+	return d.session.ChannelMessageSendComplex(d.channel(), &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Files:      files,
+		Components: components,
+	})
+}
+
+// DeleteStatus implements Publisher: deletes every message of the status group
+func (d *DiscordPublisher) DeleteStatus() error {
+	existing := d.statusMessages()
+	for i, id := range existing {
+		if err := d.session.ChannelMessageDelete(d.channel(), id); err != nil && !isNotFound(err) {
+			d.setStatusMessages(existing[i:])
+			return fmt.Errorf("failed to delete message: %w", err)
+		}
+	}
+	d.setStatusMessages(nil)
+	return nil
+}
+
+// SendAlert implements Publisher
+func (d *DiscordPublisher) SendAlert(msg string) error {
+	if _, err := d.session.ChannelMessageSend(d.channel(), msg); err != nil {
+		return fmt.Errorf("failed to send alert: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePublisher records calls; panics or fails on demand
+type fakePublisher struct {
+	name    string
+	delay   time.Duration
+	err     error
+	panics  bool
+	mu      sync.Mutex
+	updates []*StatusUpdate
+	alerts  []string
+	deletes int
+}
+
+func (f *fakePublisher) Name() string { return f.name }
+
+func (f *fakePublisher) UpdateStatus(u *StatusUpdate) error {
+	if f.panics {
+		panic("publisher exploded")
+	}
+	time.Sleep(f.delay)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, u)
+	return f.err
+}
+
+func (f *fakePublisher) DeleteStatus() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deletes++
+	return f.err
+}
+
+func (f *fakePublisher) SendAlert(msg string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.alerts = append(f.alerts, msg)
+	return f.err
+}
+
+// TestPublishStatus_AllPublishersReceiveUpdate tests fan-out of one update to every target
+func TestPublishStatus_AllPublishersReceiveUpdate(t *testing.T) {
+	a := &fakePublisher{name: "a"}
+	failing := &fakePublisher{name: "failing", err: errors.New("boom")}
+	c := &fakePublisher{name: "c"}
+	b := newTestBot(nil)
+	b.publishers = []Publisher{a, failing, c}
+
+	u := &StatusUpdate{Snapshot: &StatusSnapshot{TotalPlayers: 3}}
+	b.publishStatus(u)
+
+	for _, p := range []*fakePublisher{a, failing, c} {
+		if len(p.updates) != 1 || p.updates[0] != u {
+			t.Errorf("Publisher %s: expected the shared update once, got %d", p.name, len(p.updates))
+		}
+	}
+}
+
+// TestPublishStatus_Concurrent tests that publishers run in parallel rather than one after another
+func TestPublishStatus_Concurrent(t *testing.T) {
+	b := newTestBot(nil)
+	for i := 0; i < 4; i++ {
+		b.publishers = append(b.publishers, &fakePublisher{name: "slow", delay: 100 * time.Millisecond})
+	}
+
+	start := time.Now()
+	b.publishStatus(&StatusUpdate{})
+	if took := time.Since(start); took > 300*time.Millisecond {
+		t.Errorf("Expected concurrent publishing (~100ms), took %v", took)
+	}
+}
+
+// TestPublishStatus_PanicIsolated tests that a panicking publisher does not affect the others
+func TestPublishStatus_PanicIsolated(t *testing.T) {
+	ok := &fakePublisher{name: "ok"}
+	b := newTestBot(nil)
+	b.publishers = []Publisher{&fakePublisher{name: "panics", panics: true}, ok}
+
+	b.publishStatus(&StatusUpdate{})
+
+	if len(ok.updates) != 1 {
+		t.Errorf("Expected healthy publisher to be updated, got %d updates", len(ok.updates))
+	}
+}
+
+// TestSendAlert_Broadcast tests that alerts go to every publisher
+func TestSendAlert_Broadcast(t *testing.T) {
+	a := &fakePublisher{name: "a"}
+	c := &fakePublisher{name: "c"}
+	b := newTestBot(nil)
+	b.publishers = []Publisher{a, c}
+
+	b.SendAlert("Server down")
+
+	for _, p := range []*fakePublisher{a, c} {
+		if len(p.alerts) != 1 || p.alerts[0] != "Server down" {
+			t.Errorf("Publisher %s: expected alert, got %v", p.name, p.alerts)
+		}
+	}
+}
+
+// TestBannerFiles tests that each call returns an independent reader
+func TestBannerFiles(t *testing.T) {
+	if bannerFiles(nil) != nil {
+		t.Error("Expected no files without a banner")
+	}
+	first := bannerFiles([]byte("png"))
+	second := bannerFiles([]byte("png"))
+	if len(first) != 1 || first[0].Name != bannerAttachmentName || first[0].ContentType != "image/png" {
+		t.Fatalf("Unexpected attachment %+v", first)
+	}
+	if first[0].Reader == second[0].Reader {
+		t.Error("Expected separate readers per call")
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/supervisor"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= RACE EVENTS =================
+
+const (
+	// defaultRaceEventDuration is the event window when an event has no duration
+	defaultRaceEventDuration = 2 * time.Hour
+	// scheduledEventsStateFile maps config events to the Discord scheduled events created for them, next to config.json
+	scheduledEventsStateFile = "scheduled_events.json"
+	// Discord limits for scheduled events
+	scheduledEventNameMax        = 100
+	scheduledEventDescriptionMax = 1000
+	scheduledEventLocationMax    = 100
+)
+
+// RaceEvent is a scheduled race on one server (events in config.json)
+// In bot mode each upcoming event is mirrored as a Discord Scheduled Event, and the server is highlighted
+// in the status embed while the event runs
+type RaceEvent struct {
+	Name        string `json:"name"`                  // unique, identifies the Discord event across edits
+	Server      string `json:"server"`                // name of a server in servers[]
+	Start       string `json:"start"`                 // RFC 3339, e.g. 2026-10-18T18:00:00Z
+	Duration    string `json:"duration,omitempty"`    // event window, default 2h
+	Description string `json:"description,omitempty"` // shown in the Discord event
+}
+
+// window returns the event's start and end (config already validated)
+func (e RaceEvent) window() (time.Time, time.Time) {
+	start, _ := time.Parse(time.RFC3339, e.Start)
+	d := defaultRaceEventDuration
+	if e.Duration != "" {
+		d, _ = time.ParseDuration(e.Duration)
+	}
+	return start, start.Add(d)
+}
+
+// validateRaceEvents checks the events section against the configured servers
+func validateRaceEvents(events []RaceEvent, servers []Server) error {
+	serverNames := make(map[string]bool, len(servers))
+	for _, s := range servers {
+		serverNames[s.Name] = true
+	}
+	seen := make(map[string]bool, len(events))
+	for i, e := range events {
+		if e.Name == "" {
+			return fmt.Errorf("events[%d]: name cannot be empty", i)
+		}
+		if len([]rune(e.Name)) > scheduledEventNameMax {
+			return fmt.Errorf("event '%s': name is longer than %d characters", e.Name, scheduledEventNameMax)
+		}
+		if seen[e.Name] {
+			return fmt.Errorf("event '%s' is defined more than once", e.Name)
+		}
+		seen[e.Name] = true
+		if !serverNames[e.Server] {
+			return fmt.Errorf("event '%s' has server '%s' which is not defined in servers", e.Name, e.Server)
+		}
+		if _, err := time.Parse(time.RFC3339, e.Start); err != nil {
+			return fmt.Errorf("event '%s': start %q must be an RFC 3339 time like 2026-10-18T18:00:00Z", e.Name, e.Start)
+		}
+		if e.Duration != "" {
+			if d, err := time.ParseDuration(e.Duration); err != nil || d <= 0 {
+				return fmt.Errorf("event '%s': duration %q must be a positive duration like 2h", e.Name, e.Duration)
+			}
+		}
+	}
+	return nil
+}
+
+// activeRaceEvent returns the event running on server at now (nil if none; the earliest start wins)
+func activeRaceEvent(events []RaceEvent, server string, now time.Time) *RaceEvent {
+	var active *RaceEvent
+	var activeStart time.Time
+	for i := range events {
+		e := &events[i]
+		if e.Server != server {
+			continue
+		}
+		start, end := e.window()
+		if now.Before(start) || !now.Before(end) {
+			continue
+		}
+		if active == nil || start.Before(activeStart) {
+			active, activeStart = e, start
+		}
+	}
+	return active
+}
+
+// markRaceEvents highlights poll results of servers hosting an event at now
+func markRaceEvents(infos []ServerInfo, cfg *Config, now time.Time) []ServerInfo {
+	if len(cfg.Events) == 0 {
+		return infos
+	}
+	for i := range infos {
+		if e := activeRaceEvent(cfg.Events, infos[i].Name, now); e != nil {
+			_, end := e.window()
+			infos[i].Event = &activeEvent{name: e.Name, until: end}
+		}
+	}
+	return infos
+}
+
+// activeEvent is the race event running on a server
+type activeEvent struct {
+	name  string
+	until time.Time
+}
+
+// scheduledEvent is a Discord scheduled event created for a config event
+type scheduledEvent struct {
+	ID string `json:"id"`
+	// Fingerprint is a hash of the fields sent to Discord, so unchanged events are not edited again
+	Fingerprint string `json:"fingerprint"`
+}
+
+// scheduledEventSync mirrors config events as Discord scheduled events in the status channel's guild
+// The created event IDs are kept in statePath, so restarts edit the existing events instead of duplicating them
+type scheduledEventSync struct {
+	statePath string
+
+	mu     sync.Mutex
+	loaded bool
+	events map[string]scheduledEvent // by config event name
+}
+
+func newScheduledEventSync(statePath string) *scheduledEventSync {
+	return &scheduledEventSync{statePath: statePath, events: make(map[string]scheduledEvent)}
+}
+
+// load reads the state file once (missing file = no events created yet)
+func (s *scheduledEventSync) load() {
+	if s.loaded || s.statePath == "" {
+		return
+	}
+	s.loaded = true
+	data, err := os.ReadFile(s.statePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Warning: failed to read scheduled events state %s: %v", s.statePath, err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &s.events); err != nil {
+		log.Printf("Warning: ignoring invalid scheduled events state %s: %v", s.statePath, err)
+		s.events = make(map[string]scheduledEvent)
+	}
+}
+
+// save writes the state file
+func (s *scheduledEventSync) save() {
+	if s.statePath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(s.events, "", "  ")
+	if err == nil {
+		err = os.WriteFile(s.statePath, append(data, '\n'), 0600)
+	}
+	if err != nil {
+		log.Printf("Warning: failed to write scheduled events state %s: %v", s.statePath, err)
+	}
+}
+
+// scheduledEventParams builds the Discord event for e on server
+func scheduledEventParams(e RaceEvent, server Server) *discordgo.GuildScheduledEventParams {
+	start, end := e.window()
+	join := joinURL(server.IP, server.Port)
+	location := join
+	if len(location) > scheduledEventLocationMax {
+		location = truncateRunes(server.Name, scheduledEventLocationMax)
+	}
+	description := "Server: " + server.Name + "\nJoin: " + join
+	if e.Description != "" {
+		description = e.Description + "\n\n" + description
+	}
+	return &discordgo.GuildScheduledEventParams{
+		Name:               e.Name,
+		Description:        truncateRunes(description, scheduledEventDescriptionMax),
+		ScheduledStartTime: &start,
+		ScheduledEndTime:   &end,
+		PrivacyLevel:       discordgo.GuildScheduledEventPrivacyLevelGuildOnly,
+		EntityType:         discordgo.GuildScheduledEventEntityTypeExternal,
+		EntityMetadata:     &discordgo.GuildScheduledEventEntityMetadata{Location: location},
+	}
+}
+
+// scheduledEventFingerprint hashes the fields of p that are sent to Discord
+func scheduledEventFingerprint(p *discordgo.GuildScheduledEventParams) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%s\x00%d\x00%d\x00%s",
+		p.Name, p.Description, p.ScheduledStartTime.Unix(), p.ScheduledEndTime.Unix(), p.EntityMetadata.Location))
+	return hex.EncodeToString(sum[:8])
+}
+
+// truncateRunes cuts s to n characters, ending in … when cut
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+// sync creates, edits and deletes Discord scheduled events so they match the upcoming config events
+// Events that already ended are left alone; an event whose start has passed cannot be created any more
+func (s *scheduledEventSync) sync(session DiscordSession, guildID string, cfg *Config, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+
+	servers := make(map[string]Server, len(cfg.Servers))
+	for _, srv := range cfg.Servers {
+		if _, ok := servers[srv.Name]; !ok {
+			servers[srv.Name] = srv
+		}
+	}
+
+	changed := false
+	wanted := make(map[string]bool, len(cfg.Events))
+	for _, e := range cfg.Events {
+		wanted[e.Name] = true
+		start, end := e.window()
+		if !now.Before(end) {
+			if _, ok := s.events[e.Name]; ok {
+				delete(s.events, e.Name) // Discord ends external events by itself
+				changed = true
+			}
+			continue
+		}
+		params := scheduledEventParams(e, servers[e.Server])
+		fp := scheduledEventFingerprint(params)
+		existing, ok := s.events[e.Name]
+		if ok && existing.Fingerprint == fp {
+			continue
+		}
+
+		if ok {
+			if !now.Before(start) {
+				// Discord rejects a start time in the past, even unchanged, for a running event
+				params.ScheduledStartTime = nil
+			}
+			_, err := session.GuildScheduledEventEdit(guildID, existing.ID, params)
+			if err == nil {
+				s.events[e.Name] = scheduledEvent{ID: existing.ID, Fingerprint: fp}
+				changed = true
+				log.Printf("Updated Discord scheduled event '%s'", e.Name)
+				continue
+			}
+			if !isNotFound(err) {
+				log.Printf("Error updating Discord scheduled event '%s': %v", e.Name, err)
+				continue
+			}
+			// Deleted in Discord: create it again below
+			delete(s.events, e.Name)
+			changed = true
+		}
+		if !now.Before(start) {
+			continue
+		}
+		created, err := session.GuildScheduledEventCreate(guildID, params)
+		if err != nil {
+			log.Printf("Error creating Discord scheduled event '%s' (the bot needs the Manage Events permission): %v", e.Name, err)
+			continue
+		}
+		s.events[e.Name] = scheduledEvent{ID: created.ID, Fingerprint: fp}
+		changed = true
+		log.Printf("Created Discord scheduled event '%s' for %s", e.Name, start.UTC().Format(time.RFC3339))
+	}
+
+	// Events removed from the config are cancelled in Discord
+	for name, ev := range s.events {
+		if wanted[name] {
+			continue
+		}
+		if err := session.GuildScheduledEventDelete(guildID, ev.ID); err != nil && !isNotFound(err) {
+			log.Printf("Error deleting Discord scheduled event '%s': %v", name, err)
+			continue
+		}
+		delete(s.events, name)
+		changed = true
+		log.Printf("Deleted Discord scheduled event '%s' (removed from the config)", name)
+	}
+
+	if changed {
+		s.save()
+	}
+}
+
+// syncScheduledEvents mirrors the events of cfg in Discord in the background (bot mode, leader only)
+func (b *Bot) syncScheduledEvents(cfg *Config) {
+	if b.scheduledEvents == nil || b.discord == nil || !b.isLeader() {
+		return
+	}
+	guildID := b.discord.guild()
+	if cfg == nil || guildID == "" {
+		return
+	}
+	go func() {
+		defer supervisor.Recover("scheduled event sync", log.Default())
+		b.scheduledEvents.sync(b.discord.session, guildID, cfg, time.Now())
+	}()
+}
+
+// scheduledEventsStatePath returns the state file next to the config file
+func scheduledEventsStatePath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), scheduledEventsStateFile)
+}
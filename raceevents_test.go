@@ -0,0 +1,143 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// testRaceEventConfig has two servers and a Sunday Cup on Track #1 from 18:00 to 20:00 UTC
+func testRaceEventConfig() *Config {
+	cfg := testMaintenanceConfig()
+	for i := range cfg.Servers {
+		cfg.Servers[i].IP = cfg.ServerIP
+	}
+	cfg.Events = []RaceEvent{{Name: "Sunday Cup", Server: "Track #1", Start: "2026-10-18T18:00:00Z", Description: "GT3 at Spa"}}
+	return cfg
+}
+
+// TestValidateRaceEvents tests the events section checks
+func TestValidateRaceEvents(t *testing.T) {
+	cfg := testRaceEventConfig()
+	if err := validateRaceEvents(cfg.Events, cfg.Servers); err != nil {
+		t.Fatalf("Expected valid events, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		event   RaceEvent
+		wantErr string
+	}{
+		{"no name", RaceEvent{Server: "Track #1", Start: "2026-10-18T18:00:00Z"}, "name cannot be empty"},
+		{"duplicate", RaceEvent{Name: "Sunday Cup", Server: "Track #1", Start: "2026-10-25T18:00:00Z"}, "more than once"},
+		{"unknown server", RaceEvent{Name: "Cup", Server: "Rally #1", Start: "2026-10-18T18:00:00Z"}, "server 'Rally #1'"},
+		{"bad start", RaceEvent{Name: "Cup", Server: "Track #1", Start: "Sunday 18:00"}, "RFC 3339"},
+		{"bad duration", RaceEvent{Name: "Cup", Server: "Track #1", Start: "2026-10-18T18:00:00Z", Duration: "-1h"}, "positive duration"},
+		{"long name", RaceEvent{Name: strings.Repeat("x", 101), Server: "Track #1", Start: "2026-10-18T18:00:00Z"}, "longer than 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRaceEvents(append(cfg.Events, tt.event), cfg.Servers)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestMarkRaceEvents tests that the hosting server is highlighted only during the event window
+func TestMarkRaceEvents(t *testing.T) {
+	cfg := testRaceEventConfig()
+	cfg.Events = append(cfg.Events, RaceEvent{Name: "Late Cup", Server: "Track #1", Start: "2026-10-18T19:00:00Z", Duration: "30m"})
+	start := time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC)
+	poll := func() []ServerInfo {
+		return []ServerInfo{
+			{Name: "Drift #1", Category: "Drift", Map: "ebisu", Players: "2/16", NumPlayers: 2, Port: 8081},
+			{Name: "Track #1", Category: "Track", Map: "spa", Players: "18/20", NumPlayers: 18, Port: 8082},
+		}
+	}
+
+	if infos := markRaceEvents(poll(), cfg, start.Add(-time.Minute)); infos[1].Event != nil {
+		t.Errorf("Expected no highlight before the start, got %+v", infos[1].Event)
+	}
+	infos := markRaceEvents(poll(), cfg, start.Add(70*time.Minute))
+	if infos[0].Event != nil || infos[1].Event == nil || infos[1].Event.name != "Sunday Cup" || !infos[1].Event.until.Equal(start.Add(2*time.Hour)) {
+		t.Fatalf("Expected the earlier event on Track #1 only, got %+v / %+v", infos[0].Event, infos[1].Event)
+	}
+	if infos := markRaceEvents(poll(), cfg, start.Add(2*time.Hour)); infos[1].Event != nil {
+		t.Errorf("Expected no highlight after the end, got %+v", infos[1].Event)
+	}
+
+	embed := statusEmbed(infos, cfg, false)
+	var field *discordgo.MessageEmbedField
+	for _, f := range embed.Fields {
+		if strings.Contains(f.Name, "Track #1") {
+			field = f
+		}
+	}
+	if field == nil || field.Name != ":green_circle: 🏁 Track #1" || !strings.Contains(field.Value, "🏁 **Sunday Cup** until 20:00 UTC") {
+		t.Errorf("Expected the event highlight in the embed, got %+v", field)
+	}
+	if srv := buildStatusSnapshot(infos, cfg, start).Categories[1].Servers[0]; srv.Event != "Sunday Cup" {
+		t.Errorf("Expected the event in the status JSON, got %+v", srv)
+	}
+}
+
+// TestScheduledEventSync tests create, no-op, edit, recreate after deletion in Discord, and delete on removal
+func TestScheduledEventSync(t *testing.T) {
+	fake := newFakeDiscord()
+	cfg := testRaceEventConfig()
+	statePath := filepath.Join(t.TempDir(), scheduledEventsStateFile)
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	s := newScheduledEventSync(statePath)
+	s.sync(fake, fakeGuildID, cfg, now)
+	events := fake.scheduledEvents()
+	cup := events["Sunday Cup"]
+	if len(events) != 1 || cup == nil || !strings.HasPrefix(cup.Description, "GT3 at Spa\n\nServer: Track #1\nJoin: https://acstuff.club/") {
+		t.Fatalf("Expected the event created, got %+v", events)
+	}
+
+	// A restart reads the state file: unchanged events are not touched
+	s = newScheduledEventSync(statePath)
+	s.sync(fake, fakeGuildID, cfg, now)
+	if n := fake.count("GuildScheduledEventCreate") + fake.count("GuildScheduledEventEdit"); n != 1 {
+		t.Errorf("Expected no calls for an unchanged event, got %d in total", n)
+	}
+
+	cfg.Events[0].Start = "2026-10-18T19:00:00Z"
+	s.sync(fake, fakeGuildID, cfg, now)
+	if ev := fake.scheduledEvents()["Sunday Cup"]; ev == nil || ev.ID != cup.ID || ev.ScheduledStartTime.Hour() != 19 {
+		t.Errorf("Expected the same event moved to 19:00, got %+v", ev)
+	}
+
+	// Deleted by a moderator: created again on the next change
+	fake.GuildScheduledEventDelete(fakeGuildID, cup.ID)
+	cfg.Events[0].Duration = "3h"
+	s.sync(fake, fakeGuildID, cfg, now)
+	if ev := fake.scheduledEvents()["Sunday Cup"]; ev == nil || ev.ID == cup.ID {
+		t.Errorf("Expected the event created again, got %+v", ev)
+	}
+
+	cfg.Events = nil
+	s.sync(fake, fakeGuildID, cfg, now)
+	if events := fake.scheduledEvents(); len(events) != 0 || len(s.events) != 0 {
+		t.Errorf("Expected the removed event deleted, got %+v / %+v", events, s.events)
+	}
+}
+
+// TestScheduledEventSync_PastEvents tests that started or finished events are not created
+func TestScheduledEventSync_PastEvents(t *testing.T) {
+	fake := newFakeDiscord()
+	cfg := testRaceEventConfig()
+	s := newScheduledEventSync("")
+
+	s.sync(fake, fakeGuildID, cfg, time.Date(2026, 10, 18, 18, 30, 0, 0, time.UTC))
+	s.sync(fake, fakeGuildID, cfg, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC))
+	if n := fake.count("GuildScheduledEventCreate"); n != 0 {
+		t.Errorf("Expected no event created after the start, got %d", n)
+	}
+}
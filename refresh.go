@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ================= LAYOUT REFRESH =================
+
+// refreshRetryDelay is how long a refresh waits before retrying when an update is already running
+// The running update may have started with the old config, so the refresh must not be dropped
+const refreshRetryDelay = time.Second
+
+// categoryLayoutChanged reports whether category order, names or emojis differ between two configs
+// Server and interval changes are picked up by the next regular tick and do not count
+func categoryLayoutChanged(old, new *Config) bool {
+	if old == nil || new == nil {
+		return old != new
+	}
+	return !slices.Equal(old.CategoryOrder, new.CategoryOrder) || !maps.Equal(old.CategoryEmojis, new.CategoryEmojis)
+}
+
+// onConfigChange is the ConfigManager change listener: queues a refresh when the category layout changed,
+// routes a config_changed alert, queues the on_config_change hooks and syncs changed race events. Called with the config lock held, so it only
+// signals the update loop and does the Discord calls in the background
+func (b *Bot) onConfigChange(old, new *Config) {
+	if categoryLayoutChanged(old, new) {
+		b.requestRefresh()
+	}
+	b.alertAsync(newAlertEvent(alertConfigChanged, describeConfigChange(old, new)))
+	if b.hooks != nil && b.isLeader() {
+		b.hooks.configChange(old, new, time.Now())
+	}
+	if new != nil && (old == nil || !slices.Equal(old.Events, new.Events)) {
+		b.syncScheduledEvents(new)
+	}
+}
+
+// requestRefresh asks the update loop for an immediate full rebuild (coalesced, never blocks)
+func (b *Bot) requestRefresh() {
+	select {
+	case b.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// drainRefresh discards a pending refresh request
+func (b *Bot) drainRefresh() {
+	select {
+	case <-b.refresh:
+	default:
+	}
+}
+
+// runRefresh performs an out-of-cycle update, retrying shortly if one is already in progress
+func (b *Bot) runRefresh() {
+	if !b.tryPerformUpdate() {
+		log.Printf("Refresh deferred: update in progress, retrying in %v", refreshRetryDelay)
+		time.AfterFunc(refreshRetryDelay, b.requestRefresh)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCategoryLayoutChanged tests which config differences count as a layout change
+func TestCategoryLayoutChanged(t *testing.T) {
+	base := testStatusConfig()
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   bool
+	}{
+		{"identical", func(c *Config) {}, false},
+		{"interval only", func(c *Config) { c.UpdateInterval = 60 }, false},
+		{"servers only", func(c *Config) { c.Servers = []Server{{Name: "New", Port: 9000, Category: "Drift"}} }, false},
+		{"reordered", func(c *Config) { c.CategoryOrder = []string{"Track", "Drift"} }, true},
+		{"renamed", func(c *Config) {
+			c.CategoryOrder = []string{"Drift", "Circuit"}
+			c.CategoryEmojis = map[string]string{"Drift": "🟣", "Circuit": "🔵"}
+		}, true},
+		{"emoji changed", func(c *Config) { c.CategoryEmojis = map[string]string{"Drift": "🟣", "Track": "🏁"} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := testStatusConfig()
+			tt.modify(next)
+			if got := categoryLayoutChanged(base, next); got != tt.want {
+				t.Errorf("categoryLayoutChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !categoryLayoutChanged(nil, base) {
+		t.Error("Expected first config load to count as a layout change")
+	}
+	if categoryLayoutChanged(nil, nil) {
+		t.Error("Expected nil -> nil not to count as a layout change")
+	}
+}
+
+// TestConfigManager_WriteConfig_QueuesRefreshOnCategoryChange tests that a GUI write renaming a category
+// requests an immediate rebuild, while a write that keeps the layout does not
+func TestConfigManager_WriteConfig_QueuesRefreshOnCategoryChange(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	initial := &Config{
+		ServerIP:       "10.0.0.1",
+		UpdateInterval: 30,
+		CategoryOrder:  []string{"Race"},
+		CategoryEmojis: map[string]string{"Race": "🏎️"},
+		Servers:        []Server{{Name: "Server1", Port: 8001, Category: "Race"}},
+	}
+
+	b := newTestBot(nil)
+	b.configManager = NewConfigManager(configPath, initial)
+	b.refresh = make(chan struct{}, 1)
+	b.configManager.SetOnChange(b.onConfigChange)
+
+	sameLayout := *initial
+	sameLayout.UpdateInterval = 60
+	if err := b.configManager.WriteConfig(&sameLayout); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+	if len(b.refresh) != 0 {
+		t.Error("Expected no refresh when categories are unchanged")
+	}
+
+	renamed := &Config{
+		ServerIP:       "10.0.0.1",
+		UpdateInterval: 60,
+		CategoryOrder:  []string{"Endurance"},
+		CategoryEmojis: map[string]string{"Endurance": "⏱️"},
+		Servers:        []Server{{Name: "Server1", Port: 8001, Category: "Endurance"}},
+	}
+	if err := b.configManager.WriteConfig(renamed); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+	if len(b.refresh) != 1 {
+		t.Fatal("Expected a queued refresh after renaming a category")
+	}
+
+	// Further requests coalesce into the pending one instead of blocking
+	b.requestRefresh()
+	if len(b.refresh) != 1 {
+		t.Errorf("Expected refresh requests to coalesce, got %d pending", len(b.refresh))
+	}
+	b.drainRefresh()
+	if len(b.refresh) != 0 {
+		t.Error("Expected drainRefresh to clear the pending request")
+	}
+}
+
+// TestRunRefresh_RetriesWhenBusy tests that a refresh arriving during an update is re-queued, not dropped
+func TestRunRefresh_RetriesWhenBusy(t *testing.T) {
+	b := newTestBot(nil)
+	b.refresh = make(chan struct{}, 1)
+
+	b.updates.busy.Store(true)
+	b.runRefresh()
+
+	select {
+	case <-b.refresh:
+	case <-time.After(3 * refreshRetryDelay):
+		t.Fatal("Expected refresh to be re-queued after the retry delay")
+	}
+}
+
+// TestBuildEmbed_RenamedCategoryLeavesNoStaleFields tests that the embed is rebuilt from the given config only
+func TestBuildEmbed_RenamedCategoryLeavesNoStaleFields(t *testing.T) {
+	cfg := testStatusConfig()
+	cfg.CategoryOrder = []string{"Drift", "Circuit"}
+	cfg.CategoryEmojis = map[string]string{"Drift": "🟣", "Circuit": "🏁"}
+
+	infos := []ServerInfo{
+		{Name: "Drift 1", Category: "Drift", Map: "ebisu", Players: "2/16", NumPlayers: 2, IP: "203.0.113.10", Port: 8081},
+		{Name: "Track 1", Category: "Circuit", Map: "spa", Players: "4/20", NumPlayers: 4, IP: "203.0.113.10", Port: 8082},
+	}
+
+	embed := buildEmbed(infos, cfg)
+
+	var headers []string
+	for _, f := range embed.Fields {
+		if strings.Contains(f.Name, "Servers —") {
+			headers = append(headers, f.Name)
+		}
+		if strings.Contains(f.Name, "Track Servers") || strings.Contains(f.Name, "🔵") {
+			t.Errorf("Found stale field for the old category: %q", f.Name)
+		}
+	}
+	want := []string{"🟣 **Drift Servers — 2 players**", "🏁 **Circuit Servers — 4 players**"}
+	if strings.Join(headers, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected category headers %v, got %v", want, headers)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// ================= DATA RETENTION AND PURGE =================
+
+const (
+	// maxRetentionDays bounds the *_RETENTION_DAYS variables (ten years)
+	maxRetentionDays = 3650
+	// retentionInterval is how often the retention job prunes
+	retentionInterval = time.Hour
+)
+
+// retentionFromEnv parses name as a number of days (0 = keep forever), def when unset
+func retentionFromEnv(name string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	days, err := strconv.Atoi(v)
+	if err != nil || days < 0 || days > maxRetentionDays {
+		return 0, fmt.Errorf("invalid %s %q: must be a number of days between 0 (keep) and %d", name, v, maxRetentionDays)
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// retentionPolicy prunes data older than its limits once an hour after a poll; zero limits keep data
+// The server history prunes itself (HISTORY_RETENTION_DAYS)
+type retentionPolicy struct {
+	audit   time.Duration // API config audit entries
+	results time.Duration // race session results files in STANDINGS_RESULTS_DIR
+	drivers time.Duration // driver links of members who have not raced for this long
+
+	// lastRun is only touched by performUpdate, which never runs concurrently
+	lastRun time.Time
+}
+
+// retentionPolicyFromEnv reads AUDIT_RETENTION_DAYS, RESULTS_RETENTION_DAYS and DRIVER_RETENTION_DAYS
+// Returns nil when none is set
+func retentionPolicyFromEnv() (*retentionPolicy, error) {
+	var p retentionPolicy
+	for _, v := range []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"AUDIT_RETENTION_DAYS", &p.audit},
+		{"RESULTS_RETENTION_DAYS", &p.results},
+		{"DRIVER_RETENTION_DAYS", &p.drivers},
+	} {
+		d, err := retentionFromEnv(v.name, 0)
+		if err != nil {
+			return nil, err
+		}
+		*v.dst = d
+	}
+	if p.audit == 0 && p.results == 0 && p.drivers == 0 {
+		return nil, nil
+	}
+	log.Printf("Data retention enabled (audit log: %s, results: %s, driver links: %s)",
+		retentionDays(p.audit), retentionDays(p.results), retentionDays(p.drivers))
+	return &p, nil
+}
+
+// retentionDays renders a limit for the log
+func retentionDays(d time.Duration) string {
+	if d == 0 {
+		return "kept"
+	}
+	return fmt.Sprintf("%d days", int(d.Hours()/24))
+}
+
+// enforceRetention prunes what outlived the retention policy, at most once per retentionInterval (no-op when
+// disabled). Results files are deleted by the leader only, since the results folder may be shared
+func (b *Bot) enforceRetention(now time.Time) {
+	p := b.retention
+	if p == nil || now.Sub(p.lastRun) < retentionInterval {
+		return
+	}
+	p.lastRun = now
+
+	if p.audit > 0 && b.apiServer != nil {
+		if n := b.apiServer.ExpireAuditEntries(now.Add(-p.audit)); n > 0 {
+			log.Printf("Retention: dropped %d config audit entries older than %s", n, retentionDays(p.audit))
+		}
+	}
+	if p.results > 0 && b.standings != nil && b.isLeader() {
+		n, err := b.standings.prune(now.Add(-p.results), now)
+		if err != nil {
+			log.Printf("Retention: failed to delete old results files: %v", err)
+		}
+		if n > 0 {
+			log.Printf("Retention: deleted %d results files older than %s", n, retentionDays(p.results))
+		}
+	}
+	if p.drivers > 0 && b.drivers != nil {
+		cutoff := now.Add(-p.drivers)
+		// Until the results folder was read, every driver would look inactive
+		if raced, ok := b.standings.racedSince(cutoff); ok {
+			if n := b.drivers.prune(cutoff, raced); n > 0 {
+				log.Printf("Retention: removed %d driver links without a race in %s", n, retentionDays(p.drivers))
+			}
+		}
+	}
+}
+
+// dataPurger adapts the bot to api.DataPurger
+type dataPurger struct {
+	bot  *Bot
+	logs *logRing // nil = no log buffer
+}
+
+// Purge implements api.DataPurger: removes the driver link, slot sign-ups and whitelist entries of the person,
+// anonymizes them in the results files and blanks their identifiers in the buffered log lines
+// Links are followed both ways, so a Steam ID also purges the member it is linked to and vice versa
+func (p *dataPurger) Purge(req api.PurgeRequest) (api.PurgeResult, error) {
+	b := p.bot
+	res := api.PurgeResult{DiscordUserIDs: []string{}, SteamIDs: []string{}}
+	if req.DiscordUserID != "" {
+		res.DiscordUserIDs = append(res.DiscordUserIDs, req.DiscordUserID)
+	}
+	if req.SteamID != "" {
+		res.SteamIDs = append(res.SteamIDs, req.SteamID)
+	}
+
+	if b.drivers != nil {
+		if req.DiscordUserID != "" {
+			if guid, ok := b.drivers.unlinkUser(req.DiscordUserID); ok {
+				res.DriverLinks++
+				if !slices.Contains(res.SteamIDs, guid) {
+					res.SteamIDs = append(res.SteamIDs, guid)
+				}
+			}
+		}
+		if req.SteamID != "" {
+			if user := b.drivers.userOf(req.SteamID); user != "" && b.drivers.unlinkGUID(req.SteamID) {
+				res.DriverLinks++
+				if !slices.Contains(res.DiscordUserIDs, user) {
+					res.DiscordUserIDs = append(res.DiscordUserIDs, user)
+				}
+			}
+		}
+	}
+
+	if b.slots != nil {
+		for _, user := range res.DiscordUserIDs {
+			res.SlotSignups += b.slots.forget(user)
+		}
+	}
+
+	res.LogLines = p.logs.scrub(append(slices.Clone(res.DiscordUserIDs), res.SteamIDs...))
+
+	if b.standings != nil {
+		pseudonym := newPseudonym()
+		for _, guid := range res.SteamIDs {
+			n, err := b.standings.anonymize(guid, pseudonym, time.Now())
+			res.ResultsFiles += n
+			if err != nil {
+				return res, err
+			}
+		}
+	}
+
+	n, err := b.purgeWhitelists(res.SteamIDs)
+	res.WhitelistEntries = n
+	return res, err
+}
+
+// newPseudonym returns the GUID an anonymized driver gets in results files, random so it cannot be traced back
+// but shared by the files of one purge, so the driver's past races still count together in the standings
+func newPseudonym() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "anonymized-" + hex.EncodeToString(b)
+}
+
+// purgeWhitelists removes guids from the reserved slots of every server and rewrites their entry lists
+func (b *Bot) purgeWhitelists(guids []string) (int, error) {
+	cfg := b.configManager.GetConfig()
+	if cfg == nil || len(guids) == 0 {
+		return 0, nil
+	}
+	removed := 0
+	for server, wl := range cfg.Whitelists {
+		kept := slices.DeleteFunc(slices.Clone(wl.Drivers), func(d WhitelistEntry) bool { return slices.Contains(guids, d.GUID) })
+		if len(kept) == len(wl.Drivers) {
+			continue
+		}
+		if _, err := b.setWhitelist(server, kept); err != nil {
+			return removed, fmt.Errorf("failed to remove the driver from the whitelist of '%s': %w", server, err)
+		}
+		removed += len(wl.Drivers) - len(kept)
+	}
+	return removed, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// TestRetentionPolicyFromEnv tests the defaults, the limits and invalid values
+func TestRetentionPolicyFromEnv(t *testing.T) {
+	if p, err := retentionPolicyFromEnv(); p != nil || err != nil {
+		t.Errorf("Expected no policy without settings, got %+v %v", p, err)
+	}
+
+	t.Setenv("AUDIT_RETENTION_DAYS", "30")
+	t.Setenv("DRIVER_RETENTION_DAYS", "0")
+	p, err := retentionPolicyFromEnv()
+	if err != nil || p == nil || p.audit != 30*24*time.Hour || p.results != 0 || p.drivers != 0 {
+		t.Errorf("Unexpected policy %+v %v", p, err)
+	}
+
+	for _, bad := range []string{"-1", "3651", "30d"} {
+		t.Setenv("RESULTS_RETENTION_DAYS", bad)
+		if _, err := retentionPolicyFromEnv(); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+
+	t.Setenv("HISTORY_ENABLED", "true")
+	t.Setenv("HISTORY_RETENTION_DAYS", "0")
+	db := testDatabase(t)
+	if _, err := historyStoreFromEnv(filepath.Join(t.TempDir(), "config.json"), db); err == nil {
+		t.Error("Expected a history retention of 0 days to be refused")
+	}
+	t.Setenv("HISTORY_RETENTION_DAYS", "40")
+	if h, err := historyStoreFromEnv(filepath.Join(t.TempDir(), "config.json"), db); err != nil || h.retention != 40*24*time.Hour {
+		t.Errorf("Unexpected history store %+v %v", h, err)
+	}
+}
+
+// TestEnforceRetention tests deleting old results files, pruning inactive driver links and running once an hour
+func TestEnforceRetention(t *testing.T) {
+	dir := t.TempDir()
+	copyResults(t, dir, "2026_10_11_18_00_RACE.json", "2026_10_18_17_30_QUALIFY.json", "2026_10_18_18_00_RACE.json")
+	now := time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)
+
+	b := newTestBot(testMaintenanceConfig())
+	b.standings = newStandingsModule(dir, "", "", nil, "")
+	b.standings.scan(now)
+	b.drivers = newDriverRegistry(filepath.Join(t.TempDir(), driversStateFile))
+	b.drivers.link("76561198000000003", "3", "carol", now.AddDate(0, 0, -30)) // raced on 10-18
+	b.drivers.link("76561198000000009", "9", "dave", now.AddDate(0, 0, -30))  // never raced
+	b.drivers.link(aliceGUID, "1", "alice", now.AddDate(0, 0, -1))            // linked recently
+	b.retention = &retentionPolicy{results: 5 * 24 * time.Hour, drivers: 7 * 24 * time.Hour}
+
+	b.enforceRetention(now)
+	if _, err := os.Stat(filepath.Join(dir, "2026_10_11_18_00_RACE.json")); !os.IsNotExist(err) {
+		t.Error("Expected the results file of 10-11 to be deleted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2026_10_18_17_30_QUALIFY.json")); err != nil {
+		t.Errorf("Expected recent results files to be kept: %v", err)
+	}
+	if got := (&standingsProvider{module: b.standings}).Standings(); got.Races != 1 {
+		t.Errorf("Expected the standings to be rescanned, got %d races", got.Races)
+	}
+	if b.drivers.userOf("76561198000000009") != "" || b.drivers.userOf("76561198000000003") != "3" || b.drivers.userOf(aliceGUID) != "1" {
+		t.Error("Expected only the link without a race to be removed")
+	}
+
+	copyResults(t, dir, "2026_10_11_18_00_RACE.json")
+	b.enforceRetention(now.Add(30 * time.Minute))
+	if _, err := os.Stat(filepath.Join(dir, "2026_10_11_18_00_RACE.json")); err != nil {
+		t.Error("Expected no second run within the hour")
+	}
+}
+
+// TestDataPurge tests that a purge by Discord user follows the driver link to the GUID and removes or
+// anonymizes the link, slot sign-ups, results, whitelist entries and log lines
+func TestDataPurge(t *testing.T) {
+	b, _ := testWhitelistBot(t)
+	if _, err := b.setWhitelist("Track #1", []WhitelistEntry{{GUID: aliceGUID, Name: "Alice"}, {GUID: bobGUID}}); err != nil {
+		t.Fatalf("setWhitelist failed: %v", err)
+	}
+	dir := t.TempDir()
+	copyResults(t, dir, "2026_10_11_18_00_RACE.json", "2026_10_18_18_00_RACE.json")
+	b.standings = newStandingsModule(dir, "", "", nil, "")
+	b.drivers = newDriverRegistry(filepath.Join(t.TempDir(), driversStateFile))
+	const alice = "111111111111111111"
+	b.drivers.link(aliceGUID, alice, "alice", time.Now())
+	b.slots = newSlotNotifier(time.Hour, time.Minute)
+	b.slots.toggle(&slotPoll(2)[1], invoker{id: alice, name: "alice"}, time.Now())
+	logs := newLogRing(10)
+	logs.Write([]byte("Driver alice linked Steam ID " + aliceGUID + "\n"))
+	logs.Write([]byte("Unrelated line\n"))
+
+	res, err := (&dataPurger{bot: b, logs: logs}).Purge(api.PurgeRequest{DiscordUserID: alice})
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if !slices.Equal(res.SteamIDs, []string{aliceGUID}) || res.DriverLinks != 1 || res.SlotSignups != 1 ||
+		res.ResultsFiles != 2 || res.WhitelistEntries != 1 || res.LogLines != 1 {
+		t.Errorf("Unexpected result %+v", res)
+	}
+
+	if b.drivers.userOf(aliceGUID) != "" {
+		t.Error("Expected the driver link to be removed")
+	}
+	for _, name := range []string{"2026_10_11_18_00_RACE.json", "2026_10_18_18_00_RACE.json"} {
+		data, _ := os.ReadFile(filepath.Join(dir, name))
+		if strings.Contains(string(data), aliceGUID) || strings.Contains(string(data), `"Alice"`) {
+			t.Errorf("Expected Alice to be anonymized in %s", name)
+		}
+	}
+	got := (&standingsProvider{module: b.standings}).Standings()
+	if len(got.Drivers) != 3 || !slices.ContainsFunc(got.Drivers, func(d api.DriverStanding) bool { return d.Driver == "Anonymized driver" && d.Races == 2 }) {
+		t.Errorf("Expected the anonymized driver to keep both races in the standings, got %+v", got.Drivers)
+	}
+	if wl := b.configManager.GetConfig().Whitelists["Track #1"]; len(wl.Drivers) != 1 || wl.Drivers[0].GUID != bobGUID {
+		t.Errorf("Expected only Bob left in the whitelist, got %+v", wl.Drivers)
+	}
+	if tail := logs.String(); strings.Contains(tail, aliceGUID) || !strings.Contains(tail, "[purged]") || !strings.Contains(tail, "Unrelated line") {
+		t.Errorf("Unexpected log buffer %q", tail)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"log"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ================= STATUS EDIT RETRY =================
+
+// Retry tuning for status edits: a few quick inline attempts, then background retries until delivered or superseded
+const (
+	editRetryAttempts     = 3
+	editRetryBaseDelay    = 500 * time.Millisecond
+	editPendingRetryDelay = 2 * time.Second
+	editPendingRetryMax   = 30 * time.Second
+)
+
+// retryJitter returns d scaled by a random factor in [0.5, 1.5) so replicas and retries do not align
+func retryJitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d)))
+}
+
+// EditStats counts status delivery failures of a retryPublisher
+type EditStats struct {
+	// Failures is the number of failed attempts (including retried ones)
+	Failures uint64
+	// Recovered is the number of updates delivered after at least one failed attempt
+	Recovered uint64
+	// Superseded is the number of undelivered updates replaced by a newer one before they landed
+	Superseded uint64
+	// Dropped is the number of updates abandoned on a non-transient error
+	Dropped uint64
+}
+
+// retryPublisher wraps a publisher so transient status update failures are retried with jitter
+// Undelivered updates wait in a single-slot queue (latest wins) that is retried in the background,
+// so the newest status always lands eventually, without waiting for the next tick
+type retryPublisher struct {
+	Publisher
+
+	// sleep waits between inline attempts (time.Sleep; replaced in tests)
+	sleep func(time.Duration)
+
+	// breaker pauses requests during a Discord outage (nil = disabled)
+	breaker *circuitBreaker
+
+	// handler reacts to non-transient errors and recoveries (nil = errors are only returned)
+	handler discordErrorHandler
+
+	// send serializes deliveries so inline and background attempts never reorder edits
+	send sync.Mutex
+
+	mu      sync.Mutex
+	pending *StatusUpdate
+	timer   *time.Timer
+	backoff time.Duration
+
+	failures   atomic.Uint64
+	recovered  atomic.Uint64
+	superseded atomic.Uint64
+	dropped    atomic.Uint64
+}
+
+// newRetryPublisher wraps p with retry, a pending-update queue and a circuit breaker
+// handler may be nil
+func newRetryPublisher(p Publisher, handler discordErrorHandler) *retryPublisher {
+	return &retryPublisher{Publisher: p, sleep: time.Sleep, breaker: &circuitBreaker{}, handler: handler}
+}
+
+// Stats returns the delivery failure counters
+func (r *retryPublisher) Stats() EditStats {
+	return EditStats{
+		Failures:   r.failures.Load(),
+		Recovered:  r.recovered.Load(),
+		Superseded: r.superseded.Load(),
+		Dropped:    r.dropped.Load(),
+	}
+}
+
+// UpdateStatus implements Publisher: queues u (replacing any undelivered update) and delivers it
+func (r *retryPublisher) UpdateStatus(u *StatusUpdate) error {
+	r.mu.Lock()
+	if r.pending != nil {
+		r.superseded.Add(1)
+	}
+	r.pending = u
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	r.mu.Unlock()
+	return r.flush()
+}
+
+// flush delivers the pending update with a few quick attempts
+// A transient failure keeps it queued and schedules a background retry with growing backoff
+func (r *retryPublisher) flush() error {
+	r.send.Lock()
+	defer r.send.Unlock()
+
+	r.mu.Lock()
+	u := r.pending
+	r.mu.Unlock()
+	if u == nil {
+		return nil // delivered by a concurrent flush
+	}
+
+	var err error
+	failed := false
+	for attempt := 1; attempt <= editRetryAttempts; attempt++ {
+		if ok, until := r.breaker.allow(time.Now()); !ok {
+			r.scheduleRetry(u, time.Until(until))
+			if r.handler != nil {
+				r.handler.discordUnreachable(errCircuitOpen)
+			}
+			return errCircuitOpen
+		}
+		err = r.Publisher.UpdateStatus(u)
+		r.breaker.record(err, time.Now())
+		if err == nil {
+			break
+		}
+		failed = true
+		r.failures.Add(1)
+		if !classifyDiscordError(err).transient() || attempt == editRetryAttempts {
+			break
+		}
+		r.sleep(retryJitter(editRetryBaseDelay << (attempt - 1)))
+		if r.current() != u {
+			return nil // a newer update arrived; its own flush delivers it
+		}
+	}
+
+	r.mu.Lock()
+	if r.pending != u {
+		r.mu.Unlock()
+		return err // superseded while sending; the newer update is delivered by its caller
+	}
+
+	switch {
+	case err == nil:
+		// backoff is set while the queue holds an update that failed earlier (background retry)
+		if failed || r.backoff > 0 {
+			r.recovered.Add(1)
+			log.Printf("%s: status update delivered after retry (%d failed attempts so far)", r.Name(), r.failures.Load())
+		}
+		r.pending = nil
+		r.backoff = 0
+		r.mu.Unlock()
+		if r.handler != nil {
+			r.handler.discordRecovered()
+		}
+		return nil
+	case !classifyDiscordError(err).transient():
+		r.pending = nil
+		r.backoff = 0
+		r.dropped.Add(1)
+		r.mu.Unlock()
+		// Handlers may publish alerts or re-resolve the channel, so they run without r.mu
+		if r.handler != nil {
+			r.handler.discordFailed(classifyDiscordError(err), err)
+		}
+		return err
+	default:
+		if r.backoff == 0 {
+			r.backoff = editPendingRetryDelay
+		} else if r.backoff = r.backoff * 2; r.backoff > editPendingRetryMax {
+			r.backoff = editPendingRetryMax
+		}
+		delay := retryJitter(r.backoff)
+		r.timer = time.AfterFunc(delay, r.retryPending)
+		r.mu.Unlock()
+		log.Printf("%s: status update failed %d times, retrying in %v (%d failed attempts total)",
+			r.Name(), editRetryAttempts, delay.Round(time.Millisecond), r.failures.Load())
+		return err
+	}
+}
+
+// scheduleRetry retries u in the background after delay unless a newer update replaces it
+func (r *retryPublisher) scheduleRetry(u *StatusUpdate, delay time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending != u {
+		return
+	}
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.timer = time.AfterFunc(delay, r.retryPending)
+}
+
+// retryPending is the background retry of a queued update
+func (r *retryPublisher) retryPending() {
+	r.mu.Lock()
+	r.timer = nil
+	r.mu.Unlock()
+	if err := r.flush(); err != nil {
+		log.Printf("Error updating status (%s, background retry): %v", r.Name(), err)
+	}
+}
+
+// current returns the queued update (nil when delivered)
+func (r *retryPublisher) current() *StatusUpdate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pending
+}
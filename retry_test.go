@@ -0,0 +1,155 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// scriptedPublisher fails UpdateStatus with the queued errors, then succeeds
+type scriptedPublisher struct {
+	mu        sync.Mutex
+	errs      []error
+	calls     int
+	delivered []*StatusUpdate
+}
+
+func (s *scriptedPublisher) Name() string               { return "scripted" }
+func (s *scriptedPublisher) DeleteStatus() error        { return nil }
+func (s *scriptedPublisher) SendAlert(msg string) error { return nil }
+
+func (s *scriptedPublisher) UpdateStatus(u *StatusUpdate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return err
+	}
+	s.delivered = append(s.delivered, u)
+	return nil
+}
+
+func (s *scriptedPublisher) snapshot() (int, []*StatusUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls, append([]*StatusUpdate{}, s.delivered...)
+}
+
+// restError builds a discordgo REST error with the given status code
+func restError(code int) error {
+	return &discordgo.RESTError{Response: &http.Response{StatusCode: code}}
+}
+
+// newTestRetryPublisher wraps p without real sleeps between inline attempts
+func newTestRetryPublisher(p Publisher) *retryPublisher {
+	r := newRetryPublisher(p, nil)
+	r.sleep = func(time.Duration) {}
+	return r
+}
+
+// TestRetryPublisher_RecoversInline tests that a transient failure is retried and counted
+func TestRetryPublisher_RecoversInline(t *testing.T) {
+	inner := &scriptedPublisher{errs: []error{restError(http.StatusInternalServerError)}}
+	r := newTestRetryPublisher(inner)
+
+	u := &StatusUpdate{}
+	if err := r.UpdateStatus(u); err != nil {
+		t.Fatalf("Expected delivery after retry, got %v", err)
+	}
+	calls, delivered := inner.snapshot()
+	if calls != 2 || len(delivered) != 1 || delivered[0] != u {
+		t.Errorf("Expected 2 calls and 1 delivery, got %d calls, %d deliveries", calls, len(delivered))
+	}
+	if s := r.Stats(); s.Failures != 1 || s.Recovered != 1 || s.Dropped != 0 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+	if r.current() != nil {
+		t.Error("Expected empty queue after delivery")
+	}
+}
+
+// TestRetryPublisher_NonTransientDropped tests that 4xx errors are not retried
+func TestRetryPublisher_NonTransientDropped(t *testing.T) {
+	inner := &scriptedPublisher{errs: []error{restError(http.StatusForbidden)}}
+	r := newTestRetryPublisher(inner)
+
+	if err := r.UpdateStatus(&StatusUpdate{}); err == nil {
+		t.Fatal("Expected error for non-transient failure")
+	}
+	if calls, _ := inner.snapshot(); calls != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls)
+	}
+	if s := r.Stats(); s.Dropped != 1 || r.current() != nil {
+		t.Errorf("Expected update dropped, got stats %+v", s)
+	}
+}
+
+// TestRetryPublisher_BackgroundRetry tests that an update failing all inline attempts lands in the background
+func TestRetryPublisher_BackgroundRetry(t *testing.T) {
+	errs := make([]error, editRetryAttempts)
+	for i := range errs {
+		errs[i] = restError(http.StatusServiceUnavailable)
+	}
+	inner := &scriptedPublisher{errs: errs}
+	r := newTestRetryPublisher(inner)
+
+	u := &StatusUpdate{}
+	if err := r.UpdateStatus(u); err == nil {
+		t.Fatal("Expected error after inline attempts")
+	}
+	if r.current() != u {
+		t.Fatal("Expected update to stay queued")
+	}
+
+	deadline := time.Now().Add(2 * editPendingRetryDelay)
+	for time.Now().Before(deadline) {
+		if _, delivered := inner.snapshot(); len(delivered) == 1 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if _, delivered := inner.snapshot(); len(delivered) != 1 || delivered[0] != u {
+		t.Fatalf("Expected queued update delivered in the background, got %d deliveries", len(delivered))
+	}
+	if s := r.Stats(); s.Failures != editRetryAttempts || s.Recovered != 1 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+}
+
+// TestRetryPublisher_LatestWins tests that a newer update replaces a queued one and cancels its retry
+func TestRetryPublisher_LatestWins(t *testing.T) {
+	errs := make([]error, editRetryAttempts)
+	for i := range errs {
+		errs[i] = restError(http.StatusBadGateway)
+	}
+	inner := &scriptedPublisher{errs: errs}
+	r := newTestRetryPublisher(inner)
+
+	old := &StatusUpdate{}
+	r.UpdateStatus(old)
+
+	latest := &StatusUpdate{}
+	if err := r.UpdateStatus(latest); err != nil {
+		t.Fatalf("Expected newer update to be delivered, got %v", err)
+	}
+	_, delivered := inner.snapshot()
+	if len(delivered) != 1 || delivered[0] != latest {
+		t.Fatalf("Expected only the latest update delivered, got %d deliveries", len(delivered))
+	}
+	if s := r.Stats(); s.Superseded != 1 {
+		t.Errorf("Expected 1 superseded update, got %+v", s)
+	}
+
+	// The cancelled background retry must not resend anything
+	r.mu.Lock()
+	timer := r.timer
+	r.mu.Unlock()
+	if timer != nil {
+		t.Error("Expected pending retry timer to be cancelled")
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// ================= TRACK ROTATIONS =================
+
+const (
+	// maxRotationEntries bounds the upcoming tracks of one server
+	maxRotationEntries = 50
+	// rotationTrackMax bounds a track name shown in the embed
+	rotationTrackMax = 100
+)
+
+// TrackRotation is one upcoming track of a server (rotations in config.json, keyed by server name)
+// The embed shows the next one as "Next: Spa in 42 min", recalculated on every update
+type TrackRotation struct {
+	Track string `json:"track"`
+	Start string `json:"start"` // RFC 3339, e.g. 2026-10-18T18:00:00Z
+}
+
+// upcomingTrack is the next track of a server
+type upcomingTrack struct {
+	track string
+	at    time.Time
+}
+
+// validateRotations checks the rotations section against the configured servers
+func validateRotations(rotations map[string][]TrackRotation, servers []Server) error {
+	serverNames := make(map[string]bool, len(servers))
+	for _, s := range servers {
+		serverNames[s.Name] = true
+	}
+	for _, server := range slices.Sorted(maps.Keys(rotations)) {
+		if err := validateRotation(server, rotations[server], serverNames); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRotation checks the rotation of one server
+func validateRotation(server string, rotation []TrackRotation, serverNames map[string]bool) error {
+	if !serverNames[server] {
+		return fmt.Errorf("rotation for server '%s' which is not defined in servers", server)
+	}
+	if len(rotation) > maxRotationEntries {
+		return fmt.Errorf("rotation for server '%s' has %d entries (max %d)", server, len(rotation), maxRotationEntries)
+	}
+	for i, r := range rotation {
+		if strings.TrimSpace(r.Track) == "" {
+			return fmt.Errorf("rotation for server '%s': entry %d has an empty track", server, i)
+		}
+		if len([]rune(r.Track)) > rotationTrackMax {
+			return fmt.Errorf("rotation for server '%s': track '%s' is longer than %d characters", server, r.Track, rotationTrackMax)
+		}
+		if _, err := time.Parse(time.RFC3339, r.Start); err != nil {
+			return fmt.Errorf("rotation for server '%s': start %q must be an RFC 3339 time like 2026-10-18T18:00:00Z", server, r.Start)
+		}
+	}
+	return nil
+}
+
+// nextTrack returns the earliest rotation entry starting after now (false if none; config already validated)
+func nextTrack(rotation []TrackRotation, now time.Time) (upcomingTrack, bool) {
+	var next upcomingTrack
+	found := false
+	for _, r := range rotation {
+		at, _ := time.Parse(time.RFC3339, r.Start)
+		if !at.After(now) {
+			continue
+		}
+		if !found || at.Before(next.at) {
+			next, found = upcomingTrack{track: r.Track, at: at}, true
+		}
+	}
+	return next, found
+}
+
+// markRotations sets the next track on poll results of servers with a rotation
+func markRotations(infos []ServerInfo, cfg *Config, now time.Time) []ServerInfo {
+	if len(cfg.Rotations) == 0 {
+		return infos
+	}
+	for i := range infos {
+		if next, ok := nextTrack(cfg.Rotations[infos[i].Name], now); ok {
+			infos[i].NextTrack = &next
+		}
+	}
+	return infos
+}
+
+// appendCountdown appends "in 42 min" for the time until at (minutes rounded up, so a pending start never shows 0)
+func appendCountdown(dst []byte, at, now time.Time) []byte {
+	d := at.Sub(now)
+	if d >= 24*time.Hour {
+		dst = append(dst, "on "...)
+		return at.UTC().AppendFormat(dst, "Jan 2 15:04 MST")
+	}
+	minutes := int64((d + time.Minute - 1) / time.Minute)
+	dst = append(dst, "in "...)
+	if minutes >= 60 {
+		dst = strconv.AppendInt(dst, minutes/60, 10)
+		dst = append(dst, " h "...)
+		minutes %= 60
+	}
+	dst = strconv.AppendInt(dst, minutes, 10)
+	return append(dst, " min"...)
+}
+
+// rotationEditor adapts the config manager to api.RotationEditor
+type rotationEditor struct {
+	cm *ConfigManager
+}
+
+// Rotations implements api.RotationEditor
+func (e *rotationEditor) Rotations() map[string][]api.RotationEntry {
+	out := make(map[string][]api.RotationEntry)
+	cfg := e.cm.GetConfig()
+	if cfg == nil {
+		return out
+	}
+	for server, rotation := range cfg.Rotations {
+		entries := make([]api.RotationEntry, 0, len(rotation))
+		for _, r := range rotation {
+			entries = append(entries, api.RotationEntry{Track: r.Track, Start: r.Start})
+		}
+		out[server] = entries
+	}
+	return out
+}
+
+// SetRotation implements api.RotationEditor: replaces the rotation of server and saves the config
+// An empty rotation removes the server from the rotations section
+func (e *rotationEditor) SetRotation(server string, entries []api.RotationEntry) error {
+	cfg := e.cm.GetConfig()
+	if cfg == nil {
+		return fmt.Errorf("no config loaded")
+	}
+	if !slices.ContainsFunc(cfg.Servers, func(s Server) bool { return s.Name == server }) {
+		return fmt.Errorf("%w '%s'", api.ErrUnknownServer, server)
+	}
+
+	next := cfg.Clone()
+	if next.Rotations == nil {
+		next.Rotations = make(map[string][]TrackRotation)
+	}
+	if len(entries) == 0 {
+		delete(next.Rotations, server)
+	} else {
+		rotation := make([]TrackRotation, 0, len(entries))
+		for _, r := range entries {
+			rotation = append(rotation, TrackRotation{Track: r.Track, Start: r.Start})
+		}
+		next.Rotations[server] = rotation
+	}
+	if len(next.Rotations) == 0 {
+		next.Rotations = nil
+	}
+	return e.cm.WriteConfig(next)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// TestValidateRotations tests the rotations section checks
+func TestValidateRotations(t *testing.T) {
+	cfg := testMaintenanceConfig()
+	valid := map[string][]TrackRotation{"Track #1": {{Track: "Spa", Start: "2026-10-18T18:00:00Z"}}}
+	if err := validateRotations(valid, cfg.Servers); err != nil {
+		t.Fatalf("Expected valid rotations, got %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		rotations map[string][]TrackRotation
+		wantErr   string
+	}{
+		{"unknown server", map[string][]TrackRotation{"Rally #1": {{Track: "Spa", Start: "2026-10-18T18:00:00Z"}}}, "server 'Rally #1'"},
+		{"empty track", map[string][]TrackRotation{"Track #1": {{Track: " ", Start: "2026-10-18T18:00:00Z"}}}, "empty track"},
+		{"bad start", map[string][]TrackRotation{"Track #1": {{Track: "Spa", Start: "18:00"}}}, "RFC 3339"},
+		{"long track", map[string][]TrackRotation{"Track #1": {{Track: strings.Repeat("x", 101), Start: "2026-10-18T18:00:00Z"}}}, "longer than 100"},
+		{"too many", map[string][]TrackRotation{"Track #1": make([]TrackRotation, maxRotationEntries+1)}, "max 50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRotations(tt.rotations, cfg.Servers)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestNextTrack tests that the earliest future entry wins regardless of order
+func TestNextTrack(t *testing.T) {
+	now := time.Date(2026, 10, 18, 17, 0, 0, 0, time.UTC)
+	rotation := []TrackRotation{
+		{Track: "Monza", Start: "2026-10-18T20:00:00Z"},
+		{Track: "Imola", Start: "2026-10-18T16:00:00Z"},
+		{Track: "Spa", Start: "2026-10-18T17:42:00Z"},
+	}
+	next, ok := nextTrack(rotation, now)
+	if !ok || next.track != "Spa" || !next.at.Equal(now.Add(42*time.Minute)) {
+		t.Errorf("Expected Spa at 17:42, got %+v (%v)", next, ok)
+	}
+	if _, ok := nextTrack(rotation, now.Add(3*time.Hour)); ok {
+		t.Error("Expected no next track once every entry has started")
+	}
+}
+
+// TestAppendCountdown tests the countdown text
+func TestAppendCountdown(t *testing.T) {
+	now := time.Date(2026, 10, 18, 17, 0, 0, 0, time.UTC)
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{42 * time.Minute, "in 42 min"},
+		{41*time.Minute + 10*time.Second, "in 42 min"},
+		{20 * time.Second, "in 1 min"},
+		{2*time.Hour + 5*time.Minute, "in 2 h 5 min"},
+		{26 * time.Hour, "on Oct 19 19:00 UTC"},
+	}
+	for _, tt := range tests {
+		if got := string(appendCountdown(nil, now.Add(tt.d), now)); got != tt.want {
+			t.Errorf("appendCountdown(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+// TestMarkRotations tests the next track in the embed and the status JSON
+func TestMarkRotations(t *testing.T) {
+	cfg := testMaintenanceConfig()
+	now := time.Now()
+	cfg.Rotations = map[string][]TrackRotation{
+		"Track #1": {{Track: "Spa", Start: now.Add(42 * time.Minute).UTC().Format(time.RFC3339)}},
+	}
+	infos := markRotations([]ServerInfo{
+		{Name: "Drift #1", Category: "Drift", Map: "ebisu", Players: "2/16", NumPlayers: 2, Port: 8081},
+		{Name: "Track #1", Category: "Track", Map: "monza", Players: "8/20", NumPlayers: 8, Port: 8082},
+	}, cfg, now)
+	if infos[0].NextTrack != nil || infos[1].NextTrack == nil || infos[1].NextTrack.track != "Spa" {
+		t.Fatalf("Expected a next track on Track #1 only, got %+v / %+v", infos[0].NextTrack, infos[1].NextTrack)
+	}
+
+	embed := statusEmbed(infos, cfg, false)
+	found := false
+	for _, f := range embed.Fields {
+		if strings.Contains(f.Name, "Track #1") {
+			found = strings.Contains(f.Value, "**Next:** Spa in 42 min")
+		}
+	}
+	if !found {
+		t.Errorf("Expected the countdown in the embed, got %+v", embed.Fields)
+	}
+	if srv := buildStatusSnapshot(infos, cfg, now).Categories[1].Servers[0]; srv.NextTrack != "Spa" || srv.NextTrackAt == nil {
+		t.Errorf("Expected the next track in the status JSON, got %+v", srv)
+	}
+}
+
+// TestRotationEditor tests saving, reading and removing a rotation through the config manager
+func TestRotationEditor(t *testing.T) {
+	cfg := testMaintenanceConfig()
+	initializeServerIPs(cfg)
+	cm := NewConfigManager(filepath.Join(t.TempDir(), "config.json"), cfg)
+	if err := cm.WriteConfig(cfg); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+	e := &rotationEditor{cm: cm}
+
+	rotation := []api.RotationEntry{{Track: "Spa", Start: "2026-10-18T18:00:00Z"}, {Track: "Monza", Start: "2026-10-18T20:00:00Z"}}
+	if err := e.SetRotation("Track #1", rotation); err != nil {
+		t.Fatalf("SetRotation failed: %v", err)
+	}
+	if got := cm.GetConfig().Rotations["Track #1"]; len(got) != 2 || got[1].Track != "Monza" {
+		t.Errorf("Expected the rotation saved, got %+v", got)
+	}
+	if got := e.Rotations()["Track #1"]; len(got) != 2 || got[0] != rotation[0] {
+		t.Errorf("Expected the rotation listed, got %+v", got)
+	}
+
+	if err := e.SetRotation("Rally #1", rotation); !errors.Is(err, api.ErrUnknownServer) {
+		t.Errorf("Expected ErrUnknownServer, got %v", err)
+	}
+	if err := e.SetRotation("Track #1", []api.RotationEntry{{Track: "Spa", Start: "soon"}}); err == nil {
+		t.Error("Expected a validation error for a bad start")
+	}
+
+	if err := e.SetRotation("Track #1", nil); err != nil {
+		t.Fatalf("Removing the rotation failed: %v", err)
+	}
+	if got := cm.GetConfig().Rotations; got != nil {
+		t.Errorf("Expected the rotations section removed, got %+v", got)
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// ================= SERVERS CSV =================
+
+// serversCSVColumns is the export column order; import matches columns by header name
+// ip is not a column: every server uses the global server_ip
+var serversCSVColumns = []string{"name", "port", "category", "query_type"}
+
+// serversCSVRequired are the columns an import must have
+var serversCSVRequired = []string{"name", "port", "category"}
+
+// csvFormulaPrefixes start cells that spreadsheets evaluate as formulas
+const csvFormulaPrefixes = "=+-@"
+
+// csvEscapeCell prefixes formula-like values with ' so spreadsheets show them as text (CSV injection)
+func csvEscapeCell(v string) string {
+	if v != "" && strings.ContainsRune(csvFormulaPrefixes, rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+// csvUnescapeCell reverses csvEscapeCell so exported files import unchanged
+func csvUnescapeCell(v string) string {
+	if len(v) > 1 && v[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(v[1])) {
+		return v[1:]
+	}
+	return v
+}
+
+// writeServersCSV writes servers with a header row
+func writeServersCSV(w io.Writer, servers []Server) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(serversCSVColumns); err != nil {
+		return err
+	}
+	for _, s := range servers {
+		row := []string{csvEscapeCell(s.Name), strconv.Itoa(s.Port), csvEscapeCell(s.Category), csvEscapeCell(s.QueryType)}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// parseServersCSV reads servers from CSV, checking each row against cfg's categories and the poller registry
+// Row numbers in errors are 1-based file lines (the header is line 1); unknown columns are ignored
+func parseServersCSV(r io.Reader, cfg *Config) ([]Server, []api.CSVRowError, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1 // Spreadsheets often drop trailing empty cells
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("CSV is empty: expected a header row with %s", strings.Join(serversCSVRequired, ","))
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	col := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) // Excel writes a BOM
+		if _, dup := col[name]; !dup {
+			col[name] = i
+		}
+	}
+	for _, req := range serversCSVRequired {
+		if _, ok := col[req]; !ok {
+			return nil, nil, fmt.Errorf("CSV header is missing required column %q", req)
+		}
+	}
+	cell := func(record []string, name string) string {
+		i, ok := col[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return csvUnescapeCell(strings.TrimSpace(record[i]))
+	}
+
+	categories := make(map[string]bool)
+	if cfg != nil {
+		for _, c := range cfg.CategoryOrder {
+			categories[c] = true
+		}
+	}
+
+	var servers []Server
+	var rowErrs []api.CSVRowError
+	seen := make(map[string]int)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				rowErrs = append(rowErrs, api.CSVRowError{Row: perr.Line, Message: perr.Err.Error()})
+				break // Quoting errors make the rest of the file unreliable
+			}
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		// Skip blank lines left by spreadsheets
+		blank := true
+		for _, v := range record {
+			if strings.TrimSpace(v) != "" {
+				blank = false
+				break
+			}
+		}
+		if blank {
+			continue
+		}
+		row, _ := cr.FieldPos(0)
+
+		fail := func(format string, args ...any) {
+			rowErrs = append(rowErrs, api.CSVRowError{Row: row, Message: fmt.Sprintf(format, args...)})
+		}
+
+		s := Server{
+			Name:      cell(record, "name"),
+			Category:  cell(record, "category"),
+			QueryType: cell(record, "query_type"),
+		}
+		if s.Name == "" {
+			fail("name is empty")
+			continue
+		}
+		if first, dup := seen[s.Name]; dup {
+			fail("duplicate server name '%s' (also on row %d)", s.Name, first)
+			continue
+		}
+		seen[s.Name] = row
+
+		port, err := strconv.Atoi(cell(record, "port"))
+		if err != nil || port < 1 || port > 65535 {
+			fail("server '%s' has invalid port %q (valid range: 1-65535)", s.Name, cell(record, "port"))
+			continue
+		}
+		s.Port = port
+
+		if !categories[s.Category] {
+			fail("server '%s' has category '%s' which is not defined in category_order", s.Name, s.Category)
+			continue
+		}
+		if _, ok := lookupPoller(s.QueryType); !ok {
+			fail("server '%s' has unknown query_type '%s' (available: %v)", s.Name, s.QueryType, registeredQueryTypes())
+			continue
+		}
+
+		servers = append(servers, s)
+	}
+	return servers, rowErrs, nil
+}
+
+// diffServers lists server names added, updated and removed going from old to new (matched by name)
+func diffServers(old, new []Server) (added, updated, removed []string) {
+	byName := make(map[string]Server, len(old))
+	for _, s := range old {
+		byName[s.Name] = s
+	}
+	for _, s := range new {
+		prev, ok := byName[s.Name]
+		switch {
+		case !ok:
+			added = append(added, s.Name)
+		case prev.Port != s.Port || prev.Category != s.Category || prev.QueryType != s.QueryType:
+			updated = append(updated, s.Name)
+		}
+		delete(byName, s.Name)
+	}
+	for _, s := range old {
+		if _, gone := byName[s.Name]; gone {
+			removed = append(removed, s.Name)
+		}
+	}
+	return added, updated, removed
+}
+
+// serversCSV adapts ConfigManager to api.ServersCSV
+type serversCSV struct {
+	cm *ConfigManager
+}
+
+// ExportServersCSV implements api.ServersCSV
+func (c *serversCSV) ExportServersCSV(w io.Writer) error {
+	cfg := c.cm.GetConfig()
+	if cfg == nil {
+		return fmt.Errorf("no config loaded")
+	}
+	return writeServersCSV(w, cfg.Servers)
+}
+
+// ImportServersCSV implements api.ServersCSV: replaces the servers array unless dryRun or any row is invalid
+func (c *serversCSV) ImportServersCSV(r io.Reader, dryRun bool) (*api.CSVImportReport, error) {
+	cfg := c.cm.GetConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("no config loaded: create a config before importing servers")
+	}
+
+	servers, rowErrs, err := parseServersCSV(r, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &api.CSVImportReport{
+		DryRun:   dryRun,
+		Servers:  len(servers),
+		Added:    []string{},
+		Updated:  []string{},
+		Removed:  []string{},
+		Errors:   []api.CSVRowError{},
+		Warnings: []api.LintWarning{},
+	}
+	if len(rowErrs) > 0 {
+		report.Errors = rowErrs
+		return report, nil
+	}
+
+	added, updated, removed := diffServers(cfg.Servers, servers)
+	report.Added = append(report.Added, added...)
+	report.Updated = append(report.Updated, updated...)
+	report.Removed = append(report.Removed, removed...)
+
+	next := *cfg
+	next.Servers = servers
+	initializeServerIPs(&next)
+	report.Warnings = append(report.Warnings, lintConfig(context.Background(), &next, nil)...)
+
+	if dryRun {
+		if err := validateConfigStructSafeRuntime(&next); err != nil {
+			report.Errors = append(report.Errors, api.CSVRowError{Message: err.Error()})
+		}
+		return report, nil
+	}
+
+	if err := c.cm.WriteConfig(&next); err != nil {
+		return nil, err
+	}
+	report.Applied = true
+	return report, nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newCSVTestManager writes cfg to a temp config.json and returns its manager
+func newCSVTestManager(t *testing.T) *ConfigManager {
+	t.Helper()
+	cfg := testStatusConfig()
+	cfg.Servers = []Server{
+		{Name: "Drift 1", Port: 8081, Category: "Drift"},
+		{Name: "Track 1", Port: 8082, Category: "Track"},
+		{Name: "=Track 2", Port: 8083, Category: "Track"},
+	}
+	initializeServerIPs(cfg)
+	cm := NewConfigManager(filepath.Join(t.TempDir(), "config.json"), cfg)
+	if err := cm.WriteConfig(cfg); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+	return cm
+}
+
+// TestServersCSV_RoundTrip tests that an exported file imports with no changes
+func TestServersCSV_RoundTrip(t *testing.T) {
+	c := &serversCSV{cm: newCSVTestManager(t)}
+
+	var buf bytes.Buffer
+	if err := c.ExportServersCSV(&buf); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	want := "name,port,category,query_type\nDrift 1,8081,Drift,\nTrack 1,8082,Track,\n'=Track 2,8083,Track,\n"
+	if buf.String() != want {
+		t.Errorf("Export:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	report, err := c.ImportServersCSV(&buf, true)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(report.Errors) != 0 || len(report.Added)+len(report.Updated)+len(report.Removed) != 0 || report.Servers != 3 {
+		t.Errorf("Expected a no-op import of 3 servers, got %+v", report)
+	}
+}
+
+// TestServersCSV_DryRunDoesNotWrite tests the change report and that dry runs leave the config untouched
+func TestServersCSV_DryRunDoesNotWrite(t *testing.T) {
+	cm := newCSVTestManager(t)
+	c := &serversCSV{cm: cm}
+	before, _ := os.ReadFile(cm.configPath)
+
+	csv := "\ufeffName,Port,Category,Notes\nDrift 1,9081,Drift,moved\n\nDrift 2,8084,Drift,\n"
+	report, err := c.ImportServersCSV(strings.NewReader(csv), true)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if report.Applied || !report.DryRun {
+		t.Errorf("Expected dry run not to apply, got %+v", report)
+	}
+	if strings.Join(report.Added, ",") != "Drift 2" || strings.Join(report.Updated, ",") != "Drift 1" ||
+		strings.Join(report.Removed, ",") != "Track 1,=Track 2" {
+		t.Errorf("Unexpected diff: added=%v updated=%v removed=%v", report.Added, report.Updated, report.Removed)
+	}
+	// Track has no servers left
+	if len(report.Warnings) == 0 {
+		t.Error("Expected lint warnings for the emptied category")
+	}
+
+	after, _ := os.ReadFile(cm.configPath)
+	if !bytes.Equal(before, after) {
+		t.Error("Dry run modified config.json")
+	}
+	if len(cm.GetConfig().Servers) != 3 {
+		t.Error("Dry run modified the in-memory config")
+	}
+}
+
+// TestServersCSV_Apply tests that a valid import replaces the servers array
+func TestServersCSV_Apply(t *testing.T) {
+	cm := newCSVTestManager(t)
+	c := &serversCSV{cm: cm}
+
+	report, err := c.ImportServersCSV(strings.NewReader("name,port,category\nNew,8090,Track\n"), false)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !report.Applied {
+		t.Fatalf("Expected import to be applied, got %+v", report)
+	}
+	servers := cm.GetConfig().Servers
+	if len(servers) != 1 || servers[0].Name != "New" || servers[0].IP != "203.0.113.10" {
+		t.Errorf("Unexpected servers after import: %+v", servers)
+	}
+}
+
+// TestServersCSV_RowErrors tests that invalid rows are reported with line numbers and nothing is saved
+func TestServersCSV_RowErrors(t *testing.T) {
+	cm := newCSVTestManager(t)
+	c := &serversCSV{cm: cm}
+
+	csv := strings.Join([]string{
+		"name,port,category,query_type",
+		"Good,8081,Drift,",
+		",8082,Drift,",
+		"Bad Port,99999,Drift,",
+		"Bad Cat,8083,Rally,",
+		"Good,8084,Drift,",
+		"Bad Type,8085,Drift,gamespy",
+	}, "\n")
+	report, err := c.ImportServersCSV(strings.NewReader(csv), false)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if report.Applied {
+		t.Error("Expected import with errors not to be applied")
+	}
+
+	var rows []int
+	for _, e := range report.Errors {
+		rows = append(rows, e.Row)
+	}
+	want := []int{3, 4, 5, 6, 7}
+	if len(rows) != len(want) {
+		t.Fatalf("Error rows = %v, want %v (%+v)", rows, want, report.Errors)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("Error rows = %v, want %v", rows, want)
+			break
+		}
+	}
+	if len(cm.GetConfig().Servers) != 3 {
+		t.Error("Import with errors modified the config")
+	}
+}
+
+// TestServersCSV_BadFiles tests files that cannot be processed at all
+func TestServersCSV_BadFiles(t *testing.T) {
+	c := &serversCSV{cm: newCSVTestManager(t)}
+
+	for name, csv := range map[string]string{
+		"empty":          "",
+		"missing column": "name,category\nDrift 1,Drift\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := c.ImportServersCSV(strings.NewReader(csv), true); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	// A quoting error is a row error, not a failure
+	report, err := c.ImportServersCSV(strings.NewReader("name,port,category\n\"Broken,8081,Drift\n"), true)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(report.Errors) != 1 {
+		t.Errorf("Expected one parse error, got %+v", report.Errors)
+	}
+}
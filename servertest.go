@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// ================= SERVER TEST =================
+
+// probeServer polls one server with its registered poller and measures the round trip
+// The poll is bounded by both ctx and the client timeout
+func probeServer(ctx context.Context, client *http.Client, server Server) (PollResult, time.Duration, error) {
+	poller, ok := lookupPoller(server.QueryType)
+	if !ok {
+		return PollResult{}, 0, fmt.Errorf("unknown query_type '%s'", server.QueryType)
+	}
+	ctx, cancel := context.WithTimeout(ctx, client.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := poller.Poll(ctx, client, server)
+	return result, time.Since(start), err
+}
+
+// serverTester adapts the poller registry to api.ServerTester
+type serverTester struct {
+	cm *ConfigManager
+}
+
+// TestServer decodes a server entry, fills in server_ip from the current config if no ip is given,
+// and polls it once with the shared polling client
+func (t *serverTester) TestServer(ctx context.Context, raw map[string]interface{}) (*api.ServerTestResult, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode server: %w", err)
+	}
+	var server Server
+	if err := json.Unmarshal(data, &server); err != nil {
+		return nil, fmt.Errorf("invalid server definition: %w", err)
+	}
+
+	cfg := t.cm.GetConfig()
+	if server.IP == "" && cfg != nil {
+		server.IP = cfg.ServerIP
+	}
+	if server.IP == "" {
+		return nil, fmt.Errorf("ip is required when no config is loaded")
+	}
+	if server.Port < 1 || server.Port > 65535 {
+		return nil, fmt.Errorf("invalid port: %d (valid range: 1-65535)", server.Port)
+	}
+	if err := validateQueryType(server); err != nil {
+		return nil, err
+	}
+
+	var settings *HTTPClientConfig
+	if cfg != nil {
+		settings = cfg.HTTPClient
+	}
+	result, latency, err := probeServer(ctx, pollClients.Get(settings), server)
+
+	out := &api.ServerTestResult{LatencyMS: latency.Milliseconds()}
+	if err != nil {
+		out.Error = err.Error()
+		return out, nil
+	}
+	out.Reachable = true
+	out.Map = result.Map
+	out.Players = result.Players
+	out.MaxPlayers = result.MaxPlayers
+	return out, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newACInfoServer starts an AC /info endpoint and returns its host and port
+func newACInfoServer(t *testing.T, body string) (string, int, *httptest.Server) {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(ts.Close)
+	host, portStr, _ := strings.Cut(strings.TrimPrefix(ts.URL, "http://"), ":")
+	port, _ := strconv.Atoi(portStr)
+	return host, port, ts
+}
+
+// TestServerTester_Reachable tests a live poll using server_ip from the current config
+func TestServerTester_Reachable(t *testing.T) {
+	host, port, _ := newACInfoServer(t, `{"track":"content/tracks/spa","clients":5,"maxclients":24}`)
+	cfg := testStatusConfig()
+	cfg.ServerIP = host
+	tester := &serverTester{cm: NewConfigManager("/nonexistent/config.json", cfg)}
+
+	res, err := tester.TestServer(context.Background(), map[string]interface{}{"name": "GT3", "port": port, "category": "Track"})
+	if err != nil {
+		t.Fatalf("TestServer failed: %v", err)
+	}
+	if !res.Reachable || res.Map != "spa" || res.Players != 5 || res.MaxPlayers != 24 || res.Error != "" {
+		t.Errorf("Unexpected result: %+v", res)
+	}
+	if res.LatencyMS < 0 {
+		t.Errorf("Expected non-negative latency, got %d", res.LatencyMS)
+	}
+}
+
+// TestServerTester_Unreachable tests that poll failures are reported in the result, not as errors
+func TestServerTester_Unreachable(t *testing.T) {
+	host, port, ts := newACInfoServer(t, `{}`)
+	ts.Close()
+	tester := &serverTester{cm: NewConfigManager("/nonexistent/config.json", nil)}
+
+	res, err := tester.TestServer(context.Background(), map[string]interface{}{"ip": host, "port": port})
+	if err != nil {
+		t.Fatalf("TestServer failed: %v", err)
+	}
+	if res.Reachable || res.Error == "" {
+		t.Errorf("Expected unreachable result with error, got %+v", res)
+	}
+}
+
+// TestServerTester_InvalidDefinitions tests that bad definitions are rejected before polling
+func TestServerTester_InvalidDefinitions(t *testing.T) {
+	withConfig := &serverTester{cm: NewConfigManager("/nonexistent/config.json", testStatusConfig())}
+	noConfig := &serverTester{cm: NewConfigManager("/nonexistent/config.json", nil)}
+
+	tests := []struct {
+		name   string
+		tester *serverTester
+		server map[string]interface{}
+		want   string
+	}{
+		{"port out of range", withConfig, map[string]interface{}{"port": 70000}, "invalid port"},
+		{"missing port", withConfig, map[string]interface{}{"name": "x"}, "invalid port"},
+		{"wrong type", withConfig, map[string]interface{}{"port": "8081"}, "invalid server definition"},
+		{"unknown query_type", withConfig, map[string]interface{}{"port": 8081, "query_type": "nope"}, "unknown query_type"},
+		{"no ip without config", noConfig, map[string]interface{}{"port": 8081}, "ip is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.tester.TestServer(context.Background(), tt.server)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
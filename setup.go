@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// ================= SETUP MODE =================
+
+// setupWizard assembles the initial config through the /api/setup endpoints
+// Used when the bot starts without config.json; the update loop idles until Complete writes the file
+type setupWizard struct {
+	cm *ConfigManager
+
+	mu    sync.Mutex
+	draft Config
+}
+
+// newSetupWizard creates a wizard with an empty draft using the default update interval
+func newSetupWizard(cm *ConfigManager) *setupWizard {
+	return &setupWizard{
+		cm:    cm,
+		draft: Config{UpdateInterval: int(defaultUpdateInterval.Seconds())},
+	}
+}
+
+// done reports whether a config exists (written by Complete, the API or by hand)
+func (w *setupWizard) done() bool {
+	return w.cm.GetConfig() != nil
+}
+
+// missingLocked lists the steps still required (caller holds w.mu)
+func (w *setupWizard) missingLocked() []string {
+	missing := []string{}
+	if w.draft.ServerIP == "" {
+		missing = append(missing, "server_ip")
+	}
+	if len(w.draft.CategoryOrder) == 0 {
+		missing = append(missing, "categories")
+	}
+	if len(w.draft.Servers) == 0 {
+		missing = append(missing, "servers")
+	}
+	return missing
+}
+
+// SetupStatus implements api.SetupWizard
+func (w *setupWizard) SetupStatus() api.SetupStatus {
+	if cfg := w.cm.GetConfig(); cfg != nil {
+		return api.SetupStatus{Required: false, Draft: cfg.Clone(), Missing: []string{}}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	draft := w.draft.Clone()
+	if draft.CategoryOrder == nil {
+		draft.CategoryOrder = []string{}
+	}
+	return api.SetupStatus{Required: true, Draft: draft, Missing: w.missingLocked()}
+}
+
+// SetServerIP implements api.SetupWizard
+func (w *setupWizard) SetServerIP(ip string) error {
+	if w.done() {
+		return api.ErrSetupComplete
+	}
+	ip = strings.TrimSpace(ip)
+	if ip == "" {
+		return fmt.Errorf("server_ip cannot be empty")
+	}
+	if strings.ContainsAny(ip, " /:") {
+		return fmt.Errorf("server_ip must be a bare IP address or hostname (no scheme, port or path), got %q", ip)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.draft.ServerIP = ip
+	for i := range w.draft.Servers {
+		w.draft.Servers[i].IP = ip
+	}
+	return nil
+}
+
+// SetCategories implements api.SetupWizard
+// Replacing categories drops draft servers whose category no longer exists
+func (w *setupWizard) SetCategories(order []string, emojis map[string]string) error {
+	if w.done() {
+		return api.ErrSetupComplete
+	}
+	if len(order) == 0 {
+		return fmt.Errorf("category_order cannot be empty")
+	}
+	seen := make(map[string]bool, len(order))
+	for _, cat := range order {
+		if strings.TrimSpace(cat) == "" {
+			return fmt.Errorf("category names cannot be empty")
+		}
+		if seen[cat] {
+			return fmt.Errorf("category '%s' is listed twice", cat)
+		}
+		seen[cat] = true
+		if emojis[cat] == "" {
+			return fmt.Errorf("category '%s' is missing an emoji in category_emojis", cat)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.draft.CategoryOrder = append([]string{}, order...)
+	w.draft.CategoryEmojis = make(map[string]string, len(order))
+	for _, cat := range order {
+		w.draft.CategoryEmojis[cat] = emojis[cat]
+	}
+
+	kept := w.draft.Servers[:0]
+	for _, s := range w.draft.Servers {
+		if seen[s.Category] {
+			kept = append(kept, s)
+		} else {
+			log.Printf("Setup: dropped server '%s' (category '%s' no longer exists)", s.Name, s.Category)
+		}
+	}
+	w.draft.Servers = kept
+	return nil
+}
+
+// AddServer implements api.SetupWizard: categories must be set first so the category can be checked
+func (w *setupWizard) AddServer(raw map[string]interface{}) error {
+	if w.done() {
+		return api.ErrSetupComplete
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("failed to encode server: %w", err)
+	}
+	var server Server
+	if err := json.Unmarshal(data, &server); err != nil {
+		return fmt.Errorf("invalid server definition: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.draft.CategoryOrder) == 0 {
+		return fmt.Errorf("set categories before adding servers")
+	}
+	if server.Name == "" {
+		return fmt.Errorf("server name cannot be empty")
+	}
+	for _, s := range w.draft.Servers {
+		if s.Name == server.Name {
+			return fmt.Errorf("server '%s' already exists", server.Name)
+		}
+	}
+	if server.Port < 1 || server.Port > 65535 {
+		return fmt.Errorf("server '%s' has invalid port: %d (valid range: 1-65535)", server.Name, server.Port)
+	}
+	if _, ok := w.draft.CategoryEmojis[server.Category]; !ok {
+		return fmt.Errorf("server '%s' has category '%s' which is not defined in category_order", server.Name, server.Category)
+	}
+	if err := validateQueryType(server); err != nil {
+		return err
+	}
+
+	server.IP = w.draft.ServerIP
+	w.draft.Servers = append(w.draft.Servers, server)
+	return nil
+}
+
+// Complete implements api.SetupWizard: validates the draft and writes config.json
+// The ConfigManager change listener then triggers the first status update right away
+func (w *setupWizard) Complete() error {
+	if w.done() {
+		return api.ErrSetupComplete
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if missing := w.missingLocked(); len(missing) > 0 {
+		return fmt.Errorf("setup incomplete, missing: %s", strings.Join(missing, ", "))
+	}
+
+	// WriteConfig stores its own copy, so the draft can be written as is
+	cfg := &w.draft
+	if err := w.cm.WriteConfig(cfg); err != nil {
+		return err
+	}
+	log.Printf("Setup complete: wrote %s with %d servers, starting normal operation", w.cm.configPath, len(cfg.Servers))
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// newSetupTestBot returns a bot without config and a wizard writing to a temp config path
+func newSetupTestBot(t *testing.T) (*Bot, *setupWizard) {
+	t.Helper()
+	b := newTestBot(nil)
+	b.configManager = NewConfigManager(filepath.Join(t.TempDir(), "config.json"), nil)
+	b.refresh = make(chan struct{}, 1)
+	b.configManager.SetOnChange(b.onConfigChange)
+	return b, newSetupWizard(b.configManager)
+}
+
+// TestSetupWizard_FullFlow tests the guided steps, the written config and the switch to normal operation
+func TestSetupWizard_FullFlow(t *testing.T) {
+	b, w := newSetupTestBot(t)
+
+	status := w.SetupStatus()
+	if !status.Required || strings.Join(status.Missing, ",") != "server_ip,categories,servers" {
+		t.Fatalf("Unexpected initial status: %+v", status)
+	}
+
+	if err := w.SetServerIP("203.0.113.10"); err != nil {
+		t.Fatalf("SetServerIP failed: %v", err)
+	}
+	if err := w.SetCategories([]string{"GT3", "Drift"}, map[string]string{"GT3": "🏎️", "Drift": "🟣"}); err != nil {
+		t.Fatalf("SetCategories failed: %v", err)
+	}
+	if err := w.AddServer(map[string]interface{}{"name": "GT3 #1", "port": 8081, "category": "GT3"}); err != nil {
+		t.Fatalf("AddServer failed: %v", err)
+	}
+	if missing := w.SetupStatus().Missing; len(missing) != 0 {
+		t.Fatalf("Expected no missing steps, got %v", missing)
+	}
+
+	if err := w.Complete(); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	cfg := b.configManager.GetConfig()
+	if cfg == nil || cfg.ServerIP != "203.0.113.10" || cfg.UpdateInterval != 30 || len(cfg.Servers) != 1 || cfg.Servers[0].IP != "203.0.113.10" {
+		t.Fatalf("Unexpected config after setup: %+v", cfg)
+	}
+	if _, err := os.Stat(b.configManager.configPath); err != nil {
+		t.Errorf("Expected config file to be written: %v", err)
+	}
+	if len(b.refresh) != 1 {
+		t.Error("Expected the first status update to be requested right away")
+	}
+
+	// Setup is closed once a config exists
+	if w.SetupStatus().Required {
+		t.Error("Expected setup_required=false after completion")
+	}
+	for name, err := range map[string]error{
+		"SetServerIP":   w.SetServerIP("198.51.100.1"),
+		"SetCategories": w.SetCategories([]string{"X"}, map[string]string{"X": "x"}),
+		"AddServer":     w.AddServer(map[string]interface{}{"name": "x", "port": 1, "category": "X"}),
+		"Complete":      w.Complete(),
+	} {
+		if !errors.Is(err, api.ErrSetupComplete) {
+			t.Errorf("%s after completion: got %v, want ErrSetupComplete", name, err)
+		}
+	}
+}
+
+// TestSetupWizard_Validation tests rejected steps
+func TestSetupWizard_Validation(t *testing.T) {
+	_, w := newSetupTestBot(t)
+
+	if err := w.AddServer(map[string]interface{}{"name": "a", "port": 8081, "category": "GT3"}); err == nil || !strings.Contains(err.Error(), "categories before") {
+		t.Errorf("Expected categories-first error, got %v", err)
+	}
+	if err := w.Complete(); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected incomplete error, got %v", err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"empty server_ip", w.SetServerIP("  ")},
+		{"server_ip with scheme", w.SetServerIP("http://example.com")},
+		{"no categories", w.SetCategories(nil, nil)},
+		{"duplicate category", w.SetCategories([]string{"GT3", "GT3"}, map[string]string{"GT3": "🏎️"})},
+		{"missing emoji", w.SetCategories([]string{"GT3"}, map[string]string{})},
+	}
+	for _, tt := range tests {
+		if tt.err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	if err := w.SetCategories([]string{"GT3"}, map[string]string{"GT3": "🏎️"}); err != nil {
+		t.Fatalf("SetCategories failed: %v", err)
+	}
+	for name, server := range map[string]map[string]interface{}{
+		"empty name":       {"port": 8081, "category": "GT3"},
+		"bad port":         {"name": "a", "port": 0, "category": "GT3"},
+		"unknown category": {"name": "a", "port": 8081, "category": "Rally"},
+		"unknown type":     {"name": "a", "port": 8081, "category": "GT3", "query_type": "nope"},
+		"wrong field type": {"name": "a", "port": "8081", "category": "GT3"},
+	} {
+		if err := w.AddServer(server); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if err := w.AddServer(map[string]interface{}{"name": "a", "port": 8081, "category": "GT3"}); err != nil {
+		t.Fatalf("AddServer failed: %v", err)
+	}
+	if err := w.AddServer(map[string]interface{}{"name": "a", "port": 8082, "category": "GT3"}); err == nil {
+		t.Error("Expected duplicate name to be rejected")
+	}
+}
+
+// TestSetupWizard_CategoryChangeDropsServers tests that servers of removed categories leave the draft
+func TestSetupWizard_CategoryChangeDropsServers(t *testing.T) {
+	_, w := newSetupTestBot(t)
+
+	w.SetCategories([]string{"GT3", "Drift"}, map[string]string{"GT3": "🏎️", "Drift": "🟣"})
+	w.AddServer(map[string]interface{}{"name": "GT3 #1", "port": 8081, "category": "GT3"})
+	w.AddServer(map[string]interface{}{"name": "Drift #1", "port": 8082, "category": "Drift"})
+
+	if err := w.SetCategories([]string{"GT3"}, map[string]string{"GT3": "🏎️"}); err != nil {
+		t.Fatalf("SetCategories failed: %v", err)
+	}
+	draft := w.SetupStatus().Draft.(*Config)
+	if len(draft.Servers) != 1 || draft.Servers[0].Name != "GT3 #1" {
+		t.Errorf("Expected only GT3 #1 to remain, got %+v", draft.Servers)
+	}
+}
+
+// TestSetupWizard_ConfigCreatedElsewhere tests that a config written by hand ends setup mode
+func TestSetupWizard_ConfigCreatedElsewhere(t *testing.T) {
+	b, w := newSetupTestBot(t)
+	cfg := testStatusConfig()
+	if err := b.configManager.WriteConfig(cfg); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+	if w.SetupStatus().Required {
+		t.Error("Expected setup to end when a config appears")
+	}
+	if !errors.Is(w.SetServerIP("203.0.113.10"), api.ErrSetupComplete) {
+		t.Error("Expected ErrSetupComplete")
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// ================= POLL SHARDING =================
+
+// shardStaleIntervals is how many update intervals another shard's results stay usable
+// Older results (shard down or stuck) show those servers as offline instead of frozen
+const shardStaleIntervals = 3
+
+// shardResult is one instance's poll results in the shared store (POLL_SHARD_DIR/shard-<index>.json)
+type shardResult struct {
+	Shard     int          `json:"shard"`
+	Count     int          `json:"count"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Servers   []ServerInfo `json:"servers"`
+}
+
+// PollSharding splits the server list across instances: each polls its own shard and publishes the
+// results to a shared directory, and every instance merges all shards so the leader posts the full list
+type PollSharding struct {
+	index int
+	count int
+	dir   string
+}
+
+// shardOf assigns a server to a shard by name hash, so reordering or adding servers moves as few as possible
+func shardOf(name string, count int) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int(h.Sum32() % uint32(count))
+}
+
+// path returns the shared store file of shard i
+func (s *PollSharding) path(i int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("shard-%d.json", i))
+}
+
+// own returns a copy of cfg limited to the servers assigned to this instance
+func (s *PollSharding) own(cfg *Config) *Config {
+	mine := *cfg
+	mine.Servers = nil
+	for _, server := range cfg.Servers {
+		if shardOf(server.Name, s.count) == s.index {
+			mine.Servers = append(mine.Servers, server)
+		}
+	}
+	return &mine
+}
+
+// publish writes this shard's results to the shared store
+func (s *PollSharding) publish(infos []ServerInfo, now time.Time) error {
+	data, err := json.Marshal(shardResult{Shard: s.index, Count: s.count, UpdatedAt: now, Servers: infos})
+	if err != nil {
+		return fmt.Errorf("failed to encode shard results: %w", err)
+	}
+	return writeFileAtomic(s.path(s.index), data)
+}
+
+// load reads shard i's results, returning nil (with a logged reason) when missing, foreign or stale
+func (s *PollSharding) load(i int, now time.Time, maxAge time.Duration) *shardResult {
+	data, err := os.ReadFile(s.path(i))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Poll sharding: failed to read shard %d results: %v", i, err)
+		} else {
+			log.Printf("Poll sharding: no results from shard %d yet, showing its servers offline", i)
+		}
+		return nil
+	}
+	var res shardResult
+	if err := json.Unmarshal(data, &res); err != nil {
+		log.Printf("Poll sharding: invalid shard %d results: %v", i, err)
+		return nil
+	}
+	if res.Count != s.count {
+		log.Printf("Poll sharding: shard %d results were written for %d shards (we use %d), ignoring", i, res.Count, s.count)
+		return nil
+	}
+	if age := now.Sub(res.UpdatedAt); age > maxAge {
+		log.Printf("Poll sharding: shard %d results are %v old, showing its servers offline", i, age.Round(time.Second))
+		return nil
+	}
+	return &res
+}
+
+// merge combines this shard's fresh results with the other shards' stored results, in cfg.Servers order
+// Servers without a usable result (other shard down, stale, or not yet polled) are reported offline
+func (s *PollSharding) merge(cfg *Config, own []ServerInfo, now time.Time) []ServerInfo {
+	type key struct {
+		name string
+		port int
+	}
+	results := make(map[key]ServerInfo, len(cfg.Servers))
+	for _, info := range own {
+		results[key{info.Name, info.Port}] = info
+	}
+
+	maxAge := time.Duration(shardStaleIntervals*cfg.UpdateInterval) * time.Second
+	for i := 0; i < s.count; i++ {
+		if i == s.index {
+			continue
+		}
+		if res := s.load(i, now, maxAge); res != nil {
+			for _, info := range res.Servers {
+				results[key{info.Name, info.Port}] = info
+			}
+		}
+	}
+
+	infos := make([]ServerInfo, len(cfg.Servers))
+	for i, server := range cfg.Servers {
+		info, ok := results[key{server.Name, server.Port}]
+		if !ok {
+			info = offlineServerInfo(server)
+		}
+		// The current config decides grouping, even if a shard polled with an older config
+		info.Category = server.Category
+		info.IP = server.IP
+		infos[i] = info
+	}
+	return infos
+}
+
+// poll polls this instance's shard, publishes it and returns the merged list for all servers
+func (s *PollSharding) poll(cfg *Config) []ServerInfo {
+	now := time.Now()
+	own := fetchAllServers(s.own(cfg))
+	if err := s.publish(own, now); err != nil {
+		log.Printf("Poll sharding: failed to publish shard %d results: %v", s.index, err)
+	}
+	return s.merge(cfg, own, now)
+}
+
+// pollServers polls every configured server, or only this instance's shard when sharding is enabled
+func (b *Bot) pollServers(cfg *Config) []ServerInfo {
+	if b.shards == nil {
+		return fetchAllServers(cfg)
+	}
+	return b.shards.poll(cfg)
+}
+
+// pollShardingFromEnv returns sharding if POLL_SHARD_COUNT > 1, nil otherwise
+// POLL_SHARD_INDEX (0-based) and POLL_SHARD_DIR (shared volume) are then required; leader election
+// must be enabled so only one instance publishes the merged result
+func pollShardingFromEnv(leaderElection bool) (*PollSharding, error) {
+	countStr := os.Getenv("POLL_SHARD_COUNT")
+	if countStr == "" || countStr == "1" {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("invalid POLL_SHARD_COUNT %q: must be a positive number", countStr)
+	}
+
+	if !leaderElection {
+		return nil, fmt.Errorf("POLL_SHARD_COUNT requires LEADER_LOCK_FILE so only one instance publishes the merged status")
+	}
+
+	index, err := strconv.Atoi(os.Getenv("POLL_SHARD_INDEX"))
+	if err != nil || index < 0 || index >= count {
+		return nil, fmt.Errorf("invalid POLL_SHARD_INDEX %q: must be 0-%d", os.Getenv("POLL_SHARD_INDEX"), count-1)
+	}
+
+	dir := os.Getenv("POLL_SHARD_DIR")
+	if dir == "" {
+		return nil, fmt.Errorf("POLL_SHARD_DIR is required when POLL_SHARD_COUNT is set (a directory shared by all instances)")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create POLL_SHARD_DIR %s: %w", dir, err)
+	}
+
+	log.Printf("Poll sharding enabled: instance %d of %d, shared results in %s", index, count, dir)
+	return &PollSharding{index: index, count: count, dir: dir}, nil
+}
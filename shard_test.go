@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// shardTestConfig returns a config with n servers in the Drift category
+func shardTestConfig(n int) *Config {
+	cfg := testStatusConfig()
+	cfg.Servers = nil
+	for i := 0; i < n; i++ {
+		cfg.Servers = append(cfg.Servers, Server{Name: fmt.Sprintf("Drift %d", i), IP: "203.0.113.10", Port: 8081 + i, Category: "Drift"})
+	}
+	return cfg
+}
+
+// onlineInfo returns a poll result marking server online with the given player count
+func onlineInfo(s Server, players int) ServerInfo {
+	return ServerInfo{Name: s.Name, Category: s.Category, Map: "spa", Players: fmt.Sprintf("%d/24", players), NumPlayers: players, MaxPlayers: 24, IP: s.IP, Port: s.Port}
+}
+
+// TestShardOf tests that assignment is deterministic and every server belongs to exactly one shard
+func TestShardOf(t *testing.T) {
+	cfg := shardTestConfig(50)
+	shards := []*PollSharding{{index: 0, count: 3}, {index: 1, count: 3}, {index: 2, count: 3}}
+
+	total := 0
+	for _, s := range shards {
+		n := len(s.own(cfg).Servers)
+		if n == 0 {
+			t.Errorf("Shard %d got no servers out of 50", s.index)
+		}
+		total += n
+	}
+	if total != 50 {
+		t.Errorf("Shards cover %d servers, want 50", total)
+	}
+	if shardOf("Drift 7", 3) != shardOf("Drift 7", 3) {
+		t.Error("Expected deterministic assignment")
+	}
+}
+
+// TestPollSharding_Merge tests merging fresh, stale and foreign shard results
+func TestPollSharding_Merge(t *testing.T) {
+	dir := t.TempDir()
+	cfg := shardTestConfig(10)
+	a := &PollSharding{index: 0, count: 2, dir: dir}
+	b := &PollSharding{index: 1, count: 2, dir: dir}
+	now := time.Now()
+
+	var ownA, ownB []ServerInfo
+	for _, s := range a.own(cfg).Servers {
+		ownA = append(ownA, onlineInfo(s, 1))
+	}
+	for _, s := range b.own(cfg).Servers {
+		ownB = append(ownB, onlineInfo(s, 2))
+	}
+
+	// Shard 1 has not published yet: its servers are offline
+	merged := a.merge(cfg, ownA, now)
+	if len(merged) != 10 {
+		t.Fatalf("Merged %d servers, want 10", len(merged))
+	}
+	for i, info := range merged {
+		if info.Name != cfg.Servers[i].Name {
+			t.Fatalf("Merged order differs from config at %d: %s", i, info.Name)
+		}
+		wantOnline := shardOf(info.Name, 2) == 0
+		if (info.NumPlayers >= 0) != wantOnline {
+			t.Errorf("%s online=%v, want %v", info.Name, info.NumPlayers >= 0, wantOnline)
+		}
+	}
+
+	// Fresh results from shard 1 fill in the rest
+	if err := b.publish(ownB, now); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	for _, info := range a.merge(cfg, ownA, now) {
+		want := 1
+		if shardOf(info.Name, 2) == 1 {
+			want = 2
+		}
+		if info.NumPlayers != want {
+			t.Errorf("%s players=%d, want %d", info.Name, info.NumPlayers, want)
+		}
+	}
+
+	// Stale results count as offline
+	later := now.Add(time.Duration(shardStaleIntervals*cfg.UpdateInterval+1) * time.Second)
+	for _, info := range a.merge(cfg, ownA, later) {
+		if shardOf(info.Name, 2) == 1 && info.NumPlayers != -1 {
+			t.Errorf("%s should be offline with stale shard results", info.Name)
+		}
+	}
+
+	// Results written for another shard count are ignored
+	other := &PollSharding{index: 1, count: 3, dir: dir}
+	other.publish(ownB, now)
+	for _, info := range a.merge(cfg, ownA, now) {
+		if shardOf(info.Name, 2) == 1 && info.NumPlayers != -1 {
+			t.Errorf("%s should ignore results from a different shard count", info.Name)
+		}
+	}
+}
+
+// TestPollSharding_MergeUsesCurrentCategory tests that a recategorized server is grouped by the current config
+func TestPollSharding_MergeUsesCurrentCategory(t *testing.T) {
+	dir := t.TempDir()
+	cfg := shardTestConfig(4)
+	a := &PollSharding{index: 0, count: 2, dir: dir}
+	b := &PollSharding{index: 1, count: 2, dir: dir}
+
+	var ownB []ServerInfo
+	for _, s := range b.own(cfg).Servers {
+		ownB = append(ownB, onlineInfo(s, 2))
+	}
+	b.publish(ownB, time.Now())
+
+	for i := range cfg.Servers {
+		cfg.Servers[i].Category = "Track"
+	}
+	for _, info := range a.merge(cfg, nil, time.Now()) {
+		if info.Category != "Track" {
+			t.Errorf("%s category=%s, want Track", info.Name, info.Category)
+		}
+	}
+}
+
+// TestPollSharding_Poll tests that an instance only polls its own servers and publishes them
+func TestPollSharding_Poll(t *testing.T) {
+	host, port, _ := newACInfoServer(t, `{"track":"spa","clients":3,"maxclients":24}`)
+	cfg := testStatusConfig()
+	cfg.ServerIP = host
+	cfg.Servers = nil
+	for i := 0; i < 6; i++ {
+		cfg.Servers = append(cfg.Servers, Server{Name: fmt.Sprintf("Drift %d", i), IP: host, Port: port, Category: "Drift"})
+	}
+
+	s := &PollSharding{index: 0, count: 2, dir: t.TempDir()}
+	infos := s.poll(cfg)
+	for _, info := range infos {
+		mine := shardOf(info.Name, 2) == 0
+		if mine && info.NumPlayers != 3 {
+			t.Errorf("%s: own server not polled (players=%d)", info.Name, info.NumPlayers)
+		}
+		if !mine && info.NumPlayers != -1 {
+			t.Errorf("%s: other shard's server should be offline without results", info.Name)
+		}
+	}
+
+	res := s.load(0, time.Now(), time.Minute)
+	if res == nil || len(res.Servers) != len(s.own(cfg).Servers) {
+		t.Errorf("Expected own results in the shared store, got %+v", res)
+	}
+}
+
+// TestPollShardingFromEnv tests env parsing and required settings
+func TestPollShardingFromEnv(t *testing.T) {
+	t.Setenv("POLL_SHARD_COUNT", "")
+	if s, err := pollShardingFromEnv(true); s != nil || err != nil {
+		t.Errorf("Expected sharding disabled, got %v %v", s, err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "shards")
+	t.Setenv("POLL_SHARD_COUNT", "3")
+	t.Setenv("POLL_SHARD_INDEX", "2")
+	t.Setenv("POLL_SHARD_DIR", dir)
+	s, err := pollShardingFromEnv(true)
+	if err != nil || s == nil || s.index != 2 || s.count != 3 {
+		t.Fatalf("Unexpected sharding %+v, err %v", s, err)
+	}
+
+	tests := []struct {
+		name, count, index, dir string
+		leader                  bool
+		wantErr                 string
+	}{
+		{"no leader election", "3", "0", dir, false, "LEADER_LOCK_FILE"},
+		{"bad count", "many", "0", dir, true, "POLL_SHARD_COUNT"},
+		{"index out of range", "3", "3", dir, true, "POLL_SHARD_INDEX"},
+		{"missing index", "3", "", dir, true, "POLL_SHARD_INDEX"},
+		{"missing dir", "3", "0", "", true, "POLL_SHARD_DIR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("POLL_SHARD_COUNT", tt.count)
+			t.Setenv("POLL_SHARD_INDEX", tt.index)
+			t.Setenv("POLL_SHARD_DIR", tt.dir)
+			if _, err := pollShardingFromEnv(tt.leader); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
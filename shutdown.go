@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/drain"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= GRACEFUL SHUTDOWN =================
+
+// shutdownGrace is the time WaitForShutdown allows on top of the drain timeout for components to exit
+const shutdownGrace = 5 * time.Second
+
+// offlineColor is the embed color of the final status posted on shutdown (grey)
+const offlineColor = 0x808080
+
+// drainTimeoutFromEnv returns SHUTDOWN_DRAIN_TIMEOUT (Go duration), or drain.DefaultTimeout when unset
+// It bounds how long the API and proxy servers wait for in-flight requests on shutdown
+func drainTimeoutFromEnv() (time.Duration, error) {
+	v := os.Getenv("SHUTDOWN_DRAIN_TIMEOUT")
+	if v == "" {
+		return drain.DefaultTimeout, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < time.Second {
+		return 0, fmt.Errorf("invalid SHUTDOWN_DRAIN_TIMEOUT %q: use a duration of at least 1s (e.g. 30s)", v)
+	}
+	return d, nil
+}
+
+// setDrainTimeout applies the shutdown drain timeout to the API and proxy servers
+func (b *Bot) setDrainTimeout(d time.Duration) {
+	b.drainTimeout = d
+	if b.apiServer != nil {
+		b.apiServer.SetDrainTimeout(d)
+	}
+	if b.proxyServer != nil {
+		b.proxyServer.SetDrainTimeout(d)
+	}
+}
+
+// shutdownTimeout bounds how long WaitForShutdown waits for components (API/proxy drain plus a grace period)
+func (b *Bot) shutdownTimeout() time.Duration {
+	d := b.drainTimeout
+	if d <= 0 {
+		d = drain.DefaultTimeout
+	}
+	return d + shutdownGrace
+}
+
+// Offline status modes (OFFLINE_STATUS) for the final update posted on shutdown
+const (
+	// offlineModeNotice replaces the server list with a clear offline notice (default)
+	offlineModeNotice = "notice"
+	// offlineModeFooter keeps the last known server list and marks it offline in the footer
+	offlineModeFooter = "footer"
+	// offlineModeOff leaves the status message untouched
+	offlineModeOff = "off"
+)
+
+// defaultOfflineMessage is the offline notice text; the timestamp is appended ("... since <time> UTC")
+const defaultOfflineMessage = "Status bot offline"
+
+// offlineStatus configures the final update posted on shutdown
+type offlineStatus struct {
+	mode    string
+	message string
+}
+
+// enabled reports whether an offline status is posted (the zero value posts nothing)
+func (o offlineStatus) enabled() bool {
+	return o.mode != "" && o.mode != offlineModeOff
+}
+
+// offlineStatusFromEnv reads OFFLINE_STATUS (notice, footer or off; default notice) and OFFLINE_STATUS_MESSAGE
+func offlineStatusFromEnv() (offlineStatus, error) {
+	o := offlineStatus{mode: os.Getenv("OFFLINE_STATUS"), message: strings.TrimSpace(os.Getenv("OFFLINE_STATUS_MESSAGE"))}
+	switch o.mode {
+	case "":
+		o.mode = offlineModeNotice
+	case offlineModeNotice, offlineModeFooter, offlineModeOff:
+	default:
+		return offlineStatus{}, fmt.Errorf("invalid OFFLINE_STATUS %q: use notice, footer or off", o.mode)
+	}
+	if o.message == "" {
+		o.message = defaultOfflineMessage
+	}
+	return o, nil
+}
+
+// offlineStatusUpdate builds the final update from the last published one u
+// Footer mode keeps the server list (greyed, offline footer); notice mode replaces it with the notice
+// Neither changes u, which publishers may still hold
+func offlineStatusUpdate(u *StatusUpdate, o offlineStatus, at time.Time) *StatusUpdate {
+	notice := &OfflineNotice{Since: at.UTC(), Message: o.message}
+
+	var snap *StatusSnapshot
+	if u.Snapshot != nil {
+		s := *u.Snapshot
+		snap = &s
+	} else {
+		snap = &StatusSnapshot{UpdatedAt: at.UTC()}
+	}
+	snap.Offline = notice
+
+	// Buttons would only fail while the bot is away
+	var components []discordgo.MessageComponent
+	if u.Components != nil {
+		components = []discordgo.MessageComponent{}
+	}
+
+	embed := *u.Embed
+	embed.Color = offlineColor
+	if o.mode == offlineModeFooter {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: notice.Text() + " (last known status shown)"}
+		return &StatusUpdate{Snapshot: snap, Embed: &embed, Banner: u.Banner, Components: components}
+	}
+
+	// Notice mode: nothing that could be mistaken for live data
+	snap.Categories = nil
+	snap.TotalPlayers = 0
+	embed.Description = ":red_circle: **" + notice.Text() + "**\nServer status is not being updated. It will return when the bot restarts."
+	embed.Fields = nil
+	embed.Image = nil
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: "Last update " + snap.UpdatedAt.Format("2006-01-02 15:04 MST")}
+	return &StatusUpdate{Snapshot: snap, Embed: &embed, DropAttachments: true, Components: components}
+}
+
+// publishOffline posts the offline state so the channel does not show stale data that looks live
+// The next startup posts a normal status right away (first poll), replacing it
+// wasLeader is captured before shutdown because leader election releases the lock when stopped
+func (b *Bot) publishOffline(wasLeader bool) {
+	last := b.lastUpdate.Load()
+	if last == nil || !wasLeader || !b.offline.enabled() {
+		return
+	}
+	u := offlineStatusUpdate(last, b.offline, time.Now())
+	b.eachPublisher("posting offline status", func(p Publisher) error {
+		return p.UpdateStatus(u)
+	})
+	log.Printf("Posted final offline status (%s)", b.offline.mode)
+}
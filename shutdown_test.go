@@ -0,0 +1,216 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/pkg/drain"
+	"github.com/bwmarrin/discordgo"
+)
+
+// testLastUpdate returns a published update with one online server and a banner
+func testLastUpdate() *StatusUpdate {
+	cfg := testStatusConfig()
+	infos := []ServerInfo{{Name: "Drift #1", Category: "Drift", NumPlayers: 3, MaxPlayers: 10, Players: "3/10"}}
+	return &StatusUpdate{
+		Snapshot: buildStatusSnapshot(infos, cfg, time.Date(2026, 10, 16, 20, 4, 0, 0, time.UTC)),
+		Embed:    buildEmbed(infos, cfg),
+		Banner:   []byte("png"),
+	}
+}
+
+// TestOfflineStatusUpdate_Footer tests that footer mode keeps servers, greys the embed and leaves the original alone
+func TestOfflineStatusUpdate_Footer(t *testing.T) {
+	orig := testLastUpdate()
+	at := time.Date(2026, 10, 16, 20, 5, 0, 0, time.UTC)
+	u := offlineStatusUpdate(orig, offlineStatus{mode: offlineModeFooter, message: defaultOfflineMessage}, at)
+
+	if u.Embed.Color != offlineColor {
+		t.Errorf("Expected offline color, got %#x", u.Embed.Color)
+	}
+	if !strings.Contains(u.Embed.Footer.Text, "Status bot offline since 2026-10-16 20:05 UTC") {
+		t.Errorf("Unexpected footer %q", u.Embed.Footer.Text)
+	}
+	if len(u.Embed.Fields) != len(orig.Embed.Fields) {
+		t.Errorf("Expected server fields to be kept, got %d want %d", len(u.Embed.Fields), len(orig.Embed.Fields))
+	}
+	if string(u.Banner) != "png" || u.DropAttachments {
+		t.Error("Expected banner to be kept")
+	}
+	if u.Snapshot.Offline == nil || len(u.Snapshot.Categories) == 0 {
+		t.Error("Expected snapshot with servers and offline notice")
+	}
+	if orig.Embed.Color == offlineColor || strings.Contains(orig.Embed.Footer.Text, "offline") || orig.Snapshot.Offline != nil {
+		t.Error("Original update was modified")
+	}
+}
+
+// TestOfflineStatusUpdate_Notice tests that notice mode drops everything that could look live
+func TestOfflineStatusUpdate_Notice(t *testing.T) {
+	orig := testLastUpdate()
+	at := time.Date(2026, 10, 16, 20, 5, 0, 0, time.UTC)
+	u := offlineStatusUpdate(orig, offlineStatus{mode: offlineModeNotice, message: "Maintenance"}, at)
+
+	if !strings.Contains(u.Embed.Description, "Maintenance since 2026-10-16 20:05 UTC") {
+		t.Errorf("Unexpected description %q", u.Embed.Description)
+	}
+	if len(u.Embed.Fields) != 0 || u.Embed.Image != nil {
+		t.Error("Expected server fields and banner image to be removed")
+	}
+	if u.Banner != nil || !u.DropAttachments {
+		t.Error("Expected the banner attachment to be dropped")
+	}
+	if u.Embed.Footer.Text != "Last update 2026-10-16 20:04 UTC" {
+		t.Errorf("Unexpected footer %q", u.Embed.Footer.Text)
+	}
+	if u.Snapshot.Categories != nil || u.Snapshot.Offline == nil {
+		t.Error("Expected snapshot with only the offline notice")
+	}
+	if len(orig.Embed.Fields) == 0 || len(orig.Snapshot.Categories) == 0 {
+		t.Error("Original update was modified")
+	}
+}
+
+// TestOfflineNotice_Renderers tests that Slack and Matrix show the notice and no player count
+func TestOfflineNotice_Renderers(t *testing.T) {
+	u := offlineStatusUpdate(testLastUpdate(), offlineStatus{mode: offlineModeNotice, message: defaultOfflineMessage}, time.Date(2026, 10, 16, 20, 5, 0, 0, time.UTC))
+
+	text, blocks := renderSlackMessage(u.Snapshot, "Status")
+	if !strings.Contains(text, "Status bot offline since") {
+		t.Errorf("Unexpected Slack text %q", text)
+	}
+	for _, b := range blocks {
+		if txt, ok := b["text"].(map[string]any); ok && strings.Contains(txt["text"].(string), "Total Players") {
+			t.Error("Slack notice should not show a player count")
+		}
+	}
+
+	content := renderMatrixMessage(u.Snapshot, "Status")
+	body := content["body"].(string)
+	if !strings.Contains(body, "Status bot offline since") || strings.Contains(body, "Total Players") {
+		t.Errorf("Unexpected Matrix body %q", body)
+	}
+}
+
+// TestPublishOffline tests that only a leader with a previous update posts the offline status
+func TestPublishOffline(t *testing.T) {
+	b := newTestBot(testStatusConfig())
+	b.offline = offlineStatus{mode: offlineModeNotice, message: defaultOfflineMessage}
+	pub := &fakePublisher{name: "fake"}
+	b.publishers = []Publisher{pub}
+
+	// Nothing posted yet: nothing to mark offline
+	b.publishOffline(true)
+	if len(pub.updates) != 0 {
+		t.Fatalf("Expected no update before the first poll, got %d", len(pub.updates))
+	}
+
+	b.lastUpdate.Store(&StatusUpdate{Embed: &discordgo.MessageEmbed{Footer: &discordgo.MessageEmbedFooter{Text: "Updates every 30 seconds"}}})
+
+	b.publishOffline(false)
+	if len(pub.updates) != 0 {
+		t.Fatalf("Expected standby to skip the offline status, got %d updates", len(pub.updates))
+	}
+
+	b.offline.mode = offlineModeOff
+	b.publishOffline(true)
+	if len(pub.updates) != 0 {
+		t.Fatalf("Expected OFFLINE_STATUS=off to skip the offline status, got %d updates", len(pub.updates))
+	}
+
+	b.offline.mode = offlineModeNotice
+	b.publishOffline(true)
+	if len(pub.updates) != 1 {
+		t.Fatalf("Expected one offline update, got %d", len(pub.updates))
+	}
+	if !strings.Contains(pub.updates[0].Embed.Description, "Status bot offline since") {
+		t.Errorf("Unexpected description %q", pub.updates[0].Embed.Description)
+	}
+}
+
+// TestWebhookPublisher_OfflineThenRestore tests that the notice drops the banner and the next start restores the message
+func TestWebhookPublisher_OfflineThenRestore(t *testing.T) {
+	api := &fakeWebhookAPI{}
+	p := &WebhookPublisher{api: api, id: "1", token: "t", messageIDs: []string{"111"}}
+
+	last := testLastUpdate()
+	offline := offlineStatusUpdate(last, offlineStatus{mode: offlineModeNotice, message: defaultOfflineMessage}, time.Now())
+	if err := p.UpdateStatus(offline); err != nil {
+		t.Fatalf("Offline publish failed: %v", err)
+	}
+	if api.lastEdit.Attachments == nil || len(*api.lastEdit.Attachments) != 0 {
+		t.Error("Expected the previous banner attachment to be removed")
+	}
+
+	// Next startup: the first poll edits the same message back to the normal rendering
+	if err := p.UpdateStatus(last); err != nil {
+		t.Fatalf("Restore publish failed: %v", err)
+	}
+	if api.executes != 0 || api.edits != 2 {
+		t.Errorf("Expected the same message to be edited twice, got %d executes, %d edits", api.executes, api.edits)
+	}
+	embed := (*api.lastEdit.Embeds)[0]
+	if embed.Color == offlineColor || len(embed.Fields) == 0 {
+		t.Error("Expected normal rendering after restart")
+	}
+}
+
+// TestOfflineStatusFromEnv tests defaults, modes, custom message and rejected values
+func TestOfflineStatusFromEnv(t *testing.T) {
+	t.Setenv("OFFLINE_STATUS", "")
+	t.Setenv("OFFLINE_STATUS_MESSAGE", "")
+	o, err := offlineStatusFromEnv()
+	if err != nil || o.mode != offlineModeNotice || o.message != defaultOfflineMessage || !o.enabled() {
+		t.Errorf("Default: got %+v, %v", o, err)
+	}
+
+	t.Setenv("OFFLINE_STATUS", "footer")
+	t.Setenv("OFFLINE_STATUS_MESSAGE", " Server maintenance ")
+	o, err = offlineStatusFromEnv()
+	if err != nil || o.mode != offlineModeFooter || o.message != "Server maintenance" {
+		t.Errorf("Footer: got %+v, %v", o, err)
+	}
+
+	t.Setenv("OFFLINE_STATUS", "off")
+	if o, err := offlineStatusFromEnv(); err != nil || o.enabled() {
+		t.Errorf("Off: got %+v, %v", o, err)
+	}
+
+	t.Setenv("OFFLINE_STATUS", "banner")
+	if _, err := offlineStatusFromEnv(); err == nil {
+		t.Error("Expected unknown mode to be rejected")
+	}
+}
+
+// TestDrainTimeoutFromEnv tests the default, a custom value and rejected values
+func TestDrainTimeoutFromEnv(t *testing.T) {
+	t.Setenv("SHUTDOWN_DRAIN_TIMEOUT", "")
+	if d, err := drainTimeoutFromEnv(); err != nil || d != drain.DefaultTimeout {
+		t.Errorf("Default: got %v, %v", d, err)
+	}
+
+	t.Setenv("SHUTDOWN_DRAIN_TIMEOUT", "10s")
+	if d, err := drainTimeoutFromEnv(); err != nil || d != 10*time.Second {
+		t.Errorf("10s: got %v, %v", d, err)
+	}
+
+	for _, v := range []string{"soon", "500ms", "-5s"} {
+		t.Setenv("SHUTDOWN_DRAIN_TIMEOUT", v)
+		if _, err := drainTimeoutFromEnv(); err == nil {
+			t.Errorf("Expected %q to be rejected", v)
+		}
+	}
+}
+
+// TestShutdownTimeout tests that components get the drain timeout plus the grace period
+func TestShutdownTimeout(t *testing.T) {
+	b := newTestBot(nil)
+	if got := b.shutdownTimeout(); got != drain.DefaultTimeout+shutdownGrace {
+		t.Errorf("Default: got %v", got)
+	}
+	b.setDrainTimeout(10 * time.Second)
+	if got := b.shutdownTimeout(); got != 15*time.Second {
+		t.Errorf("Custom: got %v", got)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= UPTIME REPORTS =================
+
+const (
+	// slaTopTracks is the number of tracks listed in a report
+	slaTopTracks = 5
+	// slaReportRetry is how long a monthly report that failed to post waits before the next attempt
+	slaReportRetry = time.Hour
+)
+
+// monthStart returns the first instant of the UTC month of t
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// percent returns part/whole in percent rounded to two decimals (nil for whole 0)
+func percent(part, whole int) *float64 {
+	if whole == 0 {
+		return nil
+	}
+	p := math.Round(float64(part)*10000/float64(whole)) / 100
+	return &p
+}
+
+// playerHours converts player seconds to hours rounded to one decimal
+func playerHours(seconds int64) float64 {
+	return math.Round(float64(seconds)/360) / 10
+}
+
+// buildSLAReport sums the buckets of the month starting at month into the uptime report
+// Servers are sorted by name, tracks by player-hours
+func buildSLAReport(buckets []historyBucket, month time.Time) *api.SLAReport {
+	r := &api.SLAReport{
+		Month:     month.Format("2006-01"),
+		From:      month,
+		To:        month.AddDate(0, 1, 0),
+		Servers:   []api.SLAServer{},
+		TopTracks: []api.SLATrack{},
+	}
+	type sums struct {
+		category                   string
+		polls, online, maintenance int
+		playerSeconds              int64
+		sessions                   int
+	}
+	servers := make(map[string]*sums)
+	tracks := make(map[string]int64)
+	var total sums
+	for _, b := range buckets {
+		if b.Hour.Before(r.From) || !b.Hour.Before(r.To) {
+			continue
+		}
+		s := servers[b.Server]
+		if s == nil {
+			s = &sums{}
+			servers[b.Server] = s
+		}
+		s.category = b.Category
+		for _, sum := range []*sums{s, &total} {
+			sum.polls += b.Polls
+			sum.online += b.OnlinePolls
+			sum.maintenance += b.MaintenancePolls
+			sum.playerSeconds += b.PlayerSeconds
+			sum.sessions += b.Sessions
+		}
+		for track, seconds := range b.Tracks {
+			tracks[track] += seconds
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(servers)) {
+		s := servers[name]
+		r.Servers = append(r.Servers, api.SLAServer{
+			Server:           name,
+			Category:         s.category,
+			UptimePercent:    percent(s.online, s.polls-s.maintenance),
+			Polls:            s.polls,
+			MaintenancePolls: s.maintenance,
+			PlayerHours:      playerHours(s.playerSeconds),
+			Sessions:         s.sessions,
+		})
+	}
+	r.UptimePercent = percent(total.online, total.polls-total.maintenance)
+	r.PlayerHours = playerHours(total.playerSeconds)
+	r.Sessions = total.sessions
+
+	names := slices.SortedFunc(maps.Keys(tracks), func(a, b string) int {
+		return cmp.Or(cmp.Compare(tracks[b], tracks[a]), strings.Compare(a, b))
+	})
+	for _, track := range names[:min(len(names), slaTopTracks)] {
+		r.TopTracks = append(r.TopTracks, api.SLATrack{Track: track, PlayerHours: playerHours(tracks[track])})
+	}
+	return r
+}
+
+// slaProvider adapts the bot's history to api.SLAProvider
+type slaProvider struct {
+	bot *Bot
+}
+
+// SLAReport implements api.SLAProvider
+func (p *slaProvider) SLAReport(month time.Time) *api.SLAReport {
+	return buildSLAReport(p.bot.history.buckets(month, month.AddDate(0, 1, 0)), month)
+}
+
+// slaReporter posts the report of the previous month once a new month starts (bot mode, leader only)
+type slaReporter struct {
+	channelID string // "" = the status channel
+
+	retryAt time.Time // after a failed post
+}
+
+// slaReporterFromEnv returns the reporter if SLA_REPORT_ENABLED is true, nil otherwise
+// SLA_REPORT_CHANNEL_ID posts somewhere other than the status channel
+func slaReporterFromEnv(history *historyStore) (*slaReporter, error) {
+	if os.Getenv("SLA_REPORT_ENABLED") != "true" {
+		return nil, nil
+	}
+	if history == nil {
+		return nil, fmt.Errorf("SLA_REPORT_ENABLED requires HISTORY_ENABLED=true")
+	}
+	channelID := os.Getenv("SLA_REPORT_CHANNEL_ID")
+	if channelID != "" && !snowflakePattern.MatchString(channelID) {
+		return nil, fmt.Errorf("invalid SLA_REPORT_CHANNEL_ID %q: must be a Discord channel ID", channelID)
+	}
+	log.Printf("Monthly uptime report enabled")
+	return &slaReporter{channelID: channelID}, nil
+}
+
+// dueReport returns the month whose report should be posted now, ok=false if there is none
+// The first month after enabling is only marked: its history may be partial
+func (h *historyStore) dueReport(now time.Time) (time.Time, bool) {
+	prev := monthStart(now).AddDate(0, -1, 0)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.state.LastReport >= prev.Format("2006-01") {
+		return time.Time{}, false
+	}
+	if h.state.LastReport == "" {
+		h.markReportedLocked(prev)
+		return time.Time{}, false
+	}
+	return prev, true
+}
+
+// markReported remembers that the report of month was posted
+func (h *historyStore) markReported(month time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.markReportedLocked(month)
+}
+
+// markReportedLocked is markReported with h.mu held
+func (h *historyStore) markReportedLocked(month time.Time) {
+	h.state.LastReport = month.Format("2006-01")
+	if h.writer != nil {
+		h.writer.setLastReport(h.state.LastReport)
+	}
+}
+
+// postMonthlyReport posts the previous month's report once it is over (no-op when disabled)
+// A failed post is retried after slaReportRetry
+func (b *Bot) postMonthlyReport(now time.Time) {
+	r := b.slaReports
+	if r == nil || b.history == nil || b.discord == nil || !b.isLeader() || now.Before(r.retryAt) {
+		return
+	}
+	month, ok := b.history.dueReport(now)
+	if !ok {
+		return
+	}
+	report := buildSLAReport(b.history.buckets(month, month.AddDate(0, 1, 0)), month)
+	channelID := r.channelID
+	if channelID == "" {
+		channelID = b.discord.channel()
+	}
+	if _, err := b.discord.session.ChannelMessageSendEmbed(channelID, slaReportEmbed(report)); err != nil {
+		log.Printf("Error posting the monthly report for %s (retrying in %v): %v", report.Month, slaReportRetry, err)
+		r.retryAt = now.Add(slaReportRetry)
+		return
+	}
+	b.history.markReported(month)
+	log.Printf("Posted the monthly report for %s", report.Month)
+}
+
+// formatPercent renders an uptime ("n/a" without polls)
+func formatPercent(p *float64) string {
+	if p == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f%%", *p)
+}
+
+// slaReportEmbed renders the monthly report: overall uptime, player-hours and sessions, each server, the top tracks
+func slaReportEmbed(r *api.SLAReport) *discordgo.MessageEmbed {
+	lines := make([]string, 0, len(r.Servers))
+	for _, s := range r.Servers {
+		dot := "🟢"
+		switch {
+		case s.UptimePercent == nil:
+			dot = "⚪"
+		case *s.UptimePercent < 95:
+			dot = "🔴"
+		case *s.UptimePercent < 99:
+			dot = "🟡"
+		}
+		lines = append(lines, fmt.Sprintf("%s **%s** — %s · %.1f player-hours · %d sessions", dot, s.Server, formatPercent(s.UptimePercent), s.PlayerHours, s.Sessions))
+	}
+	servers := strings.Join(lines, "\n")
+	if servers == "" {
+		servers = "No server was polled this month"
+	}
+	embed := &discordgo.MessageEmbed{
+		Title: "📊 Monthly report — " + r.From.Format("January 2006"),
+		Description: fmt.Sprintf("**Uptime:** %s\n**Player-hours:** %.1f\n**Sessions:** %d\n\n%s",
+			formatPercent(r.UptimePercent), r.PlayerHours, r.Sessions, truncateRunes(servers, 3800)),
+		Color:  0x5865f2,
+		Footer: &discordgo.MessageEmbedFooter{Text: "Uptime excludes maintenance"},
+	}
+	if len(r.TopTracks) > 0 {
+		tracks := make([]string, len(r.TopTracks))
+		for i, t := range r.TopTracks {
+			tracks[i] = fmt.Sprintf("%d. %s — %.1f player-hours", i+1, t.Track, t.PlayerHours)
+		}
+		embed.Fields = []*discordgo.MessageEmbedField{{Name: "🏁 Top tracks", Value: truncateRunes(strings.Join(tracks, "\n"), 1024)}}
+	}
+	return embed
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBuildSLAReport tests uptime without maintenance, player-hours, top tracks and the month bounds
+func TestBuildSLAReport(t *testing.T) {
+	month := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	buckets := []historyBucket{
+		{Hour: month, Server: "Track #1", Category: "Track", Polls: 120, OnlinePolls: 110, MaintenancePolls: 10, PlayerSeconds: 7200, Tracks: map[string]int64{"spa": 3600, "monza": 3600}},
+		{Hour: month.Add(time.Hour), Server: "Drift #1", Category: "Drift", Polls: 120, OnlinePolls: 60, PlayerSeconds: 18000, Tracks: map[string]int64{"ebisu": 18000}},
+		{Hour: month.AddDate(0, 1, 0), Server: "Drift #1", Category: "Drift", Polls: 120}, // October
+	}
+	r := buildSLAReport(buckets, month)
+	if r.Month != "2026-09" || len(r.Servers) != 2 || r.Servers[0].Server != "Drift #1" {
+		t.Fatalf("Unexpected report %+v", r)
+	}
+	if *r.Servers[0].UptimePercent != 50 || *r.Servers[1].UptimePercent != 100 || r.Servers[1].MaintenancePolls != 10 {
+		t.Errorf("Unexpected uptimes %+v", r.Servers)
+	}
+	if *r.UptimePercent != 73.91 || r.PlayerHours != 7 {
+		t.Errorf("Expected 170/230 polls and 7 player-hours, got %v and %v", *r.UptimePercent, r.PlayerHours)
+	}
+	if len(r.TopTracks) != 3 || r.TopTracks[0].Track != "ebisu" || r.TopTracks[1].Track != "monza" {
+		t.Errorf("Unexpected top tracks %+v", r.TopTracks)
+	}
+
+	empty := buildSLAReport(nil, month)
+	if empty.UptimePercent != nil || len(empty.Servers) != 0 || !strings.Contains(slaReportEmbed(empty).Description, "n/a") {
+		t.Errorf("Expected an empty report, got %+v", empty)
+	}
+}
+
+// TestPostMonthlyReport tests that the first month is only marked, and later months are posted once
+func TestPostMonthlyReport(t *testing.T) {
+	f := newFakeDiscord()
+	b := newFakeDiscordBot(t, f, testMaintenanceConfig())
+	b.history = newHistoryStore(nil)
+	b.slaReports = &slaReporter{}
+	sept := time.Date(2026, 9, 15, 12, 0, 0, 0, time.UTC)
+
+	b.history.record(slotPoll(1), 30*time.Second, sept)
+	b.postMonthlyReport(sept)
+	b.postMonthlyReport(sept.AddDate(0, 0, 1))
+	if n := len(f.channelMessages(fakeChannelID)); n != 0 {
+		t.Fatalf("Expected nothing posted for the month enabled in, got %d messages", n)
+	}
+
+	oct := time.Date(2026, 10, 1, 0, 1, 0, 0, time.UTC)
+	f.failNext("ChannelMessageSendEmbed", errors.New("HTTP 500"))
+	b.postMonthlyReport(oct)
+	b.postMonthlyReport(oct.Add(time.Minute)) // within the retry delay
+	b.postMonthlyReport(oct.Add(slaReportRetry))
+	b.postMonthlyReport(oct.Add(2 * slaReportRetry))
+	msgs := f.channelMessages(fakeChannelID)
+	if len(msgs) != 1 || len(msgs[0].Embeds) != 1 || !strings.Contains(msgs[0].Embeds[0].Title, "September 2026") ||
+		!strings.Contains(msgs[0].Embeds[0].Description, "Track #1") {
+		t.Errorf("Expected one September report, got %+v", msgs)
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ================= SLACK =================
+
+const (
+	// slackAPIURL is the Slack Web API base URL
+	slackAPIURL = "https://slack.com/api/"
+	// slackStateFile stores the timestamp (message ID) of the pinned status message next to config.json
+	slackStateFile = "slack_message_ts"
+	// slackRequestTimeout bounds each Slack Web API call
+	slackRequestTimeout = 10 * time.Second
+	// slackMaxSectionText is Slack's limit for a section block's text
+	slackMaxSectionText = 3000
+)
+
+// SlackPublisher maintains a pinned status message in a Slack channel
+// Posts once with chat.postMessage + pins.add, then edits in place with chat.update
+type SlackPublisher struct {
+	token     string
+	channel   string
+	title     string
+	statePath string
+	apiURL    string
+	client    *http.Client
+
+	mu sync.Mutex
+	ts string
+}
+
+// NewSlackPublisher creates a publisher for channel using a bot token (xoxb-...)
+// statePath persists the message timestamp across restarts (empty disables persistence)
+func NewSlackPublisher(token, channel, title, statePath string) *SlackPublisher {
+	if title == "" {
+		title = defaultStatusTitle
+	}
+	p := &SlackPublisher{
+		token:     token,
+		channel:   channel,
+		title:     title,
+		statePath: statePath,
+		apiURL:    slackAPIURL,
+		client:    &http.Client{Timeout: slackRequestTimeout},
+	}
+	p.loadTS()
+	return p
+}
+
+// Name implements Publisher
+func (p *SlackPublisher) Name() string {
+	return "Slack"
+}
+
+// loadTS restores the status message timestamp from the state file
+func (p *SlackPublisher) loadTS() {
+	if p.statePath == "" {
+		return
+	}
+	data, err := os.ReadFile(p.statePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Warning: failed to read Slack state %s: %v", p.statePath, err)
+		}
+		return
+	}
+	p.ts = strings.TrimSpace(string(data))
+}
+
+// saveTS persists the status message timestamp (failure only costs a duplicate message after restart)
+func (p *SlackPublisher) saveTS(ts string) {
+	if p.statePath == "" {
+		return
+	}
+	if err := os.WriteFile(p.statePath, []byte(ts+"\n"), 0600); err != nil {
+		log.Printf("Warning: failed to save Slack state %s: %v", p.statePath, err)
+	}
+}
+
+// clearTS removes the state file so the next update posts a fresh message
+func (p *SlackPublisher) clearTS() {
+	if p.statePath == "" {
+		return
+	}
+	if err := os.Remove(p.statePath); err != nil && !os.IsNotExist(err) {
+		log.Printf("Warning: failed to remove Slack state %s: %v", p.statePath, err)
+	}
+}
+
+// slackResponse is the common envelope of Slack Web API responses
+type slackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	TS    string `json:"ts"`
+}
+
+// slackError is a Slack API-level failure (HTTP 200 with ok=false)
+type slackError struct {
+	Method string
+	Code   string
+}
+
+func (e *slackError) Error() string {
+	return fmt.Sprintf("slack %s failed: %s", e.Method, e.Code)
+}
+
+// call invokes a Slack Web API method with a JSON body
+func (p *SlackPublisher) call(method string, payload any) (*slackResponse, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), slackRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiURL+method, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("slack %s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("slack %s returned HTTP %d", method, resp.StatusCode)
+	}
+
+	var out slackResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	if !out.OK {
+		return &out, &slackError{Method: method, Code: out.Error}
+	}
+	return &out, nil
+}
+
+// UpdateStatus implements Publisher: edits the pinned message, reposting if it was deleted
+func (p *SlackPublisher) UpdateStatus(u *StatusUpdate) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	text, blocks := renderSlackMessage(u.Snapshot, p.title)
+
+	if p.ts != "" {
+		_, err := p.call("chat.update", map[string]any{
+			"channel": p.channel,
+			"ts":      p.ts,
+			"text":    text,
+			"blocks":  blocks,
+		})
+		if err == nil {
+			return nil
+		}
+		if se, ok := err.(*slackError); !ok || se.Code != "message_not_found" {
+			return err
+		}
+		log.Println("Slack status message was deleted, posting a new one")
+	}
+
+	resp, err := p.call("chat.postMessage", map[string]any{
+		"channel": p.channel,
+		"text":    text,
+		"blocks":  blocks,
+	})
+	if err != nil {
+		return err
+	}
+	p.ts = resp.TS
+	p.saveTS(resp.TS)
+	log.Println("Initial Slack status message posted")
+
+	// Pinning is best effort (requires pins:write); the message is still updated without it
+	if _, err := p.call("pins.add", map[string]any{"channel": p.channel, "timestamp": resp.TS}); err != nil {
+		log.Printf("Warning: failed to pin Slack status message: %v", err)
+	}
+	return nil
+}
+
+// DeleteStatus implements Publisher: deletes the status message and forgets its timestamp
+func (p *SlackPublisher) DeleteStatus() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ts == "" {
+		return nil
+	}
+	if _, err := p.call("chat.delete", map[string]any{"channel": p.channel, "ts": p.ts}); err != nil {
+		if se, ok := err.(*slackError); !ok || se.Code != "message_not_found" {
+			return err
+		}
+	}
+	p.ts = ""
+	p.clearTS()
+	return nil
+}
+
+// SendAlert implements Publisher
+func (p *SlackPublisher) SendAlert(msg string) error {
+	_, err := p.call("chat.postMessage", map[string]any{"channel": p.channel, "text": msg})
+	return err
+}
+
+// slackEscape escapes the characters Slack treats as control sequences in mrkdwn text
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// slackChunkLines joins lines into texts of at most max bytes (Slack rejects longer section text)
+func slackChunkLines(lines []string, max int) []string {
+	var chunks []string
+	var cur strings.Builder
+	for _, line := range lines {
+		if cur.Len() > 0 && cur.Len()+1+len(line) > max {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+		}
+		if cur.Len() > 0 {
+			cur.WriteByte('\n')
+		}
+		cur.WriteString(line)
+	}
+	if cur.Len() > 0 {
+		chunks = append(chunks, cur.String())
+	}
+	return chunks
+}
+
+// renderSlackMessage builds the notification fallback text and Block Kit blocks for a snapshot
+func renderSlackMessage(snap *StatusSnapshot, title string) (string, []map[string]any) {
+	text := fmt.Sprintf("%s: %d players online", title, snap.TotalPlayers)
+
+	blocks := []map[string]any{
+		{
+			"type": "header",
+			"text": map[string]any{"type": "plain_text", "text": title},
+		},
+	}
+	if snap.Offline != nil {
+		text = fmt.Sprintf("%s: %s", title, snap.Offline.Text())
+		blocks = append(blocks, map[string]any{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": ":red_circle: *" + slackEscape(snap.Offline.Text()) + "*"},
+		})
+	}
+	// An offline notice without server data shows no player count
+	if snap.Offline == nil || len(snap.Categories) > 0 {
+		blocks = append(blocks, map[string]any{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": fmt.Sprintf(":bust_in_silhouette: *Total Players:* %d", snap.TotalPlayers)},
+		})
+	}
+
+	for _, cat := range snap.Categories {
+		var lines []string
+		lines = append(lines, fmt.Sprintf("%s *%s Servers — %d players*", cat.Emoji, slackEscape(cat.Name), cat.Players))
+		for _, srv := range cat.Servers {
+			if note := srv.MaintenanceNote(); note != "" && !srv.Online {
+				lines = append(lines, fmt.Sprintf(":wrench: %s — _%s_", slackEscape(srv.Name), slackEscape(note)))
+				continue
+			}
+			if !srv.Online {
+				lines = append(lines, fmt.Sprintf(":red_circle: %s — offline", slackEscape(srv.Name)))
+				continue
+			}
+			dot := ":large_green_circle:"
+			if srv.Maintenance {
+				dot = ":wrench:"
+			} else if srv.Stale {
+				dot = ":white_circle:"
+			}
+			line := fmt.Sprintf("%s %s — %s — %s", dot, slackEscape(srv.Name), slackEscape(srv.Map), srv.PlayerCount())
+			if age := srv.StaleAge(snap.UpdatedAt); age != "" {
+				line += fmt.Sprintf(" — _data %s old_", age)
+			}
+			if note := srv.MaintenanceNote(); note != "" {
+				line += fmt.Sprintf(" — _%s_", slackEscape(note))
+			}
+			if srv.JoinURL != "" {
+				line += fmt.Sprintf(" — <%s|Join>", srv.JoinURL)
+			}
+			lines = append(lines, line)
+		}
+		blocks = append(blocks, map[string]any{"type": "divider"})
+		for _, chunk := range slackChunkLines(lines, slackMaxSectionText) {
+			blocks = append(blocks, map[string]any{
+				"type": "section",
+				"text": map[string]any{"type": "mrkdwn", "text": chunk},
+			})
+		}
+	}
+
+	blocks = append(blocks, map[string]any{
+		"type": "context",
+		"elements": []map[string]any{
+			{"type": "mrkdwn", "text": "Updated " + snap.UpdatedAt.Format("2006-01-02 15:04:05 MST")},
+		},
+	})
+
+	return text, blocks
+}
+
+// slackPublisherFromEnv returns a publisher if SLACK_BOT_TOKEN and SLACK_CHANNEL_ID are set, nil otherwise
+// The message timestamp state file is stored next to configPath
+func slackPublisherFromEnv(configPath string) (*SlackPublisher, error) {
+	token := os.Getenv("SLACK_BOT_TOKEN")
+	channel := os.Getenv("SLACK_CHANNEL_ID")
+	if token == "" && channel == "" {
+		return nil, nil
+	}
+	if token == "" || channel == "" {
+		return nil, fmt.Errorf("SLACK_BOT_TOKEN and SLACK_CHANNEL_ID must both be set")
+	}
+	log.Printf("Slack status mirror enabled for channel %s", channel)
+	return NewSlackPublisher(token, channel, os.Getenv("SLACK_STATUS_TITLE"), filepath.Join(filepath.Dir(configPath), slackStateFile)), nil
+}
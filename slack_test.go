@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSlack records Web API calls; chat.update fails with updateError when set
+type fakeSlack struct {
+	mu          sync.Mutex
+	calls       []string
+	auth        string
+	updateError string
+}
+
+func (f *fakeSlack) handler(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	method := strings.TrimPrefix(r.URL.Path, "/")
+	f.calls = append(f.calls, method)
+	f.auth = r.Header.Get("Authorization")
+
+	switch {
+	case method == "chat.update" && f.updateError != "":
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": f.updateError})
+	case method == "chat.postMessage":
+		json.NewEncoder(w).Encode(map[string]any{"ok": true, "ts": "1700000000.000100"})
+	default:
+		json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	}
+}
+
+func newTestSlackPublisher(t *testing.T, f *fakeSlack) *SlackPublisher {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(f.handler))
+	t.Cleanup(srv.Close)
+
+	p := NewSlackPublisher("xoxb-test", "C123", "", filepath.Join(t.TempDir(), slackStateFile))
+	p.apiURL = srv.URL + "/"
+	return p
+}
+
+func testMirrorSnapshot() *StatusSnapshot {
+	infos := []ServerInfo{
+		{Name: "Drift 1", Category: "Drift", Map: "ebisu", NumPlayers: 2, MaxPlayers: 16, IP: "203.0.113.10", Port: 8081},
+		{Name: "Drift <2>", Category: "Drift", Map: "Offline", NumPlayers: -1, IP: "203.0.113.10", Port: 8083},
+	}
+	return buildStatusSnapshot(infos, testStatusConfig(), time.Now())
+}
+
+// TestSlackPublisher_PostPinThenUpdate tests first publish posts and pins, later ones update in place
+func TestSlackPublisher_PostPinThenUpdate(t *testing.T) {
+	f := &fakeSlack{}
+	p := newTestSlackPublisher(t, f)
+
+	if err := p.UpdateStatus(&StatusUpdate{Snapshot: testMirrorSnapshot()}); err != nil {
+		t.Fatalf("First publish failed: %v", err)
+	}
+	if err := p.UpdateStatus(&StatusUpdate{Snapshot: testMirrorSnapshot()}); err != nil {
+		t.Fatalf("Second publish failed: %v", err)
+	}
+
+	want := []string{"chat.postMessage", "pins.add", "chat.update"}
+	if strings.Join(f.calls, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected calls %v, got %v", want, f.calls)
+	}
+	if f.auth != "Bearer xoxb-test" {
+		t.Errorf("Expected bearer auth, got %q", f.auth)
+	}
+
+	// Timestamp survives a restart
+	restarted := NewSlackPublisher("xoxb-test", "C123", "", p.statePath)
+	if restarted.ts != "1700000000.000100" {
+		t.Errorf("Expected persisted ts, got %q", restarted.ts)
+	}
+}
+
+// TestSlackPublisher_RepostsDeletedMessage tests that message_not_found triggers a new post
+func TestSlackPublisher_RepostsDeletedMessage(t *testing.T) {
+	f := &fakeSlack{updateError: "message_not_found"}
+	p := newTestSlackPublisher(t, f)
+	p.ts = "1600000000.000001"
+
+	if err := p.UpdateStatus(&StatusUpdate{Snapshot: testMirrorSnapshot()}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if len(f.calls) != 3 || f.calls[0] != "chat.update" || f.calls[1] != "chat.postMessage" {
+		t.Errorf("Expected update, post, pin; got %v", f.calls)
+	}
+	if p.ts != "1700000000.000100" {
+		t.Errorf("Expected new ts, got %q", p.ts)
+	}
+}
+
+// TestSlackPublisher_UpdateErrorReturned tests that other API errors are surfaced without reposting
+func TestSlackPublisher_UpdateErrorReturned(t *testing.T) {
+	f := &fakeSlack{updateError: "ratelimited"}
+	p := newTestSlackPublisher(t, f)
+	p.ts = "1600000000.000001"
+
+	err := p.UpdateStatus(&StatusUpdate{Snapshot: testMirrorSnapshot()})
+	var se *slackError
+	if !errors.As(err, &se) || se.Code != "ratelimited" {
+		t.Fatalf("Expected ratelimited slackError, got %v", err)
+	}
+	if len(f.calls) != 1 {
+		t.Errorf("Expected no repost, got calls %v", f.calls)
+	}
+}
+
+// TestRenderSlackMessage tests block content and mrkdwn escaping
+func TestRenderSlackMessage(t *testing.T) {
+	text, blocks := renderSlackMessage(testMirrorSnapshot(), "Status")
+
+	if text != "Status: 2 players online" {
+		t.Errorf("Unexpected fallback text %q", text)
+	}
+	data, _ := json.Marshal(blocks)
+	out := string(data)
+	if !strings.Contains(out, "Drift \\u0026lt;2\\u0026gt; — offline") {
+		t.Errorf("Expected escaped offline server name, got %s", out)
+	}
+	if !strings.Contains(out, "|Join\\u003e") {
+		t.Errorf("Expected join link for online server, got %s", out)
+	}
+}
+
+// TestSlackChunkLines tests that section text is split below the size limit
+func TestSlackChunkLines(t *testing.T) {
+	lines := []string{strings.Repeat("a", 6), strings.Repeat("b", 6), strings.Repeat("c", 6)}
+	chunks := slackChunkLines(lines, 13)
+	if len(chunks) != 2 || chunks[0] != "aaaaaa\nbbbbbb" || chunks[1] != "cccccc" {
+		t.Errorf("Unexpected chunks %q", chunks)
+	}
+}
+
+// TestSlackPublisherFromEnv tests enablement rules
+func TestSlackPublisherFromEnv(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	t.Setenv("SLACK_CHANNEL_ID", "")
+	if p, err := slackPublisherFromEnv(configPath); p != nil || err != nil {
+		t.Errorf("Expected disabled, got %v (%v)", p, err)
+	}
+
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	if _, err := slackPublisherFromEnv(configPath); err == nil {
+		t.Error("Expected error when SLACK_CHANNEL_ID is missing")
+	}
+
+	t.Setenv("SLACK_CHANNEL_ID", "C123")
+	p, err := slackPublisherFromEnv(configPath)
+	if err != nil || p == nil {
+		t.Fatalf("Expected publisher, got %v (%v)", p, err)
+	}
+	if p.statePath != filepath.Join(filepath.Dir(configPath), slackStateFile) {
+		t.Errorf("Unexpected state path %s", p.statePath)
+	}
+}
+
+// TestSlackPublisher_DeleteAndAlert tests chat.delete and alert posting
+func TestSlackPublisher_DeleteAndAlert(t *testing.T) {
+	f := &fakeSlack{}
+	p := newTestSlackPublisher(t, f)
+
+	if err := p.UpdateStatus(&StatusUpdate{Snapshot: testMirrorSnapshot()}); err != nil {
+		t.Fatalf("UpdateStatus failed: %v", err)
+	}
+	if err := p.DeleteStatus(); err != nil {
+		t.Fatalf("DeleteStatus failed: %v", err)
+	}
+	if p.ts != "" {
+		t.Errorf("Expected ts cleared, got %q", p.ts)
+	}
+	if err := p.SendAlert("Server down"); err != nil {
+		t.Fatalf("SendAlert failed: %v", err)
+	}
+
+	want := "chat.postMessage,pins.add,chat.delete,chat.postMessage"
+	if got := strings.Join(f.calls, ","); got != want {
+		t.Errorf("Expected calls %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= SLOT NOTIFICATIONS =================
+
+const (
+	// defaultSlotNotifyExpiry is how long a sign-up waits for a free slot
+	defaultSlotNotifyExpiry = 2 * time.Hour
+	// defaultSlotNotifyCooldown is how long after a DM the same member cannot sign up again
+	defaultSlotNotifyCooldown = 15 * time.Minute
+	// maxSlotWatchers bounds the sign-ups per server
+	maxSlotWatchers = 100
+	// slotNotifyButtonPrefix starts the custom ID of "Notify me" buttons ("notify_slot:<server>")
+	slotNotifyButtonPrefix = "notify_slot"
+)
+
+// slotWatcher is a member waiting for a slot on a server
+type slotWatcher struct {
+	name string // username, for logs
+	at   time.Time
+}
+
+// slotNotifier keeps members who asked to be told when a full server has a free slot and DMs them once it does
+// Sign-ups live in the leader's memory: a restart or failover drops them
+type slotNotifier struct {
+	expiry   time.Duration
+	cooldown time.Duration
+
+	mu       sync.Mutex
+	watchers map[string]map[string]slotWatcher // server -> user ID -> sign-up
+	notified map[string]time.Time              // user ID -> last DM
+}
+
+// slotNotifierFromEnv returns the notifier if SLOT_NOTIFY is true, nil otherwise
+// SLOT_NOTIFY_EXPIRY and SLOT_NOTIFY_COOLDOWN are durations (default 2h and 15m)
+func slotNotifierFromEnv() (*slotNotifier, error) {
+	if os.Getenv("SLOT_NOTIFY") != "true" {
+		return nil, nil
+	}
+	expiry, err := slotNotifyDurationFromEnv("SLOT_NOTIFY_EXPIRY", defaultSlotNotifyExpiry)
+	if err != nil {
+		return nil, err
+	}
+	cooldown, err := slotNotifyDurationFromEnv("SLOT_NOTIFY_COOLDOWN", defaultSlotNotifyCooldown)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Slot notifications enabled (sign-ups expire after %v, cooldown %v)", expiry, cooldown)
+	return newSlotNotifier(expiry, cooldown), nil
+}
+
+// slotNotifyDurationFromEnv parses a positive duration of at most a day
+func slotNotifyDurationFromEnv(name string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 || d > 24*time.Hour {
+		return 0, fmt.Errorf("invalid %s %q: must be a duration between 1s and 24h, e.g. 30m", name, v)
+	}
+	return d, nil
+}
+
+func newSlotNotifier(expiry, cooldown time.Duration) *slotNotifier {
+	return &slotNotifier{
+		expiry:   expiry,
+		cooldown: cooldown,
+		watchers: make(map[string]map[string]slotWatcher),
+		notified: make(map[string]time.Time),
+	}
+}
+
+// serverFull reports whether a fresh poll shows the server online with every slot taken
+func serverFull(info ServerInfo) bool {
+	return !serverDown(info) && info.Maintenance == nil && info.MaxPlayers > 0 && info.NumPlayers >= info.MaxPlayers
+}
+
+// toggle signs user up for server, or removes the sign-up if there is one, and returns the reply
+// info is the server's last poll result (nil = unknown server)
+func (n *slotNotifier) toggle(info *ServerInfo, user invoker, now time.Time) string {
+	if info == nil {
+		return "❌ Unknown server"
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.expire(now)
+
+	server := info.Name
+	if _, ok := n.watchers[server][user.id]; ok {
+		delete(n.watchers[server], user.id)
+		return fmt.Sprintf("🔕 You will no longer be notified about **%s**", server)
+	}
+	if !serverFull(*info) {
+		return fmt.Sprintf("✅ **%s** has a free slot right now (%s)", server, info.Players)
+	}
+	if last, ok := n.notified[user.id]; ok && now.Sub(last) < n.cooldown {
+		return fmt.Sprintf("⏳ You were notified %s ago; you can sign up again in %s",
+			formatDataAge(now.Sub(last)), formatDataAge(n.cooldown-now.Sub(last)))
+	}
+	if len(n.watchers[server]) >= maxSlotWatchers {
+		return fmt.Sprintf("❌ Too many members are waiting for **%s** already", server)
+	}
+	if n.watchers[server] == nil {
+		n.watchers[server] = make(map[string]slotWatcher)
+	}
+	n.watchers[server][user.id] = slotWatcher{name: user.name, at: now}
+	return fmt.Sprintf("🔔 I'll DM you when a slot opens on **%s** (for the next %s); press the button again to cancel",
+		server, formatDataAge(n.expiry))
+}
+
+// expire drops sign-ups older than the expiry and cooldowns that ended (caller holds n.mu)
+func (n *slotNotifier) expire(now time.Time) {
+	for server, users := range n.watchers {
+		for id, w := range users {
+			if now.Sub(w.at) >= n.expiry {
+				delete(users, id)
+			}
+		}
+		if len(users) == 0 {
+			delete(n.watchers, server)
+		}
+	}
+	for id, at := range n.notified {
+		if now.Sub(at) >= n.cooldown {
+			delete(n.notified, id)
+		}
+	}
+}
+
+// forget drops the sign-ups and cooldown of user and returns how many sign-ups it dropped
+func (n *slotNotifier) forget(user string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	dropped := 0
+	for server, users := range n.watchers {
+		if _, ok := users[user]; ok {
+			delete(users, user)
+			dropped++
+		}
+		if len(users) == 0 {
+			delete(n.watchers, server)
+		}
+	}
+	delete(n.notified, user)
+	return dropped
+}
+
+// slotNotice is a DM to send
+type slotNotice struct {
+	userID string
+	name   string
+	msg    string
+}
+
+// due takes the sign-ups of servers that have a free slot now and returns their DMs
+// Sign-ups of servers no longer polled are dropped
+func (n *slotNotifier) due(infos []ServerInfo, now time.Time) []slotNotice {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.expire(now)
+
+	polled := make(map[string]*ServerInfo, len(infos))
+	for i := range infos {
+		polled[infos[i].Name] = &infos[i]
+	}
+	var notices []slotNotice
+	for server, users := range n.watchers {
+		info := polled[server]
+		if info == nil {
+			delete(n.watchers, server)
+			continue
+		}
+		if serverDown(*info) || info.Maintenance != nil || info.NumPlayers >= info.MaxPlayers {
+			continue
+		}
+		msg := fmt.Sprintf("🟢 A slot opened on **%s** (%s players) — [Join Server](%s)", server, info.Players, joinURL(info.IP, info.Port))
+		for id, w := range users {
+			notices = append(notices, slotNotice{userID: id, name: w.name, msg: msg})
+			n.notified[id] = now
+		}
+		delete(n.watchers, server)
+	}
+	return notices
+}
+
+// buttons returns a "Notify me" button for every full server, in poll order
+func (n *slotNotifier) buttons(infos []ServerInfo) []discordgo.Button {
+	var buttons []discordgo.Button
+	for _, info := range infos {
+		if !serverFull(info) || len(slotNotifyButtonPrefix)+1+len(info.Name) > 100 {
+			continue // custom IDs are limited to 100 bytes
+		}
+		buttons = append(buttons, discordgo.Button{
+			Label:    truncateRunes("Notify me: "+info.Name, maxButtonLabel),
+			Style:    discordgo.SecondaryButton,
+			CustomID: slotNotifyButtonPrefix + ":" + info.Name,
+			Emoji:    &discordgo.ComponentEmoji{Name: "🔔"},
+		})
+	}
+	return buttons
+}
+
+// notifySlots DMs members whose full server has a free slot after a poll (leader only, no-op when disabled)
+// A failed DM (closed DMs, left the guild) is logged and not retried
+func (b *Bot) notifySlots(infos []ServerInfo) {
+	if b.slots == nil || b.discord == nil || !b.isLeader() {
+		return
+	}
+	for _, notice := range b.slots.due(infos, time.Now()) {
+		ch, err := b.discord.session.UserChannelCreate(notice.userID)
+		if err == nil {
+			_, err = b.discord.session.ChannelMessageSend(ch.ID, notice.msg)
+		}
+		if err != nil {
+			log.Printf("Error sending slot notification to %s: %v", notice.name, err)
+		}
+	}
+}
+
+// runSlotNotifyButton handles a click on "Notify me: <server>"
+func runSlotNotifyButton(b *Bot, user invoker, server string) string {
+	if b.slots == nil {
+		return "❌ Slot notifications are disabled"
+	}
+	var info *ServerInfo
+	if poll := b.lastPoll.Load(); poll != nil {
+		for i := range poll.infos {
+			if poll.infos[i].Name == server {
+				info = &poll.infos[i]
+				break
+			}
+		}
+	}
+	return b.slots.toggle(info, user, time.Now())
+}
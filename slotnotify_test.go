@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// slotPoll is one poll result with Track #1 at players/2 (-1 = offline)
+func slotPoll(players int) []ServerInfo {
+	return []ServerInfo{
+		{Name: "Drift #1", Category: "Drift", Players: "1/10", NumPlayers: 1, MaxPlayers: 10, IP: "203.0.113.10", Port: 8081},
+		{Name: "Track #1", Category: "Track", Players: "x", NumPlayers: players, MaxPlayers: 2, IP: "203.0.113.10", Port: 8082},
+	}
+}
+
+// TestSlotNotifierToggle tests sign-up, cancel, free servers, expiry and the cooldown after a DM
+func TestSlotNotifierToggle(t *testing.T) {
+	n := newSlotNotifier(time.Hour, 15*time.Minute)
+	alice := invoker{id: "11", name: "alice"}
+	now := time.Now()
+	full := slotPoll(2)
+
+	if reply := n.toggle(&full[1], alice, now); !strings.Contains(reply, "I'll DM you") {
+		t.Fatalf("Expected a sign-up, got %q", reply)
+	}
+	if reply := n.toggle(&full[1], alice, now); !strings.Contains(reply, "no longer be notified") {
+		t.Errorf("Expected a second click to cancel, got %q", reply)
+	}
+	if reply := n.toggle(&full[0], alice, now); !strings.Contains(reply, "free slot right now") {
+		t.Errorf("Expected no sign-up for a server with free slots, got %q", reply)
+	}
+	if reply := n.toggle(nil, alice, now); !strings.Contains(reply, "Unknown server") {
+		t.Errorf("Unexpected reply %q", reply)
+	}
+
+	// Expired sign-ups are dropped silently
+	n.toggle(&full[1], alice, now)
+	if notices := n.due(slotPoll(1), now.Add(time.Hour)); len(notices) != 0 {
+		t.Errorf("Expected the sign-up to expire, got %+v", notices)
+	}
+
+	// A DM starts the cooldown
+	n.toggle(&full[1], alice, now)
+	if notices := n.due(full, now.Add(time.Minute)); len(notices) != 0 {
+		t.Errorf("Expected no DM while the server is full, got %+v", notices)
+	}
+	notices := n.due(slotPoll(1), now.Add(2*time.Minute))
+	if len(notices) != 1 || notices[0].userID != "11" || !strings.Contains(notices[0].msg, "Track #1") || !strings.Contains(notices[0].msg, "8082") {
+		t.Fatalf("Expected one DM to alice, got %+v", notices)
+	}
+	if notices := n.due(slotPoll(0), now.Add(3*time.Minute)); len(notices) != 0 {
+		t.Errorf("Expected the sign-up to be used up, got %+v", notices)
+	}
+	if reply := n.toggle(&full[1], alice, now.Add(5*time.Minute)); !strings.Contains(reply, "sign up again in 12m") {
+		t.Errorf("Expected the cooldown, got %q", reply)
+	}
+	if reply := n.toggle(&full[1], alice, now.Add(20*time.Minute)); !strings.Contains(reply, "I'll DM you") {
+		t.Errorf("Expected a sign-up after the cooldown, got %q", reply)
+	}
+}
+
+// TestSlotNotifierButtons tests that only full, online servers get a button
+func TestSlotNotifierButtons(t *testing.T) {
+	n := newSlotNotifier(time.Hour, time.Minute)
+	infos := slotPoll(2)
+	infos[0].NumPlayers = 10
+	infos[0].LastSeen = time.Now() // stale data: not offered
+	buttons := n.buttons(infos)
+	if len(buttons) != 1 || buttons[0].CustomID != "notify_slot:Track #1" {
+		t.Errorf("Expected one button for Track #1, got %+v", buttons)
+	}
+	if buttons := n.buttons(slotPoll(-1)); len(buttons) != 0 {
+		t.Errorf("Expected no button for an offline server, got %+v", buttons)
+	}
+}
+
+// TestNotifySlotsSendsDMs tests the button handler and the DM after a poll with a free slot
+func TestNotifySlotsSendsDMs(t *testing.T) {
+	f := newFakeDiscord()
+	b := newFakeDiscordBot(t, f, testMaintenanceConfig())
+	b.slots = newSlotNotifier(time.Hour, time.Minute)
+	b.lastPoll.Store(&polledServers{infos: slotPoll(2), at: time.Now()})
+
+	if reply := runSlotNotifyButton(b, invoker{id: "11", name: "alice"}, "Track #1"); !strings.Contains(reply, "I'll DM you") {
+		t.Fatalf("Unexpected reply %q", reply)
+	}
+	if rows := b.statusComponents(b.configManager.GetConfig(), slotPoll(2)); len(rows) != 1 {
+		t.Errorf("Expected one row of buttons, got %+v", rows)
+	}
+	b.notifySlots(slotPoll(1))
+	msgs := f.channelMessages("dm-11")
+	if len(msgs) != 1 || !strings.Contains(msgs[0].Content, "A slot opened on **Track #1**") {
+		t.Errorf("Expected a DM to alice, got %+v", msgs)
+	}
+}
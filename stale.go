@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ================= STALE DATA =================
+
+// staleExpireFactor bounds how long last known data is shown: after staleExpireFactor*N intervals
+// without a successful poll the server is reported offline again
+const staleExpireFactor = 10
+
+// staleTracker remembers each server's last successful poll so a failed poll shows the last known
+// numbers instead of flipping to offline; data older than `after` intervals is marked stale
+type staleTracker struct {
+	after int
+
+	mu   sync.Mutex
+	seen map[serverKey]seenServer
+}
+
+// serverKey identifies a server across polls (name and port, like shard merging)
+type serverKey struct {
+	name string
+	port int
+}
+
+// seenServer is the last successful poll result of a server
+type seenServer struct {
+	info ServerInfo
+	at   time.Time
+}
+
+// newStaleTracker creates a tracker marking data stale after `after` update intervals
+func newStaleTracker(after int) *staleTracker {
+	return &staleTracker{after: after, seen: make(map[serverKey]seenServer)}
+}
+
+// apply records successful polls and replaces failed ones with the last known data
+// Servers never seen online, or not seen for staleExpireFactor*after intervals, stay offline
+func (t *staleTracker) apply(infos []ServerInfo, now time.Time, interval time.Duration) []ServerInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	staleAfter := time.Duration(t.after) * interval
+	expireAfter := staleExpireFactor * staleAfter
+	current := make(map[serverKey]bool, len(infos))
+
+	out := make([]ServerInfo, len(infos))
+	for i, info := range infos {
+		key := serverKey{info.Name, info.Port}
+		current[key] = true
+		out[i] = info
+
+		if info.NumPlayers >= 0 {
+			t.seen[key] = seenServer{info: info, at: now}
+			continue
+		}
+		last, ok := t.seen[key]
+		if !ok {
+			continue
+		}
+		age := now.Sub(last.at)
+		if age > expireAfter {
+			log.Printf("Server '%s' not seen for %v, showing it offline", info.Name, age.Round(time.Second))
+			delete(t.seen, key)
+			continue
+		}
+
+		// Last known numbers; the current config decides grouping and address
+		kept := last.info
+		kept.Category = info.Category
+		kept.IP = info.IP
+		kept.LastSeen = last.at
+		kept.Stale = age > staleAfter
+		out[i] = kept
+	}
+
+	// Forget servers removed from the config
+	for key := range t.seen {
+		if !current[key] {
+			delete(t.seen, key)
+		}
+	}
+	return out
+}
+
+// trackStaleness applies stale tracking to a poll result (no-op when disabled)
+func (b *Bot) trackStaleness(infos []ServerInfo, cfg *Config) []ServerInfo {
+	if b.stale == nil {
+		return infos
+	}
+	return b.stale.apply(infos, time.Now(), time.Duration(cfg.UpdateInterval)*time.Second)
+}
+
+// formatDataAge renders the age of stale data compactly ("45s", "5m", "2h10m")
+func formatDataAge(d time.Duration) string {
+	return string(appendDataAge(nil, d))
+}
+
+// appendDataAge appends formatDataAge(d) to dst
+func appendDataAge(dst []byte, d time.Duration) []byte {
+	switch {
+	case d < time.Minute:
+		return append(strconv.AppendInt(dst, int64(d/time.Second), 10), 's')
+	case d < time.Hour:
+		return append(strconv.AppendInt(dst, int64(d/time.Minute), 10), 'm')
+	default:
+		dst = append(strconv.AppendInt(dst, int64(d/time.Hour), 10), 'h')
+		return append(strconv.AppendInt(dst, int64(d/time.Minute)%60, 10), 'm')
+	}
+}
+
+// staleTrackerFromEnv returns a tracker if STALE_AFTER_INTERVALS is set, nil otherwise (failed polls show offline)
+func staleTrackerFromEnv() (*staleTracker, error) {
+	v := os.Getenv("STALE_AFTER_INTERVALS")
+	if v == "" || v == "0" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid STALE_AFTER_INTERVALS %q: must be a positive number of update intervals", v)
+	}
+	log.Printf("Stale data tracking enabled: failed polls keep the last known data, marked stale after %d intervals", n)
+	return newStaleTracker(n), nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStaleTracker_KeepsLastKnownData tests that a failed poll shows the last known data, stale after N intervals
+func TestStaleTracker_KeepsLastKnownData(t *testing.T) {
+	tr := newStaleTracker(2)
+	interval := 30 * time.Second
+	start := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	online := ServerInfo{Name: "Drift #1", Category: "Drift", Map: "ebisu", Players: "5/20", NumPlayers: 5, MaxPlayers: 20, Port: 8081}
+	failed := offlineServerInfo(Server{Name: "Drift #1", Category: "Drift", Port: 8081})
+
+	tr.apply([]ServerInfo{online}, start, interval)
+
+	// Within N intervals: last known numbers, not yet stale
+	got := tr.apply([]ServerInfo{failed}, start.Add(time.Minute), interval)[0]
+	if got.NumPlayers != 5 || got.Map != "ebisu" || got.Stale {
+		t.Errorf("Expected fresh last known data, got %+v", got)
+	}
+	if !got.LastSeen.Equal(start) {
+		t.Errorf("Expected LastSeen %v, got %v", start, got.LastSeen)
+	}
+
+	// Older than N intervals: stale
+	got = tr.apply([]ServerInfo{failed}, start.Add(90*time.Second), interval)[0]
+	if got.NumPlayers != 5 || !got.Stale {
+		t.Errorf("Expected stale last known data, got %+v", got)
+	}
+
+	// A successful poll clears the stale state
+	got = tr.apply([]ServerInfo{online}, start.Add(2*time.Minute), interval)[0]
+	if got.Stale || !got.LastSeen.IsZero() {
+		t.Errorf("Expected fresh data after a successful poll, got %+v", got)
+	}
+}
+
+// TestStaleTracker_Expires tests that servers not seen for staleExpireFactor*N intervals show offline again
+func TestStaleTracker_Expires(t *testing.T) {
+	tr := newStaleTracker(1)
+	interval := 30 * time.Second
+	start := time.Now()
+	online := ServerInfo{Name: "Track #1", Category: "Track", NumPlayers: 2, Port: 8082}
+	failed := offlineServerInfo(Server{Name: "Track #1", Category: "Track", Port: 8082})
+
+	tr.apply([]ServerInfo{online}, start, interval)
+	got := tr.apply([]ServerInfo{failed}, start.Add(staleExpireFactor*interval+time.Second), interval)[0]
+	if got.NumPlayers != -1 || got.Stale {
+		t.Errorf("Expected offline after expiry, got %+v", got)
+	}
+}
+
+// TestStaleTracker_NeverSeenAndRemoved tests that unknown servers stay offline and removed servers are forgotten
+func TestStaleTracker_NeverSeenAndRemoved(t *testing.T) {
+	tr := newStaleTracker(3)
+	now := time.Now()
+	failed := offlineServerInfo(Server{Name: "New", Category: "Drift", Port: 9000})
+	if got := tr.apply([]ServerInfo{failed}, now, time.Second)[0]; got.NumPlayers != -1 {
+		t.Errorf("Expected never-seen server offline, got %+v", got)
+	}
+
+	tr.apply([]ServerInfo{{Name: "Old", NumPlayers: 1, Port: 9001}}, now, time.Second)
+	tr.apply([]ServerInfo{failed}, now, time.Second)
+	if _, ok := tr.seen[serverKey{"Old", 9001}]; ok {
+		t.Error("Expected removed server to be forgotten")
+	}
+}
+
+// TestStaleRendering tests the greyed emoji and age note in the embed and Slack/Matrix output
+func TestStaleRendering(t *testing.T) {
+	cfg := testStatusConfig()
+	now := time.Now()
+	infos := []ServerInfo{{Name: "Drift #1", Category: "Drift", Map: "ebisu", Players: "5/20", NumPlayers: 5, MaxPlayers: 20,
+		LastSeen: now.Add(-5 * time.Minute), Stale: true}}
+
+	embed := buildEmbed(infos, cfg)
+	var field string
+	for _, f := range embed.Fields {
+		if strings.Contains(f.Name, "Drift #1") {
+			field = f.Name + " " + f.Value
+		}
+	}
+	if !strings.Contains(field, ":white_circle:") || !strings.Contains(field, "data 5m old") {
+		t.Errorf("Expected stale marker in embed field, got %q", field)
+	}
+
+	snap := buildStatusSnapshot(infos, cfg, now)
+	srv := snap.Categories[0].Servers[0]
+	if !srv.Stale || srv.LastSeen == nil || srv.StaleAge(snap.UpdatedAt) != "5m" {
+		t.Errorf("Expected stale snapshot entry, got %+v", srv)
+	}
+
+	_, blocks := renderSlackMessage(snap, "Status")
+	var slackText strings.Builder
+	for _, b := range blocks {
+		if txt, ok := b["text"].(map[string]any); ok {
+			slackText.WriteString(txt["text"].(string))
+		}
+	}
+	if !strings.Contains(slackText.String(), ":white_circle: Drift #1") || !strings.Contains(slackText.String(), "data 5m old") {
+		t.Errorf("Expected stale marker in Slack blocks, got %q", slackText.String())
+	}
+
+	body := renderMatrixMessage(snap, "Status")["body"].(string)
+	if !strings.Contains(body, "⚪ Drift #1") || !strings.Contains(body, "data 5m old") {
+		t.Errorf("Expected stale marker in Matrix body, got %q", body)
+	}
+}
+
+// TestFormatDataAge tests compact age formatting
+func TestFormatDataAge(t *testing.T) {
+	tests := map[time.Duration]string{
+		45 * time.Second:               "45s",
+		5*time.Minute + 20*time.Second: "5m",
+		2*time.Hour + 10*time.Minute:   "2h10m",
+	}
+	for d, want := range tests {
+		if got := formatDataAge(d); got != want {
+			t.Errorf("formatDataAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+// TestStaleTrackerFromEnv tests enablement and rejected values
+func TestStaleTrackerFromEnv(t *testing.T) {
+	t.Setenv("STALE_AFTER_INTERVALS", "")
+	if tr, err := staleTrackerFromEnv(); tr != nil || err != nil {
+		t.Errorf("Unset: got %v, %v", tr, err)
+	}
+	t.Setenv("STALE_AFTER_INTERVALS", "4")
+	if tr, err := staleTrackerFromEnv(); err != nil || tr == nil || tr.after != 4 {
+		t.Errorf("4: got %v, %v", tr, err)
+	}
+	t.Setenv("STALE_AFTER_INTERVALS", "-1")
+	if _, err := staleTrackerFromEnv(); err == nil {
+		t.Error("Expected negative value to be rejected")
+	}
+}
@@ -0,0 +1,761 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bombom/absa-ac/pkg/supervisor"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= CHAMPIONSHIP STANDINGS =================
+
+const (
+	// defaultStandingsTitle heads the standings embed unless STANDINGS_TITLE is set
+	defaultStandingsTitle = "Championship Standings"
+	// standingsStateFile stores the ID of the standings message next to config.json
+	standingsStateFile = "standings_message_id"
+	// standingsEmbedRows bounds the drivers listed in the embed (all of them are in the API)
+	standingsEmbedRows = 30
+	// resultsSummaryRows bounds the finishers listed in a race results summary
+	resultsSummaryRows = 20
+	// raceSessionType is the AC results "Type" of a race; practice and qualifying score no points
+	raceSessionType = "RACE"
+)
+
+// defaultStandingsPoints awards points by finishing position (25-18-15-12-10-8-6-4-2-1)
+var defaultStandingsPoints = []int{25, 18, 15, 12, 10, 8, 6, 4, 2, 1}
+
+// acResultFile is the subset of an AC server results file (results/2026_10_18_18_00_RACE.json) that is scored
+type acResultFile struct {
+	TrackName   string `json:"TrackName"`
+	TrackConfig string `json:"TrackConfig"`
+	Type        string `json:"Type"`
+	Cars        []struct {
+		CarID  int `json:"CarId"`
+		Driver struct {
+			Name string `json:"Name"`
+			Team string `json:"Team"`
+			GUID string `json:"Guid"`
+		} `json:"Driver"`
+	} `json:"Cars"`
+	// Result is in finishing order
+	Result []struct {
+		DriverName string `json:"DriverName"`
+		DriverGUID string `json:"DriverGuid"`
+		CarID      int    `json:"CarId"`
+		CarModel   string `json:"CarModel"`
+		BestLap    int64  `json:"BestLap"`   // milliseconds
+		TotalTime  int64  `json:"TotalTime"` // milliseconds, 0 = did not finish
+	} `json:"Result"`
+}
+
+// raceFinisher is one classified driver of a race
+type raceFinisher struct {
+	guid      string
+	name      string
+	team      string
+	car       string
+	finished  bool
+	totalTime time.Duration
+	bestLap   time.Duration
+}
+
+// raceResult is a parsed AC results file
+type raceResult struct {
+	id          string // file name without .json
+	track       string
+	sessionType string
+	at          time.Time
+	finishers   []raceFinisher // in finishing order, drivers without a GUID (empty slots) left out
+}
+
+// parseRaceResult decodes an AC results file; drivers without a GUID (free entry list slots) are left out
+func parseRaceResult(data []byte) (*raceResult, error) {
+	var f acResultFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("%w: invalid JSON: %v", api.ErrInvalidResults, err)
+	}
+	if f.TrackName == "" || f.Type == "" {
+		return nil, fmt.Errorf("%w: TrackName and Type are required", api.ErrInvalidResults)
+	}
+
+	teams := make(map[int]string, len(f.Cars))
+	for _, c := range f.Cars {
+		teams[c.CarID] = c.Driver.Team
+	}
+	r := &raceResult{track: f.TrackName, sessionType: strings.ToUpper(f.Type)}
+	if f.TrackConfig != "" {
+		r.track += " " + f.TrackConfig
+	}
+	for _, e := range f.Result {
+		if e.DriverGUID == "" {
+			continue
+		}
+		r.finishers = append(r.finishers, raceFinisher{
+			guid:      e.DriverGUID,
+			name:      e.DriverName,
+			team:      teams[e.CarID],
+			car:       e.CarModel,
+			finished:  e.TotalTime > 0,
+			totalTime: time.Duration(e.TotalTime) * time.Millisecond,
+			bestLap:   time.Duration(e.BestLap) * time.Millisecond,
+		})
+	}
+	return r, nil
+}
+
+// resultTimeFromName reads the session time (UTC) from an AC results file name (2026_10_18_18_00_RACE)
+// The server does not zero-pad the fields, so the name is not parsed with a time layout
+func resultTimeFromName(id string) (time.Time, bool) {
+	parts := strings.Split(id, "_")
+	if len(parts) < 5 {
+		return time.Time{}, false
+	}
+	var f [5]int
+	for i := range f {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return time.Time{}, false
+		}
+		f[i] = n
+	}
+	if f[1] < 1 || f[1] > 12 || f[2] < 1 || f[2] > 31 || f[3] > 23 || f[4] > 59 {
+		return time.Time{}, false
+	}
+	return time.Date(f[0], time.Month(f[1]), f[2], f[3], f[4], 0, 0, time.UTC), true
+}
+
+// driverStanding is one driver in the championship
+type driverStanding struct {
+	guid       string
+	name       string // from the latest race
+	team       string
+	points     int
+	races      int
+	wins       int
+	podiums    int
+	bestFinish int
+}
+
+// computeStandings scores the race sessions of results (oldest first) with points by finishing position
+// Drivers who did not finish score nothing but count the race; ties are broken by wins, podiums, then name
+func computeStandings(results []*raceResult, points []int) []driverStanding {
+	byGUID := make(map[string]*driverStanding)
+	for _, r := range results {
+		if r.sessionType != raceSessionType {
+			continue
+		}
+		for i, f := range r.finishers {
+			d := byGUID[f.guid]
+			if d == nil {
+				d = &driverStanding{guid: f.guid}
+				byGUID[f.guid] = d
+			}
+			d.name, d.team = f.name, f.team
+			d.races++
+			if !f.finished {
+				continue
+			}
+			pos := i + 1
+			if i < len(points) {
+				d.points += points[i]
+			}
+			if pos == 1 {
+				d.wins++
+			}
+			if pos <= 3 {
+				d.podiums++
+			}
+			if d.bestFinish == 0 || pos < d.bestFinish {
+				d.bestFinish = pos
+			}
+		}
+	}
+
+	out := make([]driverStanding, 0, len(byGUID))
+	for _, d := range byGUID {
+		out = append(out, *d)
+	}
+	slices.SortFunc(out, func(a, b driverStanding) int {
+		if c := cmp.Compare(b.points, a.points); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.wins, a.wins); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.podiums, a.podiums); c != 0 {
+			return c
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	return out
+}
+
+// cachedResult is a results file parsed once per modification
+type cachedResult struct {
+	modTime time.Time
+	size    int64
+	digest  string      // content hash, so an upload of a file already in the folder is recognized
+	result  *raceResult // nil when the file is invalid
+}
+
+// resultsDigest hashes a results file
+func resultsDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// standingsModule reads AC results files from a folder, computes the championship and keeps
+// a standings embed up to date in a Discord channel (bot mode)
+type standingsModule struct {
+	dir       string
+	channelID string // empty = no Discord message, API only
+	title     string
+	points    []int
+	statePath string
+	// drivers shows linked drivers as mentions (nil = names only)
+	drivers *driverRegistry
+
+	// running keeps refreshes from overlapping when a scan takes longer than the update interval
+	running atomic.Bool
+
+	mu        sync.Mutex
+	files     map[string]cachedResult // by file name
+	standings []driverStanding
+	races     int
+	updatedAt time.Time
+	messageID string
+	posted    string // fingerprint of the last posted embed
+	loaded    bool
+}
+
+func newStandingsModule(dir, channelID, title string, points []int, statePath string) *standingsModule {
+	if title == "" {
+		title = defaultStandingsTitle
+	}
+	if len(points) == 0 {
+		points = defaultStandingsPoints
+	}
+	return &standingsModule{
+		dir:       dir,
+		channelID: channelID,
+		title:     title,
+		points:    points,
+		statePath: statePath,
+		files:     make(map[string]cachedResult),
+	}
+}
+
+// standingsFromEnv returns the standings module if STANDINGS_RESULTS_DIR is set, nil otherwise
+// STANDINGS_CHANNEL_ID (bot mode) posts the standings embed; STANDINGS_POINTS overrides the points table
+func standingsFromEnv(configPath string) (*standingsModule, error) {
+	dir := os.Getenv("STANDINGS_RESULTS_DIR")
+	if dir == "" {
+		return nil, nil
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("STANDINGS_RESULTS_DIR %q is not a readable directory", dir)
+	}
+	var points []int
+	if v := os.Getenv("STANDINGS_POINTS"); v != "" {
+		for _, p := range strings.Split(v, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid STANDINGS_POINTS %q: use non-negative numbers by position, e.g. 25,18,15", v)
+			}
+			points = append(points, n)
+		}
+	}
+	channelID := os.Getenv("STANDINGS_CHANNEL_ID")
+	log.Printf("Championship standings enabled from %s (posted to channel: %q)", dir, channelID)
+	return newStandingsModule(dir, channelID, os.Getenv("STANDINGS_TITLE"),
+		points, filepath.Join(filepath.Dir(configPath), standingsStateFile)), nil
+}
+
+// scan parses new and changed results files, forgets removed ones and recomputes the standings
+// Reports whether anything changed
+func (m *standingsModule) scan(now time.Time) bool {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		log.Printf("Error reading results folder %s: %v", m.dir, err)
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	changed := false
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(name), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		seen[name] = true
+		if c, ok := m.files[name]; ok && c.modTime.Equal(info.ModTime()) && c.size == info.Size() {
+			continue
+		}
+		changed = true
+		c := cachedResult{modTime: info.ModTime(), size: info.Size()}
+		data, err := os.ReadFile(filepath.Join(m.dir, name))
+		if err == nil {
+			c.digest = resultsDigest(data)
+			c.result, err = parseRaceResult(data)
+		}
+		if err != nil {
+			log.Printf("Skipping results file %s: %v", name, err)
+		} else {
+			c.result.id = strings.TrimSuffix(name, filepath.Ext(name))
+			c.result.at = info.ModTime()
+			if t, ok := resultTimeFromName(c.result.id); ok {
+				c.result.at = t
+			}
+		}
+		m.files[name] = c
+	}
+	for name := range m.files {
+		if !seen[name] {
+			delete(m.files, name)
+			changed = true
+		}
+	}
+	if !changed && !m.updatedAt.IsZero() {
+		return false
+	}
+
+	results := make([]*raceResult, 0, len(m.files))
+	for _, c := range m.files {
+		if c.result != nil {
+			results = append(results, c.result)
+		}
+	}
+	slices.SortFunc(results, func(a, b *raceResult) int {
+		if c := a.at.Compare(b.at); c != 0 {
+			return c
+		}
+		return strings.Compare(a.id, b.id)
+	})
+	m.standings = computeStandings(results, m.points)
+	m.races = 0
+	for _, r := range results {
+		if r.sessionType == raceSessionType {
+			m.races++
+		}
+	}
+	m.updatedAt = now
+	return true
+}
+
+// ingest stores an uploaded results file in the results folder as sessionID.json and rescans it
+// sessionID defaults to a hash of the content; a known session ID or identical content is a duplicate
+// and returns the stored session without writing anything
+func (m *standingsModule) ingest(sessionID string, data []byte, now time.Time) (string, *raceResult, bool, error) {
+	r, err := parseRaceResult(data)
+	if err != nil {
+		return "", nil, false, err
+	}
+	digest := resultsDigest(data)
+	if sessionID == "" {
+		sessionID = "upload_" + digest[:16]
+	}
+	r.id = sessionID
+
+	m.mu.Lock()
+	for name, c := range m.files {
+		if c.digest == digest || name == sessionID+".json" {
+			m.mu.Unlock()
+			return strings.TrimSuffix(name, filepath.Ext(name)), r, true, nil
+		}
+	}
+	path := filepath.Join(m.dir, sessionID+".json")
+	if _, err := os.Stat(path); err == nil {
+		m.mu.Unlock()
+		return sessionID, r, true, nil // written but not scanned yet
+	}
+	err = writeFileAtomic(path, data)
+	m.mu.Unlock()
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	m.scan(now)
+	return sessionID, r, false, nil
+}
+
+// prune deletes the results files of sessions before cutoff (by file time for invalid files), rescans the
+// folder and returns how many files it deleted
+func (m *standingsModule) prune(cutoff, now time.Time) (int, error) {
+	m.scan(now)
+	m.mu.Lock()
+	var old []string
+	for name, c := range m.files {
+		at := c.modTime
+		if c.result != nil {
+			at = c.result.at
+		}
+		if at.Before(cutoff) {
+			old = append(old, name)
+		}
+	}
+	m.mu.Unlock()
+
+	deleted := 0
+	var err error
+	for _, name := range old {
+		if rmErr := os.Remove(filepath.Join(m.dir, name)); rmErr != nil && !os.IsNotExist(rmErr) {
+			err = rmErr
+			continue
+		}
+		deleted++
+	}
+	if deleted > 0 {
+		m.scan(now)
+	}
+	return deleted, err
+}
+
+// racedSince returns the GUIDs of the drivers in sessions from cutoff on; ok is false while the folder has
+// not been read yet. A nil module returns an empty set
+func (m *standingsModule) racedSince(cutoff time.Time) (map[string]bool, bool) {
+	raced := make(map[string]bool)
+	if m == nil {
+		return raced, true
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.updatedAt.IsZero() {
+		return nil, false
+	}
+	for _, c := range m.files {
+		if c.result == nil || c.result.at.Before(cutoff) {
+			continue
+		}
+		for _, f := range c.result.finishers {
+			raced[f.guid] = true
+		}
+	}
+	return raced, true
+}
+
+// anonymize rewrites the results files that mention guid with pseudonym as the GUID and without the driver's
+// name and nation, rescans the folder and returns how many files it rewrote
+func (m *standingsModule) anonymize(guid, pseudonym string, now time.Time) (int, error) {
+	m.scan(now)
+	m.mu.Lock()
+	rewritten := 0
+	var err error
+	for _, name := range slices.Sorted(maps.Keys(m.files)) {
+		path := filepath.Join(m.dir, name)
+		data, readErr := os.ReadFile(path)
+		if readErr != nil || !bytes.Contains(data, []byte(guid)) {
+			continue
+		}
+		var doc any
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err = dec.Decode(&doc); err != nil {
+			err = fmt.Errorf("failed to anonymize %s: %w", name, err)
+			break
+		}
+		out, _ := json.MarshalIndent(anonymizeDriver(doc, guid, pseudonym), "", "  ")
+		if err = writeFileAtomic(path, out); err != nil {
+			break
+		}
+		rewritten++
+	}
+	m.mu.Unlock()
+	if rewritten > 0 {
+		m.scan(now)
+	}
+	return rewritten, err
+}
+
+// anonymizeDriver replaces guid in an AC results document: objects with it as Guid or DriverGuid get pseudonym
+// instead and lose the driver's name and nation; GUID lists (GuidsList) get pseudonym as well
+func anonymizeDriver(v any, guid, pseudonym string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		if v["Guid"] == guid || v["DriverGuid"] == guid {
+			for key, blank := range map[string]string{
+				"Guid": pseudonym, "DriverGuid": pseudonym, "Name": "Anonymized driver", "DriverName": "Anonymized driver", "Nation": "",
+			} {
+				if _, ok := v[key]; ok {
+					v[key] = blank
+				}
+			}
+		}
+		for key, x := range v {
+			v[key] = anonymizeDriver(x, guid, pseudonym)
+		}
+	case []any:
+		for i, x := range v {
+			if x == guid {
+				v[i] = pseudonym
+			} else {
+				v[i] = anonymizeDriver(x, guid, pseudonym)
+			}
+		}
+	}
+	return v
+}
+
+// formatRaceTime renders a lap or race time as 2:18.512 (1:02:03.456 from an hour)
+func formatRaceTime(d time.Duration) string {
+	ms := d.Milliseconds()
+	h, m, s := ms/3_600_000, ms/60_000%60, ms/1000%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d.%03d", h, m, s, ms%1000)
+	}
+	return fmt.Sprintf("%d:%02d.%03d", m, s, ms%1000)
+}
+
+// resultsSummaryEmbed renders the finishing order of a race with the points scored and the fastest lap
+// Linked drivers are shown as mentions
+func resultsSummaryEmbed(r *raceResult, points []int, drivers *driverRegistry) *discordgo.MessageEmbed {
+	var sb strings.Builder
+	var fastest *raceFinisher
+	for i, f := range r.finishers {
+		if f.bestLap > 0 && (fastest == nil || f.bestLap < fastest.bestLap) {
+			fastest = &r.finishers[i]
+		}
+		if i == resultsSummaryRows {
+			fmt.Fprintf(&sb, "*… and %d more*\n", len(r.finishers)-resultsSummaryRows)
+			continue
+		}
+		if i > resultsSummaryRows {
+			continue
+		}
+		if !f.finished {
+			fmt.Fprintf(&sb, "**DNF** %s\n", drivers.mention(f.guid, f.name))
+			continue
+		}
+		fmt.Fprintf(&sb, "**%d.** %s — %s", i+1, drivers.mention(f.guid, f.name), formatRaceTime(f.totalTime))
+		if i < len(points) && points[i] > 0 {
+			fmt.Fprintf(&sb, " (+%d)", points[i])
+		}
+		sb.WriteByte('\n')
+	}
+	if len(r.finishers) == 0 {
+		sb.WriteString("No classified drivers\n")
+	}
+	if fastest != nil {
+		fmt.Fprintf(&sb, "\n⏱️ Fastest lap: %s %s", drivers.mention(fastest.guid, fastest.name), formatRaceTime(fastest.bestLap))
+	}
+	return &discordgo.MessageEmbed{
+		Title:       "🏁 Race Results: " + r.track,
+		Description: sb.String(),
+		Color:       0x5865f2,
+		Footer:      &discordgo.MessageEmbedFooter{Text: r.id},
+	}
+}
+
+// postSummary posts the results summary of a race to the standings channel
+func (m *standingsModule) postSummary(session DiscordSession, r *raceResult) {
+	if m.channelID == "" || r.sessionType != raceSessionType {
+		return
+	}
+	if _, err := session.ChannelMessageSendEmbed(m.channelID, resultsSummaryEmbed(r, m.points, m.drivers)); err != nil {
+		log.Printf("Error posting results summary to channel %s: %v", m.channelID, err)
+	}
+}
+
+// embed renders the standings (caller holds m.mu)
+func (m *standingsModule) embed() *discordgo.MessageEmbed {
+	var sb strings.Builder
+	for i, d := range m.standings {
+		if i == standingsEmbedRows {
+			fmt.Fprintf(&sb, "*… and %d more*", len(m.standings)-standingsEmbedRows)
+			break
+		}
+		fmt.Fprintf(&sb, "**%d.** %s — **%d** pts", i+1, m.drivers.mention(d.guid, d.name), d.points)
+		if d.wins > 0 {
+			fmt.Fprintf(&sb, " (%d %s)", d.wins, plural(d.wins, "win", "wins"))
+		}
+		sb.WriteByte('\n')
+	}
+	if len(m.standings) == 0 {
+		sb.WriteString("No races scored yet")
+	}
+	return &discordgo.MessageEmbed{
+		Title:       "🏆 " + m.title,
+		Description: sb.String(),
+		Color:       0xf0b232,
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%d %s", m.races, plural(m.races, "race", "races"))},
+		Timestamp:   m.updatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// plural picks the singular or plural word for n
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// loadMessageID reads the standings message ID once (caller holds m.mu)
+func (m *standingsModule) loadMessageID() {
+	if m.loaded || m.statePath == "" {
+		return
+	}
+	m.loaded = true
+	data, err := os.ReadFile(m.statePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Warning: failed to read standings state %s: %v", m.statePath, err)
+		}
+		return
+	}
+	m.messageID = strings.TrimSpace(string(data))
+}
+
+// saveMessageID writes the standings message ID (caller holds m.mu)
+func (m *standingsModule) saveMessageID() {
+	if m.statePath == "" {
+		return
+	}
+	if err := os.WriteFile(m.statePath, []byte(m.messageID+"\n"), 0600); err != nil {
+		log.Printf("Warning: failed to save standings state %s: %v", m.statePath, err)
+	}
+}
+
+// publish posts the standings embed, or edits the existing message when the standings changed
+// A deleted message is posted again
+func (m *standingsModule) publish(session DiscordSession) {
+	if m.channelID == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loadMessageID()
+
+	embed := m.embed()
+	data, _ := json.Marshal(struct{ Title, Description, Footer string }{embed.Title, embed.Description, embed.Footer.Text})
+	sum := sha256.Sum256(data)
+	fp := hex.EncodeToString(sum[:8])
+	if m.messageID != "" && m.posted == fp {
+		return
+	}
+
+	if m.messageID != "" {
+		_, err := session.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			Channel: m.channelID, ID: m.messageID, Embed: embed,
+		})
+		if err == nil {
+			m.posted = fp
+			return
+		}
+		if !isNotFound(err) {
+			log.Printf("Error updating standings message: %v", err)
+			return
+		}
+		log.Printf("Standings message %s was deleted, posting a new one", m.messageID)
+	}
+
+	msg, err := session.ChannelMessageSendEmbed(m.channelID, embed)
+	if err != nil {
+		log.Printf("Error posting standings to channel %s: %v", m.channelID, err)
+		return
+	}
+	m.messageID, m.posted = msg.ID, fp
+	m.saveMessageID()
+}
+
+// refreshStandings rescans the results folder in the background and updates the standings message
+// Every replica scans (each serves the API); only the leader posts in bot mode
+func (b *Bot) refreshStandings() {
+	m := b.standings
+	if m == nil || !m.running.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer m.running.Store(false)
+		defer supervisor.Recover("standings refresh", log.Default())
+		m.scan(time.Now())
+		if b.discord != nil && b.isLeader() {
+			m.publish(b.discord.session)
+		}
+	}()
+}
+
+// resultsIngester adapts the standings module to api.ResultsIngester
+// A new race posts its results summary and updates the standings message (leader only in bot mode)
+type resultsIngester struct {
+	bot *Bot
+}
+
+// IngestResults implements api.ResultsIngester
+func (i *resultsIngester) IngestResults(sessionID string, data []byte) (api.ResultsUpload, error) {
+	b := i.bot
+	id, r, duplicate, err := b.standings.ingest(sessionID, data, time.Now())
+	if err != nil {
+		return api.ResultsUpload{}, err
+	}
+	upload := api.ResultsUpload{SessionID: id, Track: r.track, Type: r.sessionType, Drivers: len(r.finishers), Duplicate: duplicate}
+	if duplicate {
+		log.Printf("Results upload is a duplicate of session %s, ignored", id)
+		return upload, nil
+	}
+	log.Printf("Stored results of %s session %s (%s, %d drivers)", r.sessionType, id, r.track, len(r.finishers))
+	if b.discord != nil && b.isLeader() {
+		session := b.discord.session
+		go func() {
+			defer supervisor.Recover("results summary", log.Default())
+			b.standings.postSummary(session, r)
+			b.standings.publish(session)
+		}()
+	}
+	return upload, nil
+}
+
+// standingsProvider adapts the standings module to api.StandingsProvider
+type standingsProvider struct {
+	module *standingsModule
+}
+
+// Standings implements api.StandingsProvider
+func (p *standingsProvider) Standings() api.Standings {
+	m := p.module
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := api.Standings{
+		Title:     m.title,
+		Races:     m.races,
+		UpdatedAt: m.updatedAt.UTC(),
+		Drivers:   make([]api.DriverStanding, 0, len(m.standings)),
+	}
+	for i, d := range m.standings {
+		out.Drivers = append(out.Drivers, api.DriverStanding{
+			Position:      i + 1,
+			Driver:        d.name,
+			DiscordUserID: m.drivers.userOf(d.guid),
+			Team:          d.team,
+			Points:        d.points,
+			Races:         d.races,
+			Wins:          d.wins,
+			Podiums:       d.podiums,
+			BestFinish:    d.bestFinish,
+		})
+	}
+	return out
+}
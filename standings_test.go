@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// copyResults copies the named results fixtures into dir
+func copyResults(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join("testdata", "results", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatalf("Failed to write results file: %v", err)
+		}
+	}
+}
+
+// TestParseRaceResult tests the track name, finishing order, DNFs and skipping empty slots
+func TestParseRaceResult(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "results", "2026_10_11_18_00_RACE.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	r, err := parseRaceResult(data)
+	if err != nil {
+		t.Fatalf("parseRaceResult failed: %v", err)
+	}
+	if r.track != "ks_spa" || r.sessionType != raceSessionType {
+		t.Errorf("Unexpected session %q %q", r.track, r.sessionType)
+	}
+	if len(r.finishers) != 3 {
+		t.Fatalf("Expected 3 classified drivers (empty slot skipped), got %d", len(r.finishers))
+	}
+	if f := r.finishers[0]; f.name != "Alice" || f.team != "Red Team" || !f.finished || f.bestLap != 138512*time.Millisecond {
+		t.Errorf("Unexpected winner %+v", f)
+	}
+	if r.finishers[2].finished {
+		t.Error("Expected a TotalTime of 0 to be a DNF")
+	}
+
+	for _, bad := range []string{`{`, `{"Type": "RACE"}`, `[]`} {
+		if _, err := parseRaceResult([]byte(bad)); err == nil {
+			t.Errorf("Expected an error for %s", bad)
+		}
+	}
+
+	if at, ok := resultTimeFromName("2026_10_18_18_00_RACE"); !ok || !at.Equal(time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected session time %v %v", at, ok)
+	}
+	if _, ok := resultTimeFromName("upload"); ok {
+		t.Error("Expected no session time from a free-form name")
+	}
+}
+
+// TestStandingsScan tests scoring race sessions only, DNFs, drivers keyed by GUID and rescanning changes
+func TestStandingsScan(t *testing.T) {
+	dir := t.TempDir()
+	copyResults(t, dir, "2026_10_11_18_00_RACE.json", "2026_10_18_17_30_QUALIFY.json", "2026_10_18_18_00_RACE.json")
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644)
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644)
+
+	m := newStandingsModule(dir, "", "", nil, "")
+	if !m.scan(time.Now()) {
+		t.Fatal("Expected the first scan to report a change")
+	}
+	got := (&standingsProvider{module: m}).Standings()
+	if got.Races != 2 || len(got.Drivers) != 3 {
+		t.Fatalf("Expected 2 races and 3 drivers, got %+v", got)
+	}
+	want := []struct {
+		name   string
+		points int
+		wins   int
+		races  int
+	}{{"Bobby", 43, 1, 2}, {"Alice", 40, 1, 2}, {"Carol", 18, 0, 2}}
+	for i, w := range want {
+		d := got.Drivers[i]
+		if d.Position != i+1 || d.Driver != w.name || d.Points != w.points || d.Wins != w.wins || d.Races != w.races {
+			t.Errorf("Position %d: got %+v, want %+v", i+1, d, w)
+		}
+	}
+	if got.Drivers[2].BestFinish != 2 || got.Drivers[2].Podiums != 1 {
+		t.Errorf("Expected Carol's DNF not to count as a finish, got %+v", got.Drivers[2])
+	}
+
+	if m.scan(time.Now()) {
+		t.Error("Expected an unchanged folder not to report a change")
+	}
+	os.Remove(filepath.Join(dir, "2026_10_18_18_00_RACE.json"))
+	if !m.scan(time.Now()) {
+		t.Fatal("Expected a removed file to report a change")
+	}
+	if got := (&standingsProvider{module: m}).Standings(); got.Races != 1 || got.Drivers[0].Driver != "Alice" || got.Drivers[0].Points != 25 {
+		t.Errorf("Unexpected standings after removal: %+v", got)
+	}
+}
+
+// TestStandingsPublish tests posting once, editing on change, reposting a deleted message and the saved message ID
+func TestStandingsPublish(t *testing.T) {
+	f := newFakeDiscord()
+	dir := t.TempDir()
+	state := filepath.Join(t.TempDir(), standingsStateFile)
+	copyResults(t, dir, "2026_10_11_18_00_RACE.json")
+
+	m := newStandingsModule(dir, fakeChannelID, "Autumn Cup", []int{10, 6, 4}, state)
+	m.scan(time.Now())
+	m.publish(f)
+	msgs := f.channelMessages(fakeChannelID)
+	if len(msgs) != 1 {
+		t.Fatalf("Expected one standings message, got %d", len(msgs))
+	}
+	embed := msgs[0].Embeds[0]
+	if !strings.Contains(embed.Title, "Autumn Cup") || !strings.Contains(embed.Description, "**1.** Alice — **10** pts (1 win)") {
+		t.Errorf("Unexpected embed %q %q", embed.Title, embed.Description)
+	}
+	if data, _ := os.ReadFile(state); strings.TrimSpace(string(data)) != msgs[0].ID {
+		t.Errorf("Expected the message ID to be saved, got %q", data)
+	}
+
+	m.publish(f)
+	if n := f.count("ChannelMessageEditComplex"); n != 0 {
+		t.Errorf("Expected unchanged standings not to be edited, got %d edits", n)
+	}
+
+	copyResults(t, dir, "2026_10_18_18_00_RACE.json")
+	m.scan(time.Now())
+	m.publish(f)
+	msgs = f.channelMessages(fakeChannelID)
+	if len(msgs) != 1 || !strings.Contains(msgs[0].Embeds[0].Description, "**1.** Bobby — **16** pts") {
+		t.Fatalf("Expected the message to be edited, got %d messages", len(msgs))
+	}
+
+	// A restarted bot edits the saved message; a deleted one is posted again
+	restarted := newStandingsModule(dir, fakeChannelID, "Autumn Cup", []int{10, 6, 4}, state)
+	restarted.scan(time.Now())
+	f.ChannelMessageDelete(fakeChannelID, msgs[0].ID)
+	restarted.publish(f)
+	msgs = f.channelMessages(fakeChannelID)
+	if len(msgs) != 1 {
+		t.Fatalf("Expected the deleted message to be reposted, got %d messages", len(msgs))
+	}
+	if data, _ := os.ReadFile(state); strings.TrimSpace(string(data)) != msgs[0].ID {
+		t.Errorf("Expected the new message ID to be saved, got %q", data)
+	}
+}
+
+// TestStandingsIngest tests storing an upload, deduplicating by session ID and by content, and rejecting invalid files
+func TestStandingsIngest(t *testing.T) {
+	dir := t.TempDir()
+	copyResults(t, dir, "2026_10_11_18_00_RACE.json")
+	m := newStandingsModule(dir, "", "", nil, "")
+	m.scan(time.Now())
+
+	data, err := os.ReadFile(filepath.Join("testdata", "results", "2026_10_18_18_00_RACE.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	id, r, duplicate, err := m.ingest("2026_10_18_18_00_RACE", data, time.Now())
+	if err != nil || duplicate || id != "2026_10_18_18_00_RACE" || r.track != "ks_nurburgring layout_gp_a" {
+		t.Fatalf("Unexpected ingest result %q %+v %v %v", id, r, duplicate, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2026_10_18_18_00_RACE.json")); err != nil {
+		t.Errorf("Expected the results file to be stored: %v", err)
+	}
+	if got := (&standingsProvider{module: m}).Standings(); got.Races != 2 || got.Drivers[0].Driver != "Bobby" {
+		t.Errorf("Expected the upload to be scored right away, got %+v", got)
+	}
+
+	// The same content without a session ID, and another file under a known session ID, are duplicates
+	if id, _, duplicate, _ := m.ingest("", data, time.Now()); !duplicate || id != "2026_10_18_18_00_RACE" {
+		t.Errorf("Expected identical content to be a duplicate of the stored session, got %q %v", id, duplicate)
+	}
+	other, _ := os.ReadFile(filepath.Join("testdata", "results", "2026_10_18_17_30_QUALIFY.json"))
+	if _, _, duplicate, _ := m.ingest("2026_10_11_18_00_RACE", other, time.Now()); !duplicate {
+		t.Error("Expected a known session ID to be a duplicate")
+	}
+
+	// Without a session ID the file is named after its content
+	id, _, duplicate, err = m.ingest("", other, time.Now())
+	if err != nil || duplicate || !strings.HasPrefix(id, "upload_") {
+		t.Errorf("Unexpected derived session %q %v %v", id, duplicate, err)
+	}
+
+	if _, _, _, err := m.ingest("bad", []byte(`{"Type": "RACE"}`), time.Now()); !errors.Is(err, api.ErrInvalidResults) {
+		t.Errorf("Expected ErrInvalidResults, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bad.json")); !os.IsNotExist(err) {
+		t.Error("Expected an invalid file not to be stored")
+	}
+}
+
+// TestResultsSummaryEmbed tests the finishing order, points, DNFs and fastest lap of a race summary
+func TestResultsSummaryEmbed(t *testing.T) {
+	data, _ := os.ReadFile(filepath.Join("testdata", "results", "2026_10_11_18_00_RACE.json"))
+	r, err := parseRaceResult(data)
+	if err != nil {
+		t.Fatalf("parseRaceResult failed: %v", err)
+	}
+	r.id = "2026_10_11_18_00_RACE"
+	embed := resultsSummaryEmbed(r, defaultStandingsPoints, nil)
+	want := "**1.** Alice — 46:25.123 (+25)\n**2.** Bob — 46:30.456 (+18)\n**DNF** Carol\n\n⏱️ Fastest lap: Alice 2:18.512"
+	if embed.Description != want {
+		t.Errorf("Unexpected summary:\n%s\nwant:\n%s", embed.Description, want)
+	}
+	if embed.Title != "🏁 Race Results: ks_spa" || embed.Footer.Text != r.id {
+		t.Errorf("Unexpected title or footer %q %q", embed.Title, embed.Footer.Text)
+	}
+	if got := formatRaceTime(time.Hour + 2*time.Minute + 3456*time.Millisecond); got != "1:02:03.456" {
+		t.Errorf("formatRaceTime = %q", got)
+	}
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bombom/absa-ac/pkg/store"
+)
+
+// ================= STATE BACKUP AND RESTORE =================
+
+const (
+	// stateBackupFormat is the archive layout version; restore refuses newer ones
+	stateBackupFormat = 1
+	// stateBackupManifest describes the archive
+	stateBackupManifest = "manifest.json"
+	// maxStateBackupEntry bounds one file read from an archive
+	maxStateBackupEntry = 512 << 20
+)
+
+// Archive layout: the config file and its backups under config/ (named after config.json, whatever the
+// file is called on the host), the state files next to it under state/ and one JSON file per table under
+// database/, so an archive of a SQLite bot restores into PostgreSQL and back
+const (
+	stateBackupConfigDir   = "config/"
+	stateBackupConfigName  = "config.json"
+	stateBackupStateDir    = "state/"
+	stateBackupDatabaseDir = "database/"
+)
+
+// stateFiles are the files next to config.json that hold bot state: message IDs, driver links,
+// deployments and scheduled events
+var stateFiles = []string{
+	driversStateFile, deploymentsStateFile, scheduledEventsStateFile,
+	slackStateFile, matrixStateFile, webhookStateFile, standingsStateFile,
+}
+
+// stateTables are the database tables of a backup, restored in this order
+var stateTables = []string{"history_buckets", "history_meta", "audit_entries"}
+
+// stateBackupInfo is the manifest of an archive
+type stateBackupInfo struct {
+	Format  int       `json:"format"`
+	Created time.Time `json:"created"`
+	Version string    `json:"version"` // bot build that wrote the archive
+	// Database is the dialect the tables were read from ("" = no database)
+	Database      string   `json:"database,omitempty"`
+	SchemaVersion int      `json:"schema_version,omitempty"`
+	Files         []string `json:"files"`
+}
+
+// writeStateBackup writes a zip of the config file, its backups, the state files and the database tables
+// (db may be nil) to w
+func writeStateBackup(w io.Writer, configPath string, db *store.DB, now time.Time) error {
+	info := stateBackupInfo{Format: stateBackupFormat, Created: now.UTC(), Version: readBuildInfo().Version, Files: []string{}}
+	files := map[string][]byte{}
+
+	cfg, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+	files[stateBackupConfigDir+stateBackupConfigName] = cfg
+	base := filepath.Base(configPath)
+	for _, b := range (&ConfigManager{configPath: configPath}).listBackups() {
+		data, err := os.ReadFile(b.path)
+		if err != nil {
+			return fmt.Errorf("failed to read config backup: %w", err)
+		}
+		files[stateBackupConfigDir+stateBackupConfigName+strings.TrimPrefix(filepath.Base(b.path), base)] = data
+	}
+	dir := filepath.Dir(configPath)
+	for _, name := range stateFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read state file: %w", err)
+		}
+		files[stateBackupStateDir+name] = data
+	}
+
+	if db != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if info.SchemaVersion, err = db.SchemaVersion(ctx); err != nil {
+			return fmt.Errorf("failed to read the schema version: %w", err)
+		}
+		info.Database = string(db.Dialect)
+		for _, name := range stateTables {
+			table, err := db.ExportTable(ctx, name)
+			if err != nil {
+				return err
+			}
+			data, err := json.Marshal(table)
+			if err != nil {
+				return err
+			}
+			files[stateBackupDatabaseDir+name+".json"] = data
+		}
+	}
+
+	zw := zip.NewWriter(w)
+	names := slices.Sorted(maps.Keys(files))
+	info.Files = names
+	manifest, _ := json.MarshalIndent(info, "", "  ")
+	for _, entry := range append([]string{stateBackupManifest}, names...) {
+		data := files[entry]
+		if entry == stateBackupManifest {
+			data = manifest
+		}
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: entry, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// stateArchive is an archive read for restore
+type stateArchive struct {
+	info  stateBackupInfo
+	files map[string][]byte
+}
+
+// readStateBackup reads and checks an archive written by writeStateBackup
+func readStateBackup(data []byte) (*stateArchive, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a backup archive: %w", err)
+	}
+	a := &stateArchive{files: make(map[string][]byte)}
+	for _, f := range zr.File {
+		if f.UncompressedSize64 > maxStateBackupEntry {
+			return nil, fmt.Errorf("%s is too large", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxStateBackupEntry+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		if len(content) > maxStateBackupEntry {
+			return nil, fmt.Errorf("%s is too large", f.Name)
+		}
+		a.files[f.Name] = content
+	}
+	manifest, ok := a.files[stateBackupManifest]
+	if !ok {
+		return nil, fmt.Errorf("not a backup archive: %s is missing", stateBackupManifest)
+	}
+	if err := json.Unmarshal(manifest, &a.info); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", stateBackupManifest, err)
+	}
+	if a.info.Format < 1 || a.info.Format > stateBackupFormat {
+		return nil, fmt.Errorf("backup format %d is not supported by this release (up to %d)", a.info.Format, stateBackupFormat)
+	}
+	if _, ok := a.files[stateBackupConfigDir+stateBackupConfigName]; !ok {
+		return nil, fmt.Errorf("backup has no %s", stateBackupConfigName)
+	}
+	return a, nil
+}
+
+// targets maps the files of the archive to their paths next to configPath
+// Names are checked, so an archive cannot write outside the config directory
+func (a *stateArchive) targets(configPath string) (map[string]string, error) {
+	dir, base := filepath.Dir(configPath), filepath.Base(configPath)
+	out := make(map[string]string)
+	for name := range a.files {
+		switch {
+		case name == stateBackupManifest, strings.HasPrefix(name, stateBackupDatabaseDir):
+			continue
+		case strings.HasPrefix(name, stateBackupConfigDir):
+			rest := strings.TrimPrefix(name, stateBackupConfigDir)
+			suffix, ok := strings.CutPrefix(rest, stateBackupConfigName)
+			if !ok || strings.ContainsAny(suffix, `/\`) || (suffix != "" && !strings.HasPrefix(suffix, ".")) {
+				return nil, fmt.Errorf("unexpected file %s in backup", name)
+			}
+			out[name] = filepath.Join(dir, base+suffix)
+		case strings.HasPrefix(name, stateBackupStateDir):
+			file := strings.TrimPrefix(name, stateBackupStateDir)
+			if !slices.Contains(stateFiles, file) {
+				return nil, fmt.Errorf("unexpected file %s in backup", name)
+			}
+			out[name] = filepath.Join(dir, file)
+		default:
+			return nil, fmt.Errorf("unexpected file %s in backup", name)
+		}
+	}
+	return out, nil
+}
+
+// tables returns the database tables of the archive in restore order
+func (a *stateArchive) tables() ([]*store.Table, error) {
+	var tables []*store.Table
+	for _, name := range stateTables {
+		data, ok := a.files[stateBackupDatabaseDir+name+".json"]
+		if !ok {
+			continue
+		}
+		var t store.Table
+		if err := json.Unmarshal(data, &t); err != nil {
+			return nil, fmt.Errorf("invalid table %s in backup: %w", name, err)
+		}
+		if t.Name != name {
+			return nil, fmt.Errorf("table %s in backup is named %q", name, t.Name)
+		}
+		tables = append(tables, &t)
+	}
+	for name := range a.files {
+		table, ok := strings.CutPrefix(name, stateBackupDatabaseDir)
+		if ok && !slices.Contains(stateTables, strings.TrimSuffix(table, ".json")) {
+			return nil, fmt.Errorf("unexpected file %s in backup", name)
+		}
+	}
+	return tables, nil
+}
+
+// restoreStateBackup writes the files of the archive next to configPath and replaces the database tables
+// Without force, existing files and a database with rows are refused. Run it with the bot stopped: a
+// running bot would overwrite the restored state from memory
+func restoreStateBackup(a *stateArchive, configPath string, force bool, out io.Writer) error {
+	targets, err := a.targets(configPath)
+	if err != nil {
+		return err
+	}
+	tables, err := a.tables()
+	if err != nil {
+		return err
+	}
+	if !force {
+		for _, target := range targets {
+			if _, err := os.Stat(target); err == nil {
+				return fmt.Errorf("%s already exists (use -force to overwrite)", target)
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return err
+	}
+
+	if len(tables) > 0 {
+		db, err := databaseFromEnv(configPath, true)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		if !force {
+			for _, t := range tables {
+				var n int
+				if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.Name).Scan(&n); err != nil {
+					return err
+				}
+				if n > 0 {
+					return fmt.Errorf("%s already has %s rows (use -force to replace them)", db, t.Name)
+				}
+			}
+		}
+		if err := db.ImportTables(ctx, tables); err != nil {
+			return err
+		}
+		for _, t := range tables {
+			fmt.Fprintf(out, "Restored %d rows of %s into %s\n", len(t.Rows), t.Name, db)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(targets)) {
+		perm := os.FileMode(0600)
+		if name == stateBackupConfigDir+stateBackupConfigName {
+			perm = 0644
+		}
+		if err := writeNewFile(targets[name], a.files[name], perm, force); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Restored %s\n", targets[name])
+	}
+	return nil
+}
+
+// runBackup implements `bot backup [-c config.json] [-o file]`: writes a state archive without a running
+// bot (the API's GET /api/v1/backup includes the latest unsaved history as well)
+func runBackup(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	fs.SetOutput(out)
+	configPath := fs.String("c", "", "Path to config.json (default /data/config.json, same as the bot)")
+	fs.StringVar(configPath, "config", "", "Path to config.json")
+	output := fs.String("o", "", "Archive to write (default absa-backup-<time>.zip in the current directory)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil // -h printed the usage
+		}
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	cfgPath := getConfigPath(*configPath)
+	now := time.Now()
+	target := *output
+	if target == "" {
+		target = stateBackupName(now)
+	}
+
+	// The database is only opened if there is one: DATABASE_URL or bot.db next to config.json
+	_, statErr := os.Stat(filepath.Join(filepath.Dir(cfgPath), databaseFile))
+	db, err := databaseFromEnv(cfgPath, statErr == nil)
+	if err != nil {
+		return err
+	}
+	if db != nil {
+		defer db.Close()
+	}
+	var buf bytes.Buffer
+	if err := writeStateBackup(&buf, cfgPath, db, now); err != nil {
+		return err
+	}
+	if err := writeNewFile(target, buf.Bytes(), 0600, false); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Wrote %s (%d bytes)\n", target, buf.Len())
+	return nil
+}
+
+// runRestore implements `bot restore [-c config.json] [-force] <archive>`
+func runRestore(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	fs.SetOutput(out)
+	configPath := fs.String("c", "", "Path to restore config.json to (default /data/config.json, same as the bot)")
+	fs.StringVar(configPath, "config", "", "Path to restore config.json to")
+	force := fs.Bool("force", false, "Overwrite existing files and database rows")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil // -h printed the usage
+		}
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: restore [-c config.json] [-force] <archive>")
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	a, err := readStateBackup(data)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Restoring backup of %s (bot %s)\n", a.info.Created.Format(time.RFC3339), a.info.Version)
+	if err := restoreStateBackup(a, getConfigPath(*configPath), *force, out); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "Restore complete; start the bot with the .env of the old host (it is not part of the backup)")
+	return nil
+}
+
+// stateBackupName is the default archive name for a backup taken at now (the API names downloads the same)
+func stateBackupName(now time.Time) string {
+	return api.StateBackupName(now)
+}
+
+// stateArchiver adapts the bot to api.StateArchiver
+type stateArchiver struct {
+	bot *Bot
+}
+
+// WriteStateBackup implements api.StateArchiver; the history is flushed first so the archive is current
+func (s *stateArchiver) WriteStateBackup(w io.Writer, now time.Time) error {
+	s.bot.history.flush()
+	return writeStateBackup(w, s.bot.configManager.configPath, s.bot.db, now)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// writeTestState fills dir with a config, a config backup, state files and a database with history and audit rows
+func writeTestState(t *testing.T, dir string) {
+	t.Helper()
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"server_ip":"203.0.113.10"}`), 0644)
+	os.WriteFile(filepath.Join(dir, "config.json.backup"), []byte(`{"server_ip":"203.0.113.9"}`), 0644)
+	os.WriteFile(filepath.Join(dir, driversStateFile), []byte(`{"links":[]}`), 0600)
+	os.WriteFile(filepath.Join(dir, slackStateFile), []byte("1712345678.000100\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "unrelated.txt"), []byte("not state"), 0600)
+
+	db, err := databaseFromEnv(filepath.Join(dir, "config.json"), true)
+	if err != nil {
+		t.Fatalf("databaseFromEnv failed: %v", err)
+	}
+	defer db.Close()
+	h := newHistoryStore(db)
+	h.record(slotPoll(1), 30*time.Second, time.Now())
+	h.markReported(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC))
+	h.flush()
+	(&auditStore{db: db}).SaveAuditEntries([]api.AuditEntry{{ID: "a1", Time: time.Now(), Action: "PATCH /api/config",
+		Actor: "203.0.113.5", ChangedKeys: []string{"update_interval"}, Before: []byte(`{}`), After: []byte(`{}`)}})
+}
+
+// TestStateBackupRoundTrip tests backing up with the CLI and restoring into an empty host
+func TestStateBackupRoundTrip(t *testing.T) {
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "data")
+	writeTestState(t, src)
+	archive := filepath.Join(t.TempDir(), "backup.zip")
+
+	var out bytes.Buffer
+	if err := runBackup([]string{"-c", filepath.Join(src, "config.json"), "-o", archive}, &out); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	data, _ := os.ReadFile(archive)
+	a, err := readStateBackup(data)
+	if err != nil {
+		t.Fatalf("readStateBackup failed: %v", err)
+	}
+	if a.info.Database != "sqlite" || a.info.SchemaVersion != databaseMigrations[len(databaseMigrations)-1].Version {
+		t.Errorf("Unexpected manifest %+v", a.info)
+	}
+	if _, ok := a.files["state/unrelated.txt"]; ok {
+		t.Error("Expected only known state files in the archive")
+	}
+
+	// Restore under another config name: backups follow it
+	if err := runRestore([]string{"-c", filepath.Join(dst, "bot.json"), archive}, &out); err != nil {
+		t.Fatalf("restore failed: %v\n%s", err, out.String())
+	}
+	for src, dst := range map[string]string{
+		filepath.Join(src, "config.json"):        filepath.Join(dst, "bot.json"),
+		filepath.Join(src, "config.json.backup"): filepath.Join(dst, "bot.json.backup"),
+		filepath.Join(src, slackStateFile):       filepath.Join(dst, slackStateFile),
+		filepath.Join(src, driversStateFile):     filepath.Join(dst, driversStateFile),
+	} {
+		want, _ := os.ReadFile(src)
+		if got, err := os.ReadFile(dst); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("Expected %s restored as %s, got %q %v", src, dst, got, err)
+		}
+	}
+
+	db := testDatabaseAt(t, filepath.Join(dst, "bot.json"))
+	h := newHistoryStore(db)
+	if err := h.load(time.Now()); err != nil || len(h.state.Buckets) != 2 || h.state.LastReport != "2026-09" {
+		t.Errorf("Expected the history restored, got %+v %v", h.state, err)
+	}
+	if entries, _ := (&auditStore{db: db}).LoadAuditEntries(10); len(entries) != 1 || entries[0].ID != "a1" {
+		t.Errorf("Expected the audit log restored, got %+v", entries)
+	}
+}
+
+// TestStateRestoreRefusesOverwrite tests that existing files and rows need -force
+func TestStateRestoreRefusesOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	writeTestState(t, dir)
+	var buf bytes.Buffer
+	if err := writeStateBackup(&buf, filepath.Join(dir, "config.json"), nil, time.Now()); err != nil {
+		t.Fatalf("writeStateBackup failed: %v", err)
+	}
+	a, err := readStateBackup(buf.Bytes())
+	if err != nil {
+		t.Fatalf("readStateBackup failed: %v", err)
+	}
+	if err := restoreStateBackup(a, filepath.Join(dir, "config.json"), false, io.Discard); err == nil || !strings.Contains(err.Error(), "-force") {
+		t.Errorf("Expected existing files to be refused, got %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{}`), 0644)
+	if err := restoreStateBackup(a, filepath.Join(dir, "config.json"), true, io.Discard); err != nil {
+		t.Fatalf("forced restore failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "config.json")); !strings.Contains(string(data), "203.0.113.10") {
+		t.Errorf("Expected config.json overwritten, got %s", data)
+	}
+}
+
+// TestStateRestoreRejectsForeignFiles tests that archives cannot write outside the known files
+func TestStateRestoreRejectsForeignFiles(t *testing.T) {
+	for _, name := range []string{"state/../../evil", "config/config.json/../../evil", "config/other.json", "database/users.json", "evil.sh"} {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for entry, data := range map[string]string{
+			stateBackupManifest:  `{"format":1}`,
+			"config/config.json": `{}`,
+			name:                 `x`,
+		} {
+			w, _ := zw.Create(entry)
+			w.Write([]byte(data))
+		}
+		zw.Close()
+		a, err := readStateBackup(buf.Bytes())
+		if err == nil {
+			err = restoreStateBackup(a, filepath.Join(t.TempDir(), "config.json"), true, io.Discard)
+		}
+		if err == nil {
+			t.Errorf("Expected %s to be refused", name)
+		}
+	}
+	if _, err := readStateBackup([]byte("not a zip")); err == nil {
+		t.Error("Expected an invalid archive to be refused")
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ================= STATUS SNAPSHOT =================
+
+// defaultStatusTitle is the heading used by the embed and all status renderers unless overridden
+const defaultStatusTitle = "ABSA Official Servers"
+
+// StatusSnapshot is the result of one completed poll cycle, grouped by category in config order
+// Stored on the Bot after every update and shared by the public status endpoint and renderers
+type StatusSnapshot struct {
+	UpdatedAt    time.Time        `json:"updated_at"`
+	TotalPlayers int              `json:"total_players"`
+	Categories   []CategoryStatus `json:"categories"`
+
+	// Offline is set on the final update posted at shutdown (nil while the bot is running)
+	Offline *OfflineNotice `json:"offline,omitempty"`
+}
+
+// OfflineNotice marks a status as no longer updated because the bot stopped
+type OfflineNotice struct {
+	Since   time.Time `json:"since"`
+	Message string    `json:"message"`
+}
+
+// Text is the notice as shown to users, e.g. "Status bot offline since 2026-10-16 20:05 UTC"
+func (n *OfflineNotice) Text() string {
+	return fmt.Sprintf("%s since %s UTC", n.Message, n.Since.UTC().Format("2006-01-02 15:04"))
+}
+
+// CategoryStatus groups servers of one category
+type CategoryStatus struct {
+	Name    string         `json:"name"`
+	Emoji   string         `json:"emoji"`
+	Players int            `json:"players"`
+	Servers []ServerStatus `json:"servers"`
+}
+
+// ServerStatus is one polled server
+// Address and JoinURL are only populated in the public document when addresses are allowed
+type ServerStatus struct {
+	Name       string `json:"name"`
+	Online     bool   `json:"online"`
+	Map        string `json:"map"`
+	Players    int    `json:"players"`
+	MaxPlayers int    `json:"max_players"`
+	Address    string `json:"address,omitempty"`
+	JoinURL    string `json:"join_url,omitempty"`
+
+	// Stale is set when Map/Players are last known data older than STALE_AFTER_INTERVALS intervals
+	Stale    bool       `json:"stale,omitempty"`
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+
+	// Trend is the player trend over the last hour (arrow or sparkline, PLAYER_TRENDS)
+	Trend string `json:"trend,omitempty"`
+
+	// Event is the race event running on the server (config events)
+	Event string `json:"event,omitempty"`
+
+	// CarClasses is the car class summary of the entry list (EMBED_CAR_CLASSES)
+	CarClasses string `json:"car_classes,omitempty"`
+
+	// NextTrack is the next track of the server's rotation, starting at NextTrackAt (config rotations)
+	NextTrack   string     `json:"next_track,omitempty"`
+	NextTrackAt *time.Time `json:"next_track_at,omitempty"`
+
+	// Maintenance is set while the server is flagged for maintenance, until MaintenanceUntil
+	Maintenance       bool       `json:"maintenance,omitempty"`
+	MaintenanceUntil  *time.Time `json:"maintenance_until,omitempty"`
+	MaintenanceReason string     `json:"maintenance_reason,omitempty"`
+}
+
+// StaleAge returns how old stale data was at now ("5m"), or "" for fresh data
+func (s ServerStatus) StaleAge(now time.Time) string {
+	if !s.Stale || s.LastSeen == nil {
+		return ""
+	}
+	return formatDataAge(now.Sub(*s.LastSeen))
+}
+
+// MaintenanceNote returns "maintenance until 21:30 UTC" with the reason, or "" outside maintenance
+func (s ServerStatus) MaintenanceNote() string {
+	if !s.Maintenance || s.MaintenanceUntil == nil {
+		return ""
+	}
+	note := "maintenance until " + s.MaintenanceUntil.Format("15:04 MST")
+	if s.MaintenanceReason != "" {
+		note += " — " + s.MaintenanceReason
+	}
+	return note
+}
+
+// PlayerCount returns "players/max", followed by the trend indicator if there is one
+func (s ServerStatus) PlayerCount() string {
+	if s.Trend == "" {
+		return fmt.Sprintf("%d/%d", s.Players, s.MaxPlayers)
+	}
+	return fmt.Sprintf("%d/%d %s", s.Players, s.MaxPlayers, s.Trend)
+}
+
+// joinURL builds the Content Manager join link for a server
+func joinURL(ip string, port int) string {
+	return string(appendJoinURL(nil, ip, port))
+}
+
+// appendJoinURL appends the join link to dst (allocation-free form of joinURL for the embed builder)
+func appendJoinURL(dst []byte, ip string, port int) []byte {
+	dst = append(dst, "https://acstuff.club/s/q:race/online/join?ip="...)
+	dst = append(dst, ip...)
+	dst = append(dst, "&httpPort="...)
+	return strconv.AppendInt(dst, int64(port), 10)
+}
+
+// buildStatusSnapshot groups poll results by category (in category_order) and totals players
+func buildStatusSnapshot(infos []ServerInfo, cfg *Config, now time.Time) *StatusSnapshot {
+	snap := &StatusSnapshot{UpdatedAt: now.UTC()}
+
+	grouped := make(map[string][]ServerInfo)
+	for _, info := range infos {
+		grouped[info.Category] = append(grouped[info.Category], info)
+	}
+
+	for _, category := range cfg.CategoryOrder {
+		cs := CategoryStatus{
+			Name:    category,
+			Emoji:   cfg.CategoryEmojis[category],
+			Servers: []ServerStatus{},
+		}
+		for _, info := range grouped[category] {
+			online := info.NumPlayers >= 0
+			players := info.NumPlayers
+			if !online {
+				players = 0
+			}
+			cs.Players += players
+			srv := ServerStatus{
+				Name:       info.Name,
+				Online:     online,
+				Map:        info.Map,
+				Players:    players,
+				MaxPlayers: info.MaxPlayers,
+				Address:    fmt.Sprintf("%s:%d", info.IP, info.Port),
+				JoinURL:    joinURL(info.IP, info.Port),
+				Stale:      info.Stale,
+				Trend:      info.Trend,
+			}
+			if !info.LastSeen.IsZero() {
+				seen := info.LastSeen.UTC()
+				srv.LastSeen = &seen
+			}
+			if info.Event != nil {
+				srv.Event = info.Event.name
+			}
+			srv.CarClasses = info.CarClasses
+			if next := info.NextTrack; next != nil {
+				at := next.at.UTC()
+				srv.NextTrack, srv.NextTrackAt = next.track, &at
+			}
+			if m := info.Maintenance; m != nil {
+				until := m.until.UTC()
+				srv.Maintenance, srv.MaintenanceUntil, srv.MaintenanceReason = true, &until, m.reason
+			}
+			cs.Servers = append(cs.Servers, srv)
+		}
+		snap.TotalPlayers += cs.Players
+		snap.Categories = append(snap.Categories, cs)
+	}
+
+	return snap
+}
+
+// Sanitized returns a copy safe for unauthenticated consumers
+// Server addresses and join links are stripped unless showAddresses is true
+func (s *StatusSnapshot) Sanitized(showAddresses bool) *StatusSnapshot {
+	out := &StatusSnapshot{
+		UpdatedAt:    s.UpdatedAt,
+		TotalPlayers: s.TotalPlayers,
+		Categories:   make([]CategoryStatus, len(s.Categories)),
+		Offline:      s.Offline,
+	}
+	for i, cat := range s.Categories {
+		servers := make([]ServerStatus, len(cat.Servers))
+		copy(servers, cat.Servers)
+		if !showAddresses {
+			for j := range servers {
+				servers[j].Address = ""
+				servers[j].JoinURL = ""
+			}
+		}
+		cat.Servers = servers
+		out.Categories[i] = cat
+	}
+	return out
+}
+
+// publicStatusProvider adapts the bot's latest snapshot to api.StatusProvider
+type publicStatusProvider struct {
+	bot           *Bot
+	showAddresses bool
+}
+
+// PublicStatus returns the sanitized latest snapshot, or nil before the first poll completes
+func (p *publicStatusProvider) PublicStatus() any {
+	snap := p.bot.lastStatus.Load()
+	if snap == nil {
+		return nil
+	}
+	return snap.Sanitized(p.showAddresses)
+}
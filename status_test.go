@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func testStatusConfig() *Config {
+	return &Config{
+		ServerIP:       "203.0.113.10",
+		UpdateInterval: 30,
+		CategoryOrder:  []string{"Drift", "Track"},
+		CategoryEmojis: map[string]string{"Drift": "🟣", "Track": "🔵"},
+	}
+}
+
+// TestBuildStatusSnapshot tests grouping by category order and player totals
+func TestBuildStatusSnapshot(t *testing.T) {
+	infos := []ServerInfo{
+		{Name: "Track 1", Category: "Track", Map: "spa", NumPlayers: 4, MaxPlayers: 20, IP: "203.0.113.10", Port: 8082},
+		{Name: "Drift 1", Category: "Drift", Map: "ebisu", NumPlayers: 2, MaxPlayers: 16, IP: "203.0.113.10", Port: 8081},
+		{Name: "Drift 2", Category: "Drift", Map: "Offline", NumPlayers: -1, IP: "203.0.113.10", Port: 8083},
+	}
+
+	snap := buildStatusSnapshot(infos, testStatusConfig(), time.Now())
+
+	if snap.TotalPlayers != 6 {
+		t.Errorf("Expected 6 total players, got %d", snap.TotalPlayers)
+	}
+	if len(snap.Categories) != 2 || snap.Categories[0].Name != "Drift" || snap.Categories[1].Name != "Track" {
+		t.Fatalf("Expected categories in config order [Drift Track], got %+v", snap.Categories)
+	}
+	drift := snap.Categories[0]
+	if drift.Players != 2 {
+		t.Errorf("Expected Drift players 2 (offline counts as 0), got %d", drift.Players)
+	}
+	if drift.Servers[1].Online || drift.Servers[1].Players != 0 {
+		t.Errorf("Expected Drift 2 offline with 0 players, got %+v", drift.Servers[1])
+	}
+	if drift.Servers[0].Address != "203.0.113.10:8081" {
+		t.Errorf("Expected address 203.0.113.10:8081, got %s", drift.Servers[0].Address)
+	}
+}
+
+// TestStatusSnapshot_Sanitized tests that addresses are stripped unless allowed
+func TestStatusSnapshot_Sanitized(t *testing.T) {
+	infos := []ServerInfo{
+		{Name: "Drift 1", Category: "Drift", NumPlayers: 2, IP: "203.0.113.10", Port: 8081},
+	}
+	snap := buildStatusSnapshot(infos, testStatusConfig(), time.Now())
+
+	public := snap.Sanitized(false)
+	srv := public.Categories[0].Servers[0]
+	if srv.Address != "" || srv.JoinURL != "" {
+		t.Errorf("Expected address and join URL stripped, got %+v", srv)
+	}
+
+	// Original snapshot must be untouched
+	if snap.Categories[0].Servers[0].Address == "" {
+		t.Error("Sanitized modified the original snapshot")
+	}
+
+	withAddresses := snap.Sanitized(true)
+	if withAddresses.Categories[0].Servers[0].JoinURL == "" {
+		t.Error("Expected join URL when addresses are allowed")
+	}
+}
+
+// TestPublicStatusProvider_NilBeforeFirstPoll tests that no document is served before the first poll
+func TestPublicStatusProvider_NilBeforeFirstPoll(t *testing.T) {
+	b := newTestBot(testStatusConfig())
+	p := &publicStatusProvider{bot: b}
+
+	if p.PublicStatus() != nil {
+		t.Error("Expected nil status before first poll")
+	}
+
+	b.lastStatus.Store(buildStatusSnapshot(nil, testStatusConfig(), time.Now()))
+	if p.PublicStatus() == nil {
+		t.Error("Expected status after poll")
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/bombom/absa-ac/pkg/atomicfile"
+)
+
+// ================= STATIC STATUS PAGE =================
+
+// statusPageTemplate renders a self-contained HTML page (no external assets, works from any web server or bucket)
+var statusPageTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="{{.Refresh}}">
+<title>{{.Title}}</title>
+<style>
+body{font-family:system-ui,sans-serif;background:#1e1f22;color:#dbdee1;margin:0;padding:2rem}
+h1{margin-top:0}
+h2{border-bottom:1px solid #3f4147;padding-bottom:.25rem}
+table{border-collapse:collapse;width:100%;margin-bottom:1.5rem}
+td,th{text-align:left;padding:.35rem .5rem}
+tr:nth-child(even){background:#2b2d31}
+.online{color:#23a55a}.offline{color:#f23f43}.stale{color:#949ba4}.maintenance{color:#f0b232}
+footer{color:#949ba4;font-size:.85rem}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Total players: <strong>{{.Status.TotalPlayers}}</strong></p>
+{{range .Status.Categories}}
+<h2>{{.Emoji}} {{.Name}} — {{.Players}} players</h2>
+<table>
+<tr><th></th><th>Server</th><th>Map</th><th>Players</th>{{if $.ShowAddresses}}<th></th>{{end}}</tr>
+{{range .Servers}}<tr>
+<td class="{{if .Maintenance}}maintenance{{else if .Stale}}stale{{else if .Online}}online{{else}}offline{{end}}">{{if .Maintenance}}🔧{{else}}●{{end}}</td>
+<td>{{.Name}}</td>
+<td>{{.Map}}</td>
+<td>{{if .Online}}{{.Players}}/{{.MaxPlayers}}{{else}}-{{end}}{{with .StaleAge $.Status.UpdatedAt}} <small class="stale">data {{.}} old</small>{{end}}{{with .MaintenanceNote}} <small class="maintenance">{{.}}</small>{{end}}</td>
+{{if $.ShowAddresses}}<td>{{if .JoinURL}}<a href="{{.JoinURL}}">Join</a>{{end}}</td>{{end}}
+</tr>
+{{end}}</table>
+{{end}}
+<footer>Updated {{.Status.UpdatedAt.Format "2006-01-02 15:04:05 MST"}}</footer>
+</body>
+</html>
+`))
+
+// statusPageData is the template input
+type statusPageData struct {
+	Title         string
+	Refresh       int
+	ShowAddresses bool
+	Status        *StatusSnapshot
+}
+
+// StatusPageRenderer writes index.html and status.json into a directory after every poll
+// Output can be served by any static web server or synced to object storage
+type StatusPageRenderer struct {
+	dir           string
+	title         string
+	showAddresses bool
+}
+
+// NewStatusPageRenderer creates a renderer writing into dir
+func NewStatusPageRenderer(dir, title string, showAddresses bool) *StatusPageRenderer {
+	if title == "" {
+		title = defaultStatusTitle
+	}
+	return &StatusPageRenderer{dir: dir, title: title, showAddresses: showAddresses}
+}
+
+// Render writes the snapshot as status.json and index.html
+// refreshSeconds sets the HTML meta refresh (normally the update interval)
+func (r *StatusPageRenderer) Render(snap *StatusSnapshot, refreshSeconds int) error {
+	public := snap.Sanitized(r.showAddresses)
+
+	jsonData, err := json.MarshalIndent(public, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON encoding failed: %w", err)
+	}
+
+	var html bytes.Buffer
+	if err := statusPageTemplate.Execute(&html, statusPageData{
+		Title:         r.title,
+		Refresh:       refreshSeconds,
+		ShowAddresses: r.showAddresses,
+		Status:        public,
+	}); err != nil {
+		return fmt.Errorf("HTML rendering failed: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(r.dir, "status.json"), jsonData); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(r.dir, "index.html"), html.Bytes())
+}
+
+// writeFileAtomic writes data to path via temp file + atomic replace so web servers never serve a partial file
+// Files are world-readable (0644) since they are meant to be published
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file in %s: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close %s: %w", tmpPath, err)
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to chmod %s: %w", tmpPath, err)
+	}
+	if err := atomicfile.Replace(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace %s with %s: %w", path, tmpPath, err)
+	}
+	return nil
+}
+
+// statusPageRendererFromEnv returns a renderer if STATUS_PAGE_DIR is set, nil otherwise
+// Creates the output directory if missing
+func statusPageRendererFromEnv() (*StatusPageRenderer, error) {
+	dir := os.Getenv("STATUS_PAGE_DIR")
+	if dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create STATUS_PAGE_DIR %s: %w", dir, err)
+	}
+	showAddresses := os.Getenv("STATUS_PAGE_SHOW_ADDRESSES") == "true"
+	log.Printf("Static status page enabled: writing to %s (server addresses shown: %v)", dir, showAddresses)
+	return NewStatusPageRenderer(dir, os.Getenv("STATUS_PAGE_TITLE"), showAddresses), nil
+}
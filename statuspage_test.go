@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStatusPageRenderer_WritesFiles tests that index.html and status.json are written
+func TestStatusPageRenderer_WritesFiles(t *testing.T) {
+	dir := t.TempDir()
+	infos := []ServerInfo{
+		{Name: "Drift <1>", Category: "Drift", Map: "ebisu", NumPlayers: 2, MaxPlayers: 16, IP: "203.0.113.10", Port: 8081},
+	}
+	snap := buildStatusSnapshot(infos, testStatusConfig(), time.Now())
+
+	r := NewStatusPageRenderer(dir, "", false)
+	if err := r.Render(snap, 30); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	jsonData, err := os.ReadFile(filepath.Join(dir, "status.json"))
+	if err != nil {
+		t.Fatalf("status.json not written: %v", err)
+	}
+	var parsed StatusSnapshot
+	if err := json.Unmarshal(jsonData, &parsed); err != nil {
+		t.Fatalf("status.json is not valid JSON: %v", err)
+	}
+	if parsed.TotalPlayers != 2 {
+		t.Errorf("Expected 2 total players, got %d", parsed.TotalPlayers)
+	}
+	if parsed.Categories[0].Servers[0].Address != "" {
+		t.Error("Expected address stripped from status.json")
+	}
+
+	html, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatalf("index.html not written: %v", err)
+	}
+	page := string(html)
+	if !strings.Contains(page, "Drift &lt;1&gt;") {
+		t.Error("Expected server name to be HTML-escaped")
+	}
+	if strings.Contains(page, "203.0.113.10") {
+		t.Error("Expected server address omitted from index.html")
+	}
+	if !strings.Contains(page, `content="30"`) {
+		t.Error("Expected meta refresh to match update interval")
+	}
+
+	// No temp files left behind
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("Expected exactly 2 files in output dir, got %d", len(entries))
+	}
+}
+
+// TestStatusPageRenderer_ShowAddresses tests join links when addresses are allowed
+func TestStatusPageRenderer_ShowAddresses(t *testing.T) {
+	dir := t.TempDir()
+	infos := []ServerInfo{
+		{Name: "Drift 1", Category: "Drift", NumPlayers: 1, IP: "203.0.113.10", Port: 8081},
+	}
+	snap := buildStatusSnapshot(infos, testStatusConfig(), time.Now())
+
+	if err := NewStatusPageRenderer(dir, "Test", true).Render(snap, 30); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	html, _ := os.ReadFile(filepath.Join(dir, "index.html"))
+	if !strings.Contains(string(html), "acstuff.club") {
+		t.Error("Expected join link in index.html")
+	}
+}
+
+// TestStatusPageRendererFromEnv tests env-driven enablement
+func TestStatusPageRendererFromEnv(t *testing.T) {
+	t.Setenv("STATUS_PAGE_DIR", "")
+	r, err := statusPageRendererFromEnv()
+	if err != nil || r != nil {
+		t.Errorf("Expected disabled renderer, got %v (%v)", r, err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "public")
+	t.Setenv("STATUS_PAGE_DIR", dir)
+	r, err = statusPageRendererFromEnv()
+	if err != nil || r == nil {
+		t.Fatalf("Expected renderer, got %v (%v)", r, err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Expected output dir to be created: %v", err)
+	}
+}
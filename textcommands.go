@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/bombom/absa-ac/pkg/supervisor"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= LEGACY TEXT COMMANDS =================
+
+const (
+	// defaultTextCommandPrefix starts text commands unless TEXT_COMMAND_PREFIX is set
+	defaultTextCommandPrefix = "!"
+	// maxTextCommandPrefix bounds TEXT_COMMAND_PREFIX
+	maxTextCommandPrefix = 5
+	// textCommandCooldown is how long a member's further commands are ignored after an answer
+	textCommandCooldown = 5 * time.Second
+	// maxTextReply is Discord's message length limit
+	maxTextReply = 2000
+)
+
+// textCommands answers "!servers" and "!status <name>" in allowlisted channels, for guilds that turned slash
+// commands off. Reading message text needs the privileged message content intent, requested only when enabled
+type textCommands struct {
+	prefix   string
+	channels map[string]bool
+
+	mu       sync.Mutex
+	answered map[string]time.Time // user ID -> last answer
+}
+
+// textCommandsFromEnv returns the handler if TEXT_COMMANDS is true, nil otherwise
+// TEXT_COMMAND_CHANNELS (required) lists the channel IDs it answers in; TEXT_COMMAND_PREFIX defaults to "!"
+func textCommandsFromEnv() (*textCommands, error) {
+	if os.Getenv("TEXT_COMMANDS") != "true" {
+		return nil, nil
+	}
+	prefix := os.Getenv("TEXT_COMMAND_PREFIX")
+	if prefix == "" {
+		prefix = defaultTextCommandPrefix
+	}
+	if len(prefix) > maxTextCommandPrefix || strings.IndexFunc(prefix, unicode.IsSpace) >= 0 {
+		return nil, fmt.Errorf("invalid TEXT_COMMAND_PREFIX %q: must be 1-%d characters without spaces", prefix, maxTextCommandPrefix)
+	}
+	channels := make(map[string]bool)
+	for _, id := range strings.Split(os.Getenv("TEXT_COMMAND_CHANNELS"), ",") {
+		if id = strings.TrimSpace(id); id == "" {
+			continue
+		}
+		if !snowflakePattern.MatchString(id) {
+			return nil, fmt.Errorf("invalid TEXT_COMMAND_CHANNELS entry %q: must be a Discord channel ID", id)
+		}
+		channels[id] = true
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("TEXT_COMMANDS requires TEXT_COMMAND_CHANNELS (comma-separated channel IDs to answer in)")
+	}
+	log.Printf("Text commands enabled with prefix %q in %d channel(s) (needs the Message Content intent)", prefix, len(channels))
+	return &textCommands{prefix: prefix, channels: channels, answered: make(map[string]time.Time)}, nil
+}
+
+// parse returns the command and its argument of content, or ok=false if content is not a text command
+func (t *textCommands) parse(content string) (cmd, arg string, ok bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(content), t.prefix)
+	if !ok {
+		return "", "", false
+	}
+	cmd, arg, _ = strings.Cut(rest, " ")
+	return strings.ToLower(cmd), strings.TrimSpace(arg), true
+}
+
+// allow reports whether user may get an answer now and starts their cooldown if so
+func (t *textCommands) allow(user string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, at := range t.answered {
+		if now.Sub(at) >= textCommandCooldown {
+			delete(t.answered, id)
+		}
+	}
+	if _, ok := t.answered[user]; ok {
+		return false
+	}
+	t.answered[user] = now
+	return true
+}
+
+// answer returns the reply to a text command, "" for messages that are not one of ours
+// Unknown commands get no reply, since other bots in the channel may share the prefix
+func (t *textCommands) answer(snap *StatusSnapshot, cmd, arg string) string {
+	switch cmd {
+	case "servers":
+		return renderTextServers(snap)
+	case "status":
+		if arg == "" {
+			return fmt.Sprintf("Usage: `%sstatus <server name>`", t.prefix)
+		}
+		return renderTextServer(snap, arg)
+	case "help":
+		return fmt.Sprintf("`%sservers` lists all servers, `%sstatus <name>` shows one", t.prefix, t.prefix)
+	}
+	return ""
+}
+
+// textServerLine is the one-line summary of a server used by both commands
+func textServerLine(srv ServerStatus, now time.Time) string {
+	if !srv.Online {
+		if srv.Maintenance {
+			return fmt.Sprintf("🔧 %s — %s", srv.Name, srv.MaintenanceNote())
+		}
+		return fmt.Sprintf("🔴 %s — offline", srv.Name)
+	}
+	dot := "🟢"
+	if srv.Maintenance {
+		dot = "🔧"
+	} else if srv.Stale {
+		dot = "⚪"
+	}
+	line := fmt.Sprintf("%s %s — %s — %s", dot, srv.Name, srv.Map, srv.PlayerCount())
+	if age := srv.StaleAge(now); age != "" {
+		line += fmt.Sprintf(" — data %s old", age)
+	}
+	return line
+}
+
+// renderTextServers lists every server by category, cut to one message
+func renderTextServers(snap *StatusSnapshot) string {
+	if snap == nil {
+		return "No server data yet; try again after the next update"
+	}
+	lines := []string{fmt.Sprintf("**%d players online**", snap.TotalPlayers)}
+	for _, cat := range snap.Categories {
+		lines = append(lines, fmt.Sprintf("%s **%s** — %d players", cat.Emoji, cat.Name, cat.Players))
+		for _, srv := range cat.Servers {
+			lines = append(lines, textServerLine(srv, snap.UpdatedAt))
+		}
+	}
+	var b strings.Builder
+	for i, line := range lines {
+		more := fmt.Sprintf("\n… and %d more lines", len(lines)-i)
+		if b.Len()+1+len(line)+len(more) > maxTextReply {
+			b.WriteString(more)
+			break
+		}
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// matchServers returns the server named name, or else every server whose name contains it (case-insensitive)
+func matchServers(snap *StatusSnapshot, name string) []ServerStatus {
+	var matches []ServerStatus
+	for _, cat := range snap.Categories {
+		for _, srv := range cat.Servers {
+			if strings.EqualFold(srv.Name, name) {
+				return []ServerStatus{srv}
+			}
+			if strings.Contains(strings.ToLower(srv.Name), strings.ToLower(name)) {
+				matches = append(matches, srv)
+			}
+		}
+	}
+	return matches
+}
+
+// renderTextServer shows one server, matched by exact name or else by a unique part of its name
+func renderTextServer(snap *StatusSnapshot, name string) string {
+	if snap == nil {
+		return "No server data yet; try again after the next update"
+	}
+	matches := matchServers(snap, name)
+	switch {
+	case len(matches) == 0:
+		return fmt.Sprintf("No server matches %q", truncateRunes(name, 50))
+	case len(matches) > 1:
+		names := make([]string, 0, len(matches))
+		for _, srv := range matches[:min(len(matches), 10)] {
+			names = append(names, srv.Name)
+		}
+		return fmt.Sprintf("%d servers match %q: %s", len(matches), truncateRunes(name, 50), strings.Join(names, ", "))
+	}
+	srv := matches[0]
+	lines := []string{textServerLine(srv, snap.UpdatedAt)}
+	if note := srv.MaintenanceNote(); note != "" && srv.Online {
+		lines = append(lines, "🔧 "+note)
+	}
+	if srv.Event != "" {
+		lines = append(lines, "🏁 Event: "+srv.Event)
+	}
+	if srv.NextTrack != "" && srv.NextTrackAt != nil {
+		lines = append(lines, fmt.Sprintf("⏭️ Next: %s at %s", srv.NextTrack, srv.NextTrackAt.Format("15:04 MST")))
+	}
+	if srv.CarClasses != "" {
+		lines = append(lines, "🚗 "+srv.CarClasses)
+	}
+	if srv.Online && srv.JoinURL != "" {
+		lines = append(lines, fmt.Sprintf("[Join Server](<%s>)", srv.JoinURL))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// answerTextCommand replies to m if it is a text command in an allowlisted channel (leader only)
+// Bots, other channels and members within their cooldown are ignored; replies mention nobody
+func (b *Bot) answerTextCommand(m *discordgo.Message) {
+	t := b.textCommands
+	if t == nil || b.discord == nil || m.Author == nil || m.Author.Bot || !t.channels[m.ChannelID] || !b.isLeader() {
+		return
+	}
+	cmd, arg, ok := t.parse(m.Content)
+	if !ok {
+		return
+	}
+	reply := t.answer(b.lastStatus.Load(), cmd, arg)
+	if reply == "" || !t.allow(m.Author.ID, time.Now()) {
+		return
+	}
+	_, err := b.discord.session.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Content:         reply,
+		Reference:       m.Reference(),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		log.Printf("Error answering %s%s from %s: %v", t.prefix, cmd, m.Author.Username, err)
+	}
+}
+
+// onMessageCreate passes new messages to the text command handler
+func (b *Bot) onMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
+	defer supervisor.Recover("text command", log.Default())
+	b.answerTextCommand(m.Message)
+}
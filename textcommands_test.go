@@ -0,0 +1,124 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// textChannel is the allowlisted channel in these tests
+const textChannel = "223456789012345678"
+
+// testTextSnapshot is a poll with Drift #1 online (Spa, 3/10) and Track #1 offline
+func testTextSnapshot() *StatusSnapshot {
+	cfg := testMaintenanceConfig()
+	infos := []ServerInfo{
+		{Name: "Drift #1", Category: "Drift", Map: "Spa", NumPlayers: 3, MaxPlayers: 10, IP: "203.0.113.10", Port: 8081},
+		{Name: "Track #1", Category: "Track", NumPlayers: -1, IP: "203.0.113.10", Port: 8082},
+	}
+	return buildStatusSnapshot(infos, cfg, time.Now())
+}
+
+// TestTextCommandsFromEnv tests the flag, prefix and the required channel allowlist
+func TestTextCommandsFromEnv(t *testing.T) {
+	t.Setenv("TEXT_COMMANDS", "")
+	if tc, err := textCommandsFromEnv(); tc != nil || err != nil {
+		t.Errorf("Expected disabled by default, got %+v, %v", tc, err)
+	}
+	t.Setenv("TEXT_COMMANDS", "true")
+	t.Setenv("TEXT_COMMAND_CHANNELS", " "+textChannel+", ")
+	tc, err := textCommandsFromEnv()
+	if err != nil || tc.prefix != "!" || !tc.channels[textChannel] || len(tc.channels) != 1 {
+		t.Fatalf("Unexpected handler %+v, %v", tc, err)
+	}
+
+	invalid := map[string][2]string{
+		"no channels":     {"", "!"},
+		"channel name":    {"#status", "!"},
+		"prefix spaces":   {textChannel, "! "},
+		"prefix too long": {textChannel, "!!!!!!"},
+	}
+	for name, env := range invalid {
+		t.Setenv("TEXT_COMMAND_CHANNELS", env[0])
+		t.Setenv("TEXT_COMMAND_PREFIX", env[1])
+		if _, err := textCommandsFromEnv(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// TestTextCommandAnswers tests parsing and the replies of !servers, !status and unknown commands
+func TestTextCommandAnswers(t *testing.T) {
+	tc := &textCommands{prefix: "!"}
+	snap := testTextSnapshot()
+	answer := func(content string) string {
+		cmd, arg, ok := tc.parse(content)
+		if !ok {
+			return ""
+		}
+		return tc.answer(snap, cmd, arg)
+	}
+
+	if got := answer("!Servers"); !strings.Contains(got, "🟢 Drift #1 — Spa — 3/10") || !strings.Contains(got, "🔴 Track #1 — offline") {
+		t.Errorf("Unexpected !servers reply:\n%s", got)
+	}
+	if got := answer("!status drift #1"); !strings.Contains(got, "Drift #1 — Spa") || !strings.Contains(got, "httpPort=8081") {
+		t.Errorf("Unexpected !status reply:\n%s", got)
+	}
+	if got := answer("!status #1"); !strings.Contains(got, "2 servers match") {
+		t.Errorf("Expected an ambiguous match, got %q", got)
+	}
+	if got := answer("!status rally"); !strings.Contains(got, "No server matches") {
+		t.Errorf("Unexpected reply %q", got)
+	}
+	if got := answer("!status"); !strings.Contains(got, "Usage") {
+		t.Errorf("Unexpected reply %q", got)
+	}
+	for _, content := range []string{"!play music", "hello !servers", "servers"} {
+		if got := answer(content); got != "" {
+			t.Errorf("%q: expected no reply, got %q", content, got)
+		}
+	}
+	if got := tc.answer(nil, "servers", ""); !strings.Contains(got, "No server data yet") {
+		t.Errorf("Unexpected reply before the first poll %q", got)
+	}
+}
+
+// TestRenderTextServersLimit tests that a long server list is cut to one message
+func TestRenderTextServersLimit(t *testing.T) {
+	cfg := testMaintenanceConfig()
+	var infos []ServerInfo
+	for i := range 100 {
+		infos = append(infos, ServerInfo{Name: strings.Repeat("x", 20) + string(rune('a'+i%26)), Category: "Drift", Map: "Spa", NumPlayers: 1, MaxPlayers: 10})
+	}
+	got := renderTextServers(buildStatusSnapshot(infos, cfg, time.Now()))
+	if len(got) > maxTextReply || !strings.Contains(got, "more lines") {
+		t.Errorf("Expected a cut reply within %d bytes, got %d bytes", maxTextReply, len(got))
+	}
+}
+
+// TestAnswerTextCommand tests replies through the fake Discord session: allowlist, bots and the cooldown
+func TestAnswerTextCommand(t *testing.T) {
+	f := newFakeDiscord()
+	b := newFakeDiscordBot(t, f, testMaintenanceConfig())
+	b.textCommands = &textCommands{prefix: "!", channels: map[string]bool{fakeChannelID: true}, answered: make(map[string]time.Time)}
+	b.lastStatus.Store(testTextSnapshot())
+	alice := &discordgo.User{ID: "11", Username: "alice"}
+
+	b.answerTextCommand(&discordgo.Message{ID: "1", ChannelID: "999", Author: alice, Content: "!servers"})
+	b.answerTextCommand(&discordgo.Message{ID: "2", ChannelID: fakeChannelID, Author: &discordgo.User{ID: "12", Bot: true}, Content: "!servers"})
+	if n := f.count("ChannelMessageSendComplex"); n != 0 {
+		t.Fatalf("Expected no reply outside the allowlist or to bots, got %d", n)
+	}
+	b.answerTextCommand(&discordgo.Message{ID: "3", ChannelID: fakeChannelID, Author: alice, Content: "!servers"})
+	msgs := f.channelMessages(fakeChannelID)
+	if len(msgs) != 1 || !strings.Contains(msgs[0].Content, "Drift #1") {
+		t.Fatalf("Expected one reply, got %+v", msgs)
+	}
+	b.answerTextCommand(&discordgo.Message{ID: "4", ChannelID: fakeChannelID, Author: alice, Content: "!status Track #1"})
+	if msgs := f.channelMessages(fakeChannelID); len(msgs) != 1 {
+		t.Errorf("Expected the cooldown to skip the second command, got %d messages", len(msgs))
+	}
+}
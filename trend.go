@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// ================= PLAYER TRENDS =================
+
+// trendWindow is how much player history the trend covers
+const trendWindow = time.Hour
+
+// trendBuckets splits trendWindow into sparkline points (10 minutes each)
+const trendBuckets = 6
+
+// sparkLevels are the sparkline glyphs from empty to full
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// Trend indicator modes (PLAYER_TRENDS)
+const (
+	trendModeArrow     = "arrow"
+	trendModeSparkline = "sparkline"
+)
+
+// trendSample is one polled player count
+type trendSample struct {
+	at      time.Time
+	players int
+}
+
+// trendTracker keeps the last trendWindow of player counts per server and renders them as a trend indicator
+type trendTracker struct {
+	mode string
+
+	mu      sync.Mutex
+	samples map[serverKey][]trendSample
+}
+
+// newTrendTracker creates a tracker rendering mode (trendModeArrow or trendModeSparkline)
+func newTrendTracker(mode string) *trendTracker {
+	return &trendTracker{mode: mode, samples: make(map[serverKey][]trendSample)}
+}
+
+// apply records the fresh player counts in infos and sets each server's Trend
+// Offline servers count as 0 players; last known (stale tracking) data is not recorded again
+func (t *trendTracker) apply(infos []ServerInfo, now time.Time) []ServerInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := make(map[serverKey]bool, len(infos))
+	out := make([]ServerInfo, len(infos))
+	for i, info := range infos {
+		key := serverKey{info.Name, info.Port}
+		current[key] = true
+
+		samples := t.samples[key]
+		if info.LastSeen.IsZero() {
+			samples = append(samples, trendSample{at: now, players: max(info.NumPlayers, 0)})
+		}
+		for len(samples) > 0 && now.Sub(samples[0].at) > trendWindow {
+			samples = samples[1:]
+		}
+		t.samples[key] = samples
+
+		out[i] = info
+		out[i].Trend = t.render(trendPoints(samples, now), info.MaxPlayers)
+	}
+
+	// Forget servers removed from the config
+	for key := range t.samples {
+		if !current[key] {
+			delete(t.samples, key)
+		}
+	}
+	return out
+}
+
+// trendPoints averages samples into trendBuckets equal slices of the window, oldest first
+// Slices before the first sample are dropped; gaps repeat the previous point
+func trendPoints(samples []trendSample, now time.Time) []int {
+	sums := make([]int, trendBuckets)
+	counts := make([]int, trendBuckets)
+	width := trendWindow / trendBuckets
+	for _, s := range samples {
+		i := trendBuckets - 1 - int(now.Sub(s.at)/width)
+		if i < 0 {
+			i = 0
+		}
+		sums[i] += s.players
+		counts[i]++
+	}
+
+	var points []int
+	for i := range sums {
+		switch {
+		case counts[i] > 0:
+			points = append(points, (sums[i]+counts[i]/2)/counts[i])
+		case len(points) > 0:
+			points = append(points, points[len(points)-1])
+		}
+	}
+	return points
+}
+
+// render formats points as an arrow or sparkline ("" until two points exist)
+// The sparkline is scaled to capacity so a full server reaches the top glyph
+func (t *trendTracker) render(points []int, capacity int) string {
+	if len(points) < 2 {
+		return ""
+	}
+	if t.mode == trendModeArrow {
+		first, last := points[0], points[len(points)-1]
+		switch {
+		case last > first:
+			return "↗"
+		case last < first:
+			return "↘"
+		default:
+			return "→"
+		}
+	}
+
+	for _, p := range points {
+		capacity = max(capacity, p)
+	}
+	spark := make([]rune, len(points))
+	for i, p := range points {
+		level := 0
+		if capacity > 0 {
+			level = (p*(len(sparkLevels)-1) + capacity/2) / capacity
+		}
+		spark[i] = sparkLevels[level]
+	}
+	return string(spark)
+}
+
+// trackTrends records player counts and sets trend indicators (no-op when disabled)
+func (b *Bot) trackTrends(infos []ServerInfo) []ServerInfo {
+	if b.trends == nil {
+		return infos
+	}
+	return b.trends.apply(infos, time.Now())
+}
+
+// trendTrackerFromEnv returns a tracker if PLAYER_TRENDS is arrow or sparkline, nil if unset or off
+func trendTrackerFromEnv() (*trendTracker, error) {
+	mode := os.Getenv("PLAYER_TRENDS")
+	switch mode {
+	case "", "off":
+		return nil, nil
+	case trendModeArrow, trendModeSparkline:
+		log.Printf("Player trends enabled: %s over the last %v", mode, trendWindow)
+		return newTrendTracker(mode), nil
+	}
+	return nil, fmt.Errorf("invalid PLAYER_TRENDS %q: use arrow, sparkline or off", mode)
+}
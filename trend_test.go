@@ -0,0 +1,129 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTrendPoints tests bucketing by age, averaging and gap filling
+func TestTrendPoints(t *testing.T) {
+	now := time.Now()
+	samples := []trendSample{
+		{at: now.Add(-55 * time.Minute), players: 2}, // oldest slice
+		{at: now.Add(-52 * time.Minute), players: 4},
+		{at: now.Add(-25 * time.Minute), players: 9}, // 4th slice, 2nd and 3rd are gaps
+		{at: now, players: 12},
+	}
+	got := trendPoints(samples, now)
+	want := []int{3, 3, 3, 9, 9, 12}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+
+	// History shorter than the window starts at the first sample
+	if got := trendPoints([]trendSample{{at: now.Add(-15 * time.Minute), players: 1}, {at: now, players: 5}}, now); len(got) != 2 {
+		t.Errorf("Expected 2 points, got %v", got)
+	}
+}
+
+// TestTrendRender tests arrows, sparkline scaling and short history
+func TestTrendRender(t *testing.T) {
+	arrow := newTrendTracker(trendModeArrow)
+	tests := []struct {
+		points []int
+		want   string
+	}{
+		{[]int{2, 5}, "↗"},
+		{[]int{5, 2}, "↘"},
+		{[]int{4, 8, 4}, "→"},
+		{[]int{4}, ""},
+	}
+	for _, tt := range tests {
+		if got := arrow.render(tt.points, 20); got != tt.want {
+			t.Errorf("Arrow for %v: got %q, want %q", tt.points, got, tt.want)
+		}
+	}
+
+	spark := newTrendTracker(trendModeSparkline)
+	if got := spark.render([]int{0, 10, 20}, 20); got != "▁▅█" {
+		t.Errorf("Expected capacity-scaled sparkline, got %q", got)
+	}
+	if got := spark.render([]int{0, 0}, 0); got != "▁▁" {
+		t.Errorf("Expected flat sparkline for unknown capacity, got %q", got)
+	}
+}
+
+// TestTrendTracker_Apply tests recording, pruning, stale data and removed servers
+func TestTrendTracker_Apply(t *testing.T) {
+	tr := newTrendTracker(trendModeArrow)
+	start := time.Now()
+	poll := func(at time.Time, infos ...ServerInfo) []ServerInfo {
+		return tr.apply(infos, at)
+	}
+
+	out := poll(start, ServerInfo{Name: "A", Port: 1, NumPlayers: 2, MaxPlayers: 20}, ServerInfo{Name: "B", Port: 2, NumPlayers: -1})
+	if out[0].Trend != "" {
+		t.Errorf("Expected no trend from one sample, got %q", out[0].Trend)
+	}
+
+	out = poll(start.Add(30*time.Minute), ServerInfo{Name: "A", Port: 1, NumPlayers: 10, MaxPlayers: 20}, ServerInfo{Name: "B", Port: 2, NumPlayers: -1})
+	if out[0].Trend != "↗" || out[1].Trend != "→" {
+		t.Errorf("Expected rising A and steady offline B, got %q and %q", out[0].Trend, out[1].Trend)
+	}
+
+	// Last known data from stale tracking is not recorded as a new sample
+	stale := ServerInfo{Name: "A", Port: 1, NumPlayers: 10, MaxPlayers: 20, LastSeen: start.Add(30 * time.Minute), Stale: true}
+	poll(start.Add(40*time.Minute), stale)
+	if n := len(tr.samples[serverKey{"A", 1}]); n != 2 {
+		t.Errorf("Expected 2 samples after stale poll, got %d", n)
+	}
+	if _, ok := tr.samples[serverKey{"B", 2}]; ok {
+		t.Error("Expected removed server to be forgotten")
+	}
+
+	// Samples older than the window are dropped
+	poll(start.Add(91*time.Minute), ServerInfo{Name: "A", Port: 1, NumPlayers: 1, MaxPlayers: 20})
+	if n := len(tr.samples[serverKey{"A", 1}]); n != 1 {
+		t.Errorf("Expected only the fresh sample within the window, got %d", n)
+	}
+}
+
+// TestTrendIndicators_Rendered tests that the trend appears in the embed and status snapshot
+func TestTrendIndicators_Rendered(t *testing.T) {
+	cfg := testStatusConfig()
+	infos := []ServerInfo{{Name: "Drift 1", Category: "Drift", Map: "ebisu", Players: "5/16", NumPlayers: 5, MaxPlayers: 16, IP: cfg.ServerIP, Port: 8081, Trend: "▁▃▅"}}
+
+	embed := buildEmbed(infos, cfg)
+	if !strings.Contains(embed.Fields[1].Value, "**Players:** 5/16 ▁▃▅") {
+		t.Errorf("Expected trend in embed field, got %q", embed.Fields[1].Value)
+	}
+
+	srv := buildStatusSnapshot(infos, cfg, time.Now()).Categories[0].Servers[0]
+	if srv.Trend != "▁▃▅" || srv.PlayerCount() != "5/16 ▁▃▅" {
+		t.Errorf("Expected trend in snapshot, got %q / %q", srv.Trend, srv.PlayerCount())
+	}
+}
+
+// TestTrendTrackerFromEnv tests PLAYER_TRENDS parsing
+func TestTrendTrackerFromEnv(t *testing.T) {
+	for _, v := range []string{"", "off"} {
+		t.Setenv("PLAYER_TRENDS", v)
+		if tr, err := trendTrackerFromEnv(); tr != nil || err != nil {
+			t.Errorf("Expected trends disabled for %q", v)
+		}
+	}
+	t.Setenv("PLAYER_TRENDS", "sparkline")
+	if tr, err := trendTrackerFromEnv(); err != nil || tr == nil || tr.mode != trendModeSparkline {
+		t.Errorf("Expected sparkline tracker, got %v (%v)", tr, err)
+	}
+	t.Setenv("PLAYER_TRENDS", "bars")
+	if _, err := trendTrackerFromEnv(); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
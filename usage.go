@@ -0,0 +1,69 @@
+package main
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// ================= PLAYER USAGE =================
+
+// buildUsage sums the player-hours and sessions of the buckets in [from, to) per server and per category,
+// each sorted by player-hours
+func buildUsage(buckets []historyBucket, from, to time.Time) *api.Usage {
+	type sums struct {
+		playerSeconds int64
+		sessions      int
+	}
+	servers := make(map[string]*sums)
+	categories := make(map[string]*sums)
+	var total sums
+	add := func(m map[string]*sums, name string) *sums {
+		if m[name] == nil {
+			m[name] = &sums{}
+		}
+		return m[name]
+	}
+	for _, b := range buckets {
+		if b.Hour.Before(from) || !b.Hour.Before(to) {
+			continue
+		}
+		for _, sum := range []*sums{add(servers, b.Server), add(categories, b.Category), &total} {
+			sum.playerSeconds += b.PlayerSeconds
+			sum.sessions += b.Sessions
+		}
+	}
+
+	totals := func(m map[string]*sums) []api.UsageTotal {
+		names := slices.SortedFunc(maps.Keys(m), func(a, b string) int {
+			return cmp.Or(cmp.Compare(m[b].playerSeconds, m[a].playerSeconds), strings.Compare(a, b))
+		})
+		out := make([]api.UsageTotal, len(names))
+		for i, name := range names {
+			out[i] = api.UsageTotal{Name: name, PlayerHours: playerHours(m[name].playerSeconds), Sessions: m[name].sessions}
+		}
+		return out
+	}
+	return &api.Usage{
+		From:        from,
+		To:          to,
+		PlayerHours: playerHours(total.playerSeconds),
+		Sessions:    total.sessions,
+		Servers:     totals(servers),
+		Categories:  totals(categories),
+	}
+}
+
+// usageProvider adapts the bot's history to api.UsageProvider
+type usageProvider struct {
+	bot *Bot
+}
+
+// Usage implements api.UsageProvider
+func (p *usageProvider) Usage(from, to time.Time) *api.Usage {
+	return buildUsage(p.bot.history.buckets(from, to), from, to)
+}
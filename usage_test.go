@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestBuildUsage tests the totals per server and category, their order and the range bounds
+func TestBuildUsage(t *testing.T) {
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	buckets := []historyBucket{
+		{Hour: from, Server: "Track #1", Category: "Track", PlayerSeconds: 3600, Sessions: 2},
+		{Hour: from.Add(time.Hour), Server: "Track #2", Category: "Track", PlayerSeconds: 7200, Sessions: 3},
+		{Hour: from.Add(2 * time.Hour), Server: "Drift #1", Category: "Drift", PlayerSeconds: 1800, Sessions: 4},
+		{Hour: to, Server: "Drift #1", Category: "Drift", PlayerSeconds: 36000, Sessions: 9}, // after the range
+	}
+	u := buildUsage(buckets, from, to)
+	if u.PlayerHours != 3.5 || u.Sessions != 9 {
+		t.Errorf("Expected 3.5 player-hours and 9 sessions, got %v and %d", u.PlayerHours, u.Sessions)
+	}
+	if len(u.Servers) != 3 || u.Servers[0].Name != "Track #2" || u.Servers[2].Name != "Drift #1" || u.Servers[2].Sessions != 4 {
+		t.Errorf("Unexpected servers %+v", u.Servers)
+	}
+	if len(u.Categories) != 2 || u.Categories[0].Name != "Track" || u.Categories[0].PlayerHours != 3 || u.Categories[0].Sessions != 5 {
+		t.Errorf("Unexpected categories %+v", u.Categories)
+	}
+}
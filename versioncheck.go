@@ -0,0 +1,307 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bombom/absa-ac/api"
+)
+
+// ================= VERSION CHECK =================
+
+const (
+	// defaultReleaseFeedURL is the latest release of the project (GitHub releases API) unless VERSION_CHECK_URL is set
+	defaultReleaseFeedURL = "https://api.github.com/repos/Griznah/absa-ac/releases/latest"
+	// versionCheckInterval is how often the release feed is checked after the check at startup
+	versionCheckInterval = 24 * time.Hour
+	// versionCheckTimeout bounds one request to the release feed
+	versionCheckTimeout = 30 * time.Second
+	// maxReleaseFeedBody bounds the release document read
+	maxReleaseFeedBody = 1 << 20
+)
+
+// buildVersion overrides the module version of the build info; set it with
+// -ldflags "-X main.buildVersion=v1.2.3" where the build has no module version (container images, go build .)
+var buildVersion string
+
+// buildInfo is the version and commit of the running binary
+type buildInfo struct {
+	Version  string // module version, "(devel)" outside a release
+	Commit   string // VCS revision ("" = unknown)
+	Modified bool   // built from a working tree with uncommitted changes
+}
+
+// readBuildInfo returns the version and commit recorded by the Go toolchain
+func readBuildInfo() buildInfo {
+	b := buildInfo{Version: "(devel)"}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if bi.Main.Version != "" {
+			b.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				b.Commit = s.Value
+			case "vcs.modified":
+				b.Modified = s.Value == "true"
+			}
+		}
+	}
+	if buildVersion != "" {
+		b.Version = buildVersion
+	}
+	return b
+}
+
+// String returns the version with the short commit, e.g. "v1.2.3 (commit 0123abcd)"
+func (b buildInfo) String() string {
+	if b.Commit == "" {
+		return b.Version
+	}
+	commit := b.Commit[:min(len(b.Commit), 12)]
+	if b.Modified {
+		commit += ", modified"
+	}
+	return fmt.Sprintf("%s (commit %s)", b.Version, commit)
+}
+
+// semver is a parsed vMAJOR.MINOR.PATCH[-PRERELEASE] version (build metadata is dropped)
+type semver struct {
+	core [3]int
+	pre  []string
+}
+
+// parseSemver parses a version with or without the leading v
+func parseSemver(s string) (semver, bool) {
+	var v semver
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	core, pre, hasPre := strings.Cut(s, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.core[i] = n
+	}
+	if hasPre {
+		if pre == "" {
+			return v, false
+		}
+		v.pre = strings.Split(pre, ".")
+	}
+	return v, true
+}
+
+// compare returns -1, 0 or 1 following semver precedence: a pre-release sorts before its release
+func (v semver) compare(o semver) int {
+	for i := range v.core {
+		if v.core[i] != o.core[i] {
+			return cmp.Compare(v.core[i], o.core[i])
+		}
+	}
+	switch {
+	case len(v.pre) == 0 && len(o.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(o.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.pre) && i < len(o.pre); i++ {
+		a, aErr := strconv.Atoi(v.pre[i])
+		b, bErr := strconv.Atoi(o.pre[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if a != b {
+				return cmp.Compare(a, b)
+			}
+		case aErr == nil:
+			return -1 // numeric identifiers sort before alphanumeric ones
+		case bErr == nil:
+			return 1
+		case v.pre[i] != o.pre[i]:
+			return strings.Compare(v.pre[i], o.pre[i])
+		}
+	}
+	return cmp.Compare(len(v.pre), len(o.pre))
+}
+
+// releaseInfo is the part of a GitHub release document the check uses
+type releaseInfo struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// versionChecker compares the running version with the latest release at startup and every day, and logs a
+// newer release once, shows it on /health and optionally posts it to a Discord admin channel (leader only)
+type versionChecker struct {
+	url      string
+	client   *http.Client
+	build    buildInfo
+	notify   func(msg string) error // nil = no Discord message
+	isLeader func() bool
+
+	mu        sync.Mutex
+	latest    *releaseInfo
+	lastErr   error
+	announced string // release already logged
+	posted    string // release already posted to Discord
+}
+
+// versionCheckerFromEnv returns the checker if VERSION_CHECK_ENABLED is true, nil otherwise
+// VERSION_CHECK_URL replaces the release feed; VERSION_CHECK_CHANNEL_ID posts new releases (bot mode)
+func versionCheckerFromEnv(b *Bot) (*versionChecker, error) {
+	if os.Getenv("VERSION_CHECK_ENABLED") != "true" {
+		return nil, nil
+	}
+	feed := defaultReleaseFeedURL
+	if v := os.Getenv("VERSION_CHECK_URL"); v != "" {
+		if !strings.HasPrefix(v, "https://") && !strings.HasPrefix(v, "http://") {
+			return nil, fmt.Errorf("invalid VERSION_CHECK_URL %q: must be an http(s) URL", v)
+		}
+		feed = v
+	}
+	c := newVersionChecker(feed, readBuildInfo())
+	c.isLeader = b.isLeader
+	if channelID := os.Getenv("VERSION_CHECK_CHANNEL_ID"); channelID != "" {
+		if b.discord == nil {
+			return nil, fmt.Errorf("VERSION_CHECK_CHANNEL_ID requires bot mode (DISCORD_TOKEN), not a webhook")
+		}
+		c.notify = func(msg string) error {
+			_, err := b.discord.session.ChannelMessageSend(channelID, msg)
+			return err
+		}
+	}
+	log.Printf("Version check enabled: running %s, checking %s daily", c.build, feed)
+	return c, nil
+}
+
+func newVersionChecker(feed string, build buildInfo) *versionChecker {
+	return &versionChecker{url: feed, client: &http.Client{Timeout: versionCheckTimeout}, build: build}
+}
+
+// Run checks at startup and then every versionCheckInterval until ctx is cancelled
+func (c *versionChecker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(versionCheckInterval)
+	defer ticker.Stop()
+	for {
+		c.check(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetch reads the latest release from the feed
+func (c *versionChecker) fetch(ctx context.Context) (*releaseInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "absa-ac/"+c.build.Version)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("release feed returned HTTP %d", resp.StatusCode)
+	}
+	var rel releaseInfo
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxReleaseFeedBody)).Decode(&rel); err != nil {
+		return nil, fmt.Errorf("invalid release feed: %w", err)
+	}
+	if _, ok := parseSemver(rel.TagName); !ok {
+		return nil, fmt.Errorf("release feed has no version tag (tag_name %q)", rel.TagName)
+	}
+	return &rel, nil
+}
+
+// newer reports whether rel is newer than the running version; false when the running version is unknown
+func (c *versionChecker) newer(rel *releaseInfo) bool {
+	running, ok := parseSemver(c.build.Version)
+	latest, _ := parseSemver(rel.TagName)
+	return ok && latest.compare(running) > 0
+}
+
+// check fetches the latest release and announces it once if it is newer; failures are logged and kept for /health
+func (c *versionChecker) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, versionCheckTimeout)
+	defer cancel()
+	rel, err := c.fetch(ctx)
+
+	c.mu.Lock()
+	c.lastErr = err
+	if err != nil {
+		c.mu.Unlock()
+		log.Printf("Warning: version check failed: %v", err)
+		return
+	}
+	c.latest = rel
+	if !c.newer(rel) {
+		c.mu.Unlock()
+		return
+	}
+	logIt := c.announced != rel.TagName
+	c.announced = rel.TagName
+	post := c.notify != nil && c.posted != rel.TagName && (c.isLeader == nil || c.isLeader())
+	c.mu.Unlock()
+
+	if logIt {
+		log.Printf("New version available: %s (running %s): %s", rel.TagName, c.build, rel.HTMLURL)
+	}
+	if !post {
+		return
+	}
+	msg := fmt.Sprintf("🆕 **absa-ac %s** is available (running %s)\n%s", rel.TagName, c.build, rel.HTMLURL)
+	if err := c.notify(msg); err != nil {
+		log.Printf("Error posting version notification: %v", err)
+		return
+	}
+	c.mu.Lock()
+	c.posted = rel.TagName
+	c.mu.Unlock()
+}
+
+// healthCheck reports the running version and a newer release; it always passes, since an outdated bot
+// or an unreachable feed is not a fault of the running one
+func (c *versionChecker) healthCheck() api.HealthCheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := api.HealthCheckResult{Name: "version", OK: true}
+	_, known := parseSemver(c.build.Version)
+	switch {
+	case c.lastErr != nil:
+		res.Detail = fmt.Sprintf("running %s; release check failed: %v", c.build, c.lastErr)
+	case c.latest == nil:
+		res.Detail = fmt.Sprintf("running %s; release not checked yet", c.build)
+	case c.newer(c.latest):
+		res.Detail = fmt.Sprintf("running %s; new version %s available: %s", c.build, c.latest.TagName, c.latest.HTMLURL)
+	case !known:
+		res.Detail = fmt.Sprintf("running %s; latest release is %s", c.build, c.latest.TagName)
+	default:
+		res.Detail = fmt.Sprintf("running %s; up to date", c.build)
+	}
+	return res
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestSemverCompare tests release ordering, pre-releases and pseudo-versions
+func TestSemverCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"v1.10.0", "v1.9.9", 1},
+		{"v2.0.0", "v1.99.99", 1},
+		{"v1.3.0-rc.1", "v1.3.0", -1},
+		{"v1.3.0-rc.2", "v1.3.0-rc.10", -1},
+		{"v1.3.0-alpha", "v1.3.0-1", 1},
+		{"v1.3.0-alpha", "v1.3.0-alpha.1", -1},
+		{"v1.2.4-0.20261017120000-0123456789ab", "v1.2.3", 1},
+		{"v1.2.3+build.5", "v1.2.3", 0},
+	}
+	for _, tt := range tests {
+		a, okA := parseSemver(tt.a)
+		b, okB := parseSemver(tt.b)
+		if !okA || !okB {
+			t.Fatalf("Failed to parse %s or %s", tt.a, tt.b)
+		}
+		if got := a.compare(b); got != tt.want {
+			t.Errorf("compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+	for _, s := range []string{"(devel)", "v1.2", "v1.2.x", "v1.2.3-", ""} {
+		if _, ok := parseSemver(s); ok {
+			t.Errorf("Expected %q to be refused", s)
+		}
+	}
+}
+
+// TestVersionChecker tests announcing a newer release once, /health reporting and feed errors
+func TestVersionChecker(t *testing.T) {
+	tag := "v1.3.0"
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("User-Agent"), "absa-ac/v1.2.0") {
+			t.Errorf("Unexpected User-Agent %q", r.Header.Get("User-Agent"))
+		}
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"tag_name": %q, "html_url": "https://example.com/releases/%s"}`, tag, tag)
+	}))
+	defer srv.Close()
+
+	c := newVersionChecker(srv.URL, buildInfo{Version: "v1.2.0", Commit: "0123456789abcdef0123"})
+	var posts []string
+	c.notify = func(msg string) error {
+		posts = append(posts, msg)
+		return nil
+	}
+	if check := c.healthCheck(); !check.OK || !strings.Contains(check.Detail, "not checked yet") {
+		t.Errorf("Expected not checked yet, got %+v", check)
+	}
+
+	c.check(context.Background())
+	c.check(context.Background())
+	if len(posts) != 1 || !strings.Contains(posts[0], "v1.3.0") || !strings.Contains(posts[0], "v1.2.0 (commit 0123456789ab)") {
+		t.Errorf("Expected one post with both versions, got %q", posts)
+	}
+	check := c.healthCheck()
+	if !check.OK || !strings.Contains(check.Detail, "new version v1.3.0 available") || !strings.Contains(check.Detail, "commit 0123456789ab") {
+		t.Errorf("Expected the new version on /health, got %+v", check)
+	}
+
+	tag = "v1.2.0"
+	c.check(context.Background())
+	if check := c.healthCheck(); !strings.Contains(check.Detail, "up to date") {
+		t.Errorf("Expected up to date, got %+v", check)
+	}
+
+	status = http.StatusForbidden
+	c.check(context.Background())
+	if check := c.healthCheck(); !check.OK || !strings.Contains(check.Detail, "HTTP 403") {
+		t.Errorf("Expected a passing check with the feed error, got %+v", check)
+	}
+}
+
+// TestVersionChecker_Standby tests that standbys and development builds post nothing
+func TestVersionChecker_Standby(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name": "v9.0.0", "html_url": "https://example.com"}`)
+	}))
+	defer srv.Close()
+
+	posted := 0
+	notify := func(string) error { posted++; return nil }
+	standby := newVersionChecker(srv.URL, buildInfo{Version: "v1.0.0"})
+	standby.notify = notify
+	standby.isLeader = func() bool { return false }
+	standby.check(context.Background())
+
+	devel := newVersionChecker(srv.URL, buildInfo{Version: "(devel)"})
+	devel.notify = notify
+	devel.check(context.Background())
+	if posted != 0 {
+		t.Errorf("Expected no posts, got %d", posted)
+	}
+	if check := devel.healthCheck(); !strings.Contains(check.Detail, "latest release is v9.0.0") {
+		t.Errorf("Expected the latest release for a development build, got %+v", check)
+	}
+}
+
+// TestVersionCheckerFromEnv tests the switch, URL check and the bot mode requirement of the channel
+func TestVersionCheckerFromEnv(t *testing.T) {
+	b := &Bot{}
+	if c, err := versionCheckerFromEnv(b); c != nil || err != nil {
+		t.Fatalf("Expected disabled by default, got %v %v", c, err)
+	}
+	t.Setenv("VERSION_CHECK_ENABLED", "true")
+	c, err := versionCheckerFromEnv(b)
+	if err != nil || c.url != defaultReleaseFeedURL || c.notify != nil {
+		t.Fatalf("Expected the default feed without notifications, got %+v %v", c, err)
+	}
+	t.Setenv("VERSION_CHECK_URL", "ftp://example.com")
+	if _, err := versionCheckerFromEnv(b); err == nil {
+		t.Error("Expected a non-HTTP feed to be refused")
+	}
+	t.Setenv("VERSION_CHECK_URL", "")
+	t.Setenv("VERSION_CHECK_CHANNEL_ID", "123")
+	if _, err := versionCheckerFromEnv(b); err == nil {
+		t.Error("Expected VERSION_CHECK_CHANNEL_ID to require bot mode")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// ================= SYSTEMD / CONTAINER WATCHDOG =================
+
+// defaultWatchdogStallIntervals is how many update intervals may pass without a
+// completed status update before the bot is considered wedged
+const defaultWatchdogStallIntervals = 3
+
+// sdNotify sends a state string to the service manager via $NOTIFY_SOCKET
+// Returns (false, nil) when no notify socket is configured (not running under systemd/podman)
+// Supports abstract namespace sockets (leading '@') as used by systemd
+func sdNotify(state string) (bool, error) {
+	socketPath := os.Getenv("NOTIFY_SOCKET")
+	if socketPath == "" {
+		return false, nil
+	}
+
+	addr := &net.UnixAddr{Name: socketPath, Net: "unixgram"}
+	if socketPath[0] == '@' {
+		addr.Name = "\x00" + socketPath[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("failed to write to notify socket: %w", err)
+	}
+	return true, nil
+}
+
+// watchdogInterval returns the keep-alive ping interval requested by the service manager
+// Reads WATCHDOG_USEC (and WATCHDOG_PID if set) and returns half the timeout, as recommended by sd_watchdog_enabled(3)
+// Returns 0 if the watchdog is not enabled for this process
+func watchdogInterval() (time.Duration, error) {
+	usecStr := os.Getenv("WATCHDOG_USEC")
+	if usecStr == "" {
+		return 0, nil
+	}
+
+	// WATCHDOG_PID restricts the watchdog to a specific process (not our children)
+	if pidStr := os.Getenv("WATCHDOG_PID"); pidStr != "" {
+		pid, err := strconv.Atoi(pidStr)
+		if err != nil {
+			return 0, fmt.Errorf("invalid WATCHDOG_PID %q: %w", pidStr, err)
+		}
+		if pid != os.Getpid() {
+			return 0, nil
+		}
+	}
+
+	usec, err := strconv.ParseInt(usecStr, 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, fmt.Errorf("invalid WATCHDOG_USEC %q", usecStr)
+	}
+
+	return time.Duration(usec) * time.Microsecond / 2, nil
+}
+
+// Watchdog tracks update loop liveness and relays it to the service manager
+// The update loop calls Beat after every completed update; Run only pings WATCHDOG=1
+// while the last beat is younger than stallIntervals update intervals
+type Watchdog struct {
+	lastBeat       atomic.Int64 // unix nanoseconds of last completed status update
+	stallIntervals int
+	interval       func() time.Duration // current update interval (follows config reloads)
+	notify         func(string) (bool, error)
+}
+
+// NewWatchdog creates a watchdog that considers the bot stalled after stallIntervals missed update intervals
+// interval is evaluated on every check so config reloads changing update_interval are honoured
+func NewWatchdog(stallIntervals int, interval func() time.Duration) *Watchdog {
+	if stallIntervals < 1 {
+		stallIntervals = defaultWatchdogStallIntervals
+	}
+	w := &Watchdog{
+		stallIntervals: stallIntervals,
+		interval:       interval,
+		notify:         sdNotify,
+	}
+	w.Beat()
+	return w
+}
+
+// Beat records a completed status update
+func (w *Watchdog) Beat() {
+	w.lastBeat.Store(time.Now().UnixNano())
+}
+
+// Healthy reports whether the update loop has beaten within the stall threshold
+func (w *Watchdog) Healthy(now time.Time) bool {
+	limit := time.Duration(w.stallIntervals) * w.interval()
+	return now.Sub(time.Unix(0, w.lastBeat.Load())) <= limit
+}
+
+// Ready notifies the service manager that startup completed
+func (w *Watchdog) Ready() {
+	if sent, err := w.notify("READY=1"); err != nil {
+		log.Printf("Warning: sd_notify READY failed: %v", err)
+	} else if sent {
+		log.Println("Notified service manager: READY")
+	}
+}
+
+// Stopping notifies the service manager that shutdown has begun
+func (w *Watchdog) Stopping() {
+	if _, err := w.notify("STOPPING=1"); err != nil {
+		log.Printf("Warning: sd_notify STOPPING failed: %v", err)
+	}
+}
+
+// Run pings the service manager every pingInterval until ctx is cancelled
+// When the update loop stalls, pings stop and WATCHDOG=trigger is sent once so the
+// service manager restarts the bot immediately instead of waiting for the timeout
+func (w *Watchdog) Run(ctx context.Context, pingInterval time.Duration) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	triggered := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if w.Healthy(now) {
+				if triggered {
+					log.Println("Watchdog: update loop recovered")
+					triggered = false
+				}
+				if _, err := w.notify("WATCHDOG=1"); err != nil {
+					log.Printf("Warning: sd_notify WATCHDOG failed: %v", err)
+				}
+				continue
+			}
+
+			if !triggered {
+				log.Printf("Watchdog: update loop stalled for more than %d intervals, requesting restart",
+					w.stallIntervals)
+				if _, err := w.notify("WATCHDOG=trigger"); err != nil {
+					log.Printf("Warning: sd_notify WATCHDOG=trigger failed: %v", err)
+				}
+				triggered = true
+			}
+		}
+	}
+}
+
+// watchdogStallIntervalsFromEnv reads WATCHDOG_STALL_INTERVALS (default 3)
+func watchdogStallIntervalsFromEnv() int {
+	v := os.Getenv("WATCHDOG_STALL_INTERVALS")
+	if v == "" {
+		return defaultWatchdogStallIntervals
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		log.Printf("Warning: invalid WATCHDOG_STALL_INTERVALS %q, using default %d", v, defaultWatchdogStallIntervals)
+		return defaultWatchdogStallIntervals
+	}
+	return n
+}
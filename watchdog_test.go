@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSdNotify_NoSocket tests that sdNotify is a no-op outside systemd
+func TestSdNotify_NoSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	sent, err := sdNotify("READY=1")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sent {
+		t.Error("Expected sent=false when NOTIFY_SOCKET is unset")
+	}
+}
+
+// TestSdNotify_WritesState tests that the state string reaches the notify socket
+func TestSdNotify_WritesState(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to listen on notify socket: %v", err)
+	}
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", socketPath)
+
+	sent, err := sdNotify("READY=1")
+	if err != nil {
+		t.Fatalf("sdNotify failed: %v", err)
+	}
+	if !sent {
+		t.Fatal("Expected sent=true")
+	}
+
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Failed to read from notify socket: %v", err)
+	}
+	if got := string(buf[:n]); got != "READY=1" {
+		t.Errorf("Expected 'READY=1', got '%s'", got)
+	}
+}
+
+// TestWatchdogInterval tests WATCHDOG_USEC / WATCHDOG_PID parsing
+func TestWatchdogInterval(t *testing.T) {
+	tests := []struct {
+		name    string
+		usec    string
+		pid     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "Disabled", usec: "", want: 0},
+		{name: "Half of timeout", usec: "10000000", want: 5 * time.Second},
+		{name: "Matching PID", usec: "2000000", pid: strconv.Itoa(os.Getpid()), want: time.Second},
+		{name: "Other PID", usec: "2000000", pid: "1", want: 0},
+		{name: "Invalid value", usec: "abc", wantErr: true},
+		{name: "Zero value", usec: "0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tt.usec)
+			t.Setenv("WATCHDOG_PID", tt.pid)
+
+			got, err := watchdogInterval()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for WATCHDOG_USEC=%q", tt.usec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestWatchdog_Healthy tests stall detection relative to the update interval
+func TestWatchdog_Healthy(t *testing.T) {
+	w := NewWatchdog(3, func() time.Duration { return 10 * time.Second })
+
+	now := time.Now()
+	if !w.Healthy(now) {
+		t.Error("Expected healthy immediately after creation")
+	}
+	if !w.Healthy(now.Add(29 * time.Second)) {
+		t.Error("Expected healthy within 3 intervals")
+	}
+	if w.Healthy(now.Add(31 * time.Second)) {
+		t.Error("Expected stalled after more than 3 intervals")
+	}
+
+	// Beat resets the stall window
+	w.lastBeat.Store(now.Add(30 * time.Second).UnixNano())
+	if !w.Healthy(now.Add(31 * time.Second)) {
+		t.Error("Expected healthy after beat")
+	}
+}
+
+// TestWatchdog_RunTriggersOnStall tests that pings stop and WATCHDOG=trigger is sent once when stalled
+func TestWatchdog_RunTriggersOnStall(t *testing.T) {
+	var mu sync.Mutex
+	var states []string
+
+	w := NewWatchdog(1, func() time.Duration { return 20 * time.Millisecond })
+	w.notify = func(state string) (bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		states = append(states, state)
+		return true, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx, 5*time.Millisecond)
+		close(done)
+	}()
+
+	// No beats: the watchdog must detect the stall
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	pings, triggers := 0, 0
+	for _, s := range states {
+		switch s {
+		case "WATCHDOG=1":
+			pings++
+		case "WATCHDOG=trigger":
+			triggers++
+		}
+	}
+	if pings == 0 {
+		t.Error("Expected at least one WATCHDOG=1 ping before the stall")
+	}
+	if triggers != 1 {
+		t.Errorf("Expected exactly one WATCHDOG=trigger, got %d", triggers)
+	}
+	if states[len(states)-1] != "WATCHDOG=trigger" {
+		t.Errorf("Expected no pings after trigger, last state was '%s'", states[len(states)-1])
+	}
+}
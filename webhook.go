@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= WEBHOOK MODE =================
+
+// webhookStateFile stores the IDs of the status messages posted through the webhook (one per line, in page order)
+// Kept next to config.json so restarts keep editing the same messages instead of posting new ones
+const webhookStateFile = "webhook_message_id"
+
+// webhookAPI is the subset of discordgo.Session used by WebhookPublisher (mockable in tests)
+type webhookAPI interface {
+	WebhookExecute(webhookID, token string, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	WebhookMessageEdit(webhookID, token, messageID string, data *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	WebhookMessageDelete(webhookID, token, messageID string, options ...discordgo.RequestOption) error
+}
+
+// WebhookPublisher posts and edits the status message through a Discord webhook
+// No bot account or gateway connection is needed; the webhook URL carries its own token
+type WebhookPublisher struct {
+	api       webhookAPI
+	id        string
+	token     string
+	statePath string
+
+	mu         sync.Mutex
+	messageIDs []string
+}
+
+// parseWebhookURL extracts the webhook ID and token from
+// https://discord.com/api/webhooks/{id}/{token} (discordapp.com, ptb. and canary. hosts accepted)
+func parseWebhookURL(raw string) (id, token string, err error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if u.Scheme != "https" {
+		return "", "", fmt.Errorf("invalid webhook URL: scheme must be https")
+	}
+	host := strings.TrimPrefix(strings.TrimPrefix(u.Hostname(), "ptb."), "canary.")
+	if host != "discord.com" && host != "discordapp.com" {
+		return "", "", fmt.Errorf("invalid webhook URL: host must be discord.com")
+	}
+
+	// Path: /api[/v10]/webhooks/{id}/{token}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 3 || parts[len(parts)-3] != "webhooks" || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", "", fmt.Errorf("invalid webhook URL: expected /api/webhooks/{id}/{token}")
+	}
+	return parts[len(parts)-2], parts[len(parts)-1], nil
+}
+
+// NewWebhookPublisher creates a publisher for webhookURL
+// statePath persists the status message ID across restarts (empty disables persistence)
+func NewWebhookPublisher(webhookURL, statePath string) (*WebhookPublisher, error) {
+	id, token, err := parseWebhookURL(webhookURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Webhook endpoints authenticate via the URL token, so the session carries no bot token
+	session, err := discordgo.New("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Discord session: %w", err)
+	}
+
+	p := &WebhookPublisher{api: session, id: id, token: token, statePath: statePath}
+	p.loadMessageID()
+	return p, nil
+}
+
+// loadMessageID restores the status message IDs from the state file (missing file = post a new message)
+func (p *WebhookPublisher) loadMessageID() {
+	if p.statePath == "" {
+		return
+	}
+	data, err := os.ReadFile(p.statePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Warning: failed to read webhook state %s: %v", p.statePath, err)
+		}
+		return
+	}
+	p.messageIDs = strings.Fields(string(data))
+	if len(p.messageIDs) > 0 {
+		log.Printf("Webhook mode: resuming edits of status message %s (%d pages)", p.messageIDs[0], len(p.messageIDs))
+	}
+}
+
+// saveMessageID persists the status message IDs (failure only costs a duplicate message after restart)
+func (p *WebhookPublisher) saveMessageID(ids []string) {
+	if p.statePath == "" {
+		return
+	}
+	if len(ids) == 0 {
+		p.clearMessageID()
+		return
+	}
+	if err := os.WriteFile(p.statePath, []byte(strings.Join(ids, "\n")+"\n"), 0600); err != nil {
+		log.Printf("Warning: failed to save webhook state %s: %v", p.statePath, err)
+	}
+}
+
+// clearMessageID removes the state file so the next update posts a fresh message
+func (p *WebhookPublisher) clearMessageID() {
+	if p.statePath == "" {
+		return
+	}
+	if err := os.Remove(p.statePath); err != nil && !os.IsNotExist(err) {
+		log.Printf("Warning: failed to remove webhook state %s: %v", p.statePath, err)
+	}
+}
+
+// MessageID returns the ID of the status message, or of its first page if split (empty until the first post)
+func (p *WebhookPublisher) MessageID() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.messageIDs) == 0 {
+		return ""
+	}
+	return p.messageIDs[0]
+}
+
+// Name implements Publisher
+func (p *WebhookPublisher) Name() string {
+	return "Discord webhook"
+}
+
+// UpdateStatus implements Publisher: edits the status message group (one message per embed page), posting
+// new messages if none exist, one was deleted or the status needs more pages. A banner (optional) replaces
+// any previous attachments of the first page
+func (p *WebhookPublisher) UpdateStatus(u *StatusUpdate) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing := p.messageIDs
+	ids, err := syncStatusPages(existing, paginateEmbed(u.Embed, u.HeaderColors), bannerFiles(u.Banner), statusPageOps{
+		send: func(embed *discordgo.MessageEmbed, files []*discordgo.File) (string, error) {
+			// wait=true makes Discord return the created message so its ID can be edited later
+			msg, err := p.api.WebhookExecute(p.id, p.token, true, &discordgo.WebhookParams{
+				Embeds: []*discordgo.MessageEmbed{embed},
+				Files:  files,
+			})
+			if err != nil {
+				return "", err
+			}
+			return msg.ID, nil
+		},
+		edit: func(id string, embed *discordgo.MessageEmbed, files []*discordgo.File, first bool) error {
+			edit := &discordgo.WebhookEdit{
+				Embeds: &[]*discordgo.MessageEmbed{embed},
+			}
+			if files != nil || (first && u.DropAttachments) {
+				edit.Files = files
+				edit.Attachments = &[]*discordgo.MessageAttachment{}
+			}
+			_, err := p.api.WebhookMessageEdit(p.id, p.token, id, edit)
+			return err
+		},
+		delete: func(id string) error {
+			return p.api.WebhookMessageDelete(p.id, p.token, id)
+		},
+	})
+	if !slices.Equal(ids, existing) {
+		p.messageIDs = ids
+		p.saveMessageID(ids)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(existing) == 0 || existing[0] != ids[0] {
+		log.Printf("Initial status message posted (webhook, %d pages)", len(ids))
+	} else {
+		log.Println("Status message updated (webhook)")
+	}
+	return nil
+}
+
+// DeleteStatus implements Publisher: deletes the status messages and forgets their IDs
+func (p *WebhookPublisher) DeleteStatus() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, id := range p.messageIDs {
+		if err := p.api.WebhookMessageDelete(p.id, p.token, id); err != nil && !isNotFound(err) {
+			p.messageIDs = p.messageIDs[i:]
+			p.saveMessageID(p.messageIDs)
+			return fmt.Errorf("failed to delete webhook message: %w", err)
+		}
+	}
+	p.messageIDs = nil
+	p.clearMessageID()
+	return nil
+}
+
+// SendAlert implements Publisher
+func (p *WebhookPublisher) SendAlert(msg string) error {
+	if _, err := p.api.WebhookExecute(p.id, p.token, false, &discordgo.WebhookParams{Content: msg}); err != nil {
+		return fmt.Errorf("failed to send webhook alert: %w", err)
+	}
+	return nil
+}
+
+// rewindFiles resets attachment readers after a failed request so they can be re-sent
+func rewindFiles(files []*discordgo.File) {
+	for _, f := range files {
+		if s, ok := f.Reader.(io.Seeker); ok {
+			s.Seek(0, io.SeekStart)
+		}
+	}
+}
+
+// webhookPublisherFromEnv returns a publisher if DISCORD_WEBHOOK_URL is set, nil otherwise
+// The message ID state file is stored next to configPath
+func webhookPublisherFromEnv(configPath string) (*WebhookPublisher, error) {
+	webhookURL := os.Getenv("DISCORD_WEBHOOK_URL")
+	if webhookURL == "" {
+		return nil, nil
+	}
+	return NewWebhookPublisher(webhookURL, filepath.Join(filepath.Dir(configPath), webhookStateFile))
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeWebhookAPI records webhook calls and returns canned results
+type fakeWebhookAPI struct {
+	executes  int
+	edits     int
+	deletes   int
+	editErr   error
+	nextID    string
+	lastEdit  *discordgo.WebhookEdit
+	lastFiles []*discordgo.File
+}
+
+func (f *fakeWebhookAPI) WebhookExecute(webhookID, token string, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.executes++
+	f.lastFiles = data.Files
+	return &discordgo.Message{ID: f.nextID}, nil
+}
+
+func (f *fakeWebhookAPI) WebhookMessageEdit(webhookID, token, messageID string, data *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.edits++
+	f.lastEdit = data
+	if f.editErr != nil {
+		return nil, f.editErr
+	}
+	return &discordgo.Message{ID: messageID}, nil
+}
+
+func (f *fakeWebhookAPI) WebhookMessageDelete(webhookID, token, messageID string, options ...discordgo.RequestOption) error {
+	f.deletes++
+	return nil
+}
+
+// TestParseWebhookURL tests webhook URL validation and ID/token extraction
+func TestParseWebhookURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		wantID    string
+		wantToken string
+		wantErr   bool
+	}{
+		{name: "Standard", url: "https://discord.com/api/webhooks/123/abc-DEF", wantID: "123", wantToken: "abc-DEF"},
+		{name: "Versioned API", url: "https://discord.com/api/v10/webhooks/123/abc", wantID: "123", wantToken: "abc"},
+		{name: "Legacy host", url: "https://discordapp.com/api/webhooks/123/abc", wantID: "123", wantToken: "abc"},
+		{name: "Canary host", url: "https://canary.discord.com/api/webhooks/123/abc", wantID: "123", wantToken: "abc"},
+		{name: "Plain HTTP", url: "http://discord.com/api/webhooks/123/abc", wantErr: true},
+		{name: "Foreign host", url: "https://example.com/api/webhooks/123/abc", wantErr: true},
+		{name: "Missing token", url: "https://discord.com/api/webhooks/123", wantErr: true},
+		{name: "Not a webhook", url: "https://discord.com/api/channels/123/abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, token, err := parseWebhookURL(tt.url)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %s", tt.url)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if id != tt.wantID || token != tt.wantToken {
+				t.Errorf("Expected (%s, %s), got (%s, %s)", tt.wantID, tt.wantToken, id, token)
+			}
+		})
+	}
+}
+
+// TestWebhookPublisher_PostThenEdit tests that the first publish posts and later ones edit
+func TestWebhookPublisher_PostThenEdit(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), webhookStateFile)
+	api := &fakeWebhookAPI{nextID: "111"}
+	p := &WebhookPublisher{api: api, id: "1", token: "t", statePath: statePath}
+
+	u := &StatusUpdate{Embed: &discordgo.MessageEmbed{Title: "Status"}}
+	if err := p.UpdateStatus(u); err != nil {
+		t.Fatalf("First publish failed: %v", err)
+	}
+	if api.executes != 1 || api.edits != 0 {
+		t.Errorf("Expected 1 execute and 0 edits, got %d/%d", api.executes, api.edits)
+	}
+	if p.MessageID() != "111" {
+		t.Errorf("Expected message ID 111, got %q", p.MessageID())
+	}
+
+	if err := p.UpdateStatus(u); err != nil {
+		t.Fatalf("Second publish failed: %v", err)
+	}
+	if api.executes != 1 || api.edits != 1 {
+		t.Errorf("Expected 1 execute and 1 edit, got %d/%d", api.executes, api.edits)
+	}
+	if api.lastEdit.Attachments != nil {
+		t.Error("Expected attachments untouched when no files are sent")
+	}
+
+	// State survives a restart
+	restarted := &WebhookPublisher{api: api, statePath: statePath}
+	restarted.loadMessageID()
+	if restarted.MessageID() != "111" {
+		t.Errorf("Expected persisted message ID 111, got %q", restarted.MessageID())
+	}
+}
+
+// TestWebhookPublisher_RecreatesDeletedMessage tests that a 404 on edit posts a new message
+func TestWebhookPublisher_RecreatesDeletedMessage(t *testing.T) {
+	api := &fakeWebhookAPI{
+		nextID:  "222",
+		editErr: &discordgo.RESTError{Response: &http.Response{StatusCode: http.StatusNotFound}},
+	}
+	p := &WebhookPublisher{api: api, id: "1", token: "t", messageIDs: []string{"111"}}
+
+	if err := p.UpdateStatus(&StatusUpdate{Embed: &discordgo.MessageEmbed{}}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if api.edits != 1 || api.executes != 1 {
+		t.Errorf("Expected edit then execute, got %d edits, %d executes", api.edits, api.executes)
+	}
+	if p.MessageID() != "222" {
+		t.Errorf("Expected new message ID 222, got %q", p.MessageID())
+	}
+}
+
+// TestWebhookPublisher_EditErrorKeepsMessage tests that non-404 errors are returned without reposting
+func TestWebhookPublisher_EditErrorKeepsMessage(t *testing.T) {
+	api := &fakeWebhookAPI{editErr: errors.New("rate limited")}
+	p := &WebhookPublisher{api: api, id: "1", token: "t", messageIDs: []string{"111"}}
+
+	if err := p.UpdateStatus(&StatusUpdate{Embed: &discordgo.MessageEmbed{}}); err == nil {
+		t.Error("Expected error from failed edit")
+	}
+	if api.executes != 0 {
+		t.Errorf("Expected no repost on transient error, got %d executes", api.executes)
+	}
+	if p.MessageID() != "111" {
+		t.Errorf("Expected message ID unchanged, got %q", p.MessageID())
+	}
+}
+
+// TestWebhookPublisher_ReplacesAttachments tests that editing with files clears previous attachments
+func TestWebhookPublisher_ReplacesAttachments(t *testing.T) {
+	api := &fakeWebhookAPI{}
+	p := &WebhookPublisher{api: api, id: "1", token: "t", messageIDs: []string{"111"}}
+
+	if err := p.UpdateStatus(&StatusUpdate{Embed: &discordgo.MessageEmbed{}, Banner: []byte("png")}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if api.lastEdit.Attachments == nil || len(*api.lastEdit.Attachments) != 0 {
+		t.Error("Expected empty attachment list to replace the previous banner")
+	}
+	if len(api.lastEdit.Files) != 1 {
+		t.Errorf("Expected 1 file, got %d", len(api.lastEdit.Files))
+	}
+}
+
+// TestWebhookPublisherFromEnv tests enablement and state file placement
+func TestWebhookPublisherFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+
+	t.Setenv("DISCORD_WEBHOOK_URL", "")
+	p, err := webhookPublisherFromEnv(configPath)
+	if err != nil || p != nil {
+		t.Fatalf("Expected nil publisher when unset, got %v (%v)", p, err)
+	}
+
+	t.Setenv("DISCORD_WEBHOOK_URL", "https://example.com/hook")
+	if _, err := webhookPublisherFromEnv(configPath); err == nil {
+		t.Error("Expected error for invalid webhook URL")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, webhookStateFile), []byte("333\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DISCORD_WEBHOOK_URL", "https://discord.com/api/webhooks/123/abc")
+	p, err = webhookPublisherFromEnv(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.MessageID() != "333" {
+		t.Errorf("Expected message ID restored from state file, got %q", p.MessageID())
+	}
+}
+
+// TestRedactSecrets_WebhookURL tests that webhook tokens never reach the logs
+func TestRedactSecrets_WebhookURL(t *testing.T) {
+	in := `Post "https://discord.com/api/webhooks/123456/SeCrEt-tok_en?wait=true": dial tcp: timeout`
+	got := RedactSecrets(in)
+	want := `Post "https://discord.com/api/webhooks/123456/[REDACTED]?wait=true": dial tcp: timeout`
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+// TestWebhookPublisher_DeleteStatus tests that deleting forgets the message and its state file
+func TestWebhookPublisher_DeleteStatus(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), webhookStateFile)
+	api := &fakeWebhookAPI{nextID: "111"}
+	p := &WebhookPublisher{api: api, id: "1", token: "t", statePath: statePath}
+
+	if err := p.DeleteStatus(); err != nil || api.deletes != 0 {
+		t.Fatalf("Expected no-op delete without message, got %v (%d deletes)", err, api.deletes)
+	}
+
+	p.UpdateStatus(&StatusUpdate{Embed: &discordgo.MessageEmbed{}})
+	if err := p.DeleteStatus(); err != nil {
+		t.Fatalf("DeleteStatus failed: %v", err)
+	}
+	if api.deletes != 1 || p.MessageID() != "" {
+		t.Errorf("Expected message deleted and forgotten, got %d deletes, ID %q", api.deletes, p.MessageID())
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Error("Expected state file removed")
+	}
+}
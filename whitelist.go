@@ -0,0 +1,428 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ================= WHITELISTS / RESERVED SLOTS =================
+
+const (
+	// maxWhitelistEntries bounds the reserved slots of one server
+	maxWhitelistEntries = 100
+	// whitelistNameMax bounds a driver name written to the entry list
+	whitelistNameMax = 64
+	// entryListFileName is the only file a whitelist may write: the AC server's cfg/entry_list.ini
+	entryListFileName = "entry_list.ini"
+)
+
+// Whitelist holds the reserved slots of one server (whitelists in config.json, keyed by server name)
+// With EntryList set, the first car slots of that entry_list.ini are locked to the drivers' GUIDs
+type Whitelist struct {
+	EntryList string           `json:"entry_list,omitempty"` // absolute path of the server's entry_list.ini on this host
+	Drivers   []WhitelistEntry `json:"drivers"`
+}
+
+// WhitelistEntry is one reserved slot
+type WhitelistEntry struct {
+	GUID string `json:"guid"` // SteamID64
+	Name string `json:"name,omitempty"`
+}
+
+// validateWhitelists checks the whitelists section against the configured servers
+func validateWhitelists(whitelists map[string]Whitelist, servers []Server) error {
+	serverNames := make(map[string]bool, len(servers))
+	for _, s := range servers {
+		serverNames[s.Name] = true
+	}
+	for _, server := range slices.Sorted(maps.Keys(whitelists)) {
+		if err := validateWhitelist(server, whitelists[server], serverNames); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateWhitelist checks the whitelist of one server
+func validateWhitelist(server string, wl Whitelist, serverNames map[string]bool) error {
+	if !serverNames[server] {
+		return fmt.Errorf("whitelist for server '%s' which is not defined in servers", server)
+	}
+	if wl.EntryList != "" && (!filepath.IsAbs(wl.EntryList) || filepath.Base(wl.EntryList) != entryListFileName) {
+		return fmt.Errorf("whitelist for server '%s': entry_list must be an absolute path to an %s file", server, entryListFileName)
+	}
+	if len(wl.Drivers) > maxWhitelistEntries {
+		return fmt.Errorf("whitelist for server '%s' has %d drivers (max %d)", server, len(wl.Drivers), maxWhitelistEntries)
+	}
+	seen := make(map[string]bool, len(wl.Drivers))
+	for _, d := range wl.Drivers {
+		if !steamIDPattern.MatchString(d.GUID) {
+			return fmt.Errorf("whitelist for server '%s': '%s' is not a SteamID64", server, d.GUID)
+		}
+		if seen[d.GUID] {
+			return fmt.Errorf("whitelist for server '%s': %s is listed twice", server, d.GUID)
+		}
+		seen[d.GUID] = true
+		if len([]rune(d.Name)) > whitelistNameMax || strings.ContainsAny(d.Name, "\r\n[]=") {
+			return fmt.Errorf("whitelist for server '%s': name of %s must be up to %d characters without line breaks, brackets or '='", server, d.GUID, whitelistNameMax)
+		}
+	}
+	return nil
+}
+
+// renderEntryList returns data with the GUID and DRIVERNAME of the car slots set to drivers in order;
+// slots past the reserved ones are opened again (empty GUID). All other keys, comments and the order are kept
+// Fails when the entry list has fewer car slots than drivers
+func renderEntryList(data []byte, drivers []WhitelistEntry) ([]byte, error) {
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		lines = append(lines, strings.TrimRight(sc.Text(), "\r"))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read entry list: %w", err)
+	}
+
+	var out bytes.Buffer
+	slots := 0
+	for i := 0; i < len(lines); {
+		header := strings.TrimSpace(lines[i])
+		out.WriteString(lines[i] + "\n")
+		i++
+		// The section body runs until the next [header]
+		end := i
+		for end < len(lines) && !isINISection(lines[end]) {
+			end++
+		}
+		if !strings.HasPrefix(strings.ToUpper(header), "[CAR_") {
+			for _, l := range lines[i:end] {
+				out.WriteString(l + "\n")
+			}
+			i = end
+			continue
+		}
+
+		guid, name := "", ""
+		if slots < len(drivers) {
+			guid, name = drivers[slots].GUID, drivers[slots].Name
+		}
+		slots++
+		// Keys go before the blank lines that separate the sections
+		last := end
+		for last > i && strings.TrimSpace(lines[last-1]) == "" {
+			last--
+		}
+		var hasGUID, hasName bool
+		for _, l := range lines[i:last] {
+			key, _, ok := strings.Cut(l, "=")
+			switch key = strings.ToUpper(strings.TrimSpace(key)); {
+			case ok && key == "GUID":
+				hasGUID = true
+				out.WriteString("GUID=" + guid + "\n")
+			case ok && key == "DRIVERNAME":
+				hasName = true
+				out.WriteString("DRIVERNAME=" + name + "\n")
+			default:
+				out.WriteString(l + "\n")
+			}
+		}
+		if !hasGUID {
+			out.WriteString("GUID=" + guid + "\n")
+		}
+		if !hasName && name != "" {
+			out.WriteString("DRIVERNAME=" + name + "\n")
+		}
+		for _, l := range lines[last:end] {
+			out.WriteString(l + "\n")
+		}
+		i = end
+	}
+	if len(drivers) > slots {
+		return nil, fmt.Errorf("%d reserved drivers but the entry list has only %d car slots", len(drivers), slots)
+	}
+	return out.Bytes(), nil
+}
+
+// isINISection reports whether line is a [section] header
+func isINISection(line string) bool {
+	t := strings.TrimSpace(line)
+	return strings.HasPrefix(t, "[") && strings.HasSuffix(t, "]")
+}
+
+// writeEntryList reserves the first slots of the entry list at path for drivers
+// Reports whether the file changed; the AC server reads it on start, so a change needs a server restart
+func writeEntryList(path string, drivers []WhitelistEntry) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	rendered, err := renderEntryList(data, drivers)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	if bytes.Equal(rendered, data) {
+		return false, nil
+	}
+	if err := writeFileAtomic(path, rendered); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// whitelistSync writes the configured whitelists to the entry lists, skipping unchanged ones
+type whitelistSync struct {
+	mu     sync.Mutex
+	synced map[string]string // entry list path -> fingerprint of the drivers last written
+	failed map[string]string // entry list path -> fingerprint whose write failed (logged once, retried)
+}
+
+// whitelistFingerprint hashes the drivers reserved in an entry list
+func whitelistFingerprint(drivers []WhitelistEntry) string {
+	h := sha256.New()
+	for _, d := range drivers {
+		fmt.Fprintf(h, "%s\x00%s\x00", d.GUID, d.Name)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// sync writes every whitelist with an entry list whose drivers changed since the last write
+// The file itself is compared too, so an entry list edited by hand is corrected on the first sync
+func (s *whitelistSync) sync(cfg *Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.synced == nil {
+		s.synced, s.failed = make(map[string]string), make(map[string]string)
+	}
+	for _, server := range slices.Sorted(maps.Keys(cfg.Whitelists)) {
+		wl := cfg.Whitelists[server]
+		if wl.EntryList == "" {
+			continue
+		}
+		fp := whitelistFingerprint(wl.Drivers)
+		if s.synced[wl.EntryList] == fp {
+			continue
+		}
+		changed, err := writeEntryList(wl.EntryList, wl.Drivers)
+		if err != nil {
+			if s.failed[wl.EntryList] != fp {
+				log.Printf("Error writing whitelist of '%s': %v", server, err)
+				s.failed[wl.EntryList] = fp
+			}
+			continue
+		}
+		delete(s.failed, wl.EntryList)
+		s.synced[wl.EntryList] = fp
+		if changed {
+			log.Printf("Wrote %d reserved slot(s) of '%s' to %s (restart the server to apply)", len(wl.Drivers), server, wl.EntryList)
+		}
+	}
+}
+
+// syncWhitelists writes changed whitelists to the entry lists on this host (leader only)
+func (b *Bot) syncWhitelists(cfg *Config) {
+	if len(cfg.Whitelists) == 0 || !b.isLeader() {
+		return
+	}
+	b.whitelists.sync(cfg)
+}
+
+// whitelistEditor adapts the config manager to api.WhitelistEditor
+type whitelistEditor struct {
+	bot *Bot
+}
+
+// Whitelists implements api.WhitelistEditor
+func (e *whitelistEditor) Whitelists() map[string]api.Whitelist {
+	out := make(map[string]api.Whitelist)
+	cfg := e.bot.configManager.GetConfig()
+	if cfg == nil {
+		return out
+	}
+	for server, wl := range cfg.Whitelists {
+		out[server] = wl.apiWhitelist(server)
+	}
+	return out
+}
+
+// SetWhitelist implements api.WhitelistEditor: replaces the reserved drivers of server, saves the config
+// and writes the entry list. An entry list without enough car slots is refused before anything is saved
+func (e *whitelistEditor) SetWhitelist(server string, entries []api.WhitelistEntry) (api.Whitelist, error) {
+	drivers := make([]WhitelistEntry, 0, len(entries))
+	for _, d := range entries {
+		drivers = append(drivers, WhitelistEntry{GUID: d.GUID, Name: d.Name})
+	}
+	wl, err := e.bot.setWhitelist(server, drivers)
+	if err != nil {
+		return api.Whitelist{}, err
+	}
+	return wl.apiWhitelist(server), nil
+}
+
+// setWhitelist saves the reserved drivers of server and writes its entry list right away
+func (b *Bot) setWhitelist(server string, drivers []WhitelistEntry) (Whitelist, error) {
+	cfg := b.configManager.GetConfig()
+	if cfg == nil {
+		return Whitelist{}, fmt.Errorf("no config loaded")
+	}
+	if !slices.ContainsFunc(cfg.Servers, func(s Server) bool { return s.Name == server }) {
+		return Whitelist{}, fmt.Errorf("%w '%s'", api.ErrUnknownServer, server)
+	}
+
+	next := cfg.Clone()
+	if next.Whitelists == nil {
+		next.Whitelists = make(map[string]Whitelist)
+	}
+	wl := next.Whitelists[server]
+	wl.Drivers = drivers
+	if wl.EntryList != "" {
+		data, err := os.ReadFile(wl.EntryList)
+		if err != nil {
+			return Whitelist{}, fmt.Errorf("failed to read %s: %w", wl.EntryList, err)
+		}
+		if _, err := renderEntryList(data, drivers); err != nil {
+			return Whitelist{}, err
+		}
+	}
+	if len(drivers) == 0 && wl.EntryList == "" {
+		delete(next.Whitelists, server)
+	} else {
+		next.Whitelists[server] = wl
+	}
+	if len(next.Whitelists) == 0 {
+		next.Whitelists = nil
+	}
+	if err := b.configManager.WriteConfig(next); err != nil {
+		return Whitelist{}, err
+	}
+	b.whitelists.sync(next)
+	return wl, nil
+}
+
+func (wl Whitelist) apiWhitelist(server string) api.Whitelist {
+	out := api.Whitelist{Server: server, EntryList: wl.EntryList, Drivers: make([]api.WhitelistEntry, 0, len(wl.Drivers))}
+	for _, d := range wl.Drivers {
+		out.Drivers = append(out.Drivers, api.WhitelistEntry{GUID: d.GUID, Name: d.Name})
+	}
+	return out
+}
+
+// whitelistCommand is /whitelist add|remove|list, limited to Manage Server by default and to
+// WHITELIST_ROLE_IDS when set
+func whitelistCommand() *slashCommand {
+	manageGuild := int64(discordgo.PermissionManageGuild)
+	serverOption := &discordgo.ApplicationCommandOption{
+		Type: discordgo.ApplicationCommandOptionString, Name: "server", Description: "Server name",
+		Required: true, Autocomplete: true,
+	}
+	steamIDOption := &discordgo.ApplicationCommandOption{
+		Type: discordgo.ApplicationCommandOptionString, Name: "steam_id", Description: "SteamID64 of the driver",
+		Required: true, MinLength: intPtr(17), MaxLength: 17,
+	}
+	return &slashCommand{
+		def: &discordgo.ApplicationCommand{
+			Name:                     "whitelist",
+			Description:              "Reserve game server slots for drivers",
+			DefaultMemberPermissions: &manageGuild,
+			Contexts:                 &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild},
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type: discordgo.ApplicationCommandOptionSubCommand, Name: "add", Description: "Reserve a slot for a driver",
+					Options: []*discordgo.ApplicationCommandOption{
+						serverOption, steamIDOption,
+						{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Driver name in the entry list", MaxLength: whitelistNameMax},
+					},
+				},
+				{
+					Type: discordgo.ApplicationCommandOptionSubCommand, Name: "remove", Description: "Free a driver's slot",
+					Options: []*discordgo.ApplicationCommandOption{serverOption, steamIDOption},
+				},
+				{
+					Type: discordgo.ApplicationCommandOptionSubCommand, Name: "list", Description: "Reserved slots of a server",
+					Options: []*discordgo.ApplicationCommandOption{serverOption},
+				},
+			},
+		},
+		run:      runWhitelistCommand,
+		complete: completeServerName,
+	}
+}
+
+// whitelistRolesFromEnv returns the role IDs allowed to use /whitelist (WHITELIST_ROLE_IDS, empty = any role)
+func whitelistRolesFromEnv() []string {
+	var roles []string
+	for _, r := range strings.Split(os.Getenv("WHITELIST_ROLE_IDS"), ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+// runWhitelistCommand handles /whitelist; every change is logged with the member who made it
+func runWhitelistCommand(b *Bot, user invoker, opts []*discordgo.ApplicationCommandInteractionDataOption) string {
+	if len(opts) != 1 {
+		return "Unknown subcommand"
+	}
+	if len(b.whitelistRoles) > 0 && !slices.ContainsFunc(b.whitelistRoles, func(r string) bool { return slices.Contains(user.roles, r) }) {
+		log.Printf("Audit: %s was refused /whitelist (missing whitelist role)", user.name)
+		return "❌ You need a whitelist manager role to change reserved slots"
+	}
+	sub := opts[0]
+	server := optionString(sub.Options, "server")
+	guid := optionString(sub.Options, "steam_id")
+	cfg := b.configManager.GetConfig()
+	if cfg == nil {
+		return "❌ No config loaded"
+	}
+	current := slices.Clone(cfg.Whitelists[server].Drivers)
+
+	switch sub.Name {
+	case "add":
+		if slices.ContainsFunc(current, func(d WhitelistEntry) bool { return d.GUID == guid }) {
+			return fmt.Sprintf("%s already has a slot on **%s**", guid, server)
+		}
+		if _, err := b.setWhitelist(server, append(current, WhitelistEntry{GUID: guid, Name: optionString(sub.Options, "name")})); err != nil {
+			return "❌ " + err.Error()
+		}
+		log.Printf("Audit: %s reserved a slot on '%s' for %s", user.name, server, guid)
+		return fmt.Sprintf("✅ Reserved a slot on **%s** for %s (%d reserved)", server, guid, len(current)+1)
+	case "remove":
+		i := slices.IndexFunc(current, func(d WhitelistEntry) bool { return d.GUID == guid })
+		if i < 0 {
+			return fmt.Sprintf("%s has no slot on **%s**", guid, server)
+		}
+		if _, err := b.setWhitelist(server, slices.Delete(current, i, i+1)); err != nil {
+			return "❌ " + err.Error()
+		}
+		log.Printf("Audit: %s freed the slot on '%s' of %s", user.name, server, guid)
+		return fmt.Sprintf("✅ Freed the slot on **%s** of %s", server, guid)
+	case "list":
+		if len(current) == 0 {
+			return fmt.Sprintf("No reserved slots on **%s**", server)
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Reserved slots on **%s**:\n", server)
+		for i, d := range current {
+			fmt.Fprintf(&sb, "%d. %s", i+1, d.GUID)
+			if d.Name != "" {
+				sb.WriteString(" — " + d.Name)
+			}
+			sb.WriteByte('\n')
+		}
+		return sb.String()
+	}
+	return "Unknown subcommand"
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bombom/absa-ac/api"
+	"github.com/bwmarrin/discordgo"
+)
+
+// testEntryList has three car slots; the first one was reserved by hand
+const testEntryList = `[CAR_0]
+MODEL=ks_ferrari_488_gt3
+SKIN=red
+GUID=76561198000000009
+BALLAST=0
+
+[CAR_1]
+MODEL=ks_ferrari_488_gt3
+SKIN=blue
+DRIVERNAME=
+BALLAST=0
+
+[CAR_2]
+MODEL=ks_audi_r8_lms_2016
+SKIN=white
+GUID=
+`
+
+// TestValidateWhitelists tests server names, entry list paths, GUIDs, duplicates and names
+func TestValidateWhitelists(t *testing.T) {
+	servers := testMaintenanceConfig().Servers
+	valid := map[string]Whitelist{"Track #1": {EntryList: "/srv/ac/cfg/entry_list.ini", Drivers: []WhitelistEntry{{GUID: aliceGUID, Name: "Alice"}}}}
+	if err := validateWhitelists(valid, servers); err != nil {
+		t.Fatalf("Expected a valid whitelist, got %v", err)
+	}
+
+	tests := map[string]Whitelist{
+		"relative path":  {EntryList: "cfg/entry_list.ini"},
+		"other file":     {EntryList: "/srv/ac/cfg/server_cfg.ini"},
+		"bad GUID":       {Drivers: []WhitelistEntry{{GUID: "123"}}},
+		"duplicate GUID": {Drivers: []WhitelistEntry{{GUID: aliceGUID}, {GUID: aliceGUID}}},
+		"ini syntax":     {Drivers: []WhitelistEntry{{GUID: aliceGUID, Name: "a\nGUID=1"}}},
+	}
+	for name, wl := range tests {
+		if err := validateWhitelists(map[string]Whitelist{"Track #1": wl}, servers); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if err := validateWhitelists(map[string]Whitelist{"Rally #1": {}}, servers); err == nil {
+		t.Error("Expected an error for an unknown server")
+	}
+}
+
+// TestRenderEntryList tests reserving slots in order, reopening the rest and keeping other keys
+func TestRenderEntryList(t *testing.T) {
+	out, err := renderEntryList([]byte(testEntryList), []WhitelistEntry{{GUID: aliceGUID, Name: "Alice"}, {GUID: bobGUID}})
+	if err != nil {
+		t.Fatalf("renderEntryList failed: %v", err)
+	}
+	want := `[CAR_0]
+MODEL=ks_ferrari_488_gt3
+SKIN=red
+GUID=76561198000000001
+BALLAST=0
+DRIVERNAME=Alice
+
+[CAR_1]
+MODEL=ks_ferrari_488_gt3
+SKIN=blue
+DRIVERNAME=
+BALLAST=0
+GUID=76561198000000002
+
+[CAR_2]
+MODEL=ks_audi_r8_lms_2016
+SKIN=white
+GUID=
+`
+	if string(out) != want {
+		t.Errorf("Unexpected entry list:\n%s\nwant:\n%s", out, want)
+	}
+
+	// Rendering the result again changes nothing
+	if again, _ := renderEntryList(out, []WhitelistEntry{{GUID: aliceGUID, Name: "Alice"}, {GUID: bobGUID}}); string(again) != want {
+		t.Errorf("Expected a stable result, got:\n%s", again)
+	}
+	if _, err := renderEntryList([]byte("[CAR_0]\nMODEL=x\n"), []WhitelistEntry{{GUID: aliceGUID}, {GUID: bobGUID}}); err == nil {
+		t.Error("Expected an error with more drivers than car slots")
+	}
+}
+
+// testWhitelistBot returns a bot whose config is saved in a temp dir, with Track #1's entry list in it
+func testWhitelistBot(t *testing.T) (*Bot, string) {
+	t.Helper()
+	dir := t.TempDir()
+	entryList := filepath.Join(dir, entryListFileName)
+	if err := os.WriteFile(entryList, []byte(testEntryList), 0644); err != nil {
+		t.Fatalf("Failed to write entry list: %v", err)
+	}
+	cfg := testMaintenanceConfig()
+	initializeServerIPs(cfg)
+	cfg.Whitelists = map[string]Whitelist{"Track #1": {EntryList: entryList}}
+	cm := NewConfigManager(filepath.Join(dir, "config.json"), cfg)
+	if err := cm.WriteConfig(cfg); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+	b := newTestBot(cfg)
+	b.configManager = cm
+	return b, entryList
+}
+
+// TestWhitelistEditor tests saving the whitelist, writing the entry list and refusing too many drivers
+func TestWhitelistEditor(t *testing.T) {
+	b, entryList := testWhitelistBot(t)
+	e := &whitelistEditor{bot: b}
+
+	wl, err := e.SetWhitelist("Track #1", []api.WhitelistEntry{{GUID: aliceGUID, Name: "Alice"}})
+	if err != nil {
+		t.Fatalf("SetWhitelist failed: %v", err)
+	}
+	if wl.EntryList != entryList || len(wl.Drivers) != 1 {
+		t.Errorf("Unexpected whitelist %+v", wl)
+	}
+	if got := b.configManager.GetConfig().Whitelists["Track #1"]; got.EntryList != entryList || got.Drivers[0].GUID != aliceGUID {
+		t.Errorf("Expected the whitelist saved with its entry list, got %+v", got)
+	}
+	data, _ := os.ReadFile(entryList)
+	if !strings.Contains(string(data), "GUID="+aliceGUID) || strings.Contains(string(data), "76561198000000009") {
+		t.Errorf("Expected the entry list rewritten, got:\n%s", data)
+	}
+
+	four := []api.WhitelistEntry{{GUID: aliceGUID}, {GUID: bobGUID}, {GUID: "76561198000000003"}, {GUID: "76561198000000004"}}
+	if _, err := e.SetWhitelist("Track #1", four); err == nil || !strings.Contains(err.Error(), "3 car slots") {
+		t.Errorf("Expected too many drivers to be refused, got %v", err)
+	}
+	if got := b.configManager.GetConfig().Whitelists["Track #1"].Drivers; len(got) != 1 {
+		t.Errorf("Expected the refused whitelist not to be saved, got %+v", got)
+	}
+	if _, err := e.SetWhitelist("Rally #1", nil); !errors.Is(err, api.ErrUnknownServer) {
+		t.Errorf("Expected ErrUnknownServer, got %v", err)
+	}
+	if got := e.Whitelists()["Track #1"]; len(got.Drivers) != 1 || got.Drivers[0].Name != "Alice" {
+		t.Errorf("Expected the whitelist listed, got %+v", got)
+	}
+}
+
+// TestWhitelistCommand tests /whitelist add, list and remove and the role gate
+func TestWhitelistCommand(t *testing.T) {
+	b, entryList := testWhitelistBot(t)
+	admin := invoker{id: "1", name: "admin", roles: []string{"500"}}
+	str := func(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+	}
+	sub := func(name string, opts ...*discordgo.ApplicationCommandInteractionDataOption) []*discordgo.ApplicationCommandInteractionDataOption {
+		return []*discordgo.ApplicationCommandInteractionDataOption{{Name: name, Type: discordgo.ApplicationCommandOptionSubCommand, Options: opts}}
+	}
+
+	if reply := runWhitelistCommand(b, admin, sub("add", str("server", "Track #1"), str("steam_id", aliceGUID), str("name", "Alice"))); !strings.HasPrefix(reply, "✅") {
+		t.Fatalf("Unexpected reply %q", reply)
+	}
+	if reply := runWhitelistCommand(b, admin, sub("add", str("server", "Track #1"), str("steam_id", aliceGUID))); !strings.Contains(reply, "already") {
+		t.Errorf("Expected a duplicate reply, got %q", reply)
+	}
+	if reply := runWhitelistCommand(b, admin, sub("list", str("server", "Track #1"))); !strings.Contains(reply, aliceGUID+" — Alice") {
+		t.Errorf("Unexpected list %q", reply)
+	}
+	if data, _ := os.ReadFile(entryList); !strings.Contains(string(data), "DRIVERNAME=Alice") {
+		t.Errorf("Expected the entry list written, got:\n%s", data)
+	}
+
+	b.whitelistRoles = []string{"600"}
+	if reply := runWhitelistCommand(b, admin, sub("remove", str("server", "Track #1"), str("steam_id", aliceGUID))); !strings.Contains(reply, "role") {
+		t.Errorf("Expected the role gate to refuse, got %q", reply)
+	}
+	b.whitelistRoles = []string{"500"}
+	if reply := runWhitelistCommand(b, admin, sub("remove", str("server", "Track #1"), str("steam_id", aliceGUID))); !strings.HasPrefix(reply, "✅") {
+		t.Errorf("Unexpected reply %q", reply)
+	}
+	if reply := runWhitelistCommand(b, admin, sub("list", str("server", "Track #1"))); !strings.HasPrefix(reply, "No reserved slots") {
+		t.Errorf("Unexpected list %q", reply)
+	}
+	if reply := runWhitelistCommand(b, admin, sub("add", str("server", "Track #1"), str("steam_id", "123"))); !strings.HasPrefix(reply, "❌") {
+		t.Errorf("Expected an invalid Steam ID to be refused, got %q", reply)
+	}
+}